@@ -2,11 +2,14 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"log"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"strconv"
 
+	"github.com/maynagashev/gophkeeper/client/internal/api"
 	"github.com/maynagashev/gophkeeper/client/internal/tui"
 )
 
@@ -18,6 +21,9 @@ const (
 	dbPathEnvVar = "GOPHKEEPER_DB_PATH"
 	// Путь к файлу KDBX по умолчанию.
 	defaultDBPath = "gophkeeper.kdbx"
+	// Имена переменных окружения для проверки сертификата сервера.
+	caFileEnvVar     = "GOPHKEEPER_CA_FILE"
+	tlsPinningEnvVar = "GOPHKEEPER_TLS_PINNING"
 )
 
 // Переменные для версии и даты сборки, устанавливаются через ldflags.
@@ -53,6 +59,59 @@ func setupLogging() {
 	slog.Info("Логгер инициализирован", "path", logPath)
 }
 
+// setupClientInfo задает сведения о клиенте, которые передаются серверу с каждым запросом.
+// Без идентификатора устройства клиент работает, но версии на сервере нельзя будет связать с устройством.
+func setupClientInfo() {
+	info := api.ClientInfo{Name: api.ClientName, Version: version}
+	path, err := api.DefaultDeviceIDPath()
+	if err == nil {
+		info.DeviceID, err = api.LoadDeviceID(path)
+	}
+	if err != nil {
+		slog.Warn("Не удалось получить идентификатор устройства", "error", err)
+	}
+	api.SetClientInfo(info)
+}
+
+// isFlagSet сообщает, был ли флаг задан в командной строке явно.
+func isFlagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// setupTLSOptions возвращает параметры проверки сертификата сервера. Флаги переопределяют переменные окружения.
+func setupTLSOptions(caFile string, pinning bool) (api.TLSOptions, error) {
+	if !isFlagSet("ca-file") {
+		caFile = os.Getenv(caFileEnvVar)
+	}
+	if env := os.Getenv(tlsPinningEnvVar); env != "" && !isFlagSet("tls-pinning") {
+		var err error
+		if pinning, err = strconv.ParseBool(env); err != nil {
+			return api.TLSOptions{}, fmt.Errorf("некорректное значение %s: %w", tlsPinningEnvVar, err)
+		}
+	}
+
+	var opts api.TLSOptions
+	if caFile != "" {
+		pool, err := api.LoadCAFile(caFile)
+		if err != nil {
+			return api.TLSOptions{}, err
+		}
+		opts.RootCAs = pool
+	}
+	if pinning {
+		// Ключ сервера загружается из KDBX вместе с URL, до этого закрепляется ключ первого соединения
+		opts.Pins = api.NewPinVerifier("")
+	}
+	slog.Info("Параметры проверки сертификата сервера", "ca_file", caFile, "pinning", pinning)
+	return opts, nil
+}
+
 func main() {
 	// Добавляем флаг для версии
 	versionFlag := flag.Bool("version", false, "Показать версию и дату сборки")
@@ -64,6 +123,11 @@ func main() {
 	kdbxPathFlag := flag.String("db", defaultDBPath, "Путь к файлу базы данных KDBX (переопределяет "+dbPathEnvVar+")")
 	debugModeFlag := flag.Bool("debug", false, "Включить режим отладки TUI")
 	serverURLFlag := flag.String("server-url", "", "URL сервера GophKeeper (например, https://localhost:8443)")
+	caFileFlag := flag.String("ca-file", "",
+		"Файл сертификата CA (PEM) для проверки сервера в дополнение к системным (переопределяет "+caFileEnvVar+")")
+	tlsPinningFlag := flag.Bool("tls-pinning", false,
+		"Закреплять открытый ключ сервера при первом соединении и проверять его вместо цепочки сертификатов"+
+			" (переопределяет "+tlsPinningEnvVar+")")
 
 	// Парсинг флагов командной строки
 	flag.Parse()
@@ -91,14 +155,7 @@ func main() {
 	}
 
 	// 2. Проверяем, был ли флаг установлен явно
-	dbFlagPresent := false
-	flag.Visit(func(f *flag.Flag) {
-		if f.Name == "db" {
-			dbFlagPresent = true
-		}
-	})
-
-	if dbFlagPresent {
+	if isFlagSet("db") {
 		finalPath = *kdbxPathFlag
 		source = "флаг -db"
 	}
@@ -119,6 +176,15 @@ func main() {
 		"server_url", *serverURLFlag,
 	)
 
+	setupClientInfo()
+
+	tlsOptions, err := setupTLSOptions(*caFileFlag, *tlsPinningFlag)
+	if err != nil {
+		slog.Error("Ошибка настройки проверки сертификата сервера", "error", err)
+		fmt.Fprintf(os.Stderr, "Ошибка настройки TLS: %v\n", err)
+		os.Exit(1)
+	}
+
 	// Запускаем TUI, передавая финальный путь и флаг отладки
-	tui.Start(finalPath, *debugModeFlag, *serverURLFlag)
+	tui.Start(finalPath, *debugModeFlag, *serverURLFlag, tlsOptions)
 }
@@ -9,6 +9,8 @@ require (
 	github.com/maynagashev/gophkeeper/models v0.0.0-20250419170703-77449cf8005a
 	github.com/stretchr/testify v1.10.0
 	github.com/tobischo/gokeepasslib/v3 v3.6.1
+	google.golang.org/grpc v1.72.2
+	google.golang.org/protobuf v1.36.6
 )
 
 replace github.com/maynagashev/gophkeeper/models => ../models
@@ -23,6 +25,8 @@ require (
 	github.com/stretchr/objx v0.5.2 // indirect
 	github.com/tobischo/argon2 v0.1.0 // indirect
 	golang.org/x/crypto v0.37.0 // indirect
+	golang.org/x/net v0.35.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20250218202821-56aae31c358a // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
 
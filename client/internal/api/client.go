@@ -15,8 +15,11 @@ import (
 	"github.com/maynagashev/gophkeeper/models" // Импортируем общие модели
 )
 
-// ErrAuthorization сигнализирует об ошибке авторизации (401).
-var ErrAuthorization = errors.New("ошибка авторизации")
+// apiPrefix - базовый путь API v2: ошибки возвращаются с машиночитаемым кодом (см. responseError).
+const apiPrefix = "/api/v2"
+
+// ErrNotModified сигнализирует, что версия на сервере совпадает с локальной (304).
+var ErrNotModified = errors.New("версия хранилища на сервере не изменилась")
 
 // Client определяет интерфейс для взаимодействия с API сервера GophKeeper.
 type Client interface {
@@ -29,11 +32,15 @@ type Client interface {
 	// UploadVault загружает файл хранилища на сервер.
 	UploadVault(ctx context.Context, data io.Reader, size int64, contentModifiedAt time.Time) error
 	// DownloadVault скачивает текущую версию файла хранилища.
-	DownloadVault(ctx context.Context) (io.ReadCloser, *models.VaultVersion, error)
+	// Если ifNoneMatch совпадает с контрольной суммой текущей версии, возвращает ErrNotModified.
+	DownloadVault(ctx context.Context, ifNoneMatch string) (io.ReadCloser, *models.VaultVersion, error)
 	// ListVersions получает список версий хранилища.
 	ListVersions(ctx context.Context, limit, offset int) ([]models.VaultVersion, int64, error)
 	// RollbackToVersion откатывает хранилище к указанной версии.
 	RollbackToVersion(ctx context.Context, versionID int64) error
+	// SubscribeEvents подписывается на поток событий об изменении хранилища.
+	// Канал закрывается при разрыве соединения или отмене контекста.
+	SubscribeEvents(ctx context.Context) (<-chan models.VaultEvent, error)
 	// SetAuthToken устанавливает JWT токен для аутентифицированных запросов.
 	SetAuthToken(token string)
 }
@@ -46,17 +53,18 @@ type httpClient struct {
 }
 
 // NewHTTPClient создает новый экземпляр API клиента.
-func NewHTTPClient(baseURL string) Client {
+// Сертификат сервера проверяется в соответствии с tlsOptions.
+func NewHTTPClient(baseURL string, tlsOptions TLSOptions) Client {
 	return &httpClient{
 		baseURL:    baseURL,
-		httpClient: &http.Client{}, // Используем стандартный HTTP клиент
+		httpClient: &http.Client{Transport: tlsOptions.transport()},
 	}
 }
 
 // Register отправляет запрос на регистрацию на сервер.
 func (c *httpClient) Register(ctx context.Context, username, password string) error {
 	// Формируем URL эндпоинта регистрации
-	registerURL, err := url.JoinPath(c.baseURL, "/api/register")
+	registerURL, err := url.JoinPath(c.baseURL, apiPrefix, "register")
 	if err != nil {
 		return fmt.Errorf("ошибка формирования URL для регистрации: %w", err)
 	}
@@ -79,7 +87,7 @@ func (c *httpClient) Register(ctx context.Context, username, password string) er
 	req.Header.Set("Content-Type", "application/json")
 
 	// Выполняем запрос
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		// TODO: Добавить обработку сетевых ошибок (таймауты, недоступность сервера)
 		return fmt.Errorf("ошибка выполнения запроса на регистрацию: %w", err)
@@ -88,8 +96,9 @@ func (c *httpClient) Register(ctx context.Context, username, password string) er
 
 	// Проверяем статус код ответа
 	if resp.StatusCode != http.StatusCreated {
-		// TODO: Читать тело ответа для получения сообщения об ошибке от сервера
-		return fmt.Errorf("ошибка регистрации на сервере: статус %d", resp.StatusCode)
+		return responseError(resp, "ошибка регистрации на сервере", map[int]error{
+			http.StatusConflict: ErrUsernameTaken,
+		})
 	}
 
 	return nil // Успешная регистрация
@@ -97,7 +106,7 @@ func (c *httpClient) Register(ctx context.Context, username, password string) er
 
 // Login отправляет запрос на вход на сервер и сохраняет токен.
 func (c *httpClient) Login(ctx context.Context, username, password string) (string, error) {
-	loginURL, err := url.JoinPath(c.baseURL, "/api/login")
+	loginURL, err := url.JoinPath(c.baseURL, apiPrefix, "login")
 	if err != nil {
 		return "", fmt.Errorf("ошибка формирования URL для входа: %w", err)
 	}
@@ -117,7 +126,7 @@ func (c *httpClient) Login(ctx context.Context, username, password string) (stri
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		// TODO: Добавить обработку сетевых ошибок
 		return "", fmt.Errorf("ошибка выполнения запроса на вход: %w", err)
@@ -125,11 +134,10 @@ func (c *httpClient) Login(ctx context.Context, username, password string) (stri
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		// TODO: Читать тело ответа для получения сообщения об ошибке
-		if resp.StatusCode == http.StatusUnauthorized {
-			return "", errors.New("неверное имя пользователя или пароль") // Можно вернуть кастомную ошибку
-		}
-		return "", fmt.Errorf("ошибка входа на сервере: статус %d", resp.StatusCode)
+		return "", responseError(resp, "ошибка входа на сервере", map[int]error{
+			http.StatusUnauthorized: ErrInvalidCredentials,
+			http.StatusForbidden:    ErrAccountDisabled,
+		})
 	}
 
 	// Декодируем ответ для получения токена
@@ -157,9 +165,15 @@ func (c *httpClient) setAuthHeader(req *http.Request) error {
 	return nil
 }
 
+// do выполняет запрос, добавляя заголовки со сведениями о клиенте (см. SetClientInfo).
+func (c *httpClient) do(req *http.Request) (*http.Response, error) {
+	setClientHeaders(req)
+	return c.httpClient.Do(req)
+}
+
 // GetVaultMetadata получает метаданные текущей версии хранилища с сервера.
 func (c *httpClient) GetVaultMetadata(ctx context.Context) (*models.VaultVersion, error) {
-	metadataURL, err := url.JoinPath(c.baseURL, "/api/vault")
+	metadataURL, err := url.JoinPath(c.baseURL, apiPrefix, "vault")
 	if err != nil {
 		return nil, fmt.Errorf("ошибка формирования URL для метаданных: %w", err)
 	}
@@ -174,7 +188,7 @@ func (c *httpClient) GetVaultMetadata(ctx context.Context) (*models.VaultVersion
 		return nil, err
 	}
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		// TODO: Обработка сетевых ошибок
 		return nil, fmt.Errorf("ошибка выполнения запроса на получение метаданных: %w", err)
@@ -182,15 +196,9 @@ func (c *httpClient) GetVaultMetadata(ctx context.Context) (*models.VaultVersion
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		if resp.StatusCode == http.StatusNotFound {
-			return nil, errors.New("хранилище не найдено на сервере") // Кастомная ошибка
-		}
-		if resp.StatusCode == http.StatusUnauthorized {
-			// Возвращаем нашу специальную ошибку
-			return nil, ErrAuthorization
-		}
-		// TODO: Читать тело для деталей
-		return nil, fmt.Errorf("ошибка получения метаданных: статус %d", resp.StatusCode)
+		return nil, responseError(resp, "ошибка получения метаданных", map[int]error{
+			http.StatusNotFound: ErrVaultNotFound,
+		})
 	}
 
 	var metadata models.VaultVersion
@@ -203,7 +211,7 @@ func (c *httpClient) GetVaultMetadata(ctx context.Context) (*models.VaultVersion
 
 // UploadVault загружает данные хранилища на сервер.
 func (c *httpClient) UploadVault(ctx context.Context, data io.Reader, size int64, contentModifiedAt time.Time) error {
-	uploadURL, err := url.JoinPath(c.baseURL, "/api/vault/upload")
+	uploadURL, err := url.JoinPath(c.baseURL, apiPrefix, "vault/upload")
 	if err != nil {
 		return fmt.Errorf("ошибка формирования URL для загрузки: %w", err)
 	}
@@ -225,7 +233,7 @@ func (c *httpClient) UploadVault(ctx context.Context, data io.Reader, size int64
 		return err
 	}
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		// TODO: Обработка сетевых ошибок
 		return fmt.Errorf("ошибка выполнения запроса на загрузку: %w", err)
@@ -233,71 +241,21 @@ func (c *httpClient) UploadVault(ctx context.Context, data io.Reader, size int64
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		defer resp.Body.Close() // Закрываем тело в случае ошибки
-		if resp.StatusCode == http.StatusUnauthorized {
-			// Возвращаем нашу специальную ошибку
-			return ErrAuthorization
-		}
-		if resp.StatusCode == http.StatusConflict {
-			return errors.New("конфликт версий при загрузке") // Возвращаем ошибку конфликта
-		}
-		// TODO: Читать тело для деталей
-		return fmt.Errorf("ошибка загрузки на сервер: статус %d", resp.StatusCode)
-	}
-
-	return nil // Успешная загрузка
-}
-
-// DownloadVault скачивает текущую версию файла хранилища с сервера.
-func (c *httpClient) DownloadVault(ctx context.Context) (io.ReadCloser, *models.VaultVersion, error) {
-	downloadURL, err := url.JoinPath(c.baseURL, "/api/vault/download")
-	if err != nil {
-		return nil, nil, fmt.Errorf("ошибка формирования URL для скачивания: %w", err)
+		return responseError(resp, "ошибка загрузки на сервер", map[int]error{
+			http.StatusConflict:              ErrVersionConflict,
+			http.StatusRequestEntityTooLarge: ErrPayloadTooLarge,
+			http.StatusUnsupportedMediaType:  ErrVaultFileRejected,
+		})
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, downloadURL, nil)
-	if err != nil {
-		return nil, nil, fmt.Errorf("ошибка создания запроса на скачивание: %w", err)
-	}
-	if err = c.setAuthHeader(req); err != nil {
-		return nil, nil, err
-	}
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		// TODO: Обработка сетевых ошибок
-		return nil, nil, fmt.Errorf("ошибка выполнения запроса на скачивание: %w", err)
-	}
-	// НЕ закрываем resp.Body здесь, вызывающая сторона должна это сделать
-
-	if resp.StatusCode != http.StatusOK {
-		defer resp.Body.Close() // Закрываем тело в случае ошибки
-		if resp.StatusCode == http.StatusNotFound {
-			return nil, nil, errors.New("хранилище не найдено для скачивания")
-		}
-		if resp.StatusCode == http.StatusUnauthorized {
-			// Возвращаем нашу специальную ошибку
-			return nil, nil, ErrAuthorization
-		}
-		// TODO: Читать тело для деталей
-		return nil, nil, fmt.Errorf("ошибка скачивания с сервера: статус %d", resp.StatusCode)
-	}
-
-	// Пытаемся извлечь метаданные из заголовков (если сервер их туда кладет - пока нет)
-	// Здесь мы не можем получить метаданные версии из ответа Download так просто.
-	// API сервера возвращает только файл. Клиенту нужно будет отдельно запросить метаданные,
-	// если они нужны после скачивания. Либо сервер должен передавать их, например, в заголовках.
-	// Пока возвращаем nil для метаданных.
-	// Можно было бы распарсить Content-Length, если он есть.
-	var meta *models.VaultVersion // Заглушка
-
-	return resp.Body, meta, nil // Возвращаем тело ответа (io.ReadCloser)
+	// Сервер возвращает метаданные созданной версии; клиенту достаточно факта успешной загрузки
+	return nil
 }
 
 // ListVersions получает список версий хранилища.
 // Возвращает список версий и ID текущей версии.
 func (c *httpClient) ListVersions(ctx context.Context, limit, offset int) ([]models.VaultVersion, int64, error) {
-	listURL, err := url.JoinPath(c.baseURL, "/api/vault/versions")
+	listURL, err := url.JoinPath(c.baseURL, apiPrefix, "vault/versions")
 	if err != nil {
 		return nil, 0, fmt.Errorf("ошибка формирования URL для списка версий: %w", err)
 	}
@@ -322,7 +280,7 @@ func (c *httpClient) ListVersions(ctx context.Context, limit, offset int) ([]mod
 		return nil, 0, err
 	}
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		// TODO: Обработка сетевых ошибок
 		return nil, 0, fmt.Errorf("ошибка выполнения запроса на список версий: %w", err)
@@ -330,12 +288,7 @@ func (c *httpClient) ListVersions(ctx context.Context, limit, offset int) ([]mod
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		if resp.StatusCode == http.StatusUnauthorized {
-			// Возвращаем нашу специальную ошибку
-			return nil, 0, ErrAuthorization
-		}
-		// TODO: Читать тело для деталей
-		return nil, 0, fmt.Errorf("ошибка получения списка версий: статус %d", resp.StatusCode)
+		return nil, 0, responseError(resp, "ошибка получения списка версий", nil)
 	}
 
 	// Создаем анонимную структуру для декодирования ответа
@@ -353,7 +306,7 @@ func (c *httpClient) ListVersions(ctx context.Context, limit, offset int) ([]mod
 
 // RollbackToVersion отправляет запрос на откат к указанной версии.
 func (c *httpClient) RollbackToVersion(ctx context.Context, versionID int64) error {
-	rollbackURL, err := url.JoinPath(c.baseURL, "/api/vault/rollback")
+	rollbackURL, err := url.JoinPath(c.baseURL, apiPrefix, "vault/rollback")
 	if err != nil {
 		return fmt.Errorf("ошибка формирования URL для отката: %w", err)
 	}
@@ -374,7 +327,7 @@ func (c *httpClient) RollbackToVersion(ctx context.Context, versionID int64) err
 		return err
 	}
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		// TODO: Обработка сетевых ошибок
 		return fmt.Errorf("ошибка выполнения запроса на откат: %w", err)
@@ -382,19 +335,10 @@ func (c *httpClient) RollbackToVersion(ctx context.Context, versionID int64) err
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusNoContent { // Ожидаем 204 No Content
-		if resp.StatusCode == http.StatusUnauthorized {
-			// Возвращаем нашу специальную ошибку
-			return ErrAuthorization
-		}
-		if resp.StatusCode == http.StatusNotFound {
-			return errors.New("указанная версия или хранилище не найдены для отката")
-		}
-		if resp.StatusCode == http.StatusBadRequest {
-			// TODO: Читать тело ответа
-			return errors.New("неверный запрос на откат (например, некорректный ID версии)")
-		}
-		// TODO: Читать тело для деталей
-		return fmt.Errorf("ошибка отката на сервере: статус %d", resp.StatusCode)
+		return responseError(resp, "ошибка отката на сервере", map[int]error{
+			http.StatusNotFound:   ErrVersionNotFound,
+			http.StatusBadRequest: errors.New("неверный запрос на откат (например, некорректный ID версии)"),
+		})
 	}
 
 	return nil // Успешный откат
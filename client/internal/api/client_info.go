@@ -0,0 +1,162 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/maynagashev/gophkeeper/models"
+)
+
+// ClientName - имя клиентского приложения, передаваемое серверу в заголовке X-Client-Name.
+const ClientName = "gophkeeper-client"
+
+const (
+	// deviceIDBytes - длина случайного идентификатора устройства, байт.
+	deviceIDBytes = 16
+	// Права на каталог настроек и файл с идентификатором устройства.
+	configDirPermissions = 0o700
+	deviceIDPermissions  = 0o600
+)
+
+// ClientInfo - сведения о клиенте, которые передаются серверу с каждым запросом
+// (заголовки X-Client-Name, X-Client-Version и X-Device-ID, в gRPC - одноименные метаданные).
+// Сервер сохраняет их вместе с загруженными версиями хранилища.
+type ClientInfo struct {
+	Name     string
+	Version  string
+	DeviceID string
+}
+
+// clientInfo - сведения о клиенте, установленные SetClientInfo.
+var clientInfo atomic.Pointer[ClientInfo] //nolint:gochecknoglobals // Устанавливается один раз при запуске
+
+// SetClientInfo задает сведения о клиенте для всех API клиентов. Пустое имя заменяется на ClientName.
+func SetClientInfo(info ClientInfo) {
+	if info.Name == "" {
+		info.Name = ClientName
+	}
+	clientInfo.Store(&info)
+}
+
+// currentClientInfo возвращает сведения о клиенте (до вызова SetClientInfo - только имя).
+func currentClientInfo() ClientInfo {
+	if info := clientInfo.Load(); info != nil {
+		return *info
+	}
+	return ClientInfo{Name: ClientName}
+}
+
+// pairs возвращает непустые сведения о клиенте в виде пар "заголовок, значение".
+func (i ClientInfo) pairs() []string {
+	var kv []string
+	for _, p := range [][2]string{
+		{models.HeaderClientName, i.Name},
+		{models.HeaderClientVersion, i.Version},
+		{models.HeaderDeviceID, i.DeviceID},
+	} {
+		if p[1] != "" {
+			kv = append(kv, p[0], p[1])
+		}
+	}
+	return kv
+}
+
+// userAgent возвращает значение User-Agent клиента в виде "имя/версия" (без версии - только имя).
+// Без него сервер видит стандартный User-Agent библиотеки, одинаковый у всех клиентов на Go.
+func (i ClientInfo) userAgent() string {
+	if i.Version == "" {
+		return i.Name
+	}
+	return i.Name + "/" + i.Version
+}
+
+// setClientHeaders добавляет в запрос заголовки со сведениями о клиенте и User-Agent.
+func setClientHeaders(req *http.Request) {
+	info := currentClientInfo()
+	req.Header.Set("User-Agent", info.userAgent())
+	kv := info.pairs()
+	for i := 0; i < len(kv); i += 2 {
+		req.Header.Set(kv[i], kv[i+1])
+	}
+}
+
+// withClientMetadata добавляет в исходящие метаданные вызова сведения о клиенте.
+func withClientMetadata(ctx context.Context) context.Context {
+	kv := currentClientInfo().pairs()
+	for i := range kv {
+		if i%2 == 0 {
+			// Ключи метаданных gRPC передаются в нижнем регистре
+			kv[i] = strings.ToLower(kv[i])
+		}
+	}
+	if len(kv) == 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, kv...)
+}
+
+// clientInfoInterceptors возвращают параметры соединения, добавляющие сведения о клиенте в каждый вызов.
+// User-Agent задается при создании соединения, поэтому SetClientInfo нужно вызвать до него.
+func clientInfoInterceptors() []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithUserAgent(currentClientInfo().userAgent()),
+		grpc.WithChainUnaryInterceptor(func(
+			ctx context.Context, method string, req, reply any, cc *grpc.ClientConn,
+			invoker grpc.UnaryInvoker, opts ...grpc.CallOption,
+		) error {
+			return invoker(withClientMetadata(ctx), method, req, reply, cc, opts...)
+		}),
+		grpc.WithChainStreamInterceptor(func(
+			ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string,
+			streamer grpc.Streamer, opts ...grpc.CallOption,
+		) (grpc.ClientStream, error) {
+			return streamer(withClientMetadata(ctx), desc, cc, method, opts...)
+		}),
+	}
+}
+
+// DefaultDeviceIDPath возвращает путь к файлу с идентификатором устройства в каталоге настроек пользователя.
+func DefaultDeviceIDPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("не удалось определить каталог настроек: %w", err)
+	}
+	return filepath.Join(dir, "gophkeeper", "device_id"), nil
+}
+
+// LoadDeviceID читает идентификатор устройства из файла path, а при первом запуске
+// создает случайный идентификатор и сохраняет его, чтобы он не менялся между запусками.
+func LoadDeviceID(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		if id := strings.TrimSpace(string(data)); id != "" {
+			return id, nil
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("ошибка чтения идентификатора устройства: %w", err)
+	}
+
+	buf := make([]byte, deviceIDBytes)
+	if _, err = rand.Read(buf); err != nil {
+		return "", fmt.Errorf("ошибка генерации идентификатора устройства: %w", err)
+	}
+	id := hex.EncodeToString(buf)
+	if err = os.MkdirAll(filepath.Dir(path), configDirPermissions); err != nil {
+		return "", fmt.Errorf("ошибка создания каталога настроек: %w", err)
+	}
+	if err = os.WriteFile(path, []byte(id+"\n"), deviceIDPermissions); err != nil {
+		return "", fmt.Errorf("ошибка сохранения идентификатора устройства: %w", err)
+	}
+	return id, nil
+}
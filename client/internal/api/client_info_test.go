@@ -0,0 +1,91 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maynagashev/gophkeeper/client/internal/api"
+	"github.com/maynagashev/gophkeeper/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setTestClientInfo задает сведения о клиенте на время теста.
+func setTestClientInfo(t *testing.T) api.ClientInfo {
+	t.Helper()
+	info := api.ClientInfo{Name: api.ClientName, Version: "v1.2.0", DeviceID: "5f2c9a0e7b3d41e8"}
+	api.SetClientInfo(info)
+	t.Cleanup(func() { api.SetClientInfo(api.ClientInfo{}) })
+	return info
+}
+
+func TestHTTPClient_ClientHeaders(t *testing.T) {
+	info := setTestClientInfo(t)
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	client := api.NewHTTPClient(server.URL, api.TLSOptions{})
+	client.SetAuthToken("token")
+
+	err := client.UploadVault(context.Background(), strings.NewReader("kdbx"), 4, time.Now())
+
+	require.NoError(t, err)
+	assert.Equal(t, info.Name, got.Get(models.HeaderClientName))
+	assert.Equal(t, info.Version, got.Get(models.HeaderClientVersion))
+	assert.Equal(t, info.DeviceID, got.Get(models.HeaderDeviceID))
+	assert.Equal(t, "gophkeeper-client/v1.2.0", got.Get("User-Agent"))
+}
+
+func TestGRPCClient_ClientMetadata(t *testing.T) {
+	info := setTestClientInfo(t)
+	srv := &fakeGophKeeper{}
+	client := newTestGRPCClient(t, srv)
+	client.SetAuthToken(grpcTestToken)
+
+	err := client.UploadVault(context.Background(), strings.NewReader("kdbx"), 4, time.Now())
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{info.Name}, srv.callMD.Get(models.HeaderClientName))
+	assert.Equal(t, []string{info.Version}, srv.callMD.Get(models.HeaderClientVersion))
+	assert.Equal(t, []string{info.DeviceID}, srv.callMD.Get(models.HeaderDeviceID))
+	require.Len(t, srv.callMD.Get("user-agent"), 1)
+	assert.True(t, strings.HasPrefix(srv.callMD.Get("user-agent")[0], "gophkeeper-client/v1.2.0"),
+		srv.callMD.Get("user-agent"))
+}
+
+func TestLoadDeviceID(t *testing.T) {
+	t.Run("Идентификатор создается при первом запуске и сохраняется", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "gophkeeper", "device_id")
+
+		id, err := api.LoadDeviceID(path)
+		require.NoError(t, err)
+		assert.Len(t, id, 32)
+
+		again, err := api.LoadDeviceID(path)
+		require.NoError(t, err)
+		assert.Equal(t, id, again)
+
+		stat, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o600), stat.Mode().Perm())
+	})
+
+	t.Run("Используется существующий идентификатор", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "device_id")
+		require.NoError(t, os.WriteFile(path, []byte("existing-id\n"), 0o600))
+
+		id, err := api.LoadDeviceID(path)
+
+		require.NoError(t, err)
+		assert.Equal(t, "existing-id", id)
+	})
+}
@@ -2,12 +2,16 @@ package api_test
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -77,7 +81,7 @@ func TestHTTPClient_UploadVault(t *testing.T) {
 			server := httptest.NewServer(tt.serverHandler)
 			defer server.Close()
 
-			client := api.NewHTTPClient(server.URL)
+			client := api.NewHTTPClient(server.URL, api.TLSOptions{})
 			client.SetAuthToken(testToken)
 
 			reader := strings.NewReader(testData)
@@ -98,6 +102,26 @@ func TestHTTPClient_UploadVault(t *testing.T) {
 		})
 	}
 
+	t.Run("Сервер отклонил файл (415)", func(_ *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			_ = json.NewEncoder(w).Encode(models.ErrorResponse{
+				Code:    models.ErrCodeUnsupportedMedia,
+				Message: "Файл не является базой KeePass поддерживаемой версии (KDBX 3.x или 4.x)",
+			})
+		}))
+		defer server.Close()
+
+		client := api.NewHTTPClient(server.URL, api.TLSOptions{})
+		client.SetAuthToken(testToken)
+
+		err := client.UploadVault(context.Background(), strings.NewReader(testData), testSize, testModTime)
+
+		require.ErrorIs(err, api.ErrVaultFileRejected)
+		assert.Contains(err.Error(), "KDBX 3.x или 4.x")
+	})
+
 	// Тест без токена
 	t.Run("Без токена авторизации", func(_ *testing.T) {
 		server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
@@ -105,7 +129,7 @@ func TestHTTPClient_UploadVault(t *testing.T) {
 		}))
 		defer server.Close()
 
-		client := api.NewHTTPClient(server.URL)
+		client := api.NewHTTPClient(server.URL, api.TLSOptions{})
 		// Не вызываем SetAuthToken
 
 		reader := strings.NewReader(testData)
@@ -205,7 +229,7 @@ func TestHTTPClient_GetVaultMetadata(t *testing.T) {
 			server := httptest.NewServer(tt.serverHandler)
 			defer server.Close()
 
-			client := api.NewHTTPClient(server.URL)
+			client := api.NewHTTPClient(server.URL, api.TLSOptions{})
 			client.SetAuthToken(testToken)
 
 			meta, err := client.GetVaultMetadata(context.Background())
@@ -234,7 +258,7 @@ func TestHTTPClient_GetVaultMetadata(t *testing.T) {
 		}))
 		defer server.Close()
 
-		client := api.NewHTTPClient(server.URL)
+		client := api.NewHTTPClient(server.URL, api.TLSOptions{})
 		// Не вызываем SetAuthToken
 
 		meta, err := client.GetVaultMetadata(context.Background())
@@ -302,7 +326,7 @@ func TestHTTPClient_Register(t *testing.T) {
 				assert.NoError(err)
 			},
 			expectedErr:    true,
-			expectedErrMsg: "ошибка регистрации на сервере: статус 409",
+			expectedErrMsg: "имя пользователя уже занято",
 		},
 		{
 			name: "Ошибка сервера (500)",
@@ -319,7 +343,7 @@ func TestHTTPClient_Register(t *testing.T) {
 			server := httptest.NewServer(tt.serverHandler)
 			defer server.Close()
 
-			client := api.NewHTTPClient(server.URL)
+			client := api.NewHTTPClient(server.URL, api.TLSOptions{})
 			err := client.Register(context.Background(), testUsername, testPassword)
 
 			if tt.expectedErr {
@@ -436,7 +460,7 @@ func TestHTTPClient_Login(t *testing.T) {
 			server := httptest.NewServer(tt.serverHandler)
 			defer server.Close()
 
-			client := api.NewHTTPClient(server.URL)
+			client := api.NewHTTPClient(server.URL, api.TLSOptions{})
 			token, err := client.Login(context.Background(), testUsername, testPassword)
 
 			if tt.expectedErr {
@@ -489,7 +513,7 @@ func TestHTTPClient_DownloadVault(t *testing.T) {
 			},
 			expectedData:   "",
 			expectedErr:    true,
-			expectedErrMsg: "хранилище не найдено для скачивания",
+			expectedErrMsg: "хранилище не найдено на сервере",
 		},
 		{
 			name: "Ошибка авторизации (401)",
@@ -516,10 +540,10 @@ func TestHTTPClient_DownloadVault(t *testing.T) {
 			server := httptest.NewServer(tt.serverHandler)
 			defer server.Close()
 
-			client := api.NewHTTPClient(server.URL)
+			client := api.NewHTTPClient(server.URL, api.TLSOptions{})
 			client.SetAuthToken(testToken)
 
-			reader, meta, err := client.DownloadVault(context.Background())
+			reader, meta, err := client.DownloadVault(context.Background(), "")
 
 			if tt.expectedErr {
 				require.Error(err)
@@ -535,8 +559,10 @@ func TestHTTPClient_DownloadVault(t *testing.T) {
 			} else {
 				require.NoError(err)
 				assert.NotNil(reader)
-				// Meta в данной реализации всегда nil, проверяем это
-				assert.Nil(meta)
+				// Метаданные извлекаются из заголовков ответа
+				require.NotNil(meta)
+				require.NotNil(meta.SizeBytes)
+				assert.Equal(int64(len(tt.expectedData)), *meta.SizeBytes)
 
 				// Проверяем содержимое полученного reader
 				readData, readErr := io.ReadAll(reader)
@@ -557,10 +583,10 @@ func TestHTTPClient_DownloadVault(t *testing.T) {
 		}))
 		defer server.Close()
 
-		client := api.NewHTTPClient(server.URL)
+		client := api.NewHTTPClient(server.URL, api.TLSOptions{})
 		// Не вызываем SetAuthToken
 
-		reader, meta, err := client.DownloadVault(context.Background())
+		reader, meta, err := client.DownloadVault(context.Background(), "")
 
 		require.Error(err)
 		assert.Nil(reader)
@@ -569,6 +595,85 @@ func TestHTTPClient_DownloadVault(t *testing.T) {
 	})
 }
 
+// TestHTTPClient_DownloadVaultConditional тестирует условное и возобновляемое скачивание хранилища.
+func TestHTTPClient_DownloadVaultConditional(t *testing.T) {
+	content := strings.Repeat("gophkeeper vault data ", 100)
+	sum := sha256.Sum256([]byte(content))
+	checksum := hex.EncodeToString(sum[:])
+	etag := `"` + checksum + `"`
+
+	t.Run("Версия не изменилась (304)", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, etag, r.Header.Get("If-None-Match"))
+			w.Header().Set("ETag", etag)
+			w.WriteHeader(http.StatusNotModified)
+		}))
+		defer server.Close()
+		client := api.NewHTTPClient(server.URL, api.TLSOptions{})
+		client.SetAuthToken("token")
+
+		reader, meta, err := client.DownloadVault(context.Background(), checksum)
+
+		require.ErrorIs(t, err, api.ErrNotModified)
+		assert.Nil(t, reader)
+		require.NotNil(t, meta)
+		require.NotNil(t, meta.Checksum)
+		assert.Equal(t, checksum, *meta.Checksum)
+	})
+
+	t.Run("Обрыв соединения продолжается запросом Range", func(t *testing.T) {
+		var requests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			w.Header().Set("ETag", etag)
+			if rangeHeader := r.Header.Get("Range"); rangeHeader != "" {
+				assert.Equal(t, etag, r.Header.Get("If-Range"))
+				offset, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(rangeHeader, "bytes="), "-"))
+				assert.NoError(t, err)
+				w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, len(content)-1, len(content)))
+				w.WriteHeader(http.StatusPartialContent)
+				_, _ = w.Write([]byte(content[offset:]))
+				return
+			}
+			// Первый ответ обрывается на середине файла
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(content[:len(content)/2]))
+			w.(http.Flusher).Flush()
+			panic(http.ErrAbortHandler)
+		}))
+		defer server.Close()
+		client := api.NewHTTPClient(server.URL, api.TLSOptions{})
+		client.SetAuthToken("token")
+
+		reader, _, err := client.DownloadVault(context.Background(), "")
+		require.NoError(t, err)
+		defer reader.Close()
+		data, err := io.ReadAll(reader)
+
+		require.NoError(t, err)
+		assert.Equal(t, content, string(data))
+		assert.Equal(t, int32(2), requests.Load())
+	})
+
+	t.Run("Несовпадение контрольной суммы", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("ETag", etag)
+			_, _ = w.Write([]byte("corrupted"))
+		}))
+		defer server.Close()
+		client := api.NewHTTPClient(server.URL, api.TLSOptions{})
+		client.SetAuthToken("token")
+
+		reader, _, err := client.DownloadVault(context.Background(), "")
+		require.NoError(t, err)
+		defer reader.Close()
+		_, err = io.ReadAll(reader)
+
+		require.ErrorIs(t, err, api.ErrDownloadCorrupted)
+	})
+}
+
 // TestHTTPClient_ListVersions тестирует функцию получения списка версий хранилища.
 func TestHTTPClient_ListVersions(t *testing.T) {
 	assert := assert.New(t)
@@ -676,7 +781,7 @@ func TestHTTPClient_ListVersions(t *testing.T) {
 			server := httptest.NewServer(tt.serverHandler)
 			defer server.Close()
 
-			client := api.NewHTTPClient(server.URL)
+			client := api.NewHTTPClient(server.URL, api.TLSOptions{})
 			client.SetAuthToken(testToken)
 
 			versions, currentID, err := client.ListVersions(context.Background(), testLimit, testOffset)
@@ -708,7 +813,7 @@ func TestHTTPClient_ListVersions(t *testing.T) {
 		}))
 		defer server.Close()
 
-		client := api.NewHTTPClient(server.URL)
+		client := api.NewHTTPClient(server.URL, api.TLSOptions{})
 		// Не вызываем SetAuthToken
 
 		versions, currentID, err := client.ListVersions(context.Background(), testLimit, testOffset)
@@ -758,7 +863,7 @@ func TestHTTPClient_RollbackToVersion(t *testing.T) {
 				w.WriteHeader(http.StatusNotFound)
 			},
 			expectedErr:    true,
-			expectedErrMsg: "указанная версия или хранилище не найдены для отката",
+			expectedErrMsg: "версия хранилища не найдена на сервере",
 		},
 		{
 			name: "Неверный запрос (400)",
@@ -791,7 +896,7 @@ func TestHTTPClient_RollbackToVersion(t *testing.T) {
 			server := httptest.NewServer(tt.serverHandler)
 			defer server.Close()
 
-			client := api.NewHTTPClient(server.URL)
+			client := api.NewHTTPClient(server.URL, api.TLSOptions{})
 			client.SetAuthToken(testToken)
 
 			err := client.RollbackToVersion(context.Background(), testVersionID)
@@ -818,7 +923,7 @@ func TestHTTPClient_RollbackToVersion(t *testing.T) {
 		}))
 		defer server.Close()
 
-		client := api.NewHTTPClient(server.URL)
+		client := api.NewHTTPClient(server.URL, api.TLSOptions{})
 		// Не вызываем SetAuthToken
 
 		err := client.RollbackToVersion(context.Background(), testVersionID)
@@ -827,3 +932,29 @@ func TestHTTPClient_RollbackToVersion(t *testing.T) {
 		assert.Contains(err.Error(), "токен аутентификации отсутствует")
 	})
 }
+
+func TestHTTPClient_APIv2Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v2/vault", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusNotFound)
+		err := json.NewEncoder(w).Encode(models.ErrorResponse{
+			Code:      models.ErrCodeVaultNotFound,
+			Message:   "Хранилище не найдено",
+			RequestID: "req-7",
+		})
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := api.NewHTTPClient(server.URL, api.TLSOptions{})
+	client.SetAuthToken("test-jwt-token")
+
+	_, err := client.GetVaultMetadata(context.Background())
+
+	require.ErrorIs(t, err, api.ErrVaultNotFound)
+	var apiErr *api.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	assert.Equal(t, "Хранилище не найдено (код vault_not_found, запрос req-7)", err.Error())
+}
@@ -0,0 +1,196 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/maynagashev/gophkeeper/models"
+)
+
+// maxDownloadResumes - сколько раз прерванное скачивание продолжается с места обрыва.
+const maxDownloadResumes = 3
+
+// DownloadVault скачивает текущую версию файла хранилища с сервера.
+// ifNoneMatch - контрольная сумма (SHA256) локальной копии: если она совпадает с серверной,
+// сервер отвечает 304 и возвращается ErrNotModified без передачи файла.
+// При обрыве соединения скачивание продолжается запросом Range с If-Range, а собранный
+// файл сверяется с контрольной суммой из ETag.
+func (c *httpClient) DownloadVault(
+	ctx context.Context,
+	ifNoneMatch string,
+) (io.ReadCloser, *models.VaultVersion, error) {
+	downloadURL, err := url.JoinPath(c.baseURL, apiPrefix, "vault/download")
+	if err != nil {
+		return nil, nil, fmt.Errorf("ошибка формирования URL для скачивания: %w", err)
+	}
+
+	headers := http.Header{}
+	if ifNoneMatch != "" {
+		headers.Set("If-None-Match", quoteETag(ifNoneMatch))
+	}
+	resp, err := c.doDownloadRequest(ctx, downloadURL, headers)
+	if err != nil {
+		return nil, nil, err
+	}
+	// НЕ закрываем resp.Body здесь, вызывающая сторона должна это сделать
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close() // Закрываем тело в случае ошибки
+		if resp.StatusCode == http.StatusNotModified {
+			return nil, versionFromHeaders(resp.Header), ErrNotModified
+		}
+		return nil, nil, responseError(resp, "ошибка скачивания с сервера", map[int]error{
+			http.StatusNotFound: ErrVaultNotFound,
+		})
+	}
+
+	meta := versionFromHeaders(resp.Header)
+	return &resumingBody{
+		ctx:         ctx,
+		client:      c,
+		downloadURL: downloadURL,
+		etag:        resp.Header.Get("ETag"),
+		body:        resp.Body,
+		hasher:      sha256.New(),
+	}, meta, nil
+}
+
+// doDownloadRequest выполняет аутентифицированный GET запрос на скачивание с дополнительными заголовками.
+func (c *httpClient) doDownloadRequest(
+	ctx context.Context,
+	downloadURL string,
+	headers http.Header,
+) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, downloadURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания запроса на скачивание: %w", err)
+	}
+	if err = c.setAuthHeader(req); err != nil {
+		return nil, err
+	}
+	for key, values := range headers {
+		req.Header[key] = values
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		// TODO: Обработка сетевых ошибок
+		return nil, fmt.Errorf("ошибка выполнения запроса на скачивание: %w", err)
+	}
+	return resp, nil
+}
+
+// versionFromHeaders извлекает метаданные версии из заголовков ответа на скачивание.
+func versionFromHeaders(h http.Header) *models.VaultVersion {
+	meta := &models.VaultVersion{}
+	if etag := h.Get("ETag"); etag != "" {
+		checksum := strings.Trim(strings.TrimPrefix(etag, "W/"), `"`)
+		meta.Checksum = &checksum
+	}
+	if size, err := strconv.ParseInt(h.Get("Content-Length"), 10, 64); err == nil {
+		meta.SizeBytes = &size
+	}
+	if modified, err := http.ParseTime(h.Get("Last-Modified")); err == nil {
+		meta.CreatedAt = modified
+	}
+	return meta
+}
+
+// quoteETag оборачивает контрольную сумму в кавычки, как того требует формат ETag.
+func quoteETag(checksum string) string {
+	if strings.HasPrefix(checksum, `"`) {
+		return checksum
+	}
+	return `"` + checksum + `"`
+}
+
+// resumingBody - тело скачиваемого файла, которое при обрыве соединения запрашивает
+// оставшуюся часть файла (Range) и проверяет целостность собранного файла по ETag.
+type resumingBody struct {
+	ctx         context.Context
+	client      *httpClient
+	downloadURL string
+	etag        string
+	body        io.ReadCloser
+	hasher      hash.Hash
+	received    int64
+	resumes     int
+}
+
+// Read читает данные файла, продолжая скачивание после обрыва соединения.
+func (b *resumingBody) Read(p []byte) (int, error) {
+	for {
+		n, err := b.body.Read(p)
+		b.hasher.Write(p[:n])
+		b.received += int64(n)
+		switch {
+		case err == nil:
+			return n, nil
+		case errors.Is(err, io.EOF):
+			return n, b.verify()
+		case n > 0:
+			// Сначала отдаем прочитанные данные, ошибка повторится при следующем чтении
+			return n, nil
+		}
+
+		if resumeErr := b.resume(err); resumeErr != nil {
+			return 0, resumeErr
+		}
+	}
+}
+
+// resume запрашивает оставшуюся часть файла после ошибки чтения cause.
+func (b *resumingBody) resume(cause error) error {
+	if b.etag == "" || b.resumes >= maxDownloadResumes || b.ctx.Err() != nil {
+		return cause
+	}
+	b.resumes++
+	slog.Warn("Скачивание прервано, продолжаем с места обрыва",
+		"received", b.received, "attempt", b.resumes, "error", cause)
+	_ = b.body.Close()
+
+	headers := http.Header{}
+	headers.Set("Range", "bytes="+strconv.FormatInt(b.received, 10)+"-")
+	headers.Set("If-Range", b.etag)
+	resp, err := b.client.doDownloadRequest(b.ctx, b.downloadURL, headers)
+	if err != nil {
+		b.body = io.NopCloser(strings.NewReader(""))
+		return fmt.Errorf("ошибка продолжения скачивания: %w", err)
+	}
+	b.body = resp.Body
+	if resp.StatusCode != http.StatusPartialContent {
+		// 200 означает, что версия на сервере изменилась и продолжить скачивание нельзя
+		return fmt.Errorf("не удалось продолжить скачивание: статус %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// verify сверяет контрольную сумму полученного файла с ETag.
+func (b *resumingBody) verify() error {
+	if b.etag == "" {
+		return io.EOF
+	}
+	expected := strings.Trim(strings.TrimPrefix(b.etag, "W/"), `"`)
+	if hex.EncodeToString(b.hasher.Sum(nil)) != expected {
+		return ErrDownloadCorrupted
+	}
+	return io.EOF
+}
+
+// Close закрывает текущее соединение.
+func (b *resumingBody) Close() error {
+	return b.body.Close()
+}
+
+// ErrDownloadCorrupted сигнализирует, что контрольная сумма скачанного файла не совпала с ETag.
+var ErrDownloadCorrupted = errors.New("контрольная сумма скачанного файла не совпадает с ETag сервера")
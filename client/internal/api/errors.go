@@ -0,0 +1,111 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/maynagashev/gophkeeper/models"
+)
+
+// maxErrorBodySize - максимальный размер тела ответа об ошибке, который читает клиент.
+const maxErrorBodySize = 64 << 10
+
+// Ошибки API. HTTP-клиент определяет их по машиночитаемому коду ответа API v2,
+// gRPC-клиент - по коду статуса вызова. Проверяются через errors.Is.
+var (
+	// ErrAuthorization сигнализирует об ошибке авторизации (401).
+	ErrAuthorization = errors.New("ошибка авторизации")
+	// ErrInvalidCredentials - неверное имя пользователя или пароль при входе.
+	ErrInvalidCredentials = errors.New("неверное имя пользователя или пароль")
+	// ErrAccountDisabled - учетная запись отключена администратором.
+	ErrAccountDisabled = errors.New("учетная запись отключена")
+	// ErrUsernameTaken - имя пользователя уже занято при регистрации.
+	ErrUsernameTaken = errors.New("имя пользователя уже занято")
+	// ErrForbidden - доступ к ресурсу запрещен.
+	ErrForbidden = errors.New("доступ запрещен")
+	// ErrVaultNotFound - у пользователя еще нет хранилища на сервере.
+	ErrVaultNotFound = errors.New("хранилище не найдено на сервере")
+	// ErrVersionNotFound - указанная версия хранилища не найдена.
+	ErrVersionNotFound = errors.New("версия хранилища не найдена на сервере")
+	// ErrVersionConflict - на сервере более новая или конфликтующая версия хранилища.
+	ErrVersionConflict = errors.New("конфликт версий при загрузке")
+	// ErrPayloadTooLarge - размер хранилища превышает лимит сервера.
+	ErrPayloadTooLarge = errors.New("размер хранилища превышает допустимый на сервере")
+	// ErrVaultFileRejected - сервер отклонил файл: он не является базой KeePass поддерживаемой версии или обрезан.
+	ErrVaultFileRejected = errors.New("сервер отклонил файл: это не база KeePass (KDBX 3.x/4.x) или файл поврежден")
+	// ErrServerTimeout - сервер не успел выполнить операцию.
+	ErrServerTimeout = errors.New("превышено время выполнения операции на сервере")
+	// ErrServerVaultCorrupted - файл хранилища на сервере поврежден.
+	ErrServerVaultCorrupted = errors.New("файл хранилища на сервере поврежден")
+)
+
+// errorsByCode сопоставляет коды ошибок API v2 с ошибками клиента.
+var errorsByCode = map[string]error{ //nolint:gochecknoglobals // Неизменяемая таблица соответствия
+	models.ErrCodeUnauthorized:       ErrAuthorization,
+	models.ErrCodeInvalidCredentials: ErrInvalidCredentials,
+	models.ErrCodeAccountDisabled:    ErrAccountDisabled,
+	models.ErrCodeUsernameTaken:      ErrUsernameTaken,
+	models.ErrCodeForbidden:          ErrForbidden,
+	models.ErrCodeVaultNotFound:      ErrVaultNotFound,
+	models.ErrCodeVersionNotFound:    ErrVersionNotFound,
+	models.ErrCodeVersionConflict:    ErrVersionConflict,
+	models.ErrCodePayloadTooLarge:    ErrPayloadTooLarge,
+	models.ErrCodeUnsupportedMedia:   ErrVaultFileRejected,
+	models.ErrCodeTimeout:            ErrServerTimeout,
+	models.ErrCodeVaultCorrupted:     ErrServerVaultCorrupted,
+}
+
+// APIError - ошибка, которую сервер вернул в формате models.ErrorResponse.
+// Unwrap возвращает ошибку клиента, соответствующую коду, поэтому
+// errors.Is(err, ErrVaultNotFound) и подобные проверки работают без разбора текста.
+type APIError struct {
+	StatusCode int // HTTP-статус ответа
+	models.ErrorResponse
+}
+
+// Error возвращает сообщение сервера и ID запроса, по которому его можно найти в журнале сервера.
+func (e *APIError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		if known := e.Unwrap(); known != nil {
+			msg = known.Error()
+		} else {
+			msg = fmt.Sprintf("ошибка сервера: статус %d", e.StatusCode)
+		}
+	}
+	if e.RequestID != "" {
+		return fmt.Sprintf("%s (код %s, запрос %s)", msg, e.Code, e.RequestID)
+	}
+	return fmt.Sprintf("%s (код %s)", msg, e.Code)
+}
+
+// Unwrap возвращает ошибку клиента для кода ответа (nil для неизвестных кодов).
+func (e *APIError) Unwrap() error {
+	return errorsByCode[e.Code]
+}
+
+// responseError формирует ошибку по неуспешному ответу сервера.
+// Ответ в формате API v2 возвращается как *APIError. Для других ответов (например, от прокси)
+// ошибка выбирается по статусу: из byStatus, 401 - ErrAuthorization, иначе "<action>: статус N".
+func responseError(resp *http.Response, action string, byStatus map[int]error) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType == "application/json" {
+		var apiErr models.ErrorResponse
+		if err := json.Unmarshal(body, &apiErr); err == nil && apiErr.Code != "" {
+			return &APIError{StatusCode: resp.StatusCode, ErrorResponse: apiErr}
+		}
+	}
+
+	if err, ok := byStatus[resp.StatusCode]; ok {
+		return err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		return ErrAuthorization
+	}
+	return fmt.Errorf("%s: статус %d", action, resp.StatusCode)
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/maynagashev/gophkeeper/models"
+)
+
+// SubscribeEvents открывает поток Server-Sent Events (GET /api/v2/vault/events)
+// и передает полученные события в возвращаемый канал.
+func (c *httpClient) SubscribeEvents(ctx context.Context) (<-chan models.VaultEvent, error) {
+	eventsURL, err := url.JoinPath(c.baseURL, apiPrefix, "vault/events")
+	if err != nil {
+		return nil, fmt.Errorf("ошибка формирования URL для подписки на события: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, eventsURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания запроса на подписку на события: %w", err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if err = c.setAuthHeader(req); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка выполнения запроса на подписку на события: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, responseError(resp, "ошибка подписки на события", nil)
+	}
+
+	events := make(chan models.VaultEvent)
+	go func() {
+		defer close(events)
+		defer resp.Body.Close()
+		readServerSentEvents(ctx, bufio.NewScanner(resp.Body), events)
+	}()
+	return events, nil
+}
+
+// readServerSentEvents разбирает поток SSE и отправляет события в канал до конца потока.
+// Поддерживаются поля "event" и "data"; комментарии (строки, начинающиеся с ':') игнорируются.
+func readServerSentEvents(ctx context.Context, scanner *bufio.Scanner, events chan<- models.VaultEvent) {
+	var eventType string
+	var data strings.Builder
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			// Пустая строка завершает событие
+			if data.Len() > 0 {
+				var event models.VaultEvent
+				if err := json.Unmarshal([]byte(data.String()), &event); err != nil {
+					slog.Warn("Не удалось разобрать событие сервера", "error", err)
+				} else {
+					if event.Type == "" {
+						event.Type = eventType
+					}
+					select {
+					case events <- event:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+			eventType = ""
+			data.Reset()
+		case strings.HasPrefix(line, ":"):
+			// Комментарий (пинг сервера)
+		case strings.HasPrefix(line, "event:"):
+			eventType = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		slog.Debug("Поток событий сервера прерван", "error", err)
+	}
+}
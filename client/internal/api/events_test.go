@@ -0,0 +1,84 @@
+package api_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/maynagashev/gophkeeper/client/internal/api"
+	"github.com/maynagashev/gophkeeper/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPClient_SubscribeEvents(t *testing.T) {
+	testToken := "test-token"
+
+	t.Run("Получение событий из потока", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/v2/vault/events", r.URL.Path)
+			assert.Equal(t, "Bearer "+testToken, r.Header.Get("Authorization"))
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.WriteHeader(http.StatusOK)
+			_, _ = fmt.Fprint(w, ": ping\n\n")
+			_, _ = fmt.Fprint(w, "event: version_created\ndata: {\"version_id\":7,\"checksum\":\"abc\"}\n\n")
+			_, _ = fmt.Fprint(w, "event: rolled_back\ndata: не json\n\n")
+			_, _ = fmt.Fprint(w, "event: rolled_back\ndata: {\"type\":\"rolled_back\",\"version_id\":3}\n\n")
+		}))
+		defer server.Close()
+
+		client := api.NewHTTPClient(server.URL, api.TLSOptions{})
+		client.SetAuthToken(testToken)
+		events, err := client.SubscribeEvents(context.Background())
+		require.NoError(t, err)
+
+		var received []models.VaultEvent
+		timeout := time.After(2 * time.Second)
+	loop:
+		for {
+			select {
+			case event, ok := <-events:
+				if !ok {
+					break loop
+				}
+				received = append(received, event)
+			case <-timeout:
+				t.Fatal("поток событий не завершился")
+			}
+		}
+
+		require.Len(t, received, 2)
+		assert.Equal(t, models.VaultEventVersionCreated, received[0].Type)
+		assert.Equal(t, int64(7), received[0].VersionID)
+		require.NotNil(t, received[0].Checksum)
+		assert.Equal(t, "abc", *received[0].Checksum)
+		assert.Equal(t, models.VaultEventRolledBack, received[1].Type)
+		assert.Equal(t, int64(3), received[1].VersionID)
+	})
+
+	t.Run("Ошибка авторизации", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer server.Close()
+
+		client := api.NewHTTPClient(server.URL, api.TLSOptions{})
+		client.SetAuthToken(testToken)
+		events, err := client.SubscribeEvents(context.Background())
+
+		require.ErrorIs(t, err, api.ErrAuthorization)
+		assert.Nil(t, events)
+	})
+
+	t.Run("Без токена авторизации", func(t *testing.T) {
+		client := api.NewHTTPClient("http://localhost:1", api.TLSOptions{})
+		_, err := client.SubscribeEvents(context.Background())
+
+		require.Error(t, err)
+		assert.False(t, errors.Is(err, api.ErrAuthorization))
+	})
+}
@@ -0,0 +1,369 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"log/slog"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/maynagashev/gophkeeper/models"
+	"github.com/maynagashev/gophkeeper/models/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// GRPCURLPrefix - префикс URL сервера, при котором клиент подключается к gRPC API (grpcs://host:port).
+const GRPCURLPrefix = "grpcs://"
+
+// uploadChunkSize - размер части файла в сообщениях Upload.
+const uploadChunkSize = 64 << 10
+
+// NewClient создает API клиент для URL сервера: для grpcs://host:port - клиент gRPC,
+// для остальных URL - HTTP клиент.
+func NewClient(serverURL string, tlsOptions TLSOptions) Client {
+	if address, ok := strings.CutPrefix(serverURL, GRPCURLPrefix); ok {
+		return NewGRPCClient(address, tlsOptions)
+	}
+	return NewHTTPClient(serverURL, tlsOptions)
+}
+
+// grpcClient реализует интерфейс Client поверх gRPC API сервера.
+type grpcClient struct {
+	client    pb.GophKeeperClient
+	connErr   error  // Ошибка создания соединения, возвращается из каждого вызова
+	authToken string // JWT токен для аутентифицированных вызовов
+}
+
+// NewGRPCClient создает API клиент, вызывающий gRPC API сервера по адресу host:port.
+// Соединение защищено TLS с проверкой сертификата в соответствии с tlsOptions
+// и устанавливается при первом вызове, в каждый вызов добавляются сведения о клиенте (см. SetClientInfo).
+// opts дополняют (и могут переопределить) параметры соединения.
+func NewGRPCClient(address string, tlsOptions TLSOptions, opts ...grpc.DialOption) Client {
+	opts = append(append([]grpc.DialOption{
+		grpc.WithTransportCredentials(credentials.NewTLS(tlsOptions.clientConfig())),
+	}, clientInfoInterceptors()...), opts...)
+	conn, err := grpc.NewClient(address, opts...)
+	if err != nil {
+		return &grpcClient{connErr: fmt.Errorf("ошибка подключения к gRPC серверу %s: %w", address, err)}
+	}
+	return &grpcClient{client: pb.NewGophKeeperClient(conn)}
+}
+
+// Register регистрирует нового пользователя.
+func (c *grpcClient) Register(ctx context.Context, username, password string) error {
+	if c.connErr != nil {
+		return c.connErr
+	}
+	_, err := c.client.Register(ctx, &pb.RegisterRequest{Username: username, Password: password})
+	if err != nil {
+		if status.Code(err) == codes.AlreadyExists {
+			return ErrUsernameTaken
+		}
+		return fmt.Errorf("ошибка регистрации на сервере: %s", status.Convert(err).Message())
+	}
+	return nil
+}
+
+// Login аутентифицирует пользователя и сохраняет токен.
+func (c *grpcClient) Login(ctx context.Context, username, password string) (string, error) {
+	if c.connErr != nil {
+		return "", c.connErr
+	}
+	resp, err := c.client.Login(ctx, &pb.LoginRequest{Username: username, Password: password})
+	if err != nil {
+		switch status.Code(err) {
+		case codes.Unauthenticated:
+			return "", ErrInvalidCredentials
+		case codes.PermissionDenied:
+			return "", ErrAccountDisabled
+		}
+		return "", fmt.Errorf("ошибка входа на сервере: %s", status.Convert(err).Message())
+	}
+	if resp.GetToken() == "" {
+		return "", errors.New("сервер вернул пустой токен")
+	}
+	c.authToken = resp.GetToken()
+	return resp.GetToken(), nil
+}
+
+// authContext добавляет токен в метаданные вызова.
+func (c *grpcClient) authContext(ctx context.Context) (context.Context, error) {
+	if c.connErr != nil {
+		return nil, c.connErr
+	}
+	if c.authToken == "" {
+		return nil, errors.New("токен аутентификации отсутствует")
+	}
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+c.authToken), nil
+}
+
+// GetVaultMetadata получает метаданные текущей версии хранилища.
+func (c *grpcClient) GetVaultMetadata(ctx context.Context) (*models.VaultVersion, error) {
+	ctx, err := c.authContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.client.GetMetadata(ctx, &pb.GetMetadataRequest{})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, ErrVaultNotFound
+		}
+		return nil, grpcError("ошибка получения метаданных", err)
+	}
+	return versionFromPB(resp), nil
+}
+
+// UploadVault загружает файл хранилища: метаданные, затем части файла.
+func (c *grpcClient) UploadVault(ctx context.Context, data io.Reader, size int64, contentModifiedAt time.Time) error {
+	ctx, err := c.authContext(ctx)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := c.client.Upload(ctx)
+	if err != nil {
+		return grpcError("ошибка загрузки на сервер", err)
+	}
+
+	err = stream.Send(&pb.UploadRequest{Payload: &pb.UploadRequest_Metadata{Metadata: &pb.UploadMetadata{
+		Size:              size,
+		ContentModifiedAt: timestamppb.New(contentModifiedAt),
+		ContentType:       "application/octet-stream",
+	}}})
+	buf := make([]byte, uploadChunkSize)
+	for err == nil {
+		n, readErr := data.Read(buf)
+		if n > 0 {
+			err = stream.Send(&pb.UploadRequest{Payload: &pb.UploadRequest_Chunk{Chunk: buf[:n]}})
+		}
+		if errors.Is(readErr, io.EOF) {
+			break
+		}
+		if readErr != nil {
+			return fmt.Errorf("ошибка чтения файла хранилища: %w", readErr)
+		}
+	}
+	// io.EOF от Send означает, что сервер завершил вызов: причину возвращает CloseAndRecv
+	if err != nil && !errors.Is(err, io.EOF) {
+		return grpcError("ошибка загрузки на сервер", err)
+	}
+	if _, err = stream.CloseAndRecv(); err != nil {
+		switch status.Code(err) {
+		case codes.Aborted:
+			return ErrVersionConflict
+		case codes.ResourceExhausted:
+			return ErrPayloadTooLarge
+		}
+		return grpcError("ошибка загрузки на сервер", err)
+	}
+	return nil
+}
+
+// DownloadVault скачивает текущую версию файла хранилища.
+// Если ifNoneMatch совпадает с контрольной суммой текущей версии, возвращает ErrNotModified.
+// Собранный файл сверяется с контрольной суммой из метаданных версии.
+func (c *grpcClient) DownloadVault(
+	ctx context.Context,
+	ifNoneMatch string,
+) (io.ReadCloser, *models.VaultVersion, error) {
+	ctx, err := c.authContext(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	stream, err := c.client.Download(ctx, &pb.DownloadRequest{IfNoneMatch: ifNoneMatch})
+	if err != nil {
+		cancel()
+		return nil, nil, grpcError("ошибка скачивания с сервера", err)
+	}
+	first, err := stream.Recv()
+	if err != nil {
+		cancel()
+		if status.Code(err) == codes.NotFound {
+			return nil, nil, ErrVaultNotFound
+		}
+		return nil, nil, grpcError("ошибка скачивания с сервера", err)
+	}
+	meta := first.GetMetadata()
+	if meta == nil {
+		cancel()
+		return nil, nil, errors.New("ошибка скачивания с сервера: нет метаданных версии")
+	}
+	version := versionFromPB(meta.GetVersion())
+	if meta.GetNotModified() {
+		cancel()
+		return nil, version, ErrNotModified
+	}
+	return &grpcDownloadBody{stream: stream, cancel: cancel, checksum: version.Checksum, hasher: sha256.New()},
+		version, nil
+}
+
+// grpcDownloadBody - тело скачиваемого файла, собираемое из сообщений потока Download.
+type grpcDownloadBody struct {
+	stream   grpc.ServerStreamingClient[pb.DownloadResponse]
+	cancel   context.CancelFunc
+	checksum *string
+	hasher   hash.Hash
+	pending  []byte
+}
+
+// Read читает данные файла и при завершении потока проверяет контрольную сумму.
+func (b *grpcDownloadBody) Read(p []byte) (int, error) {
+	for len(b.pending) == 0 {
+		msg, err := b.stream.Recv()
+		if errors.Is(err, io.EOF) {
+			if b.checksum != nil && hex.EncodeToString(b.hasher.Sum(nil)) != *b.checksum {
+				return 0, ErrDownloadCorrupted
+			}
+			return 0, io.EOF
+		}
+		if err != nil {
+			return 0, grpcError("ошибка скачивания с сервера", err)
+		}
+		b.pending = msg.GetChunk()
+		b.hasher.Write(b.pending)
+	}
+	n := copy(p, b.pending)
+	b.pending = b.pending[n:]
+	return n, nil
+}
+
+// Close отменяет вызов, если файл прочитан не полностью.
+func (b *grpcDownloadBody) Close() error {
+	b.cancel()
+	return nil
+}
+
+// ListVersions получает список версий хранилища и ID текущей версии.
+func (c *grpcClient) ListVersions(ctx context.Context, limit, offset int) ([]models.VaultVersion, int64, error) {
+	ctx, err := c.authContext(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	resp, err := c.client.ListVersions(ctx, &pb.ListVersionsRequest{
+		Limit:  int32(min(limit, math.MaxInt32)),  //nolint:gosec // Значение ограничено
+		Offset: int32(min(offset, math.MaxInt32)), //nolint:gosec // Значение ограничено
+	})
+	if err != nil {
+		return nil, 0, grpcError("ошибка получения списка версий", err)
+	}
+	versions := make([]models.VaultVersion, 0, len(resp.GetVersions()))
+	for _, v := range resp.GetVersions() {
+		versions = append(versions, *versionFromPB(v))
+	}
+	return versions, resp.GetCurrentVersionId(), nil
+}
+
+// RollbackToVersion откатывает хранилище к указанной версии.
+func (c *grpcClient) RollbackToVersion(ctx context.Context, versionID int64) error {
+	ctx, err := c.authContext(ctx)
+	if err != nil {
+		return err
+	}
+	if _, err = c.client.Rollback(ctx, &pb.RollbackRequest{VersionId: versionID}); err != nil {
+		switch status.Code(err) {
+		case codes.NotFound:
+			return ErrVersionNotFound
+		case codes.InvalidArgument:
+			return errors.New("неверный запрос на откат (например, некорректный ID версии)")
+		}
+		return grpcError("ошибка отката на сервере", err)
+	}
+	return nil
+}
+
+// SubscribeEvents подписывается на поток событий SubscribeEvents и передает события в канал.
+// Канал закрывается при разрыве соединения или отмене контекста.
+func (c *grpcClient) SubscribeEvents(ctx context.Context) (<-chan models.VaultEvent, error) {
+	ctx, err := c.authContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := c.client.SubscribeEvents(ctx, &pb.SubscribeEventsRequest{})
+	if err != nil {
+		return nil, grpcError("ошибка подписки на события", err)
+	}
+	// Сервер отправляет заголовки сразу после подписки; их отсутствие означает, что вызов завершился ошибкой
+	if header, _ := stream.Header(); header == nil {
+		_, err = stream.Recv()
+		return nil, grpcError("ошибка подписки на события", err)
+	}
+
+	events := make(chan models.VaultEvent)
+	go func() {
+		defer close(events)
+		for {
+			event, recvErr := stream.Recv()
+			if recvErr != nil {
+				if ctx.Err() == nil && !errors.Is(recvErr, io.EOF) {
+					slog.Debug("Поток событий сервера прерван", "error", recvErr)
+				}
+				return
+			}
+			select {
+			case events <- eventFromPB(event):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, nil
+}
+
+// SetAuthToken устанавливает токен аутентификации для клиента.
+func (c *grpcClient) SetAuthToken(token string) {
+	c.authToken = token
+}
+
+// grpcError преобразует ошибку вызова: коды Unauthenticated, PermissionDenied и DataLoss - в ошибки API,
+// остальные - в ошибку с сообщением сервера.
+func grpcError(action string, err error) error {
+	switch status.Code(err) {
+	case codes.Unauthenticated:
+		return ErrAuthorization
+	case codes.PermissionDenied:
+		return ErrForbidden
+	case codes.DataLoss:
+		return ErrServerVaultCorrupted
+	}
+	return fmt.Errorf("%s: %s", action, status.Convert(err).Message())
+}
+
+// versionFromPB преобразует сообщение gRPC в метаданные версии.
+func versionFromPB(v *pb.VaultVersion) *models.VaultVersion {
+	out := &models.VaultVersion{
+		ID:                    v.GetId(),
+		VaultID:               v.GetVaultId(),
+		Checksum:              v.Checksum,
+		SizeBytes:             v.Size,
+		CreatedAt:             v.GetCreatedAt().AsTime(),
+		RestoredFromVersionID: v.RestoredFromVersionId,
+	}
+	if v.GetContentModifiedAt() != nil {
+		modified := v.GetContentModifiedAt().AsTime()
+		out.ContentModifiedAt = &modified
+	}
+	return out
+}
+
+// eventFromPB преобразует сообщение gRPC в событие хранилища.
+func eventFromPB(e *pb.VaultEvent) models.VaultEvent {
+	return models.VaultEvent{
+		Type:      e.GetType(),
+		VersionID: e.GetVersionId(),
+		Checksum:  e.Checksum,
+		CreatedAt: e.GetCreatedAt().AsTime(),
+	}
+}
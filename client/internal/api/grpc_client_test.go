@@ -0,0 +1,336 @@
+package api_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maynagashev/gophkeeper/client/internal/api"
+	"github.com/maynagashev/gophkeeper/models/pb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const grpcTestToken = "grpc-test-token"
+
+// fakeGophKeeper - тестовая реализация gRPC API сервера.
+type fakeGophKeeper struct {
+	pb.UnimplementedGophKeeperServer
+
+	content  string          // Содержимое текущей версии хранилища
+	checksum string          // Контрольная сумма, отдаваемая в метаданных версии
+	uploaded strings.Builder // Содержимое, полученное Upload
+	uploadMD *pb.UploadMetadata
+	callMD   metadata.MD // Метаданные последнего вызова Upload
+	events   []*pb.VaultEvent
+}
+
+// checkToken проверяет токен в метаданных вызова.
+func checkToken(ctx context.Context) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get("authorization"); len(values) == 0 || values[0] != "Bearer "+grpcTestToken {
+		return status.Error(codes.Unauthenticated, "Невалидный токен")
+	}
+	return nil
+}
+
+func (s *fakeGophKeeper) Login(_ context.Context, req *pb.LoginRequest) (*pb.LoginResponse, error) {
+	if req.GetPassword() != "secret" {
+		return nil, status.Error(codes.Unauthenticated, "неверное имя пользователя или пароль")
+	}
+	return &pb.LoginResponse{Token: grpcTestToken}, nil
+}
+
+func (s *fakeGophKeeper) version() *pb.VaultVersion {
+	size := int64(len(s.content))
+	return &pb.VaultVersion{Id: 5, VaultId: 1, Checksum: &s.checksum, Size: &size,
+		CreatedAt: timestamppb.New(time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC))}
+}
+
+func (s *fakeGophKeeper) GetMetadata(ctx context.Context, _ *pb.GetMetadataRequest) (*pb.VaultVersion, error) {
+	if err := checkToken(ctx); err != nil {
+		return nil, err
+	}
+	if s.content == "" {
+		return nil, status.Error(codes.NotFound, "Хранилище или версия не найдены")
+	}
+	return s.version(), nil
+}
+
+func (s *fakeGophKeeper) Upload(stream grpc.ClientStreamingServer[pb.UploadRequest, pb.UploadResponse]) error {
+	if err := checkToken(stream.Context()); err != nil {
+		return err
+	}
+	s.callMD, _ = metadata.FromIncomingContext(stream.Context())
+	for {
+		msg, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if msg.GetMetadata() != nil {
+			s.uploadMD = msg.GetMetadata()
+		}
+		s.uploaded.Write(msg.GetChunk())
+	}
+	if s.uploaded.String() == "conflict" {
+		return status.Error(codes.Aborted, "Конфликт версий")
+	}
+	return stream.SendAndClose(&pb.UploadResponse{})
+}
+
+func (s *fakeGophKeeper) Download(
+	req *pb.DownloadRequest,
+	stream grpc.ServerStreamingServer[pb.DownloadResponse],
+) error {
+	if err := checkToken(stream.Context()); err != nil {
+		return err
+	}
+	notModified := req.GetIfNoneMatch() == s.checksum
+	err := stream.Send(&pb.DownloadResponse{Payload: &pb.DownloadResponse_Metadata{
+		Metadata: &pb.DownloadMetadata{Version: s.version(), NotModified: notModified},
+	}})
+	if err != nil || notModified {
+		return err
+	}
+	// Отдаем содержимое двумя частями
+	half := len(s.content) / 2
+	for _, part := range []string{s.content[:half], s.content[half:]} {
+		if err = stream.Send(&pb.DownloadResponse{Payload: &pb.DownloadResponse_Chunk{Chunk: []byte(part)}}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *fakeGophKeeper) ListVersions(
+	ctx context.Context,
+	req *pb.ListVersionsRequest,
+) (*pb.ListVersionsResponse, error) {
+	if err := checkToken(ctx); err != nil {
+		return nil, err
+	}
+	if req.GetLimit() != 10 || req.GetOffset() != 20 {
+		return nil, status.Error(codes.InvalidArgument, "неожиданные параметры пагинации")
+	}
+	current := int64(5)
+	return &pb.ListVersionsResponse{Versions: []*pb.VaultVersion{s.version()}, CurrentVersionId: &current}, nil
+}
+
+func (s *fakeGophKeeper) Rollback(ctx context.Context, req *pb.RollbackRequest) (*pb.RollbackResponse, error) {
+	if err := checkToken(ctx); err != nil {
+		return nil, err
+	}
+	if req.GetVersionId() != 5 {
+		return nil, status.Error(codes.NotFound, "Хранилище или версия не найдены")
+	}
+	return &pb.RollbackResponse{}, nil
+}
+
+func (s *fakeGophKeeper) SubscribeEvents(
+	_ *pb.SubscribeEventsRequest,
+	stream grpc.ServerStreamingServer[pb.VaultEvent],
+) error {
+	if err := checkToken(stream.Context()); err != nil {
+		return err
+	}
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+	for _, event := range s.events {
+		if err := stream.Send(event); err != nil {
+			return err
+		}
+	}
+	<-stream.Context().Done()
+	return nil
+}
+
+// newTestGRPCClient запускает fake-сервер на bufconn и возвращает подключенный к нему клиент.
+func newTestGRPCClient(t *testing.T, srv *fakeGophKeeper) api.Client {
+	t.Helper()
+	gs := grpc.NewServer()
+	pb.RegisterGophKeeperServer(gs, srv)
+	ln := bufconn.Listen(1 << 20)
+	go func() { _ = gs.Serve(ln) }()
+	t.Cleanup(gs.Stop)
+
+	return api.NewGRPCClient("passthrough:///bufnet", api.TLSOptions{},
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return ln.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestGRPCClient_LoginAndMetadata(t *testing.T) {
+	ctx := context.Background()
+	srv := &fakeGophKeeper{content: "kdbx-content", checksum: sha256Hex("kdbx-content")}
+	client := newTestGRPCClient(t, srv)
+
+	_, err := client.GetVaultMetadata(ctx)
+	require.Error(t, err, "без токена вызов не выполняется")
+
+	_, err = client.Login(ctx, "alice", "wrong")
+	require.ErrorIs(t, err, api.ErrInvalidCredentials)
+
+	token, err := client.Login(ctx, "alice", "secret")
+	require.NoError(t, err)
+	assert.Equal(t, grpcTestToken, token)
+
+	meta, err := client.GetVaultMetadata(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), meta.ID)
+	assert.Equal(t, srv.checksum, *meta.Checksum)
+
+	client.SetAuthToken("expired")
+	_, err = client.GetVaultMetadata(ctx)
+	require.ErrorIs(t, err, api.ErrAuthorization)
+}
+
+func TestGRPCClient_UploadVault(t *testing.T) {
+	ctx := context.Background()
+	modified := time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Метаданные и содержимое", func(t *testing.T) {
+		srv := &fakeGophKeeper{}
+		client := newTestGRPCClient(t, srv)
+		client.SetAuthToken(grpcTestToken)
+		content := strings.Repeat("k", 100<<10)
+
+		err := client.UploadVault(ctx, strings.NewReader(content), int64(len(content)), modified)
+
+		require.NoError(t, err)
+		assert.Equal(t, content, srv.uploaded.String())
+		assert.Equal(t, int64(len(content)), srv.uploadMD.GetSize())
+		assert.Equal(t, modified, srv.uploadMD.GetContentModifiedAt().AsTime())
+	})
+
+	t.Run("Конфликт версий", func(t *testing.T) {
+		client := newTestGRPCClient(t, &fakeGophKeeper{})
+		client.SetAuthToken(grpcTestToken)
+
+		err := client.UploadVault(ctx, strings.NewReader("conflict"), 8, modified)
+
+		require.ErrorIs(t, err, api.ErrVersionConflict)
+	})
+}
+
+func TestGRPCClient_DownloadVault(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Содержимое проверяется по контрольной сумме", func(t *testing.T) {
+		client := newTestGRPCClient(t, &fakeGophKeeper{content: "kdbx-content", checksum: sha256Hex("kdbx-content")})
+		client.SetAuthToken(grpcTestToken)
+
+		body, meta, err := client.DownloadVault(ctx, "")
+		require.NoError(t, err)
+		defer body.Close()
+		data, err := io.ReadAll(body)
+
+		require.NoError(t, err)
+		assert.Equal(t, "kdbx-content", string(data))
+		assert.Equal(t, int64(len("kdbx-content")), *meta.SizeBytes)
+	})
+
+	t.Run("Версия не изменилась", func(t *testing.T) {
+		client := newTestGRPCClient(t, &fakeGophKeeper{content: "kdbx-content", checksum: "abc"})
+		client.SetAuthToken(grpcTestToken)
+
+		body, meta, err := client.DownloadVault(ctx, "abc")
+
+		require.ErrorIs(t, err, api.ErrNotModified)
+		assert.Nil(t, body)
+		assert.Equal(t, "abc", *meta.Checksum)
+	})
+
+	t.Run("Поврежденный файл", func(t *testing.T) {
+		client := newTestGRPCClient(t, &fakeGophKeeper{content: "kdbx-content", checksum: sha256Hex("other")})
+		client.SetAuthToken(grpcTestToken)
+
+		body, _, err := client.DownloadVault(ctx, "")
+		require.NoError(t, err)
+		defer body.Close()
+		_, err = io.ReadAll(body)
+
+		require.ErrorIs(t, err, api.ErrDownloadCorrupted)
+	})
+}
+
+func TestGRPCClient_VersionsAndRollback(t *testing.T) {
+	ctx := context.Background()
+	client := newTestGRPCClient(t, &fakeGophKeeper{content: "kdbx-content", checksum: "abc"})
+	client.SetAuthToken(grpcTestToken)
+
+	versions, currentID, err := client.ListVersions(ctx, 10, 20)
+	require.NoError(t, err)
+	require.Len(t, versions, 1)
+	assert.Equal(t, int64(5), currentID)
+
+	require.NoError(t, client.RollbackToVersion(ctx, 5))
+	require.ErrorIs(t, client.RollbackToVersion(ctx, 6), api.ErrVersionNotFound)
+}
+
+func TestGRPCClient_SubscribeEvents(t *testing.T) {
+	t.Run("События передаются в канал", func(t *testing.T) {
+		checksum := "abc"
+		client := newTestGRPCClient(t, &fakeGophKeeper{events: []*pb.VaultEvent{
+			{Type: "version_created", VersionId: 5, Checksum: &checksum, CreatedAt: timestamppb.Now()},
+		}})
+		client.SetAuthToken(grpcTestToken)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		events, err := client.SubscribeEvents(ctx)
+		require.NoError(t, err)
+
+		select {
+		case event := <-events:
+			assert.Equal(t, "version_created", event.Type)
+			assert.Equal(t, int64(5), event.VersionID)
+			assert.Equal(t, checksum, *event.Checksum)
+		case <-time.After(time.Second):
+			t.Fatal("событие не получено")
+		}
+
+		cancel()
+		require.Eventually(t, func() bool {
+			_, open := <-events
+			return !open
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("Невалидный токен", func(t *testing.T) {
+		client := newTestGRPCClient(t, &fakeGophKeeper{})
+		client.SetAuthToken("expired")
+
+		_, err := client.SubscribeEvents(context.Background())
+
+		require.ErrorIs(t, err, api.ErrAuthorization)
+	})
+}
+
+func TestNewClient(t *testing.T) {
+	client := api.NewClient("grpcs://localhost:8444", api.TLSOptions{})
+	// Без токена клиент gRPC не выполняет вызовы, но создается без обращения к серверу
+	_, err := client.GetVaultMetadata(context.Background())
+	require.EqualError(t, err, "токен аутентификации отсутствует")
+}
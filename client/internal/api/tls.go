@@ -0,0 +1,125 @@
+package api
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+)
+
+// ErrServerKeyChanged сигнализирует, что открытый ключ сервера не совпадает с закрепленным.
+var ErrServerKeyChanged = errors.New("открытый ключ сервера не совпадает с закрепленным")
+
+// TLSOptions - параметры проверки сертификата сервера.
+type TLSOptions struct {
+	// RootCAs - корневые сертификаты для проверки сервера (nil - системные, см. LoadCAFile).
+	RootCAs *x509.CertPool
+	// Pins - закрепление открытого ключа сервера (nil - без закрепления).
+	// При закреплении сервер проверяется только по ключу, цепочка сертификатов и имя хоста не проверяются.
+	Pins *PinVerifier
+}
+
+// clientConfig возвращает TLS-конфигурацию клиента.
+func (o TLSOptions) clientConfig() *tls.Config {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12, RootCAs: o.RootCAs}
+	if o.Pins != nil {
+		// Самоподписанный сертификат сервера не нужно устанавливать в систему: доверие определяется ключом
+		cfg.InsecureSkipVerify = true //nolint:gosec // Сервер проверяется в VerifyConnection
+		cfg.VerifyConnection = o.Pins.verifyConnection
+	}
+	return cfg
+}
+
+// transport возвращает HTTP-транспорт с TLS-конфигурацией клиента.
+func (o TLSOptions) transport() *http.Transport {
+	//nolint:forcetypeassert // DefaultTransport всегда *http.Transport
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = o.clientConfig()
+	return transport
+}
+
+// LoadCAFile возвращает системные корневые сертификаты, дополненные сертификатами CA из PEM-файла path
+// (например, ca.crt, созданного сервером при -tls-auto-generate).
+func LoadCAFile(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения файла CA: %w", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("в файле %s нет сертификатов в формате PEM", path)
+	}
+	return pool, nil
+}
+
+// PinVerifier проверяет открытый ключ сервера по закрепленному SHA-256 SPKI в base64
+// (в том же виде сервер выводит его при создании самоподписанного сертификата).
+// Пока ключ не закреплен, принимается и закрепляется ключ первого соединения (trust on first use).
+// Несовпадение запоминается, чтобы клиент мог спросить пользователя, доверять ли новому ключу.
+type PinVerifier struct {
+	mu       sync.Mutex
+	pin      string
+	mismatch string
+}
+
+// NewPinVerifier создает проверку с закрепленным ключом pin (пусто - закрепить ключ первого соединения).
+func NewPinVerifier(pin string) *PinVerifier {
+	return &PinVerifier{pin: pin}
+}
+
+// Pin возвращает закрепленный ключ (пусто, если соединений еще не было).
+func (v *PinVerifier) Pin() string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.pin
+}
+
+// Mismatch возвращает ключ сервера, отклоненный из-за несовпадения с закрепленным (пусто, если такого не было).
+func (v *PinVerifier) Mismatch() string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.mismatch
+}
+
+// Reset закрепляет ключ pin и сбрасывает отклоненный ключ.
+func (v *PinVerifier) Reset(pin string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.pin = pin
+	v.mismatch = ""
+}
+
+// verifyConnection сверяет ключ сертификата сервера с закрепленным (для tls.Config.VerifyConnection).
+func (v *PinVerifier) verifyConnection(state tls.ConnectionState) error {
+	if len(state.PeerCertificates) == 0 {
+		return errors.New("сервер не предоставил сертификат")
+	}
+	pin := spkiPin(state.PeerCertificates[0])
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	switch v.pin {
+	case "":
+		v.pin = pin
+		return nil
+	case pin:
+		return nil
+	default:
+		v.mismatch = pin
+		return fmt.Errorf("%w: закреплен %s, получен %s", ErrServerKeyChanged, v.pin, pin)
+	}
+}
+
+// spkiPin возвращает SHA-256 открытого ключа сертификата (SubjectPublicKeyInfo) в base64.
+func spkiPin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,118 @@
+package api_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/maynagashev/gophkeeper/client/internal/api"
+)
+
+// newTLSServer запускает HTTPS-сервер с новым самоподписанным сертификатом для 127.0.0.1.
+// Возвращает сервер, сертификат в PEM и SHA-256 SPKI его ключа в base64.
+func newTLSServer(t *testing.T) (*httptest.Server, []byte, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+	server.TLS = &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+	}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return server, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func TestHTTPClient_CAFile(t *testing.T) {
+	server, certPEM, _ := newTLSServer(t)
+	ctx := context.Background()
+
+	t.Run("Без CA самоподписанный сертификат отклоняется", func(t *testing.T) {
+		err := api.NewHTTPClient(server.URL, api.TLSOptions{}).Register(ctx, "user", "password")
+		require.Error(t, err)
+	})
+
+	t.Run("Сертификат проверяется по файлу CA", func(t *testing.T) {
+		caFile := filepath.Join(t.TempDir(), "ca.crt")
+		require.NoError(t, os.WriteFile(caFile, certPEM, 0o600))
+		pool, err := api.LoadCAFile(caFile)
+		require.NoError(t, err)
+
+		err = api.NewHTTPClient(server.URL, api.TLSOptions{RootCAs: pool}).Register(ctx, "user", "password")
+		require.NoError(t, err)
+	})
+
+	t.Run("Файл без сертификатов", func(t *testing.T) {
+		caFile := filepath.Join(t.TempDir(), "ca.crt")
+		require.NoError(t, os.WriteFile(caFile, []byte("не сертификат"), 0o600))
+		_, err := api.LoadCAFile(caFile)
+		require.Error(t, err)
+	})
+}
+
+func TestHTTPClient_PinVerifier(t *testing.T) {
+	first, _, firstPin := newTLSServer(t)
+	second, _, secondPin := newTLSServer(t)
+	ctx := context.Background()
+	pins := api.NewPinVerifier("")
+	opts := api.TLSOptions{Pins: pins}
+
+	t.Run("Ключ первого соединения закрепляется", func(t *testing.T) {
+		require.NoError(t, api.NewHTTPClient(first.URL, opts).Register(ctx, "user", "password"))
+		assert.Equal(t, firstPin, pins.Pin())
+		assert.Empty(t, pins.Mismatch())
+	})
+
+	t.Run("Другой ключ отклоняется", func(t *testing.T) {
+		err := api.NewHTTPClient(second.URL, opts).Register(ctx, "user", "password")
+
+		require.ErrorIs(t, err, api.ErrServerKeyChanged)
+		assert.Equal(t, firstPin, pins.Pin(), "Закрепленный ключ не должен меняться")
+		assert.Equal(t, secondPin, pins.Mismatch())
+	})
+
+	t.Run("Подтвержденный новый ключ принимается", func(t *testing.T) {
+		pins.Reset(secondPin)
+
+		require.NoError(t, api.NewHTTPClient(second.URL, opts).Register(ctx, "user", "password"))
+		assert.Empty(t, pins.Mismatch())
+	})
+}
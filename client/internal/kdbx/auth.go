@@ -14,6 +14,8 @@ const (
 	CustomDataKeyServerURL = "GophKeeperServerURL"
 	// CustomDataKeyAuthToken - ключ для хранения JWT токена в KDBX.
 	CustomDataKeyAuthToken = "GophKeeperAuthToken" //nolint:gosec // Это имя ключа, а не сам токен
+	// CustomDataKeyServerPin - ключ для хранения закрепленного открытого ключа сервера (SHA-256 SPKI) в KDBX.
+	CustomDataKeyServerPin = "GophKeeperServerPin"
 )
 
 // setCustomDataValue обновляет или добавляет значение в слайс CustomData.
@@ -56,9 +58,9 @@ func removeCustomDataValue(customDataSlice []gokeepasslib.CustomData, key string
 	return newSlice
 }
 
-// SaveAuthData сохраняет URL сервера и токен аутентификации
-// в пользовательских данных метаданных базы KDBX.
-func SaveAuthData(db *gokeepasslib.Database, serverURL, authToken string) error {
+// SaveAuthData сохраняет URL сервера, токен аутентификации и закрепленный открытый ключ сервера
+// в пользовательских данных метаданных базы KDBX. Пустые значения удаляются.
+func SaveAuthData(db *gokeepasslib.Database, serverURL, authToken, serverPin string) error {
 	if db == nil || db.Content == nil || db.Content.Meta == nil {
 		return errors.New("база данных, ее содержимое или метаданные не инициализированы")
 	}
@@ -82,6 +84,13 @@ func SaveAuthData(db *gokeepasslib.Database, serverURL, authToken string) error
 		meta.CustomData = removeCustomDataValue(meta.CustomData, CustomDataKeyAuthToken)
 	}
 
+	// Сохраняем/удаляем ключ сервера
+	if serverPin != "" {
+		meta.CustomData = setCustomDataValue(meta.CustomData, CustomDataKeyServerPin, serverPin)
+	} else {
+		meta.CustomData = removeCustomDataValue(meta.CustomData, CustomDataKeyServerPin)
+	}
+
 	// Проверяем, изменился ли слайс CustomData
 	if len(initialCustomData) != len(meta.CustomData) {
 		changed = true
@@ -120,12 +129,12 @@ func SaveAuthData(db *gokeepasslib.Database, serverURL, authToken string) error
 	return nil
 }
 
-// LoadAuthData извлекает URL сервера и токен аутентификации
+// LoadAuthData извлекает URL сервера, токен аутентификации и закрепленный открытый ключ сервера
 // из пользовательских данных метаданных базы KDBX.
-func LoadAuthData(db *gokeepasslib.Database) (string, string, error) {
-	var serverURL, authToken string
+func LoadAuthData(db *gokeepasslib.Database) (string, string, string, error) {
+	var serverURL, authToken, serverPin string
 	if db == nil || db.Content == nil || db.Content.Meta == nil {
-		return "", "", errors.New("база данных, ее содержимое или метаданные не инициализированы для загрузки AuthData")
+		return "", "", "", errors.New("база данных, ее содержимое или метаданные не инициализированы для загрузки AuthData")
 	}
 
 	meta := db.Content.Meta
@@ -144,9 +153,9 @@ func LoadAuthData(db *gokeepasslib.Database) (string, string, error) {
 			authToken = item.Value
 			foundToken = true
 			slog.Debug("Найден токен в CustomData", "key", item.Key)
-		}
-		if foundURL && foundToken {
-			break
+		case CustomDataKeyServerPin:
+			serverPin = item.Value
+			slog.Debug("Найден ключ сервера в CustomData", "key", item.Key)
 		}
 	}
 
@@ -157,5 +166,5 @@ func LoadAuthData(db *gokeepasslib.Database) (string, string, error) {
 		slog.Debug("Токен аутентификации не найден в CustomData KDBX")
 	}
 
-	return serverURL, authToken, nil
+	return serverURL, authToken, serverPin, nil
 }
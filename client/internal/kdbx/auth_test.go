@@ -50,7 +50,7 @@ func TestSaveAuthData(t *testing.T) {
 		require.Empty(t, db.Content.Meta.CustomData, "CustomData должен быть пустым изначально")
 
 		// Сохраняем данные аутентификации
-		err := kdbx.SaveAuthData(db, testServerURL, testAuthToken)
+		err := kdbx.SaveAuthData(db, testServerURL, testAuthToken, "")
 		require.NoError(t, err, "SaveAuthData не должен возвращать ошибку")
 
 		// Проверяем, что данные были сохранены
@@ -82,7 +82,7 @@ func TestSaveAuthData(t *testing.T) {
 		require.NotNil(t, db, "База данных должна быть создана")
 
 		// Сначала добавляем начальные данные
-		err := kdbx.SaveAuthData(db, testServerURL, testAuthToken)
+		err := kdbx.SaveAuthData(db, testServerURL, testAuthToken, "")
 		require.NoError(t, err, "SaveAuthData не должен возвращать ошибку")
 
 		// Фиксируем время последней модификации
@@ -97,7 +97,7 @@ func TestSaveAuthData(t *testing.T) {
 		// Обновляем данные
 		newURL := "https://new-test-server.example.com"
 		newToken := "new-test-jwt-token"
-		err = kdbx.SaveAuthData(db, newURL, newToken)
+		err = kdbx.SaveAuthData(db, newURL, newToken, "")
 		require.NoError(t, err, "SaveAuthData не должен возвращать ошибку при обновлении")
 
 		// Проверяем, что данные обновлены
@@ -133,12 +133,12 @@ func TestSaveAuthData(t *testing.T) {
 		require.NotNil(t, db, "База данных должна быть создана")
 
 		// Сначала добавляем данные
-		err := kdbx.SaveAuthData(db, testServerURL, testAuthToken)
+		err := kdbx.SaveAuthData(db, testServerURL, testAuthToken, "")
 		require.NoError(t, err, "SaveAuthData не должен возвращать ошибку")
 		require.Len(t, db.Content.Meta.CustomData, 2, "CustomData должен содержать 2 записи")
 
 		// Теперь удаляем данные, передавая пустые строки
-		err = kdbx.SaveAuthData(db, "", "")
+		err = kdbx.SaveAuthData(db, "", "", "")
 		require.NoError(t, err, "SaveAuthData не должен возвращать ошибку при удалении")
 
 		// Проверяем, что данные удалены
@@ -147,7 +147,7 @@ func TestSaveAuthData(t *testing.T) {
 
 	// Тест с некорректной базой данных
 	t.Run("Error_With_Nil_Database", func(t *testing.T) {
-		err := kdbx.SaveAuthData(nil, testServerURL, testAuthToken)
+		err := kdbx.SaveAuthData(nil, testServerURL, testAuthToken, "")
 		require.Error(t, err, "SaveAuthData должен возвращать ошибку при nil базе данных")
 		assert.Contains(t, err.Error(), "не инициализированы", "Ошибка должна указывать на проблему инициализации")
 	})
@@ -155,7 +155,7 @@ func TestSaveAuthData(t *testing.T) {
 	// Тест с nil Content
 	t.Run("Error_With_Nil_Content", func(t *testing.T) {
 		db := &gokeepasslib.Database{Content: nil}
-		err := kdbx.SaveAuthData(db, testServerURL, testAuthToken)
+		err := kdbx.SaveAuthData(db, testServerURL, testAuthToken, "")
 		require.Error(t, err, "SaveAuthData должен возвращать ошибку при nil Content")
 		assert.Contains(t, err.Error(), "не инициализированы", "Ошибка должна указывать на проблему инициализации")
 	})
@@ -163,7 +163,7 @@ func TestSaveAuthData(t *testing.T) {
 	// Тест с nil Meta
 	t.Run("Error_With_Nil_Meta", func(t *testing.T) {
 		db := &gokeepasslib.Database{Content: &gokeepasslib.DBContent{Meta: nil}}
-		err := kdbx.SaveAuthData(db, testServerURL, testAuthToken)
+		err := kdbx.SaveAuthData(db, testServerURL, testAuthToken, "")
 		require.Error(t, err, "SaveAuthData должен возвращать ошибку при nil Meta")
 		assert.Contains(t, err.Error(), "не инициализированы", "Ошибка должна указывать на проблему инициализации")
 	})
@@ -177,23 +177,40 @@ func TestLoadAuthData(t *testing.T) {
 		require.NotNil(t, db, "База данных должна быть создана")
 
 		// Сохраняем данные аутентификации
-		err := kdbx.SaveAuthData(db, testServerURL, testAuthToken)
+		err := kdbx.SaveAuthData(db, testServerURL, testAuthToken, "")
 		require.NoError(t, err, "SaveAuthData не должен возвращать ошибку")
 
 		// Загружаем данные
-		url, token, err := kdbx.LoadAuthData(db)
+		url, token, _, err := kdbx.LoadAuthData(db)
 		require.NoError(t, err, "LoadAuthData не должен возвращать ошибку")
 		assert.Equal(t, testServerURL, url, "Загруженный URL должен соответствовать сохраненному")
 		assert.Equal(t, testAuthToken, token, "Загруженный токен должен соответствовать сохраненному")
 	})
 
+	// Тест на сохранение и удаление закрепленного ключа сервера
+	t.Run("Success_Load_Server_Pin", func(t *testing.T) {
+		db := createTestDatabase()
+		const pin = "n5B6LTb6d7mXoHJ0C5uyG8C6X1ULhbQuWnRzV5LTvWw="
+
+		require.NoError(t, kdbx.SaveAuthData(db, testServerURL, testAuthToken, pin))
+		_, _, loadedPin, err := kdbx.LoadAuthData(db)
+		require.NoError(t, err)
+		assert.Equal(t, pin, loadedPin, "Загруженный ключ должен соответствовать сохраненному")
+
+		require.NoError(t, kdbx.SaveAuthData(db, testServerURL, testAuthToken, ""))
+		_, _, loadedPin, err = kdbx.LoadAuthData(db)
+		require.NoError(t, err)
+		assert.Empty(t, loadedPin, "Ключ должен быть удален")
+		assert.Len(t, db.Content.Meta.CustomData, 2, "CustomData должен содержать только URL и токен")
+	})
+
 	// Тест на загрузку отсутствующих данных
 	t.Run("Success_Load_Empty_Auth_Data", func(t *testing.T) {
 		db := createTestDatabase()
 		require.NotNil(t, db, "База данных должна быть создана")
 
 		// CustomData пуст, данных нет
-		url, token, err := kdbx.LoadAuthData(db)
+		url, token, _, err := kdbx.LoadAuthData(db)
 		require.NoError(t, err, "LoadAuthData не должен возвращать ошибку для пустых данных")
 		assert.Empty(t, url, "URL должен быть пустым")
 		assert.Empty(t, token, "Токен должен быть пустым")
@@ -201,7 +218,7 @@ func TestLoadAuthData(t *testing.T) {
 
 	// Тест с некорректной базой данных
 	t.Run("Error_With_Nil_Database", func(t *testing.T) {
-		url, token, err := kdbx.LoadAuthData(nil)
+		url, token, _, err := kdbx.LoadAuthData(nil)
 		require.Error(t, err, "LoadAuthData должен возвращать ошибку при nil базе данных")
 		assert.Empty(t, url, "URL должен быть пустым при ошибке")
 		assert.Empty(t, token, "Токен должен быть пустым при ошибке")
@@ -211,7 +228,7 @@ func TestLoadAuthData(t *testing.T) {
 	// Тест с nil Content
 	t.Run("Error_With_Nil_Content", func(t *testing.T) {
 		db := &gokeepasslib.Database{Content: nil}
-		url, token, err := kdbx.LoadAuthData(db)
+		url, token, _, err := kdbx.LoadAuthData(db)
 		require.Error(t, err, "LoadAuthData должен возвращать ошибку при nil Content")
 		assert.Empty(t, url, "URL должен быть пустым при ошибке")
 		assert.Empty(t, token, "Токен должен быть пустым при ошибке")
@@ -221,7 +238,7 @@ func TestLoadAuthData(t *testing.T) {
 	// Тест с nil Meta
 	t.Run("Error_With_Nil_Meta", func(t *testing.T) {
 		db := &gokeepasslib.Database{Content: &gokeepasslib.DBContent{Meta: nil}}
-		url, token, err := kdbx.LoadAuthData(db)
+		url, token, _, err := kdbx.LoadAuthData(db)
 		require.Error(t, err, "LoadAuthData должен возвращать ошибку при nil Meta")
 		assert.Empty(t, url, "URL должен быть пустым при ошибке")
 		assert.Empty(t, token, "Токен должен быть пустым при ошибке")
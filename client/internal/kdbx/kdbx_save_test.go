@@ -100,7 +100,7 @@ func TestSaveFile(t *testing.T) {
 		require.NotNil(t, db1, "База данных должна быть создана")
 
 		// Добавляем URL в CustomData для первой базы
-		authErr1 := kdbx.SaveAuthData(db1, testServerURL, "")
+		authErr1 := kdbx.SaveAuthData(db1, testServerURL, "", "")
 		require.NoError(t, authErr1, "SaveAuthData не должен возвращать ошибку")
 
 		savePath := filepath.Join(tempDir, "test-overwrite.kdbx")
@@ -112,7 +112,7 @@ func TestSaveFile(t *testing.T) {
 		// Открываем файл для проверки
 		dbCheck1, openErr1 := kdbx.OpenFile(savePath, testPassword)
 		require.NoError(t, openErr1, "OpenFile не должен возвращать ошибку")
-		url1, _, loadErr1 := kdbx.LoadAuthData(dbCheck1)
+		url1, _, _, loadErr1 := kdbx.LoadAuthData(dbCheck1)
 		require.NoError(t, loadErr1, "LoadAuthData не должен возвращать ошибку")
 		assert.Equal(t, testServerURL, url1, "URL должен совпадать с сохраненным")
 
@@ -122,7 +122,7 @@ func TestSaveFile(t *testing.T) {
 
 		// Добавляем другой URL в CustomData для второй базы
 		newURL := "https://other-server.example.com"
-		authErr2 := kdbx.SaveAuthData(db2, newURL, "")
+		authErr2 := kdbx.SaveAuthData(db2, newURL, "", "")
 		require.NoError(t, authErr2, "SaveAuthData не должен возвращать ошибку")
 
 		// Перезаписываем файл второй базой
@@ -132,7 +132,7 @@ func TestSaveFile(t *testing.T) {
 		// Открываем файл и проверяем, что он содержит данные из второй базы
 		dbCheck2, openErr2 := kdbx.OpenFile(savePath, testPassword)
 		require.NoError(t, openErr2, "OpenFile не должен возвращать ошибку")
-		url2, _, loadErr2 := kdbx.LoadAuthData(dbCheck2)
+		url2, _, _, loadErr2 := kdbx.LoadAuthData(dbCheck2)
 		require.NoError(t, loadErr2, "LoadAuthData не должен возвращать ошибку")
 		assert.Equal(t, newURL, url2, "URL должен совпадать с новым значением")
 		assert.NotEqual(t, url1, url2, "URL должен отличаться от первого значения")
@@ -2,7 +2,8 @@ package tui
 
 import (
 	"bytes"
-	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
@@ -13,6 +14,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/tobischo/gokeepasslib/v3"
 
+	"github.com/maynagashev/gophkeeper/client/internal/api"
 	"github.com/maynagashev/gophkeeper/client/internal/kdbx"
 	"github.com/maynagashev/gophkeeper/models"
 )
@@ -62,8 +64,9 @@ func (e LoginError) Error() string {
 
 // makeLoginCmd выполняет вход через API.
 func (m *model) makeLoginCmd(username, password string) tea.Cmd {
+	ctx, op := m.startOperation()
 	return func() tea.Msg {
-		ctx := context.Background()
+		defer op.finish()
 		token, err := m.apiClient.Login(ctx, username, password)
 		if err != nil {
 			// Возвращаем исходную ошибку API клиента без добавления контекста
@@ -87,8 +90,9 @@ func (e RegisterError) Error() string {
 
 // makeRegisterCmd выполняет регистрацию через API.
 func (m *model) makeRegisterCmd(username, password string) tea.Cmd {
+	ctx, op := m.startOperation()
 	return func() tea.Msg {
-		ctx := context.Background()
+		defer op.finish()
 		err := m.apiClient.Register(ctx, username, password)
 		if err != nil {
 			// Возвращаем исходную ошибку API клиента без добавления контекста
@@ -125,16 +129,22 @@ type localMetadataMsg struct {
 }
 
 // syncUploadSuccessMsg сигнализирует об успешной загрузке хранилища на сервер.
-type syncUploadSuccessMsg struct{}
+type syncUploadSuccessMsg struct {
+	checksum string // SHA256 загруженных данных
+}
 
 // syncDownloadSuccessMsg сигнализирует об успешном скачивании хранилища с сервера.
 // Включает флаг необходимости перезагрузки базы данных.
 type syncDownloadSuccessMsg struct {
-	reloadNeeded bool // Обычно true, чтобы обновить TUI
+	reloadNeeded bool   // Обычно true, чтобы обновить TUI
+	checksum     string // SHA256 скачанных данных
 }
 
 const defaultFilePerm = 0600
 
+// downloadTempSuffix - суффикс временного файла, в который скачивается хранилище перед заменой локального.
+const downloadTempSuffix = ".download"
+
 // startSyncCmd проверяет предусловия и запускает процесс синхронизации.
 func startSyncCmd(m *model) tea.Cmd {
 	return func() tea.Msg {
@@ -175,18 +185,19 @@ func startSyncCmd(m *model) tea.Cmd {
 
 // fetchServerMetadataCmd получает метаданные хранилища с сервера.
 func fetchServerMetadataCmd(m *model) tea.Cmd {
+	ctx, op := m.startOperation()
 	return func() tea.Msg {
+		defer op.finish()
 		slog.Debug("Получение метаданных с сервера", "url", m.serverURL)
-		ctx := context.Background() // Используем background context
 		meta, err := m.apiClient.GetVaultMetadata(ctx)
 
 		if err != nil {
-			// Проверяем специфичные ошибки API клиента с помощью switch
-			switch err.Error() {
-			case "хранилище не найдено на сервере": // Проверка на текст ошибки
+			// Проверяем типизированные ошибки API клиента
+			switch {
+			case errors.Is(err, api.ErrVaultNotFound):
 				slog.Info("Хранилище не найдено на сервере.")
 				return serverMetadataMsg{metadata: nil, found: false}
-			case "ошибка авторизации (невалидный или просроченный токен?)":
+			case errors.Is(err, api.ErrAuthorization):
 				slog.Warn("Ошибка авторизации при получении метаданных с сервера", "error", err)
 				return SyncError{err: errors.New("ошибка авторизации")}
 			default:
@@ -227,7 +238,9 @@ func fetchLocalMetadataCmd(m *model) tea.Cmd {
 
 // uploadVaultCmd загружает локальный файл KDBX на сервер.
 func uploadVaultCmd(m *model) tea.Cmd {
+	ctx, op := m.startOperation()
 	return func() tea.Msg {
+		defer op.finish()
 		// Шаг 1: Убедиться, что все изменения из TUI применены к m.db (как в Ctrl+S)
 		slog.Info("Подготовка к загрузке: обновление m.db из TUI...")
 		applyUIChangesToDB(m)
@@ -269,10 +282,10 @@ func uploadVaultCmd(m *model) tea.Cmd {
 		}
 
 		dataSize := int64(buf.Len())
+		checksum := sha256.Sum256(buf.Bytes())
 
 		// Шаг 3: Вызвать API для загрузки, передавая время модификации файла
 		slog.Info("Запуск загрузки KDBX на сервер...", "fileModTime", contentModTime)
-		ctx := context.Background()
 		err = m.apiClient.UploadVault(ctx, buf, dataSize, contentModTime) // Передаем fileModTime
 		if err != nil {
 			slog.Error("Ошибка загрузки KDBX на сервер", "error", err)
@@ -280,42 +293,83 @@ func uploadVaultCmd(m *model) tea.Cmd {
 		}
 
 		slog.Info("Загрузка KDBX на сервер успешно завершена.")
-		return syncUploadSuccessMsg{}
+		return syncUploadSuccessMsg{checksum: hex.EncodeToString(checksum[:])}
 	}
 }
 
 // downloadVaultCmd скачивает файл KDBX с сервера и перезаписывает локальный.
+// Сервер не передает файл, если контрольная сумма локального файла совпадает с его версией.
 func downloadVaultCmd(m *model) tea.Cmd {
+	ctx, op := m.startOperation()
 	return func() tea.Msg {
+		defer op.finish()
 		slog.Info("Запуск скачивания KDBX с сервера...")
-		ctx := context.Background()
-		reader, _, err := m.apiClient.DownloadVault(ctx) // Метаданные пока не используем
+
+		localChecksum, err := fileChecksum(m.kdbxPath)
+		if err != nil {
+			// Без контрольной суммы просто скачиваем файл целиком
+			slog.Debug("Не удалось вычислить контрольную сумму локального файла", "path", m.kdbxPath, "error", err)
+		}
+
+		reader, _, err := m.apiClient.DownloadVault(ctx, localChecksum) // Метаданные пока не используем
+		if errors.Is(err, api.ErrNotModified) {
+			slog.Info("Локальный файл совпадает с версией на сервере, скачивание не требуется")
+			return syncDownloadSuccessMsg{reloadNeeded: false, checksum: localChecksum}
+		}
 		if err != nil {
 			slog.Error("Ошибка скачивания KDBX с сервера", "error", err)
 			return SyncError{err: fmt.Errorf("ошибка скачивания с сервера: %w", err)}
 		}
 		defer reader.Close()
 
-		// Шаг 2: Создать/открыть локальный файл для записи (перезапись)
-		slog.Debug("Открытие локального файла для записи", "path", m.kdbxPath)
-		file, err := os.OpenFile(m.kdbxPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, defaultFilePerm)
+		// Шаг 2: Скачиваем во временный файл рядом с локальным, чтобы прерванное
+		// или поврежденное скачивание не испортило локальную базу
+		tmpPath := m.kdbxPath + downloadTempSuffix
+		slog.Debug("Открытие временного файла для записи", "path", tmpPath)
+		file, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, defaultFilePerm)
 		if err != nil {
-			slog.Error("Ошибка открытия локального файла для записи", "path", m.kdbxPath, "error", err)
+			slog.Error("Ошибка открытия локального файла для записи", "path", tmpPath, "error", err)
 			return SyncError{err: fmt.Errorf("ошибка записи локального файла: %w", err)}
 		}
-		defer file.Close()
 
 		// Шаг 3: Скопировать данные из ответа в файл
-		_, err = io.Copy(file, reader)
+		hasher := sha256.New()
+		_, err = io.Copy(io.MultiWriter(file, hasher), reader)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
 		if err != nil {
+			_ = os.Remove(tmpPath)
 			slog.Error("Ошибка копирования данных в локальный файл", "error", err)
 			return SyncError{err: fmt.Errorf("ошибка сохранения скачанного файла: %w", err)}
 		}
 
+		// Шаг 4: Заменить локальный файл скачанным
+		if err = os.Rename(tmpPath, m.kdbxPath); err != nil {
+			_ = os.Remove(tmpPath)
+			slog.Error("Ошибка замены локального файла скачанным", "path", m.kdbxPath, "error", err)
+			return SyncError{err: fmt.Errorf("ошибка сохранения скачанного файла: %w", err)}
+		}
+
 		slog.Info("Скачивание KDBX с сервера и сохранение локально завершено.")
 		// Отправляем сообщение об успехе и необходимости перезагрузки
-		return syncDownloadSuccessMsg{reloadNeeded: true}
+		return syncDownloadSuccessMsg{reloadNeeded: true, checksum: hex.EncodeToString(hasher.Sum(nil))}
+	}
+}
+
+// fileChecksum вычисляет SHA256 файла в hex.
+func fileChecksum(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hasher := sha256.New()
+	if _, err = io.Copy(hasher, file); err != nil {
+		return "", err
 	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
 }
 
 // applyUIChangesToDB применяет изменения из компонентов TUI (например, списка) к m.db.
@@ -4,8 +4,11 @@ package tui
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"io"
+	"net/http"
 	"os"
 	"path/filepath"
 	"testing"
@@ -53,8 +56,11 @@ func (m *CommandsTestMockAPIClient) UploadVault(ctx context.Context, data io.Rea
 	return args.Error(0)
 }
 
-func (m *CommandsTestMockAPIClient) DownloadVault(ctx context.Context) (io.ReadCloser, *models.VaultVersion, error) {
-	args := m.Called(ctx)
+func (m *CommandsTestMockAPIClient) DownloadVault(
+	ctx context.Context,
+	ifNoneMatch string,
+) (io.ReadCloser, *models.VaultVersion, error) {
+	args := m.Called(ctx, ifNoneMatch)
 	if args.Get(0) == nil {
 		return nil, nil, args.Error(2)
 	}
@@ -92,6 +98,18 @@ func (m *CommandsTestMockAPIClient) RollbackToVersion(ctx context.Context, versi
 	return args.Error(0)
 }
 
+func (m *CommandsTestMockAPIClient) SubscribeEvents(ctx context.Context) (<-chan models.VaultEvent, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	events, ok := args.Get(0).(<-chan models.VaultEvent)
+	if !ok {
+		return nil, errors.New("неверный тип результата <-chan models.VaultEvent")
+	}
+	return events, args.Error(1)
+}
+
 func (m *CommandsTestMockAPIClient) SetAuthToken(token string) {
 	m.Called(token)
 }
@@ -919,6 +937,33 @@ func TestFetchServerMetadataCmd(t *testing.T) {
 		// Проверяем, что метод GetVaultMetadata был вызван
 		mockAPI.AssertExpectations(t)
 	})
+
+	t.Run("VaultNotFound", func(t *testing.T) {
+		// Ошибка API v2 с кодом vault_not_found распознается через errors.Is, а не по тексту
+		apiErr := &api.APIError{
+			StatusCode:    http.StatusNotFound,
+			ErrorResponse: models.ErrorResponse{Code: models.ErrCodeVaultNotFound, Message: "Хранилище не найдено"},
+		}
+		mockAPI.On("GetVaultMetadata", mock.Anything).Return(nil, apiErr).Once()
+
+		msg := fetchServerMetadataCmd(&model{apiClient: mockAPI})()
+		metadataMsg, ok := msg.(serverMetadataMsg)
+		require.True(t, ok, "Сообщение должно быть serverMetadataMsg")
+		assert.False(t, metadataMsg.found, "Хранилище не должно быть найдено")
+
+		mockAPI.AssertExpectations(t)
+	})
+
+	t.Run("AuthorizationError", func(t *testing.T) {
+		mockAPI.On("GetVaultMetadata", mock.Anything).Return(nil, api.ErrAuthorization).Once()
+
+		msg := fetchServerMetadataCmd(&model{apiClient: mockAPI})()
+		syncErr, ok := msg.(SyncError)
+		require.True(t, ok, "Сообщение должно быть SyncError")
+		assert.Equal(t, "ошибка авторизации", syncErr.Error())
+
+		mockAPI.AssertExpectations(t)
+	})
 }
 
 // TestFetchLocalMetadataCmd проверяет функцию fetchLocalMetadataCmd, которая получает локальные метаданные.
@@ -1157,7 +1202,7 @@ func TestDownloadVaultCmd(t *testing.T) {
 		}
 
 		// Настраиваем мок для успешного скачивания
-		mockAPI.On("DownloadVault", mock.Anything).Return(readCloser, mockVersion, nil).Once()
+		mockAPI.On("DownloadVault", mock.Anything, mock.Anything).Return(readCloser, mockVersion, nil).Once()
 
 		// Создаем модель
 		model := &model{
@@ -1188,10 +1233,36 @@ func TestDownloadVaultCmd(t *testing.T) {
 		mockAPI.AssertExpectations(t)
 	})
 
+	t.Run("NotModified", func(t *testing.T) {
+		localContent := []byte("local content")
+		require.NoError(t, os.WriteFile(testFilePath, localContent, 0600))
+		sum := sha256.Sum256(localContent)
+		localChecksum := hex.EncodeToString(sum[:])
+
+		// Сервер получает контрольную сумму локального файла и сообщает, что версия не изменилась
+		mockAPI.On("DownloadVault", mock.Anything, localChecksum).Return(nil, nil, api.ErrNotModified).Once()
+
+		model := &model{
+			kdbxPath:  testFilePath,
+			apiClient: mockAPI,
+		}
+
+		msg := downloadVaultCmd(model)()
+		downloadMsg, ok := msg.(syncDownloadSuccessMsg)
+		require.True(t, ok, "Сообщение должно быть syncDownloadSuccessMsg")
+		assert.False(t, downloadMsg.reloadNeeded, "Перезагрузка не нужна, если файл не изменился")
+		assert.Equal(t, localChecksum, downloadMsg.checksum)
+
+		content, errRead := os.ReadFile(testFilePath)
+		require.NoError(t, errRead)
+		assert.Equal(t, localContent, content, "Локальный файл не должен измениться")
+		mockAPI.AssertExpectations(t)
+	})
+
 	t.Run("APIError", func(t *testing.T) {
 		// Настраиваем мок для ошибки при скачивании
 		expectedErr := errors.New("ошибка скачивания")
-		mockAPI.On("DownloadVault", mock.Anything).Return(nil, nil, expectedErr).Once()
+		mockAPI.On("DownloadVault", mock.Anything, mock.Anything).Return(nil, nil, expectedErr).Once()
 
 		// Создаем модель
 		model := &model{
@@ -1235,7 +1306,7 @@ func TestDownloadVaultCmd(t *testing.T) {
 		}
 
 		// Настраиваем мок для успешного скачивания API
-		mockAPI.On("DownloadVault", mock.Anything).Return(readCloser, mockVersion, nil).Once()
+		mockAPI.On("DownloadVault", mock.Anything, mock.Anything).Return(readCloser, mockVersion, nil).Once()
 
 		// Создаем модель с путем к файлу в read-only директории
 		model := &model{
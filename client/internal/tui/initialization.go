@@ -2,6 +2,7 @@ package tui
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/list"
@@ -25,6 +26,8 @@ const (
 // Константы для работы с данными.
 const (
 	bytesPerKilobyte = 1024.0 // Добавлено для mnd
+	// deviceIDDisplayLength - сколько символов идентификатора устройства показывать в списке версий.
+	deviceIDDisplayLength = 8
 )
 
 // versionItem представляет элемент в списке версий.
@@ -60,6 +63,33 @@ func (i versionItem) Description() string {
 		description += fmt.Sprintf("Размер: %.2f KB", sizeKB)
 	}
 
+	// Формат базы, определенный сервером по заголовку файла (для старых версий неизвестен)
+	if i.version.KdbxVersion != nil {
+		if description != "" {
+			description += " | "
+		}
+		description += "KDBX " + *i.version.KdbxVersion
+		if i.version.KdbxCipher != nil && i.version.KdbxKDF != nil {
+			description += fmt.Sprintf(" (%s, %s)", *i.version.KdbxCipher, *i.version.KdbxKDF)
+		}
+	}
+
+	// Клиент, устройство и IP, с которых загружена версия (для старых версий неизвестны)
+	if origin := versionOrigin(i.version); origin != "" {
+		if description != "" {
+			description += " | "
+		}
+		description += origin
+	}
+
+	// Версия, созданная откатом, ссылается на восстановленную версию
+	if i.version.RestoredFromVersionID != nil {
+		if description != "" {
+			description += " | "
+		}
+		description += fmt.Sprintf("Восстановлена из #%d", *i.version.RestoredFromVersionID)
+	}
+
 	// Если ничего нет, просто выводим ID
 	if description == "" {
 		description = fmt.Sprintf("ID: %d", i.version.ID)
@@ -68,6 +98,30 @@ func (i versionItem) Description() string {
 	return description
 }
 
+// versionOrigin описывает клиент, устройство и IP-адрес, с которых загружена версия.
+// Идентификатор устройства сокращается до deviceIDDisplayLength символов.
+func versionOrigin(v models.VaultVersion) string {
+	var parts []string
+	if v.ClientName != nil {
+		client := "Клиент: " + *v.ClientName
+		if v.ClientVersion != nil {
+			client += " " + *v.ClientVersion
+		}
+		parts = append(parts, client)
+	}
+	if v.DeviceID != nil {
+		deviceID := []rune(*v.DeviceID)
+		if len(deviceID) > deviceIDDisplayLength {
+			deviceID = deviceID[:deviceIDDisplayLength]
+		}
+		parts = append(parts, "Устройство: "+string(deviceID))
+	}
+	if v.UploaderIP != nil {
+		parts = append(parts, "IP: "+*v.UploaderIP)
+	}
+	return strings.Join(parts, " | ")
+}
+
 func (i versionItem) FilterValue() string {
 	// Фильтрация не используется для этого списка, но интерфейс требует реализации
 	return i.Title()
@@ -52,6 +52,10 @@ func TestVersionItem_Description(t *testing.T) {
 	now := time.Now()
 	nowStr := now.Format(time.RFC3339)
 	size := int64(2048) // 2 KB
+	restoredFrom := int64(3)
+	kdbxVersion, kdbxCipher, kdbxKDF := "4.1", "AES-256", "Argon2id"
+	clientName, clientVersion := "gophkeeper-client", "v1.2.0"
+	deviceID, uploaderIP := "5f2c9a0e7b3d41e8a0c4", "192.0.2.10"
 
 	tests := []struct {
 		name            string
@@ -110,6 +114,56 @@ func TestVersionItem_Description(t *testing.T) {
 			},
 			wantDescription: fmt.Sprintf("Изменена: %s | Размер: 2.00 KB", nowStr),
 		},
+		{
+			name: "Версия, восстановленная откатом",
+			item: versionItem{
+				version: models.VaultVersion{
+					ID:                    6,
+					ContentModifiedAt:     &now,
+					SizeBytes:             &size,
+					RestoredFromVersionID: &restoredFrom,
+				},
+			},
+			wantDescription: fmt.Sprintf("Изменена: %s | Размер: 2.00 KB | Восстановлена из #3", nowStr),
+		},
+		{
+			name: "Версия с форматом KDBX",
+			item: versionItem{
+				version: models.VaultVersion{
+					ID:                7,
+					ContentModifiedAt: &now,
+					SizeBytes:         &size,
+					KdbxVersion:       &kdbxVersion,
+					KdbxCipher:        &kdbxCipher,
+					KdbxKDF:           &kdbxKDF,
+				},
+			},
+			wantDescription: fmt.Sprintf("Изменена: %s | Размер: 2.00 KB | KDBX 4.1 (AES-256, Argon2id)", nowStr),
+		},
+		{
+			name: "Клиент и устройство загрузки",
+			item: versionItem{
+				version: models.VaultVersion{
+					ID:            8,
+					SizeBytes:     &size,
+					ClientName:    &clientName,
+					ClientVersion: &clientVersion,
+					DeviceID:      &deviceID,
+					UploaderIP:    &uploaderIP,
+				},
+			},
+			wantDescription: "Размер: 2.00 KB | Клиент: gophkeeper-client v1.2.0 | Устройство: 5f2c9a0e | IP: 192.0.2.10",
+		},
+		{
+			name: "Только IP (клиент без заголовков)",
+			item: versionItem{
+				version: models.VaultVersion{
+					ID:         9,
+					UploaderIP: &uploaderIP,
+				},
+			},
+			wantDescription: "IP: 192.0.2.10",
+		},
 	}
 
 	for _, tt := range tests {
@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"context"
 	"encoding/hex"
 	"fmt"
 	"sync"
@@ -35,6 +36,7 @@ const (
 	loginScreen               // Экран ввода данных для входа
 	registerScreen            // Экран ввода данных для регистрации
 	versionListScreen         // Экран списка версий
+	serverPinChangedScreen    // Экран подтверждения нового ключа сервера
 )
 
 // String возвращает строковое представление screenState.
@@ -70,6 +72,8 @@ func (s screenState) String() string {
 		return "registerScreen"
 	case versionListScreen:
 		return "versionListScreen"
+	case serverPinChangedScreen:
+		return "serverPinChangedScreen"
 	default:
 		return fmt.Sprintf("unknownScreen(%d)", s)
 	}
@@ -252,6 +256,12 @@ type model struct {
 	docStyle                  lipgloss.Style  // Общий стиль для обрамления View
 	debugMode                 bool            // Флаг режима отладки
 
+	// -- Поля для проверки сертификата сервера --
+	tlsOptions           api.TLSOptions // Параметры проверки сертификата для создаваемых API клиентов
+	savedServerPin       string         // Закрепленный ключ сервера, сохраненный в KDBX
+	newServerPin         string         // Новый ключ сервера, ожидающий подтверждения пользователя
+	serverPinReturnState screenState    // Экран, на который возвращаемся после подтверждения ключа
+
 	// -- Поля для состояния синхронизации --
 	isSyncing          bool                 // Флаг: идет ли процесс синхронизации
 	serverMeta         *models.VaultVersion // Метаданные сервера
@@ -261,6 +271,15 @@ type model struct {
 	receivedServerMeta bool                 // Флаг: получены ли метаданные сервера
 	receivedLocalMeta  bool                 // Флаг: получены ли метаданные локального файла
 
+	// -- Поля для отмены сетевых запросов --
+	operation *operation // Текущая операция с сервером (nil, если ее нет), отменяется по Esc
+
+	// -- Поля для фоновой подписки на события сервера --
+	eventsCancel        context.CancelFunc // Отмена текущей подписки (nil, если подписки нет)
+	eventsGeneration    int                // Номер текущей подписки, отсекает сообщения отмененных подписок
+	knownServerChecksum string             // SHA256 версии на сервере, совпадающей с локальной
+	serverNotice        string             // Уведомление о новой версии на сервере (отображается внизу)
+
 	// -- Поля для работы с версиями --
 	versionList                list.Model            // Список версий
 	versions                   []models.VaultVersion // Полученные с сервера версии
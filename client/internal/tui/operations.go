@@ -0,0 +1,59 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// operation - выполняющийся сетевой запрос к серверу, который пользователь может отменить по Esc.
+type operation struct {
+	cancel context.CancelFunc
+	done   atomic.Bool // Выставляется командой по завершении (из горутины команды)
+}
+
+// finish отмечает операцию завершенной и освобождает ее контекст.
+// Вызывается в команде через defer.
+func (op *operation) finish() {
+	op.done.Store(true)
+	op.cancel()
+}
+
+// run оборачивает команду, выполняющую операцию, так чтобы по ее завершении операция была отмечена завершенной.
+func (op *operation) run(cmd tea.Cmd) tea.Cmd {
+	return func() tea.Msg {
+		defer op.finish()
+		return cmd()
+	}
+}
+
+// startOperation создает контекст для новой сетевой операции. Предыдущая операция, если она
+// еще выполняется, отменяется: одновременно выполняется не больше одной операции.
+// Контекст создается при построении команды, а не внутри нее, чтобы Esc мог отменить его сразу.
+func (m *model) startOperation() (context.Context, *operation) {
+	m.cancelOperation()
+	ctx, cancel := context.WithCancel(context.Background())
+	m.operation = &operation{cancel: cancel}
+	return ctx, m.operation
+}
+
+// cancelOperation отменяет текущую операцию. Возвращает false, если отменять нечего.
+func (m *model) cancelOperation() bool {
+	op := m.operation
+	m.operation = nil
+	if op == nil || op.done.Load() {
+		return false
+	}
+	slog.Info("Отмена выполняющейся операции")
+	op.cancel()
+	return true
+}
+
+// isCanceled сообщает, что операция была прервана пользователем.
+// Результаты таких операций не показываются: статус "Операция отменена" уже выставлен.
+func isCanceled(err error) bool {
+	return errors.Is(err, context.Canceled)
+}
@@ -0,0 +1,70 @@
+//nolint:testpackage // Тесты в том же пакете для доступа к непубличным функциям
+package tui
+
+import (
+	"context"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCancelOperation проверяет отмену выполняющегося запроса по Esc.
+func TestCancelOperation(t *testing.T) {
+	t.Run("Esc прерывает вход и скрывает ошибку отмены", func(t *testing.T) {
+		s := NewScreenTestSuite()
+		m := s.Model
+		m.state = loginScreen
+		started := make(chan struct{})
+		s.Mocks.APIClient.On("Login", mock.Anything, "user", "pass").
+			Run(func(args mock.Arguments) {
+				ctx, _ := args.Get(0).(context.Context)
+				close(started)
+				<-ctx.Done()
+			}).
+			Return("", context.Canceled).Once()
+
+		cmd := m.makeLoginCmd("user", "pass")
+		result := make(chan tea.Msg, 1)
+		go func() { result <- cmd() }()
+		<-started
+
+		model, _ := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+		m = toModel(t, model)
+		assert.Equal(t, "Операция отменена", m.savingStatus)
+		assert.Equal(t, loginScreen, m.state, "Первый Esc только отменяет запрос")
+		assert.Nil(t, m.operation)
+
+		msg := <-result
+		require.IsType(t, LoginError{}, msg)
+		model, _ = m.Update(msg)
+		m = toModel(t, model)
+		require.NoError(t, m.err, "Ошибка отмененной операции не показывается")
+		s.Mocks.APIClient.AssertExpectations(t)
+	})
+
+	t.Run("Esc без выполняющейся операции обрабатывается экраном", func(t *testing.T) {
+		s := NewScreenTestSuite()
+		m := s.Model
+		s.Mocks.APIClient.On("ListVersions", mock.Anything, defaultVersionListLimit, 0).
+			Return(nil, int64(0), nil).Once()
+		m.authToken = "token"
+
+		// Завершенная операция не перехватывает Esc
+		_ = loadVersionsCmd(m)()
+		assert.False(t, m.cancelOperation())
+	})
+
+	t.Run("Новая операция отменяет предыдущую", func(t *testing.T) {
+		m := NewScreenTestSuite().Model
+		first, _ := m.startOperation()
+		second, _ := m.startOperation()
+
+		require.ErrorIs(t, first.Err(), context.Canceled)
+		require.NoError(t, second.Err())
+		assert.True(t, m.cancelOperation())
+		require.ErrorIs(t, second.Err(), context.Canceled)
+	})
+}
@@ -7,8 +7,6 @@ import (
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 
-	// Убедимся, что импорт есть.
-	"github.com/maynagashev/gophkeeper/client/internal/api"
 	"github.com/maynagashev/gophkeeper/client/internal/kdbx"
 )
 
@@ -93,7 +91,7 @@ func (m *model) _handleAuthLoadSuccess(loadedURL, loadedToken string, urlFromFla
 		m.serverURL = loadedURL
 		m.authToken = loadedToken
 		if m.serverURL != "" {
-			m.apiClient = api.NewHTTPClient(m.serverURL)
+			m.apiClient = m.newAPIClient(m.serverURL)
 			slog.Info("URL сервера загружен из KDBX, создан API клиент", "url", m.serverURL, "token_found", m.authToken != "")
 		} else {
 			m.apiClient = nil
@@ -123,13 +121,19 @@ func (m *model) handleDBOpenedMsg(msg dbOpenedMsg) (tea.Model, tea.Cmd) {
 	urlFromFlag := m.serverURL != "" && m.apiClient != nil
 	slog.Debug("Проверка URL из флага", "urlFromFlag", urlFromFlag, "initialURL", m.serverURL)
 
-	loadedURL, loadedToken, errLoad := kdbx.LoadAuthData(m.db)
+	loadedURL, loadedToken, loadedPin, errLoad := kdbx.LoadAuthData(m.db)
 	// Вызываем соответствующие хелперы
 	if errLoad != nil {
 		m._handleAuthLoadError(errLoad, urlFromFlag)
 	} else {
 		m._handleAuthLoadSuccess(loadedURL, loadedToken, urlFromFlag)
 	}
+	// Закрепленный ключ относится к сохраненному URL и не применяется к серверу, заданному флагом
+	if errLoad == nil && loadedURL == m.serverURL {
+		m.setServerPin(loadedPin)
+	} else {
+		m.setServerPin("")
+	}
 
 	// Устанавливаем токен в API клиенте, если клиент существует
 	if m.apiClient != nil {
@@ -164,6 +168,11 @@ func (m *model) handleDBOpenedMsg(msg dbOpenedMsg) (tea.Model, tea.Cmd) {
 	if prevState != entryListScreen {
 		dbOpenedCmds = append(dbOpenedCmds, tea.ClearScreen)
 	}
+	// Подписываемся на события сервера, если сессия загружена из KDBX.
+	// При перезагрузке базы после скачивания подписка уже активна.
+	if m.eventsCancel == nil {
+		dbOpenedCmds = append(dbOpenedCmds, m.startVaultEvents())
+	}
 
 	slog.Debug("handleDBOpenedMsg: Конец, m.db обновлен")
 	return m, tea.Batch(dbOpenedCmds...)
@@ -185,7 +185,7 @@ func TestHandleAuthLoadError(t *testing.T) {
 	t.Run("URL не из флага", func(t *testing.T) {
 		initialModel := &model{
 			serverURL: "http://existing.url",
-			apiClient: api.NewHTTPClient("http://existing.url"),
+			apiClient: api.NewHTTPClient("http://existing.url", api.TLSOptions{}),
 			authToken: "existing_token",
 		}
 		errLoad := errors.New("test auth load error")
@@ -202,7 +202,7 @@ func TestHandleAuthLoadError(t *testing.T) {
 	t.Run("URL из флага", func(t *testing.T) {
 		initialModel := &model{
 			serverURL: "http://flag.url",
-			apiClient: api.NewHTTPClient("http://flag.url"),
+			apiClient: api.NewHTTPClient("http://flag.url", api.TLSOptions{}),
 			authToken: "existing_token",
 		}
 		errLoad := errors.New("test auth load error")
@@ -261,7 +261,7 @@ func TestHandleAuthLoadSuccess(t *testing.T) {
 	t.Run("URL из флага, токен загружен", func(t *testing.T) {
 		initialModel := &model{
 			serverURL: "http://flag.url",
-			apiClient: api.NewHTTPClient("http://flag.url"), // Клиент уже есть
+			apiClient: api.NewHTTPClient("http://flag.url", api.TLSOptions{}), // Клиент уже есть
 		}
 		loadedURL := "http://ignored.url"
 		loadedToken := "loaded_token"
@@ -277,7 +277,7 @@ func TestHandleAuthLoadSuccess(t *testing.T) {
 	t.Run("URL из флага, токен пустой", func(t *testing.T) {
 		initialModel := &model{
 			serverURL: "http://flag.url",
-			apiClient: api.NewHTTPClient("http://flag.url"),
+			apiClient: api.NewHTTPClient("http://flag.url", api.TLSOptions{}),
 		}
 		loadedURL := "http://ignored.url"
 		loadedToken := ""
@@ -0,0 +1,122 @@
+package tui
+
+import (
+	"log/slog"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/maynagashev/gophkeeper/client/internal/api"
+	"github.com/maynagashev/gophkeeper/client/internal/kdbx"
+)
+
+// newAPIClient создает API клиент для URL сервера с параметрами проверки сертификата модели.
+func (m *model) newAPIClient(serverURL string) api.Client {
+	return api.NewClient(serverURL, m.tlsOptions)
+}
+
+// serverPin возвращает закрепленный ключ сервера для сохранения в KDBX.
+// Без закрепления (-tls-pinning) сохраненный ранее ключ не удаляется.
+func (m *model) serverPin() string {
+	if m.tlsOptions.Pins == nil {
+		return m.savedServerPin
+	}
+	return m.tlsOptions.Pins.Pin()
+}
+
+// setServerPin задает закрепленный ключ при смене сервера или загрузке KDBX (пусто - ключ еще не известен).
+func (m *model) setServerPin(pin string) {
+	m.savedServerPin = pin
+	if m.tlsOptions.Pins != nil {
+		m.tlsOptions.Pins.Reset(pin)
+	}
+}
+
+// serverPinMismatch сообщает, что соединение с сервером отклонено из-за смены его ключа.
+func (m *model) serverPinMismatch() bool {
+	return m.tlsOptions.Pins != nil && m.tlsOptions.Pins.Mismatch() != ""
+}
+
+// checkServerPin вызывается после обработки каждого сообщения: переходит к подтверждению,
+// если соединение отклонено из-за смены ключа сервера, и сохраняет в KDBX ключ, закрепленный при первом соединении.
+func (m *model) checkServerPin() tea.Cmd {
+	pins := m.tlsOptions.Pins
+	if pins == nil || m.state == serverPinChangedScreen {
+		return nil
+	}
+	if changed := pins.Mismatch(); changed != "" {
+		slog.Warn("Ключ сервера не совпадает с закрепленным", "url", m.serverURL, "pin", pins.Pin(), "new_pin", changed)
+		m.newServerPin = changed
+		m.serverPinReturnState = m.state
+		m.state = serverPinChangedScreen
+		return tea.ClearScreen
+	}
+	pin := pins.Pin()
+	if pin == "" || pin == m.savedServerPin || m.db == nil {
+		return nil
+	}
+	if err := kdbx.SaveAuthData(m.db, m.serverURL, m.authToken, pin); err != nil {
+		slog.Error("Ошибка сохранения ключа сервера в KDBX", "error", err)
+		return nil
+	}
+	m.savedServerPin = pin
+	slog.Info("Ключ сервера закреплен", "url", m.serverURL, "pin", pin)
+	_, cmd := m.setStatusMessage("Ключ сервера закреплен: " + pin)
+	return cmd
+}
+
+// updateServerPinChangedScreen обрабатывает решение пользователя о доверии новому ключу сервера.
+func (m *model) updateServerPinChangedScreen(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch keyMsg.String() {
+	case "y", "Y":
+		m.setServerPin(m.newServerPin)
+		if m.db != nil {
+			if err := kdbx.SaveAuthData(m.db, m.serverURL, m.authToken, m.newServerPin); err != nil {
+				slog.Error("Ошибка сохранения ключа сервера в KDBX", "error", err)
+			}
+		}
+		slog.Info("Пользователь подтвердил новый ключ сервера", "url", m.serverURL, "pin", m.newServerPin)
+		m.newServerPin = ""
+		m.state = m.serverPinReturnState
+		newM, cmd := m.setStatusMessage("Новый ключ сервера закреплен, повторите операцию")
+		return newM, tea.Batch(cmd, tea.ClearScreen, m.startVaultEvents())
+	case "n", "N", keyEsc:
+		// Прежний ключ остается закрепленным, соединения с новым ключом отклоняются
+		m.tlsOptions.Pins.Reset(m.tlsOptions.Pins.Pin())
+		slog.Warn("Пользователь отклонил новый ключ сервера", "url", m.serverURL, "pin", m.newServerPin)
+		m.newServerPin = ""
+		m.state = m.serverPinReturnState
+		newM, cmd := m.setStatusMessage("Соединение отклонено: ключ сервера не совпадает с закрепленным")
+		return newM, tea.Batch(cmd, tea.ClearScreen)
+	}
+	return m, nil
+}
+
+// viewServerPinChangedScreen отображает предупреждение о смене ключа сервера.
+func (m *model) viewServerPinChangedScreen() string {
+	var b strings.Builder
+
+	warningStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("196")) // Красный
+	focusedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("205"))            // Пурпурный
+	subtleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))             // Серый
+
+	pinned := ""
+	if m.tlsOptions.Pins != nil {
+		pinned = m.tlsOptions.Pins.Pin()
+	}
+	b.WriteString(warningStyle.Render("ВНИМАНИЕ: открытый ключ сервера изменился!") + "\n\n")
+	b.WriteString("Сервер: " + m.serverURL + "\n")
+	b.WriteString("Закрепленный ключ (SHA-256 SPKI): " + pinned + "\n")
+	b.WriteString("Новый ключ (SHA-256 SPKI):        " + m.newServerPin + "\n\n")
+	b.WriteString("Если сертификат сервера не перевыпускали с новым ключом, соединение может быть перехвачено.\n")
+	b.WriteString("Сверьте новый ключ с выводом сервера при создании сертификата.\n\n")
+	b.WriteString("Доверять новому ключу? " + focusedStyle.Render("(Y - да, N - нет)") + "\n\n")
+	b.WriteString(subtleStyle.Render("Пока ключ не подтвержден, соединения с сервером отклоняются"))
+
+	return b.String()
+}
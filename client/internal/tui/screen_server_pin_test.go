@@ -0,0 +1,95 @@
+//nolint:testpackage // Это тесты в том же пакете для доступа к приватным компонентам
+package tui
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/maynagashev/gophkeeper/client/internal/api"
+	"github.com/maynagashev/gophkeeper/client/internal/kdbx"
+)
+
+const testPinnedKey = "n5B6LTb6d7mXoHJ0C5uyG8C6X1ULhbQuWnRzV5LTvWw="
+
+// newPinSuite возвращает тестовую среду с закреплением ключа и соединяется с HTTPS-сервером,
+// ключ которого не совпадает с закрепленным testPinnedKey.
+func newPinSuite(t *testing.T) *ScreenTestSuite {
+	t.Helper()
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+	t.Cleanup(server.Close)
+
+	suite := NewScreenTestSuite().WithServerURL(server.URL).WithDatabase(CreateBasicTestDB()).WithState(loginScreen)
+	suite.Model.tlsOptions = api.TLSOptions{Pins: api.NewPinVerifier("")}
+	suite.Model.setServerPin(testPinnedKey)
+
+	err := suite.Model.newAPIClient(server.URL).Register(context.Background(), "user", "password")
+	require.ErrorIs(t, err, api.ErrServerKeyChanged)
+	return suite
+}
+
+func TestServerPinChangedScreen(t *testing.T) {
+	t.Run("Смена ключа открывает экран подтверждения", func(t *testing.T) {
+		suite := newPinSuite(t)
+
+		_, cmd := suite.Model.Update(clearStatusMsg{})
+
+		assert.NotNil(t, cmd)
+		suite.AssertState(t, serverPinChangedScreen)
+		assert.Equal(t, loginScreen, suite.Model.serverPinReturnState)
+		assert.NotEmpty(t, suite.Model.newServerPin)
+		suite.AssertViewContains(t, "открытый ключ сервера изменился")
+		suite.AssertViewContains(t, testPinnedKey)
+		suite.AssertViewContains(t, suite.Model.newServerPin)
+	})
+
+	t.Run("Подтвержденный ключ закрепляется и сохраняется в KDBX", func(t *testing.T) {
+		suite := newPinSuite(t)
+		suite.Model.Update(clearStatusMsg{})
+		newPin := suite.Model.newServerPin
+
+		suite.SimulateKeyRune('y')
+
+		suite.AssertState(t, loginScreen)
+		assert.Equal(t, newPin, suite.Model.tlsOptions.Pins.Pin())
+		assert.Empty(t, suite.Model.tlsOptions.Pins.Mismatch())
+		_, _, savedPin, err := kdbx.LoadAuthData(suite.Model.db)
+		require.NoError(t, err)
+		assert.Equal(t, newPin, savedPin)
+	})
+
+	t.Run("Отклоненный ключ не закрепляется", func(t *testing.T) {
+		suite := newPinSuite(t)
+		suite.Model.Update(clearStatusMsg{})
+
+		suite.SimulateKeyRune('n')
+
+		suite.AssertState(t, loginScreen)
+		assert.Equal(t, testPinnedKey, suite.Model.tlsOptions.Pins.Pin())
+		assert.Empty(t, suite.Model.tlsOptions.Pins.Mismatch())
+		assert.Contains(t, suite.Model.savingStatus, "Соединение отклонено")
+	})
+}
+
+func TestCheckServerPin_TrustOnFirstUse(t *testing.T) {
+	suite := NewScreenTestSuite().WithServerURL("https://localhost:8443").WithDatabase(CreateBasicTestDB())
+	suite.Model.tlsOptions = api.TLSOptions{Pins: api.NewPinVerifier("")}
+	assert.Nil(t, suite.Model.checkServerPin(), "Пока соединений не было, сохранять нечего")
+
+	// Ключ закреплен при первом соединении
+	suite.Model.tlsOptions.Pins.Reset(testPinnedKey)
+	cmd := suite.Model.checkServerPin()
+
+	assert.NotNil(t, cmd)
+	assert.Equal(t, testPinnedKey, suite.Model.savedServerPin)
+	url, _, savedPin, err := kdbx.LoadAuthData(suite.Model.db)
+	require.NoError(t, err)
+	assert.Equal(t, "https://localhost:8443", url)
+	assert.Equal(t, testPinnedKey, savedPin)
+}
@@ -5,7 +5,6 @@ import (
 	"log/slog"
 
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/maynagashev/gophkeeper/client/internal/api"
 )
 
 // updateServerURLInputScreen обрабатывает ввод URL сервера.
@@ -26,7 +25,8 @@ func (m *model) updateServerURLInputScreen(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Сбрасываем статус, т.к. URL изменился
 			m.loginStatus = "Не выполнен"
 			m.authToken = ""
-			m.apiClient = api.NewHTTPClient(newURL) // Пересоздаем клиент с новым URL
+			m.setServerPin("")                   // Ключ нового сервера закрепляется при первом соединении
+			m.apiClient = m.newAPIClient(newURL) // Пересоздаем клиент с новым URL
 			slog.Info("URL сервера обновлен", "url", newURL)
 			// Переходим к выбору логина/регистрации
 			m.state = loginRegisterChoiceScreen
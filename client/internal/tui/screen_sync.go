@@ -120,7 +120,7 @@ func (m *model) handleSyncMenuLogout() tea.Cmd {
 	}
 	var saveCmd tea.Cmd
 	if m.db != nil {
-		errSave := kdbx.SaveAuthData(m.db, m.serverURL, "")
+		errSave := kdbx.SaveAuthData(m.db, m.serverURL, "", m.serverPin())
 		if errSave != nil {
 			slog.Error("Ошибка сохранения пустого токена в KDBX при выходе", "error", errSave)
 			_, saveCmd = m.setStatusMessage("Ошибка сохранения данных при выходе")
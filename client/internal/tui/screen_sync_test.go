@@ -414,7 +414,7 @@ func TestHandleSyncMenuLogout(t *testing.T) {
 					},
 				}
 				// Устанавливаем URL в метаданные, чтобы SaveAuthData мог его использовать
-				_ = kdbx.SaveAuthData(m.db, tt.serverURL, tt.authToken) // Сохраняем начальные данные, игнорируем ошибку
+				_ = kdbx.SaveAuthData(m.db, tt.serverURL, tt.authToken, "") // Сохраняем начальные данные, игнорируем ошибку
 			} else {
 				m.db = nil // Убеждаемся, что db nil
 			}
@@ -454,8 +454,8 @@ func TestHandleSyncMenuLogout(t *testing.T) {
 
 			// Дополнительная проверка: если db был установлен, проверим, что токен удален из KDBX (косвенно)
 			if tt.dbIsSet && tt.expectTokenCleared {
-				// LoadAuthData возвращает url, token, err
-				_, loadedToken, _ := kdbx.LoadAuthData(m.db)
+				// LoadAuthData возвращает url, token, pin, err
+				_, loadedToken, _, _ := kdbx.LoadAuthData(m.db)
 				assert.Empty(t, loadedToken, "Токен должен быть удален из KDBX")
 			}
 		})
@@ -495,7 +495,7 @@ func TestHandleSyncMenuAction(t *testing.T) {
 							Groups: []gokeepasslib.Group{gokeepasslib.NewGroup()},
 						},
 					}
-					_ = kdbx.SaveAuthData(m.db, m.serverURL, m.authToken)
+					_ = kdbx.SaveAuthData(m.db, m.serverURL, m.authToken, "")
 				}
 			} else if item.id == "login_register" {
 				m.serverURL = "http://fake.url" // Для login_register нужен URL
@@ -650,7 +650,7 @@ func TestUpdateSyncServerScreen(t *testing.T) {
 								Groups: []gokeepasslib.Group{gokeepasslib.NewGroup()},
 							},
 						}
-						_ = kdbx.SaveAuthData(m.db, m.serverURL, m.authToken)
+						_ = kdbx.SaveAuthData(m.db, m.serverURL, m.authToken, "")
 						mockAPI.On("SetAuthToken", "").Return().Once()
 					case "sync_now":
 						m.authToken = "fake-token" // Нужно для sync_now
@@ -663,7 +663,7 @@ func TestUpdateSyncServerScreen(t *testing.T) {
 								Groups: []gokeepasslib.Group{gokeepasslib.NewGroup()},
 							},
 						}
-						_ = kdbx.SaveAuthData(m.db, m.serverURL, m.authToken)
+						_ = kdbx.SaveAuthData(m.db, m.serverURL, m.authToken, "")
 						// startSyncCmd пока не вызывает API, мок не нужен
 					}
 				}
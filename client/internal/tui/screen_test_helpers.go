@@ -80,8 +80,11 @@ func (m *ScreenTestMockAPIClient) UploadVault(
 }
 
 // DownloadVault мокирует метод DownloadVault.
-func (m *ScreenTestMockAPIClient) DownloadVault(ctx context.Context) (io.ReadCloser, *models.VaultVersion, error) {
-	args := m.Called(ctx)
+func (m *ScreenTestMockAPIClient) DownloadVault(
+	ctx context.Context,
+	ifNoneMatch string,
+) (io.ReadCloser, *models.VaultVersion, error) {
+	args := m.Called(ctx, ifNoneMatch)
 	if args.Get(0) == nil {
 		return nil, nil, args.Error(mockErrorIndex)
 	}
@@ -136,6 +139,19 @@ func (m *ScreenTestMockAPIClient) RollbackToVersion(ctx context.Context, version
 	return args.Error(0)
 }
 
+// SubscribeEvents мокирует метод SubscribeEvents.
+func (m *ScreenTestMockAPIClient) SubscribeEvents(ctx context.Context) (<-chan models.VaultEvent, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	events, ok := args.Get(0).(<-chan models.VaultEvent)
+	if !ok {
+		return nil, errors.New("неверный тип результата <-chan models.VaultEvent")
+	}
+	return events, args.Error(1)
+}
+
 // SetAuthToken мокирует метод SetAuthToken.
 func (m *ScreenTestMockAPIClient) SetAuthToken(token string) {
 	m.Called(token)
@@ -189,6 +205,7 @@ func NewScreenTestSuite() *ScreenTestSuite {
 		loginScreen:                "(Tab - след. поле, Enter - войти, Esc - назад)",
 		registerScreen:             "(Tab - след. поле, Enter - зарегистрироваться, Esc - назад)",
 		versionListScreen:          "(↑/↓ - навигация, Enter - откатить, Esc/b - назад, r - обновить)",
+		serverPinChangedScreen:     "(Y - доверять новому ключу, N/Esc - отклонить)",
 	}
 
 	return s
@@ -154,8 +154,8 @@ func TestScreenTestMockAPIClient_DownloadVault(t *testing.T) {
 
 	// Тест успешной загрузки
 	t.Run("Success", func(t *testing.T) {
-		mockClient.On("DownloadVault", ctx).Return(reader, expectedVersion, nil).Once()
-		downloadedReader, version, err := mockClient.DownloadVault(ctx)
+		mockClient.On("DownloadVault", ctx, "").Return(reader, expectedVersion, nil).Once()
+		downloadedReader, version, err := mockClient.DownloadVault(ctx, "")
 		require.NoError(t, err)
 		assert.Equal(t, expectedVersion, version)
 		// Читаем содержимое ридера для проверки
@@ -170,8 +170,8 @@ func TestScreenTestMockAPIClient_DownloadVault(t *testing.T) {
 
 	// Тест ошибки загрузки
 	t.Run("Error", func(t *testing.T) {
-		mockClient.On("DownloadVault", ctx).Return(nil, nil, expectedErr).Once()
-		downloadedReader, version, err := mockClient.DownloadVault(ctx)
+		mockClient.On("DownloadVault", ctx, "").Return(nil, nil, expectedErr).Once()
+		downloadedReader, version, err := mockClient.DownloadVault(ctx, "")
 		require.Error(t, err)
 		assert.Equal(t, expectedErr, err)
 		assert.Nil(t, downloadedReader)
@@ -183,8 +183,8 @@ func TestScreenTestMockAPIClient_DownloadVault(t *testing.T) {
 	t.Run("SuccessOnlyReader", func(t *testing.T) {
 		// Пересоздаем reader
 		reader = io.NopCloser(strings.NewReader(fileContent))
-		mockClient.On("DownloadVault", ctx).Return(reader, nil, nil).Once()
-		downloadedReader, version, err := mockClient.DownloadVault(ctx)
+		mockClient.On("DownloadVault", ctx, "").Return(reader, nil, nil).Once()
+		downloadedReader, version, err := mockClient.DownloadVault(ctx, "")
 		require.NoError(t, err)
 		assert.Nil(t, version) // Версия должна быть nil
 		// Читаем содержимое ридера для проверки
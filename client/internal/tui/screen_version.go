@@ -45,7 +45,9 @@ type rollbackErrorMsg struct {
 
 // loadVersionsCmd загружает список версий с сервера.
 func loadVersionsCmd(m *model) tea.Cmd {
+	ctx, op := m.startOperation()
 	return func() tea.Msg {
+		defer op.finish()
 		if m.apiClient == nil {
 			return versionsLoadErrorMsg{err: errors.New("API клиент не инициализирован")}
 		}
@@ -54,7 +56,6 @@ func loadVersionsCmd(m *model) tea.Cmd {
 			return versionsLoadErrorMsg{err: errors.New("требуется авторизация")}
 		}
 
-		ctx := context.Background()
 		// Вызываем обновленную ListVersions
 		versions, currentID, err := m.apiClient.ListVersions(ctx, defaultVersionListLimit, 0)
 		if err != nil {
@@ -98,15 +99,17 @@ func rollbackToVersionCmd(ctx context.Context, m *model, versionID int64) tea.Cm
 // --- Функции обработки экрана версий --- //
 
 // handleVersionRollbackConfirm обрабатывает ввод в режиме подтверждения отката.
-func (m *model) handleVersionRollbackConfirm(ctx context.Context, keyMsg tea.KeyMsg) (tea.Model, tea.Cmd) {
+func (m *model) handleVersionRollbackConfirm(keyMsg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch keyMsg.String() {
 	case keyEnter:
 		// Подтверждение отката
 		if m.selectedVersionForRollback != nil {
 			m.confirmRollback = false
 			m.rollbackError = nil
-			// Передаем полученный контекст в команду
-			return m, tea.Batch(tea.ClearScreen, rollbackToVersionCmd(ctx, m, m.selectedVersionForRollback.ID))
+			// Откат выполняется как отменяемая операция (Esc)
+			ctx, op := m.startOperation()
+			rollbackCmd := op.run(rollbackToVersionCmd(ctx, m, m.selectedVersionForRollback.ID))
+			return m, tea.Batch(tea.ClearScreen, rollbackCmd)
 		}
 	case keyEsc, keyBack:
 		// Отмена отката
@@ -200,9 +203,7 @@ func (m *model) updateVersionListScreen(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if keyMsg, ok := msg.(tea.KeyMsg); ok {
 		// Если показывается экран подтверждения
 		if m.confirmRollback {
-			// Передаем контекст в обработчик подтверждения
-			// Используем Background(), т.к. Update не имеет контекста
-			return m.handleVersionRollbackConfirm(context.Background(), keyMsg)
+			return m.handleVersionRollbackConfirm(keyMsg)
 		}
 
 		// Если показывается ошибка отката
@@ -260,6 +261,9 @@ func handleVersionsLoadedMsg(m *model, msg versionsLoadedMsg) (tea.Model, tea.Cm
 // handleVersionsLoadErrorMsg обрабатывает ошибку загрузки версий.
 func handleVersionsLoadErrorMsg(m *model, msg versionsLoadErrorMsg) (tea.Model, tea.Cmd) {
 	m.loadingVersions = false
+	if isCanceled(msg.err) {
+		return m, nil
+	}
 	// Проверяем, является ли ошибка ошибкой авторизации
 	if errors.Is(msg.err, api.ErrAuthorization) {
 		// Не меняем состояние здесь, т.к. мы уже на экране версий,
@@ -286,6 +290,9 @@ func handleRollbackSuccessMsg(m *model, msg rollbackSuccessMsg) (tea.Model, tea.
 
 // handleRollbackErrorMsg обрабатывает ошибку отката.
 func handleRollbackErrorMsg(m *model, msg rollbackErrorMsg) (tea.Model, tea.Cmd) {
+	if isCanceled(msg.err) {
+		return m, nil
+	}
 	// Проверяем, является ли ошибка ошибкой авторизации
 	if errors.Is(msg.err, api.ErrAuthorization) {
 		m.state = loginRegisterChoiceScreen // Переходим на экран выбора входа/регистрации
@@ -42,11 +42,10 @@ func TestHandleVersionRollbackConfirm(t *testing.T) {
 		m.selectedVersionForRollback = selectedVersion
 		m.authToken = "test-token" // Добавляем токен для теста
 		// Настроим мок, чтобы rollbackToVersionCmd вернул success
-		// Используем ctxForTest, так как теперь он передается в команду
-		s.Mocks.APIClient.On("RollbackToVersion", ctxForTest, versionID).Return(nil).Once()
+		// Команда получает собственный отменяемый контекст операции
+		s.Mocks.APIClient.On("RollbackToVersion", mock.Anything, versionID).Return(nil).Once()
 
-		// Передаем контекст в handleVersionRollbackConfirm
-		model, cmd := m.handleVersionRollbackConfirm(ctxForTest, keyMsg(keyEnter)) // Используем keyMsg
+		model, cmd := m.handleVersionRollbackConfirm(keyMsg(keyEnter)) // Используем keyMsg
 
 		// Проверяем состояние
 		m = toModel(t, model)
@@ -89,8 +88,7 @@ func TestHandleVersionRollbackConfirm(t *testing.T) {
 		m.confirmRollback = true
 		m.selectedVersionForRollback = selectedVersion
 
-		// Передаем контекст в handleVersionRollbackConfirm
-		model, cmd := m.handleVersionRollbackConfirm(ctxForTest, keyMsg(keyEsc)) // Используем keyMsg
+		model, cmd := m.handleVersionRollbackConfirm(keyMsg(keyEsc)) // Используем keyMsg
 
 		m = toModel(t, model)
 		assert.False(t, m.confirmRollback, "confirmRollback should be false after Escape")
@@ -110,8 +108,7 @@ func TestHandleVersionRollbackConfirm(t *testing.T) {
 		m.selectedVersionForRollback = selectedVersion
 
 		// Используем 'b' как keyBack
-		// Передаем контекст в handleVersionRollbackConfirm
-		model, cmd := m.handleVersionRollbackConfirm(ctxForTest, keyMsg(keyBack)) // Используем keyMsg
+		model, cmd := m.handleVersionRollbackConfirm(keyMsg(keyBack)) // Используем keyMsg
 
 		m = toModel(t, model)
 		assert.False(t, m.confirmRollback, "confirmRollback should be false after Backspace")
@@ -125,13 +122,11 @@ func TestHandleVersionRollbackConfirm(t *testing.T) {
 	t.Run("Press Other Key", func(t *testing.T) {
 		s := NewScreenTestSuite()
 		m := s.Model
-		ctxForTest := context.Background()
 		m.state = versionListScreen
 		m.confirmRollback = true
 		m.selectedVersionForRollback = selectedVersion
 
-		// Передаем контекст в handleVersionRollbackConfirm
-		model, cmd := m.handleVersionRollbackConfirm(ctxForTest, keyMsg("a")) // Используем keyMsg
+		model, cmd := m.handleVersionRollbackConfirm(keyMsg("a")) // Используем keyMsg
 
 		m = toModel(t, model)
 		assert.True(t, m.confirmRollback, "confirmRollback should remain true")
@@ -142,13 +137,11 @@ func TestHandleVersionRollbackConfirm(t *testing.T) {
 	t.Run("Press Enter with nil selectedVersion", func(t *testing.T) {
 		s := NewScreenTestSuite()
 		m := s.Model
-		ctxForTest := context.Background()
 		m.state = versionListScreen
 		m.confirmRollback = true
 		m.selectedVersionForRollback = nil // Устанавливаем nil
 
-		// Передаем контекст в handleVersionRollbackConfirm
-		model, cmd := m.handleVersionRollbackConfirm(ctxForTest, keyMsg(keyEnter)) // Используем keyMsg
+		model, cmd := m.handleVersionRollbackConfirm(keyMsg(keyEnter)) // Используем keyMsg
 
 		m = toModel(t, model)
 		assert.True(t, m.confirmRollback, "confirmRollback should remain true if selectedVersion is nil")
@@ -748,9 +741,9 @@ func TestHandleRollbackSuccessMsg(t *testing.T) {
 	m := s.Model
 	// Настраиваем мок API клиента
 	mockAPI := &CommandsTestMockAPIClient{}
-	mockAPI.On("DownloadVault", mock.Anything).Return(errors.New("mock download error")) // Ожидаем вызов
-	m.apiClient = mockAPI                                                                // Используем мок
-	m.kdbxPath = "/tmp/test.kdbx"                                                        // Нужен для downloadVaultCmd
+	mockAPI.On("DownloadVault", mock.Anything, mock.Anything).Return(errors.New("mock download error")) // Ожидаем вызов
+	m.apiClient = mockAPI                                                                               // Используем мок
+	m.kdbxPath = "/tmp/test.kdbx"                                                                       // Нужен для downloadVaultCmd
 
 	rollbackVersionID := int64(99)
 	msg := rollbackSuccessMsg{versionID: rollbackVersionID}
@@ -47,11 +47,14 @@ type (
 	}
 
 	// SyncUploadSuccessMsg сигнализирует об успешной загрузке.
-	SyncUploadSuccessMsg struct{}
+	SyncUploadSuccessMsg struct {
+		Checksum string
+	}
 
 	// SyncDownloadSuccessMsg сигнализирует об успешном скачивании.
 	SyncDownloadSuccessMsg struct {
 		ReloadNeeded bool
+		Checksum     string
 	}
 
 	// LoginSuccessMsg сообщение об успешном входе.
@@ -77,6 +77,8 @@ func (m *model) getMainContentView() string {
 		return m.viewRegisterScreen()
 	case versionListScreen:
 		return m.viewVersionListScreen()
+	case serverPinChangedScreen:
+		return m.viewServerPinChangedScreen()
 	default:
 		return "Неизвестное состояние!"
 	}
@@ -168,6 +170,10 @@ func (m *model) View() string {
 		footer.WriteString(m.savingStatus)
 		footer.WriteString(readOnlyIndicator)
 	}
+	if m.serverNotice != "" {
+		footer.WriteString("\n")
+		footer.WriteString(m.serverNotice)
+	}
 
 	// Добавляем отладку, если включен режим
 	if m.debugMode {
@@ -186,12 +192,12 @@ func (m *model) View() string {
 	return fmt.Sprintf("%s\n%s%s", styledContent, help, footer.String())
 }
 
-// Start запускает TUI приложение.
-func Start(kdbxPath string, debugMode bool, serverURL string) {
+// Start запускает TUI приложение. tlsOptions задают проверку сертификата сервера для всех API клиентов.
+func Start(kdbxPath string, debugMode bool, serverURL string, tlsOptions api.TLSOptions) {
 	// --- Инициализация API клиента ---
 	var apiClient api.Client // Объявляем переменную
 	if serverURL != "" {     // Создаем клиент, только если URL не пустой
-		apiClient = api.NewHTTPClient(serverURL)
+		apiClient = api.NewClient(serverURL, tlsOptions)
 		slog.Info("API клиент инициализирован", "baseURL", serverURL)
 	} else {
 		slog.Warn("URL сервера не указан (--server-url), функции API будут недоступны.")
@@ -200,6 +206,7 @@ func Start(kdbxPath string, debugMode bool, serverURL string) {
 
 	// Создаем начальную модель, передавая флаг
 	m := initModel(kdbxPath, debugMode, serverURL, apiClient)
+	m.tlsOptions = tlsOptions
 
 	// --- Инициализация helpTextMap ---
 	m.helpTextMap = map[screenState]string{
@@ -218,6 +225,7 @@ func Start(kdbxPath string, debugMode bool, serverURL string) {
 		loginScreen:                "(Tab - след. поле, Enter - войти, Esc - назад)",
 		registerScreen:             "(Tab - след. поле, Enter - зарегистрироваться, Esc - назад)",
 		versionListScreen:          "(↑/↓ - навигация, Enter - откатить, Esc/b - назад, r - обновить)",
+		serverPinChangedScreen:     "(Y - доверять новому ключу, N/Esc - отклонить)",
 	}
 
 	// --- Реализация flock ---
@@ -31,6 +31,9 @@ func handleWindowSizeMsg(m *model, msg tea.WindowSizeMsg) {
 	if m.savingStatus != "" || m.readOnlyMode {
 		statusHeight = 1 // 1 строка для статуса/read-only
 	}
+	if m.serverNotice != "" {
+		statusHeight++ // Строка уведомления о новой версии на сервере
+	}
 	debugHeight := 0
 	if m.debugMode {
 		debugContentHeight := strings.Count(m.getDebugInfoString(), "\n") + 1 // Количество строк в отладке
@@ -101,7 +104,7 @@ func handleDBMsg(m *model, msg tea.Msg) (tea.Model, tea.Cmd, bool) {
 		newM, cmd := handleLocalMetadataMsg(m, msg)
 		return newM, cmd, true
 	case syncUploadSuccessMsg:
-		newM, cmd := handleSyncUploadSuccessMsg(m)
+		newM, cmd := handleSyncUploadSuccessMsg(m, msg)
 		return newM, cmd, true
 	case syncDownloadSuccessMsg:
 		newM, cmd := handleSyncDownloadSuccessMsg(m, msg)
@@ -140,6 +143,9 @@ func handleClearStatusMsg(m *model) (tea.Model, tea.Cmd) {
 
 func handleSyncErrorMsg(m *model, msg SyncError) (tea.Model, tea.Cmd) {
 	m.isSyncing = false
+	if isCanceled(msg.err) {
+		return m, nil
+	}
 	// Проверяем на ошибку авторизации
 	if errors.Is(msg.err, api.ErrAuthorization) {
 		m.state = loginRegisterChoiceScreen // Переходим на экран выбора входа/регистрации
@@ -168,6 +174,9 @@ func handleServerMetadataMsg(m *model, msg serverMetadataMsg) (tea.Model, tea.Cm
 	}
 	m.serverMeta = msg.metadata
 	m.serverMetaFound = msg.found
+	if msg.metadata != nil && msg.metadata.Checksum != nil {
+		m.knownServerChecksum = *msg.metadata.Checksum
+	}
 	m.receivedServerMeta = true
 	slog.Debug("Получено сообщение serverMetadataMsg", "found", msg.found)
 	if m.receivedLocalMeta {
@@ -192,20 +201,24 @@ func handleLocalMetadataMsg(m *model, msg localMetadataMsg) (tea.Model, tea.Cmd)
 	return m, nil
 }
 
-func handleSyncUploadSuccessMsg(m *model) (tea.Model, tea.Cmd) {
+func handleSyncUploadSuccessMsg(m *model, msg syncUploadSuccessMsg) (tea.Model, tea.Cmd) {
 	// TODO: Обновить время последней синхронизации в m
+	m.knownServerChecksum = msg.checksum
+	m.serverNotice = ""
 	newM, statusCmd := m.setStatusMessage("Синхронизация завершена (загружено)")
 	// Добавляем ClearScreen
 	return newM, tea.Batch(statusCmd, tea.ClearScreen)
 }
 
 func handleSyncDownloadSuccessMsg(m *model, msg syncDownloadSuccessMsg) (tea.Model, tea.Cmd) {
-	newM, statusCmd := m.setStatusMessage("Синхронизация завершена (скачано), перезагрузка...")
-	if msg.reloadNeeded {
-		openCmd := openKdbxCmd(m.kdbxPath, m.password)
-		return newM, tea.Batch(statusCmd, openCmd)
+	m.knownServerChecksum = msg.checksum
+	m.serverNotice = ""
+	if !msg.reloadNeeded {
+		return m.setStatusMessage("Синхронизация завершена (без изменений)")
 	}
-	return newM, statusCmd
+	newM, statusCmd := m.setStatusMessage("Синхронизация завершена (скачано), перезагрузка...")
+	openCmd := openKdbxCmd(m.kdbxPath, m.password)
+	return newM, tea.Batch(statusCmd, openCmd)
 }
 
 // processMetadataResults обрабатывает ситуацию, когда получены и локальные, и серверные метаданные.
@@ -321,7 +334,7 @@ func handleAPIMsg(m *model, msg tea.Msg) (tea.Model, tea.Cmd, bool) {
 
 		// Сохраняем Auth данные в KDBX (в памяти)
 		if m.db != nil {
-			errSave := kdbx.SaveAuthData(m.db, m.serverURL, m.authToken)
+			errSave := kdbx.SaveAuthData(m.db, m.serverURL, m.authToken, m.serverPin())
 			if errSave != nil {
 				slog.Error("Ошибка сохранения Auth данных в KDBX (в памяти)", "error", errSave)
 				m.err = fmt.Errorf("ошибка сохранения данных сессии: %w", errSave)
@@ -339,9 +352,12 @@ func handleAPIMsg(m *model, msg tea.Msg) (tea.Model, tea.Cmd, bool) {
 
 		// Возвращаем команды только после успешного сохранения (или если db был nil)
 		newM, statusCmd := m.setStatusMessage("Вход выполнен успешно!")
-		return newM, tea.Batch(statusCmd, tea.ClearScreen), true
+		return newM, tea.Batch(statusCmd, tea.ClearScreen, m.startVaultEvents()), true
 
 	case LoginError:
+		if isCanceled(msg.err) {
+			return m, nil, true
+		}
 		m.err = msg.err
 		newM, statusCmd := m.setStatusMessage("Ошибка входа")
 		// Добавляем очистку экрана, чтобы перерисовать с ошибкой чисто
@@ -360,6 +376,9 @@ func handleAPIMsg(m *model, msg tea.Msg) (tea.Model, tea.Cmd, bool) {
 		return newM, tea.Batch(statusCmd, tea.ClearScreen), true
 
 	case RegisterError:
+		if isCanceled(msg.err) {
+			return m, nil, true
+		}
 		m.err = msg.err
 		newM, statusCmd := m.setStatusMessage("Ошибка регистрации")
 		// Добавляем очистку экрана, чтобы перерисовать с ошибкой чисто
@@ -446,6 +465,15 @@ func handleGlobalKeys(m *model, msg tea.KeyMsg) (tea.Model, tea.Cmd, bool) {
 	switch msg.String() {
 	case "ctrl+c":
 		return m, tea.Quit, true
+	case keyEsc:
+		// Esc прерывает выполняющийся запрос к серверу; если его нет, клавиша обрабатывается экраном
+		if !m.cancelOperation() {
+			return m, nil, false
+		}
+		m.isSyncing = false
+		m.loadingVersions = false
+		newM, statusCmd := m.setStatusMessage("Операция отменена")
+		return newM, statusCmd, true
 	case "ctrl+s":
 		// Delegate saving logic to a separate function
 		updatedModel, cmd := m.handleSaveKeyPress()
@@ -457,10 +485,19 @@ func handleGlobalKeys(m *model, msg tea.KeyMsg) (tea.Model, tea.Cmd, bool) {
 	}
 }
 
-// Update обрабатывает входящие сообщения.
+// Update обрабатывает входящие сообщения, после чего проверяет закрепленный ключ сервера (см. checkServerPin).
+func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	updatedModel, cmd := m.update(msg)
+	if pinCmd := m.checkServerPin(); pinCmd != nil {
+		return updatedModel, tea.Batch(cmd, pinCmd)
+	}
+	return updatedModel, cmd
+}
+
+// update обрабатывает входящие сообщения.
 //
 //nolint:funlen // TODO: Рефакторить роутинг и длину функции (убрали gocyclo)
-func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+func (m *model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd         // Собираем команды для батчинга
 	var cmd tea.Cmd            // Команда от обработчика
 	var handled bool           // Флаг: сообщение было обработано глобальным хендлером
@@ -498,6 +535,12 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if handled {
 			return updatedModel, cmd
 		}
+
+		// Затем пытаемся обработать события сервера
+		updatedModel, cmd, handled = handleVaultEventsMsg(m, msg)
+		if handled {
+			return updatedModel, cmd
+		}
 	}
 
 	// == Обработка сообщения в зависимости от текущего состояния ==
@@ -534,6 +577,8 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		updatedModel, stateCmd = m.updateRegisterScreen(msg)
 	case versionListScreen:
 		updatedModel, stateCmd = m.updateVersionListScreen(msg)
+	case serverPinChangedScreen:
+		updatedModel, stateCmd = m.updateServerPinChangedScreen(msg)
 	default:
 		// Неизвестное состояние - ничего не делаем, updatedModel остается nil?
 		// Это нужно обработать: если updatedModel не был присвоен,
@@ -256,14 +256,17 @@ func TestHandleLocalMetadataMsg(t *testing.T) {
 // TestHandleSyncUploadSuccessMsg проверяет обработку сообщения об успешной загрузке.
 func TestHandleSyncUploadSuccessMsg(t *testing.T) {
 	m := createTestModelForUpdate()
+	m.serverNotice = serverNoticeNewerVersion
 
-	newM, cmd := handleSyncUploadSuccessMsg(m)
+	newM, cmd := handleSyncUploadSuccessMsg(m, syncUploadSuccessMsg{checksum: "abc123"})
 
 	updatedModel := newM.(*model)
 	// Разбиваем строку для линтера
 	expectedStatus := "завершена (загружено)"
 	require.Contains(t, updatedModel.savingStatus, expectedStatus,
 		"Статус должен содержать сообщение об успешной загрузке")
+	require.Equal(t, "abc123", updatedModel.knownServerChecksum, "Контрольная сумма загруженной версии должна запомниться")
+	require.Empty(t, updatedModel.serverNotice, "Уведомление о новой версии должно быть сброшено")
 	require.NotNil(t, cmd, "Должна быть возвращена команда (Batch)")
 	// TODO: Проверить, что время последней синхронизации обновлено, когда это будет реализовано
 }
@@ -370,7 +373,8 @@ func TestHandleSyncDownloadSuccessMsg(t *testing.T) {
 		newM, cmd := handleSyncDownloadSuccessMsg(m, msg)
 
 		updatedModel := newM.(*model)
-		require.Contains(t, updatedModel.savingStatus, "Синхронизация завершена (скачано)", "Статус должен обновиться")
+		require.Contains(t, updatedModel.savingStatus, "Синхронизация завершена (без изменений)",
+			"Статус должен сообщать, что файл на сервере не изменился")
 
 		// Проверяем, что команда - это просто команда статуса (без openKdbxCmd)
 		isBatch := false
@@ -0,0 +1,142 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/maynagashev/gophkeeper/client/internal/api"
+	"github.com/maynagashev/gophkeeper/models"
+)
+
+const (
+	// vaultEventsReconnectDelay - пауза перед повторной подпиской после разрыва потока событий.
+	vaultEventsReconnectDelay = 10 * time.Second
+	// serverNoticeNewerVersion - уведомление о новой версии хранилища на сервере.
+	serverNoticeNewerVersion = "На сервере доступна более новая версия (s - синхронизация)"
+)
+
+// --- Сообщения для событий сервера --- //
+
+// vaultEventMsg содержит событие хранилища, полученное с сервера.
+type vaultEventMsg struct {
+	event      models.VaultEvent
+	events     <-chan models.VaultEvent // Канал, из которого продолжаем читать события
+	generation int                      // Номер подписки, к которой относится событие
+}
+
+// vaultEventsClosedMsg сигнализирует о завершении потока событий (ошибка подписки или разрыв соединения).
+type vaultEventsClosedMsg struct {
+	err        error
+	generation int
+}
+
+// vaultEventsReconnectMsg сигнализирует, что пора повторить подписку на события.
+type vaultEventsReconnectMsg struct {
+	generation int
+}
+
+// startVaultEvents запускает фоновую подписку на события хранилища.
+// Предыдущая подписка (если была) отменяется. Возвращает nil, если сервер не настроен или вход не выполнен.
+func (m *model) startVaultEvents() tea.Cmd {
+	m.stopVaultEvents()
+	if m.apiClient == nil || m.authToken == "" {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.eventsCancel = cancel
+	m.eventsGeneration++
+	slog.Debug("Запуск подписки на события хранилища", "generation", m.eventsGeneration)
+	return subscribeVaultEventsCmd(ctx, m.apiClient, m.eventsGeneration)
+}
+
+// stopVaultEvents отменяет текущую подписку на события хранилища.
+func (m *model) stopVaultEvents() {
+	if m.eventsCancel != nil {
+		m.eventsCancel()
+		m.eventsCancel = nil
+	}
+}
+
+// subscribeVaultEventsCmd подписывается на события и ожидает первое из них.
+func subscribeVaultEventsCmd(ctx context.Context, client api.Client, generation int) tea.Cmd {
+	return func() tea.Msg {
+		events, err := client.SubscribeEvents(ctx)
+		if err != nil {
+			return vaultEventsClosedMsg{err: err, generation: generation}
+		}
+		slog.Info("Подписка на события хранилища установлена")
+		return waitForVaultEventCmd(events, generation)()
+	}
+}
+
+// waitForVaultEventCmd ожидает следующее событие из канала подписки.
+func waitForVaultEventCmd(events <-chan models.VaultEvent, generation int) tea.Cmd {
+	return func() tea.Msg {
+		event, ok := <-events
+		if !ok {
+			return vaultEventsClosedMsg{generation: generation}
+		}
+		return vaultEventMsg{event: event, events: events, generation: generation}
+	}
+}
+
+// handleVaultEventsMsg обрабатывает сообщения фоновой подписки на события хранилища.
+func handleVaultEventsMsg(m *model, msg tea.Msg) (tea.Model, tea.Cmd, bool) {
+	switch msg := msg.(type) {
+	case vaultEventMsg:
+		if msg.generation != m.eventsGeneration {
+			return m, nil, true // Событие отмененной подписки
+		}
+		m.handleVaultEvent(msg.event)
+		return m, waitForVaultEventCmd(msg.events, msg.generation), true
+
+	case vaultEventsClosedMsg:
+		if msg.generation != m.eventsGeneration || m.eventsCancel == nil {
+			return m, nil, true // Подписка была отменена намеренно
+		}
+		if errors.Is(msg.err, api.ErrAuthorization) {
+			slog.Warn("Подписка на события хранилища отклонена: требуется повторный вход")
+			m.stopVaultEvents()
+			return m, nil, true
+		}
+		if m.serverPinMismatch() {
+			// Подписка возобновится после подтверждения нового ключа сервера
+			slog.Warn("Подписка на события хранилища отклонена: ключ сервера не совпадает с закрепленным")
+			m.stopVaultEvents()
+			return m, nil, true
+		}
+		slog.Warn("Поток событий хранилища прерван, повторная подписка",
+			"error", msg.err, "delay", vaultEventsReconnectDelay)
+		generation := msg.generation
+		return m, tea.Tick(vaultEventsReconnectDelay, func(_ time.Time) tea.Msg {
+			return vaultEventsReconnectMsg{generation: generation}
+		}), true
+
+	case vaultEventsReconnectMsg:
+		if msg.generation != m.eventsGeneration || m.eventsCancel == nil {
+			return m, nil, true
+		}
+		return m, m.startVaultEvents(), true
+
+	default:
+		return m, nil, false
+	}
+}
+
+// handleVaultEvent показывает уведомление о новой версии, если событие не описывает
+// версию, которая уже совпадает с локальной (например, только что загруженную этим клиентом).
+func (m *model) handleVaultEvent(event models.VaultEvent) {
+	slog.Info("Получено событие хранилища", "type", event.Type, "versionId", event.VersionID)
+	if event.Type != models.VaultEventVersionCreated && event.Type != models.VaultEventRolledBack {
+		return
+	}
+	if event.Checksum != nil && *event.Checksum == m.knownServerChecksum {
+		slog.Debug("Версия из события совпадает с локальной, уведомление не требуется")
+		return
+	}
+	m.serverNotice = serverNoticeNewerVersion
+}
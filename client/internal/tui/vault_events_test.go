@@ -0,0 +1,134 @@
+//nolint:testpackage // Тесты в том же пакете для доступа к непубличным функциям
+package tui
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/maynagashev/gophkeeper/client/internal/api"
+	"github.com/maynagashev/gophkeeper/models"
+)
+
+// TestStartVaultEvents проверяет запуск подписки и получение событий.
+func TestStartVaultEvents(t *testing.T) {
+	t.Run("Без входа подписка не запускается", func(t *testing.T) {
+		m := createTestModelForUpdate()
+		m.apiClient = new(ScreenTestMockAPIClient)
+		m.authToken = ""
+
+		assert.Nil(t, m.startVaultEvents())
+		assert.Nil(t, m.eventsCancel)
+	})
+
+	t.Run("Событие передается в модель", func(t *testing.T) {
+		mockClient := new(ScreenTestMockAPIClient)
+		events := make(chan models.VaultEvent, 1)
+		events <- models.VaultEvent{Type: models.VaultEventVersionCreated, UserID: 1, VersionID: 7}
+		mockClient.On("SubscribeEvents", mock.Anything).Return((<-chan models.VaultEvent)(events), nil)
+
+		m := createTestModelForUpdate()
+		m.apiClient = mockClient
+		m.authToken = "token"
+
+		cmd := m.startVaultEvents()
+		require.NotNil(t, cmd)
+		require.NotNil(t, m.eventsCancel)
+
+		msg, ok := cmd().(vaultEventMsg)
+		require.True(t, ok, "Ожидалось сообщение vaultEventMsg")
+		assert.Equal(t, int64(7), msg.event.VersionID)
+		assert.Equal(t, m.eventsGeneration, msg.generation)
+		mockClient.AssertExpectations(t)
+	})
+}
+
+// TestHandleVaultEventsMsg проверяет обработку событий сервера в TUI.
+func TestHandleVaultEventsMsg(t *testing.T) {
+	checksum := "abc123"
+	otherChecksum := "def456"
+
+	t.Run("Новая версия показывает уведомление", func(t *testing.T) {
+		m := createTestModelForUpdate()
+		m.eventsGeneration = 1
+		m.knownServerChecksum = checksum
+		events := make(chan models.VaultEvent)
+
+		msg := vaultEventMsg{
+			event:      models.VaultEvent{Type: models.VaultEventVersionCreated, Checksum: &otherChecksum},
+			events:     events,
+			generation: 1,
+		}
+		_, cmd, handled := handleVaultEventsMsg(m, msg)
+
+		require.True(t, handled)
+		assert.NotNil(t, cmd, "Должна быть возвращена команда ожидания следующего события")
+		assert.Equal(t, serverNoticeNewerVersion, m.serverNotice)
+		assert.Contains(t, m.View(), serverNoticeNewerVersion)
+	})
+
+	t.Run("Версия, совпадающая с локальной, игнорируется", func(t *testing.T) {
+		m := createTestModelForUpdate()
+		m.eventsGeneration = 1
+		m.knownServerChecksum = checksum
+
+		msg := vaultEventMsg{
+			event:      models.VaultEvent{Type: models.VaultEventVersionCreated, Checksum: &checksum},
+			events:     make(chan models.VaultEvent),
+			generation: 1,
+		}
+		_, _, handled := handleVaultEventsMsg(m, msg)
+
+		require.True(t, handled)
+		assert.Empty(t, m.serverNotice)
+	})
+
+	t.Run("Событие отмененной подписки игнорируется", func(t *testing.T) {
+		m := createTestModelForUpdate()
+		m.eventsGeneration = 2
+
+		msg := vaultEventMsg{
+			event:      models.VaultEvent{Type: models.VaultEventRolledBack},
+			events:     make(chan models.VaultEvent),
+			generation: 1,
+		}
+		_, cmd, handled := handleVaultEventsMsg(m, msg)
+
+		require.True(t, handled)
+		assert.Nil(t, cmd)
+		assert.Empty(t, m.serverNotice)
+	})
+
+	t.Run("Разрыв потока планирует повторную подписку", func(t *testing.T) {
+		m := createTestModelForUpdate()
+		m.eventsGeneration = 1
+		m.eventsCancel = func() {}
+
+		_, cmd, handled := handleVaultEventsMsg(m, vaultEventsClosedMsg{err: errors.New("сеть"), generation: 1})
+
+		require.True(t, handled)
+		assert.NotNil(t, cmd, "Должна быть запланирована повторная подписка")
+		assert.NotNil(t, m.eventsCancel)
+	})
+
+	t.Run("Ошибка авторизации останавливает подписку", func(t *testing.T) {
+		m := createTestModelForUpdate()
+		m.eventsGeneration = 1
+		m.eventsCancel = func() {}
+
+		_, cmd, handled := handleVaultEventsMsg(m, vaultEventsClosedMsg{err: api.ErrAuthorization, generation: 1})
+
+		require.True(t, handled)
+		assert.Nil(t, cmd)
+		assert.Nil(t, m.eventsCancel)
+	})
+
+	t.Run("Другие сообщения не обрабатываются", func(t *testing.T) {
+		m := createTestModelForUpdate()
+		_, _, handled := handleVaultEventsMsg(m, clearStatusMsg{})
+		assert.False(t, handled)
+	})
+}
@@ -0,0 +1,72 @@
+package models
+
+import "time"
+
+// UserSummary - сведения о пользователе для администрирования.
+type UserSummary struct {
+	ID         int64      `db:"id" json:"id"`
+	Username   string     `db:"username" json:"username"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+	DisabledAt *time.Time `db:"disabled_at" json:"disabled_at,omitempty"` // nil - учетная запись активна
+	Role       string     `db:"role" json:"role"`
+	// VersionCount - количество версий хранилища пользователя.
+	VersionCount int64 `db:"version_count" json:"version_count"`
+	// StorageBytes - суммарный размер объектов версий (объект, общий для нескольких версий, учитывается один раз).
+	StorageBytes int64 `db:"storage_bytes" json:"storage_bytes"`
+	// LastUploadAt - время создания последней версии (nil, если версий нет).
+	LastUploadAt *time.Time `db:"last_upload_at" json:"last_upload_at,omitempty"`
+}
+
+// UserVersions - все версии хранилища пользователя.
+type UserVersions struct {
+	CurrentVersionID *int64         `json:"current_version_id,omitempty"` // nil, если у пользователя нет хранилища
+	Versions         []VaultVersion `json:"versions"`                     // Сначала новые
+}
+
+// PruneReport - итоги удаления старых версий хранилища.
+type PruneReport struct {
+	KeptVersions    int   `json:"kept_versions"`
+	DeletedVersions int   `json:"deleted_versions"`
+	DeletedObjects  int   `json:"deleted_objects"`
+	FreedBytes      int64 `json:"freed_bytes"`
+	// FailedObjects - ключи объектов, которые не удалось удалить из хранилища файлов
+	// (записи версий уже удалены, объекты нужно удалить повторно или вручную).
+	FailedObjects []string `json:"failed_objects,omitempty"`
+}
+
+// UserStorageReport - итоги проверки целостности объектов всех версий пользователя.
+type UserStorageReport struct {
+	Report StorageScrubReport `json:"report"`
+	// Problems - версии с поврежденными, отсутствующими или непроверенными из-за ошибки объектами.
+	Problems []VaultVersion `json:"problems"`
+}
+
+// UserDeleteReport - итоги удаления пользователя.
+type UserDeleteReport struct {
+	DeletedObjects int      `json:"deleted_objects"`
+	FailedObjects  []string `json:"failed_objects,omitempty"` // Ключи объектов, которые не удалось удалить
+}
+
+// UserStorageUsage - использование хранилища одним пользователем.
+type UserStorageUsage struct {
+	Username     string `json:"username"`
+	VersionCount int    `json:"version_count"`
+	// StorageBytes - суммарный размер объектов версий (объект, общий для нескольких версий, учитывается один раз).
+	StorageBytes     int64      `json:"storage_bytes"`
+	CurrentVersionID *int64     `json:"current_version_id,omitempty"` // nil, если у пользователя нет хранилища
+	CurrentSizeBytes *int64     `json:"current_size_bytes,omitempty"` // Размер текущей версии
+	LastUploadAt     *time.Time `json:"last_upload_at,omitempty"`     // Время создания последней версии
+}
+
+// ServerStats - сводная статистика сервера для администратора.
+type ServerStats struct {
+	Users         int64 `db:"users" json:"users"`
+	DisabledUsers int64 `db:"disabled_users" json:"disabled_users"`
+	Admins        int64 `db:"admins" json:"admins"`
+	Vaults        int64 `db:"vaults" json:"vaults"`
+	Versions      int64 `db:"versions" json:"versions"`
+	// StorageBytes - суммарный размер всех объектов (объект, общий для нескольких версий, учитывается один раз).
+	StorageBytes int64 `db:"storage_bytes" json:"storage_bytes"`
+	// LastScrub - итоги последнего прохода фоновой проверки целостности (nil, если проходов еще не было).
+	LastScrub *StorageScrubReport `db:"-" json:"last_scrub,omitempty"`
+}
@@ -0,0 +1,33 @@
+package models
+
+// ErrorResponse - тело ответа об ошибке API v2 (/api/v2).
+// Клиент определяет вид ошибки по машиночитаемому коду Code, а Message показывает пользователю.
+type ErrorResponse struct {
+	Code    string `json:"code"`    // Машиночитаемый код ошибки (ErrCode*)
+	Message string `json:"message"` // Описание ошибки для человека
+	// Details - дополнительные сведения об ошибке (например, имя неверного заголовка или лимит размера).
+	Details map[string]any `json:"details,omitempty"`
+	// RequestID - идентификатор запроса из журнала сервера, по которому можно найти подробности.
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// Коды ошибок API v2.
+const (
+	ErrCodeInvalidRequest      = "invalid_request"       // Неверный формат запроса или параметров
+	ErrCodeUnauthorized        = "unauthorized"          // Токен отсутствует, неверен или истек
+	ErrCodeInvalidCredentials  = "invalid_credentials"   // Неверное имя пользователя или пароль
+	ErrCodeAccountDisabled     = "account_disabled"      // Учетная запись отключена администратором
+	ErrCodeForbidden           = "forbidden"             // Доступ к ресурсу запрещен
+	ErrCodeUsernameTaken       = "username_taken"        // Имя пользователя уже занято
+	ErrCodeVaultNotFound       = "vault_not_found"       // У пользователя нет хранилища
+	ErrCodeVersionNotFound     = "version_not_found"     // Версия хранилища не найдена
+	ErrCodeVersionConflict     = "version_conflict"      // На сервере более новая или конфликтующая версия
+	ErrCodeNoAccessRequest     = "no_access_request"     // Доверенный контакт не запрашивал экстренный доступ
+	ErrCodeNotFound            = "not_found"             // Прочие ресурсы (пользователь, вебхук, контакт) не найдены
+	ErrCodePayloadTooLarge     = "payload_too_large"     // Размер тела запроса превышает лимит
+	ErrCodeUnsupportedMedia    = "unsupported_media"     // Загружаемый файл не является базой KeePass (KDBX 3.x/4.x)
+	ErrCodeRangeNotSatisfiable = "range_not_satisfiable" // Запрошенный диапазон файла недостижим
+	ErrCodeVaultCorrupted      = "vault_corrupted"       // Файл хранилища на сервере поврежден
+	ErrCodeTimeout             = "timeout"               // Превышено время выполнения операции
+	ErrCodeInternal            = "internal_error"        // Внутренняя ошибка сервера
+)
@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// Статусы экстренного доступа доверенного контакта.
+const (
+	EmergencyAccessIdle      = "idle"      // Контакт назначен, доступ не запрашивался
+	EmergencyAccessRequested = "requested" // Контакт запросил доступ, идет период ожидания
+	EmergencyAccessDenied    = "denied"    // Владелец отклонил последний запрос
+	// EmergencyAccessGranted - период ожидания после запроса истек, контакт может читать хранилище владельца.
+	// В БД не хранится: вычисляется по статусу requested и времени запроса (см. EmergencyAccess.Resolve).
+	EmergencyAccessGranted = "granted"
+)
+
+// EmergencyAccess описывает доверенного контакта, которому владелец хранилища разрешил экстренный доступ.
+// Контакт может запросить доступ; если владелец не отклонит запрос за период ожидания,
+// контакт получает доступ на чтение версий хранилища владельца.
+type EmergencyAccess struct {
+	ID              int64      `db:"id" json:"id"`
+	OwnerID         int64      `db:"owner_id" json:"owner_id"`
+	OwnerUsername   string     `db:"owner_username" json:"owner_username"`
+	ContactID       int64      `db:"contact_id" json:"contact_id"`
+	ContactUsername string     `db:"contact_username" json:"contact_username"`
+	WaitPeriodHours int        `db:"wait_period_hours" json:"wait_period_hours"`
+	Status          string     `db:"status" json:"status"`                       // EmergencyAccess*
+	RequestedAt     *time.Time `db:"requested_at" json:"requested_at,omitempty"` // Время последнего запроса
+	HasKeyEnvelope  bool       `db:"has_key_envelope" json:"has_key_envelope"`   // Владелец оставил ключ
+	AvailableAt     *time.Time `db:"-" json:"access_available_at,omitempty"`     // Когда доступ откроется
+	CreatedAt       time.Time  `db:"created_at" json:"created_at"`               // Время назначения контакта
+	UpdatedAt       time.Time  `db:"updated_at" json:"updated_at"`               // Время изменения статуса
+}
+
+// Resolve заполняет время открытия доступа для запрошенного доступа и меняет статус на granted,
+// если к моменту now период ожидания истек.
+func (a *EmergencyAccess) Resolve(now time.Time) {
+	if a.Status != EmergencyAccessRequested || a.RequestedAt == nil {
+		return
+	}
+	availableAt := a.RequestedAt.Add(time.Duration(a.WaitPeriodHours) * time.Hour)
+	a.AvailableAt = &availableAt
+	if !now.Before(availableAt) {
+		a.Status = EmergencyAccessGranted
+	}
+}
+
+// SetEmergencyContactRequest представляет тело запроса на назначение доверенного контакта.
+type SetEmergencyContactRequest struct {
+	Username        string `json:"username"`          // Имя пользователя контакта
+	WaitPeriodHours int    `json:"wait_period_hours"` // Период ожидания после запроса доступа, часов
+}
+
+// KeyEnvelope - ключ к хранилищу владельца, зашифрованный для доверенного контакта на стороне клиента.
+// Сервер хранит его как есть и отдает контакту только после открытия доступа.
+type KeyEnvelope struct {
+	KeyEnvelope []byte `json:"key_envelope"` // base64 в JSON
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// Статусы проверки состояния сервера и его зависимостей.
+const (
+	HealthStatusOK   = "ok"   // Проверка пройдена
+	HealthStatusFail = "fail" // Проверка не пройдена
+)
+
+// HealthCheck содержит результат проверки одной зависимости сервера.
+type HealthCheck struct {
+	Status string `json:"status"`
+	// Error - причина неуспешной проверки (пусто, если проверка пройдена).
+	Error string `json:"error,omitempty"`
+	// DurationMS - длительность проверки в миллисекундах.
+	DurationMS int64 `json:"duration_ms"`
+}
+
+// HealthReport - ответ эндпоинтов проверки состояния (/healthz, /readyz).
+type HealthReport struct {
+	Status string `json:"status"`
+	// Checks - результаты проверок зависимостей по именам (пусто для /healthz).
+	Checks map[string]HealthCheck `json:"checks,omitempty"`
+	// CheckedAt - время выполнения проверок (результат кэшируется на короткое время).
+	CheckedAt *time.Time `json:"checked_at,omitempty"`
+}
@@ -11,8 +11,18 @@ type User struct {
 	PasswordHash string    `db:"password_hash" json:"-"` // Не отправляем хеш пароля в JSON
 	CreatedAt    time.Time `db:"created_at" json:"created_at"`
 	UpdatedAt    time.Time `db:"updated_at" json:"updated_at"`
+	// DisabledAt - время отключения учетной записи администратором (nil - учетная запись активна).
+	DisabledAt *time.Time `db:"disabled_at" json:"disabled_at,omitempty"`
+	// Role - роль пользователя (RoleUser или RoleAdmin). Передается в JWT, но права проверяются по значению в БД.
+	Role string `db:"role" json:"role"`
 }
 
+// Роли пользователей.
+const (
+	RoleUser  = "user"  // Обычный пользователь
+	RoleAdmin = "admin" // Администратор: доступен API /api/admin
+)
+
 // RegisterRequest представляет тело запроса на регистрацию.
 type RegisterRequest struct {
 	Username string `json:"username"`
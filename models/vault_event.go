@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// Типы событий об изменении хранилища, рассылаемых клиентам.
+const (
+	VaultEventVersionCreated = "version_created" // Загружена новая версия хранилища
+	VaultEventRolledBack     = "rolled_back"     // Хранилище откачено к одной из прежних версий
+	// VaultEventLoginNewDevice - вход с ранее не встречавшегося устройства.
+	// Рассылается только на вебхуки, в поток событий клиентов не попадает.
+	VaultEventLoginNewDevice = "login_new_device"
+	// VaultEventEmergencyAccessRequested - доверенный контакт запросил экстренный доступ (отправляется владельцу).
+	VaultEventEmergencyAccessRequested = "emergency_access_requested"
+	// VaultEventEmergencyAccessDenied - владелец отклонил запрос экстренного доступа (отправляется контакту).
+	VaultEventEmergencyAccessDenied = "emergency_access_denied"
+)
+
+// VaultEvent описывает изменение хранилища пользователя.
+// Передается клиентам через поток Server-Sent Events (GET /api/vault/events) и на вебхуки пользователя.
+type VaultEvent struct {
+	Type      string    `json:"type"`                 // Тип события (VaultEvent*)
+	UserID    int64     `json:"user_id"`              // Владелец хранилища
+	VersionID int64     `json:"version_id,omitempty"` // Версия, ставшая текущей
+	Checksum  *string   `json:"checksum,omitempty"`   // Контрольная сумма (SHA256) текущей версии
+	CreatedAt time.Time `json:"created_at"`           // Время события на сервере
+	Device    *Device   `json:"device,omitempty"`     // Устройство (для VaultEventLoginNewDevice)
+	// EmergencyAccess - доступ доверенного контакта (для VaultEventEmergencyAccess*).
+	EmergencyAccess *EmergencyAccess `json:"emergency_access,omitempty"`
+}
+
+// Device описывает устройство, с которого выполнен запрос.
+type Device struct {
+	// DeviceID - постоянный идентификатор установки клиента (заголовок X-Device-ID), пусто у сторонних клиентов.
+	DeviceID  string `json:"device_id,omitempty"`
+	UserAgent string `json:"user_agent"`
+	IPAddress string `json:"ip_address"`
+}
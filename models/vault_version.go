@@ -1,6 +1,11 @@
 package models
 
-import "time"
+import (
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
 
 // VaultVersion представляет конкретную версию файла хранилища KDBX.
 // Содержит метаданные версии и ключ для доступа к файлу в S3/MinIO.
@@ -14,4 +19,103 @@ type VaultVersion struct {
 	// Время последнего изменения *контента* KDBX (из Root.LastModificationTime)
 	// Передается клиентом при загрузке.
 	ContentModifiedAt *time.Time `db:"content_modified_at" json:"content_modified_at,omitempty"`
+	// ID версии, восстановленной откатом (nil для загруженных клиентом версий).
+	// Версия, созданная откатом, ссылается на объект восстановленной версии.
+	RestoredFromVersionID *int64 `db:"restored_from_version_id" json:"restored_from_version_id,omitempty"`
+	// Результат последней фоновой проверки целостности объекта (NULL - еще не проверялся).
+	LastVerifiedAt     *time.Time `db:"last_verified_at" json:"last_verified_at,omitempty"`
+	VerificationStatus *string    `db:"verification_status" json:"verification_status,omitempty"`
+	// Сведения из заголовка KDBX, определенные сервером при загрузке
+	// (NULL для версий, загруженных до появления проверки формата).
+	KdbxVersion *string `db:"kdbx_version" json:"kdbx_version,omitempty"` // Версия формата, например "4.1"
+	KdbxCipher  *string `db:"kdbx_cipher" json:"kdbx_cipher,omitempty"`   // Алгоритм шифрования, например "AES-256"
+	KdbxKDF     *string `db:"kdbx_kdf" json:"kdbx_kdf,omitempty"`         // Алгоритм формирования ключа (KDF)
+	// Клиент и устройство, с которых загружена версия (см. UploadOrigin; NULL, если неизвестны).
+	ClientName    *string `db:"client_name" json:"client_name,omitempty"`
+	ClientVersion *string `db:"client_version" json:"client_version,omitempty"`
+	DeviceID      *string `db:"device_id" json:"device_id,omitempty"`
+	UploaderIP    *string `db:"uploader_ip" json:"uploader_ip,omitempty"`
+}
+
+// Заголовки запроса со сведениями о клиенте. Клиент передает их с каждым запросом,
+// а сервер сохраняет вместе с загруженной версией (в gRPC - одноименные метаданные).
+const (
+	HeaderClientName    = "X-Client-Name"    // Имя клиентского приложения, например "gophkeeper-client"
+	HeaderClientVersion = "X-Client-Version" // Версия (сборка) клиентского приложения
+	HeaderDeviceID      = "X-Device-ID"      // Постоянный идентификатор установки клиента на устройстве
+)
+
+// UploadOrigin - сведения о клиенте и устройстве, с которых загружается версия хранилища.
+// Пустые поля не сохраняются.
+type UploadOrigin struct {
+	ClientName    string
+	ClientVersion string
+	DeviceID      string
+	IPAddress     string
+}
+
+// Ограничения длины полей UploadOrigin (совпадают с размерами столбцов vault_versions).
+const (
+	MaxOriginFieldLength = 128
+	MaxIPAddressLength   = 45
+)
+
+// Normalize убирает пробелы по краям полей и обрезает их до допустимой длины:
+// заголовки передает клиент, поэтому их значения не проверяются иначе.
+func (o UploadOrigin) Normalize() UploadOrigin {
+	return UploadOrigin{
+		ClientName:    truncateRunes(o.ClientName, MaxOriginFieldLength),
+		ClientVersion: truncateRunes(o.ClientVersion, MaxOriginFieldLength),
+		DeviceID:      truncateRunes(o.DeviceID, MaxOriginFieldLength),
+		IPAddress:     truncateRunes(o.IPAddress, MaxIPAddressLength),
+	}
+}
+
+// truncateRunes убирает управляющие символы и пробелы по краям s и оставляет не больше limit символов.
+// Некорректные последовательности UTF-8 заменяются, так как PostgreSQL их не принимает.
+func truncateRunes(s string, limit int) string {
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, strings.ToValidUTF8(s, "\uFFFD"))
+	s = strings.TrimSpace(s)
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+	return string([]rune(s)[:limit])
+}
+
+// UploadResponse - ответ API v2 на загрузку файла хранилища.
+type UploadResponse struct {
+	// Version - метаданные созданной версии (или текущей, если загружена идентичная версия).
+	Version VaultVersion `json:"version"`
+}
+
+// Статусы проверки целостности объекта версии.
+const (
+	VerificationStatusOK        = "ok"        // Контрольная сумма совпала
+	VerificationStatusCorrupted = "corrupted" // Контрольная сумма не совпала или объект не расшифровывается
+	VerificationStatusMissing   = "missing"   // Объект отсутствует в хранилище файлов
+	VerificationStatusError     = "error"     // Проверку не удалось выполнить (например, хранилище недоступно)
+)
+
+// StorageScrubReport содержит итоги прохода фоновой проверки целостности.
+type StorageScrubReport struct {
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Checked    int       `json:"checked"`
+	OK         int       `json:"ok"`
+	Corrupted  int       `json:"corrupted"`
+	Missing    int       `json:"missing"`
+	Errors     int       `json:"errors"`
+}
+
+// StorageIntegrityResponse - ответ административного эндпоинта проверки целостности.
+type StorageIntegrityResponse struct {
+	// LastScrub - итоги последнего прохода проверки (nil, если проверка еще не выполнялась).
+	LastScrub *StorageScrubReport `json:"last_scrub,omitempty"`
+	// Problems - версии с поврежденными, отсутствующими или непроверенными из-за ошибки объектами.
+	Problems []VaultVersion `json:"problems"`
 }
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Статусы доставки вебхука.
+const (
+	WebhookDeliveryPending   = "pending"   // Ожидает отправки (в том числе повторной)
+	WebhookDeliveryDelivered = "delivered" // Получатель ответил кодом 2xx
+	WebhookDeliveryFailed    = "failed"    // Исчерпаны попытки доставки
+)
+
+// Webhook описывает зарегистрированный пользователем адрес для исходящих уведомлений.
+type Webhook struct {
+	ID        int64     `db:"id" json:"id"`
+	UserID    int64     `db:"user_id" json:"user_id"`
+	URL       string    `db:"url" json:"url"`
+	Secret    string    `db:"secret" json:"secret,omitempty"` // Отдается клиенту только при создании
+	IsActive  bool      `db:"is_active" json:"is_active"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// CreateWebhookRequest представляет тело запроса на регистрацию вебхука.
+type CreateWebhookRequest struct {
+	URL    string `json:"url"`
+	Secret string `json:"secret,omitempty"` // Если не указан, сервер сгенерирует секрет сам
+}
+
+// WebhookDelivery описывает одну доставку события на вебхук (запись исходящей очереди).
+type WebhookDelivery struct {
+	ID             int64           `db:"id" json:"id"`
+	WebhookID      int64           `db:"webhook_id" json:"webhook_id"`
+	EventType      string          `db:"event_type" json:"event_type"`
+	Payload        json.RawMessage `db:"payload" json:"payload"`
+	Status         string          `db:"status" json:"status"`
+	Attempts       int             `db:"attempts" json:"attempts"`
+	NextAttemptAt  time.Time       `db:"next_attempt_at" json:"next_attempt_at"`
+	LastAttemptAt  *time.Time      `db:"last_attempt_at" json:"last_attempt_at,omitempty"`
+	ResponseStatus *int            `db:"response_status" json:"response_status,omitempty"`
+	LastError      *string         `db:"last_error" json:"last_error,omitempty"`
+	CreatedAt      time.Time       `db:"created_at" json:"created_at"`
+	DeliveredAt    *time.Time      `db:"delivered_at" json:"delivered_at,omitempty"`
+
+	// Адрес и секрет вебхука, заполняются только при выборке доставок для отправки.
+	URL    string `db:"url" json:"-"`
+	Secret string `db:"secret" json:"-"`
+}
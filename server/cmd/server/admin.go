@@ -0,0 +1,475 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/maynagashev/gophkeeper/models"
+	"github.com/maynagashev/gophkeeper/server/internal/repository"
+	"github.com/maynagashev/gophkeeper/server/internal/services"
+)
+
+const (
+	defaultAdminListLimit  = 50            // Количество пользователей в выводе "admin users" по умолчанию
+	defaultAdminKeep       = 10            // Количество сохраняемых версий в "admin prune" по умолчанию
+	generatedPasswordBytes = 18            // Длина случайного пароля "admin reset-password" до кодирования
+	adminTimeFormat        = time.DateTime // Формат времени в выводе административных команд
+	adminMissingValue      = "-"           // Выводится вместо отсутствующего значения
+)
+
+// adminAction выполняет административную команду с уже подключенным сервисом.
+type adminAction func(ctx context.Context, svc services.UserAdminService, out io.Writer) error
+
+// adminCommand описывает подкоманду "admin".
+// setup регистрирует флаги команды и возвращает функцию, которая после разбора флагов проверяет
+// позиционные аргументы и готовит действие. Так ошибки в аргументах выявляются до подключения к БД.
+type adminCommand struct {
+	name        string
+	args        string
+	description string
+	setup       func(fs *flag.FlagSet, in io.Reader) func(args []string) (adminAction, error)
+}
+
+// Функция подключения сервиса администрирования (переменная для мокирования в тестах).
+// Возвращает также функцию освобождения ресурсов.
+var newAdminService = //nolint:gochecknoglobals // Используется для мокирования в тестах
+func(cfg *config) (services.UserAdminService, func(), error) {
+	db, err := newPostgresDB(cfg.Database.DSN, cfg.poolConfig())
+	if err != nil {
+		return nil, nil, fmt.Errorf("ошибка инициализации БД: %w", err)
+	}
+	closeDB := func() {
+		if closeErr := db.Close(); closeErr != nil {
+			log.Printf("Ошибка закрытия соединения с БД: %v", closeErr)
+		}
+	}
+	fileStorage, _, err := newFileStorage(cfg)
+	if err != nil {
+		closeDB()
+		return nil, nil, err
+	}
+
+	vaultVersionRepo := repository.NewPostgresVaultVersionRepository(db)
+	svc := services.NewUserAdminService(
+		repository.NewPostgresUserRepository(db),
+		repository.NewPostgresVaultRepository(db),
+		vaultVersionRepo,
+		repository.NewTransactor(db),
+		fileStorage,
+		services.NewIntegrityService(vaultVersionRepo, fileStorage, cfg.Storage.ScrubInterval),
+	)
+	return svc, closeDB, nil
+}
+
+// adminCommands возвращает подкоманды "admin" в порядке вывода в справке.
+func adminCommands() []adminCommand {
+	return []adminCommand{
+		{
+			name: "users", args: "[-search <строка>] [-limit N] [-offset N]",
+			description: "список пользователей с количеством версий и объемом хранилища",
+			setup:       setupAdminUsers,
+		},
+		{
+			name: "disable", args: "<пользователь>",
+			description: "запретить вход и отклонять запросы с уже выданными токенами",
+			setup:       setupAdminSetDisabled(true),
+		},
+		{
+			name: "enable", args: "<пользователь>",
+			description: "разрешить вход отключенному пользователю",
+			setup:       setupAdminSetDisabled(false),
+		},
+		{
+			name: "grant-admin", args: "<пользователь>",
+			description: "назначить роль администратора",
+			setup:       setupAdminSetRole(models.RoleAdmin),
+		},
+		{
+			name: "revoke-admin", args: "<пользователь>",
+			description: "снять роль администратора",
+			setup:       setupAdminSetRole(models.RoleUser),
+		},
+		{
+			name: "reset-password", args: "[-password-stdin] <пользователь>",
+			description: "задать новый пароль (без -password-stdin генерируется и выводится случайный)",
+			setup:       setupAdminResetPassword,
+		},
+		{
+			name: "versions", args: "<пользователь>",
+			description: "версии хранилища с размерами и результатами проверки целостности",
+			setup:       setupAdminVersions,
+		},
+		{
+			name: "prune", args: "[-keep N] -yes <пользователь>",
+			description: "удалить все версии, кроме N последних и текущей, и объекты без ссылок",
+			setup:       setupAdminPrune,
+		},
+		{
+			name: "verify", args: "<пользователь>",
+			description: "проверить контрольные суммы объектов всех версий пользователя",
+			setup:       setupAdminVerify,
+		},
+		{
+			name: "delete-user", args: "-yes <пользователь>",
+			description: "удалить пользователя со всеми версиями и объектами",
+			setup:       setupAdminDeleteUser,
+		},
+	}
+}
+
+// runAdminCommand выполняет подкоманду "admin <команда>".
+// Флаги сервера (-config, -database-dsn, -minio-* и т.д.) указываются до имени команды,
+// флаги команды - после него. Пароль для reset-password -password-stdin читается из in.
+func runAdminCommand(args []string, in io.Reader, out io.Writer) error {
+	commands := adminCommands()
+	fs := flag.NewFlagSet("admin", flag.ContinueOnError)
+	fs.SetOutput(out)
+	fs.Usage = func() {
+		fmt.Fprintln(out, "Использование: gophkeeper-server admin [-config <файл>] [флаги сервера] <команда> [аргументы]")
+		fmt.Fprintln(out, "Команды:")
+		tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		for _, c := range commands {
+			fmt.Fprintf(tw, "  %s %s\t%s\n", c.name, c.args, c.description)
+		}
+		_ = tw.Flush()
+	}
+	cfg, err := readConfig(fs, args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("укажите команду")
+	}
+
+	name := fs.Arg(0)
+	var command *adminCommand
+	for i := range commands {
+		if commands[i].name == name {
+			command = &commands[i]
+		}
+	}
+	if command == nil {
+		fs.Usage()
+		return fmt.Errorf("неизвестная команда '%s'", name)
+	}
+
+	cmdFlags := flag.NewFlagSet("admin "+name, flag.ContinueOnError)
+	cmdFlags.SetOutput(out)
+	cmdFlags.Usage = func() {
+		fmt.Fprintf(out, "Использование: gophkeeper-server admin %s %s\n", name, command.args)
+		cmdFlags.PrintDefaults()
+	}
+	prepare := command.setup(cmdFlags, in)
+	if err = cmdFlags.Parse(fs.Args()[1:]); err != nil {
+		return err
+	}
+	action, err := prepare(cmdFlags.Args())
+	if err != nil {
+		cmdFlags.Usage()
+		return err
+	}
+
+	if cfg.Database.DSN == "" {
+		return errors.New("не указана строка подключения к БД (--database-dsn или " + envDatabaseDSN + ")")
+	}
+	svc, cleanup, err := newAdminService(cfg)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return action(ctx, svc, out)
+}
+
+// setupAdminUsers готовит команду "users".
+func setupAdminUsers(fs *flag.FlagSet, _ io.Reader) func([]string) (adminAction, error) {
+	search := fs.String("search", "", "Подстрока имени пользователя (без учета регистра)")
+	limit := fs.Int("limit", defaultAdminListLimit, "Максимальное количество пользователей")
+	offset := fs.Int("offset", 0, "Количество пропускаемых пользователей")
+	return func(args []string) (adminAction, error) {
+		if len(args) != 0 {
+			return nil, errors.New("команда не принимает аргументов")
+		}
+		if *limit < 1 || *offset < 0 {
+			return nil, errors.New("-limit должен быть больше 0, -offset - не меньше 0")
+		}
+		return func(ctx context.Context, svc services.UserAdminService, out io.Writer) error {
+			users, err := svc.ListUsers(ctx, *search, *limit, *offset)
+			if err != nil {
+				return err
+			}
+			printUsers(out, users)
+			return nil
+		}, nil
+	}
+}
+
+// setupAdminSetDisabled готовит команды "disable" и "enable".
+func setupAdminSetDisabled(disabled bool) func(*flag.FlagSet, io.Reader) func([]string) (adminAction, error) {
+	return func(_ *flag.FlagSet, _ io.Reader) func([]string) (adminAction, error) {
+		return func(args []string) (adminAction, error) {
+			username, err := usernameArg(args)
+			if err != nil {
+				return nil, err
+			}
+			return func(ctx context.Context, svc services.UserAdminService, out io.Writer) error {
+				if err = svc.SetUserDisabled(ctx, username, disabled); err != nil {
+					return err
+				}
+				if disabled {
+					fmt.Fprintf(out, "Учетная запись '%s' отключена.\n", username)
+				} else {
+					fmt.Fprintf(out, "Учетная запись '%s' включена.\n", username)
+				}
+				return nil
+			}, nil
+		}
+	}
+}
+
+// setupAdminSetRole готовит команды "grant-admin" и "revoke-admin".
+func setupAdminSetRole(role string) func(*flag.FlagSet, io.Reader) func([]string) (adminAction, error) {
+	return func(_ *flag.FlagSet, _ io.Reader) func([]string) (adminAction, error) {
+		return func(args []string) (adminAction, error) {
+			username, err := usernameArg(args)
+			if err != nil {
+				return nil, err
+			}
+			return func(ctx context.Context, svc services.UserAdminService, out io.Writer) error {
+				if err = svc.SetUserRole(ctx, username, role); err != nil {
+					return err
+				}
+				fmt.Fprintf(out, "Роль пользователя '%s': %s\n", username, role)
+				return nil
+			}, nil
+		}
+	}
+}
+
+// setupAdminResetPassword готовит команду "reset-password".
+func setupAdminResetPassword(fs *flag.FlagSet, in io.Reader) func([]string) (adminAction, error) {
+	fromStdin := fs.Bool("password-stdin", false, "Прочитать новый пароль из первой строки stdin")
+	return func(args []string) (adminAction, error) {
+		username, err := usernameArg(args)
+		if err != nil {
+			return nil, err
+		}
+		password, generated := "", false
+		if *fromStdin {
+			if password, err = readPassword(in); err != nil {
+				return nil, err
+			}
+		} else {
+			if password, err = generatePassword(); err != nil {
+				return nil, err
+			}
+			generated = true
+		}
+		return func(ctx context.Context, svc services.UserAdminService, out io.Writer) error {
+			if err = svc.ResetPassword(ctx, username, password); err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "Пароль пользователя '%s' изменен.\n", username)
+			if generated {
+				fmt.Fprintf(out, "Новый пароль: %s\n", password)
+			}
+			return nil
+		}, nil
+	}
+}
+
+// setupAdminVersions готовит команду "versions".
+func setupAdminVersions(_ *flag.FlagSet, _ io.Reader) func([]string) (adminAction, error) {
+	return func(args []string) (adminAction, error) {
+		username, err := usernameArg(args)
+		if err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context, svc services.UserAdminService, out io.Writer) error {
+			userVersions, listErr := svc.ListVersions(ctx, username)
+			if listErr != nil {
+				return listErr
+			}
+			printVersions(out, userVersions)
+			return nil
+		}, nil
+	}
+}
+
+// setupAdminPrune готовит команду "prune".
+func setupAdminPrune(fs *flag.FlagSet, _ io.Reader) func([]string) (adminAction, error) {
+	keep := fs.Int("keep", defaultAdminKeep, "Количество сохраняемых последних версий (текущая сохраняется всегда)")
+	confirmed := fs.Bool("yes", false, "Подтвердить удаление версий")
+	return func(args []string) (adminAction, error) {
+		username, err := usernameArg(args)
+		if err != nil {
+			return nil, err
+		}
+		if *keep < 1 {
+			return nil, services.ErrInvalidKeepCount
+		}
+		if !*confirmed {
+			return nil, errors.New("удаление версий необратимо, подтвердите его флагом -yes")
+		}
+		return func(ctx context.Context, svc services.UserAdminService, out io.Writer) error {
+			report, pruneErr := svc.PruneVersions(ctx, username, *keep)
+			if pruneErr != nil {
+				return pruneErr
+			}
+			fmt.Fprintf(out, "Оставлено версий: %d, удалено версий: %d\n", report.KeptVersions, report.DeletedVersions)
+			fmt.Fprintf(out, "Удалено объектов: %d (%d байт)\n", report.DeletedObjects, report.FreedBytes)
+			return failedObjectsError(out, report.FailedObjects)
+		}, nil
+	}
+}
+
+// setupAdminVerify готовит команду "verify".
+func setupAdminVerify(_ *flag.FlagSet, _ io.Reader) func([]string) (adminAction, error) {
+	return func(args []string) (adminAction, error) {
+		username, err := usernameArg(args)
+		if err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context, svc services.UserAdminService, out io.Writer) error {
+			result, verifyErr := svc.VerifyStorage(ctx, username)
+			if verifyErr != nil {
+				return verifyErr
+			}
+			report := result.Report
+			fmt.Fprintf(out, "Проверено версий: %d, в порядке: %d, повреждено: %d, отсутствует: %d, ошибок: %d\n",
+				report.Checked, report.OK, report.Corrupted, report.Missing, report.Errors)
+			if len(result.Problems) == 0 {
+				return nil
+			}
+			printVersions(out, &models.UserVersions{Versions: result.Problems})
+			return fmt.Errorf("обнаружены проблемы целостности у %d версий", len(result.Problems))
+		}, nil
+	}
+}
+
+// setupAdminDeleteUser готовит команду "delete-user".
+func setupAdminDeleteUser(fs *flag.FlagSet, _ io.Reader) func([]string) (adminAction, error) {
+	confirmed := fs.Bool("yes", false, "Подтвердить удаление пользователя")
+	return func(args []string) (adminAction, error) {
+		username, err := usernameArg(args)
+		if err != nil {
+			return nil, err
+		}
+		if !*confirmed {
+			return nil, errors.New("удаление пользователя необратимо, подтвердите его флагом -yes")
+		}
+		return func(ctx context.Context, svc services.UserAdminService, out io.Writer) error {
+			report, deleteErr := svc.DeleteUser(ctx, username)
+			if deleteErr != nil {
+				return deleteErr
+			}
+			fmt.Fprintf(out, "Пользователь '%s' удален, удалено объектов: %d\n", username, report.DeletedObjects)
+			return failedObjectsError(out, report.FailedObjects)
+		}, nil
+	}
+}
+
+// usernameArg возвращает имя пользователя - единственный позиционный аргумент команды.
+func usernameArg(args []string) (string, error) {
+	if len(args) != 1 || args[0] == "" {
+		return "", errors.New("укажите одно имя пользователя")
+	}
+	return args[0], nil
+}
+
+// readPassword читает пароль из первой строки in.
+func readPassword(in io.Reader) (string, error) {
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("ошибка чтения пароля: %w", err)
+	}
+	password := strings.TrimRight(line, "\r\n")
+	if password == "" {
+		return "", services.ErrEmptyPassword
+	}
+	return password, nil
+}
+
+// generatePassword создает случайный пароль.
+func generatePassword() (string, error) {
+	buf := make([]byte, generatedPasswordBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("ошибка генерации пароля: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// failedObjectsError выводит объекты, которые не удалось удалить, и возвращает ошибку, если они есть.
+// Записи о них в БД уже удалены, поэтому объекты нужно удалить из хранилища вручную.
+func failedObjectsError(out io.Writer, failed []string) error {
+	if len(failed) == 0 {
+		return nil
+	}
+	fmt.Fprintln(out, "Не удалось удалить объекты (удалите их из хранилища вручную):")
+	for _, objectKey := range failed {
+		fmt.Fprintf(out, "  %s\n", objectKey)
+	}
+	return fmt.Errorf("не удалось удалить объектов: %d", len(failed))
+}
+
+// printUsers выводит таблицу пользователей.
+func printUsers(out io.Writer, users []models.UserSummary) {
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tПОЛЬЗОВАТЕЛЬ\tРОЛЬ\tСТАТУС\tВЕРСИЙ\tРАЗМЕР\tПОСЛЕДНЯЯ ЗАГРУЗКА\tСОЗДАН")
+	for _, u := range users {
+		status := "активен"
+		if u.DisabledAt != nil {
+			status = "отключен с " + u.DisabledAt.Format(adminTimeFormat)
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%d\t%d\t%s\t%s\n", u.ID, u.Username, u.Role, status, u.VersionCount,
+			u.StorageBytes, formatAdminTime(u.LastUploadAt), u.CreatedAt.Format(adminTimeFormat))
+	}
+	_ = tw.Flush()
+}
+
+// printVersions выводит таблицу версий; текущая версия отмечается звездочкой.
+func printVersions(out io.Writer, userVersions *models.UserVersions) {
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "\tID\tСОЗДАНА\tРАЗМЕР\tПРОВЕРКА\tОБЪЕКТ")
+	for _, v := range userVersions.Versions {
+		marker := ""
+		if userVersions.CurrentVersionID != nil && *userVersions.CurrentVersionID == v.ID {
+			marker = "*"
+		}
+		size := adminMissingValue
+		if v.SizeBytes != nil {
+			size = fmt.Sprint(*v.SizeBytes)
+		}
+		verification := adminMissingValue
+		if v.VerificationStatus != nil {
+			verification = *v.VerificationStatus + " " + formatAdminTime(v.LastVerifiedAt)
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\t%s\n", marker, v.ID, v.CreatedAt.Format(adminTimeFormat), size,
+			verification, v.ObjectKey)
+	}
+	_ = tw.Flush()
+}
+
+// formatAdminTime форматирует необязательное время для вывода.
+func formatAdminTime(t *time.Time) string {
+	if t == nil {
+		return adminMissingValue
+	}
+	return t.Format(adminTimeFormat)
+}
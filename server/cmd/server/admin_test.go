@@ -0,0 +1,230 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maynagashev/gophkeeper/models"
+	"github.com/maynagashev/gophkeeper/server/internal/mocks"
+	"github.com/maynagashev/gophkeeper/server/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// stubAdminService подменяет newAdminService моком на время теста.
+// Возвращает мок и признак того, что ресурсы сервиса были освобождены.
+func stubAdminService(t *testing.T) (*mocks.UserAdminService, *bool) {
+	t.Helper()
+	original := newAdminService
+	t.Cleanup(func() { newAdminService = original })
+	svc := mocks.NewUserAdminService(t)
+	closed := false
+	newAdminService = func(cfg *config) (services.UserAdminService, func(), error) {
+		assert.Equal(t, "postgres://db", cfg.Database.DSN)
+		return svc, func() { closed = true }, nil
+	}
+	return svc, &closed
+}
+
+// runAdmin выполняет команду admin с заданной строкой подключения и возвращает вывод.
+func runAdmin(in string, args ...string) (string, error) {
+	var out bytes.Buffer
+	err := runAdminCommand(append([]string{"-database-dsn=postgres://db"}, args...), strings.NewReader(in), &out)
+	return out.String(), err
+}
+
+func TestRunAdminCommand_Users(t *testing.T) {
+	svc, closed := stubAdminService(t)
+	disabledAt := time.Date(2025, 5, 1, 10, 0, 0, 0, time.UTC)
+	svc.EXPECT().ListUsers(mock.Anything, "ali", 10, 20).Return([]models.UserSummary{
+		{ID: 1, Username: "alice", Role: models.RoleAdmin, VersionCount: 3, StorageBytes: 4096},
+		{ID: 2, Username: "alina", DisabledAt: &disabledAt},
+	}, nil).Once()
+
+	out, err := runAdmin("", "users", "-search=ali", "-limit=10", "-offset=20")
+
+	require.NoError(t, err)
+	assert.True(t, *closed)
+	assert.Contains(t, out, "alice")
+	assert.Contains(t, out, "4096")
+	assert.Contains(t, out, "admin")
+	assert.Contains(t, out, "отключен с 2025-05-01 10:00:00")
+}
+
+func TestRunAdminCommand_SetDisabled(t *testing.T) {
+	svc, _ := stubAdminService(t)
+	svc.EXPECT().SetUserDisabled(mock.Anything, "alice", true).Return(nil).Once()
+	svc.EXPECT().SetUserDisabled(mock.Anything, "bob", false).Return(services.ErrUserNotFound).Once()
+
+	out, err := runAdmin("", "disable", "alice")
+	require.NoError(t, err)
+	assert.Contains(t, out, "Учетная запись 'alice' отключена")
+
+	_, err = runAdmin("", "enable", "bob")
+	require.ErrorIs(t, err, services.ErrUserNotFound)
+}
+
+func TestRunAdminCommand_SetRole(t *testing.T) {
+	svc, _ := stubAdminService(t)
+	svc.EXPECT().SetUserRole(mock.Anything, "alice", models.RoleAdmin).Return(nil).Once()
+	svc.EXPECT().SetUserRole(mock.Anything, "alice", models.RoleUser).Return(nil).Once()
+
+	out, err := runAdmin("", "grant-admin", "alice")
+	require.NoError(t, err)
+	assert.Contains(t, out, "Роль пользователя 'alice': admin")
+
+	out, err = runAdmin("", "revoke-admin", "alice")
+	require.NoError(t, err)
+	assert.Contains(t, out, "Роль пользователя 'alice': user")
+}
+
+func TestRunAdminCommand_ResetPassword(t *testing.T) {
+	t.Run("Пароль из stdin", func(t *testing.T) {
+		svc, _ := stubAdminService(t)
+		svc.EXPECT().ResetPassword(mock.Anything, "alice", "s3cret pass").Return(nil).Once()
+
+		out, err := runAdmin("s3cret pass\n", "reset-password", "-password-stdin", "alice")
+
+		require.NoError(t, err)
+		assert.NotContains(t, out, "s3cret")
+	})
+
+	t.Run("Случайный пароль выводится", func(t *testing.T) {
+		svc, _ := stubAdminService(t)
+		var generated string
+		svc.EXPECT().ResetPassword(mock.Anything, "alice", mock.Anything).
+			Run(func(_ context.Context, _ string, password string) { generated = password }).
+			Return(nil).Once()
+
+		out, err := runAdmin("", "reset-password", "alice")
+
+		require.NoError(t, err)
+		assert.Len(t, generated, 24)
+		assert.Contains(t, out, "Новый пароль: "+generated)
+	})
+
+	t.Run("Пустой stdin", func(t *testing.T) {
+		stubAdminService(t)
+
+		_, err := runAdmin("", "reset-password", "-password-stdin", "alice")
+
+		require.ErrorIs(t, err, services.ErrEmptyPassword)
+	})
+}
+
+func TestRunAdminCommand_Versions(t *testing.T) {
+	svc, _ := stubAdminService(t)
+	size := int64(2048)
+	status := models.VerificationStatusOK
+	current := int64(5)
+	svc.EXPECT().ListVersions(mock.Anything, "alice").Return(&models.UserVersions{
+		CurrentVersionID: &current,
+		Versions: []models.VaultVersion{
+			{ID: 5, ObjectKey: "user_1/v5.kdbx", SizeBytes: &size, VerificationStatus: &status,
+				LastVerifiedAt: &time.Time{}},
+			{ID: 4, ObjectKey: "user_1/v4.kdbx"},
+		},
+	}, nil).Once()
+
+	out, err := runAdmin("", "versions", "alice")
+
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 3)
+	assert.True(t, strings.HasPrefix(lines[1], "*"), "текущая версия должна быть отмечена")
+	assert.Contains(t, lines[1], "2048")
+	assert.Contains(t, lines[1], "ok")
+	assert.Contains(t, lines[2], "user_1/v4.kdbx")
+}
+
+func TestRunAdminCommand_Prune(t *testing.T) {
+	t.Run("Требуется подтверждение", func(t *testing.T) {
+		stubAdminService(t)
+
+		_, err := runAdmin("", "prune", "-keep=3", "alice")
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "-yes")
+	})
+
+	t.Run("Неудаленные объекты - ошибка", func(t *testing.T) {
+		svc, _ := stubAdminService(t)
+		svc.EXPECT().PruneVersions(mock.Anything, "alice", 3).Return(&models.PruneReport{
+			KeptVersions: 3, DeletedVersions: 2, DeletedObjects: 1, FreedBytes: 100,
+			FailedObjects: []string{"user_1/v1.kdbx"},
+		}, nil).Once()
+
+		out, err := runAdmin("", "prune", "-keep=3", "-yes", "alice")
+
+		require.Error(t, err)
+		assert.Contains(t, out, "удалено версий: 2")
+		assert.Contains(t, out, "user_1/v1.kdbx")
+	})
+}
+
+func TestRunAdminCommand_Verify(t *testing.T) {
+	svc, _ := stubAdminService(t)
+	status := models.VerificationStatusMissing
+	svc.EXPECT().VerifyStorage(mock.Anything, "alice").Return(&models.UserStorageReport{
+		Report:   models.StorageScrubReport{Checked: 2, OK: 1, Missing: 1},
+		Problems: []models.VaultVersion{{ID: 4, ObjectKey: "user_1/v4.kdbx", VerificationStatus: &status}},
+	}, nil).Once()
+
+	out, err := runAdmin("", "verify", "alice")
+
+	require.Error(t, err)
+	assert.Contains(t, out, "Проверено версий: 2")
+	assert.Contains(t, out, "user_1/v4.kdbx")
+}
+
+func TestRunAdminCommand_DeleteUser(t *testing.T) {
+	svc, _ := stubAdminService(t)
+	svc.EXPECT().DeleteUser(mock.Anything, "alice").Return(&models.UserDeleteReport{DeletedObjects: 2}, nil).Once()
+
+	out, err := runAdmin("", "delete-user", "-yes", "alice")
+
+	require.NoError(t, err)
+	assert.Contains(t, out, "удалено объектов: 2")
+}
+
+func TestRunAdminCommand_InvalidArgs(t *testing.T) {
+	// Сервис не подключается: ошибки аргументов выявляются до подключения к БД
+	original := newAdminService
+	t.Cleanup(func() { newAdminService = original })
+	newAdminService = func(*config) (services.UserAdminService, func(), error) {
+		return nil, nil, errors.New("не должно вызываться")
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{name: "Нет команды", args: nil, wantErr: "укажите команду"},
+		{name: "Неизвестная команда", args: []string{"drop"}, wantErr: "неизвестная команда 'drop'"},
+		{name: "Нет имени пользователя", args: []string{"disable"}, wantErr: "укажите одно имя пользователя"},
+		{name: "Лишние аргументы", args: []string{"users", "alice"}, wantErr: "не принимает аргументов"},
+		{name: "Неверное количество версий", args: []string{"prune", "-keep=0", "-yes", "alice"}, wantErr: "не меньше 1"},
+		{name: "Удаление без подтверждения", args: []string{"delete-user", "alice"}, wantErr: "-yes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := runAdmin("", tt.args...)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+
+	t.Run("Не указана строка подключения", func(t *testing.T) {
+		t.Setenv(envDatabaseDSN, "")
+		var out bytes.Buffer
+		err := runAdminCommand([]string{"users"}, strings.NewReader(""), &out)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "не указана строка подключения к БД")
+	})
+}
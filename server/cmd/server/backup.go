@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/maynagashev/gophkeeper/server/internal/backup"
+	"github.com/maynagashev/gophkeeper/server/internal/repository"
+	"github.com/maynagashev/gophkeeper/server/internal/storage"
+)
+
+// Функция подключения к БД и файловому хранилищу для резервного копирования
+// (переменная для мокирования в тестах). Возвращаемая функция закрывает соединение с БД.
+var newBackupDeps = //nolint:gochecknoglobals // Используется для мокирования в тестах
+func(cfg *config) (repository.BackupRepository, storage.FileStorage, func(), error) {
+	db, err := newPostgresDB(cfg.Database.DSN, cfg.poolConfig())
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("ошибка инициализации БД: %w", err)
+	}
+	closeDB := func() {
+		if closeErr := db.Close(); closeErr != nil {
+			log.Printf("Ошибка закрытия соединения с БД: %v", closeErr)
+		}
+	}
+	fileStorage, _, err := newFileStorage(cfg)
+	if err != nil {
+		closeDB()
+		return nil, nil, nil, err
+	}
+	return repository.NewPostgresBackupRepository(db), fileStorage, closeDB, nil
+}
+
+// runBackupCommand выполняет подкоманду "backup": выгружает таблицы сервера и все объекты,
+// на которые они ссылаются, в один архив. Архив записывается во временный файл рядом с -out
+// и переименовывается только после успешного завершения.
+func runBackupCommand(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("backup", flag.ContinueOnError)
+	fs.SetOutput(out)
+	output := fs.String("out", "", "Файл архива резервной копии (tar.gz)")
+	fs.Usage = func() {
+		fmt.Fprintln(out, "Использование: gophkeeper-server backup [флаги сервера] -out <файл>")
+		fs.PrintDefaults()
+	}
+	cfg, err := readConfig(fs, args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() != 0 || *output == "" {
+		fs.Usage()
+		return errors.New("укажите файл архива: -out <файл>")
+	}
+	if cfg.Database.DSN == "" {
+		return errors.New("не указана строка подключения к БД (--database-dsn или " + envDatabaseDSN + ")")
+	}
+
+	repo, files, cleanup, err := newBackupDeps(cfg)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	manifest, err := writeBackup(*output, func(w io.Writer) (*backup.Manifest, error) {
+		return backup.Create(ctx, repo, files, w)
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Резервная копия записана в %s\n", *output)
+	printManifest(out, manifest)
+	return nil
+}
+
+// writeBackup записывает архив во временный файл и атомарно переименовывает его в path.
+// При ошибке временный файл удаляется, а существующий файл path не изменяется.
+func writeBackup(path string, create func(w io.Writer) (*backup.Manifest, error)) (*backup.Manifest, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания файла архива: %w", err)
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	buffered := bufio.NewWriter(tmp)
+	manifest, err := create(buffered)
+	if err != nil {
+		return nil, err
+	}
+	if err = buffered.Flush(); err != nil {
+		return nil, fmt.Errorf("ошибка записи файла архива: %w", err)
+	}
+	if err = tmp.Sync(); err != nil {
+		return nil, fmt.Errorf("ошибка записи файла архива: %w", err)
+	}
+	if err = tmp.Close(); err != nil {
+		return nil, fmt.Errorf("ошибка записи файла архива: %w", err)
+	}
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return nil, fmt.Errorf("ошибка записи файла архива: %w", err)
+	}
+	committed = true
+	return manifest, nil
+}
+
+// runRestoreCommand выполняет подкоманду "restore": проверяет архив и загружает его в пустую БД
+// и файловое хранилище. Схема БД сначала доводится до версии резервной копии, после восстановления -
+// до последней версии сервера. С -verify-only архив только проверяется, подключение к БД не нужно.
+func runRestoreCommand(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("restore", flag.ContinueOnError)
+	fs.SetOutput(out)
+	input := fs.String("in", "", "Файл архива резервной копии (tar.gz)")
+	verifyOnly := fs.Bool("verify-only", false, "Только проверить архив, не восстанавливая его")
+	fs.Usage = func() {
+		fmt.Fprintln(out, "Использование: gophkeeper-server restore [флаги сервера] -in <файл> [-verify-only]")
+		fs.PrintDefaults()
+	}
+	cfg, err := readConfig(fs, args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() != 0 || *input == "" {
+		fs.Usage()
+		return errors.New("укажите файл архива: -in <файл>")
+	}
+
+	file, err := os.Open(*input)
+	if err != nil {
+		return fmt.Errorf("ошибка открытия архива: %w", err)
+	}
+	defer file.Close()
+
+	manifest, err := backup.Verify(bufio.NewReader(file))
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Архив %s проверен\n", *input)
+	printManifest(out, manifest)
+	if *verifyOnly {
+		return nil
+	}
+
+	if cfg.Database.DSN == "" {
+		return errors.New("не указана строка подключения к БД (--database-dsn или " + envDatabaseDSN + ")")
+	}
+	m, err := newMigrator(cfg.Database.DSN)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := m.Close(); closeErr != nil {
+			log.Printf("Ошибка закрытия соединения мигратора: %v", closeErr)
+		}
+	}()
+	if err = m.MigrateTo(manifest.SchemaVersion); err != nil {
+		return fmt.Errorf("ошибка подготовки схемы БД к восстановлению: %w", err)
+	}
+
+	repo, files, cleanup, err := newBackupDeps(cfg)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("ошибка чтения архива: %w", err)
+	}
+	if err = backup.Restore(ctx, repo, files, manifest, bufio.NewReader(file)); err != nil {
+		return err
+	}
+	if err = m.Up(); err != nil {
+		return fmt.Errorf("данные восстановлены, но миграции не применены: %w", err)
+	}
+
+	status, err := m.Status()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(out, "Восстановление завершено")
+	printMigrationStatus(out, status)
+	return nil
+}
+
+// printManifest выводит состав резервной копии.
+func printManifest(out io.Writer, manifest *backup.Manifest) {
+	fmt.Fprintf(out, "Создана: %s, версия схемы БД: %d\n", formatAdminTime(&manifest.CreatedAt), manifest.SchemaVersion)
+	for _, table := range manifest.Tables {
+		fmt.Fprintf(out, "Таблица %s: строк %d\n", table.Name, table.Rows)
+	}
+	var size int64
+	for _, object := range manifest.Objects {
+		size += object.Size
+	}
+	fmt.Fprintf(out, "Объектов: %d, объем: %d байт\n", len(manifest.Objects), size)
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/maynagashev/gophkeeper/server/internal/backup"
+	"github.com/maynagashev/gophkeeper/server/internal/mocks"
+	"github.com/maynagashev/gophkeeper/server/internal/repository"
+	"github.com/maynagashev/gophkeeper/server/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// stubBackupDeps подменяет newBackupDeps моками на время теста.
+func stubBackupDeps(t *testing.T) (*mocks.BackupRepository, *mocks.BackupTx, *mocks.FileStorage) {
+	t.Helper()
+	original := newBackupDeps
+	t.Cleanup(func() { newBackupDeps = original })
+	repo := mocks.NewBackupRepository(t)
+	tx := mocks.NewBackupTx(t)
+	files := mocks.NewFileStorage(t)
+	newBackupDeps = func(cfg *config) (repository.BackupRepository, storage.FileStorage, func(), error) {
+		assert.Equal(t, "postgres://db", cfg.Database.DSN)
+		return repo, files, func() {}, nil
+	}
+	return repo, tx, files
+}
+
+// writeTestBackup создает резервную копию с одной строкой users и одним объектом и возвращает путь к ней.
+func writeTestBackup(t *testing.T) string {
+	t.Helper()
+	repo, tx, files := stubBackupDeps(t)
+	repo.EXPECT().Export(mock.Anything, mock.Anything).
+		RunAndReturn(func(_ context.Context, fn func(repository.BackupTx) error) error { return fn(tx) }).Once()
+	tx.EXPECT().SchemaVersion(mock.Anything).Return(10, false, nil).Once()
+	tx.EXPECT().ExportTable(mock.Anything, mock.Anything, mock.Anything).
+		RunAndReturn(func(_ context.Context, table string, fn func([]byte) error) (int64, error) {
+			if table != "users" {
+				return 0, nil
+			}
+			return 1, fn([]byte(`{"id":1,"username":"alice"}`))
+		})
+	tx.EXPECT().ListObjects(mock.Anything).Return([]repository.BackupObject{{Key: "user_1/v1.kdbx"}}, nil).Once()
+	files.EXPECT().DownloadFile(mock.Anything, "user_1/v1.kdbx").
+		Return(io.NopCloser(strings.NewReader("kdbx")), nil).Once()
+
+	path := filepath.Join(t.TempDir(), "backup.tar.gz")
+	var out bytes.Buffer
+	require.NoError(t, runBackupCommand([]string{"-database-dsn=postgres://db", "-out", path}, &out))
+	assert.Contains(t, out.String(), "Таблица users: строк 1")
+	assert.Contains(t, out.String(), "Объектов: 1, объем: 4 байт")
+	return path
+}
+
+func TestRunBackupCommand(t *testing.T) {
+	t.Run("Архив записывается и проходит проверку", func(t *testing.T) {
+		path := writeTestBackup(t)
+
+		file, err := os.Open(path)
+		require.NoError(t, err)
+		defer file.Close()
+		manifest, err := backup.Verify(file)
+		require.NoError(t, err)
+		assert.Equal(t, uint(10), manifest.SchemaVersion)
+
+		entries, err := os.ReadDir(filepath.Dir(path))
+		require.NoError(t, err)
+		assert.Len(t, entries, 1, "временный файл должен быть переименован")
+	})
+
+	t.Run("При ошибке файл не создается", func(t *testing.T) {
+		repo, _, _ := stubBackupDeps(t)
+		repo.EXPECT().Export(mock.Anything, mock.Anything).Return(errors.New("нет соединения")).Once()
+		dir := t.TempDir()
+		var out bytes.Buffer
+
+		err := runBackupCommand([]string{"-database-dsn=postgres://db", "-out", filepath.Join(dir, "b.tar.gz")}, &out)
+
+		require.Error(t, err)
+		entries, readErr := os.ReadDir(dir)
+		require.NoError(t, readErr)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("Не указан файл", func(t *testing.T) {
+		var out bytes.Buffer
+		err := runBackupCommand([]string{"-database-dsn=postgres://db"}, &out)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "-out")
+	})
+}
+
+func TestRunRestoreCommand(t *testing.T) {
+	t.Run("Только проверка не требует БД", func(t *testing.T) {
+		path := writeTestBackup(t)
+		t.Setenv(envDatabaseDSN, "")
+		var out bytes.Buffer
+
+		err := runRestoreCommand([]string{"-in", path, "-verify-only"}, &out)
+
+		require.NoError(t, err)
+		assert.Contains(t, out.String(), "проверен")
+		assert.Contains(t, out.String(), "версия схемы БД: 10")
+	})
+
+	t.Run("Восстановление с миграциями до версии копии и до последней", func(t *testing.T) {
+		path := writeTestBackup(t)
+		m := &fakeMigrator{status: repository.MigrationStatus{Version: 0, Latest: 12}}
+		stubMigrator(t, m)
+		repo, tx, files := stubBackupDeps(t)
+		repo.EXPECT().Import(mock.Anything, mock.Anything).
+			RunAndReturn(func(_ context.Context, fn func(repository.BackupTx) error) error { return fn(tx) }).Once()
+		tx.EXPECT().SchemaVersion(mock.Anything).Return(10, false, nil).Once()
+		tx.EXPECT().IsEmpty(mock.Anything, mock.Anything).Return(true, nil).Once()
+		tx.EXPECT().ImportRows(mock.Anything, "users", [][]byte{[]byte(`{"id":1,"username":"alice"}`)}).
+			Return(nil).Once()
+		tx.EXPECT().ResetSequences(mock.Anything, mock.Anything).Return(nil).Once()
+		files.EXPECT().UploadFile(mock.Anything, "user_1/v1.kdbx", mock.Anything, int64(4), mock.Anything).
+			Return(nil).Once()
+		var out bytes.Buffer
+
+		err := runRestoreCommand([]string{"-database-dsn=postgres://db", "-in", path}, &out)
+
+		require.NoError(t, err)
+		assert.Equal(t, []uint{10}, m.migrateTo)
+		assert.Equal(t, 1, m.upCalls)
+		assert.True(t, m.closed)
+		assert.Contains(t, out.String(), "Восстановление завершено")
+		assert.Contains(t, out.String(), "Версия схемы БД: 12")
+	})
+
+	t.Run("Поврежденный архив не восстанавливается", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "broken.tar.gz")
+		require.NoError(t, os.WriteFile(path, []byte("broken"), 0o600))
+		var out bytes.Buffer
+
+		err := runRestoreCommand([]string{"-database-dsn=postgres://db", "-in", path}, &out)
+
+		require.ErrorIs(t, err, backup.ErrInvalidArchive)
+	})
+
+	t.Run("Не указан файл", func(t *testing.T) {
+		var out bytes.Buffer
+		err := runRestoreCommand([]string{"-verify-only"}, &out)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "-in")
+	})
+}
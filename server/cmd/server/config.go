@@ -0,0 +1,416 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/maynagashev/gophkeeper/server/internal/repository"
+	"github.com/maynagashev/gophkeeper/server/internal/services"
+	"github.com/maynagashev/gophkeeper/server/internal/tlsutil"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// Значения по умолчанию для HTTP-сервера.
+	defaultServerPort      = "8443"
+	defaultReadTimeout     = 10 * time.Second
+	defaultWriteTimeout    = 10 * time.Second
+	defaultIdleTimeout     = 30 * time.Second
+	defaultShutdownTimeout = 30 * time.Second
+
+	// Значения по умолчанию для MinIO (из docker-compose).
+	storageBackendMinio  = "minio"
+	defaultMinioEndpoint = "localhost:9000"
+	defaultMinioUser     = "minioadmin"
+	defaultMinioPassword = "minioadmin"
+	defaultMinioBucket   = "gophkeeper-vaults"
+
+	// Интервал фоновой проверки целостности объектов по умолчанию.
+	defaultScrubInterval = 24 * time.Hour
+	// Предельное время одной операции с БД по умолчанию.
+	defaultDBTimeout = 5 * time.Second
+	// Предельное время чтения или записи объекта в хранилище по умолчанию (файлы хранилищ бывают большими).
+	defaultStorageTimeout = 10 * time.Minute
+
+	// Период проверки файлов TLS-сертификата по умолчанию.
+	defaultTLSReloadInterval = 30 * time.Second
+
+	// redactedValue заменяет секреты при выводе конфигурации.
+	redactedValue = "xxxxx"
+)
+
+// config - действующая конфигурация сервера. Значения собираются слоями: значения по умолчанию,
+// файл конфигурации (-config), переменные окружения и флаги; каждый следующий слой переопределяет предыдущий.
+type config struct {
+	Server   serverConfig   `yaml:"server" toml:"server"`
+	TLS      tlsConfig      `yaml:"tls" toml:"tls"`
+	Database databaseConfig `yaml:"database" toml:"database"`
+	Storage  storageConfig  `yaml:"storage" toml:"storage"`
+	Auth     authConfig     `yaml:"auth" toml:"auth"`
+	Limits   limitsConfig   `yaml:"limits" toml:"limits"`
+	Webhooks webhooksConfig `yaml:"webhooks" toml:"webhooks"`
+}
+
+// serverConfig - параметры HTTP-сервера.
+type serverConfig struct {
+	// Address - адрес HTTPS-сервера в формате host:port (пустой host - все интерфейсы).
+	Address string `yaml:"address" toml:"address"`
+	// Таймауты соединений http.Server (0 - без ограничения).
+	ReadTimeout  time.Duration `yaml:"read_timeout" toml:"read_timeout"`
+	WriteTimeout time.Duration `yaml:"write_timeout" toml:"write_timeout"`
+	IdleTimeout  time.Duration `yaml:"idle_timeout" toml:"idle_timeout"`
+	// ShutdownTimeout - время ожидания завершения активных запросов при остановке (0 - без ограничения).
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout"`
+	// MetricsAddr - адрес отдельного HTTP-сервера метрик (пусто - метрики на /metrics основного порта).
+	MetricsAddr string `yaml:"metrics_addr" toml:"metrics_addr"`
+	// GRPCAddress - адрес gRPC-сервера (TLS с тем же сертификатом; пусто - gRPC API выключен).
+	GRPCAddress string `yaml:"grpc_address" toml:"grpc_address"`
+	// AdminToken - токен для служебных маршрутов /api/admin (пусто - маршруты выключены).
+	AdminToken string `yaml:"admin_token" toml:"admin_token"`
+}
+
+// tlsConfig - сертификат и параметры TLS HTTPS- и gRPC-сервера.
+type tlsConfig struct {
+	CertFile string `yaml:"cert_file" toml:"cert_file"`
+	KeyFile  string `yaml:"key_file" toml:"key_file"`
+	// AutoGenerate - создать самоподписанный CA и сертификат сервера, если файлов сертификата и ключа нет.
+	AutoGenerate bool `yaml:"auto_generate" toml:"auto_generate"`
+	// Hosts - имена и IP-адреса, на которые выпускается сертификат при auto_generate.
+	Hosts []string `yaml:"hosts" toml:"hosts"`
+	// MinVersion - минимальная версия TLS: 1.2 или 1.3.
+	MinVersion string `yaml:"min_version" toml:"min_version"`
+	// CipherSuites - разрешенные наборы шифров TLS 1.2 (пусто - наборы Go по умолчанию).
+	CipherSuites []string `yaml:"cipher_suites" toml:"cipher_suites"`
+	// ReloadInterval - период проверки файлов сертификата и ключа для перезагрузки (0 - не перезагружать).
+	ReloadInterval time.Duration `yaml:"reload_interval" toml:"reload_interval"`
+}
+
+// databaseConfig - подключение к PostgreSQL.
+type databaseConfig struct {
+	DSN             string        `yaml:"dsn" toml:"dsn"`
+	MaxOpenConns    int           `yaml:"max_open_conns" toml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" toml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" toml:"conn_max_lifetime"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" toml:"conn_max_idle_time"`
+	// Timeout - предельное время операции с БД (0 - без ограничения).
+	Timeout time.Duration `yaml:"timeout" toml:"timeout"`
+	// AutoMigrate - применять встроенные миграции схемы БД при запуске.
+	AutoMigrate bool `yaml:"auto_migrate" toml:"auto_migrate"`
+}
+
+// storageConfig - хранилище объектов.
+type storageConfig struct {
+	// Backend - тип хранилища (поддерживается только minio - MinIO или другое S3-совместимое хранилище).
+	Backend   string `yaml:"backend" toml:"backend"`
+	Endpoint  string `yaml:"endpoint" toml:"endpoint"`
+	AccessKey string `yaml:"access_key" toml:"access_key"`
+	SecretKey string `yaml:"secret_key" toml:"secret_key"`
+	Bucket    string `yaml:"bucket" toml:"bucket"`
+	Region    string `yaml:"region" toml:"region"`
+	UseSSL    bool   `yaml:"use_ssl" toml:"use_ssl"`
+	// Timeout - предельное время чтения или записи объекта (0 - без ограничения).
+	Timeout time.Duration `yaml:"timeout" toml:"timeout"`
+	// MasterKeyFile - путь к файлу мастер-ключей для шифрования объектов (пусто - шифрование выключено).
+	MasterKeyFile string `yaml:"master_key_file" toml:"master_key_file"`
+	// ScrubInterval - интервал фоновой проверки целостности объектов (0 - проверка выключена).
+	ScrubInterval time.Duration `yaml:"scrub_interval" toml:"scrub_interval"`
+}
+
+// authConfig - параметры выдачи и проверки JWT.
+type authConfig struct {
+	JWTSecret string        `yaml:"jwt_secret" toml:"jwt_secret"`
+	TokenTTL  time.Duration `yaml:"token_ttl" toml:"token_ttl"`
+}
+
+// limitsConfig - ограничения на запросы клиентов.
+type limitsConfig struct {
+	// MaxUploadSize - максимальный размер загружаемого файла хранилища в байтах (0 - без ограничения).
+	MaxUploadSize int64 `yaml:"max_upload_size" toml:"max_upload_size"`
+}
+
+// webhooksConfig - отправка вебхуков.
+type webhooksConfig struct {
+	// AllowedNetworks - внутренние сети в формате CIDR, куда разрешено отправлять вебхуки
+	// (по умолчанию loopback, link-local и частные сети запрещены).
+	AllowedNetworks []string `yaml:"allowed_networks" toml:"allowed_networks"`
+}
+
+// defaultConfig возвращает конфигурацию со значениями по умолчанию.
+func defaultConfig() *config {
+	pool := repository.DefaultPoolConfig()
+	return &config{
+		Server: serverConfig{
+			Address:         ":" + defaultServerPort,
+			ReadTimeout:     defaultReadTimeout,
+			WriteTimeout:    defaultWriteTimeout,
+			IdleTimeout:     defaultIdleTimeout,
+			ShutdownTimeout: defaultShutdownTimeout,
+		},
+		TLS: tlsConfig{
+			Hosts:          defaultTLSHosts(),
+			MinVersion:     tlsutil.Version12,
+			ReloadInterval: defaultTLSReloadInterval,
+		},
+		Database: databaseConfig{
+			MaxOpenConns:    pool.MaxOpenConns,
+			MaxIdleConns:    pool.MaxIdleConns,
+			ConnMaxLifetime: pool.ConnMaxLifetime,
+			ConnMaxIdleTime: pool.ConnMaxIdleTime,
+			Timeout:         defaultDBTimeout,
+		},
+		Storage: storageConfig{
+			Backend:       storageBackendMinio,
+			Endpoint:      defaultMinioEndpoint,
+			AccessKey:     defaultMinioUser,
+			SecretKey:     defaultMinioPassword,
+			Bucket:        defaultMinioBucket,
+			Timeout:       defaultStorageTimeout,
+			ScrubInterval: defaultScrubInterval,
+		},
+		Auth: authConfig{
+			JWTSecret: services.DefaultJWTSecret,
+			TokenTTL:  services.DefaultTokenTTL,
+		},
+	}
+}
+
+// loadFile накладывает на конфигурацию значения из файла YAML (.yaml, .yml) или TOML (.toml).
+// Неизвестные ключи считаются ошибкой, чтобы опечатка не оставляла параметр со значением по умолчанию.
+func (c *config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("ошибка чтения файла конфигурации: %w", err)
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err = decoder.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("ошибка разбора файла конфигурации %s: %w", path, err)
+		}
+	case ".toml":
+		meta, decodeErr := toml.Decode(string(data), c)
+		if decodeErr != nil {
+			return fmt.Errorf("ошибка разбора файла конфигурации %s: %w", path, decodeErr)
+		}
+		if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+			return fmt.Errorf("ошибка разбора файла конфигурации %s: неизвестный параметр %s", path, undecoded[0])
+		}
+	default:
+		return fmt.Errorf("неподдерживаемый формат файла конфигурации '%s' (ожидается .yaml, .yml или .toml)", ext)
+	}
+	return nil
+}
+
+// validate проверяет конфигурацию и возвращает все найденные ошибки с указанием параметра.
+func (c *config) validate() error {
+	var errs []error
+	addErr := func(key, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+	}
+
+	if err := validateAddress(c.Server.Address); err != nil {
+		addErr("server.address", "%v (--address, %s или --port, %s)", err, envServerAddress, envServerPort)
+	}
+	if c.Server.MetricsAddr != "" {
+		if err := validateAddress(c.Server.MetricsAddr); err != nil {
+			addErr("server.metrics_addr", "%v (--metrics-addr или %s)", err, envMetricsAddr)
+		}
+	}
+	if c.Server.GRPCAddress != "" {
+		if err := validateAddress(c.Server.GRPCAddress); err != nil {
+			addErr("server.grpc_address", "%v (--grpc-address или %s)", err, envGRPCAddress)
+		}
+	}
+	if c.TLS.CertFile == "" {
+		addErr("tls.cert_file", "не указан путь к файлу сертификата (--cert-file или %s)", envTLSCertFile)
+	}
+	if c.TLS.KeyFile == "" {
+		addErr("tls.key_file", "не указан путь к файлу ключа (--key-file или %s)", envTLSKeyFile)
+	}
+	if c.TLS.AutoGenerate && len(c.TLS.Hosts) == 0 {
+		addErr("tls.hosts", "не указаны имена хоста для создаваемого сертификата (--tls-hosts или %s)", envTLSHosts)
+	}
+	if _, err := tlsutil.ParseVersion(c.TLS.MinVersion); err != nil {
+		addErr("tls.min_version", "%v (--tls-min-version или %s)", err, envTLSMinVersion)
+	}
+	if _, err := tlsutil.ParseCipherSuites(c.TLS.CipherSuites); err != nil {
+		addErr("tls.cipher_suites", "%v (--tls-cipher-suites или %s)", err, envTLSCiphers)
+	} else if len(c.TLS.CipherSuites) > 0 && c.TLS.MinVersion == tlsutil.Version13 {
+		addErr("tls.cipher_suites", "наборы шифров TLS 1.2 не применяются при tls.min_version %s", tlsutil.Version13)
+	}
+
+	if c.Database.DSN == "" {
+		addErr("database.dsn", "не указана строка подключения к БД (--database-dsn или %s)", envDatabaseDSN)
+	}
+	if c.Database.MaxOpenConns < 0 {
+		addErr("database.max_open_conns", "не может быть отрицательным (0 - без ограничения)")
+	}
+	if c.Database.MaxIdleConns < 0 {
+		addErr("database.max_idle_conns", "не может быть отрицательным")
+	}
+	if c.Database.MaxOpenConns > 0 && c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		addErr("database.max_idle_conns", "%d больше database.max_open_conns (%d)",
+			c.Database.MaxIdleConns, c.Database.MaxOpenConns)
+	}
+
+	if c.Storage.Backend != storageBackendMinio {
+		addErr("storage.backend", "неподдерживаемое хранилище '%s' (поддерживается: %s)",
+			c.Storage.Backend, storageBackendMinio)
+	}
+	if c.Storage.Endpoint == "" {
+		addErr("storage.endpoint", "не указан адрес хранилища (--minio-endpoint или %s)", envMinioEndpoint)
+	}
+	if c.Storage.Bucket == "" {
+		addErr("storage.bucket", "не указано имя бакета (--minio-bucket или %s)", envMinioBucket)
+	}
+
+	if c.Auth.JWTSecret == "" {
+		addErr("auth.jwt_secret", "не указан ключ подписи токенов (--jwt-secret или %s)", envJWTSecret)
+	}
+	if c.Auth.TokenTTL <= 0 {
+		addErr("auth.token_ttl", "время жизни токена должно быть больше нуля")
+	}
+	if c.Limits.MaxUploadSize < 0 {
+		addErr("limits.max_upload_size", "не может быть отрицательным (0 - без ограничения)")
+	}
+	for _, network := range c.Webhooks.AllowedNetworks {
+		if _, err := netip.ParsePrefix(network); err != nil {
+			addErr("webhooks.allowed_networks", "неверная сеть '%s', ожидается CIDR, например 10.0.0.0/8 "+
+				"(--webhook-allowed-networks или %s)", network, envWebhookNets)
+		}
+	}
+
+	durations := []struct {
+		key   string
+		value time.Duration
+	}{
+		{"server.read_timeout", c.Server.ReadTimeout},
+		{"server.write_timeout", c.Server.WriteTimeout},
+		{"server.idle_timeout", c.Server.IdleTimeout},
+		{"server.shutdown_timeout", c.Server.ShutdownTimeout},
+		{"database.conn_max_lifetime", c.Database.ConnMaxLifetime},
+		{"database.conn_max_idle_time", c.Database.ConnMaxIdleTime},
+		{"database.timeout", c.Database.Timeout},
+		{"storage.timeout", c.Storage.Timeout},
+		{"storage.scrub_interval", c.Storage.ScrubInterval},
+		{"tls.reload_interval", c.TLS.ReloadInterval},
+	}
+	for _, d := range durations {
+		if d.value < 0 {
+			addErr(d.key, "длительность не может быть отрицательной")
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateAddress проверяет адрес в формате host:port.
+func validateAddress(addr string) error {
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("неверный адрес '%s' (ожидается host:port)", addr)
+	}
+	if n, convErr := strconv.Atoi(port); convErr != nil || n < 0 || n > 65535 {
+		return fmt.Errorf("неверный порт в адресе '%s'", addr)
+	}
+	return nil
+}
+
+// defaultTLSHosts возвращает имена, на которые по умолчанию выпускается самоподписанный сертификат.
+func defaultTLSHosts() []string {
+	return []string{"localhost", "127.0.0.1", "::1"}
+}
+
+// webhookAllowedNetworks возвращает разобранные сети webhooks.allowed_networks (конфигурация уже проверена).
+func (c *config) webhookAllowedNetworks() []netip.Prefix {
+	networks := make([]netip.Prefix, 0, len(c.Webhooks.AllowedNetworks))
+	for _, network := range c.Webhooks.AllowedNetworks {
+		if prefix, err := netip.ParsePrefix(network); err == nil {
+			networks = append(networks, prefix.Masked())
+		}
+	}
+	return networks
+}
+
+// poolConfig возвращает параметры пула соединений с БД.
+func (c *config) poolConfig() repository.PoolConfig {
+	return repository.PoolConfig{
+		MaxOpenConns:    c.Database.MaxOpenConns,
+		MaxIdleConns:    c.Database.MaxIdleConns,
+		ConnMaxLifetime: c.Database.ConnMaxLifetime,
+		ConnMaxIdleTime: c.Database.ConnMaxIdleTime,
+	}
+}
+
+// redacted возвращает копию конфигурации со скрытыми секретами (для вывода и логов).
+func (c *config) redacted() config {
+	out := *c
+	for _, secret := range []*string{&out.Server.AdminToken, &out.Storage.SecretKey, &out.Auth.JWTSecret} {
+		if *secret != "" {
+			*secret = redactedValue
+		}
+	}
+	out.Database.DSN = redactDSN(out.Database.DSN)
+	return out
+}
+
+// redactDSN скрывает пароль в строке подключения к PostgreSQL (в форме URL или key=value).
+func redactDSN(dsn string) string {
+	if u, err := url.Parse(dsn); err == nil && u.User != nil {
+		if _, hasPassword := u.User.Password(); hasPassword {
+			u.User = url.UserPassword(u.User.Username(), redactedValue)
+			dsn = u.String()
+		}
+	}
+	passwordParam := regexp.MustCompile(`(password\s*=\s*)('[^']*'|[^\s&]+)`)
+	return passwordParam.ReplaceAllString(dsn, "${1}"+redactedValue)
+}
+
+// writeConfig выводит конфигурацию в формате YAML, пригодном для использования в качестве файла -config.
+func writeConfig(out io.Writer, cfg config) error {
+	encoder := yaml.NewEncoder(out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(cfg); err != nil {
+		return fmt.Errorf("ошибка вывода конфигурации: %w", err)
+	}
+	return encoder.Close()
+}
+
+// runConfigCommand выполняет подкоманду "config print": выводит действующую конфигурацию (после применения
+// файла, переменных окружения и флагов) со скрытыми секретами и сообщает об ошибках в ней.
+func runConfigCommand(args []string, out io.Writer) error {
+	if len(args) == 0 || args[0] != "print" {
+		fmt.Fprintln(out, "Использование: gophkeeper-server config print [-config <файл>] [флаги]")
+		return errors.New("укажите действие: print")
+	}
+
+	fs := flag.NewFlagSet("config print", flag.ContinueOnError)
+	fs.SetOutput(out)
+	cfg, err := readConfig(fs, args[1:])
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(out, "# Действующая конфигурация сервера GophKeeper (секреты скрыты)")
+	if err = writeConfig(out, cfg.redacted()); err != nil {
+		return err
+	}
+	if err = cfg.validate(); err != nil {
+		return fmt.Errorf("конфигурация содержит ошибки:\n%w", err)
+	}
+	return nil
+}
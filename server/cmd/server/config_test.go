@@ -0,0 +1,363 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testYAMLConfig = `
+server:
+  address: "127.0.0.1:9443"
+  read_timeout: 15s
+  shutdown_timeout: 1m
+  admin_token: "file-admin-token"
+tls:
+  cert_file: "/etc/gophkeeper/cert.pem"
+  key_file: "/etc/gophkeeper/key.pem"
+  auto_generate: true
+  hosts: ["keeper.example.com", "10.0.0.5"]
+  min_version: "1.3"
+database:
+  dsn: "postgres://gophkeeper:db-password@db:5432/gophkeeper?sslmode=disable"
+  max_open_conns: 50
+  max_idle_conns: 10
+  conn_max_lifetime: 1h
+  timeout: 3s
+  auto_migrate: true
+storage:
+  endpoint: "s3.example.com"
+  access_key: "access"
+  secret_key: "storage-secret"
+  bucket: "vaults"
+  region: "eu-central-1"
+  use_ssl: true
+auth:
+  jwt_secret: "file-jwt-secret"
+  token_ttl: 2h
+limits:
+  max_upload_size: 1048576
+webhooks:
+  allowed_networks: ["10.20.0.0/16"]
+`
+
+const testTOMLConfig = `
+[server]
+address = "127.0.0.1:9443"
+read_timeout = "15s"
+shutdown_timeout = "1m"
+admin_token = "file-admin-token"
+
+[tls]
+cert_file = "/etc/gophkeeper/cert.pem"
+key_file = "/etc/gophkeeper/key.pem"
+auto_generate = true
+hosts = ["keeper.example.com", "10.0.0.5"]
+min_version = "1.3"
+
+[database]
+dsn = "postgres://gophkeeper:db-password@db:5432/gophkeeper?sslmode=disable"
+max_open_conns = 50
+max_idle_conns = 10
+conn_max_lifetime = "1h"
+timeout = "3s"
+auto_migrate = true
+
+[storage]
+endpoint = "s3.example.com"
+access_key = "access"
+secret_key = "storage-secret"
+bucket = "vaults"
+region = "eu-central-1"
+use_ssl = true
+
+[auth]
+jwt_secret = "file-jwt-secret"
+token_ttl = "2h"
+
+[limits]
+max_upload_size = 1048576
+
+[webhooks]
+allowed_networks = ["10.20.0.0/16"]
+`
+
+// writeConfigFile сохраняет содержимое файла конфигурации во временный каталог.
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+// loadTestConfig загружает конфигурацию с новым набором флагов.
+func loadTestConfig(args ...string) (*config, error) {
+	return loadConfig(flag.NewFlagSet("test", flag.ContinueOnError), args)
+}
+
+func TestLoadConfig_File(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{name: "YAML", file: "server.yaml", content: testYAMLConfig},
+		{name: "TOML", file: "server.toml", content: testTOMLConfig},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, err := loadTestConfig("-config", writeConfigFile(t, tc.file, tc.content))
+			require.NoError(t, err)
+
+			assert.Equal(t, "127.0.0.1:9443", cfg.Server.Address)
+			assert.Equal(t, 15*time.Second, cfg.Server.ReadTimeout)
+			assert.Equal(t, defaultWriteTimeout, cfg.Server.WriteTimeout) // Не задан в файле
+			assert.Equal(t, time.Minute, cfg.Server.ShutdownTimeout)
+			assert.Equal(t, "file-admin-token", cfg.Server.AdminToken)
+			assert.Equal(t, "/etc/gophkeeper/cert.pem", cfg.TLS.CertFile)
+			assert.True(t, cfg.TLS.AutoGenerate)
+			assert.Equal(t, []string{"keeper.example.com", "10.0.0.5"}, cfg.TLS.Hosts)
+			assert.Equal(t, "1.3", cfg.TLS.MinVersion)
+			assert.Equal(t, defaultTLSReloadInterval, cfg.TLS.ReloadInterval) // Не задан в файле
+			assert.Equal(t, 50, cfg.Database.MaxOpenConns)
+			assert.Equal(t, 10, cfg.Database.MaxIdleConns)
+			assert.Equal(t, time.Hour, cfg.Database.ConnMaxLifetime)
+			assert.Equal(t, 3*time.Second, cfg.Database.Timeout)
+			assert.True(t, cfg.Database.AutoMigrate)
+			assert.Equal(t, storageBackendMinio, cfg.Storage.Backend)
+			assert.Equal(t, "s3.example.com", cfg.Storage.Endpoint)
+			assert.Equal(t, "eu-central-1", cfg.Storage.Region)
+			assert.True(t, cfg.Storage.UseSSL)
+			assert.Equal(t, "file-jwt-secret", cfg.Auth.JWTSecret)
+			assert.Equal(t, 2*time.Hour, cfg.Auth.TokenTTL)
+			assert.Equal(t, int64(1048576), cfg.Limits.MaxUploadSize)
+			assert.Equal(t, []netip.Prefix{netip.MustParsePrefix("10.20.0.0/16")}, cfg.webhookAllowedNetworks())
+		})
+	}
+
+	t.Run("Путь к файлу из переменной окружения", func(t *testing.T) {
+		t.Setenv(envConfigFile, writeConfigFile(t, "server.yml", testYAMLConfig))
+
+		cfg, err := loadTestConfig()
+		require.NoError(t, err)
+		assert.Equal(t, "127.0.0.1:9443", cfg.Server.Address)
+	})
+
+	t.Run("Неизвестный параметр в YAML", func(t *testing.T) {
+		path := writeConfigFile(t, "server.yaml", "server:\n  adress: \":9443\"\n")
+
+		_, err := loadTestConfig("-config", path)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "adress")
+	})
+
+	t.Run("Неизвестный параметр в TOML", func(t *testing.T) {
+		path := writeConfigFile(t, "server.toml", "[storage]\nbuckets = \"vaults\"\n")
+
+		_, err := loadTestConfig("-config", path)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "storage.buckets")
+	})
+
+	t.Run("Неверный тип значения", func(t *testing.T) {
+		path := writeConfigFile(t, "server.yaml", "database:\n  timeout: soon\n")
+
+		_, err := loadTestConfig("-config", path)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "ошибка разбора файла конфигурации")
+	})
+
+	t.Run("Неподдерживаемый формат", func(t *testing.T) {
+		_, err := loadTestConfig("-config", writeConfigFile(t, "server.json", "{}"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "неподдерживаемый формат файла конфигурации")
+	})
+
+	t.Run("Файл не найден", func(t *testing.T) {
+		_, err := loadTestConfig("-config", filepath.Join(t.TempDir(), "missing.yaml"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "ошибка чтения файла конфигурации")
+	})
+}
+
+func TestLoadConfig_Precedence(t *testing.T) {
+	path := writeConfigFile(t, "server.yaml", testYAMLConfig)
+	t.Setenv(envDBTimeout, "7s")
+	t.Setenv(envServerPort, "9000")
+	t.Setenv(envJWTSecret, "env-jwt-secret")
+	t.Setenv(envTLSHosts, "env.example.com")
+
+	cfg, err := loadTestConfig("-config", path, "-jwt-secret", "flag-jwt-secret", "-max-upload-size", "2048",
+		"-tls-hosts", "keeper.local, 192.0.2.1,")
+	require.NoError(t, err)
+
+	// Переменная окружения переопределяет файл, порт заменяется с сохранением хоста
+	assert.Equal(t, 7*time.Second, cfg.Database.Timeout)
+	assert.Equal(t, "127.0.0.1:9000", cfg.Server.Address)
+	// Флаг переопределяет и файл, и переменную окружения
+	assert.Equal(t, "flag-jwt-secret", cfg.Auth.JWTSecret)
+	assert.Equal(t, int64(2048), cfg.Limits.MaxUploadSize)
+	assert.Equal(t, []string{"keeper.local", "192.0.2.1"}, cfg.TLS.Hosts)
+	// Остальное берется из файла
+	assert.Equal(t, "storage-secret", cfg.Storage.SecretKey)
+}
+
+func TestLoadConfig_InvalidOverride(t *testing.T) {
+	path := writeConfigFile(t, "server.yaml", testYAMLConfig)
+
+	t.Run("Флаг", func(t *testing.T) {
+		_, err := loadTestConfig("-config", path, "-db-max-open-conns", "many")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "неверный размер пула соединений с БД 'many' (--db-max-open-conns)")
+	})
+
+	t.Run("Переменная окружения", func(t *testing.T) {
+		t.Setenv(envTokenTTL, "-1h")
+
+		_, err := loadTestConfig("-config", path)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "неверный срок действия токена '-1h' ("+envTokenTTL+")")
+	})
+}
+
+func TestConfig_Validate(t *testing.T) {
+	validConfig := func() *config {
+		cfg := defaultConfig()
+		cfg.TLS.CertFile = "cert.pem"
+		cfg.TLS.KeyFile = "key.pem"
+		cfg.Database.DSN = "postgres://db"
+		return cfg
+	}
+
+	t.Run("Корректная конфигурация", func(t *testing.T) {
+		require.NoError(t, validConfig().validate())
+	})
+
+	t.Run("Все ошибки с указанием параметра", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.Server.Address = "localhost"
+		cfg.Database.MaxOpenConns = 5
+		cfg.Database.MaxIdleConns = 10
+		cfg.Storage.Backend = "s3"
+		cfg.Storage.Timeout = -time.Second
+		cfg.Auth.TokenTTL = 0
+		cfg.Limits.MaxUploadSize = -1
+		cfg.TLS.MinVersion = "1.0"
+		cfg.TLS.CipherSuites = []string{"TLS_RSA_WITH_RC4_128_SHA"}
+		cfg.Webhooks.AllowedNetworks = []string{"10.0.0.0/8", "intranet"}
+
+		err := cfg.validate()
+		require.Error(t, err)
+		msg := err.Error()
+		assert.Contains(t, msg, "server.address: неверный адрес 'localhost'")
+		assert.Contains(t, msg, "database.max_idle_conns: 10 больше database.max_open_conns (5)")
+		assert.Contains(t, msg, "storage.backend: неподдерживаемое хранилище 's3'")
+		assert.Contains(t, msg, "storage.timeout: длительность не может быть отрицательной")
+		assert.Contains(t, msg, "auth.token_ttl")
+		assert.Contains(t, msg, "limits.max_upload_size")
+		assert.Contains(t, msg, "tls.min_version: неподдерживаемая версия TLS '1.0'")
+		assert.Contains(t, msg, "tls.cipher_suites: небезопасный набор шифров TLS_RSA_WITH_RC4_128_SHA")
+		assert.Contains(t, msg, "webhooks.allowed_networks: неверная сеть 'intranet'")
+		assert.NotContains(t, msg, "'10.0.0.0/8'")
+	})
+
+	t.Run("Наборы шифров TLS 1.2 при минимальной версии 1.3", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.TLS.MinVersion = "1.3"
+		cfg.TLS.CipherSuites = []string{"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256"}
+
+		err := cfg.validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "tls.cipher_suites: наборы шифров TLS 1.2 не применяются")
+	})
+
+	t.Run("Неограниченный пул допускает любое число простаивающих соединений", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.Database.MaxOpenConns = 0
+		cfg.Database.MaxIdleConns = 100
+		require.NoError(t, cfg.validate())
+	})
+}
+
+func TestRedactDSN(t *testing.T) {
+	tests := []struct {
+		name string
+		dsn  string
+		want string
+	}{
+		{
+			name: "URL с паролем",
+			dsn:  "postgres://user:secret@db:5432/app?sslmode=disable",
+			want: "postgres://user:xxxxx@db:5432/app?sslmode=disable",
+		},
+		{
+			name: "Пароль в параметрах URL",
+			dsn:  "postgres://db/app?user=app&password=secret&sslmode=disable",
+			want: "postgres://db/app?user=app&password=xxxxx&sslmode=disable",
+		},
+		{
+			name: "Формат key=value",
+			dsn:  "host=db user=app password='s e c' dbname=app",
+			want: "host=db user=app password=xxxxx dbname=app",
+		},
+		{
+			name: "Без пароля",
+			dsn:  "postgres://user@db/app",
+			want: "postgres://user@db/app",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, redactDSN(tc.dsn))
+		})
+	}
+}
+
+func TestRunConfigCommand(t *testing.T) {
+	path := writeConfigFile(t, "server.yaml", testYAMLConfig)
+
+	t.Run("Вывод действующей конфигурации без секретов", func(t *testing.T) {
+		t.Setenv(envMinioBucket, "env-bucket")
+		var out bytes.Buffer
+
+		err := runConfigCommand([]string{"print", "-config", path}, &out)
+		require.NoError(t, err)
+
+		printed := out.String()
+		assert.Contains(t, printed, "address: 127.0.0.1:9443")
+		assert.Contains(t, printed, "bucket: env-bucket")
+		assert.Contains(t, printed, "dsn: postgres://gophkeeper:xxxxx@db:5432/gophkeeper?sslmode=disable")
+		for _, secret := range []string{"db-password", "storage-secret", "file-jwt-secret", "file-admin-token"} {
+			assert.NotContains(t, printed, secret)
+		}
+
+		// Вывод можно использовать как файл конфигурации
+		reloaded, loadErr := loadTestConfig("-config", writeConfigFile(t, "printed.yaml", printed))
+		require.NoError(t, loadErr)
+		assert.Equal(t, "env-bucket", reloaded.Storage.Bucket)
+		assert.Equal(t, 2*time.Hour, reloaded.Auth.TokenTTL)
+	})
+
+	t.Run("Конфигурация с ошибками выводится вместе с ошибками", func(t *testing.T) {
+		var out bytes.Buffer
+
+		err := runConfigCommand([]string{"print", "-config", path, "-storage-backend", "s3"}, &out)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "storage.backend")
+		assert.Contains(t, out.String(), "backend: s3")
+	})
+
+	t.Run("Неизвестное действие", func(t *testing.T) {
+		var out bytes.Buffer
+
+		err := runConfigCommand([]string{"show"}, &out)
+		require.Error(t, err)
+		assert.Contains(t, out.String(), "Использование")
+	})
+}
@@ -1,82 +1,438 @@
 package main
 
 import (
-	"errors"
 	"flag"
 	"fmt"
+	"net"
 	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/maynagashev/gophkeeper/server/internal/tlsutil"
 )
 
 const (
-	// Порт по умолчанию для HTTPS (непривилегированный).
-	defaultServerPort = "8443"
-
 	// Переменные окружения.
-	envServerPort  = "SERVER_PORT"
-	envTLSCertFile = "TLS_CERT_FILE"
-	envTLSKeyFile  = "TLS_KEY_FILE"
-	envDatabaseDSN = "DATABASE_DSN"
+	envConfigFile      = "GOPHKEEPER_CONFIG"
+	envServerAddress   = "SERVER_ADDRESS"
+	envServerPort      = "SERVER_PORT"
+	envReadTimeout     = "HTTP_READ_TIMEOUT"
+	envWriteTimeout    = "HTTP_WRITE_TIMEOUT"
+	envIdleTimeout     = "HTTP_IDLE_TIMEOUT"
+	envShutdown        = "SHUTDOWN_TIMEOUT"
+	envMetricsAddr     = "METRICS_ADDR"
+	envGRPCAddress     = "GRPC_ADDRESS"
+	envAdminToken      = "ADMIN_TOKEN"
+	envTLSCertFile     = "TLS_CERT_FILE"
+	envTLSKeyFile      = "TLS_KEY_FILE"
+	envTLSAutoGenerate = "TLS_AUTO_GENERATE"
+	envTLSHosts        = "TLS_HOSTS"
+	envTLSMinVersion   = "TLS_MIN_VERSION"
+	envTLSCiphers      = "TLS_CIPHER_SUITES"
+	envTLSReload       = "TLS_RELOAD_INTERVAL"
+	envDatabaseDSN     = "DATABASE_DSN"
+	envDBMaxOpenConns  = "DB_MAX_OPEN_CONNS"
+	envDBMaxIdleConns  = "DB_MAX_IDLE_CONNS"
+	envDBConnLifetime  = "DB_CONN_MAX_LIFETIME"
+	envDBConnIdleTime  = "DB_CONN_MAX_IDLE_TIME"
+	envDBTimeout       = "DB_TIMEOUT"
+	envAutoMigrate     = "AUTO_MIGRATE"
+	envStorageBackend  = "STORAGE_BACKEND"
+	envMinioEndpoint   = "MINIO_ENDPOINT"
+	envMinioUser       = "MINIO_USER"
+	envMinioPassword   = "MINIO_PASSWORD"
+	envMinioBucket     = "MINIO_BUCKET"
+	envMinioRegion     = "MINIO_REGION"
+	envMinioUseSSL     = "MINIO_USE_SSL"
+	envStorageTime     = "STORAGE_TIMEOUT"
+	envMasterKey       = "STORAGE_MASTER_KEY_FILE"
+	envScrubPeriod     = "STORAGE_SCRUB_INTERVAL"
+	envJWTSecret       = "JWT_SECRET"
+	envTokenTTL        = "TOKEN_TTL"
+	envMaxUploadSize   = "MAX_UPLOAD_SIZE"
+	envWebhookNets     = "WEBHOOK_ALLOWED_NETWORKS"
+	flagConfigFileName = "config"
 )
 
-// config хранит конфигурацию сервера.
-type config struct {
-	Port        string
-	CertFile    string
-	KeyFile     string
-	DatabaseDSN string
+// setting описывает параметр, который можно задать флагом или переменной окружения
+// поверх значения из файла конфигурации.
+type setting struct {
+	flag   string // Имя флага командной строки
+	env    string // Имя переменной окружения
+	usage  string // Описание для справки (включает ключ файла конфигурации)
+	what   string // Название параметра в сообщении об ошибке: "неверный <what>"
+	isBool bool   // Флаг без значения (-auto-migrate)
+	apply  func(cfg *config, value string) error
+}
+
+// settings возвращает все параметры, переопределяемые флагами и переменными окружения.
+// Порядок важен: параметры применяются в порядке объявления (например, -port после -address).
+func settings() []setting {
+	return []setting{
+		{
+			flag: "address", env: envServerAddress, what: "адрес сервера",
+			usage: "Адрес HTTPS-сервера host:port (server.address, default: :" + defaultServerPort + ")",
+			apply: stringSetting(func(c *config) *string { return &c.Server.Address }),
+		},
+		{
+			flag: "port", env: envServerPort, what: "порт",
+			usage: "Порт HTTPS-сервера, заменяет порт в server.address",
+			apply: func(c *config, value string) error {
+				if n, err := strconv.Atoi(value); err != nil || n < 0 || n > 65535 {
+					return fmt.Errorf("порт должен быть числом от 0 до 65535")
+				}
+				host, _, err := net.SplitHostPort(c.Server.Address)
+				if err != nil {
+					host = ""
+				}
+				c.Server.Address = net.JoinHostPort(host, value)
+				return nil
+			},
+		},
+		{
+			flag: "read-timeout", env: envReadTimeout, what: "таймаут чтения запроса",
+			usage: fmt.Sprintf("Таймаут чтения запроса, 0 - без ограничения (server.read_timeout, default: %s)",
+				defaultReadTimeout),
+			apply: durationSetting(func(c *config) *time.Duration { return &c.Server.ReadTimeout }),
+		},
+		{
+			flag: "write-timeout", env: envWriteTimeout, what: "таймаут записи ответа",
+			usage: fmt.Sprintf("Таймаут записи ответа, 0 - без ограничения (server.write_timeout, default: %s)",
+				defaultWriteTimeout),
+			apply: durationSetting(func(c *config) *time.Duration { return &c.Server.WriteTimeout }),
+		},
+		{
+			flag: "idle-timeout", env: envIdleTimeout, what: "таймаут простоя соединения",
+			usage: fmt.Sprintf("Таймаут простоя keep-alive соединения (server.idle_timeout, default: %s)",
+				defaultIdleTimeout),
+			apply: durationSetting(func(c *config) *time.Duration { return &c.Server.IdleTimeout }),
+		},
+		{
+			flag: "shutdown-timeout", env: envShutdown, what: "таймаут остановки сервера",
+			usage: fmt.Sprintf("Время ожидания завершения активных запросов при остановке, 0 - без ограничения "+
+				"(server.shutdown_timeout, default: %s)", defaultShutdownTimeout),
+			apply: durationSetting(func(c *config) *time.Duration { return &c.Server.ShutdownTimeout }),
+		},
+		{
+			flag: "metrics-addr", env: envMetricsAddr, what: "адрес сервера метрик",
+			usage: "Адрес отдельного HTTP-сервера метрик Prometheus, например :9090 (server.metrics_addr)",
+			apply: stringSetting(func(c *config) *string { return &c.Server.MetricsAddr }),
+		},
+		{
+			flag: "grpc-address", env: envGRPCAddress, what: "адрес gRPC-сервера",
+			usage: "Адрес gRPC-сервера host:port, например :8444 (server.grpc_address, пусто - выключен)",
+			apply: stringSetting(func(c *config) *string { return &c.Server.GRPCAddress }),
+		},
+		{
+			flag: "admin-token", env: envAdminToken, what: "токен администратора",
+			usage: "Токен администратора для маршрутов /api/admin (server.admin_token)",
+			apply: stringSetting(func(c *config) *string { return &c.Server.AdminToken }),
+		},
+		{
+			flag: "cert-file", env: envTLSCertFile, what: "путь к файлу сертификата",
+			usage: "Путь к файлу TLS-сертификата (tls.cert_file)",
+			apply: stringSetting(func(c *config) *string { return &c.TLS.CertFile }),
+		},
+		{
+			flag: "key-file", env: envTLSKeyFile, what: "путь к файлу ключа",
+			usage: "Путь к файлу TLS-ключа (tls.key_file)",
+			apply: stringSetting(func(c *config) *string { return &c.TLS.KeyFile }),
+		},
+		{
+			flag: "tls-auto-generate", env: envTLSAutoGenerate, what: "признак создания сертификата", isBool: true,
+			usage: "Создать самоподписанный CA и сертификат сервера, если файлов сертификата и ключа нет " +
+				"(tls.auto_generate)",
+			apply: boolSetting(func(c *config) *bool { return &c.TLS.AutoGenerate }),
+		},
+		{
+			flag: "tls-hosts", env: envTLSHosts, what: "список имен хоста",
+			usage: "Имена и IP-адреса через запятую для создаваемого сертификата " +
+				"(tls.hosts, default: " + strings.Join(defaultTLSHosts(), ",") + ")",
+			apply: listSetting(func(c *config) *[]string { return &c.TLS.Hosts }),
+		},
+		{
+			flag: "tls-min-version", env: envTLSMinVersion, what: "минимальная версия TLS",
+			usage: "Минимальная версия TLS: 1.2 или 1.3 (tls.min_version, default: " + tlsutil.Version12 + ")",
+			apply: stringSetting(func(c *config) *string { return &c.TLS.MinVersion }),
+		},
+		{
+			flag: "tls-cipher-suites", env: envTLSCiphers, what: "список наборов шифров",
+			usage: "Наборы шифров TLS 1.2 через запятую, например TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256 " +
+				"(tls.cipher_suites, пусто - наборы Go по умолчанию)",
+			apply: listSetting(func(c *config) *[]string { return &c.TLS.CipherSuites }),
+		},
+		{
+			flag: "tls-reload-interval", env: envTLSReload, what: "период проверки сертификата",
+			usage: fmt.Sprintf("Период проверки файлов сертификата и ключа для перезагрузки без перезапуска, "+
+				"0 - выключить (tls.reload_interval, default: %s)", defaultTLSReloadInterval),
+			apply: durationSetting(func(c *config) *time.Duration { return &c.TLS.ReloadInterval }),
+		},
+		{
+			flag: "database-dsn", env: envDatabaseDSN, what: "DSN",
+			usage: "Строка подключения к базе данных (database.dsn)",
+			apply: stringSetting(func(c *config) *string { return &c.Database.DSN }),
+		},
+		{
+			flag: "db-max-open-conns", env: envDBMaxOpenConns, what: "размер пула соединений с БД",
+			usage: "Максимум открытых соединений с БД, 0 - без ограничения (database.max_open_conns)",
+			apply: intSetting(func(c *config) *int { return &c.Database.MaxOpenConns }),
+		},
+		{
+			flag: "db-max-idle-conns", env: envDBMaxIdleConns, what: "размер пула простаивающих соединений с БД",
+			usage: "Максимум простаивающих соединений с БД (database.max_idle_conns)",
+			apply: intSetting(func(c *config) *int { return &c.Database.MaxIdleConns }),
+		},
+		{
+			flag: "db-conn-max-lifetime", env: envDBConnLifetime, what: "срок жизни соединения с БД",
+			usage: "Максимальное время жизни соединения с БД (database.conn_max_lifetime)",
+			apply: durationSetting(func(c *config) *time.Duration { return &c.Database.ConnMaxLifetime }),
+		},
+		{
+			flag: "db-conn-max-idle-time", env: envDBConnIdleTime, what: "срок простоя соединения с БД",
+			usage: "Максимальное время простоя соединения с БД (database.conn_max_idle_time)",
+			apply: durationSetting(func(c *config) *time.Duration { return &c.Database.ConnMaxIdleTime }),
+		},
+		{
+			flag: "db-timeout", env: envDBTimeout, what: "таймаут операций с БД",
+			usage: fmt.Sprintf("Предельное время операции с БД, 0 - без ограничения (database.timeout, default: %s)",
+				defaultDBTimeout),
+			apply: durationSetting(func(c *config) *time.Duration { return &c.Database.Timeout }),
+		},
+		{
+			flag: "auto-migrate", env: envAutoMigrate, what: "признак применения миграций", isBool: true,
+			usage: "Применять миграции схемы БД при запуске (database.auto_migrate)",
+			apply: boolSetting(func(c *config) *bool { return &c.Database.AutoMigrate }),
+		},
+		{
+			flag: "storage-backend", env: envStorageBackend, what: "тип хранилища",
+			usage: "Тип хранилища объектов (storage.backend, default: " + storageBackendMinio + ")",
+			apply: stringSetting(func(c *config) *string { return &c.Storage.Backend }),
+		},
+		{
+			flag: "minio-endpoint", env: envMinioEndpoint, what: "адрес хранилища",
+			usage: "Адрес MinIO/S3 (storage.endpoint, default: " + defaultMinioEndpoint + ")",
+			apply: stringSetting(func(c *config) *string { return &c.Storage.Endpoint }),
+		},
+		{
+			flag: "minio-user", env: envMinioUser, what: "ключ доступа к хранилищу",
+			usage: "Ключ доступа MinIO/S3 (storage.access_key)",
+			apply: stringSetting(func(c *config) *string { return &c.Storage.AccessKey }),
+		},
+		{
+			flag: "minio-password", env: envMinioPassword, what: "секретный ключ хранилища",
+			usage: "Секретный ключ MinIO/S3 (storage.secret_key)",
+			apply: stringSetting(func(c *config) *string { return &c.Storage.SecretKey }),
+		},
+		{
+			flag: "minio-bucket", env: envMinioBucket, what: "бакет",
+			usage: "Бакет для файлов хранилищ (storage.bucket, default: " + defaultMinioBucket + ")",
+			apply: stringSetting(func(c *config) *string { return &c.Storage.Bucket }),
+		},
+		{
+			flag: "minio-region", env: envMinioRegion, what: "регион хранилища",
+			usage: "Регион S3 (storage.region)",
+			apply: stringSetting(func(c *config) *string { return &c.Storage.Region }),
+		},
+		{
+			flag: "minio-use-ssl", env: envMinioUseSSL, what: "признак использования TLS для хранилища", isBool: true,
+			usage: "Подключаться к MinIO/S3 по HTTPS (storage.use_ssl)",
+			apply: boolSetting(func(c *config) *bool { return &c.Storage.UseSSL }),
+		},
+		{
+			flag: "storage-timeout", env: envStorageTime, what: "таймаут операций с хранилищем",
+			usage: fmt.Sprintf("Предельное время чтения или записи объекта в хранилище, 0 - без ограничения "+
+				"(storage.timeout, default: %s)", defaultStorageTimeout),
+			apply: durationSetting(func(c *config) *time.Duration { return &c.Storage.Timeout }),
+		},
+		{
+			flag: "storage-master-key-file", env: envMasterKey, what: "путь к файлу мастер-ключей",
+			usage: "Путь к файлу мастер-ключей для шифрования объектов в хранилище (storage.master_key_file)",
+			apply: stringSetting(func(c *config) *string { return &c.Storage.MasterKeyFile }),
+		},
+		{
+			flag: "storage-scrub-interval", env: envScrubPeriod, what: "интервал проверки целостности",
+			usage: fmt.Sprintf("Интервал фоновой проверки целостности объектов, 0 - выключить "+
+				"(storage.scrub_interval, default: %s)", defaultScrubInterval),
+			apply: durationSetting(func(c *config) *time.Duration { return &c.Storage.ScrubInterval }),
+		},
+		{
+			flag: "jwt-secret", env: envJWTSecret, what: "ключ подписи токенов",
+			usage: "Ключ подписи JWT (auth.jwt_secret)",
+			apply: stringSetting(func(c *config) *string { return &c.Auth.JWTSecret }),
+		},
+		{
+			flag: "token-ttl", env: envTokenTTL, what: "срок действия токена",
+			usage: "Время жизни JWT (auth.token_ttl, default: 24h)",
+			apply: durationSetting(func(c *config) *time.Duration { return &c.Auth.TokenTTL }),
+		},
+		{
+			flag: "max-upload-size", env: envMaxUploadSize, what: "максимальный размер загрузки",
+			usage: "Максимальный размер загружаемого файла в байтах, 0 - без ограничения (limits.max_upload_size)",
+			apply: func(c *config, value string) error {
+				n, err := strconv.ParseInt(value, 10, 64)
+				if err != nil || n < 0 {
+					return fmt.Errorf("ожидается неотрицательное число байт")
+				}
+				c.Limits.MaxUploadSize = n
+				return nil
+			},
+		},
+		{
+			flag: "webhook-allowed-networks", env: envWebhookNets, what: "список сетей для вебхуков",
+			usage: "Внутренние сети в формате CIDR через запятую, куда разрешено отправлять вебхуки " +
+				"(webhooks.allowed_networks, по умолчанию loopback, link-local и частные сети запрещены)",
+			apply: listSetting(func(c *config) *[]string { return &c.Webhooks.AllowedNetworks }),
+		},
+	}
 }
 
-// parseFlags разбирает флаги и переменные окружения, возвращает config или ошибку.
+// parseFlags загружает конфигурацию из файла, переменных окружения и флагов командной строки
+// и проверяет ее. Возвращает config или ошибку.
 func parseFlags() (*config, error) {
-	cfg := &config{}
-
-	// Определяем флаги
-	flag.StringVar(&cfg.Port, "port", "",
-		fmt.Sprintf("Порт для запуска HTTPS-сервера (env: %s, default: %s)", envServerPort, defaultServerPort))
-	flag.StringVar(&cfg.CertFile, "cert-file", "",
-		fmt.Sprintf("Путь к файлу TLS-сертификата (env: %s)", envTLSCertFile))
-	flag.StringVar(&cfg.KeyFile, "key-file", "",
-		fmt.Sprintf("Путь к файлу TLS-ключа (env: %s)", envTLSKeyFile))
-	flag.StringVar(&cfg.DatabaseDSN, "database-dsn", "",
-		fmt.Sprintf("Строка подключения к базе данных (env: %s)", envDatabaseDSN))
-
-	// Парсим флаги
-	flag.Parse()
-
-	// Применяем переменные окружения, если флаги не заданы
-	if cfg.Port == "" {
-		if value, ok := os.LookupEnv(envServerPort); ok {
-			cfg.Port = value
-		} else {
-			cfg.Port = defaultServerPort
-		}
+	return loadConfig(flag.CommandLine, os.Args[1:])
+}
+
+// loadConfig читает конфигурацию (см. readConfig) и проверяет ее.
+func loadConfig(fs *flag.FlagSet, args []string) (*config, error) {
+	cfg, err := readConfig(fs, args)
+	if err != nil {
+		return nil, err
+	}
+	if err = cfg.validate(); err != nil {
+		return nil, err
 	}
-	if cfg.CertFile == "" {
-		if value, ok := os.LookupEnv(envTLSCertFile); ok {
-			cfg.CertFile = value
+	return cfg, nil
+}
+
+// readConfig регистрирует флаги в fs, разбирает args и собирает конфигурацию: значения по умолчанию,
+// затем файл (-config или GOPHKEEPER_CONFIG), переменные окружения и флаги. Конфигурация не проверяется.
+func readConfig(fs *flag.FlagSet, args []string) (*config, error) {
+	all := settings()
+	values := make([]*settingFlag, len(all))
+	for i, s := range all {
+		values[i] = &settingFlag{isBool: s.isBool}
+		fs.Var(values[i], s.flag, fmt.Sprintf("%s (env: %s)", s.usage, s.env))
+	}
+	configFile := fs.String(flagConfigFileName, "",
+		fmt.Sprintf("Путь к файлу конфигурации YAML или TOML (env: %s)", envConfigFile))
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	cfg := defaultConfig()
+	if *configFile == "" {
+		*configFile = os.Getenv(envConfigFile)
+	}
+	if *configFile != "" {
+		if err := cfg.loadFile(*configFile); err != nil {
+			return nil, err
 		}
 	}
-	if cfg.KeyFile == "" {
-		if value, ok := os.LookupEnv(envTLSKeyFile); ok {
-			cfg.KeyFile = value
+
+	// Переменные окружения переопределяют файл, флаги - переменные окружения
+	for _, s := range all {
+		value, ok := os.LookupEnv(s.env)
+		if !ok || value == "" {
+			continue
+		}
+		if err := s.apply(cfg, value); err != nil {
+			return nil, fmt.Errorf("неверный %s '%s' (%s): %w", s.what, value, s.env, err)
 		}
 	}
-	if cfg.DatabaseDSN == "" {
-		if value, ok := os.LookupEnv(envDatabaseDSN); ok {
-			cfg.DatabaseDSN = value
+	for i, s := range all {
+		if !values[i].set {
+			continue
+		}
+		if err := s.apply(cfg, values[i].value); err != nil {
+			return nil, fmt.Errorf("неверный %s '%s' (--%s): %w", s.what, values[i].value, s.flag, err)
 		}
 	}
+	return cfg, nil
+}
 
-	// Проверяем обязательные параметры
-	if cfg.CertFile == "" {
-		return nil, errors.New("не указан путь к файлу сертификата (--cert-file или " + envTLSCertFile + ")")
+// settingFlag хранит значение флага до применения к конфигурации.
+type settingFlag struct {
+	value  string
+	set    bool
+	isBool bool
+}
+
+// String возвращает значение флага.
+func (f *settingFlag) String() string {
+	if f == nil {
+		return ""
+	}
+	return f.value
+}
+
+// Set запоминает значение флага.
+func (f *settingFlag) Set(value string) error {
+	f.value = value
+	f.set = true
+	return nil
+}
+
+// IsBoolFlag позволяет указывать логические флаги без значения.
+func (f *settingFlag) IsBoolFlag() bool {
+	return f.isBool
+}
+
+// stringSetting присваивает значение строковому полю.
+func stringSetting(field func(*config) *string) func(*config, string) error {
+	return func(c *config, value string) error {
+		*field(c) = value
+		return nil
+	}
+}
+
+// listSetting разбирает список значений через запятую (пустые элементы пропускаются).
+func listSetting(field func(*config) *[]string) func(*config, string) error {
+	return func(c *config, value string) error {
+		var list []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		*field(c) = list
+		return nil
 	}
-	if cfg.KeyFile == "" {
-		return nil, errors.New("не указан путь к файлу ключа (--key-file или " + envTLSKeyFile + ")")
+}
+
+// durationSetting разбирает неотрицательную длительность.
+func durationSetting(field func(*config) *time.Duration) func(*config, string) error {
+	return func(c *config, value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 {
+			return fmt.Errorf("ожидается неотрицательная длительность, например 30s или 5m")
+		}
+		*field(c) = d
+		return nil
 	}
-	if cfg.DatabaseDSN == "" {
-		return nil, errors.New("не указана строка подключения к БД (--database-dsn или " + envDatabaseDSN + ")")
+}
+
+// intSetting разбирает неотрицательное целое число.
+func intSetting(field func(*config) *int) func(*config, string) error {
+	return func(c *config, value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return fmt.Errorf("ожидается неотрицательное целое число")
+		}
+		*field(c) = n
+		return nil
 	}
+}
 
-	return cfg, nil
+// boolSetting разбирает логическое значение.
+func boolSetting(field func(*config) *bool) func(*config, string) error {
+	return func(c *config, value string) error {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("ожидается true или false")
+		}
+		*field(c) = b
+		return nil
+	}
 }
@@ -4,6 +4,7 @@ import (
 	"flag"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -43,10 +44,10 @@ func TestParseFlags(t *testing.T) {
 		os.Args = []string{"cmd", "-port=8080", "-cert-file=cert.pem", "-key-file=key.pem", "-database-dsn=postgres://..."}
 		cfg, err := parseFlags()
 		require.NoError(t, err)
-		assert.Equal(t, "8080", cfg.Port)
-		assert.Equal(t, "cert.pem", cfg.CertFile)
-		assert.Equal(t, "key.pem", cfg.KeyFile)
-		assert.Equal(t, "postgres://...", cfg.DatabaseDSN)
+		assert.Equal(t, ":8080", cfg.Server.Address)
+		assert.Equal(t, "cert.pem", cfg.TLS.CertFile)
+		assert.Equal(t, "key.pem", cfg.TLS.KeyFile)
+		assert.Equal(t, "postgres://...", cfg.Database.DSN)
 	})
 
 	t.Run("Все параметры из переменных окружения", func(t *testing.T) {
@@ -67,10 +68,10 @@ func TestParseFlags(t *testing.T) {
 
 		cfg, err := parseFlags()
 		require.NoError(t, err)
-		assert.Equal(t, "9090", cfg.Port)
-		assert.Equal(t, "env_cert.pem", cfg.CertFile)
-		assert.Equal(t, "env_key.pem", cfg.KeyFile)
-		assert.Equal(t, "env_postgres://...", cfg.DatabaseDSN)
+		assert.Equal(t, ":9090", cfg.Server.Address)
+		assert.Equal(t, "env_cert.pem", cfg.TLS.CertFile)
+		assert.Equal(t, "env_key.pem", cfg.TLS.KeyFile)
+		assert.Equal(t, "env_postgres://...", cfg.Database.DSN)
 	})
 
 	t.Run("Порт по умолчанию", func(t *testing.T) {
@@ -80,7 +81,7 @@ func TestParseFlags(t *testing.T) {
 
 		cfg, err := parseFlags()
 		require.NoError(t, err)
-		assert.Equal(t, defaultServerPort, cfg.Port) // Проверяем порт по умолчанию
+		assert.Equal(t, ":"+defaultServerPort, cfg.Server.Address) // Проверяем порт по умолчанию
 	})
 
 	t.Run("Отсутствует обязательный параметр cert-file", func(t *testing.T) {
@@ -137,9 +138,143 @@ func TestParseFlags(t *testing.T) {
 		}
 		cfg, err := parseFlags()
 		require.NoError(t, err)
-		assert.Equal(t, "8080", cfg.Port)
-		assert.Equal(t, "flag_cert.pem", cfg.CertFile)
-		assert.Equal(t, "flag_key.pem", cfg.KeyFile)
-		assert.Equal(t, "flag_postgres://...", cfg.DatabaseDSN)
+		assert.Equal(t, ":8080", cfg.Server.Address)
+		assert.Equal(t, "flag_cert.pem", cfg.TLS.CertFile)
+		assert.Equal(t, "flag_key.pem", cfg.TLS.KeyFile)
+		assert.Equal(t, "flag_postgres://...", cfg.Database.DSN)
+	})
+
+	t.Run("Интервал проверки целостности и токен администратора", func(t *testing.T) {
+		resetFlags()
+		defer func() { os.Args = originalArgs }()
+		os.Setenv(envAdminToken, "env-admin-token")
+		defer os.Unsetenv(envAdminToken)
+
+		os.Args = []string{
+			"cmd", "-cert-file=cert.pem", "-key-file=key.pem", "-database-dsn=postgres://...",
+			"-storage-scrub-interval=6h",
+		}
+		cfg, err := parseFlags()
+		require.NoError(t, err)
+		assert.Equal(t, 6*time.Hour, cfg.Storage.ScrubInterval)
+		assert.Equal(t, "env-admin-token", cfg.Server.AdminToken)
+	})
+
+	t.Run("Интервал проверки целостности по умолчанию", func(t *testing.T) {
+		resetFlags()
+		defer func() { os.Args = originalArgs }()
+		os.Args = []string{"cmd", "-cert-file=cert.pem", "-key-file=key.pem", "-database-dsn=postgres://..."}
+
+		cfg, err := parseFlags()
+		require.NoError(t, err)
+		assert.Equal(t, defaultScrubInterval, cfg.Storage.ScrubInterval)
+		assert.Empty(t, cfg.Server.AdminToken)
+		assert.Equal(t, defaultDBTimeout, cfg.Database.Timeout)
+		assert.Equal(t, defaultStorageTimeout, cfg.Storage.Timeout)
+		assert.Equal(t, defaultShutdownTimeout, cfg.Server.ShutdownTimeout)
+	})
+
+	t.Run("Таймауты операций", func(t *testing.T) {
+		resetFlags()
+		defer func() { os.Args = originalArgs }()
+		os.Setenv(envStorageTime, "30m")
+		defer os.Unsetenv(envStorageTime)
+		os.Setenv(envShutdown, "1m")
+		defer os.Unsetenv(envShutdown)
+
+		os.Args = []string{
+			"cmd", "-cert-file=cert.pem", "-key-file=key.pem", "-database-dsn=postgres://...",
+			"-db-timeout=2s",
+		}
+		cfg, err := parseFlags()
+		require.NoError(t, err)
+		assert.Equal(t, 2*time.Second, cfg.Database.Timeout)
+		assert.Equal(t, 30*time.Minute, cfg.Storage.Timeout)
+		assert.Equal(t, time.Minute, cfg.Server.ShutdownTimeout)
+	})
+
+	t.Run("Автоматическое применение миграций", func(t *testing.T) {
+		resetFlags()
+		defer func() { os.Args = originalArgs }()
+		os.Args = []string{"cmd", "-cert-file=cert.pem", "-key-file=key.pem", "-database-dsn=postgres://..."}
+
+		cfg, err := parseFlags()
+		require.NoError(t, err)
+		assert.False(t, cfg.Database.AutoMigrate)
+
+		resetFlags()
+		os.Setenv(envAutoMigrate, "true")
+		defer os.Unsetenv(envAutoMigrate)
+		cfg, err = parseFlags()
+		require.NoError(t, err)
+		assert.True(t, cfg.Database.AutoMigrate)
+
+		resetFlags()
+		os.Setenv(envAutoMigrate, "yes")
+		_, err = parseFlags()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), envAutoMigrate)
+	})
+
+	t.Run("Адрес сервера метрик", func(t *testing.T) {
+		resetFlags()
+		defer func() { os.Args = originalArgs }()
+		os.Setenv(envMetricsAddr, ":9100")
+		defer os.Unsetenv(envMetricsAddr)
+
+		os.Args = []string{"cmd", "-cert-file=cert.pem", "-key-file=key.pem", "-database-dsn=postgres://..."}
+		cfg, err := parseFlags()
+		require.NoError(t, err)
+		assert.Equal(t, ":9100", cfg.Server.MetricsAddr)
+	})
+
+	t.Run("Адрес gRPC-сервера", func(t *testing.T) {
+		resetFlags()
+		defer func() { os.Args = originalArgs }()
+		os.Args = []string{
+			"cmd", "-cert-file=cert.pem", "-key-file=key.pem", "-database-dsn=postgres://...", "-grpc-address=:8444",
+		}
+
+		cfg, err := parseFlags()
+		require.NoError(t, err)
+		assert.Equal(t, ":8444", cfg.Server.GRPCAddress)
+	})
+
+	t.Run("Неверный адрес gRPC-сервера", func(t *testing.T) {
+		resetFlags()
+		defer func() { os.Args = originalArgs }()
+		os.Setenv(envGRPCAddress, "localhost")
+		defer os.Unsetenv(envGRPCAddress)
+		os.Args = []string{"cmd", "-cert-file=cert.pem", "-key-file=key.pem", "-database-dsn=postgres://..."}
+
+		_, err := parseFlags()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "server.grpc_address")
+	})
+
+	t.Run("Неверный таймаут операций с БД", func(t *testing.T) {
+		resetFlags()
+		defer func() { os.Args = originalArgs }()
+		os.Args = []string{
+			"cmd", "-cert-file=cert.pem", "-key-file=key.pem", "-database-dsn=postgres://...",
+			"-db-timeout=abc",
+		}
+
+		_, err := parseFlags()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "неверный таймаут операций с БД")
+	})
+
+	t.Run("Неверный интервал проверки целостности", func(t *testing.T) {
+		resetFlags()
+		defer func() { os.Args = originalArgs }()
+		os.Args = []string{
+			"cmd", "-cert-file=cert.pem", "-key-file=key.pem", "-database-dsn=postgres://...",
+			"-storage-scrub-interval=-1h",
+		}
+
+		_, err := parseFlags()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "неверный интервал проверки целостности")
 	})
 }
@@ -1,39 +1,35 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/jmoiron/sqlx" // Добавляем импорт sqlx
 	_ "github.com/lib/pq"     // Драйвер PostgreSQL
+	"github.com/maynagashev/gophkeeper/models"
+	"github.com/maynagashev/gophkeeper/server/internal/events"
+	"github.com/maynagashev/gophkeeper/server/internal/grpcserver"
 	"github.com/maynagashev/gophkeeper/server/internal/handlers"
+	"github.com/maynagashev/gophkeeper/server/internal/metrics"
 	appmiddleware "github.com/maynagashev/gophkeeper/server/internal/middleware"
+	"github.com/maynagashev/gophkeeper/server/internal/openapi"
 	"github.com/maynagashev/gophkeeper/server/internal/repository"
 	"github.com/maynagashev/gophkeeper/server/internal/services"
 	"github.com/maynagashev/gophkeeper/server/internal/storage" // Добавляем импорт storage
-)
-
-const (
-	defaultReadTimeout  = 10 * time.Second
-	defaultWriteTimeout = 10 * time.Second
-	defaultIdleTimeout  = 30 * time.Second
-
-	// Переменные окружения для MinIO (значения по умолчанию из docker-compose).
-	envMinioEndpoint     = "MINIO_ENDPOINT"
-	envMinioUser         = "MINIO_USER"
-	envMinioPassword     = "MINIO_PASSWORD"
-	envMinioBucket       = "MINIO_BUCKET"
-	defaultMinioEndpoint = "localhost:9000"
-	defaultMinioUser     = "minioadmin"
-	defaultMinioPassword = "minioadmin"
-	defaultMinioBucket   = "gophkeeper-vaults"
-	minioUseSSL          = false // Для локальной разработки
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 // Переменная для функции создания соединения с БД, для возможности мокирования в тестах.
@@ -41,43 +37,135 @@ var newPostgresDB = repository.NewPostgresDB //nolint:gochecknoglobals // Исп
 
 // Структура для хранения инициализированных зависимостей.
 type dependencies struct {
-	db           *sqlx.DB            // Используем тип *sqlx.DB
-	fileStorage  storage.FileStorage // Используем интерфейс
-	authHandler  *handlers.AuthHandler
-	vaultHandler *handlers.VaultHandler
+	db               *sqlx.DB            // Используем тип *sqlx.DB
+	fileStorage      storage.FileStorage // Используем интерфейс
+	encryptedStorage *storage.EncryptedStorage
+	vaultVersionRepo repository.VaultVersionRepository
+	authHandler      *handlers.AuthHandler
+	vaultHandler     *handlers.VaultHandler
+	webdavHandler    *handlers.WebDAVHandler
+	eventBroker      *events.Broker
+	eventsHandler    *handlers.EventsHandler
+	integrityService services.IntegrityService
+	adminHandler     *handlers.AdminHandler
+	webhookService   services.WebhookService
+	webhookHandler   *handlers.WebhookHandler
+	emergencyHandler *handlers.EmergencyAccessHandler
+	healthHandler    *handlers.HealthHandler
+	metrics          *metrics.Metrics
+	grpcService      *grpcserver.Server
+	accounts         appmiddleware.AccountLookup
+}
+
+// routeHandlers объединяет обработчики, маршруты которых регистрирует setupRouter.
+type routeHandlers struct {
+	auth     *handlers.AuthHandler
+	vault    *handlers.VaultHandler
+	events   *handlers.EventsHandler
+	webdav   *handlers.WebDAVHandler // Может быть nil: каталог WebDAV не регистрируется
+	webhooks *handlers.WebhookHandler
+	// emergency - экстренный доступ доверенных контактов, в том числе чтение хранилища владельца.
+	emergency *handlers.EmergencyAccessHandler
+	health    *handlers.HealthHandler
+	admin     *handlers.AdminHandler // Может быть nil: служебные маршруты не регистрируются
+	metrics   *metrics.Metrics       // Может быть nil: метрики HTTP-запросов не собираются
+	// metricsEndpoint отдает метрики на /metrics основного порта (nil - метрики на отдельном порту или выключены).
+	metricsEndpoint http.Handler
 }
 
 // Функция для запуска HTTP сервера (для удобства мокирования в тестах).
+// Сервер работает до отмены ctx, после чего дожидается завершения активных запросов.
+// onShutdown (если задан) вызывается в начале остановки, например, чтобы закрыть долгоживущие потоки событий.
 var startHTTPServer = //nolint:gochecknoglobals // Используется для мокирования в тестах
-func(cfg *config, handler http.Handler) error {
+func(ctx context.Context, cfg *config, tlsConfig *tls.Config, handler http.Handler, onShutdown func()) error {
 	server := &http.Server{
-		Addr:         fmt.Sprintf(":%s", cfg.Port),
+		Addr:         cfg.Server.Address,
 		Handler:      handler,
-		ReadTimeout:  defaultReadTimeout,
-		WriteTimeout: defaultWriteTimeout,
-		IdleTimeout:  defaultIdleTimeout,
+		TLSConfig:    tlsConfig,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
+	}
+	if onShutdown != nil {
+		server.RegisterOnShutdown(onShutdown)
 	}
 
-	log.Printf("Запуск HTTPS-сервера на порту %s...", cfg.Port)
-	log.Printf("Используется сертификат: %s", cfg.CertFile)
-	log.Printf("Используется ключ: %s", cfg.KeyFile)
+	log.Printf("Запуск HTTPS-сервера на %s...", cfg.Server.Address)
 
-	// Запускаем сервер с TLS
-	if err := server.ListenAndServeTLS(cfg.CertFile, cfg.KeyFile); err != nil && !errors.Is(err, http.ErrServerClosed) {
-		return fmt.Errorf("ошибка запуска HTTPS-сервера: %w", err)
+	// Запускаем сервер с TLS: сертификат берется из tlsConfig.GetCertificate
+	err := serveUntilShutdown(ctx, server, func() error {
+		return server.ListenAndServeTLS("", "")
+	}, cfg.Server.ShutdownTimeout)
+	if err != nil {
+		return fmt.Errorf("ошибка работы HTTPS-сервера: %w", err)
 	}
 	return nil
 }
 
-// main - точка входа. Вызывает run и обрабатывает ошибку.
+// serveUntilShutdown выполняет serve (ListenAndServe* сервера) до отмены ctx, затем останавливает сервер:
+// новые соединения больше не принимаются, а активные запросы (например, загрузки хранилищ)
+// дорабатывают не дольше timeout (0 - без ограничения). Соединения, не успевшие завершиться, разрываются.
+func serveUntilShutdown(ctx context.Context, server *http.Server, serve func() error, timeout time.Duration) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- serve()
+	}()
+
+	select {
+	case err := <-serveErr:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Printf("Остановка сервера %s: ожидание завершения активных запросов (таймаут %s)...", server.Addr, timeout)
+	shutdownCtx, cancel := context.WithCancel(context.Background())
+	if timeout > 0 {
+		shutdownCtx, cancel = context.WithTimeout(context.Background(), timeout)
+	}
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		if closeErr := server.Close(); closeErr != nil {
+			log.Printf("Ошибка принудительного закрытия соединений: %v", closeErr)
+		}
+		return fmt.Errorf("активные запросы не завершились за %s: %w", timeout, err)
+	}
+	if err := <-serveErr; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	log.Printf("Сервер %s остановлен.", server.Addr)
+	return nil
+}
+
+// main - точка входа. Вызывает run (или подкоманду migrate, config, admin) и обрабатывает ошибку.
 func main() {
-	if err := run(); err != nil {
+	var err error
+	switch {
+	case len(os.Args) > 1 && os.Args[1] == "migrate":
+		err = runMigrateCommand(os.Args[2:], os.Stdout)
+	case len(os.Args) > 1 && os.Args[1] == "config":
+		err = runConfigCommand(os.Args[2:], os.Stdout)
+	case len(os.Args) > 1 && os.Args[1] == "admin":
+		err = runAdminCommand(os.Args[2:], os.Stdin, os.Stdout)
+	case len(os.Args) > 1 && os.Args[1] == "backup":
+		err = runBackupCommand(os.Args[2:], os.Stdout)
+	case len(os.Args) > 1 && os.Args[1] == "restore":
+		err = runRestoreCommand(os.Args[2:], os.Stdout)
+	default:
+		err = run()
+	}
+	if err != nil {
 		log.Printf("Ошибка выполнения сервера: %v", err) // Используем Printf
 		os.Exit(1)                                       // Выход с кодом ошибки
 	}
 }
 
 // run содержит основную логику запуска сервера и возвращает ошибку.
+// Сервер работает до получения SIGINT или SIGTERM, после чего дожидается завершения активных запросов,
+// останавливает фоновые задачи и закрывает соединение с БД.
 func run() error {
 	log.Println("Запуск сервера GophKeeper...")
 
@@ -89,6 +177,21 @@ func run() error {
 		// Пока оставляем так, но тест должен будет мокировать parseFlags.
 		log.Fatalf("Ошибка конфигурации сервера: %v", err)
 	}
+	if cfg.Auth.JWTSecret == services.DefaultJWTSecret {
+		log.Printf("ВНИМАНИЕ: используется ключ подписи токенов по умолчанию, задайте auth.jwt_secret (%s)",
+			envJWTSecret)
+	}
+
+	// TLS-сертификат загружается до подключения к БД, чтобы ошибка в нем обнаруживалась сразу
+	tlsConfig, tlsReloader, err := setupTLS(cfg)
+	if err != nil {
+		return fmt.Errorf("ошибка настройки TLS: %w", err)
+	}
+
+	// Проверка версии схемы БД (и применение миграций при -auto-migrate)
+	if err = migrateSchema(cfg); err != nil {
+		return fmt.Errorf("ошибка подготовки схемы БД: %w", err)
+	}
 
 	// Инициализация зависимостей
 	deps, err := setupDependencies(cfg)
@@ -105,12 +208,108 @@ func run() error {
 		}
 	}()
 
+	// Сигнал завершения останавливает прием запросов; фоновые задачи отменяются отдельно,
+	// после того как активные запросы завершатся (они еще могут публиковать события).
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	jobsCtx, cancelJobs := context.WithCancel(context.Background())
+	var jobs sync.WaitGroup
+	// Выполняется до закрытия БД (defer выполняются в обратном порядке)
+	defer func() {
+		cancelJobs()
+		jobs.Wait()
+		log.Println("Фоновые задачи остановлены.")
+	}()
+	startJob := func(job func(ctx context.Context)) {
+		jobs.Add(1)
+		go func() {
+			defer jobs.Done()
+			job(jobsCtx)
+		}()
+	}
+
+	// Перезагрузка сертификата при изменении файлов (например, после продления)
+	if cfg.TLS.ReloadInterval > 0 {
+		startJob(func(ctx context.Context) {
+			tlsReloader.Watch(ctx, cfg.TLS.ReloadInterval)
+		})
+	}
+
+	// Переоборачиваем ключи объектов, если мастер-ключ был ротирован
+	if deps.encryptedStorage != nil {
+		startJob(func(ctx context.Context) {
+			rewrapStorageKeys(ctx, deps.vaultVersionRepo, deps.encryptedStorage)
+		})
+	}
+
+	// Получение событий об изменении хранилищ от всех реплик сервера (Postgres LISTEN/NOTIFY)
+	startJob(func(ctx context.Context) {
+		if listenErr := deps.eventBroker.Listen(ctx, cfg.Database.DSN); listenErr != nil {
+			log.Printf("Ошибка подписки на события хранилищ: %v", listenErr)
+		}
+	})
+
+	// Отправка событий на вебхуки пользователей из исходящей очереди
+	startJob(deps.webhookService.Run)
+
+	// Удаление временных файлов WebDAV с истекшим сроком хранения
+	startJob(deps.webdavHandler.Run)
+
+	// Фоновая проверка целостности объектов по сохраненным контрольным суммам
+	if cfg.Storage.ScrubInterval > 0 {
+		startJob(deps.integrityService.Run)
+	} else {
+		log.Println("Фоновая проверка целостности объектов выключена.")
+	}
+
+	// Метрики отдаются на отдельном порту, если он задан, иначе - на /metrics основного порта
+	var metricsEndpoint http.Handler
+	if cfg.Server.MetricsAddr != "" {
+		startJob(func(ctx context.Context) {
+			if metricsErr := serveMetrics(ctx, cfg.Server.MetricsAddr, deps.metrics.Handler()); metricsErr != nil {
+				log.Printf("Ошибка сервера метрик: %v", metricsErr)
+			}
+		})
+	} else {
+		metricsEndpoint = deps.metrics.Handler()
+	}
+
+	// gRPC API на отдельном порту (если задан адрес) работает до сигнала завершения, как и HTTPS-сервер
+	if cfg.Server.GRPCAddress != "" {
+		startJob(func(jobCtx context.Context) {
+			// Останавливаем и при выходе из run без сигнала (например, если HTTPS-сервер не запустился)
+			grpcCtx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			defer context.AfterFunc(jobCtx, cancel)()
+			if grpcErr := startGRPCServer(grpcCtx, cfg, tlsConfig, deps.grpcService, deps.accounts); grpcErr != nil {
+				log.Printf("Ошибка gRPC-сервера: %v", grpcErr)
+			}
+		})
+	}
+
 	// Настройка роутера
-	r := setupRouter(deps.authHandler, deps.vaultHandler)
+	r := setupRouter(routeHandlers{
+		auth:            deps.authHandler,
+		vault:           deps.vaultHandler,
+		events:          deps.eventsHandler,
+		webdav:          deps.webdavHandler,
+		webhooks:        deps.webhookHandler,
+		emergency:       deps.emergencyHandler,
+		health:          deps.healthHandler,
+		admin:           deps.adminHandler,
+		metrics:         deps.metrics,
+		metricsEndpoint: metricsEndpoint,
+	}, routerOptions{
+		adminToken:    cfg.Server.AdminToken,
+		jwtSecret:     cfg.Auth.JWTSecret,
+		accounts:      deps.accounts,
+		maxUploadSize: cfg.Limits.MaxUploadSize,
+	})
 
 	// --- Запуск сервера --- //
 	// Используем переменную startHTTPServer вместо прямого кода запуска
-	if err = startHTTPServer(cfg, r); err != nil {
+	// Потоки событий (SSE) не завершаются сами, поэтому закрываем их в начале остановки
+	if err = startHTTPServer(ctx, cfg, tlsConfig, r, deps.eventBroker.CloseSubscribers); err != nil {
 		return err // Возвращаем ошибку от startHTTPServer
 	}
 	return nil // Успешное завершение run()
@@ -122,51 +321,137 @@ func setupDependencies(cfg *config) (*dependencies, error) {
 	var err error
 
 	// 1. Подключение к БД
-	deps.db, err = newPostgresDB(cfg.DatabaseDSN)
+	deps.db, err = newPostgresDB(cfg.Database.DSN, cfg.poolConfig())
 	if err != nil {
 		return nil, fmt.Errorf("ошибка инициализации БД: %w", err)
 	}
 	log.Println("Соединение с БД успешно установлено.")
+	deps.metrics = metrics.New()
+	deps.metrics.RegisterDBStats(deps.db.DB)
 
-	// 2. Инициализация клиента MinIO
-	minioCfg := storage.MinioConfig{
-		Endpoint:        getEnv(envMinioEndpoint, defaultMinioEndpoint),
-		AccessKeyID:     getEnv(envMinioUser, defaultMinioUser),
-		SecretAccessKey: getEnv(envMinioPassword, defaultMinioPassword),
-		UseSSL:          minioUseSSL,
-		BucketName:      getEnv(envMinioBucket, defaultMinioBucket),
-	}
-	// storage.NewMinioClient возвращает *storage.MinioClient, который реализует storage.FileStorage
-	deps.fileStorage, err = storage.NewMinioClient(minioCfg)
+	// 2. Инициализация хранилища файлов (MinIO, при заданном мастер-ключе - с шифрованием объектов)
+	deps.fileStorage, deps.encryptedStorage, err = newFileStorage(cfg)
 	if err != nil {
 		// Закрываем соединение с БД перед выходом
 		if dbCloseErr := deps.db.Close(); dbCloseErr != nil {
-			log.Printf("Ошибка закрытия соединения с БД при ошибке MinIO: %v", dbCloseErr)
+			log.Printf("Ошибка закрытия соединения с БД при ошибке инициализации хранилища: %v", dbCloseErr)
 		}
-		return nil, fmt.Errorf("ошибка инициализации клиента MinIO: %w", err)
+		return nil, err
 	}
 
 	// 3. Создание репозиториев
 	// Передаем *sqlx.DB в конструкторы репозиториев
 	userRepo := repository.NewPostgresUserRepository(deps.db)
+	deps.accounts = accountLookup(userRepo)
 	vaultRepo := repository.NewPostgresVaultRepository(deps.db)
 	vaultVersionRepo := repository.NewPostgresVaultVersionRepository(deps.db)
+	deps.vaultVersionRepo = vaultVersionRepo
+	webhookRepo := repository.NewPostgresWebhookRepository(deps.db)
+	emergencyRepo := repository.NewPostgresEmergencyAccessRepository(deps.db)
 
 	// 4. Создание сервисов
-	authService := services.NewAuthService(userRepo)
-	// Передаем *sql.DB (из поля DB типа *sqlx.DB) в VaultService
-	vaultService := services.NewVaultService(deps.db.DB, vaultRepo, vaultVersionRepo, deps.fileStorage)
+	// События о входе с нового устройства рассылаются только на вебхуки, события хранилища - еще и клиентам
+	// Вебхуки во внутреннюю сеть запрещены, кроме явно разрешенных сетей (защита от SSRF)
+	deps.webhookService = services.NewWebhookService(webhookRepo, nil, cfg.webhookAllowedNetworks())
+	// Таймауты ограничивают операции с БД и хранилищем, даже если клиент не закрыл соединение
+	timeouts := services.Timeouts{DB: cfg.Database.Timeout, Storage: cfg.Storage.Timeout}
+	tokens := services.TokenConfig{Secret: cfg.Auth.JWTSecret, TTL: cfg.Auth.TokenTTL}
+	authService := services.NewAuthService(userRepo, deps.webhookService, tokens, timeouts, deps.metrics)
+	deps.eventBroker = events.NewBroker(deps.db)
+	// Изменения хранилища выполняются в транзакциях (unit of work поверх того же подключения).
+	// Доставки вебхуков ставятся в очередь в этих же транзакциях, клиентам события публикуются после коммита.
+	transactor := repository.NewTransactor(deps.db)
+	vaultService := services.NewVaultService(transactor, vaultRepo, vaultVersionRepo,
+		deps.fileStorage, deps.eventBroker, timeouts, deps.metrics)
+	deps.integrityService = services.NewIntegrityService(vaultVersionRepo, deps.fileStorage, cfg.Storage.ScrubInterval)
+	userAdminService := services.NewUserAdminService(userRepo, vaultRepo, vaultVersionRepo, transactor,
+		deps.fileStorage, deps.integrityService)
+	// Владелец узнает о запросе экстренного доступа, а контакт - об отказе так же, как об изменениях хранилища
+	emergencyService := services.NewEmergencyAccessService(emergencyRepo, userRepo,
+		events.NewMultiPublisher(deps.eventBroker, deps.webhookService))
 
 	// 5. Создание обработчиков
 	deps.authHandler = handlers.NewAuthHandler(authService)
 	deps.vaultHandler = handlers.NewVaultHandler(vaultService)
+	deps.webdavHandler = handlers.NewWebDAVHandler(vaultService, authService, cfg.Auth.JWTSecret, deps.accounts,
+		webdavPrefix)
+	deps.eventsHandler = handlers.NewEventsHandler(deps.eventBroker)
+	deps.adminHandler = handlers.NewAdminHandler(deps.integrityService, userAdminService)
+	deps.webhookHandler = handlers.NewWebhookHandler(deps.webhookService)
+	deps.emergencyHandler = handlers.NewEmergencyAccessHandler(emergencyService)
+	deps.healthHandler = handlers.NewHealthHandler(services.NewHealthService(
+		repository.NewPostgresHealthRepository(deps.db), deps.fileStorage, 0))
+	deps.grpcService = grpcserver.NewServer(authService, vaultService, deps.eventBroker, cfg.Limits.MaxUploadSize)
 
 	return deps, nil
 }
 
+// accountLookup загружает учетную запись владельца токена при проверке каждого запроса,
+// чтобы отключение пользователя и смена роли действовали сразу, а не после истечения токена.
+func accountLookup(userRepo repository.UserRepository) appmiddleware.AccountLookup {
+	return func(ctx context.Context, userID int64) (*models.User, error) {
+		user, err := userRepo.GetUserByID(ctx, userID)
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, fmt.Errorf("%w: %w", appmiddleware.ErrAccountNotFound, err)
+		}
+		return user, err
+	}
+}
+
+// newFileStorage создает клиент MinIO и, если задан файл мастер-ключей, оборачивает его шифрованием объектов.
+// encrypted равен nil, если шифрование выключено.
+func newFileStorage(cfg *config) (storage.FileStorage, *storage.EncryptedStorage, error) {
+	minioCfg := storage.MinioConfig{
+		Endpoint:        cfg.Storage.Endpoint,
+		AccessKeyID:     cfg.Storage.AccessKey,
+		SecretAccessKey: cfg.Storage.SecretKey,
+		UseSSL:          cfg.Storage.UseSSL,
+		BucketName:      cfg.Storage.Bucket,
+		Region:          cfg.Storage.Region,
+	}
+	// storage.NewMinioClient возвращает *storage.MinioClient, который реализует storage.FileStorage
+	minioClient, err := storage.NewMinioClient(minioCfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ошибка инициализации клиента MinIO: %w", err)
+	}
+	if cfg.Storage.MasterKeyFile == "" {
+		return minioClient, nil, nil
+	}
+
+	// Шифрование объектов на стороне сервера (опционально)
+	keyring, err := storage.LoadMasterKeyring(cfg.Storage.MasterKeyFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ошибка загрузки мастер-ключей хранилища: %w", err)
+	}
+	encrypted := storage.NewEncryptedStorage(minioClient, keyring)
+	log.Printf("Шифрование объектов включено, активная версия мастер-ключа: %d", keyring.ActiveVersion())
+	return encrypted, encrypted, nil
+}
+
+// webdavPrefix - путь каталога WebDAV с файлом хранилища пользователя.
+const webdavPrefix = "/dav"
+
+// routerOptions - параметры конфигурации, влияющие на маршруты.
+type routerOptions struct {
+	// adminToken - токен администратора (пусто - маршруты /api/admin/storage не регистрируются).
+	adminToken    string
+	jwtSecret     string                      // Ключ проверки подписи JWT
+	accounts      appmiddleware.AccountLookup // Загрузка учетной записи владельца токена
+	maxUploadSize int64                       // Максимальный размер загружаемого хранилища в байтах (0 - без ограничения)
+}
+
 // setupRouter настраивает и возвращает роутер chi.
-func setupRouter(authHandler *handlers.AuthHandler, vaultHandler *handlers.VaultHandler) *chi.Mux {
+// Служебные маршруты /api/admin/storage регистрируются только при заданном токене администратора,
+// остальные маршруты /api/admin доступны пользователям с ролью администратора.
+func setupRouter(h routeHandlers, opts routerOptions) *chi.Mux {
+	// Методы WebDAV должны быть известны chi до регистрации маршрутов
+	for _, method := range handlers.WebDAVMethods() {
+		chi.RegisterMethod(method)
+	}
 	r := chi.NewRouter()
+	if h.metrics != nil {
+		r.Use(appmiddleware.Metrics(h.metrics))
+	}
 	r.Use(middleware.RequestID)
 	r.Use(middleware.RealIP)
 	r.Use(middleware.Logger)
@@ -176,38 +461,205 @@ func setupRouter(authHandler *handlers.AuthHandler, vaultHandler *handlers.Vault
 	r.Get("/ping", func(w http.ResponseWriter, _ *http.Request) {
 		_, _ = w.Write([]byte("pong\n"))
 	})
+	// Проверки состояния для оркестратора: /healthz - процесс жив, /readyz - доступны БД и хранилище
+	r.Get("/healthz", h.health.Liveness)
+	r.Get("/readyz", h.health.Readiness)
+	if h.metricsEndpoint != nil {
+		r.Method(http.MethodGet, "/metrics", h.metricsEndpoint)
+	}
 
-	// Определяем базовый маршрут /api
+	// Определяем базовый маршрут /api. API v2 (/api/v2) использует те же обработчики,
+	// но отвечает об ошибках в формате models.ErrorResponse с машиночитаемым кодом.
 	r.Route("/api", func(r chi.Router) {
-		// Публичные маршруты (регистрация, вход)
-		r.Post("/register", authHandler.Register)
-		r.Post("/login", authHandler.Login)
-
-		// Приватные маршруты (требуют аутентификации)
-		r.Group(func(r chi.Router) {
-			// Применяем middleware аутентификации ко всей группе
-			r.Use(appmiddleware.Authenticator)
-
-			// Маршруты для работы с хранилищем
-			r.Route("/vault", func(r chi.Router) {
-				r.Get("/", vaultHandler.GetMetadata)
-				r.Post("/upload", vaultHandler.Upload)
-				r.Get("/download", vaultHandler.Download)
-				r.Get("/versions", vaultHandler.ListVersions)
-				r.Post("/rollback", vaultHandler.Rollback)
-			})
-			// Маршрут для удаления аккаунта (если он есть в AuthHandler)
-			// r.Delete("/account", authHandler.DeleteAccount)
+		// Спецификация OpenAPI обеих версий API
+		r.Get("/openapi.json", openapi.Handler)
+		r.Route("/v2", func(r chi.Router) {
+			r.Use(appmiddleware.APIv2)
+			registerAPIRoutes(r, h, opts)
 		})
+		registerAPIRoutes(r, h, opts)
 	})
+
+	// Каталог WebDAV с текущей версией хранилища для клиентов KeePass (аутентификация Basic или Bearer)
+	if h.webdav != nil {
+		r.Route(webdavPrefix, func(r chi.Router) {
+			r.Use(appmiddleware.MaxBodySize(opts.maxUploadSize))
+			r.Handle("/", h.webdav)
+			r.Handle("/*", h.webdav)
+		})
+	}
 	return r
 }
 
-// getEnv получает значение переменной окружения или возвращает значение по умолчанию.
-func getEnv(key, fallback string) string {
-	if value, ok := os.LookupEnv(key); ok {
-		return value
+// registerAPIRoutes регистрирует маршруты API относительно r (/api или /api/v2).
+func registerAPIRoutes(r chi.Router, h routeHandlers, opts routerOptions) {
+	// Публичные маршруты (регистрация, вход)
+	r.Post("/register", h.auth.Register)
+	r.Post("/login", h.auth.Login)
+
+	// Приватные маршруты (требуют аутентификации)
+	r.Group(func(r chi.Router) {
+		// Применяем middleware аутентификации ко всей группе
+		r.Use(appmiddleware.JWTAuthenticator(opts.jwtSecret, opts.accounts))
+
+		// Маршруты для работы с хранилищем
+		r.Route("/vault", func(r chi.Router) {
+			// Доверенный контакт читает хранилище владельца с параметром owner_id после открытия доступа
+			r.With(h.emergency.OwnerVault).Get("/", h.vault.GetMetadata)
+			r.With(appmiddleware.MaxBodySize(opts.maxUploadSize)).Post("/upload", h.vault.Upload)
+			r.With(h.emergency.OwnerVault).Get("/download", h.vault.Download)
+			r.With(h.emergency.OwnerVault).Head("/download", h.vault.Download)
+			r.With(h.emergency.OwnerVault).Get("/versions", h.vault.ListVersions)
+			r.Post("/rollback", h.vault.Rollback)
+			r.Get("/events", h.events.Stream)
+		})
+
+		// Маршруты для управления вебхуками
+		r.Route("/webhooks", func(r chi.Router) {
+			r.Get("/", h.webhooks.List)
+			r.Post("/", h.webhooks.Create)
+			r.Delete("/{webhookID}", h.webhooks.Delete)
+			r.Get("/{webhookID}/deliveries", h.webhooks.ListDeliveries)
+		})
+
+		// Маршруты экстренного доступа: владелец управляет доверенными контактами, контакт запрашивает доступ
+		r.Route("/emergency", func(r chi.Router) {
+			r.Get("/contacts", h.emergency.ListContacts)
+			r.Post("/contacts", h.emergency.SetContact)
+			r.Delete("/contacts/{contactID}", h.emergency.RemoveContact)
+			r.Put("/contacts/{contactID}/envelope", h.emergency.SetKeyEnvelope)
+			r.Post("/contacts/{contactID}/deny", h.emergency.DenyAccess)
+			r.Get("/grants", h.emergency.ListGrants)
+			r.Post("/grants/{ownerID}/request", h.emergency.RequestAccess)
+			r.Get("/grants/{ownerID}/envelope", h.emergency.GetKeyEnvelope)
+		})
+
+		// Маршрут для удаления аккаунта (если он есть в AuthHandler)
+		// r.Delete("/account", h.auth.DeleteAccount)
+	})
+
+	// Маршруты администратора
+	if h.admin != nil {
+		r.Route("/admin", func(r chi.Router) {
+			// Служебные маршруты (аутентификация по X-Admin-Token)
+			if opts.adminToken != "" {
+				r.Group(func(r chi.Router) {
+					r.Use(appmiddleware.AdminTokenAuthenticator(opts.adminToken))
+					r.Get("/storage/integrity", h.admin.StorageIntegrity)
+					r.Post("/storage/scrub", h.admin.StorageScrub)
+				})
+			}
+
+			// Управление пользователями (JWT пользователя с ролью администратора)
+			r.Group(func(r chi.Router) {
+				r.Use(appmiddleware.JWTAuthenticator(opts.jwtSecret, opts.accounts))
+				r.Use(appmiddleware.RequireRole(models.RoleAdmin))
+				r.Get("/users", h.admin.ListUsers)
+				r.Post("/users/{username}/disable", h.admin.DisableUser)
+				r.Post("/users/{username}/enable", h.admin.EnableUser)
+				r.Get("/users/{username}/storage", h.admin.UserStorage)
+				r.Get("/stats", h.admin.Stats)
+			})
+		})
+	}
+}
+
+// serveMetrics запускает отдельный HTTP-сервер (без TLS), отдающий метрики на /metrics, до отмены ctx.
+// Порт метрик предназначен для внутренней сети, где их собирает Prometheus.
+func serveMetrics(ctx context.Context, addr string, handler http.Handler) error {
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", handler)
+	server := &http.Server{
+		Addr:         addr,
+		Handler:      mux,
+		ReadTimeout:  defaultReadTimeout,
+		WriteTimeout: defaultWriteTimeout,
+		IdleTimeout:  defaultIdleTimeout,
+	}
+	log.Printf("Запуск сервера метрик на %s...", addr)
+	if err := serveUntilShutdown(ctx, server, server.ListenAndServe, defaultReadTimeout); err != nil {
+		return fmt.Errorf("ошибка работы сервера метрик: %w", err)
+	}
+	return nil
+}
+
+// startGRPCServer запускает gRPC-сервер с TLS (та же конфигурация, что у HTTPS-сервера) на cfg.Server.GRPCAddress
+// до отмены ctx.
+func startGRPCServer(
+	ctx context.Context,
+	cfg *config,
+	tlsConfig *tls.Config,
+	srv *grpcserver.Server,
+	accounts appmiddleware.AccountLookup,
+) error {
+	creds := credentials.NewTLS(tlsConfig)
+	ln, err := net.Listen("tcp", cfg.Server.GRPCAddress)
+	if err != nil {
+		return fmt.Errorf("ошибка открытия порта gRPC: %w", err)
+	}
+	log.Printf("Запуск gRPC-сервера на %s...", cfg.Server.GRPCAddress)
+	server := grpcserver.NewGRPCServer(srv, cfg.Auth.JWTSecret, accounts, grpc.Creds(creds))
+	return serveGRPCUntilShutdown(ctx, server, ln, cfg.Server.ShutdownTimeout)
+}
+
+// serveGRPCUntilShutdown обслуживает вызовы на ln до отмены ctx, затем останавливает сервер так же,
+// как serveUntilShutdown: активные вызовы дорабатывают не дольше timeout (0 - без ограничения),
+// после чего оставшиеся соединения разрываются.
+func serveGRPCUntilShutdown(ctx context.Context, server *grpc.Server, ln net.Listener, timeout time.Duration) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Serve(ln)
+	}()
+
+	select {
+	case err := <-serveErr:
+		if errors.Is(err, grpc.ErrServerStopped) {
+			return nil
+		}
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Printf("Остановка gRPC-сервера %s: ожидание завершения активных вызовов (таймаут %s)...", ln.Addr(), timeout)
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+	var timer <-chan time.Time
+	if timeout > 0 {
+		timer = time.After(timeout)
+	}
+	select {
+	case <-stopped:
+	case <-timer:
+		server.Stop()
+		<-stopped
+		return fmt.Errorf("активные вызовы gRPC не завершились за %s", timeout)
+	}
+	if err := <-serveErr; err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		return err
+	}
+	log.Printf("gRPC-сервер %s остановлен.", ln.Addr())
+	return nil
+}
+
+// rewrapStorageKeys переоборачивает ключи данных всех объектов активной версией мастер-ключа.
+// Данные хранилищ при этом не перезаписываются, поэтому операция дешевая и идемпотентная.
+func rewrapStorageKeys(
+	ctx context.Context,
+	versionRepo repository.VaultVersionRepository,
+	encrypted *storage.EncryptedStorage,
+) {
+	objectKeys, err := versionRepo.ListAllObjectKeys(ctx)
+	if err != nil {
+		log.Printf("Ошибка получения списка объектов для переоборачивания ключей: %v", err)
+		return
+	}
+	rewrapped, err := encrypted.RewrapKeys(ctx, objectKeys)
+	if err != nil {
+		log.Printf("Ошибка переоборачивания ключей объектов (обновлено %d): %v", rewrapped, err)
+		return
 	}
-	log.Printf("Переменная окружения '%s' не установлена, используется значение по умолчанию: '%s'", key, fallback)
-	return fallback
+	log.Printf("Переоборачивание ключей завершено: проверено %d объектов, обновлено %d", len(objectKeys), rewrapped)
 }
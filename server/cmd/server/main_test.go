@@ -1,40 +1,39 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
+	"io"
+	"net"
 	"net/http"
-	"os"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/jmoiron/sqlx"
+	"github.com/maynagashev/gophkeeper/models"
+	"github.com/maynagashev/gophkeeper/models/pb"
+	"github.com/maynagashev/gophkeeper/server/internal/grpcserver"
 	"github.com/maynagashev/gophkeeper/server/internal/handlers"
+	"github.com/maynagashev/gophkeeper/server/internal/metrics"
+	appmiddleware "github.com/maynagashev/gophkeeper/server/internal/middleware"
+	"github.com/maynagashev/gophkeeper/server/internal/mocks"
+	"github.com/maynagashev/gophkeeper/server/internal/repository"
+	"github.com/maynagashev/gophkeeper/server/internal/services"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
 )
 
-func TestGetEnv(t *testing.T) {
-	key := "TEST_ENV_VAR"
-	falback := "default_value"
-
-	t.Run("Переменная окружения установлена", func(t *testing.T) {
-		expectedValue := "test_value"
-		os.Setenv(key, expectedValue)
-		defer os.Unsetenv(key)
-
-		value := getEnv(key, falback)
-		assert.Equal(t, expectedValue, value)
-	})
-
-	t.Run("Переменная окружения не установлена", func(t *testing.T) {
-		os.Unsetenv(key) // Убедимся, что переменная не установлена
-		value := getEnv(key, falback)
-		assert.Equal(t, falback, value)
-	})
-}
-
 func TestSetupRouter(t *testing.T) {
 	// Используем nil для зависимостей обработчиков, так как тестируем только роутинг
 	var nilAuthHandler *handlers.AuthHandler
@@ -53,7 +52,16 @@ func TestSetupRouter(t *testing.T) {
 	}
 
 	// Вызываем тестируемую функцию
-	r := setupRouter(actualAuthHandler, actualVaultHandler)
+	routes := routeHandlers{
+		auth:      actualAuthHandler,
+		vault:     actualVaultHandler,
+		events:    handlers.NewEventsHandler(nil),
+		webhooks:  handlers.NewWebhookHandler(nil),
+		emergency: handlers.NewEmergencyAccessHandler(nil),
+		health:    handlers.NewHealthHandler(nil),
+		admin:     handlers.NewAdminHandler(nil, nil),
+	}
+	r := setupRouter(routes, routerOptions{adminToken: "admin-token", jwtSecret: "test-secret", accounts: testAccounts})
 
 	// Проверяем, что роутер не nil
 	require.NotNil(t, r)
@@ -66,13 +74,186 @@ func TestSetupRouter(t *testing.T) {
 
 	// Проверяем наличие маршрутов
 	assert.True(t, hasRoute(r, http.MethodGet, "/ping"))
+	assert.True(t, hasRoute(r, http.MethodGet, "/healthz"))
+	assert.True(t, hasRoute(r, http.MethodGet, "/readyz"))
 	assert.True(t, hasRoute(r, http.MethodPost, "/api/register"))
 	assert.True(t, hasRoute(r, http.MethodPost, "/api/login"))
 	assert.True(t, hasRoute(r, http.MethodGet, "/api/vault/"))
 	assert.True(t, hasRoute(r, http.MethodPost, "/api/vault/upload"))
 	assert.True(t, hasRoute(r, http.MethodGet, "/api/vault/download"))
+	assert.True(t, hasRoute(r, http.MethodHead, "/api/vault/download"))
 	assert.True(t, hasRoute(r, http.MethodGet, "/api/vault/versions"))
 	assert.True(t, hasRoute(r, http.MethodPost, "/api/vault/rollback"))
+	assert.True(t, hasRoute(r, http.MethodGet, "/api/vault/events"))
+	assert.True(t, hasRoute(r, http.MethodGet, "/api/webhooks/"))
+	assert.True(t, hasRoute(r, http.MethodPost, "/api/webhooks/"))
+	assert.True(t, hasRoute(r, http.MethodDelete, "/api/webhooks/{webhookID}"))
+	assert.True(t, hasRoute(r, http.MethodGet, "/api/webhooks/{webhookID}/deliveries"))
+	assert.True(t, hasRoute(r, http.MethodGet, "/api/emergency/contacts"))
+	assert.True(t, hasRoute(r, http.MethodPost, "/api/emergency/contacts"))
+	assert.True(t, hasRoute(r, http.MethodDelete, "/api/emergency/contacts/{contactID}"))
+	assert.True(t, hasRoute(r, http.MethodPut, "/api/emergency/contacts/{contactID}/envelope"))
+	assert.True(t, hasRoute(r, http.MethodPost, "/api/emergency/contacts/{contactID}/deny"))
+	assert.True(t, hasRoute(r, http.MethodGet, "/api/emergency/grants"))
+	assert.True(t, hasRoute(r, http.MethodPost, "/api/emergency/grants/{ownerID}/request"))
+	assert.True(t, hasRoute(r, http.MethodGet, "/api/emergency/grants/{ownerID}/envelope"))
+	assert.True(t, hasRoute(r, http.MethodGet, "/api/admin/storage/integrity"))
+	assert.True(t, hasRoute(r, http.MethodPost, "/api/admin/storage/scrub"))
+	assert.True(t, hasRoute(r, http.MethodGet, "/api/admin/users"))
+	assert.True(t, hasRoute(r, http.MethodPost, "/api/admin/users/{username}/disable"))
+	assert.True(t, hasRoute(r, http.MethodPost, "/api/admin/users/{username}/enable"))
+	assert.True(t, hasRoute(r, http.MethodGet, "/api/admin/users/{username}/storage"))
+	assert.True(t, hasRoute(r, http.MethodGet, "/api/admin/stats"))
+
+	t.Run("Без токена администратора служебные маршруты не регистрируются", func(t *testing.T) {
+		r := setupRouter(routes, routerOptions{jwtSecret: "test-secret", accounts: testAccounts})
+		assert.False(t, hasRoute(r, http.MethodGet, "/api/admin/storage/integrity"))
+		assert.True(t, hasRoute(r, http.MethodGet, "/api/admin/users"), "Маршруты по роли не зависят от токена")
+	})
+
+	t.Run("Маршруты администратора недоступны обычному пользователю", func(t *testing.T) {
+		for _, role := range []string{models.RoleUser, ""} {
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/api/admin/stats", nil)
+			req.Header.Set("Authorization", "Bearer "+signTestToken(t, "test-secret", role))
+			r.ServeHTTP(rr, req)
+			assert.Equal(t, http.StatusForbidden, rr.Code, "роль '%s'", role)
+		}
+
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/admin/stats", nil))
+		assert.Equal(t, http.StatusUnauthorized, rr.Code, "без токена")
+	})
+
+	t.Run("Роль администратора снята после выдачи токена", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/admin/stats", nil)
+		req.Header.Set("Authorization", "Bearer "+signUserToken(t, "test-secret", 1, models.RoleAdmin))
+		r.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("Учетная запись отключена после выдачи токена", func(t *testing.T) {
+		disabledAt := time.Now()
+		accounts := func(_ context.Context, userID int64) (*models.User, error) {
+			return &models.User{ID: userID, Role: models.RoleUser, DisabledAt: &disabledAt}, nil
+		}
+		r := setupRouter(routes, routerOptions{jwtSecret: "test-secret", accounts: accounts})
+
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/v2/vault/", nil)
+		req.Header.Set("Authorization", "Bearer "+signTestToken(t, "test-secret", models.RoleUser))
+		r.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		var resp models.ErrorResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, models.ErrCodeAccountDisabled, resp.Code)
+	})
+
+	t.Run("API v2 использует те же обработчики и отвечает об ошибках в JSON", func(t *testing.T) {
+		assert.True(t, hasRoute(r, http.MethodPost, "/api/v2/login"))
+		assert.True(t, hasRoute(r, http.MethodPost, "/api/v2/vault/upload"))
+		assert.True(t, hasRoute(r, http.MethodGet, "/api/v2/admin/stats"))
+
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v2/vault/", nil))
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		var resp models.ErrorResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, models.ErrCodeUnauthorized, resp.Code)
+		assert.NotEmpty(t, resp.RequestID)
+
+		rr = httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/vault/", nil))
+		assert.Equal(t, "Требуется аутентификация\n", rr.Body.String(), "API v1 отвечает текстом")
+	})
+
+	t.Run("Маршруты администратора доступны администратору", func(t *testing.T) {
+		userAdmin := mocks.NewUserAdminService(t)
+		userAdmin.EXPECT().ServerStats(mock.Anything).Return(&models.ServerStats{Users: 1}, nil).Once()
+		withAdmin := routes
+		withAdmin.admin = handlers.NewAdminHandler(nil, userAdmin)
+		r := setupRouter(withAdmin, routerOptions{jwtSecret: "test-secret", accounts: testAccounts})
+
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/admin/stats", nil)
+		req.Header.Set("Authorization", "Bearer "+signTestToken(t, "test-secret", models.RoleAdmin))
+		r.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Каталог WebDAV принимает методы WebDAV", func(t *testing.T) {
+		assert.False(t, hasRoute(r, "PROPFIND", "/dav/*"), "Без обработчика каталог не регистрируется")
+
+		vaultService := mocks.NewVaultService(t)
+		vaultService.EXPECT().GetVaultMetadata(mock.Anything, int64(1)).Return(nil, services.ErrVaultNotFound).Once()
+		withWebDAV := routes
+		withWebDAV.webdav = handlers.NewWebDAVHandler(vaultService, nil, "test-secret", testAccounts, webdavPrefix)
+		r := setupRouter(withWebDAV, routerOptions{jwtSecret: "test-secret", accounts: testAccounts})
+
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest("PROPFIND", "/dav/", nil))
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+		rr = httptest.NewRecorder()
+		req := httptest.NewRequest("PROPFIND", "/dav/", nil)
+		req.Header.Set("Authorization", "Bearer "+signTestToken(t, "test-secret", models.RoleUser))
+		req.Header.Set("Depth", "0")
+		r.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusMultiStatus, rr.Code)
+	})
+
+	t.Run("Маршрут метрик на основном порту", func(t *testing.T) {
+		assert.False(t, hasRoute(r, http.MethodGet, "/metrics"), "Без обработчика метрик маршрут не регистрируется")
+
+		appMetrics := metrics.New()
+		withMetrics := routes
+		withMetrics.metrics = appMetrics
+		withMetrics.metricsEndpoint = appMetrics.Handler()
+		r := setupRouter(withMetrics, routerOptions{jwtSecret: "test-secret", accounts: testAccounts})
+		require.True(t, hasRoute(r, http.MethodGet, "/metrics"))
+
+		// Запрос к /ping учитывается middleware и виден на /metrics
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/ping", nil))
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `gophkeeper_http_requests_total{method="GET",route="/ping",status="200"} 1`)
+	})
+}
+
+// testAdminID - администратор среди пользователей, которых находит testAccounts.
+const testAdminID = 2
+
+// testAccounts находит пользователя testAdminID администратором, остальных - обычными пользователями.
+func testAccounts(_ context.Context, userID int64) (*models.User, error) {
+	if userID == testAdminID {
+		return &models.User{ID: userID, Role: models.RoleAdmin}, nil
+	}
+	return &models.User{ID: userID, Role: models.RoleUser}, nil
+}
+
+// signTestToken выпускает JWT с ролью role, подписанный ключом secret: администратора testAdminID,
+// для остальных ролей - пользователя 1.
+func signTestToken(t *testing.T, secret, role string) string {
+	t.Helper()
+	if role == models.RoleAdmin {
+		return signUserToken(t, secret, testAdminID, role)
+	}
+	return signUserToken(t, secret, 1, role)
+}
+
+// signUserToken выпускает JWT пользователя userID с ролью role, подписанный ключом secret.
+func signUserToken(t *testing.T, secret string, userID int64, role string) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": userID,
+		"role":    role,
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	})
+	signed, err := token.SignedString([]byte(secret))
+	require.NoError(t, err)
+	return signed
 }
 
 // Вспомогательная функция для проверки наличия маршрута.
@@ -101,29 +282,11 @@ func TestSetupDependencies(t *testing.T) {
 	originalNewPostgresDB := newPostgresDB
 	defer func() { newPostgresDB = originalNewPostgresDB }()
 
-	// Сохраняем и очищаем переменные окружения MinIO
-	originalMinioEnv := map[string]string{
-		envMinioEndpoint: os.Getenv(envMinioEndpoint),
-		envMinioUser:     os.Getenv(envMinioUser),
-		envMinioPassword: os.Getenv(envMinioPassword),
-		envMinioBucket:   os.Getenv(envMinioBucket),
-	}
-	defer func() {
-		for k, v := range originalMinioEnv {
-			os.Setenv(k, v)
-		}
-	}()
-	os.Unsetenv(envMinioEndpoint)
-	os.Unsetenv(envMinioUser)
-	os.Unsetenv(envMinioPassword)
-	os.Unsetenv(envMinioBucket)
-
 	t.Run("Ошибка: Некорректный DatabaseDSN", func(t *testing.T) {
 		// Восстанавливаем реальную функцию NewPostgresDB для этого теста
 		newPostgresDB = originalNewPostgresDB
-		cfg := &config{
-			DatabaseDSN: "невалидный dsn",
-		}
+		cfg := defaultConfig()
+		cfg.Database.DSN = "невалидный dsn"
 		_, err := setupDependencies(cfg)
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "ошибка инициализации БД")
@@ -131,22 +294,21 @@ func TestSetupDependencies(t *testing.T) {
 
 	t.Run("Ошибка: Некорректный MinIO Endpoint", func(t *testing.T) {
 		// Мокируем newPostgresDB, чтобы он возвращал успех
-		newPostgresDB = func(_ string) (*sqlx.DB, error) {
+		newPostgresDB = func(_ string, _ repository.PoolConfig) (*sqlx.DB, error) {
 			mockDB, _, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
 			require.NoError(t, err)
 			sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
 			return sqlxDB, nil
 		}
 
-		cfg := &config{
-			// DSN теперь не важен, так как newPostgresDB замокирован
-			DatabaseDSN: "dummy-dsn-for-mock",
-		}
+		cfg := defaultConfig()
+		// DSN теперь не важен, так как newPostgresDB замокирован
+		cfg.Database.DSN = "dummy-dsn-for-mock"
 		// Устанавливаем некорректный endpoint MinIO
-		os.Setenv(envMinioEndpoint, "invalid-endpoint:!!!")
-		os.Setenv(envMinioUser, "user")
-		os.Setenv(envMinioPassword, "password")
-		os.Setenv(envMinioBucket, "bucket")
+		cfg.Storage.Endpoint = "invalid-endpoint:!!!"
+		cfg.Storage.AccessKey = "user"
+		cfg.Storage.SecretKey = "password"
+		cfg.Storage.Bucket = "bucket"
 
 		_, err := setupDependencies(cfg) // Вызываем setupDependencies с моком БД
 		require.Error(t, err)            // Ожидаем ошибку от NewMinioClient
@@ -155,21 +317,16 @@ func TestSetupDependencies(t *testing.T) {
 
 	t.Run("Успешное выполнение (без реальной проверки соединений)", func(t *testing.T) {
 		// Мокируем newPostgresDB и для этого теста
-		newPostgresDB = func(_ string) (*sqlx.DB, error) {
+		newPostgresDB = func(_ string, _ repository.PoolConfig) (*sqlx.DB, error) {
 			mockDB, _, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
 			require.NoError(t, err)
 			sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
 			return sqlxDB, nil
 		}
 
-		cfg := &config{
-			DatabaseDSN: "dummy-dsn-for-mock",
-		}
-		// Используем переменные окружения для MinIO по умолчанию
-		os.Setenv(envMinioEndpoint, defaultMinioEndpoint)
-		os.Setenv(envMinioUser, defaultMinioUser)
-		os.Setenv(envMinioPassword, defaultMinioPassword)
-		os.Setenv(envMinioBucket, defaultMinioBucket)
+		// Используем настройки MinIO по умолчанию
+		cfg := defaultConfig()
+		cfg.Database.DSN = "dummy-dsn-for-mock"
 
 		deps, err := setupDependencies(cfg)
 
@@ -188,3 +345,203 @@ func TestSetupDependencies(t *testing.T) {
 		}
 	})
 }
+
+// startTestServer запускает serveUntilShutdown на локальном порту и возвращает адрес и канал результата.
+func startTestServer(
+	ctx context.Context, t *testing.T, handler http.Handler, timeout time.Duration,
+) (string, <-chan error) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := &http.Server{Handler: handler, ReadHeaderTimeout: defaultReadTimeout}
+	done := make(chan error, 1)
+	go func() {
+		done <- serveUntilShutdown(ctx, server, func() error { return server.Serve(ln) }, timeout)
+	}()
+	return ln.Addr().String(), done
+}
+
+// connectionRefused сообщает, что сервер больше не принимает новые соединения.
+func connectionRefused(addr string) bool {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return true
+	}
+	_ = conn.Close()
+	return false
+}
+
+func TestServeUntilShutdown(t *testing.T) {
+	t.Run("Загрузка, начатая до остановки, завершается", func(t *testing.T) {
+		payload := bytes.Repeat([]byte("kdbx"), 64*1024)
+		half := len(payload) / 2
+		uploadStarted := make(chan struct{})
+		var received []byte
+
+		vaultService := mocks.NewVaultService(t)
+		vaultService.EXPECT().
+			UploadVault(mock.Anything, int64(1), mock.Anything, int64(len(payload)), mock.Anything, mock.Anything,
+				mock.Anything).
+			RunAndReturn(func(
+				ctx context.Context, _ int64, r io.Reader, _ int64, _ string, _ time.Time, _ models.UploadOrigin,
+			) (*models.VaultVersion, error) {
+				first := make([]byte, half)
+				if _, err := io.ReadFull(r, first); err != nil {
+					return nil, err
+				}
+				close(uploadStarted)
+				rest, err := io.ReadAll(r)
+				if err != nil {
+					return nil, err
+				}
+				received = append(first, rest...)
+				// Контекст запроса не должен отменяться при остановке
+				return &models.VaultVersion{ID: 1}, ctx.Err()
+			}).Once()
+		vaultHandler := handlers.NewVaultHandler(vaultService)
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), appmiddleware.UserIDKey, int64(1))
+			vaultHandler.Upload(w, r.WithContext(ctx))
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		addr, done := startTestServer(ctx, t, handler, 5*time.Second)
+
+		body, bodyWriter := io.Pipe()
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, "http://"+addr+"/upload", body)
+		require.NoError(t, err)
+		req.ContentLength = int64(len(payload))
+		req.Header.Set("X-Kdbx-Content-Modified-At", time.Now().UTC().Format(time.RFC3339))
+		responses := make(chan *http.Response, 1)
+		go func() {
+			resp, doErr := http.DefaultClient.Do(req)
+			if doErr != nil {
+				t.Errorf("запрос загрузки прерван: %v", doErr)
+				close(responses)
+				return
+			}
+			responses <- resp
+		}()
+
+		_, err = bodyWriter.Write(payload[:half])
+		require.NoError(t, err)
+		<-uploadStarted
+
+		// Сигнал остановки приходит посреди загрузки: новые соединения больше не принимаются
+		cancel()
+		require.Eventually(t, func() bool { return connectionRefused(addr) }, time.Second, 10*time.Millisecond)
+
+		_, err = bodyWriter.Write(payload[half:])
+		require.NoError(t, err)
+		require.NoError(t, bodyWriter.Close())
+
+		resp, ok := <-responses
+		require.True(t, ok)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, payload, received)
+
+		select {
+		case err = <-done:
+			require.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("сервер не остановился после завершения загрузки")
+		}
+	})
+
+	t.Run("Запросы, не завершившиеся за таймаут, прерываются", func(t *testing.T) {
+		release := make(chan struct{})
+		defer close(release)
+		requestStarted := make(chan struct{})
+		handler := http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+			close(requestStarted)
+			<-release
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		addr, done := startTestServer(ctx, t, handler, 50*time.Millisecond)
+		go func() {
+			req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, "http://"+addr+"/", nil)
+			if resp, doErr := http.DefaultClient.Do(req); doErr == nil {
+				resp.Body.Close()
+			}
+		}()
+		<-requestStarted
+		cancel()
+
+		select {
+		case err := <-done:
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "активные запросы не завершились")
+		case <-time.After(5 * time.Second):
+			t.Fatal("сервер не остановился по таймауту")
+		}
+	})
+
+	t.Run("Ошибка запуска возвращается сразу", func(t *testing.T) {
+		server := &http.Server{ReadHeaderTimeout: defaultReadTimeout}
+		err := serveUntilShutdown(context.Background(), server, func() error {
+			return errors.New("address already in use")
+		}, time.Second)
+		require.EqualError(t, err, "address already in use")
+	})
+}
+
+func TestServeGRPCUntilShutdown(t *testing.T) {
+	// startGRPC запускает gRPC-сервер на локальном порту и возвращает адрес и канал результата.
+	startGRPC := func(ctx context.Context, t *testing.T, subscriber *mocks.Subscriber) (string, <-chan error) {
+		t.Helper()
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		srv := grpcserver.NewServer(mocks.NewAuthService(t), mocks.NewVaultService(t), subscriber, 0)
+		done := make(chan error, 1)
+		go func() {
+			done <- serveGRPCUntilShutdown(ctx, grpcserver.NewGRPCServer(srv, "secret", testAccounts), ln, 100*time.Millisecond)
+		}()
+		return ln.Addr().String(), done
+	}
+
+	t.Run("Остановка без активных вызовов", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		_, done := startGRPC(ctx, t, mocks.NewSubscriber(t))
+		cancel()
+
+		select {
+		case err := <-done:
+			require.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("gRPC-сервер не остановился")
+		}
+	})
+
+	t.Run("Незавершенный поток событий разрывается по таймауту", func(t *testing.T) {
+		subscriber := mocks.NewSubscriber(t)
+		subscribed := make(chan struct{})
+		subscriber.EXPECT().Subscribe(int64(1)).RunAndReturn(func(int64) (<-chan models.VaultEvent, func()) {
+			close(subscribed)
+			return make(chan models.VaultEvent), func() {}
+		}).Once()
+		ctx, cancel := context.WithCancel(context.Background())
+		addr, done := startGRPC(ctx, t, subscriber)
+
+		conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		require.NoError(t, err)
+		defer conn.Close()
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"user_id": 1}).SignedString([]byte("secret"))
+		require.NoError(t, err)
+		callCtx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+		_, err = pb.NewGophKeeperClient(conn).SubscribeEvents(callCtx, &pb.SubscribeEventsRequest{})
+		require.NoError(t, err)
+		<-subscribed
+		cancel()
+
+		select {
+		case err = <-done:
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "активные вызовы gRPC не завершились")
+		case <-time.After(5 * time.Second):
+			t.Fatal("gRPC-сервер не остановился по таймауту")
+		}
+	})
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/maynagashev/gophkeeper/server/internal/repository"
+)
+
+// schemaMigrator - операции с миграциями схемы БД, используемые сервером.
+type schemaMigrator interface {
+	Up() error
+	Down() error
+	MigrateTo(version uint) error
+	Status() (repository.MigrationStatus, error)
+	Close() error
+}
+
+// Функция создания мигратора (переменная для мокирования в тестах).
+var newMigrator = func(dsn string) (schemaMigrator, error) { //nolint:gochecknoglobals // Мокируется в тестах
+	return repository.NewMigrator(dsn)
+}
+
+// runMigrateCommand выполняет подкоманду "migrate up|down|status".
+// Строка подключения берется из конфигурации сервера: флага -database-dsn, переменной окружения DATABASE_DSN
+// или параметра database.dsn файла -config.
+func runMigrateCommand(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	fs.SetOutput(out)
+	fs.Usage = func() {
+		fmt.Fprintln(out, "Использование: gophkeeper-server migrate [-config <файл>] [-database-dsn <dsn>] up|down|status")
+		fmt.Fprintln(out, "  up     - применить все непримененные миграции")
+		fmt.Fprintln(out, "  down   - откатить последнюю примененную миграцию")
+		fmt.Fprintln(out, "  status - показать примененную и последнюю доступную версии схемы")
+		fs.PrintDefaults()
+	}
+	cfg, err := readConfig(fs, args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("укажите одно действие: up, down или status")
+	}
+	action := fs.Arg(0)
+	if action != "up" && action != "down" && action != "status" {
+		fs.Usage()
+		return fmt.Errorf("неизвестное действие '%s'", action)
+	}
+
+	if cfg.Database.DSN == "" {
+		return errors.New("не указана строка подключения к БД (--database-dsn или " + envDatabaseDSN + ")")
+	}
+
+	m, err := newMigrator(cfg.Database.DSN)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := m.Close(); closeErr != nil {
+			log.Printf("Ошибка закрытия соединения мигратора: %v", closeErr)
+		}
+	}()
+
+	switch action {
+	case "up":
+		err = m.Up()
+	case "down":
+		err = m.Down()
+	}
+	if err != nil {
+		return err
+	}
+
+	status, err := m.Status()
+	if err != nil {
+		return err
+	}
+	printMigrationStatus(out, status)
+	return nil
+}
+
+// printMigrationStatus выводит состояние схемы БД.
+func printMigrationStatus(out io.Writer, status repository.MigrationStatus) {
+	fmt.Fprintf(out, "Версия схемы БД: %d\n", status.Version)
+	fmt.Fprintf(out, "Последняя версия сервера: %d\n", status.Latest)
+	switch {
+	case status.Dirty:
+		fmt.Fprintf(out, "Состояние: миграция %d прервана, требуется ручное исправление\n", status.Version)
+	case status.Ahead():
+		fmt.Fprintln(out, "Состояние: схема новее, чем поддерживает сервер")
+	case status.Pending():
+		fmt.Fprintf(out, "Состояние: не применено миграций: %d\n", status.Latest-status.Version)
+	default:
+		fmt.Fprintln(out, "Состояние: схема актуальна")
+	}
+}
+
+// prepareSchema проверяет схему БД перед запуском сервера и при autoMigrate применяет миграции.
+// Сервер не запускается, если схема новее встроенных миграций: код не знает о ее изменениях.
+func prepareSchema(m schemaMigrator, autoMigrate bool) error {
+	status, err := m.Status()
+	if err != nil {
+		return err
+	}
+	if status.Ahead() {
+		return fmt.Errorf("%w: версия %d, сервер поддерживает до %d; обновите сервер",
+			repository.ErrSchemaAhead, status.Version, status.Latest)
+	}
+	if !status.Pending() && !status.Dirty {
+		log.Printf("Схема БД актуальна (версия %d).", status.Version)
+		return nil
+	}
+	if !autoMigrate {
+		log.Printf("ВНИМАНИЕ: схема БД (версия %d, dirty=%t) не соответствует серверу (версия %d). "+
+			"Выполните 'migrate up' или запустите сервер с -auto-migrate.", status.Version, status.Dirty, status.Latest)
+		return nil
+	}
+
+	log.Printf("Применение миграций БД: версия %d -> %d...", status.Version, status.Latest)
+	if err = m.Up(); err != nil {
+		return err
+	}
+	log.Println("Миграции БД применены.")
+	return nil
+}
+
+// migrateSchema подключается к БД отдельным соединением и готовит схему к запуску сервера.
+func migrateSchema(cfg *config) error {
+	m, err := newMigrator(cfg.Database.DSN)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := m.Close(); closeErr != nil {
+			log.Printf("Ошибка закрытия соединения мигратора: %v", closeErr)
+		}
+	}()
+	return prepareSchema(m, cfg.Database.AutoMigrate)
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/maynagashev/gophkeeper/server/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeMigrator имитирует мигратор: Up доводит версию до последней, Down уменьшает ее на единицу,
+// MigrateTo устанавливает запрошенную версию.
+type fakeMigrator struct {
+	status    repository.MigrationStatus
+	statusErr error
+	upErr     error
+	upCalls   int
+	downCalls int
+	migrateTo []uint
+	closed    bool
+}
+
+func (f *fakeMigrator) Up() error {
+	f.upCalls++
+	if f.upErr != nil {
+		return f.upErr
+	}
+	f.status.Version = f.status.Latest
+	return nil
+}
+
+func (f *fakeMigrator) Down() error {
+	f.downCalls++
+	f.status.Version--
+	return nil
+}
+
+func (f *fakeMigrator) MigrateTo(version uint) error {
+	f.migrateTo = append(f.migrateTo, version)
+	f.status.Version = version
+	return nil
+}
+
+func (f *fakeMigrator) Status() (repository.MigrationStatus, error) {
+	return f.status, f.statusErr
+}
+
+func (f *fakeMigrator) Close() error {
+	f.closed = true
+	return nil
+}
+
+// stubMigrator подменяет newMigrator на время теста.
+func stubMigrator(t *testing.T, m *fakeMigrator) *string {
+	t.Helper()
+	original := newMigrator
+	t.Cleanup(func() { newMigrator = original })
+	var usedDSN string
+	newMigrator = func(dsn string) (schemaMigrator, error) {
+		usedDSN = dsn
+		return m, nil
+	}
+	return &usedDSN
+}
+
+func TestRunMigrateCommand(t *testing.T) {
+	t.Run("Применение миграций", func(t *testing.T) {
+		m := &fakeMigrator{status: repository.MigrationStatus{Version: 5, Latest: 7}}
+		usedDSN := stubMigrator(t, m)
+		var out bytes.Buffer
+
+		err := runMigrateCommand([]string{"-database-dsn=postgres://db", "up"}, &out)
+
+		require.NoError(t, err)
+		assert.Equal(t, "postgres://db", *usedDSN)
+		assert.Equal(t, 1, m.upCalls)
+		assert.True(t, m.closed)
+		assert.Contains(t, out.String(), "Версия схемы БД: 7")
+		assert.Contains(t, out.String(), "схема актуальна")
+	})
+
+	t.Run("Откат миграции с DSN из окружения", func(t *testing.T) {
+		m := &fakeMigrator{status: repository.MigrationStatus{Version: 7, Latest: 7}}
+		usedDSN := stubMigrator(t, m)
+		os.Setenv(envDatabaseDSN, "postgres://env")
+		defer os.Unsetenv(envDatabaseDSN)
+		var out bytes.Buffer
+
+		err := runMigrateCommand([]string{"down"}, &out)
+
+		require.NoError(t, err)
+		assert.Equal(t, "postgres://env", *usedDSN)
+		assert.Equal(t, 1, m.downCalls)
+		assert.Contains(t, out.String(), "не применено миграций: 1")
+	})
+
+	t.Run("Состояние схемы", func(t *testing.T) {
+		m := &fakeMigrator{status: repository.MigrationStatus{Version: 8, Latest: 7}}
+		stubMigrator(t, m)
+		var out bytes.Buffer
+
+		err := runMigrateCommand([]string{"-database-dsn=postgres://db", "status"}, &out)
+
+		require.NoError(t, err)
+		assert.Zero(t, m.upCalls)
+		assert.Contains(t, out.String(), "схема новее")
+	})
+
+	t.Run("Неизвестное действие", func(t *testing.T) {
+		stubMigrator(t, &fakeMigrator{})
+		var out bytes.Buffer
+
+		err := runMigrateCommand([]string{"-database-dsn=postgres://db", "redo"}, &out)
+
+		require.Error(t, err)
+		assert.Contains(t, out.String(), "Использование")
+	})
+
+	t.Run("Без строки подключения", func(t *testing.T) {
+		stubMigrator(t, &fakeMigrator{})
+		os.Unsetenv(envDatabaseDSN)
+
+		err := runMigrateCommand([]string{"status"}, &bytes.Buffer{})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "не указана строка подключения")
+	})
+}
+
+func TestPrepareSchema(t *testing.T) {
+	t.Run("Схема новее сервера", func(t *testing.T) {
+		m := &fakeMigrator{status: repository.MigrationStatus{Version: 8, Latest: 7}}
+
+		err := prepareSchema(m, true)
+
+		require.ErrorIs(t, err, repository.ErrSchemaAhead)
+		assert.Zero(t, m.upCalls, "миграции не должны применяться к более новой схеме")
+	})
+
+	t.Run("Схема актуальна", func(t *testing.T) {
+		m := &fakeMigrator{status: repository.MigrationStatus{Version: 7, Latest: 7}}
+
+		require.NoError(t, prepareSchema(m, true))
+		assert.Zero(t, m.upCalls)
+	})
+
+	t.Run("Автоматическое применение миграций", func(t *testing.T) {
+		m := &fakeMigrator{status: repository.MigrationStatus{Version: 0, Latest: 7}}
+
+		require.NoError(t, prepareSchema(m, true))
+		assert.Equal(t, 1, m.upCalls)
+	})
+
+	t.Run("Без -auto-migrate миграции не применяются", func(t *testing.T) {
+		m := &fakeMigrator{status: repository.MigrationStatus{Version: 5, Latest: 7}}
+
+		require.NoError(t, prepareSchema(m, false))
+		assert.Zero(t, m.upCalls)
+	})
+
+	t.Run("Ошибка применения миграций", func(t *testing.T) {
+		m := &fakeMigrator{status: repository.MigrationStatus{Version: 5, Latest: 7}, upErr: errors.New("syntax error")}
+
+		require.Error(t, prepareSchema(m, true))
+	})
+
+	t.Run("Ошибка получения версии", func(t *testing.T) {
+		m := &fakeMigrator{statusErr: errors.New("connection refused")}
+
+		require.Error(t, prepareSchema(m, true))
+	})
+}
@@ -0,0 +1,633 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/maynagashev/gophkeeper/models"
+	"github.com/maynagashev/gophkeeper/server/internal/handlers"
+	"github.com/maynagashev/gophkeeper/server/internal/metrics"
+	"github.com/maynagashev/gophkeeper/server/internal/mocks"
+	"github.com/maynagashev/gophkeeper/server/internal/openapi"
+	"github.com/maynagashev/gophkeeper/server/internal/services"
+)
+
+const (
+	contractJWTSecret  = "test-secret"
+	contractAdminToken = "admin-token"
+	contractMaxUpload  = 1024
+)
+
+// contractDeps - моки сервисов, с которыми работают обработчики в контрактных тестах.
+type contractDeps struct {
+	auth       *mocks.AuthService
+	vault      *mocks.VaultService
+	subscriber *mocks.Subscriber
+	webhooks   *mocks.WebhookService
+	emergency  *mocks.EmergencyAccessService
+	health     *mocks.HealthService
+	integrity  *mocks.IntegrityService
+	userAdmin  *mocks.UserAdminService
+}
+
+// newContractRouter создает роутер сервера со всеми маршрутами и моками сервисов.
+func newContractRouter(t *testing.T) (*chi.Mux, contractDeps) {
+	t.Helper()
+	deps := contractDeps{
+		auth:       mocks.NewAuthService(t),
+		vault:      mocks.NewVaultService(t),
+		subscriber: mocks.NewSubscriber(t),
+		webhooks:   mocks.NewWebhookService(t),
+		emergency:  mocks.NewEmergencyAccessService(t),
+		health:     mocks.NewHealthService(t),
+		integrity:  mocks.NewIntegrityService(t),
+		userAdmin:  mocks.NewUserAdminService(t),
+	}
+	appMetrics := metrics.New()
+	r := setupRouter(routeHandlers{
+		auth:            handlers.NewAuthHandler(deps.auth),
+		vault:           handlers.NewVaultHandler(deps.vault),
+		events:          handlers.NewEventsHandler(deps.subscriber),
+		webhooks:        handlers.NewWebhookHandler(deps.webhooks),
+		emergency:       handlers.NewEmergencyAccessHandler(deps.emergency),
+		health:          handlers.NewHealthHandler(deps.health),
+		admin:           handlers.NewAdminHandler(deps.integrity, deps.userAdmin),
+		metricsEndpoint: appMetrics.Handler(),
+	}, routerOptions{
+		adminToken: contractAdminToken, jwtSecret: contractJWTSecret, accounts: testAccounts,
+		maxUploadSize: contractMaxUpload,
+	})
+	return r, deps
+}
+
+func TestOpenAPISpecCoversRoutes(t *testing.T) {
+	doc, err := openapi.Load()
+	require.NoError(t, err)
+	r, _ := newContractRouter(t)
+
+	var routes []string
+	require.NoError(t, chi.Walk(r, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		if len(route) > 1 {
+			route = strings.TrimSuffix(route, "/")
+		}
+		routes = append(routes, method+" "+route)
+		return nil
+	}))
+
+	var documented []string
+	for path, item := range doc.Paths.Map() {
+		for method := range item.Operations() {
+			documented = append(documented, method+" "+path)
+		}
+	}
+
+	sort.Strings(routes)
+	sort.Strings(documented)
+	assert.Equal(t, routes, documented,
+		"Маршруты setupRouter и спецификации OpenAPI различаются: обновите internal/openapi/operations.go")
+}
+
+// contractCase - запрос к API и ожидаемый статус ответа.
+type contractCase struct {
+	name    string
+	method  string
+	path    string // Относительно /api для операций API (api=true)
+	api     bool
+	auth    string // Роль пользователя в JWT, authAdminTokenHeader или пусто - без аутентификации
+	body    string
+	headers map[string]string
+	setup   func(d contractDeps)
+	status  int
+	// invalidRequest - запрос намеренно не соответствует спецификации (проверяется ответ об ошибке).
+	invalidRequest bool
+}
+
+const authAdminTokenHeader = "X-Admin-Token"
+
+//nolint:funlen,maintidx // Таблица запросов ко всем операциям API
+func contractCases() []contractCase {
+	data := []byte("kdbx file content")
+	sum := sha256.Sum256(data)
+	checksum := hex.EncodeToString(sum[:])
+	size := int64(len(data))
+	createdAt := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	version := &models.VaultVersion{
+		ID: 7, VaultID: 3, ObjectKey: "user_1/vault.kdbx", Checksum: &checksum, SizeBytes: &size,
+		CreatedAt: createdAt, ContentModifiedAt: &createdAt,
+	}
+	webhook := &models.Webhook{ID: 5, UserID: 1, URL: "https://example.com/hook", IsActive: true, CreatedAt: createdAt}
+	requestedAt := createdAt.Add(-48 * time.Hour)
+	access := &models.EmergencyAccess{
+		ID: 3, OwnerID: 1, OwnerUsername: "owner", ContactID: 2, ContactUsername: "contact", WaitPeriodHours: 24,
+		Status: models.EmergencyAccessGranted, RequestedAt: &requestedAt, AvailableAt: &createdAt,
+		CreatedAt: createdAt, UpdatedAt: createdAt,
+	}
+	report := &models.StorageScrubReport{StartedAt: createdAt, FinishedAt: createdAt, Checked: 1, OK: 1}
+	fileReader := func() io.ReadCloser { return io.NopCloser(strings.NewReader(string(data))) }
+	uploadHeaders := map[string]string{
+		"Content-Type":               "application/octet-stream",
+		"X-Kdbx-Content-Modified-At": createdAt.Format(time.RFC3339),
+	}
+	jsonHeaders := map[string]string{"Content-Type": "application/json"}
+
+	return []contractCase{
+		// --- Служебные маршруты --- //
+		{name: "ping", method: http.MethodGet, path: "/ping", status: http.StatusOK},
+		{name: "healthz", method: http.MethodGet, path: "/healthz", status: http.StatusOK},
+		{
+			name: "readyz", method: http.MethodGet, path: "/readyz", status: http.StatusOK,
+			setup: func(d contractDeps) {
+				d.health.EXPECT().Ready(mock.Anything).Return(models.HealthReport{
+					Status: models.HealthStatusOK, CheckedAt: &createdAt,
+					Checks: map[string]models.HealthCheck{"database": {Status: models.HealthStatusOK, DurationMS: 1}},
+				})
+			},
+		},
+		{
+			name: "readyz недоступна БД", method: http.MethodGet, path: "/readyz", status: http.StatusServiceUnavailable,
+			setup: func(d contractDeps) {
+				d.health.EXPECT().Ready(mock.Anything).Return(models.HealthReport{
+					Status: models.HealthStatusFail,
+					Checks: map[string]models.HealthCheck{"database": {Status: models.HealthStatusFail, Error: "нет связи"}},
+				})
+			},
+		},
+		{name: "metrics", method: http.MethodGet, path: "/metrics", status: http.StatusOK},
+		{name: "openapi.json", method: http.MethodGet, path: "/api/openapi.json", status: http.StatusOK},
+
+		// --- Авторизация --- //
+		{
+			name: "register", method: http.MethodPost, path: "/register", api: true, status: http.StatusCreated,
+			body: `{"username":"user","password":"secret"}`, headers: jsonHeaders,
+			setup: func(d contractDeps) {
+				d.auth.EXPECT().Register(mock.Anything, "user", "secret").Return(nil)
+			},
+		},
+		{
+			name: "register имя занято", method: http.MethodPost, path: "/register", api: true, status: http.StatusConflict,
+			body: `{"username":"user","password":"secret"}`, headers: jsonHeaders,
+			setup: func(d contractDeps) {
+				d.auth.EXPECT().Register(mock.Anything, "user", "secret").Return(services.ErrUsernameTaken)
+			},
+		},
+		{
+			name: "register неверный JSON", method: http.MethodPost, path: "/register", api: true,
+			status: http.StatusBadRequest, body: `{"username":`, headers: jsonHeaders, invalidRequest: true,
+		},
+		{
+			name: "login", method: http.MethodPost, path: "/login", api: true, status: http.StatusOK,
+			body: `{"username":"user","password":"secret"}`, headers: jsonHeaders,
+			setup: func(d contractDeps) {
+				d.auth.EXPECT().Login(mock.Anything, "user", "secret", mock.Anything).Return("jwt", nil)
+			},
+		},
+		{
+			name: "login неверный пароль", method: http.MethodPost, path: "/login", api: true,
+			status: http.StatusUnauthorized, body: `{"username":"user","password":"wrong"}`, headers: jsonHeaders,
+			setup: func(d contractDeps) {
+				d.auth.EXPECT().Login(mock.Anything, "user", "wrong", mock.Anything).Return("", services.ErrInvalidCredentials)
+			},
+		},
+		{
+			name: "login учетная запись отключена", method: http.MethodPost, path: "/login", api: true,
+			status: http.StatusForbidden, body: `{"username":"user","password":"secret"}`, headers: jsonHeaders,
+			setup: func(d contractDeps) {
+				d.auth.EXPECT().Login(mock.Anything, "user", "secret", mock.Anything).Return("", services.ErrAccountDisabled)
+			},
+		},
+
+		// --- Хранилище --- //
+		{
+			name: "metadata", method: http.MethodGet, path: "/vault", api: true, auth: models.RoleUser,
+			status: http.StatusOK,
+			setup: func(d contractDeps) {
+				d.vault.EXPECT().GetVaultMetadata(mock.Anything, int64(1)).Return(version, nil)
+			},
+		},
+		{
+			name: "metadata без хранилища", method: http.MethodGet, path: "/vault", api: true, auth: models.RoleUser,
+			status: http.StatusNotFound,
+			setup: func(d contractDeps) {
+				d.vault.EXPECT().GetVaultMetadata(mock.Anything, int64(1)).Return(nil, services.ErrVaultNotFound)
+			},
+		},
+		{name: "metadata без токена", method: http.MethodGet, path: "/vault", api: true, status: http.StatusUnauthorized},
+		{
+			name: "upload", method: http.MethodPost, path: "/vault/upload", api: true, auth: models.RoleUser,
+			status: http.StatusOK, body: string(data), headers: uploadHeaders,
+			setup: func(d contractDeps) {
+				d.vault.EXPECT().UploadVault(mock.Anything, int64(1), mock.Anything, size,
+					"application/octet-stream", createdAt, mock.Anything).Return(version, nil)
+			},
+		},
+		{
+			name: "upload конфликт версий", method: http.MethodPost, path: "/vault/upload", api: true,
+			auth: models.RoleUser, status: http.StatusConflict, body: string(data), headers: uploadHeaders,
+			setup: func(d contractDeps) {
+				d.vault.EXPECT().UploadVault(mock.Anything, int64(1), mock.Anything, size,
+					"application/octet-stream", createdAt, mock.Anything).Return(nil, services.ErrConflictVersion)
+			},
+		},
+		{
+			name: "upload без времени изменения", method: http.MethodPost, path: "/vault/upload", api: true,
+			auth: models.RoleUser, status: http.StatusBadRequest, body: string(data), invalidRequest: true,
+			headers: map[string]string{"Content-Type": "application/octet-stream"},
+		},
+		{
+			name: "upload превышен размер", method: http.MethodPost, path: "/vault/upload", api: true,
+			auth: models.RoleUser, status: http.StatusRequestEntityTooLarge,
+			body: strings.Repeat("x", contractMaxUpload+1), headers: uploadHeaders,
+		},
+		{
+			name: "upload не база KeePass", method: http.MethodPost, path: "/vault/upload", api: true,
+			auth: models.RoleUser, status: http.StatusUnsupportedMediaType, body: string(data), headers: uploadHeaders,
+			setup: func(d contractDeps) {
+				d.vault.EXPECT().UploadVault(mock.Anything, int64(1), mock.Anything, size,
+					"application/octet-stream", createdAt, mock.Anything).Return(nil, services.ErrInvalidVaultFile)
+			},
+		},
+		{
+			name: "download", method: http.MethodGet, path: "/vault/download", api: true, auth: models.RoleUser,
+			status: http.StatusOK,
+			setup: func(d contractDeps) {
+				d.vault.EXPECT().GetVaultMetadata(mock.Anything, int64(1)).Return(version, nil)
+				d.vault.EXPECT().DownloadVersion(mock.Anything, version, int64(0), int64(-1)).Return(fileReader(), nil)
+			},
+		},
+		{
+			name: "download части файла", method: http.MethodGet, path: "/vault/download", api: true,
+			auth: models.RoleUser, status: http.StatusPartialContent, headers: map[string]string{"Range": "bytes=5-"},
+			setup: func(d contractDeps) {
+				d.vault.EXPECT().GetVaultMetadata(mock.Anything, int64(1)).Return(version, nil)
+				d.vault.EXPECT().DownloadVersion(mock.Anything, version, int64(5), size-5).
+					Return(io.NopCloser(strings.NewReader(string(data[5:]))), nil)
+			},
+		},
+		{
+			name: "download недостижимый диапазон", method: http.MethodGet, path: "/vault/download", api: true,
+			auth: models.RoleUser, status: http.StatusRequestedRangeNotSatisfiable,
+			headers: map[string]string{"Range": "bytes=1000-"},
+			setup: func(d contractDeps) {
+				d.vault.EXPECT().GetVaultMetadata(mock.Anything, int64(1)).Return(version, nil)
+			},
+		},
+		{
+			name: "download без изменений", method: http.MethodGet, path: "/vault/download", api: true,
+			auth: models.RoleUser, status: http.StatusNotModified,
+			headers: map[string]string{"If-None-Match": `"` + checksum + `"`},
+			setup: func(d contractDeps) {
+				d.vault.EXPECT().GetVaultMetadata(mock.Anything, int64(1)).Return(version, nil)
+			},
+		},
+		{
+			name: "download без хранилища", method: http.MethodGet, path: "/vault/download", api: true,
+			auth: models.RoleUser, status: http.StatusNotFound,
+			setup: func(d contractDeps) {
+				d.vault.EXPECT().GetVaultMetadata(mock.Anything, int64(1)).Return(nil, services.ErrVaultNotFound)
+			},
+		},
+		{
+			name: "head download", method: http.MethodHead, path: "/vault/download", api: true, auth: models.RoleUser,
+			status: http.StatusOK,
+			setup: func(d contractDeps) {
+				d.vault.EXPECT().GetVaultMetadata(mock.Anything, int64(1)).Return(version, nil)
+			},
+		},
+		{
+			name: "versions", method: http.MethodGet, path: "/vault/versions?limit=10&offset=0", api: true,
+			auth: models.RoleUser, status: http.StatusOK,
+			setup: func(d contractDeps) {
+				d.vault.EXPECT().ListVersions(mock.Anything, int64(1), 10, 0).Return([]models.VaultVersion{*version}, nil)
+				d.vault.EXPECT().GetVaultMetadata(mock.Anything, int64(1)).Return(version, nil)
+			},
+		},
+		{
+			name: "rollback", method: http.MethodPost, path: "/vault/rollback", api: true, auth: models.RoleUser,
+			status: http.StatusNoContent, body: `{"version_id":6}`, headers: jsonHeaders,
+			setup: func(d contractDeps) {
+				d.vault.EXPECT().RollbackToVersion(mock.Anything, int64(1), int64(6)).Return(nil)
+			},
+		},
+		{
+			name: "rollback версия не найдена", method: http.MethodPost, path: "/vault/rollback", api: true,
+			auth: models.RoleUser, status: http.StatusNotFound, body: `{"version_id":6}`, headers: jsonHeaders,
+			setup: func(d contractDeps) {
+				d.vault.EXPECT().RollbackToVersion(mock.Anything, int64(1), int64(6)).Return(services.ErrVersionNotFound)
+			},
+		},
+		{
+			name: "rollback неверный ID", method: http.MethodPost, path: "/vault/rollback", api: true,
+			auth: models.RoleUser, status: http.StatusBadRequest, body: `{"version_id":0}`, headers: jsonHeaders,
+		},
+		{
+			name: "events", method: http.MethodGet, path: "/vault/events", api: true, auth: models.RoleUser,
+			status: http.StatusOK,
+			setup: func(d contractDeps) {
+				d.subscriber.EXPECT().Subscribe(int64(1)).RunAndReturn(func(int64) (<-chan models.VaultEvent, func()) {
+					ch := make(chan models.VaultEvent, 1)
+					ch <- models.VaultEvent{Type: models.VaultEventVersionCreated, UserID: 1, VersionID: 7}
+					close(ch) // Закрытый канал завершает поток после отправки события
+					return ch, func() {}
+				})
+			},
+		},
+
+		// --- Вебхуки --- //
+		{
+			name: "webhooks", method: http.MethodGet, path: "/webhooks", api: true, auth: models.RoleUser,
+			status: http.StatusOK,
+			setup: func(d contractDeps) {
+				d.webhooks.EXPECT().ListWebhooks(mock.Anything, int64(1)).Return([]models.Webhook{*webhook}, nil)
+			},
+		},
+		{
+			name: "webhooks пустой список", method: http.MethodGet, path: "/webhooks", api: true, auth: models.RoleUser,
+			status: http.StatusOK,
+			setup: func(d contractDeps) {
+				d.webhooks.EXPECT().ListWebhooks(mock.Anything, int64(1)).Return(nil, nil)
+			},
+		},
+		{
+			name: "create webhook", method: http.MethodPost, path: "/webhooks", api: true, auth: models.RoleUser,
+			status: http.StatusCreated, body: `{"url":"https://example.com/hook"}`, headers: jsonHeaders,
+			setup: func(d contractDeps) {
+				d.webhooks.EXPECT().CreateWebhook(mock.Anything, int64(1), "https://example.com/hook", "").
+					Return(webhook, nil)
+			},
+		},
+		{
+			name: "create webhook неверный URL", method: http.MethodPost, path: "/webhooks", api: true,
+			auth: models.RoleUser, status: http.StatusBadRequest, body: `{"url":"ftp://x"}`, headers: jsonHeaders,
+			setup: func(d contractDeps) {
+				d.webhooks.EXPECT().CreateWebhook(mock.Anything, int64(1), "ftp://x", "").
+					Return(nil, services.ErrInvalidWebhookURL)
+			},
+		},
+		{
+			name: "delete webhook", method: http.MethodDelete, path: "/webhooks/5", api: true, auth: models.RoleUser,
+			status: http.StatusNoContent,
+			setup: func(d contractDeps) {
+				d.webhooks.EXPECT().DeleteWebhook(mock.Anything, int64(1), int64(5)).Return(nil)
+			},
+		},
+		{
+			name: "delete webhook не найден", method: http.MethodDelete, path: "/webhooks/5", api: true,
+			auth: models.RoleUser, status: http.StatusNotFound,
+			setup: func(d contractDeps) {
+				d.webhooks.EXPECT().DeleteWebhook(mock.Anything, int64(1), int64(5)).Return(services.ErrWebhookNotFound)
+			},
+		},
+		{
+			name: "webhook deliveries", method: http.MethodGet, path: "/webhooks/5/deliveries", api: true,
+			auth: models.RoleUser, status: http.StatusOK,
+			setup: func(d contractDeps) {
+				status := http.StatusOK
+				d.webhooks.EXPECT().ListDeliveries(mock.Anything, int64(1), int64(5), 20, 0).Return([]models.WebhookDelivery{{
+					ID: 1, WebhookID: 5, EventType: models.VaultEventVersionCreated, Payload: []byte(`{"type":"version_created"}`),
+					Status: models.WebhookDeliveryDelivered, Attempts: 1, NextAttemptAt: createdAt, ResponseStatus: &status,
+					CreatedAt: createdAt, DeliveredAt: &createdAt,
+				}}, nil)
+			},
+		},
+		{
+			name: "webhook deliveries неверный ID", method: http.MethodGet, path: "/webhooks/abc/deliveries", api: true,
+			auth: models.RoleUser, status: http.StatusBadRequest, invalidRequest: true,
+		},
+
+		// --- Экстренный доступ --- //
+		{
+			name: "versions хранилища владельца", method: http.MethodGet, path: "/vault/versions?owner_id=2", api: true,
+			auth: models.RoleUser, status: http.StatusOK,
+			setup: func(d contractDeps) {
+				d.emergency.EXPECT().CheckAccess(mock.Anything, int64(1), int64(2)).Return(nil)
+				d.vault.EXPECT().ListVersions(mock.Anything, int64(2), 20, 0).Return([]models.VaultVersion{*version}, nil)
+				d.vault.EXPECT().GetVaultMetadata(mock.Anything, int64(2)).Return(version, nil)
+			},
+		},
+		{
+			name: "download хранилища владельца без доступа", method: http.MethodGet, path: "/vault/download?owner_id=2",
+			api: true, auth: models.RoleUser, status: http.StatusForbidden,
+			setup: func(d contractDeps) {
+				d.emergency.EXPECT().CheckAccess(mock.Anything, int64(1), int64(2)).
+					Return(services.ErrEmergencyAccessNotGranted)
+			},
+		},
+		{
+			name: "emergency contacts", method: http.MethodGet, path: "/emergency/contacts", api: true,
+			auth: models.RoleUser, status: http.StatusOK,
+			setup: func(d contractDeps) {
+				d.emergency.EXPECT().ListContacts(mock.Anything, int64(1)).Return([]models.EmergencyAccess{*access}, nil)
+			},
+		},
+		{
+			name: "set emergency contact", method: http.MethodPost, path: "/emergency/contacts", api: true,
+			auth: models.RoleUser, status: http.StatusOK, body: `{"username":"contact","wait_period_hours":24}`,
+			headers: jsonHeaders,
+			setup: func(d contractDeps) {
+				d.emergency.EXPECT().SetContact(mock.Anything, int64(1), "contact", 24).Return(access, nil)
+			},
+		},
+		{
+			name: "set emergency contact пользователь не найден", method: http.MethodPost, path: "/emergency/contacts",
+			api: true, auth: models.RoleUser, status: http.StatusNotFound, body: `{"username":"ghost","wait_period_hours":24}`,
+			headers: jsonHeaders,
+			setup: func(d contractDeps) {
+				d.emergency.EXPECT().SetContact(mock.Anything, int64(1), "ghost", 24).Return(nil, services.ErrUserNotFound)
+			},
+		},
+		{
+			name: "remove emergency contact", method: http.MethodDelete, path: "/emergency/contacts/2", api: true,
+			auth: models.RoleUser, status: http.StatusNoContent,
+			setup: func(d contractDeps) {
+				d.emergency.EXPECT().RemoveContact(mock.Anything, int64(1), int64(2)).Return(nil)
+			},
+		},
+		{
+			name: "set key envelope", method: http.MethodPut, path: "/emergency/contacts/2/envelope", api: true,
+			auth: models.RoleUser, status: http.StatusNoContent, body: `{"key_envelope":"ZW52ZWxvcGU="}`,
+			headers: jsonHeaders,
+			setup: func(d contractDeps) {
+				d.emergency.EXPECT().SetKeyEnvelope(mock.Anything, int64(1), int64(2), []byte("envelope")).Return(nil)
+			},
+		},
+		{
+			name: "deny emergency access", method: http.MethodPost, path: "/emergency/contacts/2/deny", api: true,
+			auth: models.RoleUser, status: http.StatusOK,
+			setup: func(d contractDeps) {
+				denied := *access
+				denied.Status, denied.AvailableAt = models.EmergencyAccessDenied, nil
+				d.emergency.EXPECT().DenyAccess(mock.Anything, int64(1), int64(2)).Return(&denied, nil)
+			},
+		},
+		{
+			name: "deny emergency access без запроса", method: http.MethodPost, path: "/emergency/contacts/2/deny",
+			api: true, auth: models.RoleUser, status: http.StatusConflict,
+			setup: func(d contractDeps) {
+				d.emergency.EXPECT().DenyAccess(mock.Anything, int64(1), int64(2)).Return(nil, services.ErrNoAccessRequest)
+			},
+		},
+		{
+			name: "emergency grants", method: http.MethodGet, path: "/emergency/grants", api: true,
+			auth: models.RoleUser, status: http.StatusOK,
+			setup: func(d contractDeps) {
+				d.emergency.EXPECT().ListGrants(mock.Anything, int64(1)).Return([]models.EmergencyAccess{*access}, nil)
+			},
+		},
+		{
+			name: "request emergency access", method: http.MethodPost, path: "/emergency/grants/2/request", api: true,
+			auth: models.RoleUser, status: http.StatusOK,
+			setup: func(d contractDeps) {
+				d.emergency.EXPECT().RequestAccess(mock.Anything, int64(1), int64(2)).Return(access, nil)
+			},
+		},
+		{
+			name: "key envelope", method: http.MethodGet, path: "/emergency/grants/2/envelope", api: true,
+			auth: models.RoleUser, status: http.StatusOK,
+			setup: func(d contractDeps) {
+				d.emergency.EXPECT().GetKeyEnvelope(mock.Anything, int64(1), int64(2)).Return([]byte("envelope"), nil)
+			},
+		},
+		{
+			name: "key envelope до открытия доступа", method: http.MethodGet, path: "/emergency/grants/2/envelope",
+			api: true, auth: models.RoleUser, status: http.StatusForbidden,
+			setup: func(d contractDeps) {
+				d.emergency.EXPECT().GetKeyEnvelope(mock.Anything, int64(1), int64(2)).
+					Return(nil, services.ErrEmergencyAccessNotGranted)
+			},
+		},
+
+		// --- Администрирование --- //
+		{
+			name: "storage integrity", method: http.MethodGet, path: "/admin/storage/integrity", api: true,
+			auth: authAdminTokenHeader, status: http.StatusOK,
+			setup: func(d contractDeps) {
+				d.integrity.EXPECT().ListProblems(mock.Anything, 20, 0).Return(nil, nil)
+				d.integrity.EXPECT().LastReport().Return(report)
+			},
+		},
+		{
+			name: "storage integrity без токена администратора", method: http.MethodGet, path: "/admin/storage/integrity",
+			api: true, status: http.StatusForbidden,
+		},
+		{
+			name: "storage scrub", method: http.MethodPost, path: "/admin/storage/scrub", api: true,
+			auth: authAdminTokenHeader, status: http.StatusOK,
+			setup: func(d contractDeps) {
+				d.integrity.EXPECT().ScrubOnce(mock.Anything).Return(report, nil)
+			},
+		},
+		{
+			name: "users", method: http.MethodGet, path: "/admin/users?search=us", api: true, auth: models.RoleAdmin,
+			status: http.StatusOK,
+			setup: func(d contractDeps) {
+				d.userAdmin.EXPECT().ListUsers(mock.Anything, "us", 20, 0).Return([]models.UserSummary{{
+					ID: 1, Username: "user", CreatedAt: createdAt, Role: models.RoleUser, VersionCount: 1, StorageBytes: size,
+				}}, nil)
+			},
+		},
+		{
+			name: "users обычному пользователю", method: http.MethodGet, path: "/admin/users", api: true,
+			auth: models.RoleUser, status: http.StatusForbidden,
+		},
+		{
+			name: "disable user", method: http.MethodPost, path: "/admin/users/user/disable", api: true,
+			auth: models.RoleAdmin, status: http.StatusNoContent,
+			setup: func(d contractDeps) {
+				d.userAdmin.EXPECT().SetUserDisabled(mock.Anything, "user", true).Return(nil)
+			},
+		},
+		{
+			name: "enable user не найден", method: http.MethodPost, path: "/admin/users/ghost/enable", api: true,
+			auth: models.RoleAdmin, status: http.StatusNotFound,
+			setup: func(d contractDeps) {
+				d.userAdmin.EXPECT().SetUserDisabled(mock.Anything, "ghost", false).Return(services.ErrUserNotFound)
+			},
+		},
+		{
+			name: "user storage", method: http.MethodGet, path: "/admin/users/user/storage", api: true,
+			auth: models.RoleAdmin, status: http.StatusOK,
+			setup: func(d contractDeps) {
+				d.userAdmin.EXPECT().GetStorageUsage(mock.Anything, "user").Return(&models.UserStorageUsage{
+					Username: "user", VersionCount: 1, StorageBytes: size, CurrentVersionID: &version.ID,
+				}, nil)
+			},
+		},
+		{
+			name: "stats", method: http.MethodGet, path: "/admin/stats", api: true, auth: models.RoleAdmin,
+			status: http.StatusOK,
+			setup: func(d contractDeps) {
+				d.userAdmin.EXPECT().ServerStats(mock.Anything).Return(&models.ServerStats{Users: 1, LastScrub: report}, nil)
+			},
+		},
+	}
+}
+
+// TestOpenAPIContract выполняет запросы к настоящим обработчикам через роутер сервера
+// и проверяет запросы и ответы по спецификации OpenAPI.
+func TestOpenAPIContract(t *testing.T) {
+	doc, err := openapi.Load()
+	require.NoError(t, err)
+
+	for _, tc := range contractCases() {
+		prefixes := []string{""}
+		if tc.api {
+			prefixes = []string{"/api/v2", "/api"}
+		}
+		for _, prefix := range prefixes {
+			t.Run(strings.TrimSpace(prefix+" "+tc.name), func(t *testing.T) {
+				r, deps := newContractRouter(t)
+				if tc.setup != nil {
+					tc.setup(deps)
+				}
+
+				var requestErr error
+				validator, err := openapi.NewValidator(doc, func(_ *http.Request, err error) {
+					if tc.invalidRequest && errors.Is(err, openapi.ErrInvalidRequest) {
+						requestErr = err
+						return
+					}
+					t.Errorf("%v", err)
+				})
+				require.NoError(t, err)
+
+				req := httptest.NewRequest(tc.method, prefix+tc.path, strings.NewReader(tc.body))
+				if tc.body != "" {
+					// Как и клиент, передаем размер тела в заголовке (его требует загрузка файла)
+					req.Header.Set("Content-Length", strconv.Itoa(len(tc.body)))
+				}
+				for name, value := range tc.headers {
+					req.Header.Set(name, value)
+				}
+				switch tc.auth {
+				case "":
+				case authAdminTokenHeader:
+					req.Header.Set(authAdminTokenHeader, contractAdminToken)
+				default:
+					req.Header.Set("Authorization", "Bearer "+signTestToken(t, contractJWTSecret, tc.auth))
+				}
+
+				rr := httptest.NewRecorder()
+				validator.Middleware(r).ServeHTTP(rr, req)
+
+				assert.Equal(t, tc.status, rr.Code, rr.Body.String())
+				if tc.invalidRequest {
+					assert.Error(t, requestErr, "Запрос должен не соответствовать спецификации")
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log"
+
+	"github.com/maynagashev/gophkeeper/server/internal/tlsutil"
+)
+
+// setupTLS готовит TLS-конфигурацию HTTPS- и gRPC-сервера: при tls.auto_generate создает самоподписанный
+// сертификат, если его еще нет, и загружает сертификат в Reloader, который перечитывает файлы при изменении
+// (см. tls.reload_interval).
+func setupTLS(cfg *config) (*tls.Config, *tlsutil.Reloader, error) {
+	if cfg.TLS.AutoGenerate {
+		bootstrap, err := tlsutil.EnsureSelfSigned(cfg.TLS.CertFile, cfg.TLS.KeyFile, cfg.TLS.Hosts)
+		if err != nil {
+			return nil, nil, fmt.Errorf("ошибка создания самоподписанного сертификата: %w", err)
+		}
+		if bootstrap != nil {
+			logBootstrap(cfg, bootstrap)
+		}
+	}
+
+	minVersion, err := tlsutil.ParseVersion(cfg.TLS.MinVersion)
+	if err != nil {
+		return nil, nil, err
+	}
+	cipherSuites, err := tlsutil.ParseCipherSuites(cfg.TLS.CipherSuites)
+	if err != nil {
+		return nil, nil, err
+	}
+	reloader, err := tlsutil.NewReloader(cfg.TLS.CertFile, cfg.TLS.KeyFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	log.Printf("Используется сертификат: %s", cfg.TLS.CertFile)
+	log.Printf("Используется ключ: %s", cfg.TLS.KeyFile)
+	return tlsutil.ServerConfig(reloader, minVersion, cipherSuites), reloader, nil
+}
+
+// logBootstrap выводит отпечатки созданных сертификатов, которые нужно передать клиентам.
+func logBootstrap(cfg *config, b *tlsutil.Bootstrap) {
+	log.Printf("Создан самоподписанный сертификат %s (ключ %s) для %v", cfg.TLS.CertFile, cfg.TLS.KeyFile,
+		cfg.TLS.Hosts)
+	log.Printf("Сертификат CA для установки на клиентах: %s", b.CAFile)
+	log.Printf("Отпечаток SHA-256 сертификата CA: %s", b.CAFingerprint)
+	log.Printf("Отпечаток SHA-256 открытого ключа сервера (SPKI) для закрепления на клиентах: %s", b.SPKIPin)
+}
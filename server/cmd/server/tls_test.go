@@ -0,0 +1,65 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/maynagashev/gophkeeper/server/internal/tlsutil"
+)
+
+func TestSetupTLS(t *testing.T) {
+	dir := t.TempDir()
+	cfg := defaultConfig()
+	cfg.TLS.CertFile = filepath.Join(dir, "server.crt")
+	cfg.TLS.KeyFile = filepath.Join(dir, "server.key")
+
+	t.Run("Без auto_generate отсутствующий сертификат - ошибка", func(t *testing.T) {
+		_, _, err := setupTLS(cfg)
+		require.Error(t, err)
+	})
+
+	cfg.TLS.AutoGenerate = true
+	cfg.TLS.MinVersion = tlsutil.Version13
+	serverConfig, _, err := setupTLS(cfg)
+	require.NoError(t, err)
+
+	// Клиент доверяет созданному CA
+	caPEM, err := os.ReadFile(filepath.Join(dir, tlsutil.CAFileName))
+	require.NoError(t, err)
+	roots := x509.NewCertPool()
+	require.True(t, roots.AppendCertsFromPEM(caPEM))
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", serverConfig)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+	go func() {
+		for {
+			conn, acceptErr := ln.Accept()
+			if acceptErr != nil {
+				return
+			}
+			_ = conn.(*tls.Conn).Handshake()
+			_ = conn.Close()
+		}
+	}()
+
+	t.Run("Подключение с проверкой по CA", func(t *testing.T) {
+		conn, dialErr := tls.Dial("tcp", ln.Addr().String(), &tls.Config{RootCAs: roots, ServerName: "localhost"})
+		require.NoError(t, dialErr)
+		assert.Equal(t, uint16(tls.VersionTLS13), conn.ConnectionState().Version)
+		_ = conn.Close()
+	})
+
+	t.Run("Версия ниже минимальной отклоняется", func(t *testing.T) {
+		_, dialErr := tls.Dial("tcp", ln.Addr().String(), &tls.Config{
+			RootCAs: roots, ServerName: "localhost", MaxVersion: tls.VersionTLS12,
+		})
+		require.Error(t, dialErr)
+	})
+}
@@ -0,0 +1,234 @@
+// Package backup создает и восстанавливает полные резервные копии сервера.
+//
+// Резервная копия - архив tar.gz со следующими записями:
+//
+//	db/<таблица>.jsonl - строки таблицы в формате JSON, по одной на строку файла;
+//	objects/<ключ>     - содержимое объектов файлового хранилища, на которые ссылаются версии;
+//	manifest.json      - манифест с версией схемы БД, размерами и контрольными суммами всех записей.
+//
+// Манифест записывается последним, поэтому архив создается за один проход без промежуточной копии.
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"time"
+)
+
+const (
+	// FormatVersion - версия формата архива. Увеличивается при несовместимых изменениях.
+	FormatVersion = 1
+	// MinSchemaVersion - первая версия схемы БД, поддерживающая восстановление (миграция 000010).
+	MinSchemaVersion = 10
+	// ManifestName - имя записи манифеста в архиве.
+	ManifestName = "manifest.json"
+
+	tablePrefix  = "db/"
+	tableSuffix  = ".jsonl"
+	objectPrefix = "objects/"
+)
+
+// Manifest описывает содержимое резервной копии.
+type Manifest struct {
+	FormatVersion int       `json:"format_version"`
+	CreatedAt     time.Time `json:"created_at"`
+	// SchemaVersion - версия схемы БД, из которой выгружены таблицы.
+	SchemaVersion uint    `json:"schema_version"`
+	Tables        []Entry `json:"tables"`
+	Objects       []Entry `json:"objects"`
+}
+
+// Entry описывает одну запись архива.
+type Entry struct {
+	// Name - имя таблицы или ключ объекта.
+	Name string `json:"name"`
+	// Rows - количество строк (только для таблиц).
+	Rows   int64  `json:"rows,omitempty"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+	// Checksum - контрольная сумма объекта, записанная в метаданных версии (только для объектов).
+	// Если она известна, содержимое объекта должно ей соответствовать.
+	Checksum *string `json:"checksum,omitempty"`
+}
+
+// tablePath возвращает путь записи таблицы в архиве.
+func tablePath(table string) string {
+	return tablePrefix + table + tableSuffix
+}
+
+// objectPath возвращает путь записи объекта в архиве.
+func objectPath(key string) string {
+	return objectPrefix + key
+}
+
+// archiveWriter последовательно записывает архив резервной копии.
+// Размер записи tar нужно знать заранее, поэтому каждая запись сначала сохраняется во временный файл.
+type archiveWriter struct {
+	gz *gzip.Writer
+	tw *tar.Writer
+}
+
+// newArchiveWriter создает archiveWriter поверх w.
+func newArchiveWriter(w io.Writer) *archiveWriter {
+	gz := gzip.NewWriter(w)
+	return &archiveWriter{gz: gz, tw: tar.NewWriter(gz)}
+}
+
+// add записывает в архив запись path с содержимым, которое формирует fill,
+// и возвращает ее размер и контрольную сумму.
+func (a *archiveWriter) add(path string, fill func(w io.Writer) error) (int64, string, error) {
+	tmp, err := os.CreateTemp("", "gophkeeper-backup-*")
+	if err != nil {
+		return 0, "", fmt.Errorf("ошибка создания временного файла: %w", err)
+	}
+	defer func() {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+	}()
+
+	h := sha256.New()
+	counter := &countingWriter{w: io.MultiWriter(tmp, h)}
+	if err = fill(counter); err != nil {
+		return 0, "", err
+	}
+	if _, err = tmp.Seek(0, io.SeekStart); err != nil {
+		return 0, "", fmt.Errorf("ошибка чтения временного файла: %w", err)
+	}
+	if err = a.write(path, counter.n, tmp); err != nil {
+		return 0, "", err
+	}
+	return counter.n, hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// write записывает в архив запись path размером size.
+func (a *archiveWriter) write(path string, size int64, r io.Reader) error {
+	header := &tar.Header{
+		Name:    path,
+		Mode:    0o600,
+		Size:    size,
+		ModTime: time.Now(),
+		Format:  tar.FormatPAX,
+	}
+	if err := a.tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("ошибка записи архива: %w", err)
+	}
+	if _, err := io.CopyN(a.tw, r, size); err != nil {
+		return fmt.Errorf("ошибка записи архива: %w", err)
+	}
+	return nil
+}
+
+// close записывает манифест и завершает архив.
+func (a *archiveWriter) close(manifest *Manifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ошибка формирования манифеста: %w", err)
+	}
+	if err = a.write(ManifestName, int64(len(data)), bytes.NewReader(data)); err != nil {
+		return err
+	}
+	if err = a.tw.Close(); err != nil {
+		return fmt.Errorf("ошибка записи архива: %w", err)
+	}
+	if err = a.gz.Close(); err != nil {
+		return fmt.Errorf("ошибка записи архива: %w", err)
+	}
+	return nil
+}
+
+// countingWriter подсчитывает количество записанных байт.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+// Write записывает p и увеличивает счетчик.
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// walkArchive читает записи архива по порядку и передает в fn путь и содержимое каждой записи, кроме манифеста.
+// Содержимое записи передается через хеширующий reader: после fn запись дочитывается,
+// а ее размер и контрольная сумма возвращаются в sums. Возвращает разобранный манифест.
+func walkArchive(r io.Reader, fn func(path string, r io.Reader) error) (*Manifest, map[string]entrySum, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+
+	sums := make(map[string]entrySum)
+	var manifest *Manifest
+	for {
+		header, nextErr := tr.Next()
+		if errors.Is(nextErr, io.EOF) {
+			break
+		}
+		if nextErr != nil {
+			return nil, nil, fmt.Errorf("%w: %w", ErrInvalidArchive, nextErr)
+		}
+		if header.Typeflag != tar.TypeReg {
+			return nil, nil, fmt.Errorf("%w: запись '%s' не является файлом", ErrInvalidArchive, header.Name)
+		}
+		if manifest != nil {
+			return nil, nil, fmt.Errorf("%w: запись '%s' после манифеста", ErrInvalidArchive, header.Name)
+		}
+		if header.Name == ManifestName {
+			if manifest, err = readManifest(tr); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+		if _, dup := sums[header.Name]; dup {
+			return nil, nil, fmt.Errorf("%w: запись '%s' повторяется", ErrInvalidArchive, header.Name)
+		}
+
+		h := sha256.New()
+		counter := &countingWriter{w: h}
+		body := io.TeeReader(tr, counter)
+		if fn != nil {
+			if err = fn(header.Name, body); err != nil {
+				return nil, nil, err
+			}
+		}
+		if _, err = io.Copy(io.Discard, body); err != nil {
+			return nil, nil, fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+		}
+		sums[header.Name] = entrySum{size: counter.n, hash: h}
+	}
+	if manifest == nil {
+		return nil, nil, fmt.Errorf("%w: нет манифеста", ErrInvalidArchive)
+	}
+	return manifest, sums, nil
+}
+
+// entrySum - фактические размер и контрольная сумма записи архива.
+type entrySum struct {
+	size int64
+	hash hash.Hash
+}
+
+// readManifest разбирает манифест и проверяет версию формата.
+func readManifest(r io.Reader) (*Manifest, error) {
+	var manifest Manifest
+	if err := json.NewDecoder(r).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("%w: ошибка чтения манифеста: %w", ErrInvalidArchive, err)
+	}
+	if manifest.FormatVersion != FormatVersion {
+		return nil, fmt.Errorf("%w: версия формата %d, поддерживается %d",
+			ErrUnsupportedFormat, manifest.FormatVersion, FormatVersion)
+	}
+	return &manifest, nil
+}
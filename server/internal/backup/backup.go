@@ -0,0 +1,322 @@
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/maynagashev/gophkeeper/server/internal/repository"
+	"github.com/maynagashev/gophkeeper/server/internal/storage"
+)
+
+const (
+	// restoreBatchSize - количество строк таблицы, передаваемых в БД за один вызов при восстановлении.
+	restoreBatchSize = 500
+	// maxRowSize - максимальный размер одной строки таблицы в архиве.
+	maxRowSize = 64 << 20
+	// objectContentType - тип содержимого восстановленных объектов.
+	objectContentType = "application/octet-stream"
+)
+
+// Create выгружает таблицы и все объекты, на которые они ссылаются, в архив w.
+// Таблицы и список объектов берутся из одного снимка БД, поэтому архив согласован, даже если сервер работает:
+// объекты версий не изменяются после загрузки. Если w - файл, его следует удалить при ошибке.
+func Create(
+	ctx context.Context,
+	repo repository.BackupRepository,
+	files storage.FileStorage,
+	w io.Writer,
+) (*Manifest, error) {
+	var manifest *Manifest
+	err := repo.Export(ctx, func(tx repository.BackupTx) error {
+		version, dirty, err := tx.SchemaVersion(ctx)
+		if err != nil {
+			return err
+		}
+		if dirty {
+			return fmt.Errorf("%w: миграция %d прервана", ErrSchemaMismatch, version)
+		}
+		if version < MinSchemaVersion {
+			return fmt.Errorf("%w: версия схемы БД %d, требуется не ниже %d (выполните 'migrate up')",
+				ErrSchemaMismatch, version, MinSchemaVersion)
+		}
+		manifest = &Manifest{FormatVersion: FormatVersion, CreatedAt: time.Now().UTC(), SchemaVersion: version}
+		archive := newArchiveWriter(w)
+
+		for _, table := range repository.BackupTables(version) {
+			entry, tableErr := exportTable(ctx, tx, archive, table)
+			if tableErr != nil {
+				return tableErr
+			}
+			log.Printf("[Backup] Таблица %s: строк %d", table, entry.Rows)
+			manifest.Tables = append(manifest.Tables, entry)
+		}
+
+		objects, err := tx.ListObjects(ctx)
+		if err != nil {
+			return err
+		}
+		for _, object := range objects {
+			entry, objectErr := exportObject(ctx, files, archive, object)
+			if objectErr != nil {
+				return objectErr
+			}
+			manifest.Objects = append(manifest.Objects, entry)
+		}
+		log.Printf("[Backup] Объектов: %d", len(manifest.Objects))
+
+		return archive.close(manifest)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// exportTable записывает строки таблицы в архив.
+func exportTable(ctx context.Context, tx repository.BackupTx, archive *archiveWriter, table string) (Entry, error) {
+	var rows int64
+	size, sum, err := archive.add(tablePath(table), func(w io.Writer) error {
+		bw := bufio.NewWriter(w)
+		var exportErr error
+		rows, exportErr = tx.ExportTable(ctx, table, func(row []byte) error {
+			if _, writeErr := bw.Write(row); writeErr != nil {
+				return writeErr
+			}
+			return bw.WriteByte('\n')
+		})
+		if exportErr != nil {
+			return exportErr
+		}
+		return bw.Flush()
+	})
+	if err != nil {
+		return Entry{}, err
+	}
+	return Entry{Name: table, Rows: rows, Size: size, SHA256: sum}, nil
+}
+
+// exportObject скачивает объект и записывает его в архив.
+// Объект с содержимым, не совпадающим с контрольной суммой версии, в копию не попадает.
+func exportObject(
+	ctx context.Context,
+	files storage.FileStorage,
+	archive *archiveWriter,
+	object repository.BackupObject,
+) (Entry, error) {
+	size, sum, err := archive.add(objectPath(object.Key), func(w io.Writer) error {
+		reader, err := files.DownloadFile(ctx, object.Key)
+		if err != nil {
+			return fmt.Errorf("ошибка скачивания объекта '%s': %w", object.Key, err)
+		}
+		defer reader.Close()
+		if _, err = io.Copy(w, reader); err != nil {
+			return fmt.Errorf("ошибка скачивания объекта '%s': %w", object.Key, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return Entry{}, err
+	}
+	if object.Checksum != nil && !strings.EqualFold(*object.Checksum, sum) {
+		return Entry{}, fmt.Errorf("%w: объект '%s' поврежден (проверьте его командой admin verify)",
+			ErrChecksumMismatch, object.Key)
+	}
+	return Entry{Name: object.Key, Size: size, SHA256: sum, Checksum: object.Checksum}, nil
+}
+
+// Verify читает архив целиком и проверяет его: версию формата, состав таблиц,
+// размеры и контрольные суммы всех записей и соответствие объектов контрольным суммам версий.
+// Для проверки не нужны ни БД, ни файловое хранилище. Возвращает манифест проверенного архива.
+func Verify(r io.Reader) (*Manifest, error) {
+	manifest, sums, err := walkArchive(r, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkManifest(manifest); err != nil {
+		return nil, err
+	}
+	if err = checkEntries(manifest, sums); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// Restore загружает архив, предварительно проверенный Verify, в пустую БД и файловое хранилище.
+// Версия схемы БД должна совпадать с версией в манифесте. Таблицы загружаются в одной транзакции,
+// которая фиксируется только после того, как все записи архива прочитаны и совпали с манифестом.
+// Объекты загружаются в хранилище по мере чтения архива и при ошибке остаются в нем;
+// повторное восстановление перезаписывает их.
+func Restore(
+	ctx context.Context,
+	repo repository.BackupRepository,
+	files storage.FileStorage,
+	manifest *Manifest,
+	r io.Reader,
+) error {
+	if err := checkManifest(manifest); err != nil {
+		return err
+	}
+	tables := make(map[string]Entry, len(manifest.Tables))
+	for _, entry := range manifest.Tables {
+		tables[tablePath(entry.Name)] = entry
+	}
+	objects := make(map[string]Entry, len(manifest.Objects))
+	for _, entry := range manifest.Objects {
+		objects[objectPath(entry.Name)] = entry
+	}
+
+	backupTables := repository.BackupTables(manifest.SchemaVersion)
+
+	return repo.Import(ctx, func(tx repository.BackupTx) error {
+		if err := checkTarget(ctx, tx, manifest.SchemaVersion, backupTables); err != nil {
+			return err
+		}
+
+		_, sums, err := walkArchive(r, func(path string, body io.Reader) error {
+			if entry, ok := tables[path]; ok {
+				return importTable(ctx, tx, entry.Name, body)
+			}
+			if entry, ok := objects[path]; ok {
+				if uploadErr := files.UploadFile(ctx, entry.Name, body, entry.Size, objectContentType); uploadErr != nil {
+					return fmt.Errorf("ошибка загрузки объекта '%s': %w", entry.Name, uploadErr)
+				}
+				return nil
+			}
+			return fmt.Errorf("%w: неизвестная запись '%s'", ErrInvalidArchive, path)
+		})
+		if err != nil {
+			return err
+		}
+		// Архив мог измениться после проверки: фиксируем транзакцию, только если записи снова совпали с манифестом
+		if err = checkEntries(manifest, sums); err != nil {
+			return err
+		}
+		if err = tx.ResetSequences(ctx, backupTables); err != nil {
+			return err
+		}
+		log.Printf("[Backup] Восстановлено таблиц: %d, объектов: %d", len(manifest.Tables), len(manifest.Objects))
+		return nil
+	})
+}
+
+// checkTarget проверяет, что БД пуста и ее схема совпадает со схемой резервной копии.
+// Пустота проверяется по таблицам резервной копии: в схеме ее версии других таблиц может не быть.
+func checkTarget(ctx context.Context, tx repository.BackupTx, schemaVersion uint, tables []string) error {
+	version, dirty, err := tx.SchemaVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if dirty || version != schemaVersion {
+		return fmt.Errorf("%w: версия схемы БД %d (dirty=%t), резервной копии - %d",
+			ErrSchemaMismatch, version, dirty, schemaVersion)
+	}
+	empty, err := tx.IsEmpty(ctx, tables)
+	if err != nil {
+		return err
+	}
+	if !empty {
+		return ErrTargetNotEmpty
+	}
+	return nil
+}
+
+// importTable загружает строки таблицы пакетами по restoreBatchSize.
+func importTable(ctx context.Context, tx repository.BackupTx, table string, body io.Reader) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxRowSize)
+	batch := make([][]byte, 0, restoreBatchSize)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		batch = append(batch, bytes.Clone(line))
+		if len(batch) == restoreBatchSize {
+			if err := tx.ImportRows(ctx, table, batch); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("%w: ошибка чтения таблицы %s: %w", ErrInvalidArchive, table, err)
+	}
+	if len(batch) > 0 {
+		return tx.ImportRows(ctx, table, batch)
+	}
+	return nil
+}
+
+// checkManifest проверяет версию схемы и что манифест содержит ровно таблицы резервной копии
+// этой версии схемы в порядке восстановления.
+func checkManifest(manifest *Manifest) error {
+	if manifest.SchemaVersion < MinSchemaVersion {
+		return fmt.Errorf("%w: версия схемы %d", ErrUnsupportedFormat, manifest.SchemaVersion)
+	}
+	names := make([]string, 0, len(manifest.Tables))
+	for _, entry := range manifest.Tables {
+		names = append(names, entry.Name)
+	}
+	if expected := repository.BackupTables(manifest.SchemaVersion); !slices.Equal(names, expected) {
+		return fmt.Errorf("%w: таблицы %v, ожидались %v", ErrInvalidArchive, names, expected)
+	}
+	return nil
+}
+
+// checkEntries сверяет фактические размеры и контрольные суммы записей архива с манифестом.
+func checkEntries(manifest *Manifest, sums map[string]entrySum) error {
+	check := func(path string, entry Entry) error {
+		sum, ok := sums[path]
+		if !ok {
+			return fmt.Errorf("%w: нет записи '%s'", ErrInvalidArchive, path)
+		}
+		delete(sums, path)
+		actual := hex.EncodeToString(sum.hash.Sum(nil))
+		if sum.size != entry.Size || actual != entry.SHA256 {
+			return fmt.Errorf("%w: запись '%s'", ErrChecksumMismatch, path)
+		}
+		if entry.Checksum != nil && !strings.EqualFold(*entry.Checksum, actual) {
+			return fmt.Errorf("%w: объект '%s' не совпадает с контрольной суммой версии", ErrChecksumMismatch, entry.Name)
+		}
+		return nil
+	}
+
+	for _, entry := range manifest.Tables {
+		if err := check(tablePath(entry.Name), entry); err != nil {
+			return err
+		}
+	}
+	for _, entry := range manifest.Objects {
+		if err := check(objectPath(entry.Name), entry); err != nil {
+			return err
+		}
+	}
+	if len(sums) > 0 {
+		extra := slices.Sorted(maps.Keys(sums))
+		return fmt.Errorf("%w: запись '%s' отсутствует в манифесте", ErrInvalidArchive, extra[0])
+	}
+	return nil
+}
+
+var (
+	// ErrInvalidArchive возвращается, если архив поврежден или не является резервной копией сервера.
+	ErrInvalidArchive = errors.New("некорректный архив резервной копии")
+	// ErrUnsupportedFormat возвращается для архива неподдерживаемой версии формата.
+	ErrUnsupportedFormat = errors.New("неподдерживаемая версия формата резервной копии")
+	// ErrChecksumMismatch возвращается, если содержимое записи не совпадает с контрольной суммой.
+	ErrChecksumMismatch = errors.New("контрольная сумма не совпадает")
+	// ErrSchemaMismatch возвращается, если схема БД не совпадает со схемой резервной копии.
+	ErrSchemaMismatch = errors.New("версия схемы БД не совпадает с резервной копией")
+	// ErrTargetNotEmpty возвращается при восстановлении в БД, в которой уже есть данные.
+	ErrTargetNotEmpty = errors.New("восстановление возможно только в пустую БД")
+)
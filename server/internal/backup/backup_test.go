@@ -0,0 +1,346 @@
+package backup_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/maynagashev/gophkeeper/server/internal/backup"
+	"github.com/maynagashev/gophkeeper/server/internal/mocks"
+	"github.com/maynagashev/gophkeeper/server/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testObjectContent = "kdbx-content"
+
+// testRows - строки таблиц, выгружаемые тестовой БД.
+var testRows = map[string][]string{ //nolint:gochecknoglobals // Тестовые данные
+	"users":          {`{"id":1,"username":"alice"}`, `{"id":2,"username":"bob"}`},
+	"vaults":         {`{"id":1,"user_id":1,"current_version_id":1}`},
+	"vault_versions": {`{"id":1,"vault_id":1,"object_key":"user_1/v1.kdbx"}`},
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// createTestArchive создает резервную копию тестовой БД с одним объектом.
+func createTestArchive(t *testing.T, objectContent string) ([]byte, *backup.Manifest, error) {
+	t.Helper()
+	return createArchiveAt(t, 10, objectContent)
+}
+
+// createArchiveAt создает резервную копию тестовой БД со схемой версии schemaVersion.
+func createArchiveAt(t *testing.T, schemaVersion uint, objectContent string) ([]byte, *backup.Manifest, error) {
+	t.Helper()
+	repo := mocks.NewBackupRepository(t)
+	tx := mocks.NewBackupTx(t)
+	files := mocks.NewFileStorage(t)
+	checksum := sha256Hex(testObjectContent)
+
+	repo.EXPECT().Export(mock.Anything, mock.Anything).
+		RunAndReturn(func(_ context.Context, fn func(repository.BackupTx) error) error { return fn(tx) })
+	tx.EXPECT().SchemaVersion(mock.Anything).Return(schemaVersion, false, nil)
+	tx.EXPECT().ExportTable(mock.Anything, mock.Anything, mock.Anything).
+		RunAndReturn(func(_ context.Context, table string, fn func([]byte) error) (int64, error) {
+			for _, row := range testRows[table] {
+				if err := fn([]byte(row)); err != nil {
+					return 0, err
+				}
+			}
+			return int64(len(testRows[table])), nil
+		})
+	tx.EXPECT().ListObjects(mock.Anything).
+		Return([]repository.BackupObject{{Key: "user_1/v1.kdbx", Checksum: &checksum}}, nil)
+	files.EXPECT().DownloadFile(mock.Anything, "user_1/v1.kdbx").
+		Return(io.NopCloser(strings.NewReader(objectContent)), nil)
+
+	var buf bytes.Buffer
+	manifest, err := backup.Create(context.Background(), repo, files, &buf)
+	return buf.Bytes(), manifest, err
+}
+
+// rewriteArchive перепаковывает архив, заменяя содержимое записей функцией edit
+// (nil - удалить запись) и добавляя записи extra перед манифестом.
+func rewriteArchive(
+	t *testing.T,
+	data []byte,
+	edit func(name string, body []byte) []byte,
+	extra map[string]string,
+) []byte {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	tr := tar.NewReader(gz)
+
+	var out bytes.Buffer
+	gw := gzip.NewWriter(&out)
+	tw := tar.NewWriter(gw)
+	write := func(name string, body []byte) {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o600, Size: int64(len(body))}))
+		_, writeErr := tw.Write(body)
+		require.NoError(t, writeErr)
+	}
+	for {
+		header, nextErr := tr.Next()
+		if nextErr == io.EOF {
+			break
+		}
+		require.NoError(t, nextErr)
+		body, readErr := io.ReadAll(tr)
+		require.NoError(t, readErr)
+		if header.Name == backup.ManifestName {
+			for name, content := range extra {
+				write(name, []byte(content))
+			}
+		}
+		if edit != nil {
+			body = edit(header.Name, body)
+		}
+		if body != nil {
+			write(header.Name, body)
+		}
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gw.Close())
+	return out.Bytes()
+}
+
+func TestCreateVerifyRestore(t *testing.T) {
+	ctx := context.Background()
+	data, manifest, err := createTestArchive(t, testObjectContent)
+	require.NoError(t, err)
+
+	assert.Equal(t, backup.FormatVersion, manifest.FormatVersion)
+	assert.Equal(t, uint(10), manifest.SchemaVersion)
+	require.Len(t, manifest.Tables, len(repository.BackupTables(10)))
+	assert.Equal(t, "users", manifest.Tables[0].Name)
+	assert.Equal(t, int64(2), manifest.Tables[0].Rows)
+	require.Len(t, manifest.Objects, 1)
+	assert.Equal(t, sha256Hex(testObjectContent), manifest.Objects[0].SHA256)
+	assert.Equal(t, int64(len(testObjectContent)), manifest.Objects[0].Size)
+
+	verified, err := backup.Verify(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, manifest.Objects, verified.Objects)
+
+	repo := mocks.NewBackupRepository(t)
+	tx := mocks.NewBackupTx(t)
+	files := mocks.NewFileStorage(t)
+	repo.EXPECT().Import(mock.Anything, mock.Anything).
+		RunAndReturn(func(_ context.Context, fn func(repository.BackupTx) error) error { return fn(tx) })
+	tx.EXPECT().SchemaVersion(mock.Anything).Return(10, false, nil)
+	tx.EXPECT().IsEmpty(mock.Anything, repository.BackupTables(10)).Return(true, nil)
+	imported := make(map[string][]string)
+	tx.EXPECT().ImportRows(mock.Anything, mock.Anything, mock.Anything).
+		RunAndReturn(func(_ context.Context, table string, rows [][]byte) error {
+			for _, row := range rows {
+				imported[table] = append(imported[table], string(row))
+			}
+			return nil
+		})
+	tx.EXPECT().ResetSequences(mock.Anything, repository.BackupTables(10)).Return(nil).Once()
+	var uploaded string
+	files.EXPECT().UploadFile(mock.Anything, "user_1/v1.kdbx", mock.Anything, int64(len(testObjectContent)),
+		"application/octet-stream").
+		RunAndReturn(func(_ context.Context, _ string, r io.Reader, _ int64, _ string) error {
+			body, readErr := io.ReadAll(r)
+			uploaded = string(body)
+			return readErr
+		}).Once()
+
+	err = backup.Restore(ctx, repo, files, verified, bytes.NewReader(data))
+
+	require.NoError(t, err)
+	assert.Equal(t, testRows, imported)
+	assert.Equal(t, testObjectContent, uploaded)
+}
+
+func TestRestore_OlderSchema(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Копия до появления экстренного доступа", func(t *testing.T) {
+		data, manifest, err := createArchiveAt(t, 12, testObjectContent)
+		require.NoError(t, err)
+		tables := make([]string, 0, len(manifest.Tables))
+		for _, table := range manifest.Tables {
+			tables = append(tables, table.Name)
+		}
+		assert.NotContains(t, tables, "emergency_access")
+
+		verified, err := backup.Verify(bytes.NewReader(data))
+		require.NoError(t, err)
+
+		repo := mocks.NewBackupRepository(t)
+		tx := mocks.NewBackupTx(t)
+		files := mocks.NewFileStorage(t)
+		repo.EXPECT().Import(mock.Anything, mock.Anything).
+			RunAndReturn(func(_ context.Context, fn func(repository.BackupTx) error) error { return fn(tx) })
+		tx.EXPECT().SchemaVersion(mock.Anything).Return(12, false, nil)
+		tx.EXPECT().IsEmpty(mock.Anything, tables).Return(true, nil).Once()
+		tx.EXPECT().ImportRows(mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		tx.EXPECT().ResetSequences(mock.Anything, tables).Return(nil).Once()
+		files.EXPECT().UploadFile(mock.Anything, "user_1/v1.kdbx", mock.Anything, mock.Anything, mock.Anything).
+			RunAndReturn(func(_ context.Context, _ string, r io.Reader, _ int64, _ string) error {
+				_, readErr := io.Copy(io.Discard, r)
+				return readErr
+			}).Once()
+
+		require.NoError(t, backup.Restore(ctx, repo, files, verified, bytes.NewReader(data)))
+	})
+
+	t.Run("Копия новой схемы содержит экстренный доступ", func(t *testing.T) {
+		_, manifest, err := createArchiveAt(t, 13, testObjectContent)
+		require.NoError(t, err)
+
+		require.Len(t, manifest.Tables, len(repository.BackupTables(13)))
+		assert.Equal(t, "emergency_access", manifest.Tables[2].Name)
+	})
+}
+
+func TestCreate_CorruptedObject(t *testing.T) {
+	_, _, err := createTestArchive(t, "damaged")
+
+	require.ErrorIs(t, err, backup.ErrChecksumMismatch)
+	assert.Contains(t, err.Error(), "user_1/v1.kdbx")
+}
+
+func TestVerify(t *testing.T) {
+	data, _, err := createTestArchive(t, testObjectContent)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		archive []byte
+		wantErr error
+	}{
+		{
+			name:    "Не архив",
+			archive: []byte("not a backup"),
+			wantErr: backup.ErrInvalidArchive,
+		},
+		{
+			name: "Измененный объект",
+			archive: rewriteArchive(t, data, func(name string, body []byte) []byte {
+				if name == "objects/user_1/v1.kdbx" {
+					return []byte("kdbx-CONTENT")
+				}
+				return body
+			}, nil),
+			wantErr: backup.ErrChecksumMismatch,
+		},
+		{
+			name: "Нет записи таблицы",
+			archive: rewriteArchive(t, data, func(name string, body []byte) []byte {
+				if name == "db/users.jsonl" {
+					return nil
+				}
+				return body
+			}, nil),
+			wantErr: backup.ErrInvalidArchive,
+		},
+		{
+			name:    "Лишняя запись",
+			archive: rewriteArchive(t, data, nil, map[string]string{"objects/user_2/v1.kdbx": "x"}),
+			wantErr: backup.ErrInvalidArchive,
+		},
+		{
+			name: "Нет манифеста",
+			archive: rewriteArchive(t, data, func(name string, body []byte) []byte {
+				if name == backup.ManifestName {
+					return nil
+				}
+				return body
+			}, nil),
+			wantErr: backup.ErrInvalidArchive,
+		},
+		{
+			name: "Неподдерживаемая версия формата",
+			archive: rewriteArchive(t, data, func(name string, body []byte) []byte {
+				if name != backup.ManifestName {
+					return body
+				}
+				var manifest map[string]any
+				require.NoError(t, json.Unmarshal(body, &manifest))
+				manifest["format_version"] = backup.FormatVersion + 1
+				edited, marshalErr := json.Marshal(manifest)
+				require.NoError(t, marshalErr)
+				return edited
+			}, nil),
+			wantErr: backup.ErrUnsupportedFormat,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, verifyErr := backup.Verify(bytes.NewReader(tt.archive))
+			require.ErrorIs(t, verifyErr, tt.wantErr)
+		})
+	}
+}
+
+func TestRestore_Target(t *testing.T) {
+	ctx := context.Background()
+	data, manifest, err := createTestArchive(t, testObjectContent)
+	require.NoError(t, err)
+
+	// setupImport возвращает репозиторий, выполняющий Import с моком транзакции.
+	setupImport := func(t *testing.T) (*mocks.BackupRepository, *mocks.BackupTx) {
+		repo := mocks.NewBackupRepository(t)
+		tx := mocks.NewBackupTx(t)
+		repo.EXPECT().Import(mock.Anything, mock.Anything).
+			RunAndReturn(func(_ context.Context, fn func(repository.BackupTx) error) error { return fn(tx) })
+		return repo, tx
+	}
+
+	t.Run("Непустая БД", func(t *testing.T) {
+		repo, tx := setupImport(t)
+		tx.EXPECT().SchemaVersion(mock.Anything).Return(10, false, nil)
+		tx.EXPECT().IsEmpty(mock.Anything, repository.BackupTables(10)).Return(false, nil)
+
+		restoreErr := backup.Restore(ctx, repo, mocks.NewFileStorage(t), manifest, bytes.NewReader(data))
+
+		require.ErrorIs(t, restoreErr, backup.ErrTargetNotEmpty)
+	})
+
+	t.Run("Другая версия схемы", func(t *testing.T) {
+		repo, tx := setupImport(t)
+		tx.EXPECT().SchemaVersion(mock.Anything).Return(11, false, nil)
+
+		restoreErr := backup.Restore(ctx, repo, mocks.NewFileStorage(t), manifest, bytes.NewReader(data))
+
+		require.ErrorIs(t, restoreErr, backup.ErrSchemaMismatch)
+	})
+
+	t.Run("Архив изменен после проверки", func(t *testing.T) {
+		repo, tx := setupImport(t)
+		files := mocks.NewFileStorage(t)
+		tx.EXPECT().SchemaVersion(mock.Anything).Return(10, false, nil)
+		tx.EXPECT().IsEmpty(mock.Anything, repository.BackupTables(10)).Return(true, nil)
+		tx.EXPECT().ImportRows(mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		files.EXPECT().UploadFile(mock.Anything, "user_1/v1.kdbx", mock.Anything, mock.Anything, mock.Anything).
+			RunAndReturn(func(_ context.Context, _ string, r io.Reader, _ int64, _ string) error {
+				_, readErr := io.Copy(io.Discard, r)
+				return readErr
+			})
+		tampered := rewriteArchive(t, data, func(name string, body []byte) []byte {
+			if name == "db/users.jsonl" {
+				return append(body, []byte(`{"id":3,"username":"mallory"}`+"\n")...)
+			}
+			return body
+		}, nil)
+
+		restoreErr := backup.Restore(ctx, repo, files, manifest, bytes.NewReader(tampered))
+
+		require.ErrorIs(t, restoreErr, backup.ErrChecksumMismatch)
+	})
+}
@@ -0,0 +1,203 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/maynagashev/gophkeeper/models"
+)
+
+const (
+	// NotifyChannel - канал Postgres LISTEN/NOTIFY, через который реплики сервера обмениваются событиями.
+	NotifyChannel = "gophkeeper_vault_events"
+
+	// Параметры переподключения слушателя Postgres.
+	listenerMinReconnect = 1 * time.Second
+	listenerMaxReconnect = 30 * time.Second
+	// listenerPingInterval - как часто проверять соединение слушателя при отсутствии уведомлений.
+	listenerPingInterval = 90 * time.Second
+
+	// subscriberBuffer - размер буфера канала подписчика.
+	// Если подписчик не успевает читать, лишние события отбрасываются: клиенту достаточно знать,
+	// что на сервере появилась новая версия, а актуальные метаданные он запросит сам.
+	subscriberBuffer = 8
+)
+
+// Publisher публикует события об изменении хранилищ.
+type Publisher interface {
+	Publish(ctx context.Context, event models.VaultEvent) error
+}
+
+// Subscriber позволяет подписаться на события хранилища конкретного пользователя.
+type Subscriber interface {
+	// Subscribe возвращает канал событий пользователя и функцию отписки.
+	// После отписки канал закрывается.
+	Subscribe(userID int64) (<-chan models.VaultEvent, func())
+}
+
+var (
+	_ Publisher  = (*Broker)(nil)
+	_ Subscriber = (*Broker)(nil)
+)
+
+// Broker рассылает события об изменении хранилищ подписчикам.
+//
+// События публикуются через pg_notify и принимаются всеми репликами сервера, слушающими
+// NotifyChannel (см. Listen). Каждая реплика раздает полученное событие своим локальным
+// подписчикам - открытым SSE-соединениям пользователя.
+type Broker struct {
+	db *sqlx.DB
+
+	mu          sync.RWMutex
+	subscribers map[int64]map[chan models.VaultEvent]struct{}
+	closed      bool // Подписчики закрыты при остановке сервера, новые подписки сразу закрываются
+}
+
+// NewBroker создает брокер событий, публикующий уведомления через указанную БД.
+func NewBroker(db *sqlx.DB) *Broker {
+	return &Broker{
+		db:          db,
+		subscribers: make(map[int64]map[chan models.VaultEvent]struct{}),
+	}
+}
+
+// Publish отправляет событие всем репликам сервера через pg_notify.
+// Локальные подписчики получат событие так же, как и остальные реплики, - через Listen.
+func (b *Broker) Publish(ctx context.Context, event models.VaultEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("ошибка кодирования события: %w", err)
+	}
+	if _, err = b.db.ExecContext(ctx, `SELECT pg_notify($1, $2)`, NotifyChannel, string(payload)); err != nil {
+		log.Printf("[EventBroker] Ошибка публикации события %s для пользователя %d: %v", event.Type, event.UserID, err)
+		return fmt.Errorf("ошибка публикации события: %w", err)
+	}
+	log.Printf("[EventBroker] Опубликовано событие %s (версия %d) для пользователя %d",
+		event.Type, event.VersionID, event.UserID)
+	return nil
+}
+
+// Subscribe регистрирует подписчика на события пользователя.
+func (b *Broker) Subscribe(userID int64) (<-chan models.VaultEvent, func()) {
+	ch := make(chan models.VaultEvent, subscriberBuffer)
+
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		close(ch)
+		return ch, func() {}
+	}
+	if b.subscribers[userID] == nil {
+		b.subscribers[userID] = make(map[chan models.VaultEvent]struct{})
+	}
+	b.subscribers[userID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			if _, ok := b.subscribers[userID][ch]; !ok {
+				return // Канал уже закрыт в CloseSubscribers
+			}
+			delete(b.subscribers[userID], ch)
+			if len(b.subscribers[userID]) == 0 {
+				delete(b.subscribers, userID)
+			}
+			close(ch)
+		})
+	}
+	return ch, unsubscribe
+}
+
+// CloseSubscribers закрывает каналы всех подписчиков, завершая открытые SSE-соединения.
+// Вызывается при остановке сервера: иначе долгоживущие потоки событий не дали бы дождаться
+// завершения остальных запросов. Клиенты переподключатся к другой реплике.
+func (b *Broker) CloseSubscribers() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for userID, channels := range b.subscribers {
+		for ch := range channels {
+			close(ch)
+		}
+		delete(b.subscribers, userID)
+	}
+	log.Printf("[EventBroker] Подписки на события закрыты")
+}
+
+// Listen подписывается на NotifyChannel и раздает полученные события локальным подписчикам
+// до отмены контекста. Соединение со слушателем восстанавливается автоматически.
+func (b *Broker) Listen(ctx context.Context, dsn string) error {
+	listener := pq.NewListener(dsn, listenerMinReconnect, listenerMaxReconnect,
+		func(event pq.ListenerEventType, err error) {
+			if err != nil {
+				log.Printf("[EventBroker] Событие слушателя Postgres %d: %v", event, err)
+			}
+		})
+	defer listener.Close()
+
+	if err := listener.Listen(NotifyChannel); err != nil {
+		return fmt.Errorf("ошибка подписки на канал '%s': %w", NotifyChannel, err)
+	}
+	log.Printf("[EventBroker] Подписка на канал '%s' установлена", NotifyChannel)
+
+	b.run(ctx, listener.NotificationChannel(), listener.Ping)
+	return nil
+}
+
+// run обрабатывает уведомления Postgres до отмены контекста.
+func (b *Broker) run(ctx context.Context, notifications <-chan *pq.Notification, ping func() error) {
+	ticker := time.NewTicker(listenerPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("[EventBroker] Прослушивание канала '%s' остановлено", NotifyChannel)
+			return
+		case notification, ok := <-notifications:
+			if !ok {
+				return
+			}
+			// nil приходит после переподключения: уведомления за время разрыва потеряны,
+			// но клиенты все равно сверяют метаданные при синхронизации.
+			if notification == nil {
+				log.Printf("[EventBroker] Соединение слушателя Postgres восстановлено")
+				continue
+			}
+			b.dispatch(notification.Extra)
+		case <-ticker.C:
+			if err := ping(); err != nil {
+				log.Printf("[EventBroker] Ошибка проверки соединения слушателя Postgres: %v", err)
+			}
+		}
+	}
+}
+
+// dispatch декодирует уведомление и передает событие подписчикам пользователя.
+func (b *Broker) dispatch(payload string) {
+	var event models.VaultEvent
+	if err := json.Unmarshal([]byte(payload), &event); err != nil {
+		log.Printf("[EventBroker] Ошибка декодирования уведомления: %v", err)
+		return
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for ch := range b.subscribers[event.UserID] {
+		select {
+		case ch <- event:
+		default:
+			log.Printf("[EventBroker] Подписчик пользователя %d не успевает читать события, событие пропущено",
+				event.UserID)
+		}
+	}
+}
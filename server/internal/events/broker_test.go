@@ -0,0 +1,128 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/maynagashev/gophkeeper/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBroker_Publish(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	broker := NewBroker(sqlx.NewDb(db, "sqlmock"))
+	event := models.VaultEvent{Type: models.VaultEventVersionCreated, UserID: 1, VersionID: 10}
+	payload, err := json.Marshal(event)
+	require.NoError(t, err)
+	query := regexp.QuoteMeta(`SELECT pg_notify($1, $2)`)
+
+	mock.ExpectExec(query).WithArgs(NotifyChannel, string(payload)).WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, broker.Publish(context.Background(), event))
+
+	mock.ExpectExec(query).WillReturnError(errors.New("db error"))
+	require.Error(t, broker.Publish(context.Background(), event))
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBroker_Dispatch(t *testing.T) {
+	broker := NewBroker(nil)
+	notifications := make(chan *pq.Notification)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		broker.run(ctx, notifications, func() error { return nil })
+		close(done)
+	}()
+
+	user1, unsubscribe1 := broker.Subscribe(1)
+	user1Second, unsubscribe1Second := broker.Subscribe(1)
+	user2, unsubscribe2 := broker.Subscribe(2)
+	defer unsubscribe1Second()
+	defer unsubscribe2()
+
+	checksum := "abc"
+	payload, err := json.Marshal(models.VaultEvent{
+		Type: models.VaultEventRolledBack, UserID: 1, VersionID: 5, Checksum: &checksum,
+	})
+	require.NoError(t, err)
+
+	notifications <- nil                                // Переподключение игнорируется
+	notifications <- &pq.Notification{Extra: "не json"} // Некорректное уведомление игнорируется
+	notifications <- &pq.Notification{Extra: string(payload)}
+
+	for _, ch := range []<-chan models.VaultEvent{user1, user1Second} {
+		select {
+		case event := <-ch:
+			assert.Equal(t, models.VaultEventRolledBack, event.Type)
+			assert.Equal(t, int64(5), event.VersionID)
+			require.NotNil(t, event.Checksum)
+			assert.Equal(t, checksum, *event.Checksum)
+		case <-time.After(time.Second):
+			t.Fatal("событие не доставлено подписчику")
+		}
+	}
+	select {
+	case event := <-user2:
+		t.Fatalf("событие другого пользователя доставлено: %+v", event)
+	default:
+	}
+
+	// После отписки канал закрывается, повторная отписка безопасна
+	unsubscribe1()
+	unsubscribe1()
+	_, open := <-user1
+	assert.False(t, open)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("обработка уведомлений не остановилась после отмены контекста")
+	}
+}
+
+func TestBroker_SlowSubscriberDoesNotBlock(t *testing.T) {
+	broker := NewBroker(nil)
+	events, unsubscribe := broker.Subscribe(1)
+	defer unsubscribe()
+
+	payload, err := json.Marshal(models.VaultEvent{Type: models.VaultEventVersionCreated, UserID: 1})
+	require.NoError(t, err)
+	for range subscriberBuffer + 5 {
+		broker.dispatch(string(payload))
+	}
+
+	assert.Len(t, events, subscriberBuffer)
+}
+
+func TestBroker_CloseSubscribers(t *testing.T) {
+	broker := NewBroker(nil)
+	user1, unsubscribe1 := broker.Subscribe(1)
+	user2, unsubscribe2 := broker.Subscribe(2)
+
+	broker.CloseSubscribers()
+
+	for _, ch := range []<-chan models.VaultEvent{user1, user2} {
+		_, open := <-ch
+		assert.False(t, open, "канал подписчика должен быть закрыт")
+	}
+	// Отписка после закрытия не паникует
+	unsubscribe1()
+	unsubscribe2()
+
+	// Подписка после остановки сразу возвращает закрытый канал
+	late, unsubscribeLate := broker.Subscribe(1)
+	defer unsubscribeLate()
+	_, open := <-late
+	assert.False(t, open)
+}
@@ -0,0 +1,28 @@
+package events
+
+import (
+	"context"
+	"errors"
+
+	"github.com/maynagashev/gophkeeper/models"
+)
+
+// multiPublisher передает событие нескольким получателям.
+type multiPublisher []Publisher
+
+// NewMultiPublisher объединяет несколько получателей событий в один Publisher.
+// Событие передается всем получателям, даже если часть из них вернула ошибку.
+func NewMultiPublisher(publishers ...Publisher) Publisher {
+	return multiPublisher(publishers)
+}
+
+// Publish передает событие всем получателям и возвращает объединенную ошибку.
+func (p multiPublisher) Publish(ctx context.Context, event models.VaultEvent) error {
+	var errs []error
+	for _, publisher := range p {
+		if err := publisher.Publish(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
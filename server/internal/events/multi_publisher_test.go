@@ -0,0 +1,41 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/maynagashev/gophkeeper/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// publisherFunc позволяет использовать функцию как Publisher в тестах.
+type publisherFunc func(ctx context.Context, event models.VaultEvent) error
+
+func (f publisherFunc) Publish(ctx context.Context, event models.VaultEvent) error {
+	return f(ctx, event)
+}
+
+func TestMultiPublisher_Publish(t *testing.T) {
+	event := models.VaultEvent{Type: models.VaultEventRolledBack, UserID: 1, VersionID: 3}
+	errFirst := errors.New("first failed")
+
+	var received []string
+	first := publisherFunc(func(_ context.Context, e models.VaultEvent) error {
+		received = append(received, "first:"+e.Type)
+		return errFirst
+	})
+	second := publisherFunc(func(_ context.Context, e models.VaultEvent) error {
+		received = append(received, "second:"+e.Type)
+		return nil
+	})
+
+	err := NewMultiPublisher(first, second).Publish(context.Background(), event)
+
+	require.ErrorIs(t, err, errFirst)
+	assert.Equal(t, []string{"first:rolled_back", "second:rolled_back"}, received,
+		"Событие должно дойти до всех получателей, несмотря на ошибку первого")
+
+	assert.NoError(t, NewMultiPublisher(second).Publish(context.Background(), event))
+}
@@ -0,0 +1,92 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/maynagashev/gophkeeper/models/pb"
+	"github.com/maynagashev/gophkeeper/server/internal/middleware"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// publicMethods - методы, доступные без токена.
+var publicMethods = map[string]bool{ //nolint:gochecknoglobals // Неизменяемый список методов
+	pb.GophKeeper_Register_FullMethodName: true,
+	pb.GophKeeper_Login_FullMethodName:    true,
+}
+
+// UnaryAuthInterceptor проверяет JWT из метаданных "authorization: Bearer <токен>" у унарных вызовов
+// и добавляет ID и роль пользователя в контекст, как middleware.JWTAuthenticator для REST API.
+// Учетная запись владельца токена проверяется через accounts при каждом вызове.
+func UnaryAuthInterceptor(secret string, accounts middleware.AccountLookup) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if publicMethods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+		ctx, err := authenticate(ctx, []byte(secret), accounts)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamAuthInterceptor проверяет JWT у потоковых вызовов (см. UnaryAuthInterceptor).
+func StreamAuthInterceptor(secret string, accounts middleware.AccountLookup) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if publicMethods[info.FullMethod] {
+			return handler(srv, ss)
+		}
+		ctx, err := authenticate(ss.Context(), []byte(secret), accounts)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authenticate извлекает токен из метаданных вызова и проверяет его.
+func authenticate(ctx context.Context, secret []byte, accounts middleware.AccountLookup) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 || values[0] == "" {
+		log.Println("[GRPCAuth] Метаданные authorization отсутствуют")
+		return nil, status.Error(codes.Unauthenticated, "Требуется аутентификация")
+	}
+
+	// Проверяем формат "Bearer token"
+	scheme, token, found := strings.Cut(values[0], " ")
+	if !found || !strings.EqualFold(scheme, "bearer") || token == "" {
+		log.Println("[GRPCAuth] Неверный формат метаданных authorization")
+		return nil, status.Error(codes.Unauthenticated, "Неверный формат токена")
+	}
+
+	ctx, err := middleware.AuthenticateToken(ctx, token, secret, accounts)
+	switch {
+	case err == nil:
+		return ctx, nil
+	case errors.Is(err, middleware.ErrInvalidToken):
+		return nil, status.Error(codes.Unauthenticated, "Невалидный токен")
+	case errors.Is(err, middleware.ErrAccountDisabled):
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	default:
+		log.Printf("[GRPCAuth] Ошибка проверки учетной записи: %v", err)
+		return nil, status.Error(codes.Internal, "Внутренняя ошибка сервера")
+	}
+}
+
+// authenticatedStream подменяет контекст потока контекстом с данными пользователя.
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context возвращает контекст с ID и ролью пользователя.
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
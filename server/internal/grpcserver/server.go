@@ -0,0 +1,458 @@
+// Package grpcserver реализует gRPC API сервера (сервис gophkeeper.v1.GophKeeper из models/pb)
+// поверх тех же сервисов аутентификации и хранилищ, что и REST API.
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net"
+
+	"github.com/maynagashev/gophkeeper/models"
+	"github.com/maynagashev/gophkeeper/models/pb"
+	"github.com/maynagashev/gophkeeper/server/internal/events"
+	"github.com/maynagashev/gophkeeper/server/internal/middleware"
+	"github.com/maynagashev/gophkeeper/server/internal/services"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// ChunkSize - размер части файла в сообщениях Download.
+	ChunkSize = 64 << 10
+
+	// Параметры пагинации ListVersions (как у GET /api/vault/versions).
+	defaultListLimit = 20
+	maxListLimit     = 100
+
+	defaultContentType = "application/octet-stream"
+)
+
+// Server реализует pb.GophKeeperServer.
+type Server struct {
+	pb.UnimplementedGophKeeperServer
+
+	auth          services.AuthService
+	vault         services.VaultService
+	subscriber    events.Subscriber
+	maxUploadSize int64
+}
+
+var _ pb.GophKeeperServer = (*Server)(nil)
+
+// NewServer создает реализацию gRPC API.
+// maxUploadSize - максимальный размер загружаемого хранилища в байтах (0 - без ограничения).
+func NewServer(
+	auth services.AuthService,
+	vault services.VaultService,
+	subscriber events.Subscriber,
+	maxUploadSize int64,
+) *Server {
+	return &Server{auth: auth, vault: vault, subscriber: subscriber, maxUploadSize: maxUploadSize}
+}
+
+// NewGRPCServer создает gRPC-сервер с зарегистрированным сервисом GophKeeper и перехватчиками,
+// проверяющими JWT (подписанный ключом jwtSecret) у всех методов, кроме Register и Login.
+func NewGRPCServer(
+	srv *Server,
+	jwtSecret string,
+	accounts middleware.AccountLookup,
+	opts ...grpc.ServerOption,
+) *grpc.Server {
+	opts = append(opts,
+		grpc.ChainUnaryInterceptor(UnaryAuthInterceptor(jwtSecret, accounts)),
+		grpc.ChainStreamInterceptor(StreamAuthInterceptor(jwtSecret, accounts)),
+	)
+	gs := grpc.NewServer(opts...)
+	pb.RegisterGophKeeperServer(gs, srv)
+	return gs
+}
+
+// Register регистрирует нового пользователя.
+func (s *Server) Register(ctx context.Context, req *pb.RegisterRequest) (*pb.RegisterResponse, error) {
+	if req.GetUsername() == "" || req.GetPassword() == "" {
+		return nil, status.Error(codes.InvalidArgument, "Имя пользователя и пароль не могут быть пустыми")
+	}
+	if err := s.auth.Register(ctx, req.GetUsername(), req.GetPassword()); err != nil {
+		log.Printf("[GRPCServer:Register] Ошибка регистрации '%s': %v", req.GetUsername(), err)
+		return nil, toStatus(err)
+	}
+	log.Printf("[GRPCServer:Register] Успешная регистрация для: %s", req.GetUsername())
+	return &pb.RegisterResponse{}, nil
+}
+
+// Login проверяет учетные данные и возвращает JWT.
+func (s *Server) Login(ctx context.Context, req *pb.LoginRequest) (*pb.LoginResponse, error) {
+	if req.GetUsername() == "" || req.GetPassword() == "" {
+		return nil, status.Error(codes.InvalidArgument, "Имя пользователя и пароль не могут быть пустыми")
+	}
+	token, err := s.auth.Login(ctx, req.GetUsername(), req.GetPassword(), callDevice(ctx))
+	if err != nil {
+		log.Printf("[GRPCServer:Login] Ошибка входа '%s': %v", req.GetUsername(), err)
+		return nil, toStatus(err)
+	}
+	log.Printf("[GRPCServer:Login] Успешный вход для: %s", req.GetUsername())
+	return &pb.LoginResponse{Token: token}, nil
+}
+
+// GetMetadata возвращает метаданные текущей версии хранилища.
+func (s *Server) GetMetadata(ctx context.Context, _ *pb.GetMetadataRequest) (*pb.VaultVersion, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	version, err := s.vault.GetVaultMetadata(ctx, userID)
+	if err != nil {
+		log.Printf("[GRPCServer:GetMetadata] Ошибка получения метаданных для пользователя %d: %v", userID, err)
+		return nil, toStatus(err)
+	}
+	return versionToPB(version), nil
+}
+
+// Upload загружает новую версию хранилища. Первое сообщение потока содержит метаданные файла,
+// следующие - его части; объем частей должен совпасть с объявленным размером.
+func (s *Server) Upload(stream grpc.ClientStreamingServer[pb.UploadRequest, pb.UploadResponse]) error {
+	ctx := stream.Context()
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	meta := first.GetMetadata()
+	switch {
+	case meta == nil:
+		return status.Error(codes.InvalidArgument, "Первое сообщение потока должно содержать метаданные файла")
+	case meta.GetSize() <= 0:
+		return status.Error(codes.InvalidArgument, "Неверный размер файла")
+	case meta.GetContentModifiedAt() == nil:
+		return status.Error(codes.InvalidArgument, "Не указано время изменения содержимого (content_modified_at)")
+	case s.maxUploadSize > 0 && meta.GetSize() > s.maxUploadSize:
+		log.Printf("[GRPCServer:Upload] Размер файла пользователя %d (%d байт) превышает лимит %d",
+			userID, meta.GetSize(), s.maxUploadSize)
+		return status.Error(codes.ResourceExhausted, "Размер файла превышает допустимый")
+	}
+	contentType := meta.GetContentType()
+	if contentType == "" {
+		contentType = defaultContentType
+	}
+
+	log.Printf("[GRPCServer:Upload] Запрос на загрузку файла (%d байт) от пользователя %d", meta.GetSize(), userID)
+	body := &uploadReader{stream: stream, remaining: meta.GetSize()}
+	version, err := s.vault.UploadVault(ctx, userID, body, meta.GetSize(), contentType,
+		meta.GetContentModifiedAt().AsTime(), callOrigin(ctx))
+	if body.err != nil {
+		// Ошибка чтения потока важнее ошибки сервиса, которая из нее следует
+		err = body.err
+	}
+	if err != nil {
+		log.Printf("[GRPCServer:Upload] Ошибка загрузки файла для пользователя %d: %v", userID, err)
+		return toStatus(err)
+	}
+
+	log.Printf("[GRPCServer:Upload] Файл для пользователя %d успешно загружен", userID)
+	return stream.SendAndClose(&pb.UploadResponse{Version: versionToPB(version)})
+}
+
+// Download отдает текущую версию хранилища: сначала метаданные, затем части файла.
+// Если if_none_match совпадает с контрольной суммой текущей версии, файл не передается.
+// При несовпадении контрольной суммы файла на сервере поток завершается ошибкой DATA_LOSS.
+func (s *Server) Download(req *pb.DownloadRequest, stream grpc.ServerStreamingServer[pb.DownloadResponse]) error {
+	ctx := stream.Context()
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	version, err := s.vault.GetVaultMetadata(ctx, userID)
+	if err != nil {
+		log.Printf("[GRPCServer:Download] Ошибка получения метаданных для пользователя %d: %v", userID, err)
+		return toStatus(err)
+	}
+	notModified := req.GetIfNoneMatch() != "" && version.Checksum != nil && *version.Checksum == req.GetIfNoneMatch()
+	err = stream.Send(&pb.DownloadResponse{Payload: &pb.DownloadResponse_Metadata{
+		Metadata: &pb.DownloadMetadata{Version: versionToPB(version), NotModified: notModified},
+	}})
+	if err != nil || notModified {
+		return err
+	}
+
+	fileReader, err := s.vault.DownloadVersion(ctx, version, 0, -1)
+	if err != nil {
+		log.Printf("[GRPCServer:Download] Ошибка открытия файла для пользователя %d: %v", userID, err)
+		return toStatus(err)
+	}
+	defer func() {
+		if closeErr := fileReader.Close(); closeErr != nil {
+			log.Printf("[GRPCServer:Download] Ошибка закрытия fileReader: %v", closeErr)
+		}
+	}()
+
+	buf := make([]byte, ChunkSize)
+	for {
+		n, readErr := fileReader.Read(buf)
+		if n > 0 {
+			if err = stream.Send(&pb.DownloadResponse{Payload: &pb.DownloadResponse_Chunk{Chunk: buf[:n]}}); err != nil {
+				return err
+			}
+		}
+		if errors.Is(readErr, io.EOF) {
+			break
+		}
+		if readErr != nil {
+			log.Printf("[GRPCServer:Download] Ошибка чтения файла пользователя %d (версия %d): %v",
+				userID, version.ID, readErr)
+			return toStatus(readErr)
+		}
+	}
+
+	log.Printf("[GRPCServer:Download] Файл для пользователя %d (версия %d) успешно отправлен", userID, version.ID)
+	return nil
+}
+
+// ListVersions возвращает версии хранилища, начиная с последней.
+func (s *Server) ListVersions(ctx context.Context, req *pb.ListVersionsRequest) (*pb.ListVersionsResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	limit, offset := int(req.GetLimit()), int(req.GetOffset())
+	if limit <= 0 || limit > maxListLimit {
+		limit = defaultListLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	versions, err := s.vault.ListVersions(ctx, userID, limit, offset)
+	if err != nil {
+		log.Printf("[GRPCServer:ListVersions] Ошибка получения списка версий для пользователя %d: %v", userID, err)
+		return nil, toStatus(err)
+	}
+	resp := &pb.ListVersionsResponse{Versions: make([]*pb.VaultVersion, 0, len(versions))}
+	for i := range versions {
+		resp.Versions = append(resp.Versions, versionToPB(&versions[i]))
+	}
+
+	current, err := s.vault.GetVaultMetadata(ctx, userID)
+	switch {
+	case err == nil:
+		resp.CurrentVersionId = &current.ID
+	case !errors.Is(err, services.ErrVaultNotFound):
+		// Список версий отдаем и без ID текущей версии
+		log.Printf("[GRPCServer:ListVersions] Ошибка при получении current_version_id: %v", err)
+	}
+	return resp, nil
+}
+
+// Rollback делает текущей одну из прежних версий хранилища.
+func (s *Server) Rollback(ctx context.Context, req *pb.RollbackRequest) (*pb.RollbackResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetVersionId() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "Неверный ID версии")
+	}
+	if err = s.vault.RollbackToVersion(ctx, userID, req.GetVersionId()); err != nil {
+		log.Printf("[GRPCServer:Rollback] Ошибка отката к версии %d для пользователя %d: %v",
+			req.GetVersionId(), userID, err)
+		return nil, toStatus(err)
+	}
+	log.Printf("[GRPCServer:Rollback] Успешный откат к версии %d для пользователя %d", req.GetVersionId(), userID)
+	return &pb.RollbackResponse{}, nil
+}
+
+// SubscribeEvents отдает события об изменении хранилища пользователя до отмены вызова
+// или остановки сервера (закрытия подписчиков).
+func (s *Server) SubscribeEvents(
+	_ *pb.SubscribeEventsRequest,
+	stream grpc.ServerStreamingServer[pb.VaultEvent],
+) error {
+	ctx := stream.Context()
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	eventsCh, unsubscribe := s.subscriber.Subscribe(userID)
+	defer unsubscribe()
+	// Заголовки отправляются сразу, чтобы клиент узнал об успешной подписке, не дожидаясь первого события
+	if err = stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+	log.Printf("[GRPCServer:SubscribeEvents] Пользователь %d подписался на события хранилища", userID)
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("[GRPCServer:SubscribeEvents] Пользователь %d отключился от потока событий", userID)
+			return nil
+		case event, open := <-eventsCh:
+			if !open {
+				return nil
+			}
+			if err = stream.Send(eventToPB(event)); err != nil {
+				log.Printf("[GRPCServer:SubscribeEvents] Ошибка отправки события пользователю %d: %v", userID, err)
+				return err
+			}
+		}
+	}
+}
+
+// uploadReader читает части файла из потока Upload. Данных должно быть ровно remaining байт.
+type uploadReader struct {
+	stream    grpc.ClientStreamingServer[pb.UploadRequest, pb.UploadResponse]
+	remaining int64
+	pending   []byte
+	err       error // Ошибка потока (gRPC-статус), сохраняется для ответа клиенту
+}
+
+// Read возвращает очередную часть файла.
+func (r *uploadReader) Read(p []byte) (int, error) {
+	for len(r.pending) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		msg, err := r.stream.Recv()
+		if errors.Is(err, io.EOF) {
+			if r.remaining > 0 {
+				r.err = status.Errorf(codes.InvalidArgument, "Поток завершен до конца файла: не хватает %d байт", r.remaining)
+				return 0, r.err
+			}
+			return 0, io.EOF
+		}
+		if err != nil {
+			r.err = err
+			return 0, err
+		}
+		if msg.GetMetadata() != nil {
+			r.err = status.Error(codes.InvalidArgument, "Метаданные допустимы только в первом сообщении потока")
+			return 0, r.err
+		}
+		r.remaining -= int64(len(msg.GetChunk()))
+		if r.remaining < 0 {
+			r.err = status.Error(codes.InvalidArgument, "Объем данных превышает объявленный размер файла")
+			return 0, r.err
+		}
+		r.pending = msg.GetChunk()
+	}
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+// toStatus преобразует ошибку сервиса в gRPC-статус.
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, services.ErrUsernameTaken):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, services.ErrInvalidCredentials):
+		return status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, services.ErrAccountDisabled):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, services.ErrForbidden):
+		return status.Error(codes.PermissionDenied, "Доступ запрещен")
+	case errors.Is(err, services.ErrVaultNotFound), errors.Is(err, services.ErrVersionNotFound):
+		return status.Error(codes.NotFound, "Хранилище или версия не найдены")
+	case errors.Is(err, services.ErrConflictVersion):
+		return status.Error(codes.Aborted, "Конфликт версий: на сервере уже есть более новая "+
+			"или идентичная версия с другим содержимым.")
+	case errors.Is(err, services.ErrInvalidVaultFile):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, services.ErrChecksumMismatch):
+		return status.Error(codes.DataLoss, "Файл хранилища на сервере поврежден")
+	case errors.Is(err, services.ErrOperationTimeout):
+		return status.Error(codes.Unavailable, "Превышено время выполнения операции")
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	}
+	if st, ok := status.FromError(err); ok {
+		return st.Err()
+	}
+	return status.Error(codes.Internal, "Внутренняя ошибка сервера")
+}
+
+// userIDFromContext возвращает ID пользователя, добавленный перехватчиком аутентификации.
+func userIDFromContext(ctx context.Context) (int64, error) {
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		log.Printf("[GRPCServer] Не удалось получить userID из контекста")
+		return 0, status.Error(codes.Internal, "Внутренняя ошибка сервера")
+	}
+	return userID, nil
+}
+
+// callDevice возвращает сведения об устройстве, с которого выполнен вызов (в том числе метаданные x-device-id).
+func callDevice(ctx context.Context) models.Device {
+	var device models.Device
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if userAgent := md.Get("user-agent"); len(userAgent) > 0 {
+			device.UserAgent = userAgent[0]
+		}
+		if deviceID := md.Get(models.HeaderDeviceID); len(deviceID) > 0 {
+			device.DeviceID = deviceID[0]
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		device.IPAddress = p.Addr.String()
+		if host, _, err := net.SplitHostPort(device.IPAddress); err == nil {
+			device.IPAddress = host
+		}
+	}
+	return device
+}
+
+// callOrigin возвращает сведения о клиенте и устройстве из метаданных вызова
+// (x-client-name, x-client-version, x-device-id) и IP-адрес клиента.
+func callOrigin(ctx context.Context) models.UploadOrigin {
+	origin := models.UploadOrigin{IPAddress: callDevice(ctx).IPAddress}
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return origin
+	}
+	first := func(key string) string {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+	origin.ClientName = first(models.HeaderClientName)
+	origin.ClientVersion = first(models.HeaderClientVersion)
+	origin.DeviceID = first(models.HeaderDeviceID)
+	return origin
+}
+
+// versionToPB преобразует метаданные версии в сообщение gRPC.
+func versionToPB(v *models.VaultVersion) *pb.VaultVersion {
+	out := &pb.VaultVersion{
+		Id:                    v.ID,
+		VaultId:               v.VaultID,
+		Checksum:              v.Checksum,
+		Size:                  v.SizeBytes,
+		CreatedAt:             timestamppb.New(v.CreatedAt),
+		RestoredFromVersionId: v.RestoredFromVersionID,
+	}
+	if v.ContentModifiedAt != nil {
+		out.ContentModifiedAt = timestamppb.New(*v.ContentModifiedAt)
+	}
+	return out
+}
+
+// eventToPB преобразует событие хранилища в сообщение gRPC.
+func eventToPB(e models.VaultEvent) *pb.VaultEvent {
+	return &pb.VaultEvent{
+		Type:      e.Type,
+		VersionId: e.VersionID,
+		Checksum:  e.Checksum,
+		CreatedAt: timestamppb.New(e.CreatedAt),
+	}
+}
@@ -0,0 +1,461 @@
+package grpcserver_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/maynagashev/gophkeeper/models"
+	"github.com/maynagashev/gophkeeper/models/pb"
+	"github.com/maynagashev/gophkeeper/server/internal/grpcserver"
+	"github.com/maynagashev/gophkeeper/server/internal/mocks"
+	"github.com/maynagashev/gophkeeper/server/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	testSecret = "grpc-test-secret"
+	testUserID = int64(7)
+	// disabledUserID - пользователь, учетную запись которого отключили после выдачи токена.
+	disabledUserID = int64(8)
+	testMaxUpload  = 1 << 20
+)
+
+// testEnv - gRPC-сервер поверх моков сервисов и подключенный к нему клиент.
+type testEnv struct {
+	auth       *mocks.AuthService
+	vault      *mocks.VaultService
+	subscriber *mocks.Subscriber
+	client     pb.GophKeeperClient
+}
+
+func setupTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	env := &testEnv{
+		auth:       mocks.NewAuthService(t),
+		vault:      mocks.NewVaultService(t),
+		subscriber: mocks.NewSubscriber(t),
+	}
+	srv := grpcserver.NewServer(env.auth, env.vault, env.subscriber, testMaxUpload)
+	gs := grpcserver.NewGRPCServer(srv, testSecret, testAccounts)
+	ln := bufconn.Listen(1 << 20)
+	go func() { _ = gs.Serve(ln) }()
+	t.Cleanup(gs.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return ln.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUserAgent("gophkeeper-test"),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	env.client = pb.NewGophKeeperClient(conn)
+	return env
+}
+
+// testAccounts находит пользователей активными, кроме disabledUserID.
+func testAccounts(_ context.Context, userID int64) (*models.User, error) {
+	user := &models.User{ID: userID, Role: models.RoleUser}
+	if userID == disabledUserID {
+		disabledAt := time.Now()
+		user.DisabledAt = &disabledAt
+	}
+	return user, nil
+}
+
+// authContext возвращает контекст с токеном пользователя testUserID, подписанным ключом secret.
+func authContext(t *testing.T, secret string) context.Context {
+	t.Helper()
+	return userContext(t, secret, testUserID)
+}
+
+// userContext возвращает контекст с токеном пользователя userID, подписанным ключом secret.
+func userContext(t *testing.T, secret string, userID int64) context.Context {
+	t.Helper()
+	claims := jwt.MapClaims{"user_id": userID, "exp": time.Now().Add(time.Hour).Unix()}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	require.NoError(t, err)
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+func testVersion() *models.VaultVersion {
+	checksum := "abc123"
+	size := int64(len("kdbx-content"))
+	modified := time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC)
+	return &models.VaultVersion{
+		ID: 3, VaultID: 1, Checksum: &checksum, SizeBytes: &size,
+		CreatedAt: modified.Add(time.Minute), ContentModifiedAt: &modified,
+	}
+}
+
+func TestAuth(t *testing.T) {
+	env := setupTestEnv(t)
+
+	t.Run("Регистрация без токена", func(t *testing.T) {
+		env.auth.EXPECT().Register(mock.Anything, "alice", "secret").Return(nil).Once()
+
+		_, err := env.client.Register(context.Background(), &pb.RegisterRequest{Username: "alice", Password: "secret"})
+
+		require.NoError(t, err)
+	})
+
+	t.Run("Имя занято", func(t *testing.T) {
+		env.auth.EXPECT().Register(mock.Anything, "bob", "secret").Return(services.ErrUsernameTaken).Once()
+
+		_, err := env.client.Register(context.Background(), &pb.RegisterRequest{Username: "bob", Password: "secret"})
+
+		assert.Equal(t, codes.AlreadyExists, status.Code(err))
+	})
+
+	t.Run("Вход передает устройство", func(t *testing.T) {
+		env.auth.EXPECT().Login(mock.Anything, "alice", "secret", mock.MatchedBy(func(d models.Device) bool {
+			return strings.HasPrefix(d.UserAgent, "gophkeeper-test") && d.DeviceID == "device-1"
+		})).Return("jwt-token", nil).Once()
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "x-device-id", "device-1")
+
+		resp, err := env.client.Login(ctx, &pb.LoginRequest{Username: "alice", Password: "secret"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "jwt-token", resp.GetToken())
+	})
+
+	t.Run("Неверные учетные данные", func(t *testing.T) {
+		env.auth.EXPECT().Login(mock.Anything, "alice", "wrong", mock.Anything).
+			Return("", services.ErrInvalidCredentials).Once()
+
+		_, err := env.client.Login(context.Background(), &pb.LoginRequest{Username: "alice", Password: "wrong"})
+
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("Вызов без токена", func(t *testing.T) {
+		_, err := env.client.GetMetadata(context.Background(), &pb.GetMetadataRequest{})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("Токен с чужой подписью", func(t *testing.T) {
+		_, err := env.client.GetMetadata(authContext(t, "other-secret"), &pb.GetMetadataRequest{})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("Учетная запись отключена после выдачи токена", func(t *testing.T) {
+		_, err := env.client.GetMetadata(userContext(t, testSecret, disabledUserID), &pb.GetMetadataRequest{})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+		stream, err := env.client.Download(userContext(t, testSecret, disabledUserID), &pb.DownloadRequest{})
+		require.NoError(t, err)
+		_, err = stream.Recv()
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("Потоковый вызов без токена", func(t *testing.T) {
+		stream, err := env.client.Download(context.Background(), &pb.DownloadRequest{})
+		require.NoError(t, err)
+		_, err = stream.Recv()
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
+func TestGetMetadata(t *testing.T) {
+	env := setupTestEnv(t)
+	ctx := authContext(t, testSecret)
+
+	t.Run("Метаданные текущей версии", func(t *testing.T) {
+		env.vault.EXPECT().GetVaultMetadata(mock.Anything, testUserID).Return(testVersion(), nil).Once()
+
+		resp, err := env.client.GetMetadata(ctx, &pb.GetMetadataRequest{})
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), resp.GetId())
+		assert.Equal(t, "abc123", resp.GetChecksum())
+		assert.Equal(t, *testVersion().ContentModifiedAt, resp.GetContentModifiedAt().AsTime())
+	})
+
+	t.Run("Хранилища нет", func(t *testing.T) {
+		env.vault.EXPECT().GetVaultMetadata(mock.Anything, testUserID).Return(nil, services.ErrVaultNotFound).Once()
+
+		_, err := env.client.GetMetadata(ctx, &pb.GetMetadataRequest{})
+
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+}
+
+// upload отправляет сообщения потока Upload и возвращает результат.
+func upload(
+	ctx context.Context,
+	t *testing.T,
+	client pb.GophKeeperClient,
+	msgs ...*pb.UploadRequest,
+) (*pb.UploadResponse, error) {
+	t.Helper()
+	stream, err := client.Upload(ctx)
+	require.NoError(t, err)
+	for _, msg := range msgs {
+		if err = stream.Send(msg); err != nil {
+			break
+		}
+	}
+	return stream.CloseAndRecv()
+}
+
+func uploadMetadata(size int64) *pb.UploadRequest {
+	return &pb.UploadRequest{Payload: &pb.UploadRequest_Metadata{Metadata: &pb.UploadMetadata{
+		Size:              size,
+		ContentModifiedAt: timestamppb.New(time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC)),
+	}}}
+}
+
+func uploadChunk(data string) *pb.UploadRequest {
+	return &pb.UploadRequest{Payload: &pb.UploadRequest_Chunk{Chunk: []byte(data)}}
+}
+
+func TestUpload(t *testing.T) {
+	env := setupTestEnv(t)
+	ctx := authContext(t, testSecret)
+
+	// expectUpload ожидает загрузку и возвращает указатель на полученное содержимое.
+	expectUpload := func(size int64) *string {
+		var received string
+		env.vault.EXPECT().UploadVault(mock.Anything, testUserID, mock.Anything, size, "application/octet-stream",
+			time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC), mock.Anything).
+			RunAndReturn(func(
+				_ context.Context, _ int64, r io.Reader, _ int64, _ string, _ time.Time, _ models.UploadOrigin,
+			) (*models.VaultVersion, error) {
+				data, err := io.ReadAll(r)
+				received = string(data)
+				return &models.VaultVersion{ID: 7}, err
+			}).Once()
+		return &received
+	}
+
+	t.Run("Файл собирается из частей", func(t *testing.T) {
+		received := expectUpload(12)
+
+		resp, err := upload(ctx, t, env.client, uploadMetadata(12), uploadChunk("kdbx-"), uploadChunk("content"))
+
+		require.NoError(t, err)
+		assert.Equal(t, "kdbx-content", *received)
+		assert.Equal(t, int64(7), resp.GetVersion().GetId())
+	})
+
+	t.Run("Конфликт версий", func(t *testing.T) {
+		env.vault.EXPECT().UploadVault(mock.Anything, testUserID, mock.Anything, int64(4), mock.Anything,
+			mock.Anything, mock.Anything).Return(nil, services.ErrConflictVersion).Once()
+
+		_, err := upload(ctx, t, env.client, uploadMetadata(4), uploadChunk("kdbx"))
+
+		assert.Equal(t, codes.Aborted, status.Code(err))
+	})
+
+	t.Run("Файл не является базой KeePass", func(t *testing.T) {
+		env.vault.EXPECT().UploadVault(mock.Anything, testUserID, mock.Anything, int64(4), mock.Anything,
+			mock.Anything, mock.Anything).Return(nil, services.ErrInvalidVaultFile).Once()
+
+		_, err := upload(ctx, t, env.client, uploadMetadata(4), uploadChunk("text"))
+
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("Данных больше объявленного размера", func(t *testing.T) {
+		expectUpload(4)
+
+		_, err := upload(ctx, t, env.client, uploadMetadata(4), uploadChunk("kdbx-content"))
+
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("Поток завершен раньше конца файла", func(t *testing.T) {
+		expectUpload(12)
+
+		_, err := upload(ctx, t, env.client, uploadMetadata(12), uploadChunk("kdbx"))
+
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("Сведения о клиенте передаются из метаданных вызова", func(t *testing.T) {
+		env.vault.EXPECT().UploadVault(mock.Anything, testUserID, mock.Anything, int64(4), mock.Anything,
+			mock.Anything, mock.MatchedBy(func(o models.UploadOrigin) bool {
+				return o.ClientName == "gophkeeper-client" && o.ClientVersion == "v1.2.0" &&
+					o.DeviceID == "5f2c9a0e7b3d41e8"
+			})).
+			RunAndReturn(func(
+				_ context.Context, _ int64, r io.Reader, _ int64, _ string, _ time.Time, _ models.UploadOrigin,
+			) (*models.VaultVersion, error) {
+				_, err := io.Copy(io.Discard, r)
+				return &models.VaultVersion{ID: 8}, err
+			}).Once()
+		clientCtx := metadata.AppendToOutgoingContext(ctx,
+			"x-client-name", "gophkeeper-client", "x-client-version", "v1.2.0", "x-device-id", "5f2c9a0e7b3d41e8")
+
+		resp, err := upload(clientCtx, t, env.client, uploadMetadata(4), uploadChunk("kdbx"))
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(8), resp.GetVersion().GetId())
+	})
+
+	t.Run("Нет метаданных", func(t *testing.T) {
+		_, err := upload(ctx, t, env.client, uploadChunk("kdbx"))
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("Превышен максимальный размер", func(t *testing.T) {
+		_, err := upload(ctx, t, env.client, uploadMetadata(testMaxUpload+1))
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	})
+}
+
+// download читает поток Download и возвращает метаданные и содержимое файла.
+func download(
+	ctx context.Context,
+	t *testing.T,
+	client pb.GophKeeperClient,
+	ifNoneMatch string,
+) (*pb.DownloadMetadata, []byte, error) {
+	t.Helper()
+	stream, err := client.Download(ctx, &pb.DownloadRequest{IfNoneMatch: ifNoneMatch})
+	require.NoError(t, err)
+	first, err := stream.Recv()
+	if err != nil {
+		return nil, nil, err
+	}
+	var data bytes.Buffer
+	for {
+		msg, recvErr := stream.Recv()
+		if errors.Is(recvErr, io.EOF) {
+			return first.GetMetadata(), data.Bytes(), nil
+		}
+		if recvErr != nil {
+			return first.GetMetadata(), data.Bytes(), recvErr
+		}
+		data.Write(msg.GetChunk())
+	}
+}
+
+func TestDownload(t *testing.T) {
+	env := setupTestEnv(t)
+	ctx := authContext(t, testSecret)
+
+	t.Run("Файл передается частями после метаданных", func(t *testing.T) {
+		content := strings.Repeat("k", grpcserver.ChunkSize+10)
+		version := testVersion()
+		env.vault.EXPECT().GetVaultMetadata(mock.Anything, testUserID).Return(version, nil).Once()
+		env.vault.EXPECT().DownloadVersion(mock.Anything, version, int64(0), int64(-1)).
+			Return(io.NopCloser(strings.NewReader(content)), nil).Once()
+
+		meta, data, err := download(ctx, t, env.client, "")
+
+		require.NoError(t, err)
+		assert.False(t, meta.GetNotModified())
+		assert.Equal(t, int64(3), meta.GetVersion().GetId())
+		assert.Equal(t, content, string(data))
+	})
+
+	t.Run("Версия не изменилась", func(t *testing.T) {
+		env.vault.EXPECT().GetVaultMetadata(mock.Anything, testUserID).Return(testVersion(), nil).Once()
+
+		meta, data, err := download(ctx, t, env.client, "abc123")
+
+		require.NoError(t, err)
+		assert.True(t, meta.GetNotModified())
+		assert.Empty(t, data)
+	})
+
+	t.Run("Файл на сервере поврежден", func(t *testing.T) {
+		version := testVersion()
+		env.vault.EXPECT().GetVaultMetadata(mock.Anything, testUserID).Return(version, nil).Once()
+		env.vault.EXPECT().DownloadVersion(mock.Anything, version, int64(0), int64(-1)).
+			Return(io.NopCloser(io.MultiReader(strings.NewReader("kdbx"),
+				errReader{services.ErrChecksumMismatch})), nil).Once()
+
+		_, _, err := download(ctx, t, env.client, "")
+
+		assert.Equal(t, codes.DataLoss, status.Code(err))
+	})
+}
+
+// errReader возвращает ошибку при чтении.
+type errReader struct{ err error }
+
+func (r errReader) Read([]byte) (int, error) { return 0, r.err }
+
+func TestListVersionsAndRollback(t *testing.T) {
+	env := setupTestEnv(t)
+	ctx := authContext(t, testSecret)
+
+	t.Run("Список версий с текущей версией", func(t *testing.T) {
+		env.vault.EXPECT().ListVersions(mock.Anything, testUserID, 20, 0).
+			Return([]models.VaultVersion{*testVersion()}, nil).Once()
+		env.vault.EXPECT().GetVaultMetadata(mock.Anything, testUserID).Return(testVersion(), nil).Once()
+
+		resp, err := env.client.ListVersions(ctx, &pb.ListVersionsRequest{Limit: 1000})
+
+		require.NoError(t, err)
+		require.Len(t, resp.GetVersions(), 1)
+		assert.Equal(t, int64(3), resp.GetCurrentVersionId())
+	})
+
+	t.Run("Откат", func(t *testing.T) {
+		env.vault.EXPECT().RollbackToVersion(mock.Anything, testUserID, int64(2)).Return(nil).Once()
+
+		_, err := env.client.Rollback(ctx, &pb.RollbackRequest{VersionId: 2})
+
+		require.NoError(t, err)
+	})
+
+	t.Run("Откат к несуществующей версии", func(t *testing.T) {
+		env.vault.EXPECT().RollbackToVersion(mock.Anything, testUserID, int64(99)).
+			Return(services.ErrVersionNotFound).Once()
+
+		_, err := env.client.Rollback(ctx, &pb.RollbackRequest{VersionId: 99})
+
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("Неверный ID версии", func(t *testing.T) {
+		_, err := env.client.Rollback(ctx, &pb.RollbackRequest{})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestSubscribeEvents(t *testing.T) {
+	env := setupTestEnv(t)
+	eventsCh := make(chan models.VaultEvent, 1)
+	unsubscribed := make(chan struct{})
+	env.subscriber.EXPECT().Subscribe(testUserID).
+		Return(eventsCh, func() { close(unsubscribed) }).Once()
+	checksum := "abc123"
+	eventsCh <- models.VaultEvent{Type: models.VaultEventVersionCreated, UserID: testUserID, VersionID: 3,
+		Checksum: &checksum, CreatedAt: time.Now()}
+
+	ctx, cancel := context.WithCancel(authContext(t, testSecret))
+	stream, err := env.client.SubscribeEvents(ctx, &pb.SubscribeEventsRequest{})
+	require.NoError(t, err)
+
+	event, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, models.VaultEventVersionCreated, event.GetType())
+	assert.Equal(t, int64(3), event.GetVersionId())
+	assert.Equal(t, checksum, event.GetChecksum())
+
+	cancel()
+	select {
+	case <-unsubscribed:
+	case <-time.After(time.Second):
+		t.Fatal("подписка не отменена после отмены вызова")
+	}
+}
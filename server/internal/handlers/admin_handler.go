@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/maynagashev/gophkeeper/models"
+	"github.com/maynagashev/gophkeeper/server/internal/middleware"
+	"github.com/maynagashev/gophkeeper/server/internal/services"
+)
+
+// AdminHandler обрабатывает служебные HTTP-запросы администратора.
+type AdminHandler struct {
+	integrityService services.IntegrityService
+	userAdminService services.UserAdminService
+}
+
+// NewAdminHandler создает новый экземпляр AdminHandler.
+func NewAdminHandler(is services.IntegrityService, uas services.UserAdminService) *AdminHandler {
+	return &AdminHandler{integrityService: is, userAdminService: uas}
+}
+
+// StorageIntegrity обрабатывает GET запрос на получение отчета о целостности объектов хранилища.
+// Возвращает итоги последнего прохода проверки и версии с поврежденными или отсутствующими объектами.
+func (h *AdminHandler) StorageIntegrity(w http.ResponseWriter, r *http.Request) {
+	limit, offset := adminPagination(r)
+
+	problems, err := h.integrityService.ListProblems(r.Context(), limit, offset)
+	if err != nil {
+		log.Printf("[AdminHandler:StorageIntegrity] Ошибка получения списка проблемных версий: %v", err)
+		middleware.WriteError(w, r, http.StatusInternalServerError, models.ErrCodeInternal, "Внутренняя ошибка сервера")
+		return
+	}
+
+	response := models.StorageIntegrityResponse{
+		LastScrub: h.integrityService.LastReport(),
+		Problems:  problems,
+	}
+	writeAdminJSON(w, response, "StorageIntegrity")
+}
+
+// StorageScrub обрабатывает POST запрос на внеочередной проход проверки целостности.
+// Проход выполняется синхронно, ответ содержит его итоги.
+func (h *AdminHandler) StorageScrub(w http.ResponseWriter, r *http.Request) {
+	log.Printf("[AdminHandler:StorageScrub] Запуск внеочередной проверки целостности")
+
+	report, err := h.integrityService.ScrubOnce(r.Context())
+	if err != nil {
+		log.Printf("[AdminHandler:StorageScrub] Ошибка проверки целостности: %v", err)
+		middleware.WriteError(w, r, http.StatusInternalServerError, models.ErrCodeInternal,
+			"Внутренняя ошибка сервера при проверке целостности")
+		return
+	}
+	writeAdminJSON(w, report, "StorageScrub")
+}
+
+// ListUsers обрабатывает GET запрос на получение списка пользователей со статистикой хранилищ.
+// Параметр search отбирает пользователей, имя которых содержит подстроку.
+func (h *AdminHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	limit, offset := adminPagination(r)
+	users, err := h.userAdminService.ListUsers(r.Context(), r.URL.Query().Get("search"), limit, offset)
+	if err != nil {
+		log.Printf("[AdminHandler:ListUsers] Ошибка получения списка пользователей: %v", err)
+		middleware.WriteError(w, r, http.StatusInternalServerError, models.ErrCodeInternal, "Внутренняя ошибка сервера")
+		return
+	}
+	writeAdminJSON(w, users, "ListUsers")
+}
+
+// DisableUser обрабатывает POST запрос на отключение учетной записи.
+func (h *AdminHandler) DisableUser(w http.ResponseWriter, r *http.Request) {
+	h.setUserDisabled(w, r, true, "DisableUser")
+}
+
+// EnableUser обрабатывает POST запрос на включение отключенной учетной записи.
+func (h *AdminHandler) EnableUser(w http.ResponseWriter, r *http.Request) {
+	h.setUserDisabled(w, r, false, "EnableUser")
+}
+
+// setUserDisabled изменяет статус учетной записи пользователя из пути запроса.
+func (h *AdminHandler) setUserDisabled(w http.ResponseWriter, r *http.Request, disabled bool, handlerName string) {
+	username := chi.URLParam(r, "username")
+	if err := h.userAdminService.SetUserDisabled(r.Context(), username, disabled); err != nil {
+		writeAdminUserError(w, r, err, handlerName)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UserStorage обрабатывает GET запрос на получение объема хранилища пользователя.
+func (h *AdminHandler) UserStorage(w http.ResponseWriter, r *http.Request) {
+	usage, err := h.userAdminService.GetStorageUsage(r.Context(), chi.URLParam(r, "username"))
+	if err != nil {
+		writeAdminUserError(w, r, err, "UserStorage")
+		return
+	}
+	writeAdminJSON(w, usage, "UserStorage")
+}
+
+// Stats обрабатывает GET запрос на получение сводной статистики сервера.
+func (h *AdminHandler) Stats(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.userAdminService.ServerStats(r.Context())
+	if err != nil {
+		log.Printf("[AdminHandler:Stats] Ошибка получения статистики сервера: %v", err)
+		middleware.WriteError(w, r, http.StatusInternalServerError, models.ErrCodeInternal, "Внутренняя ошибка сервера")
+		return
+	}
+	writeAdminJSON(w, stats, "Stats")
+}
+
+// adminPagination читает параметры limit и offset; некорректные значения заменяются значениями по умолчанию.
+func adminPagination(r *http.Request) (int, int) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
+
+// writeAdminUserError отправляет ответ на ошибку операции с пользователем.
+func writeAdminUserError(w http.ResponseWriter, r *http.Request, err error, handlerName string) {
+	if errors.Is(err, services.ErrUserNotFound) {
+		middleware.WriteError(w, r, http.StatusNotFound, models.ErrCodeNotFound, err.Error())
+		return
+	}
+	log.Printf("[AdminHandler:%s] Ошибка операции с пользователем: %v", handlerName, err)
+	middleware.WriteError(w, r, http.StatusInternalServerError, models.ErrCodeInternal, "Внутренняя ошибка сервера")
+}
+
+// writeAdminJSON отправляет ответ в формате JSON.
+func writeAdminJSON(w http.ResponseWriter, response any, handlerName string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("[AdminHandler:%s] Ошибка кодирования ответа: %v", handlerName, err)
+	}
+}
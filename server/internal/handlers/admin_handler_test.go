@@ -0,0 +1,252 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/maynagashev/gophkeeper/models"
+	"github.com/maynagashev/gophkeeper/server/internal/handlers"
+	"github.com/maynagashev/gophkeeper/server/internal/mocks"
+	"github.com/maynagashev/gophkeeper/server/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminHandler_StorageIntegrity(t *testing.T) {
+	status := models.VerificationStatusCorrupted
+	problems := []models.VaultVersion{{ID: 5, VaultID: 1, ObjectKey: "user_1/vault.kdbx", VerificationStatus: &status}}
+	report := &models.StorageScrubReport{StartedAt: time.Now().UTC(), Checked: 3, OK: 2, Corrupted: 1}
+
+	tests := []struct {
+		name           string
+		query          string
+		setupMock      func(m *mocks.IntegrityService)
+		expectedStatus int
+		checkBody      func(t *testing.T, body []byte)
+	}{
+		{
+			name:  "Успешное получение отчета",
+			query: "?limit=10&offset=5",
+			setupMock: func(m *mocks.IntegrityService) {
+				m.EXPECT().ListProblems(mock.Anything, 10, 5).Return(problems, nil).Once()
+				m.EXPECT().LastReport().Return(report).Once()
+			},
+			expectedStatus: http.StatusOK,
+			checkBody: func(t *testing.T, body []byte) {
+				var resp models.StorageIntegrityResponse
+				require.NoError(t, json.Unmarshal(body, &resp))
+				require.Len(t, resp.Problems, 1)
+				assert.Equal(t, int64(5), resp.Problems[0].ID)
+				require.NotNil(t, resp.LastScrub)
+				assert.Equal(t, 1, resp.LastScrub.Corrupted)
+			},
+		},
+		{
+			name:  "Некорректная пагинация заменяется значениями по умолчанию",
+			query: "?limit=1000&offset=-1",
+			setupMock: func(m *mocks.IntegrityService) {
+				m.EXPECT().ListProblems(mock.Anything, 20, 0).Return([]models.VaultVersion{}, nil).Once()
+				m.EXPECT().LastReport().Return(nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			checkBody: func(t *testing.T, body []byte) {
+				var resp models.StorageIntegrityResponse
+				require.NoError(t, json.Unmarshal(body, &resp))
+				assert.Empty(t, resp.Problems)
+				assert.Nil(t, resp.LastScrub)
+			},
+		},
+		{
+			name: "Ошибка сервиса",
+			setupMock: func(m *mocks.IntegrityService) {
+				m.EXPECT().ListProblems(mock.Anything, 20, 0).Return(nil, errors.New("db error")).Once()
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := mocks.NewIntegrityService(t)
+			tt.setupMock(mockService)
+			handler := handlers.NewAdminHandler(mockService, nil)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/admin/storage/integrity"+tt.query, nil)
+			rr := httptest.NewRecorder()
+			handler.StorageIntegrity(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.checkBody != nil {
+				tt.checkBody(t, rr.Body.Bytes())
+			}
+		})
+	}
+}
+
+func TestAdminHandler_StorageScrub(t *testing.T) {
+	t.Run("Успешный проход", func(t *testing.T) {
+		mockService := mocks.NewIntegrityService(t)
+		mockService.EXPECT().ScrubOnce(mock.Anything).
+			Return(&models.StorageScrubReport{Checked: 2, OK: 1, Missing: 1}, nil).Once()
+		handler := handlers.NewAdminHandler(mockService, nil)
+
+		rr := httptest.NewRecorder()
+		handler.StorageScrub(rr, httptest.NewRequest(http.MethodPost, "/api/admin/storage/scrub", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var report models.StorageScrubReport
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &report))
+		assert.Equal(t, 1, report.Missing)
+	})
+
+	t.Run("Ошибка прохода", func(t *testing.T) {
+		mockService := mocks.NewIntegrityService(t)
+		mockService.EXPECT().ScrubOnce(mock.Anything).Return(nil, errors.New("db error")).Once()
+		handler := handlers.NewAdminHandler(mockService, nil)
+
+		rr := httptest.NewRecorder()
+		handler.StorageScrub(rr, httptest.NewRequest(http.MethodPost, "/api/admin/storage/scrub", nil))
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+}
+
+// withUsername добавляет в запрос параметр маршрута {username}.
+func withUsername(req *http.Request, username string) *http.Request {
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("username", username)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestAdminHandler_ListUsers(t *testing.T) {
+	t.Run("Успешное получение списка", func(t *testing.T) {
+		mockService := mocks.NewUserAdminService(t)
+		mockService.EXPECT().ListUsers(mock.Anything, "ali", 10, 0).
+			Return([]models.UserSummary{{ID: 1, Username: "alice", Role: models.RoleAdmin}}, nil).Once()
+		handler := handlers.NewAdminHandler(nil, mockService)
+
+		rr := httptest.NewRecorder()
+		handler.ListUsers(rr, httptest.NewRequest(http.MethodGet, "/api/admin/users?search=ali&limit=10", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var users []models.UserSummary
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &users))
+		require.Len(t, users, 1)
+		assert.Equal(t, models.RoleAdmin, users[0].Role)
+	})
+
+	t.Run("Ошибка сервиса", func(t *testing.T) {
+		mockService := mocks.NewUserAdminService(t)
+		mockService.EXPECT().ListUsers(mock.Anything, "", 20, 0).Return(nil, errors.New("db error")).Once()
+		handler := handlers.NewAdminHandler(nil, mockService)
+
+		rr := httptest.NewRecorder()
+		handler.ListUsers(rr, httptest.NewRequest(http.MethodGet, "/api/admin/users", nil))
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+}
+
+func TestAdminHandler_SetUserDisabled(t *testing.T) {
+	tests := []struct {
+		name           string
+		disable        bool
+		serviceErr     error
+		expectedStatus int
+	}{
+		{name: "Отключение", disable: true, expectedStatus: http.StatusNoContent},
+		{name: "Включение", disable: false, expectedStatus: http.StatusNoContent},
+		{
+			name: "Пользователь не найден", disable: true,
+			serviceErr: services.ErrUserNotFound, expectedStatus: http.StatusNotFound,
+		},
+		{
+			name: "Ошибка сервиса", disable: false,
+			serviceErr: errors.New("db error"), expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := mocks.NewUserAdminService(t)
+			mockService.EXPECT().SetUserDisabled(mock.Anything, "alice", tt.disable).Return(tt.serviceErr).Once()
+			handler := handlers.NewAdminHandler(nil, mockService)
+
+			req := withUsername(httptest.NewRequest(http.MethodPost, "/api/admin/users/alice/disable", nil), "alice")
+			rr := httptest.NewRecorder()
+			if tt.disable {
+				handler.DisableUser(rr, req)
+			} else {
+				handler.EnableUser(rr, req)
+			}
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestAdminHandler_UserStorage(t *testing.T) {
+	t.Run("Успешное получение", func(t *testing.T) {
+		mockService := mocks.NewUserAdminService(t)
+		mockService.EXPECT().GetStorageUsage(mock.Anything, "alice").
+			Return(&models.UserStorageUsage{Username: "alice", VersionCount: 3, StorageBytes: 300}, nil).Once()
+		handler := handlers.NewAdminHandler(nil, mockService)
+
+		rr := httptest.NewRecorder()
+		handler.UserStorage(rr, withUsername(httptest.NewRequest(http.MethodGet, "/api/admin/users/alice/storage", nil),
+			"alice"))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var usage models.UserStorageUsage
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &usage))
+		assert.Equal(t, int64(300), usage.StorageBytes)
+	})
+
+	t.Run("Пользователь не найден", func(t *testing.T) {
+		mockService := mocks.NewUserAdminService(t)
+		mockService.EXPECT().GetStorageUsage(mock.Anything, "bob").Return(nil, services.ErrUserNotFound).Once()
+		handler := handlers.NewAdminHandler(nil, mockService)
+
+		rr := httptest.NewRecorder()
+		handler.UserStorage(rr, withUsername(httptest.NewRequest(http.MethodGet, "/api/admin/users/bob/storage", nil),
+			"bob"))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestAdminHandler_Stats(t *testing.T) {
+	t.Run("Успешное получение", func(t *testing.T) {
+		mockService := mocks.NewUserAdminService(t)
+		mockService.EXPECT().ServerStats(mock.Anything).
+			Return(&models.ServerStats{Users: 10, Admins: 1, StorageBytes: 4096}, nil).Once()
+		handler := handlers.NewAdminHandler(nil, mockService)
+
+		rr := httptest.NewRecorder()
+		handler.Stats(rr, httptest.NewRequest(http.MethodGet, "/api/admin/stats", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var stats models.ServerStats
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &stats))
+		assert.Equal(t, int64(10), stats.Users)
+		assert.Nil(t, stats.LastScrub)
+	})
+
+	t.Run("Ошибка сервиса", func(t *testing.T) {
+		mockService := mocks.NewUserAdminService(t)
+		mockService.EXPECT().ServerStats(mock.Anything).Return(nil, errors.New("db error")).Once()
+		handler := handlers.NewAdminHandler(nil, mockService)
+
+		rr := httptest.NewRecorder()
+		handler.Stats(rr, httptest.NewRequest(http.MethodGet, "/api/admin/stats", nil))
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+}
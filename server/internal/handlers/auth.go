@@ -4,10 +4,12 @@ import (
 	"encoding/json"
 	"errors"
 	"log"
+	"net"
 	"net/http"
 
-	"github.com/maynagashev/gophkeeper/models"                   // Импортируем наши модели
-	"github.com/maynagashev/gophkeeper/server/internal/services" // Импортируем пакет сервисов
+	"github.com/maynagashev/gophkeeper/models"                     // Импортируем наши модели
+	"github.com/maynagashev/gophkeeper/server/internal/middleware" // Пакет middleware: формат ответов об ошибках
+	"github.com/maynagashev/gophkeeper/server/internal/services"   // Импортируем пакет сервисов
 )
 
 // AuthService определяет интерфейс для сервиса аутентификации.
@@ -33,30 +35,34 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	// Декодируем JSON из тела запроса
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		log.Printf("[AuthHandler] Ошибка декодирования запроса регистрации: %v", err)
-		http.Error(w, "Неверный формат запроса", http.StatusBadRequest)
+		middleware.WriteError(w, r, http.StatusBadRequest, models.ErrCodeInvalidRequest, "Неверный формат запроса")
 		return
 	}
 
 	// Валидация входных данных (простая)
 	if req.Username == "" || req.Password == "" {
 		log.Printf("[AuthHandler] Пустое имя пользователя или пароль при регистрации")
-		http.Error(w, "Имя пользователя и пароль не могут быть пустыми", http.StatusBadRequest)
+		middleware.WriteError(w, r, http.StatusBadRequest, models.ErrCodeInvalidRequest,
+			"Имя пользователя и пароль не могут быть пустыми")
 		return
 	}
 
 	log.Printf("[AuthHandler] Попытка регистрации пользователя: %s", req.Username)
 
 	// Вызываем сервис
-	err := h.service.Register(req.Username, req.Password)
+	err := h.service.Register(r.Context(), req.Username, req.Password)
 	if err != nil {
 		// Обрабатываем ошибки от сервиса
 		if errors.Is(err, services.ErrUsernameTaken) {
 			log.Printf("[AuthHandler] Ошибка регистрации (имя занято): %s", req.Username)
-			http.Error(w, err.Error(), http.StatusConflict) // 409 Conflict
+			middleware.WriteError(w, r, http.StatusConflict, models.ErrCodeUsernameTaken, err.Error())
+		} else if errors.Is(err, services.ErrOperationTimeout) {
+			log.Printf("[AuthHandler] Превышено время регистрации '%s'", req.Username)
+			writeTimeoutError(w, r)
 		} else {
 			// Другие ошибки считаем внутренними
 			log.Printf("[AuthHandler] Внутренняя ошибка при регистрации '%s': %v", req.Username, err)
-			http.Error(w, "Внутренняя ошибка сервера", http.StatusInternalServerError)
+			middleware.WriteError(w, r, http.StatusInternalServerError, models.ErrCodeInternal, "Внутренняя ошибка сервера")
 		}
 		return
 	}
@@ -73,30 +79,37 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	// Декодируем JSON из тела запроса
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		log.Printf("[AuthHandler] Ошибка декодирования запроса входа: %v", err)
-		http.Error(w, "Неверный формат запроса", http.StatusBadRequest)
+		middleware.WriteError(w, r, http.StatusBadRequest, models.ErrCodeInvalidRequest, "Неверный формат запроса")
 		return
 	}
 
 	// Валидация входных данных (простая)
 	if req.Username == "" || req.Password == "" {
 		log.Printf("[AuthHandler] Пустое имя пользователя или пароль при входе")
-		http.Error(w, "Имя пользователя и пароль не могут быть пустыми", http.StatusBadRequest)
+		middleware.WriteError(w, r, http.StatusBadRequest, models.ErrCodeInvalidRequest,
+			"Имя пользователя и пароль не могут быть пустыми")
 		return
 	}
 
 	log.Printf("[AuthHandler] Попытка входа пользователя: %s", req.Username)
 
 	// Вызываем сервис
-	token, err := h.service.Login(req.Username, req.Password)
+	token, err := h.service.Login(r.Context(), req.Username, req.Password, requestDevice(r))
 	if err != nil {
 		// Обрабатываем ошибки от сервиса
 		if errors.Is(err, services.ErrInvalidCredentials) {
 			log.Printf("[AuthHandler] Ошибка входа (неверные данные): %s", req.Username)
-			http.Error(w, err.Error(), http.StatusUnauthorized) // 401 Unauthorized
+			middleware.WriteError(w, r, http.StatusUnauthorized, models.ErrCodeInvalidCredentials, err.Error())
+		} else if errors.Is(err, services.ErrAccountDisabled) {
+			log.Printf("[AuthHandler] Ошибка входа (учетная запись отключена): %s", req.Username)
+			middleware.WriteError(w, r, http.StatusForbidden, models.ErrCodeAccountDisabled, err.Error())
+		} else if errors.Is(err, services.ErrOperationTimeout) {
+			log.Printf("[AuthHandler] Превышено время входа '%s'", req.Username)
+			writeTimeoutError(w, r)
 		} else {
 			// Другие ошибки считаем внутренними
 			log.Printf("[AuthHandler] Внутренняя ошибка при входе '%s': %v", req.Username, err)
-			http.Error(w, "Внутренняя ошибка сервера", http.StatusInternalServerError)
+			middleware.WriteError(w, r, http.StatusInternalServerError, models.ErrCodeInternal, "Внутренняя ошибка сервера")
 		}
 		return
 	}
@@ -114,3 +127,24 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	}
 	log.Printf("[AuthHandler] Успешный вход для: %s", req.Username)
 }
+
+// requestDevice возвращает сведения об устройстве, с которого пришел запрос (в том числе заголовок X-Device-ID).
+// IP-адрес берется из RemoteAddr, который middleware RealIP заменяет адресом из заголовков прокси.
+func requestDevice(r *http.Request) models.Device {
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+	return models.Device{DeviceID: r.Header.Get(models.HeaderDeviceID), UserAgent: r.UserAgent(), IPAddress: ip}
+}
+
+// requestOrigin возвращает сведения о клиенте и устройстве из заголовков X-Client-* и X-Device-ID
+// и IP-адрес запроса.
+func requestOrigin(r *http.Request) models.UploadOrigin {
+	return models.UploadOrigin{
+		ClientName:    r.Header.Get(models.HeaderClientName),
+		ClientVersion: r.Header.Get(models.HeaderClientVersion),
+		DeviceID:      r.Header.Get(models.HeaderDeviceID),
+		IPAddress:     requestDevice(r).IPAddress,
+	}
+}
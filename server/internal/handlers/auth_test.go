@@ -1,6 +1,7 @@
 package handlers_test
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -23,16 +24,22 @@ type MockAuthService struct {
 	mock.Mock
 }
 
-func (m *MockAuthService) Register(username, password string) error {
+func (m *MockAuthService) Register(_ context.Context, username, password string) error {
 	args := m.Called(username, password)
 	return args.Error(0)
 }
 
-func (m *MockAuthService) Login(username, password string) (string, error) {
-	args := m.Called(username, password)
+func (m *MockAuthService) Login(_ context.Context, username, password string, device models.Device) (string, error) {
+	args := m.Called(username, password, device)
 	return args.String(0), args.Error(1)
 }
 
+func (m *MockAuthService) VerifyCredentials(_ context.Context, username, password string) (*models.User, error) {
+	args := m.Called(username, password)
+	user, _ := args.Get(0).(*models.User)
+	return user, args.Error(1)
+}
+
 // --- Tests --- //
 
 func TestNewAuthHandler(t *testing.T) {
@@ -185,6 +192,15 @@ func TestAuthHandler_Login(t *testing.T) {
 			expectedStatus:  http.StatusUnauthorized,
 			expectedBody:    services.ErrInvalidCredentials.Error(),
 		},
+		{
+			name:            "Учетная запись отключена",
+			body:            `{"username": "disabled", "password": "password123"}`,
+			mockUsername:    "disabled",
+			mockPassword:    "password123",
+			mockReturnError: services.ErrAccountDisabled,
+			expectedStatus:  http.StatusForbidden,
+			expectedBody:    services.ErrAccountDisabled.Error(),
+		},
 		{
 			name:            "Внутренняя ошибка сервера",
 			body:            `{"username": "erroruser", "password": "password123"}`,
@@ -204,10 +220,14 @@ func TestAuthHandler_Login(t *testing.T) {
 
 			// Настраиваем мок только если ожидается вызов сервиса
 			if tt.mockUsername != "" || tt.mockPassword != "" {
-				mockService.On("Login", tt.mockUsername, tt.mockPassword).Return(tt.mockReturnToken, tt.mockReturnError).Once()
+				device := models.Device{DeviceID: "device-1", UserAgent: "gophkeeper-test", IPAddress: "192.0.2.1"}
+				mockService.On("Login", tt.mockUsername, tt.mockPassword, device).
+					Return(tt.mockReturnToken, tt.mockReturnError).Once()
 			}
 
 			req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(tt.body))
+			req.Header.Set("User-Agent", "gophkeeper-test")
+			req.Header.Set(models.HeaderDeviceID, "device-1")
 			rr := httptest.NewRecorder()
 
 			r.ServeHTTP(rr, req)
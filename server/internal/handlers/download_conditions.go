@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/maynagashev/gophkeeper/models"
+)
+
+// vaultETag возвращает сильный ETag версии хранилища - SHA256 ее содержимого в кавычках.
+// Для версий без сохраненной контрольной суммы возвращается пустая строка.
+func vaultETag(version *models.VaultVersion) string {
+	if version.Checksum == nil || *version.Checksum == "" {
+		return ""
+	}
+	return `"` + *version.Checksum + `"`
+}
+
+// setDownloadValidators устанавливает заголовки, по которым клиент проверяет актуальность своей копии.
+func setDownloadValidators(h http.Header, version *models.VaultVersion) {
+	h.Set("Accept-Ranges", "bytes")
+	// Клиент всегда должен перепроверять актуальность у сервера, промежуточные кэши данные не хранят
+	h.Set("Cache-Control", "private, no-cache")
+	if etag := vaultETag(version); etag != "" {
+		h.Set("ETag", etag)
+	}
+	if !version.CreatedAt.IsZero() {
+		h.Set("Last-Modified", version.CreatedAt.UTC().Format(http.TimeFormat))
+	}
+}
+
+// isNotModified проверяет условия If-None-Match и If-Modified-Since.
+// Как требует RFC 9110, If-Modified-Since учитывается только при отсутствии If-None-Match.
+func isNotModified(r *http.Request, version *models.VaultVersion) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		return etagListMatches(inm, vaultETag(version))
+	}
+	ims := r.Header.Get("If-Modified-Since")
+	if ims == "" || version.CreatedAt.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(ims)
+	if err != nil {
+		return false
+	}
+	return !version.CreatedAt.Truncate(time.Second).After(since)
+}
+
+// etagListMatches проверяет, совпадает ли etag с одним из значений списка If-None-Match (слабое сравнение).
+func etagListMatches(header, etag string) bool {
+	if etag == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// rangeApplies проверяет условие If-Range: диапазон отдается, только если версия не изменилась.
+func rangeApplies(r *http.Request, version *models.VaultVersion) bool {
+	ifRange := r.Header.Get("If-Range")
+	if ifRange == "" {
+		return true
+	}
+	if strings.HasPrefix(ifRange, `"`) || strings.HasPrefix(ifRange, "W/") {
+		// Для If-Range допускается только сильное сравнение
+		etag := vaultETag(version)
+		return etag != "" && ifRange == etag
+	}
+	since, err := http.ParseTime(ifRange)
+	if err != nil || version.CreatedAt.IsZero() {
+		return false
+	}
+	return version.CreatedAt.Truncate(time.Second).Equal(since)
+}
+
+// parseByteRange разбирает заголовок Range с одним диапазоном байт для файла размером size.
+// Возвращает смещение и длину запрошенной части. Несколько диапазонов и некорректный синтаксис
+// возвращают errRangeIgnored (файл отдается целиком), недостижимый диапазон - errRangeNotSatisfiable.
+func parseByteRange(header string, size int64) (int64, int64, error) {
+	spec, ok := strings.CutPrefix(header, "bytes=")
+	if !ok || strings.Contains(spec, ",") {
+		return 0, 0, errRangeIgnored
+	}
+	startStr, endStr, ok := strings.Cut(strings.TrimSpace(spec), "-")
+	if !ok {
+		return 0, 0, errRangeIgnored
+	}
+
+	if startStr == "" {
+		// Суффиксный диапазон: последние N байт
+		suffix, err := strconv.ParseInt(endStr, 10, 64)
+		if err != nil || suffix < 0 {
+			return 0, 0, errRangeIgnored
+		}
+		if suffix == 0 || size == 0 {
+			return 0, 0, errRangeNotSatisfiable
+		}
+		suffix = min(suffix, size)
+		return size - suffix, suffix, nil
+	}
+
+	start, err := strconv.ParseInt(startStr, 10, 64)
+	if err != nil || start < 0 {
+		return 0, 0, errRangeIgnored
+	}
+	end := size - 1
+	if endStr != "" {
+		end, err = strconv.ParseInt(endStr, 10, 64)
+		if err != nil || end < start {
+			return 0, 0, errRangeIgnored
+		}
+		end = min(end, size-1)
+	}
+	if start >= size {
+		return 0, 0, errRangeNotSatisfiable
+	}
+	return start, end - start + 1, nil
+}
+
+// contentRange формирует значение заголовка Content-Range.
+func contentRange(offset, length, size int64) string {
+	return fmt.Sprintf("bytes %d-%d/%d", offset, offset+length-1, size)
+}
+
+// Ошибки разбора заголовка Range.
+var (
+	errRangeIgnored        = errors.New("заголовок Range не поддерживается и игнорируется")
+	errRangeNotSatisfiable = errors.New("запрошенный диапазон за пределами файла")
+)
@@ -0,0 +1,281 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/maynagashev/gophkeeper/models"
+	"github.com/maynagashev/gophkeeper/server/internal/middleware"
+	"github.com/maynagashev/gophkeeper/server/internal/services"
+)
+
+// maxKeyEnvelopeBodySize ограничивает тело запроса с ключом для контакта (ключ передается в base64).
+const maxKeyEnvelopeBodySize = 2 * services.MaxKeyEnvelopeSize
+
+// EmergencyAccessHandler обрабатывает HTTP-запросы экстренного доступа доверенных контактов.
+// Владелец управляет контактами через /emergency/contacts, контакт запрашивает доступ через /emergency/grants.
+type EmergencyAccessHandler struct {
+	emergencyService services.EmergencyAccessService
+}
+
+// NewEmergencyAccessHandler создает новый экземпляр EmergencyAccessHandler.
+func NewEmergencyAccessHandler(s services.EmergencyAccessService) *EmergencyAccessHandler {
+	return &EmergencyAccessHandler{emergencyService: s}
+}
+
+// ListContacts обрабатывает GET запрос на получение доверенных контактов владельца.
+func (h *EmergencyAccessHandler) ListContacts(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userID(w, r, "ListContacts")
+	if !ok {
+		return
+	}
+
+	contacts, err := h.emergencyService.ListContacts(r.Context(), userID)
+	if err != nil {
+		h.writeServiceError(w, r, err, "ListContacts")
+		return
+	}
+	writeEmergencyJSON(w, http.StatusOK, contacts, "ListContacts")
+}
+
+// SetContact обрабатывает POST запрос на назначение доверенного контакта или изменение периода ожидания.
+func (h *EmergencyAccessHandler) SetContact(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userID(w, r, "SetContact")
+	if !ok {
+		return
+	}
+
+	var req models.SetEmergencyContactRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("[EmergencyAccessHandler:SetContact] Ошибка декодирования запроса: %v", err)
+		middleware.WriteError(w, r, http.StatusBadRequest, models.ErrCodeInvalidRequest, "Неверный формат запроса")
+		return
+	}
+
+	access, err := h.emergencyService.SetContact(r.Context(), userID, req.Username, req.WaitPeriodHours)
+	if err != nil {
+		h.writeServiceError(w, r, err, "SetContact")
+		return
+	}
+	writeEmergencyJSON(w, http.StatusOK, access, "SetContact")
+}
+
+// RemoveContact обрабатывает DELETE запрос на отмену назначения доверенного контакта.
+func (h *EmergencyAccessHandler) RemoveContact(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userID(w, r, "RemoveContact")
+	if !ok {
+		return
+	}
+	contactID, ok := parseEmergencyUserID(w, r, "contactID")
+	if !ok {
+		return
+	}
+
+	if err := h.emergencyService.RemoveContact(r.Context(), userID, contactID); err != nil {
+		h.writeServiceError(w, r, err, "RemoveContact")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SetKeyEnvelope обрабатывает PUT запрос на сохранение ключа к хранилищу, зашифрованного для контакта.
+func (h *EmergencyAccessHandler) SetKeyEnvelope(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userID(w, r, "SetKeyEnvelope")
+	if !ok {
+		return
+	}
+	contactID, ok := parseEmergencyUserID(w, r, "contactID")
+	if !ok {
+		return
+	}
+
+	var req models.KeyEnvelope
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxKeyEnvelopeBodySize)).Decode(&req); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			middleware.WriteErrorDetails(w, r, http.StatusRequestEntityTooLarge, models.ErrCodePayloadTooLarge,
+				services.ErrInvalidKeyEnvelope.Error(), map[string]any{"max_size": services.MaxKeyEnvelopeSize})
+			return
+		}
+		log.Printf("[EmergencyAccessHandler:SetKeyEnvelope] Ошибка декодирования запроса: %v", err)
+		middleware.WriteError(w, r, http.StatusBadRequest, models.ErrCodeInvalidRequest, "Неверный формат запроса")
+		return
+	}
+
+	if err := h.emergencyService.SetKeyEnvelope(r.Context(), userID, contactID, req.KeyEnvelope); err != nil {
+		h.writeServiceError(w, r, err, "SetKeyEnvelope")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DenyAccess обрабатывает POST запрос владельца на отклонение запроса доступа контакта.
+func (h *EmergencyAccessHandler) DenyAccess(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userID(w, r, "DenyAccess")
+	if !ok {
+		return
+	}
+	contactID, ok := parseEmergencyUserID(w, r, "contactID")
+	if !ok {
+		return
+	}
+
+	access, err := h.emergencyService.DenyAccess(r.Context(), userID, contactID)
+	if err != nil {
+		h.writeServiceError(w, r, err, "DenyAccess")
+		return
+	}
+	writeEmergencyJSON(w, http.StatusOK, access, "DenyAccess")
+}
+
+// ListGrants обрабатывает GET запрос на получение владельцев, назначивших пользователя доверенным контактом.
+func (h *EmergencyAccessHandler) ListGrants(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userID(w, r, "ListGrants")
+	if !ok {
+		return
+	}
+
+	grants, err := h.emergencyService.ListGrants(r.Context(), userID)
+	if err != nil {
+		h.writeServiceError(w, r, err, "ListGrants")
+		return
+	}
+	writeEmergencyJSON(w, http.StatusOK, grants, "ListGrants")
+}
+
+// RequestAccess обрабатывает POST запрос контакта на экстренный доступ к хранилищу владельца.
+func (h *EmergencyAccessHandler) RequestAccess(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userID(w, r, "RequestAccess")
+	if !ok {
+		return
+	}
+	ownerID, ok := parseEmergencyUserID(w, r, "ownerID")
+	if !ok {
+		return
+	}
+
+	access, err := h.emergencyService.RequestAccess(r.Context(), userID, ownerID)
+	if err != nil {
+		h.writeServiceError(w, r, err, "RequestAccess")
+		return
+	}
+	writeEmergencyJSON(w, http.StatusOK, access, "RequestAccess")
+}
+
+// GetKeyEnvelope обрабатывает GET запрос контакта на получение ключа, оставленного владельцем.
+func (h *EmergencyAccessHandler) GetKeyEnvelope(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userID(w, r, "GetKeyEnvelope")
+	if !ok {
+		return
+	}
+	ownerID, ok := parseEmergencyUserID(w, r, "ownerID")
+	if !ok {
+		return
+	}
+
+	envelope, err := h.emergencyService.GetKeyEnvelope(r.Context(), userID, ownerID)
+	if err != nil {
+		h.writeServiceError(w, r, err, "GetKeyEnvelope")
+		return
+	}
+	writeEmergencyJSON(w, http.StatusOK, models.KeyEnvelope{KeyEnvelope: envelope}, "GetKeyEnvelope")
+}
+
+// OwnerVault позволяет доверенному контакту читать хранилище владельца через обычные маршруты /vault.
+// Если в запросе указан параметр owner_id, middleware проверяет открытый экстренный доступ
+// и подменяет пользователя в контексте на владельца. Без параметра запрос обрабатывается как обычно.
+func (h *EmergencyAccessHandler) OwnerVault(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		param := r.URL.Query().Get("owner_id")
+		if param == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		userID, ok := h.userID(w, r, "OwnerVault")
+		if !ok {
+			return
+		}
+		ownerID, err := strconv.ParseInt(param, 10, 64)
+		if err != nil || ownerID <= 0 {
+			middleware.WriteError(w, r, http.StatusBadRequest, models.ErrCodeInvalidRequest, "Неверный ID владельца")
+			return
+		}
+		if ownerID == userID {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if err = h.emergencyService.CheckAccess(r.Context(), userID, ownerID); err != nil {
+			if errors.Is(err, services.ErrEmergencyAccessNotFound) {
+				// Не раскрываем, существует ли пользователь с таким ID
+				err = services.ErrEmergencyAccessNotGranted
+			}
+			h.writeServiceError(w, r, err, "OwnerVault")
+			return
+		}
+		log.Printf("[EmergencyAccessHandler:OwnerVault] Контакт %d читает хранилище пользователя %d: %s %s",
+			userID, ownerID, r.Method, r.URL.Path)
+		ctx := context.WithValue(r.Context(), middleware.UserIDKey, ownerID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// userID возвращает ID пользователя из контекста или отправляет ответ 500.
+func (h *EmergencyAccessHandler) userID(w http.ResponseWriter, r *http.Request, handlerName string) (int64, bool) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Printf("[EmergencyAccessHandler:%s] Не удалось получить userID из контекста", handlerName)
+		middleware.WriteError(w, r, http.StatusInternalServerError, models.ErrCodeInternal, "Внутренняя ошибка сервера")
+	}
+	return userID, ok
+}
+
+// writeServiceError отправляет ответ, соответствующий ошибке сервиса экстренного доступа.
+func (h *EmergencyAccessHandler) writeServiceError(
+	w http.ResponseWriter,
+	r *http.Request,
+	err error,
+	handlerName string,
+) {
+	switch {
+	case errors.Is(err, services.ErrInvalidWaitPeriod),
+		errors.Is(err, services.ErrEmergencyContactSelf),
+		errors.Is(err, services.ErrInvalidKeyEnvelope):
+		middleware.WriteError(w, r, http.StatusBadRequest, models.ErrCodeInvalidRequest, err.Error())
+	case errors.Is(err, services.ErrUserNotFound),
+		errors.Is(err, services.ErrEmergencyAccessNotFound),
+		errors.Is(err, services.ErrKeyEnvelopeNotFound):
+		middleware.WriteError(w, r, http.StatusNotFound, models.ErrCodeNotFound, err.Error())
+	case errors.Is(err, services.ErrEmergencyAccessNotGranted):
+		middleware.WriteError(w, r, http.StatusForbidden, models.ErrCodeForbidden, err.Error())
+	case errors.Is(err, services.ErrNoAccessRequest):
+		middleware.WriteError(w, r, http.StatusConflict, models.ErrCodeNoAccessRequest, err.Error())
+	default:
+		log.Printf("[EmergencyAccessHandler:%s] Ошибка сервиса: %v", handlerName, err)
+		middleware.WriteError(w, r, http.StatusInternalServerError, models.ErrCodeInternal, "Внутренняя ошибка сервера")
+	}
+}
+
+// parseEmergencyUserID разбирает ID пользователя из параметра пути или отправляет ответ 400.
+func parseEmergencyUserID(w http.ResponseWriter, r *http.Request, param string) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, param), 10, 64)
+	if err != nil || id <= 0 {
+		middleware.WriteError(w, r, http.StatusBadRequest, models.ErrCodeInvalidRequest, "Неверный ID пользователя")
+		return 0, false
+	}
+	return id, true
+}
+
+// writeEmergencyJSON отправляет ответ в формате JSON.
+func writeEmergencyJSON(w http.ResponseWriter, status int, response any, handlerName string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("[EmergencyAccessHandler:%s] Ошибка кодирования ответа: %v", handlerName, err)
+	}
+}
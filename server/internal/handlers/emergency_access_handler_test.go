@@ -0,0 +1,295 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/maynagashev/gophkeeper/models"
+	"github.com/maynagashev/gophkeeper/server/internal/handlers"
+	"github.com/maynagashev/gophkeeper/server/internal/middleware"
+	"github.com/maynagashev/gophkeeper/server/internal/mocks"
+	"github.com/maynagashev/gophkeeper/server/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// setupEmergencyRouter создает роутер API v2 с маршрутами экстренного доступа и userID в контексте.
+// Маршрут /vault возвращает ID пользователя, от имени которого читается хранилище.
+func setupEmergencyRouter(h *handlers.EmergencyAccessHandler, userID int64) *chi.Mux {
+	r := chi.NewRouter()
+	r.Use(middleware.APIv2)
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), middleware.UserIDKey, userID)))
+		})
+	})
+	r.Get("/emergency/contacts", h.ListContacts)
+	r.Post("/emergency/contacts", h.SetContact)
+	r.Delete("/emergency/contacts/{contactID}", h.RemoveContact)
+	r.Put("/emergency/contacts/{contactID}/envelope", h.SetKeyEnvelope)
+	r.Post("/emergency/contacts/{contactID}/deny", h.DenyAccess)
+	r.Get("/emergency/grants", h.ListGrants)
+	r.Post("/emergency/grants/{ownerID}/request", h.RequestAccess)
+	r.Get("/emergency/grants/{ownerID}/envelope", h.GetKeyEnvelope)
+	r.With(h.OwnerVault).Get("/vault", func(w http.ResponseWriter, r *http.Request) {
+		vaultUserID, _ := middleware.GetUserIDFromContext(r.Context())
+		_ = json.NewEncoder(w).Encode(vaultUserID)
+	})
+	return r
+}
+
+func TestEmergencyAccessHandler_SetContact(t *testing.T) {
+	const userID = int64(1)
+
+	tests := []struct {
+		name           string
+		body           string
+		setupMock      func(m *mocks.EmergencyAccessService)
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name: "Успешное назначение",
+			body: `{"username": "contact", "wait_period_hours": 48}`,
+			setupMock: func(m *mocks.EmergencyAccessService) {
+				m.EXPECT().SetContact(mock.Anything, userID, "contact", 48).
+					Return(&models.EmergencyAccess{OwnerID: userID, ContactID: 2, WaitPeriodHours: 48}, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Невалидный JSON",
+			body:           `{"username":`,
+			setupMock:      func(_ *mocks.EmergencyAccessService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   models.ErrCodeInvalidRequest,
+		},
+		{
+			name: "Некорректный период ожидания",
+			body: `{"username": "contact", "wait_period_hours": 0}`,
+			setupMock: func(m *mocks.EmergencyAccessService) {
+				m.EXPECT().SetContact(mock.Anything, userID, "contact", 0).Return(nil, services.ErrInvalidWaitPeriod).Once()
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   models.ErrCodeInvalidRequest,
+		},
+		{
+			name: "Пользователь не найден",
+			body: `{"username": "ghost", "wait_period_hours": 24}`,
+			setupMock: func(m *mocks.EmergencyAccessService) {
+				m.EXPECT().SetContact(mock.Anything, userID, "ghost", 24).Return(nil, services.ErrUserNotFound).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   models.ErrCodeNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := mocks.NewEmergencyAccessService(t)
+			tt.setupMock(svc)
+			r := setupEmergencyRouter(handlers.NewEmergencyAccessHandler(svc), userID)
+			rr := httptest.NewRecorder()
+
+			r.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/emergency/contacts", strings.NewReader(tt.body)))
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedCode != "" {
+				var resp models.ErrorResponse
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+				assert.Equal(t, tt.expectedCode, resp.Code)
+			}
+		})
+	}
+}
+
+func TestEmergencyAccessHandler_OwnerActions(t *testing.T) {
+	const userID = int64(1)
+
+	t.Run("Сохранение ключа для контакта", func(t *testing.T) {
+		svc := mocks.NewEmergencyAccessService(t)
+		svc.EXPECT().SetKeyEnvelope(mock.Anything, userID, int64(2), []byte("envelope")).Return(nil).Once()
+		r := setupEmergencyRouter(handlers.NewEmergencyAccessHandler(svc), userID)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodPut, "/emergency/contacts/2/envelope",
+			strings.NewReader(`{"key_envelope": "ZW52ZWxvcGU="}`)))
+
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+	})
+
+	t.Run("Слишком большой ключ", func(t *testing.T) {
+		r := setupEmergencyRouter(handlers.NewEmergencyAccessHandler(mocks.NewEmergencyAccessService(t)), userID)
+		rr := httptest.NewRecorder()
+		body := `{"key_envelope": "` + strings.Repeat("A", 2*services.MaxKeyEnvelopeSize) + `"}`
+
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodPut, "/emergency/contacts/2/envelope", strings.NewReader(body)))
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+	})
+
+	t.Run("Отклонение без запроса", func(t *testing.T) {
+		svc := mocks.NewEmergencyAccessService(t)
+		svc.EXPECT().DenyAccess(mock.Anything, userID, int64(2)).Return(nil, services.ErrNoAccessRequest).Once()
+		r := setupEmergencyRouter(handlers.NewEmergencyAccessHandler(svc), userID)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/emergency/contacts/2/deny", nil))
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+		var resp models.ErrorResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, models.ErrCodeNoAccessRequest, resp.Code)
+	})
+
+	t.Run("Удаление неизвестного контакта", func(t *testing.T) {
+		svc := mocks.NewEmergencyAccessService(t)
+		svc.EXPECT().RemoveContact(mock.Anything, userID, int64(2)).Return(services.ErrEmergencyAccessNotFound).Once()
+		r := setupEmergencyRouter(handlers.NewEmergencyAccessHandler(svc), userID)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/emergency/contacts/2", nil))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Неверный ID контакта", func(t *testing.T) {
+		r := setupEmergencyRouter(handlers.NewEmergencyAccessHandler(mocks.NewEmergencyAccessService(t)), userID)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/emergency/contacts/abc", nil))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestEmergencyAccessHandler_ContactActions(t *testing.T) {
+	const contactID = int64(2)
+
+	t.Run("Запрос доступа", func(t *testing.T) {
+		svc := mocks.NewEmergencyAccessService(t)
+		svc.EXPECT().RequestAccess(mock.Anything, contactID, int64(1)).
+			Return(&models.EmergencyAccess{OwnerID: 1, ContactID: contactID, Status: models.EmergencyAccessRequested}, nil).
+			Once()
+		r := setupEmergencyRouter(handlers.NewEmergencyAccessHandler(svc), contactID)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/emergency/grants/1/request", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var access models.EmergencyAccess
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &access))
+		assert.Equal(t, models.EmergencyAccessRequested, access.Status)
+	})
+
+	t.Run("Ключ до открытия доступа", func(t *testing.T) {
+		svc := mocks.NewEmergencyAccessService(t)
+		svc.EXPECT().GetKeyEnvelope(mock.Anything, contactID, int64(1)).
+			Return(nil, services.ErrEmergencyAccessNotGranted).Once()
+		r := setupEmergencyRouter(handlers.NewEmergencyAccessHandler(svc), contactID)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/emergency/grants/1/envelope", nil))
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("Ключ после открытия доступа", func(t *testing.T) {
+		svc := mocks.NewEmergencyAccessService(t)
+		svc.EXPECT().GetKeyEnvelope(mock.Anything, contactID, int64(1)).Return([]byte("envelope"), nil).Once()
+		r := setupEmergencyRouter(handlers.NewEmergencyAccessHandler(svc), contactID)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/emergency/grants/1/envelope", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"key_envelope": "ZW52ZWxvcGU="}`, rr.Body.String())
+	})
+
+	t.Run("Ошибка сервиса", func(t *testing.T) {
+		svc := mocks.NewEmergencyAccessService(t)
+		svc.EXPECT().ListGrants(mock.Anything, contactID).Return(nil, errors.New("db error")).Once()
+		r := setupEmergencyRouter(handlers.NewEmergencyAccessHandler(svc), contactID)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/emergency/grants", nil))
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+}
+
+func TestEmergencyAccessHandler_OwnerVault(t *testing.T) {
+	const contactID = int64(2)
+
+	tests := []struct {
+		name           string
+		target         string
+		setupMock      func(m *mocks.EmergencyAccessService)
+		expectedStatus int
+		expectedUserID string
+	}{
+		{
+			name:           "Без owner_id читается свое хранилище",
+			target:         "/vault",
+			setupMock:      func(_ *mocks.EmergencyAccessService) {},
+			expectedStatus: http.StatusOK,
+			expectedUserID: "2",
+		},
+		{
+			name:   "Доступ открыт",
+			target: "/vault?owner_id=1",
+			setupMock: func(m *mocks.EmergencyAccessService) {
+				m.EXPECT().CheckAccess(mock.Anything, contactID, int64(1)).Return(nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedUserID: "1",
+		},
+		{
+			name:   "Период ожидания не истек",
+			target: "/vault?owner_id=1",
+			setupMock: func(m *mocks.EmergencyAccessService) {
+				m.EXPECT().CheckAccess(mock.Anything, contactID, int64(1)).
+					Return(services.ErrEmergencyAccessNotGranted).Once()
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:   "Пользователь не назначен контактом",
+			target: "/vault?owner_id=3",
+			setupMock: func(m *mocks.EmergencyAccessService) {
+				m.EXPECT().CheckAccess(mock.Anything, contactID, int64(3)).
+					Return(services.ErrEmergencyAccessNotFound).Once()
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Неверный owner_id",
+			target:         "/vault?owner_id=abc",
+			setupMock:      func(_ *mocks.EmergencyAccessService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := mocks.NewEmergencyAccessService(t)
+			tt.setupMock(svc)
+			r := setupEmergencyRouter(handlers.NewEmergencyAccessHandler(svc), contactID)
+			rr := httptest.NewRecorder()
+
+			r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedUserID != "" {
+				assert.Equal(t, tt.expectedUserID, strings.TrimSpace(rr.Body.String()))
+			}
+		})
+	}
+}
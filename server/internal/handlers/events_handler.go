@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/maynagashev/gophkeeper/models"
+	"github.com/maynagashev/gophkeeper/server/internal/events"
+	"github.com/maynagashev/gophkeeper/server/internal/middleware"
+)
+
+// eventsHeartbeatInterval - интервал отправки комментариев-пингов, не дающих прокси закрыть соединение.
+const eventsHeartbeatInterval = 25 * time.Second
+
+// EventsHandler отдает события об изменении хранилища в формате Server-Sent Events.
+type EventsHandler struct {
+	subscriber events.Subscriber
+}
+
+// NewEventsHandler создает новый экземпляр EventsHandler.
+func NewEventsHandler(s events.Subscriber) *EventsHandler {
+	return &EventsHandler{subscriber: s}
+}
+
+// Stream обрабатывает GET запрос на подписку на события хранилища пользователя.
+// Соединение остается открытым, пока клиент не отключится.
+func (h *EventsHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Printf("[EventsHandler:Stream] Не удалось получить userID из контекста")
+		middleware.WriteError(w, r, http.StatusInternalServerError, models.ErrCodeInternal, "Внутренняя ошибка сервера")
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	// Поток живет дольше WriteTimeout сервера, поэтому снимаем дедлайн записи для этого соединения
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		log.Printf("[EventsHandler:Stream] Не удалось снять дедлайн записи: %v", err)
+	}
+
+	eventsCh, unsubscribe := h.subscriber.Subscribe(userID)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // Отключаем буферизацию в nginx
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		log.Printf("[EventsHandler:Stream] Потоковая отдача не поддерживается: %v", err)
+		return
+	}
+	log.Printf("[EventsHandler:Stream] Пользователь %d подписался на события хранилища", userID)
+
+	heartbeat := time.NewTicker(eventsHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			log.Printf("[EventsHandler:Stream] Пользователь %d отключился от потока событий", userID)
+			return
+		case event, open := <-eventsCh:
+			if !open {
+				return
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				log.Printf("[EventsHandler:Stream] Ошибка кодирования события: %v", err)
+				continue
+			}
+			if _, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+				log.Printf("[EventsHandler:Stream] Ошибка отправки события пользователю %d: %v", userID, err)
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package handlers_test
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maynagashev/gophkeeper/models"
+	"github.com/maynagashev/gophkeeper/server/internal/handlers"
+	"github.com/maynagashev/gophkeeper/server/internal/middleware"
+	"github.com/maynagashev/gophkeeper/server/internal/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventsHandler_Stream(t *testing.T) {
+	testUserID := int64(1)
+	eventsCh := make(chan models.VaultEvent, 1)
+	unsubscribed := make(chan struct{})
+
+	subscriber := mocks.NewSubscriber(t)
+	subscriber.EXPECT().Subscribe(testUserID).Return(eventsCh, func() { close(unsubscribed) }).Once()
+	handler := handlers.NewEventsHandler(subscriber)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), middleware.UserIDKey, testUserID)
+		handler.Stream(w, r.WithContext(ctx))
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	checksum := "abc"
+	eventsCh <- models.VaultEvent{Type: models.VaultEventVersionCreated, UserID: testUserID, VersionID: 42, Checksum: &checksum}
+
+	reader := bufio.NewReader(resp.Body)
+	eventLine, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	dataLine, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "event: version_created\n", eventLine)
+	assert.True(t, strings.HasPrefix(dataLine, "data: {"), dataLine)
+	assert.Contains(t, dataLine, `"version_id":42`)
+	assert.Contains(t, dataLine, `"checksum":"abc"`)
+
+	// Отключение клиента должно снимать подписку
+	cancel()
+	select {
+	case <-unsubscribed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("подписка не снята после отключения клиента")
+	}
+}
+
+func TestEventsHandler_StreamWithoutUser(t *testing.T) {
+	handler := handlers.NewEventsHandler(mocks.NewSubscriber(t))
+	rr := httptest.NewRecorder()
+
+	handler.Stream(rr, httptest.NewRequest(http.MethodGet, "/api/vault/events", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/maynagashev/gophkeeper/models"
+	"github.com/maynagashev/gophkeeper/server/internal/services"
+)
+
+// HealthHandler обрабатывает запросы проверки состояния сервера от оркестратора.
+type HealthHandler struct {
+	healthService services.HealthService
+}
+
+// NewHealthHandler создает новый экземпляр HealthHandler.
+func NewHealthHandler(hs services.HealthService) *HealthHandler {
+	return &HealthHandler{healthService: hs}
+}
+
+// Liveness обрабатывает GET /healthz: процесс запущен и обслуживает HTTP-запросы.
+// Зависимости не проверяются, чтобы сбой БД или хранилища не приводил к перезапуску процесса.
+func (h *HealthHandler) Liveness(w http.ResponseWriter, _ *http.Request) {
+	writeHealthJSON(w, http.StatusOK, models.HealthReport{Status: models.HealthStatusOK}, "Liveness")
+}
+
+// Readiness обрабатывает GET /readyz: сервер готов принимать трафик.
+// Возвращает 200, если все зависимости доступны, иначе 503; в теле - результат каждой проверки.
+func (h *HealthHandler) Readiness(w http.ResponseWriter, r *http.Request) {
+	report := h.healthService.Ready(r.Context())
+	status := http.StatusOK
+	if report.Status != models.HealthStatusOK {
+		status = http.StatusServiceUnavailable
+		log.Printf("[HealthHandler:Readiness] Сервер не готов: %+v", report.Checks)
+	}
+	writeHealthJSON(w, status, report, "Readiness")
+}
+
+// writeHealthJSON отправляет отчет о состоянии с заданным статусом. Ответ не кэшируется прокси.
+func writeHealthJSON(w http.ResponseWriter, status int, report models.HealthReport, handlerName string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		log.Printf("[HealthHandler:%s] Ошибка кодирования ответа: %v", handlerName, err)
+	}
+}
@@ -0,0 +1,85 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/maynagashev/gophkeeper/models"
+	"github.com/maynagashev/gophkeeper/server/internal/handlers"
+	"github.com/maynagashev/gophkeeper/server/internal/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthHandler_Liveness(t *testing.T) {
+	// Сервис готовности не должен вызываться: mocks.NewHealthService провалит тест при неожиданном вызове
+	h := handlers.NewHealthHandler(mocks.NewHealthService(t))
+
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	rr := httptest.NewRecorder()
+	h.Liveness(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var resp models.HealthReport
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Equal(t, models.HealthStatusOK, resp.Status)
+	assert.Empty(t, resp.Checks)
+}
+
+func TestHealthHandler_Readiness(t *testing.T) {
+	checkedAt := time.Now().UTC()
+
+	tests := []struct {
+		name           string
+		report         models.HealthReport
+		expectedStatus int
+	}{
+		{
+			name: "Все зависимости доступны",
+			report: models.HealthReport{
+				Status: models.HealthStatusOK,
+				Checks: map[string]models.HealthCheck{
+					"database": {Status: models.HealthStatusOK},
+					"storage":  {Status: models.HealthStatusOK},
+				},
+				CheckedAt: &checkedAt,
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Зависимость недоступна",
+			report: models.HealthReport{
+				Status: models.HealthStatusFail,
+				Checks: map[string]models.HealthCheck{
+					"database": {Status: models.HealthStatusOK},
+					"storage":  {Status: models.HealthStatusFail, Error: "connection refused"},
+				},
+				CheckedAt: &checkedAt,
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := mocks.NewHealthService(t)
+			svc.EXPECT().Ready(mock.Anything).Return(tt.report).Once()
+			h := handlers.NewHealthHandler(svc)
+
+			req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+			rr := httptest.NewRecorder()
+			h.Readiness(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.Equal(t, "no-store", rr.Header().Get("Cache-Control"))
+			var resp models.HealthReport
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			assert.Equal(t, tt.report.Status, resp.Status)
+			assert.Equal(t, tt.report.Checks, resp.Checks)
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"io"
 	"log"
+	"mime"
 	"net/http"
 	"strconv"
 	"time"
@@ -14,6 +15,17 @@ import (
 	"github.com/maynagashev/gophkeeper/server/internal/services"
 )
 
+const (
+	// maxBufferedDownloadSize - максимальный размер файла, который проверяется целиком до отправки.
+	maxBufferedDownloadSize = 8 << 20
+
+	// IntegrityTrailer - заголовок (или трейлер для потоковой отдачи) с результатом проверки контрольной суммы.
+	IntegrityTrailer         = "X-Gophkeeper-Integrity"
+	contentModifiedAtHeader  = "X-Kdbx-Content-Modified-At"
+	integrityStatusOK        = "ok"
+	integrityStatusCorrupted = "corrupted"
+)
+
 // VaultHandler обрабатывает HTTP-запросы, связанные с хранилищем.
 type VaultHandler struct {
 	vaultService services.VaultService
@@ -29,22 +41,22 @@ func (h *VaultHandler) GetMetadata(w http.ResponseWriter, r *http.Request) {
 	userID, ok := middleware.GetUserIDFromContext(r.Context())
 	if !ok {
 		log.Printf("[VaultHandler:GetMetadata] Не удалось получить userID из контекста")
-		http.Error(w, "Внутренняя ошибка сервера", http.StatusInternalServerError)
+		middleware.WriteError(w, r, http.StatusInternalServerError, models.ErrCodeInternal, "Внутренняя ошибка сервера")
 		return
 	}
 
 	log.Printf("[VaultHandler:GetMetadata] Запрос метаданных от пользователя %d", userID)
 
 	// Вызываем сервис для получения метаданных ТЕКУЩЕЙ версии
-	currentVersion, err := h.vaultService.GetVaultMetadata(userID)
+	currentVersion, err := h.vaultService.GetVaultMetadata(r.Context(), userID)
 	if err != nil {
 		if errors.Is(err, services.ErrVaultNotFound) {
 			log.Printf("[VaultHandler:GetMetadata] Метаданные не найдены для пользователя %d", userID)
-			http.Error(w, "Хранилище не найдено", http.StatusNotFound)
+			middleware.WriteError(w, r, http.StatusNotFound, models.ErrCodeVaultNotFound, "Хранилище не найдено")
 		} else {
 			log.Printf("[VaultHandler:GetMetadata] Внутренняя ошибка "+
 				"при получении метаданных для пользователя %d: %v", userID, err)
-			http.Error(w, "Внутренняя ошибка сервера", http.StatusInternalServerError)
+			middleware.WriteError(w, r, http.StatusInternalServerError, models.ErrCodeInternal, "Внутренняя ошибка сервера")
 		}
 		return
 	}
@@ -63,17 +75,18 @@ func (h *VaultHandler) Upload(w http.ResponseWriter, r *http.Request) {
 	userID, ok := middleware.GetUserIDFromContext(r.Context())
 	if !ok {
 		log.Printf("[VaultHandler:Upload] Не удалось получить userID из контекста")
-		http.Error(w, "Внутренняя ошибка сервера", http.StatusInternalServerError)
+		middleware.WriteError(w, r, http.StatusInternalServerError, models.ErrCodeInternal, "Внутренняя ошибка сервера")
 		return
 	}
 
 	log.Printf("[VaultHandler:Upload] Запрос на загрузку файла от пользователя %d", userID)
 
 	// === Чтение заголовка X-Kdbx-Content-Modified-At ===
-	contentModTimeStr := r.Header.Get("X-Kdbx-Content-Modified-At")
+	contentModTimeStr := r.Header.Get(contentModifiedAtHeader)
 	if contentModTimeStr == "" {
 		log.Printf("[VaultHandler:Upload] Отсутствует обязательный заголовок X-Kdbx-Content-Modified-At")
-		http.Error(w, "Отсутствует обязательный заголовок X-Kdbx-Content-Modified-At", http.StatusBadRequest)
+		middleware.WriteErrorDetails(w, r, http.StatusBadRequest, models.ErrCodeInvalidRequest,
+			"Отсутствует обязательный заголовок X-Kdbx-Content-Modified-At", map[string]any{"header": contentModifiedAtHeader})
 		return
 	}
 
@@ -81,7 +94,9 @@ func (h *VaultHandler) Upload(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		log.Printf("[VaultHandler:Upload] Ошибка парсинга заголовка "+
 			"X-Kdbx-Content-Modified-At ('%s'): %v", contentModTimeStr, err)
-		http.Error(w, "Неверный формат заголовка X-Kdbx-Content-Modified-At (ожидается RFC3339)", http.StatusBadRequest)
+		middleware.WriteErrorDetails(w, r, http.StatusBadRequest, models.ErrCodeInvalidRequest,
+			"Неверный формат заголовка X-Kdbx-Content-Modified-At (ожидается RFC3339)",
+			map[string]any{"header": contentModifiedAtHeader})
 		return
 	}
 	// ===================================================
@@ -91,7 +106,8 @@ func (h *VaultHandler) Upload(w http.ResponseWriter, r *http.Request) {
 	size, err := strconv.ParseInt(sizeStr, 10, 64)
 	if err != nil || size <= 0 {
 		log.Printf("[VaultHandler:Upload] Неверный или отсутствующий заголовок Content-Length: %s", sizeStr)
-		http.Error(w, "Неверный или отсутствующий заголовок Content-Length", http.StatusBadRequest)
+		middleware.WriteErrorDetails(w, r, http.StatusBadRequest, models.ErrCodeInvalidRequest,
+			"Неверный или отсутствующий заголовок Content-Length", map[string]any{"header": "Content-Length"})
 		return
 	}
 
@@ -101,9 +117,18 @@ func (h *VaultHandler) Upload(w http.ResponseWriter, r *http.Request) {
 		// По умолчанию считаем бинарным потоком
 		contentType = "application/octet-stream"
 	}
+	if !isVaultContentType(contentType) {
+		log.Printf("[VaultHandler:Upload] Неподдерживаемый Content-Type '%s' от пользователя %d", contentType, userID)
+		middleware.WriteErrorDetails(w, r, http.StatusUnsupportedMediaType, models.ErrCodeUnsupportedMedia,
+			"Неподдерживаемый Content-Type: ожидается application/octet-stream",
+			map[string]any{"header": "Content-Type"})
+		return
+	}
 
 	// Вызываем сервис для загрузки файла, передавая contentModTime
-	err = h.vaultService.UploadVault(userID, r.Body, size, contentType, contentModTime)
+	version, err := h.vaultService.UploadVault(
+		r.Context(), userID, r.Body, size, contentType, contentModTime, requestOrigin(r),
+	)
 	if err != nil {
 		// Обработка ошибок сервиса
 		if errors.Is(err, services.ErrConflictVersion) {
@@ -111,43 +136,100 @@ func (h *VaultHandler) Upload(w http.ResponseWriter, r *http.Request) {
 			// Формируем строку ошибки для переноса
 			conflictMsg := "Конфликт версий: на сервере уже есть более новая " +
 				"или идентичная версия с другим содержимым."
-			http.Error(w, conflictMsg, http.StatusConflict)
+			middleware.WriteError(w, r, http.StatusConflict, models.ErrCodeVersionConflict, conflictMsg)
+		} else if errors.Is(err, services.ErrInvalidVaultFile) {
+			// Сервис уже записал причину в журнал
+			middleware.WriteErrorDetails(w, r, http.StatusUnsupportedMediaType, models.ErrCodeUnsupportedMedia,
+				"Файл не является базой KeePass поддерживаемой версии (KDBX 3.x или 4.x)",
+				map[string]any{"reason": err.Error()})
+		} else if errors.Is(err, services.ErrOperationTimeout) {
+			log.Printf("[VaultHandler:Upload] Превышено время загрузки файла для пользователя %d", userID)
+			writeTimeoutError(w, r)
 		} else {
 			// Другие ошибки считаем внутренними
 			log.Printf("[VaultHandler:Upload] Ошибка сервиса при загрузке файла для пользователя %d: %v", userID, err)
-			http.Error(w, "Внутренняя ошибка сервера при загрузке файла", http.StatusInternalServerError)
+			middleware.WriteError(w, r, http.StatusInternalServerError, models.ErrCodeInternal,
+				"Внутренняя ошибка сервера при загрузке файла")
 		}
 		return
 	}
 
-	// Успешный ответ (даже если версия была идентичной и не создавалась новая)
+	// Успешный ответ (даже если версия была идентичной и не создавалась новая).
+	// API v1 отвечает текстом, API v2 - метаданными созданной (или текущей) версии.
+	log.Printf("[VaultHandler:Upload] Файл для пользователя %d успешно загружен (версия %d)", userID, version.ID)
+	if !middleware.IsAPIv2(r) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("Файл успешно загружен\n"))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	_, _ = w.Write([]byte("Файл успешно загружен\n")) // TODO: Возможно, стоит вернуть ID версии как в api.md?
-	log.Printf("[VaultHandler:Upload] Файл для пользователя %d успешно загружен", userID)
+	if err = json.NewEncoder(w).Encode(models.UploadResponse{Version: *version}); err != nil {
+		log.Printf("[VaultHandler:Upload] Ошибка кодирования ответа о загрузке: %v", err)
+	}
 }
 
-// Download обрабатывает GET запрос на скачивание ТЕКУЩЕЙ версии файла хранилища.
+// Download обрабатывает GET и HEAD запросы на скачивание ТЕКУЩЕЙ версии файла хранилища.
+// Поддерживает условные запросы (ETag - SHA256 файла, If-None-Match, If-Modified-Since)
+// и запросы части файла (Range, If-Range) для продолжения прерванного скачивания.
 func (h *VaultHandler) Download(w http.ResponseWriter, r *http.Request) {
 	userID, ok := middleware.GetUserIDFromContext(r.Context())
 	if !ok {
 		log.Printf("[VaultHandler:Download] Не удалось получить userID из контекста")
-		http.Error(w, "Внутренняя ошибка сервера", http.StatusInternalServerError)
+		middleware.WriteError(w, r, http.StatusInternalServerError, models.ErrCodeInternal, "Внутренняя ошибка сервера")
 		return
 	}
 
 	log.Printf("[VaultHandler:Download] Запрос на скачивание файла от пользователя %d", userID)
 
-	// Вызываем сервис для скачивания ТЕКУЩЕЙ версии
-	fileReader, versionMeta, err := h.vaultService.DownloadVault(userID)
+	// Метаданные ТЕКУЩЕЙ версии нужны до открытия файла: по ним проверяются условные запросы
+	versionMeta, err := h.vaultService.GetVaultMetadata(r.Context(), userID)
 	if err != nil {
-		if errors.Is(err, services.ErrVaultNotFound) {
-			log.Printf("[VaultHandler:Download] Хранилище/версия не найдено для пользователя %d", userID)
-			http.Error(w, "Хранилище не найдено", http.StatusNotFound)
-		} else {
-			log.Printf("[VaultHandler:Download] Внутренняя ошибка при скачивании "+
-				"файла для пользователя %d: %v", userID, err)
-			http.Error(w, "Внутренняя ошибка сервера при скачивании файла", http.StatusInternalServerError)
+		h.writeDownloadError(w, r, userID, err)
+		return
+	}
+
+	setDownloadValidators(w.Header(), versionMeta)
+	if isNotModified(r, versionMeta) {
+		log.Printf("[VaultHandler:Download] Версия %d пользователя %d не изменилась", versionMeta.ID, userID)
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	// Устанавливаем заголовки для скачивания файла
+	w.Header().Set("Content-Disposition", `attachment; filename="gophkeeper_vault.kdbx"`)
+	contentType := "application/octet-stream"
+	w.Header().Set("Content-Type", contentType)
+
+	if r.Method == http.MethodHead {
+		if versionMeta.SizeBytes != nil {
+			w.Header().Set("Content-Length", strconv.FormatInt(*versionMeta.SizeBytes, 10))
 		}
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// Запрос части файла (например, для продолжения прерванного скачивания).
+	// Без известного размера файла диапазон не вычислить, поэтому файл отдается целиком.
+	if rangeHeader := r.Header.Get("Range"); rangeHeader != "" && versionMeta.SizeBytes != nil &&
+		rangeApplies(r, versionMeta) {
+		offset, length, rangeErr := parseByteRange(rangeHeader, *versionMeta.SizeBytes)
+		switch {
+		case rangeErr == nil:
+			h.writePartialDownload(w, r, userID, versionMeta, offset, length)
+			return
+		case errors.Is(rangeErr, errRangeNotSatisfiable):
+			w.Header().Set("Content-Range", "bytes */"+strconv.FormatInt(*versionMeta.SizeBytes, 10))
+			middleware.WriteError(w, r, http.StatusRequestedRangeNotSatisfiable, models.ErrCodeRangeNotSatisfiable,
+				"Запрошенный диапазон недостижим")
+			return
+		}
+		log.Printf("[VaultHandler:Download] Заголовок Range '%s' не поддерживается, отдаем файл целиком", rangeHeader)
+	}
+
+	fileReader, err := h.vaultService.DownloadVersion(r.Context(), versionMeta, 0, -1)
+	if err != nil {
+		h.writeDownloadError(w, r, userID, err)
 		return
 	}
 	defer func() {
@@ -156,20 +238,116 @@ func (h *VaultHandler) Download(w http.ResponseWriter, r *http.Request) {
 		}
 	}()
 
-	// Устанавливаем заголовки для скачивания файла
-	w.Header().Set("Content-Disposition", `attachment; filename="gophkeeper_vault.kdbx"`)
-	contentType := "application/octet-stream"
-	w.Header().Set("Content-Type", contentType)
-	if versionMeta.SizeBytes != nil {
-		w.Header().Set("Content-Length", strconv.FormatInt(*versionMeta.SizeBytes, 10))
+	// Небольшие файлы читаем целиком и проверяем контрольную сумму до отправки ответа,
+	// чтобы при повреждении вернуть клиенту ошибку вместо данных.
+	if versionMeta.SizeBytes != nil && *versionMeta.SizeBytes <= maxBufferedDownloadSize {
+		h.writeBufferedDownload(w, r, fileReader, userID, versionMeta)
+		return
 	}
 
-	// Копируем данные из fileReader в ResponseWriter
+	// Большие файлы отдаем потоком, а результат проверки передаем в трейлере.
+	// При несовпадении контрольной суммы соединение обрывается, и клиент не получает
+	// корректного завершения ответа.
+	w.Header().Set("Trailer", IntegrityTrailer)
 	_, err = io.Copy(w, fileReader)
 	if err != nil {
+		if errors.Is(err, services.ErrChecksumMismatch) {
+			log.Printf("[VaultHandler:Download] Контрольная сумма файла пользователя %d (версия %d) не совпала,"+
+				" передача прервана", userID, versionMeta.ID)
+			w.Header().Set(IntegrityTrailer, integrityStatusCorrupted)
+			panic(http.ErrAbortHandler)
+		}
 		log.Printf("[VaultHandler:Download] Ошибка копирования данных файла в ответ для пользователя %d: %v", userID, err)
 		return
 	}
+	w.Header().Set(IntegrityTrailer, integrityStatusOK)
+
+	log.Printf("[VaultHandler:Download] Файл для пользователя %d (версия %d) успешно отправлен", userID, versionMeta.ID)
+}
+
+// writePartialDownload отправляет запрошенную часть файла с кодом 206.
+// Контрольная сумма части не проверяется: клиент сверяет собранный файл с ETag.
+func (h *VaultHandler) writePartialDownload(
+	w http.ResponseWriter,
+	r *http.Request,
+	userID int64,
+	versionMeta *models.VaultVersion,
+	offset, length int64,
+) {
+	fileReader, err := h.vaultService.DownloadVersion(r.Context(), versionMeta, offset, length)
+	if err != nil {
+		h.writeDownloadError(w, r, userID, err)
+		return
+	}
+	defer func() {
+		if closeErr := fileReader.Close(); closeErr != nil {
+			log.Printf("[VaultHandler:Download] Ошибка закрытия fileReader: %v", closeErr)
+		}
+	}()
+
+	w.Header().Set("Content-Range", contentRange(offset, length, *versionMeta.SizeBytes))
+	w.Header().Set("Content-Length", strconv.FormatInt(length, 10))
+	w.WriteHeader(http.StatusPartialContent)
+	if _, err = io.CopyN(w, fileReader, length); err != nil {
+		log.Printf("[VaultHandler:Download] Ошибка отправки части файла пользователя %d: %v", userID, err)
+		return
+	}
+
+	log.Printf("[VaultHandler:Download] Часть файла пользователя %d (версия %d, %s) успешно отправлена",
+		userID, versionMeta.ID, contentRange(offset, length, *versionMeta.SizeBytes))
+}
+
+// writeDownloadError отправляет ответ об ошибке получения версии или файла хранилища.
+func (h *VaultHandler) writeDownloadError(w http.ResponseWriter, r *http.Request, userID int64, err error) {
+	// Заголовки файла не относятся к ответу с ошибкой
+	for _, header := range []string{"ETag", "Last-Modified", "Accept-Ranges", "Cache-Control", "Content-Disposition"} {
+		w.Header().Del(header)
+	}
+	if errors.Is(err, services.ErrVaultNotFound) {
+		log.Printf("[VaultHandler:Download] Хранилище/версия не найдено для пользователя %d", userID)
+		middleware.WriteError(w, r, http.StatusNotFound, models.ErrCodeVaultNotFound, "Хранилище не найдено")
+		return
+	}
+	if errors.Is(err, services.ErrOperationTimeout) {
+		log.Printf("[VaultHandler:Download] Превышено время скачивания файла для пользователя %d", userID)
+		writeTimeoutError(w, r)
+		return
+	}
+	log.Printf("[VaultHandler:Download] Внутренняя ошибка при скачивании "+
+		"файла для пользователя %d: %v", userID, err)
+	middleware.WriteError(w, r, http.StatusInternalServerError, models.ErrCodeInternal,
+		"Внутренняя ошибка сервера при скачивании файла")
+}
+
+// writeBufferedDownload читает файл целиком и отправляет его только после успешной проверки целостности.
+func (h *VaultHandler) writeBufferedDownload(
+	w http.ResponseWriter,
+	r *http.Request,
+	fileReader io.Reader,
+	userID int64,
+	versionMeta *models.VaultVersion,
+) {
+	data, err := io.ReadAll(fileReader)
+	if err != nil {
+		if errors.Is(err, services.ErrChecksumMismatch) {
+			log.Printf("[VaultHandler:Download] Контрольная сумма файла пользователя %d (версия %d) не совпала",
+				userID, versionMeta.ID)
+			middleware.WriteError(w, r, http.StatusInternalServerError, models.ErrCodeVaultCorrupted,
+				"Файл хранилища на сервере поврежден")
+			return
+		}
+		log.Printf("[VaultHandler:Download] Ошибка чтения файла для пользователя %d: %v", userID, err)
+		middleware.WriteError(w, r, http.StatusInternalServerError, models.ErrCodeInternal,
+			"Внутренняя ошибка сервера при скачивании файла")
+		return
+	}
+
+	w.Header().Set("Content-Length", strconv.FormatInt(*versionMeta.SizeBytes, 10))
+	w.Header().Set(IntegrityTrailer, integrityStatusOK)
+	if _, err = w.Write(data); err != nil {
+		log.Printf("[VaultHandler:Download] Ошибка записи данных файла в ответ для пользователя %d: %v", userID, err)
+		return
+	}
 
 	log.Printf("[VaultHandler:Download] Файл для пользователя %d (версия %d) успешно отправлен", userID, versionMeta.ID)
 }
@@ -179,7 +357,7 @@ func (h *VaultHandler) ListVersions(w http.ResponseWriter, r *http.Request) {
 	userID, ok := middleware.GetUserIDFromContext(r.Context())
 	if !ok {
 		log.Printf("[VaultHandler:ListVersions] Не удалось получить userID из контекста")
-		http.Error(w, "Внутренняя ошибка сервера", http.StatusInternalServerError)
+		middleware.WriteError(w, r, http.StatusInternalServerError, models.ErrCodeInternal, "Внутренняя ошибка сервера")
 		return
 	}
 
@@ -198,17 +376,17 @@ func (h *VaultHandler) ListVersions(w http.ResponseWriter, r *http.Request) {
 	log.Printf("[VaultHandler:ListVersions] Запрос списка версий от пользователя %d "+
 		"(limit=%d, offset=%d)", userID, limit, offset)
 
-	versions, err := h.vaultService.ListVersions(userID, limit, offset)
+	versions, err := h.vaultService.ListVersions(r.Context(), userID, limit, offset)
 	if err != nil {
 		log.Printf("[VaultHandler:ListVersions] Внутренняя ошибка при получении "+
 			"списка версий для пользователя %d: %v", userID, err)
-		http.Error(w, "Внутренняя ошибка сервера", http.StatusInternalServerError)
+		middleware.WriteError(w, r, http.StatusInternalServerError, models.ErrCodeInternal, "Внутренняя ошибка сервера")
 		return
 	}
 
 	// --- Формирование ответа с учетом current_version_id ---
 	// Получаем ID текущей версии, чтобы добавить его в ответ
-	currentVersionMeta, err := h.vaultService.GetVaultMetadata(userID)
+	currentVersionMeta, err := h.vaultService.GetVaultMetadata(r.Context(), userID)
 	var currentVersionID *int64
 	if err == nil && currentVersionMeta != nil { // Если ошибки нет и метаданные получены
 		cvID := currentVersionMeta.ID // Копируем значение ID
@@ -248,7 +426,7 @@ func (h *VaultHandler) Rollback(w http.ResponseWriter, r *http.Request) {
 	userID, ok := middleware.GetUserIDFromContext(r.Context())
 	if !ok {
 		log.Printf("[VaultHandler:Rollback] Не удалось получить userID из контекста")
-		http.Error(w, "Внутренняя ошибка сервера", http.StatusInternalServerError)
+		middleware.WriteError(w, r, http.StatusInternalServerError, models.ErrCodeInternal, "Внутренняя ошибка сервера")
 		return
 	}
 
@@ -256,30 +434,37 @@ func (h *VaultHandler) Rollback(w http.ResponseWriter, r *http.Request) {
 	var req RollbackRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		log.Printf("[VaultHandler:Rollback] Ошибка декодирования запроса на откат: %v", err)
-		http.Error(w, "Неверный формат запроса", http.StatusBadRequest)
+		middleware.WriteError(w, r, http.StatusBadRequest, models.ErrCodeInvalidRequest, "Неверный формат запроса")
 		return
 	}
 
 	if req.VersionID <= 0 {
-		http.Error(w, "Неверный ID версии", http.StatusBadRequest)
+		middleware.WriteError(w, r, http.StatusBadRequest, models.ErrCodeInvalidRequest, "Неверный ID версии")
 		return
 	}
 
 	log.Printf("[VaultHandler:Rollback] Запрос на откат к версии %d от пользователя %d", req.VersionID, userID)
 
-	err := h.vaultService.RollbackToVersion(userID, req.VersionID)
+	err := h.vaultService.RollbackToVersion(r.Context(), userID, req.VersionID)
 	if err != nil {
 		switch {
 		case errors.Is(err, services.ErrVaultNotFound), errors.Is(err, services.ErrVersionNotFound):
 			log.Printf("[VaultHandler:Rollback] Хранилище/версия %d не найдена для пользователя %d", req.VersionID, userID)
-			http.Error(w, "Указанное хранилище или версия не найдены", http.StatusNotFound)
+			code := models.ErrCodeVersionNotFound
+			if errors.Is(err, services.ErrVaultNotFound) {
+				code = models.ErrCodeVaultNotFound
+			}
+			middleware.WriteError(w, r, http.StatusNotFound, code, "Указанное хранилище или версия не найдены")
 		case errors.Is(err, services.ErrForbidden):
 			log.Printf("[VaultHandler:Rollback] Попытка отката к чужой версии %d пользователем %d", req.VersionID, userID)
-			http.Error(w, "Доступ запрещен", http.StatusForbidden)
+			middleware.WriteError(w, r, http.StatusForbidden, models.ErrCodeForbidden, "Доступ запрещен")
+		case errors.Is(err, services.ErrOperationTimeout):
+			log.Printf("[VaultHandler:Rollback] Превышено время отката к версии %d для пользователя %d", req.VersionID, userID)
+			writeTimeoutError(w, r)
 		default:
 			log.Printf("[VaultHandler:Rollback] Внутренняя ошибка при откате "+
 				"к версии %d для пользователя %d: %v", req.VersionID, userID, err)
-			http.Error(w, "Внутренняя ошибка сервера", http.StatusInternalServerError)
+			middleware.WriteError(w, r, http.StatusInternalServerError, models.ErrCodeInternal, "Внутренняя ошибка сервера")
 		}
 		return
 	}
@@ -287,3 +472,19 @@ func (h *VaultHandler) Rollback(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent) // 204 No Content - успешный откат без тела ответа
 	log.Printf("[VaultHandler:Rollback] Успешный откат к версии %d для пользователя %d", req.VersionID, userID)
 }
+
+// writeTimeoutError отправляет ответ о превышении времени выполнения операции.
+func writeTimeoutError(w http.ResponseWriter, r *http.Request) {
+	middleware.WriteError(w, r, http.StatusServiceUnavailable, models.ErrCodeTimeout,
+		"Превышено время выполнения операции")
+}
+
+// isVaultContentType сообщает, что Content-Type загрузки допустим для файла KDBX:
+// двоичный поток или тип, зарегистрированный KeePass.
+func isVaultContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/octet-stream" || mediaType == "application/x-keepass2"
+}
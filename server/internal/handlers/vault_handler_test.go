@@ -27,7 +27,7 @@ type MockVaultService struct {
 	mock.Mock
 }
 
-func (m *MockVaultService) GetVaultMetadata(userID int64) (*models.VaultVersion, error) {
+func (m *MockVaultService) GetVaultMetadata(_ context.Context, userID int64) (*models.VaultVersion, error) {
 	args := m.Called(userID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -36,19 +36,24 @@ func (m *MockVaultService) GetVaultMetadata(userID int64) (*models.VaultVersion,
 }
 
 func (m *MockVaultService) UploadVault(
+	_ context.Context,
 	userID int64,
 	reader io.Reader,
 	size int64,
 	contentType string,
 	contentModifiedAt time.Time,
-) error {
-	args := m.Called(userID, reader, size, contentType, contentModifiedAt)
+	origin models.UploadOrigin,
+) (*models.VaultVersion, error) {
+	args := m.Called(userID, reader, size, contentType, contentModifiedAt, origin)
 	// Consume the reader to simulate reading the body
 	_, _ = io.Copy(io.Discard, reader)
-	return args.Error(0)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.VaultVersion), args.Error(1) //nolint:errcheck // Acceptable for mocks
 }
 
-func (m *MockVaultService) DownloadVault(userID int64) (io.ReadCloser, *models.VaultVersion, error) {
+func (m *MockVaultService) DownloadVault(_ context.Context, userID int64) (io.ReadCloser, *models.VaultVersion, error) {
 	args := m.Called(userID)
 	if args.Get(0) == nil {
 		return nil, nil, args.Error(2)
@@ -64,7 +69,23 @@ func (m *MockVaultService) DownloadVault(userID int64) (io.ReadCloser, *models.V
 	return reader, meta, args.Error(2)
 }
 
-func (m *MockVaultService) ListVersions(userID int64, limit, offset int) ([]models.VaultVersion, error) {
+func (m *MockVaultService) DownloadVersion(
+	_ context.Context,
+	version *models.VaultVersion,
+	offset, length int64,
+) (io.ReadCloser, error) {
+	args := m.Called(version, offset, length)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(io.ReadCloser), args.Error(1) //nolint:errcheck // Acceptable for mocks
+}
+
+func (m *MockVaultService) ListVersions(
+	_ context.Context,
+	userID int64,
+	limit, offset int,
+) ([]models.VaultVersion, error) {
 	args := m.Called(userID, limit, offset)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -72,7 +93,7 @@ func (m *MockVaultService) ListVersions(userID int64, limit, offset int) ([]mode
 	return args.Get(0).([]models.VaultVersion), args.Error(1) //nolint:errcheck // Acceptable for mocks
 }
 
-func (m *MockVaultService) RollbackToVersion(userID, versionID int64) error {
+func (m *MockVaultService) RollbackToVersion(_ context.Context, userID, versionID int64) error {
 	args := m.Called(userID, versionID)
 	return args.Error(0)
 }
@@ -198,12 +219,23 @@ func TestVaultHandler_Upload(t *testing.T) {
 				"Content-Length":             strconv.FormatInt(testFileSize, 10),
 				"Content-Type":               testContentType,
 				"X-Kdbx-Content-Modified-At": testModTimeStr,
+				"X-Client-Name":              "gophkeeper-client",
+				"X-Client-Version":           "v1.2.0",
+				"X-Device-ID":                "5f2c9a0e7b3d41e8",
 			},
 			mockReturnErr:      nil,
 			expectedStatusCode: http.StatusOK,
 			expectedBody:       "Файл успешно загружен\n",
 			setupMock: func(mockSvc *MockVaultService) {
-				mockSvc.On("UploadVault", testUserID, mock.Anything, testFileSize, testContentType, testModTime).Return(nil)
+				// Сведения о клиенте берутся из заголовков, IP - из адреса запроса httptest
+				origin := models.UploadOrigin{
+					ClientName:    "gophkeeper-client",
+					ClientVersion: "v1.2.0",
+					DeviceID:      "5f2c9a0e7b3d41e8",
+					IPAddress:     "192.0.2.1",
+				}
+				mockSvc.On("UploadVault", testUserID, mock.Anything, testFileSize, testContentType, testModTime, origin).
+					Return(&models.VaultVersion{ID: 101}, nil)
 			},
 		},
 		{
@@ -269,6 +301,53 @@ func TestVaultHandler_Upload(t *testing.T) {
 			expectedBody:       "Неверный формат заголовка X-Kdbx-Content-Modified-At (ожидается RFC3339)\n",
 			setupMock:          func(_ *MockVaultService) { /* No service call expected */ },
 		},
+		{
+			name: "Unsupported Content-Type",
+			body: strings.NewReader(string(make([]byte, testFileSize))),
+			headers: map[string]string{
+				"Content-Length":             strconv.FormatInt(testFileSize, 10),
+				"Content-Type":               "application/x-www-form-urlencoded",
+				"X-Kdbx-Content-Modified-At": testModTimeStr,
+			},
+			mockReturnErr:      nil, // Service not called
+			expectedStatusCode: http.StatusUnsupportedMediaType,
+			expectedBody:       "Неподдерживаемый Content-Type: ожидается application/octet-stream\n",
+			setupMock:          func(_ *MockVaultService) { /* No service call expected */ },
+		},
+		{
+			name: "Not a KDBX File",
+			body: strings.NewReader(string(make([]byte, testFileSize))),
+			headers: map[string]string{
+				"Content-Length":             strconv.FormatInt(testFileSize, 10),
+				"Content-Type":               testContentType,
+				"X-Kdbx-Content-Modified-At": testModTimeStr,
+			},
+			mockReturnErr:      services.ErrInvalidVaultFile,
+			expectedStatusCode: http.StatusUnsupportedMediaType,
+			expectedBody:       "Файл не является базой KeePass поддерживаемой версии (KDBX 3.x или 4.x)\n",
+			setupMock: func(mockSvc *MockVaultService) {
+				mockSvc.On("UploadVault", testUserID, mock.Anything, testFileSize, testContentType, testModTime,
+					mock.Anything).
+					Return(nil, services.ErrInvalidVaultFile)
+			},
+		},
+		{
+			name: "Operation Timeout",
+			body: strings.NewReader(string(make([]byte, testFileSize))),
+			headers: map[string]string{
+				"Content-Length":             strconv.FormatInt(testFileSize, 10),
+				"Content-Type":               testContentType,
+				"X-Kdbx-Content-Modified-At": testModTimeStr,
+			},
+			mockReturnErr:      services.ErrOperationTimeout,
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedBody:       "Превышено время выполнения операции\n",
+			setupMock: func(mockSvc *MockVaultService) {
+				mockSvc.On("UploadVault", testUserID, mock.Anything, testFileSize, testContentType, testModTime,
+					mock.Anything).
+					Return(nil, services.ErrOperationTimeout)
+			},
+		},
 		{
 			name: "Internal Service Error",
 			body: strings.NewReader(string(make([]byte, testFileSize))),
@@ -281,8 +360,9 @@ func TestVaultHandler_Upload(t *testing.T) {
 			expectedStatusCode: http.StatusInternalServerError,
 			expectedBody:       "Внутренняя ошибка сервера при загрузке файла\n",
 			setupMock: func(mockSvc *MockVaultService) {
-				mockSvc.On("UploadVault", testUserID, mock.Anything, testFileSize, testContentType, testModTime).
-					Return(errors.New("service upload error"))
+				mockSvc.On("UploadVault", testUserID, mock.Anything, testFileSize, testContentType, testModTime,
+					mock.Anything).
+					Return(nil, errors.New("service upload error"))
 			},
 		},
 	}
@@ -315,6 +395,7 @@ func TestVaultHandler_Upload(t *testing.T) {
 						testFileSize,
 						"application/octet-stream",
 						testModTime,
+						mock.Anything,
 					).Maybe()
 				}
 			}
@@ -355,6 +436,7 @@ func TestVaultHandler_Upload(t *testing.T) {
 			mock.Anything, // size
 			mock.Anything, // contentType
 			mock.Anything, // contentModifiedAt
+			mock.Anything, // origin
 		)
 	})
 }
@@ -405,7 +487,8 @@ func TestVaultHandler_Download(t *testing.T) {
 					SizeBytes: &testFileSize,
 					CreatedAt: testCreatedAt,
 				}
-				mockSvc.On("DownloadVault", testUserID).Return(mockReader, mockMeta, nil)
+				mockSvc.On("GetVaultMetadata", testUserID).Return(mockMeta, nil)
+				mockSvc.On("DownloadVersion", mockMeta, int64(0), int64(-1)).Return(mockReader, nil)
 			},
 		},
 		{
@@ -417,7 +500,7 @@ func TestVaultHandler_Download(t *testing.T) {
 			expectedHeaders:    map[string]string{}, // No specific headers expected on error
 			expectedBody:       "Хранилище не найдено\n",
 			setupMock: func(mockSvc *MockVaultService) {
-				mockSvc.On("DownloadVault", testUserID).Return(nil, nil, services.ErrVaultNotFound)
+				mockSvc.On("GetVaultMetadata", testUserID).Return(nil, services.ErrVaultNotFound)
 			},
 		},
 		{
@@ -429,7 +512,10 @@ func TestVaultHandler_Download(t *testing.T) {
 			expectedHeaders:    map[string]string{}, // No specific headers expected on error
 			expectedBody:       "Внутренняя ошибка сервера при скачивании файла\n",
 			setupMock: func(mockSvc *MockVaultService) {
-				mockSvc.On("DownloadVault", testUserID).Return(nil, nil, errors.New("internal download error"))
+				mockMeta := &models.VaultVersion{ID: testVersionID, VaultID: testVaultID, ObjectKey: testObjectKey}
+				mockSvc.On("GetVaultMetadata", testUserID).Return(mockMeta, nil)
+				mockSvc.On("DownloadVersion", mockMeta, int64(0), int64(-1)).
+					Return(nil, errors.New("internal download error"))
 			},
 		},
 	}
@@ -477,8 +563,232 @@ func TestVaultHandler_Download(t *testing.T) {
 
 		assert.Equal(t, http.StatusInternalServerError, rr.Code)
 		assert.Equal(t, "Внутренняя ошибка сервера\n", rr.Body.String())
-		mockService.AssertNotCalled(t, "DownloadVault", mock.Anything)
+		mockService.AssertNotCalled(t, "GetVaultMetadata", mock.Anything)
+	})
+}
+
+// failingReader отдает данные, а в конце вместо io.EOF возвращает заданную ошибку.
+type failingReader struct {
+	data []byte
+	err  error
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, r.err
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+func TestVaultHandler_DownloadIntegrity(t *testing.T) {
+	testUserID := int64(1)
+
+	serveDownload := func(reader io.ReadCloser, size int64) *httptest.ResponseRecorder {
+		mockService := new(MockVaultService)
+		meta := &models.VaultVersion{ID: 7, VaultID: 1, ObjectKey: "user_1/vault.kdbx", SizeBytes: &size}
+		mockService.On("GetVaultMetadata", testUserID).Return(meta, nil)
+		mockService.On("DownloadVersion", meta, int64(0), int64(-1)).Return(reader, nil)
+		handler := handlers.NewVaultHandler(mockService)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/vault/download", nil)
+		req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, testUserID))
+		rr := httptest.NewRecorder()
+		handler.Download(rr, req)
+		return rr
+	}
+
+	t.Run("Небольшой поврежденный файл не отправляется клиенту", func(t *testing.T) {
+		reader := io.NopCloser(&failingReader{data: []byte("corrupted"), err: services.ErrChecksumMismatch})
+		rr := serveDownload(reader, 9)
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		assert.Equal(t, "Файл хранилища на сервере поврежден\n", rr.Body.String())
 	})
+
+	t.Run("Небольшой целый файл отправляется с отметкой проверки", func(t *testing.T) {
+		rr := serveDownload(io.NopCloser(strings.NewReader("content")), 7)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "content", rr.Body.String())
+		assert.Equal(t, "ok", rr.Header().Get(handlers.IntegrityTrailer))
+	})
+
+	t.Run("Большой целый файл отдается потоком с трейлером", func(t *testing.T) {
+		rr := serveDownload(io.NopCloser(strings.NewReader("content")), 64<<20)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "content", rr.Body.String())
+		assert.Empty(t, rr.Header().Get("Content-Length"))
+		assert.Equal(t, "ok", rr.Result().Trailer.Get(handlers.IntegrityTrailer))
+	})
+
+	t.Run("Большой поврежденный файл обрывает соединение", func(t *testing.T) {
+		reader := io.NopCloser(&failingReader{data: []byte("corrupted"), err: services.ErrChecksumMismatch})
+
+		assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+			serveDownload(reader, 64<<20)
+		})
+	})
+}
+
+func TestVaultHandler_DownloadConditional(t *testing.T) {
+	testUserID := int64(1)
+	content := "0123456789"
+	size := int64(len(content))
+	checksum := "3a46a5b1d5c0fb6c1e6c5a8e1d3f2b0c9e8d7c6b5a4f3e2d1c0b9a8f7e6d5c4b"
+	etag := `"` + checksum + `"`
+	createdAt := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	meta := &models.VaultVersion{ID: 7, VaultID: 1, ObjectKey: "user_1/vault.kdbx",
+		SizeBytes: &size, Checksum: &checksum, CreatedAt: createdAt}
+
+	tests := []struct {
+		name            string
+		method          string
+		headers         map[string]string
+		setupMock       func(m *MockVaultService)
+		expectedStatus  int
+		expectedBody    string
+		expectedHeaders map[string]string
+	}{
+		{
+			name:   "Полный ответ содержит валидаторы",
+			method: http.MethodGet,
+			setupMock: func(m *MockVaultService) {
+				m.On("DownloadVersion", meta, int64(0), int64(-1)).Return(nopReader(content), nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   content,
+			expectedHeaders: map[string]string{
+				"ETag":          etag,
+				"Last-Modified": "Thu, 01 May 2025 12:00:00 GMT",
+				"Accept-Ranges": "bytes",
+			},
+		},
+		{
+			name:            "If-None-Match с текущим ETag",
+			method:          http.MethodGet,
+			headers:         map[string]string{"If-None-Match": `"other", ` + etag},
+			setupMock:       func(_ *MockVaultService) {},
+			expectedStatus:  http.StatusNotModified,
+			expectedHeaders: map[string]string{"ETag": etag},
+		},
+		{
+			name:    "If-None-Match с устаревшим ETag",
+			method:  http.MethodGet,
+			headers: map[string]string{"If-None-Match": `"other"`},
+			setupMock: func(m *MockVaultService) {
+				m.On("DownloadVersion", meta, int64(0), int64(-1)).Return(nopReader(content), nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   content,
+		},
+		{
+			name:           "If-Modified-Since не раньше создания версии",
+			method:         http.MethodGet,
+			headers:        map[string]string{"If-Modified-Since": "Thu, 01 May 2025 12:00:00 GMT"},
+			setupMock:      func(_ *MockVaultService) {},
+			expectedStatus: http.StatusNotModified,
+		},
+		{
+			name:            "HEAD без тела",
+			method:          http.MethodHead,
+			setupMock:       func(_ *MockVaultService) {},
+			expectedStatus:  http.StatusOK,
+			expectedHeaders: map[string]string{"Content-Length": "10", "ETag": etag},
+		},
+		{
+			name:    "Диапазон байт",
+			method:  http.MethodGet,
+			headers: map[string]string{"Range": "bytes=2-5"},
+			setupMock: func(m *MockVaultService) {
+				m.On("DownloadVersion", meta, int64(2), int64(4)).Return(nopReader(content[2:6]), nil)
+			},
+			expectedStatus:  http.StatusPartialContent,
+			expectedBody:    "2345",
+			expectedHeaders: map[string]string{"Content-Range": "bytes 2-5/10", "Content-Length": "4"},
+		},
+		{
+			name:    "Продолжение скачивания с If-Range",
+			method:  http.MethodGet,
+			headers: map[string]string{"Range": "bytes=6-", "If-Range": etag},
+			setupMock: func(m *MockVaultService) {
+				m.On("DownloadVersion", meta, int64(6), int64(4)).Return(nopReader(content[6:]), nil)
+			},
+			expectedStatus:  http.StatusPartialContent,
+			expectedBody:    "6789",
+			expectedHeaders: map[string]string{"Content-Range": "bytes 6-9/10"},
+		},
+		{
+			name:    "Суффиксный диапазон",
+			method:  http.MethodGet,
+			headers: map[string]string{"Range": "bytes=-3"},
+			setupMock: func(m *MockVaultService) {
+				m.On("DownloadVersion", meta, int64(7), int64(3)).Return(nopReader(content[7:]), nil)
+			},
+			expectedStatus:  http.StatusPartialContent,
+			expectedBody:    "789",
+			expectedHeaders: map[string]string{"Content-Range": "bytes 7-9/10"},
+		},
+		{
+			name:            "Диапазон за пределами файла",
+			method:          http.MethodGet,
+			headers:         map[string]string{"Range": "bytes=10-"},
+			setupMock:       func(_ *MockVaultService) {},
+			expectedStatus:  http.StatusRequestedRangeNotSatisfiable,
+			expectedBody:    "Запрошенный диапазон недостижим\n",
+			expectedHeaders: map[string]string{"Content-Range": "bytes */10"},
+		},
+		{
+			name:    "If-Range с устаревшим ETag отдает файл целиком",
+			method:  http.MethodGet,
+			headers: map[string]string{"Range": "bytes=6-", "If-Range": `"other"`},
+			setupMock: func(m *MockVaultService) {
+				m.On("DownloadVersion", meta, int64(0), int64(-1)).Return(nopReader(content), nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   content,
+		},
+		{
+			name:    "Несколько диапазонов игнорируются",
+			method:  http.MethodGet,
+			headers: map[string]string{"Range": "bytes=0-1,4-5"},
+			setupMock: func(m *MockVaultService) {
+				m.On("DownloadVersion", meta, int64(0), int64(-1)).Return(nopReader(content), nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   content,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockVaultService)
+			mockService.On("GetVaultMetadata", testUserID).Return(meta, nil)
+			tt.setupMock(mockService)
+
+			req := httptest.NewRequest(tt.method, "/api/vault/download", nil)
+			req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, testUserID))
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			rr := httptest.NewRecorder()
+			handlers.NewVaultHandler(mockService).Download(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.Equal(t, tt.expectedBody, rr.Body.String())
+			for key, value := range tt.expectedHeaders {
+				assert.Equal(t, value, rr.Header().Get(key), "Заголовок %s", key)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+// nopReader возвращает io.ReadCloser со строкой.
+func nopReader(s string) io.ReadCloser {
+	return io.NopCloser(strings.NewReader(s))
 }
 
 func TestVaultHandler_ListVersions(t *testing.T) {
@@ -802,3 +1112,102 @@ func TestVaultHandler_Rollback(t *testing.T) {
 		mockService.AssertNotCalled(t, "RollbackToVersion", mock.Anything, mock.Anything)
 	})
 }
+
+func TestVaultHandler_APIv2(t *testing.T) {
+	testUserID := int64(1)
+	testModTime := time.Now().UTC().Truncate(time.Second)
+
+	// serveV2 выполняет запрос к обработчику API v2 от имени тестового пользователя.
+	serveV2 := func(method, pattern string, h http.HandlerFunc, req *http.Request) *httptest.ResponseRecorder {
+		req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, testUserID))
+		rr := httptest.NewRecorder()
+		router := chi.NewRouter()
+		router.Use(middleware.APIv2)
+		router.Method(method, pattern, h)
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("Загрузка возвращает метаданные созданной версии", func(t *testing.T) {
+		mockService := new(MockVaultService)
+		handler := handlers.NewVaultHandler(mockService)
+		checksum := "abc"
+		created := &models.VaultVersion{ID: 101, VaultID: 10, Checksum: &checksum, ContentModifiedAt: &testModTime}
+		mockService.On("UploadVault", testUserID, mock.Anything, int64(4), "application/octet-stream", testModTime,
+			mock.Anything).
+			Return(created, nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v2/vault/upload", strings.NewReader("kdbx"))
+		req.Header.Set("Content-Length", "4")
+		req.Header.Set("X-Kdbx-Content-Modified-At", testModTime.Format(time.RFC3339))
+		rr := serveV2(http.MethodPost, "/api/v2/vault/upload", handler.Upload, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var resp models.UploadResponse
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, int64(101), resp.Version.ID)
+		assert.Equal(t, &checksum, resp.Version.Checksum)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Конфликт версий возвращает код version_conflict", func(t *testing.T) {
+		mockService := new(MockVaultService)
+		handler := handlers.NewVaultHandler(mockService)
+		mockService.On("UploadVault", testUserID, mock.Anything, int64(4), "application/octet-stream", testModTime,
+			mock.Anything).
+			Return(nil, services.ErrConflictVersion)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v2/vault/upload", strings.NewReader("kdbx"))
+		req.Header.Set("Content-Length", "4")
+		req.Header.Set("X-Kdbx-Content-Modified-At", testModTime.Format(time.RFC3339))
+		rr := serveV2(http.MethodPost, "/api/v2/vault/upload", handler.Upload, req)
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+		var resp models.ErrorResponse
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, models.ErrCodeVersionConflict, resp.Code)
+	})
+
+	t.Run("Неверный заголовок указывается в details", func(t *testing.T) {
+		handler := handlers.NewVaultHandler(new(MockVaultService))
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v2/vault/upload", strings.NewReader("kdbx"))
+		req.Header.Set("Content-Length", "4")
+		rr := serveV2(http.MethodPost, "/api/v2/vault/upload", handler.Upload, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		var resp models.ErrorResponse
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, models.ErrCodeInvalidRequest, resp.Code)
+		assert.Equal(t, map[string]any{"header": "X-Kdbx-Content-Modified-At"}, resp.Details)
+	})
+
+	t.Run("Отсутствие хранилища возвращает код vault_not_found", func(t *testing.T) {
+		mockService := new(MockVaultService)
+		handler := handlers.NewVaultHandler(mockService)
+		mockService.On("GetVaultMetadata", testUserID).Return(nil, services.ErrVaultNotFound)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v2/vault", nil)
+		rr := serveV2(http.MethodGet, "/api/v2/vault", handler.GetMetadata, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		var resp models.ErrorResponse
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, models.ErrCodeVaultNotFound, resp.Code)
+		assert.Equal(t, "Хранилище не найдено", resp.Message)
+	})
+
+	t.Run("Откат к несуществующей версии возвращает код version_not_found", func(t *testing.T) {
+		mockService := new(MockVaultService)
+		handler := handlers.NewVaultHandler(mockService)
+		mockService.On("RollbackToVersion", testUserID, int64(5)).Return(services.ErrVersionNotFound)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v2/vault/rollback", strings.NewReader(`{"version_id": 5}`))
+		rr := serveV2(http.MethodPost, "/api/v2/vault/rollback", handler.Rollback, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		var resp models.ErrorResponse
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, models.ErrCodeVersionNotFound, resp.Code)
+	})
+}
@@ -0,0 +1,951 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+
+	"github.com/maynagashev/gophkeeper/models"
+	"github.com/maynagashev/gophkeeper/server/internal/middleware"
+	"github.com/maynagashev/gophkeeper/server/internal/services"
+)
+
+const (
+	// WebDAVVaultFile - имя файла текущей версии хранилища в каталоге WebDAV пользователя.
+	WebDAVVaultFile = "vault.kdbx"
+	// WebDAVMaxBufferedSize - максимальный размер тела запроса, которое читается в память: временного файла
+	// или vault.kdbx без Content-Length. Ограничение действует независимо от limits.max_upload_size
+	// (меньший лимит загрузки тоже действует).
+	WebDAVMaxBufferedSize = 64 << 20
+
+	webdavRealm      = "GophKeeper"
+	webdavClientName = "webdav"
+	webdavAllow      = "OPTIONS, GET, HEAD, PUT, DELETE, PROPFIND, MOVE, LOCK, UNLOCK"
+	webdavLockTTL    = "Second-600"
+	// webdavTempTTL - время хранения временных файлов, которые клиенты записывают перед переименованием в vault.kdbx.
+	webdavTempTTL = 10 * time.Minute
+	// webdavMaxTempFiles - максимальное число временных файлов одного пользователя.
+	webdavMaxTempFiles = 4
+	// webdavMaxTempBytes - общий объем временных файлов всех пользователей в памяти сервера.
+	webdavMaxTempBytes = 256 << 20
+	// webdavSweepInterval - как часто удаляются временные файлы и проверенные пароли с истекшим сроком.
+	webdavSweepInterval = time.Minute
+	// webdavCredentialsTTL - сколько действует успешная проверка пароля Basic. Клиенты WebDAV передают
+	// пароль с каждым запросом, а проверка хеша bcrypt дорогая, поэтому результат ненадолго запоминается.
+	webdavCredentialsTTL = time.Minute
+	// webdavBaseTTL - сколько помнится версия vault.kdbx, которую скачал клиент. Клиенты KeePass держат
+	// базу открытой часами и сохраняют ее без повторного скачивания.
+	webdavBaseTTL = 24 * time.Hour
+	// webdavMaxClients - сколько клиентов (User-Agent) одного пользователя помнится, лишние вытесняют старые.
+	webdavMaxClients = 16
+)
+
+// WebDAVMethods возвращает методы WebDAV, которые нужно зарегистрировать в роутере помимо стандартных методов HTTP.
+func WebDAVMethods() []string {
+	return []string{"PROPFIND", "MOVE", "LOCK", "UNLOCK"}
+}
+
+// webdavTempFile - временный файл в каталоге WebDAV (хранится в памяти до переименования или истечения срока).
+type webdavTempFile struct {
+	data     []byte
+	modified time.Time
+}
+
+// webdavBase - версия vault.kdbx, которую клиент последней скачал или записал сам.
+type webdavBase struct {
+	etag string
+	seen time.Time
+}
+
+// webdavCredential - недавно проверенный пароль Basic.
+type webdavCredential struct {
+	userID  int64
+	expires time.Time
+}
+
+// WebDAVHandler предоставляет текущую версию хранилища пользователя как файл vault.kdbx по WebDAV,
+// чтобы синхронизировать его стандартными клиентами KeePass (KeePass 2, KeePassDX, KeeWeb и др.).
+// Аутентификация - HTTP Basic (имя и пароль пользователя) или Bearer JWT.
+// Каждая запись vault.kdbx (PUT или MOVE временного файла) выполняется через VaultService.UploadVault
+// и создает версию по тем же правилам разрешения конфликтов, что и API. Клиенты KeePass не передают
+// If-Match, поэтому обработчик помнит версию, которую клиент скачал, и отклоняет запись поверх более новой.
+// Блокировки LOCK не удерживаются: клиенты получают токен, а одновременную запись разрешают правила конфликтов.
+type WebDAVHandler struct {
+	vaultService services.VaultService
+	authService  services.AuthService
+	vault        *VaultHandler // Отдает vault.kdbx так же, как /api/vault/download
+	jwtSecret    []byte
+	accounts     middleware.AccountLookup
+	prefix       string // Путь каталога WebDAV, например /dav
+
+	mu        sync.Mutex
+	temps     map[int64]map[string]webdavTempFile
+	tempBytes int // Общий размер временных файлов в temps
+	// deleted - пользователи, которые удалили vault.kdbx перед переименованием в него временного файла.
+	// Пока переименование не выполнено, vault.kdbx для них не показывается.
+	deleted map[int64]bool
+	// bases - версии vault.kdbx, на которых основаны копии клиентов, по пользователю и User-Agent.
+	bases map[int64]map[string]webdavBase
+
+	credMu sync.Mutex
+	// credentials - проверенные пароли по HMAC имени и пароля (сами пароли в памяти не хранятся).
+	credentials map[[sha256.Size]byte]webdavCredential
+	credKey     []byte // Случайный ключ HMAC, свой у каждого запуска сервера
+}
+
+// NewWebDAVHandler создает обработчик WebDAV для каталога prefix.
+// JWT проверяются ключом jwtSecret (тем же, которым сервис аутентификации подписывает токены),
+// а учетная запись владельца токена - через accounts.
+func NewWebDAVHandler(
+	vs services.VaultService,
+	as services.AuthService,
+	jwtSecret string,
+	accounts middleware.AccountLookup,
+	prefix string,
+) *WebDAVHandler {
+	return &WebDAVHandler{
+		vaultService: vs,
+		authService:  as,
+		vault:        NewVaultHandler(vs),
+		jwtSecret:    []byte(jwtSecret),
+		accounts:     accounts,
+		prefix:       "/" + strings.Trim(prefix, "/"),
+		temps:        make(map[int64]map[string]webdavTempFile),
+		bases:        make(map[int64]map[string]webdavBase),
+		deleted:      make(map[int64]bool),
+		credentials:  make(map[[sha256.Size]byte]webdavCredential),
+		credKey:      []byte(rand.Text()),
+	}
+}
+
+// ServeHTTP аутентифицирует запрос и выполняет метод WebDAV.
+func (h *WebDAVHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	r, ok := h.authenticate(w, r)
+	if !ok {
+		return
+	}
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+
+	name, ok := h.resourceName(r.URL.Path)
+	if !ok {
+		middleware.WriteError(w, r, http.StatusNotFound, models.ErrCodeNotFound, "Ресурс не найден")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodOptions:
+		w.Header().Set("DAV", "1, 2")
+		w.Header().Set("Allow", webdavAllow)
+		w.Header().Set("MS-Author-Via", "DAV")
+		w.WriteHeader(http.StatusOK)
+	case "PROPFIND":
+		h.propfind(w, r, userID, name)
+	case http.MethodGet, http.MethodHead:
+		h.get(w, r, userID, name)
+	case http.MethodPut:
+		h.put(w, r, userID, name)
+	case http.MethodDelete:
+		h.delete(w, r, userID, name)
+	case "MOVE":
+		h.move(w, r, userID, name)
+	case "LOCK":
+		h.lock(w, r, name)
+	case "UNLOCK":
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", webdavAllow)
+		middleware.WriteError(w, r, http.StatusMethodNotAllowed, models.ErrCodeInvalidRequest, "Метод не поддерживается")
+	}
+}
+
+// authenticate проверяет учетные данные Basic или токен Bearer и возвращает запрос с ID пользователя в контексте.
+func (h *WebDAVHandler) authenticate(w http.ResponseWriter, r *http.Request) (*http.Request, bool) {
+	if username, password, ok := r.BasicAuth(); ok {
+		return h.authenticateBasic(w, r, username, password)
+	}
+	scheme, token, found := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, "bearer") {
+		h.writeChallenge(w, r)
+		return nil, false
+	}
+
+	ctx, err := middleware.AuthenticateToken(r.Context(), strings.TrimSpace(token), h.jwtSecret, h.accounts)
+	switch {
+	case err == nil:
+		return r.WithContext(ctx), true
+	case errors.Is(err, middleware.ErrInvalidToken):
+		h.writeChallenge(w, r)
+	case errors.Is(err, middleware.ErrAccountDisabled):
+		middleware.WriteError(w, r, http.StatusForbidden, models.ErrCodeAccountDisabled, err.Error())
+	default:
+		log.Printf("[WebDAVHandler] Ошибка проверки учетной записи: %v", err)
+		middleware.WriteError(w, r, http.StatusInternalServerError, models.ErrCodeInternal, "Внутренняя ошибка сервера")
+	}
+	return nil, false
+}
+
+// authenticateBasic проверяет имя и пароль через AuthService.VerifyCredentials: действуют те же правила,
+// что и при входе, но токен не выпускается, а запросы не учитываются как входы с устройства.
+// Успешная проверка запоминается на webdavCredentialsTTL; в это время при каждом запросе проверяется
+// только статус учетной записи, поэтому ее отключение действует сразу, а смена пароля - в течение TTL.
+func (h *WebDAVHandler) authenticateBasic(
+	w http.ResponseWriter,
+	r *http.Request,
+	username, password string,
+) (*http.Request, bool) {
+	key := h.credentialKey(username, password)
+	if userID, ok := h.cachedCredential(key); ok {
+		user, err := h.accounts(r.Context(), userID)
+		if err == nil && user.DisabledAt == nil {
+			return r.WithContext(middleware.ContextWithUser(r.Context(), userID, user.Role)), true
+		}
+		// Учетная запись отключена, удалена или недоступна - повторяем полную проверку, она сообщит причину
+		h.forgetCredential(key)
+	}
+
+	user, err := h.authService.VerifyCredentials(r.Context(), username, password)
+	switch {
+	case err == nil:
+	case errors.Is(err, services.ErrInvalidCredentials):
+		log.Printf("[WebDAVHandler] Неверные учетные данные пользователя '%s'", username)
+		h.writeChallenge(w, r)
+		return nil, false
+	case errors.Is(err, services.ErrAccountDisabled):
+		log.Printf("[WebDAVHandler] Учетная запись '%s' отключена", username)
+		middleware.WriteError(w, r, http.StatusForbidden, models.ErrCodeAccountDisabled, err.Error())
+		return nil, false
+	case errors.Is(err, services.ErrOperationTimeout):
+		writeTimeoutError(w, r)
+		return nil, false
+	default:
+		log.Printf("[WebDAVHandler] Ошибка аутентификации пользователя '%s': %v", username, err)
+		middleware.WriteError(w, r, http.StatusInternalServerError, models.ErrCodeInternal, "Внутренняя ошибка сервера")
+		return nil, false
+	}
+
+	h.rememberCredential(key, user.ID)
+	return r.WithContext(middleware.ContextWithUser(r.Context(), user.ID, user.Role)), true
+}
+
+// credentialKey возвращает ключ кэша проверенных паролей для имени и пароля.
+func (h *WebDAVHandler) credentialKey(username, password string) [sha256.Size]byte {
+	mac := hmac.New(sha256.New, h.credKey)
+	mac.Write([]byte(username))
+	mac.Write([]byte{0})
+	mac.Write([]byte(password))
+	var key [sha256.Size]byte
+	copy(key[:], mac.Sum(nil))
+	return key
+}
+
+// cachedCredential возвращает ID пользователя, если пароль недавно проверен.
+func (h *WebDAVHandler) cachedCredential(key [sha256.Size]byte) (int64, bool) {
+	h.credMu.Lock()
+	defer h.credMu.Unlock()
+	cred, ok := h.credentials[key]
+	if !ok || time.Now().After(cred.expires) {
+		return 0, false
+	}
+	return cred.userID, true
+}
+
+// rememberCredential запоминает проверенный пароль (устаревшие записи удаляет SweepExpired).
+func (h *WebDAVHandler) rememberCredential(key [sha256.Size]byte, userID int64) {
+	h.credMu.Lock()
+	defer h.credMu.Unlock()
+	h.credentials[key] = webdavCredential{userID: userID, expires: time.Now().Add(webdavCredentialsTTL)}
+}
+
+// forgetCredential удаляет проверенный пароль из кэша.
+func (h *WebDAVHandler) forgetCredential(key [sha256.Size]byte) {
+	h.credMu.Lock()
+	defer h.credMu.Unlock()
+	delete(h.credentials, key)
+}
+
+// writeChallenge отвечает 401 с предложением аутентификации Basic, по которому клиенты запрашивают пароль.
+func (h *WebDAVHandler) writeChallenge(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("WWW-Authenticate", `Basic realm="`+webdavRealm+`", charset="UTF-8"`)
+	middleware.WriteError(w, r, http.StatusUnauthorized, models.ErrCodeUnauthorized, "Требуется аутентификация")
+}
+
+// resourceName возвращает имя файла в каталоге WebDAV для пути p (пусто - сам каталог).
+// Вложенных каталогов нет, поэтому пути глубже одного уровня не существуют.
+func (h *WebDAVHandler) resourceName(p string) (string, bool) {
+	rel, ok := strings.CutPrefix(p, h.prefix)
+	if !ok || (rel != "" && rel[0] != '/') {
+		return "", false
+	}
+	name := strings.Trim(rel, "/")
+	if strings.Contains(name, "/") || name == "." || name == ".." {
+		return "", false
+	}
+	return name, true
+}
+
+// href возвращает путь файла name (пусто - каталога) для ответов WebDAV.
+func (h *WebDAVHandler) href(name string) string {
+	if name == "" {
+		return h.prefix + "/"
+	}
+	return path.Join(h.prefix, url.PathEscape(name))
+}
+
+// get отдает vault.kdbx (как /api/vault/download) или временный файл.
+func (h *WebDAVHandler) get(w http.ResponseWriter, r *http.Request, userID int64, name string) {
+	switch name {
+	case "":
+		w.Header().Set("Allow", "OPTIONS, PROPFIND")
+		middleware.WriteError(w, r, http.StatusMethodNotAllowed, models.ErrCodeInvalidRequest,
+			"Каталог не скачивается, используйте PROPFIND")
+	case WebDAVVaultFile:
+		if h.vaultDeleted(userID) {
+			middleware.WriteError(w, r, http.StatusNotFound, models.ErrCodeNotFound, "Файл не найден")
+			return
+		}
+		ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		h.vault.Download(ww, r)
+		// Копия клиента теперь основана на отданной версии (304 - на той же, что у него уже есть)
+		switch ww.Status() {
+		case http.StatusOK, http.StatusPartialContent, http.StatusNotModified:
+			if r.Method == http.MethodGet {
+				h.rememberBase(r, userID, w.Header().Get("ETag"))
+			}
+		}
+	default:
+		file, ok := h.tempFile(userID, name)
+		if !ok {
+			middleware.WriteError(w, r, http.StatusNotFound, models.ErrCodeNotFound, "Файл не найден")
+			return
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		http.ServeContent(w, r, name, file.modified, bytes.NewReader(file.data))
+	}
+}
+
+// put записывает vault.kdbx новой версией хранилища или сохраняет временный файл.
+// Для vault.kdbx поддерживаются условия If-Match (ETag текущей версии) и If-None-Match: *,
+// а без них запись проверяется по версии, которую клиент скачал (checkBase).
+func (h *WebDAVHandler) put(w http.ResponseWriter, r *http.Request, userID int64, name string) {
+	if name == "" {
+		middleware.WriteError(w, r, http.StatusMethodNotAllowed, models.ErrCodeInvalidRequest,
+			"Запись в каталог не поддерживается")
+		return
+	}
+	if name != WebDAVVaultFile {
+		h.putTempFile(w, r, userID, name)
+		return
+	}
+
+	if r.Header.Get("If-Match") != "" || r.Header.Get("If-None-Match") != "" {
+		current, err := h.currentVersion(r, userID)
+		if err != nil {
+			h.writeServiceError(w, r, userID, err)
+			return
+		}
+		if !putPreconditionsMet(r, current) {
+			log.Printf("[WebDAVHandler] Условие записи vault.kdbx пользователя %d не выполнено", userID)
+			middleware.WriteError(w, r, http.StatusPreconditionFailed, models.ErrCodeVersionConflict,
+				"Версия хранилища на сервере изменилась")
+			return
+		}
+	} else if !h.checkBase(w, r, userID) {
+		return
+	}
+
+	body, size := io.Reader(r.Body), r.ContentLength
+	if size < 0 {
+		// Тело без Content-Length (chunked) читается целиком: сервису нужен размер файла
+		data, err := readBufferedBody(r)
+		if err != nil {
+			h.writeReadError(w, r, userID, err)
+			return
+		}
+		body, size = bytes.NewReader(data), int64(len(data))
+	}
+	h.upload(w, r, userID, body, size)
+}
+
+// putPreconditionsMet проверяет условия If-Match и If-None-Match относительно текущей версии (nil - хранилища нет).
+func putPreconditionsMet(r *http.Request, current *models.VaultVersion) bool {
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+		if current == nil || !etagListMatches(ifMatch, vaultETag(current)) {
+			return false
+		}
+	}
+	if inm := r.Header.Get("If-None-Match"); inm != "" && current != nil {
+		return !etagListMatches(inm, vaultETag(current))
+	}
+	return true
+}
+
+// checkBase проверяет, что запись без If-Match основана на текущей версии vault.kdbx: если клиент скачивал
+// хранилище, а после этого появилась новая версия (например, загруженная через API), отвечает 412.
+// Клиент, версия которого неизвестна (не скачивал файл или сервер перезапустился), пишет без проверки.
+func (h *WebDAVHandler) checkBase(w http.ResponseWriter, r *http.Request, userID int64) bool {
+	base, ok := h.baseETag(r, userID)
+	if !ok {
+		return true
+	}
+	current, err := h.currentVersion(r, userID)
+	if err != nil {
+		h.writeServiceError(w, r, userID, err)
+		return false
+	}
+	if current != nil && vaultETag(current) != base {
+		log.Printf("[WebDAVHandler] vault.kdbx пользователя %d изменился после скачивания клиентом %q (%s, сейчас %s)",
+			userID, r.UserAgent(), base, vaultETag(current))
+		middleware.WriteError(w, r, http.StatusPreconditionFailed, models.ErrCodeVersionConflict,
+			"Версия хранилища на сервере изменилась, скачайте ее и объедините изменения")
+		return false
+	}
+	return true
+}
+
+// upload создает версию хранилища из body и отвечает 204 с ETag версии.
+// Время изменения содержимого берется из X-Kdbx-Content-Modified-At, а без него (клиенты KeePass
+// его не передают) - время запроса, поэтому устаревшую копию такого клиента отклоняет checkBase, а не сервис.
+func (h *WebDAVHandler) upload(w http.ResponseWriter, r *http.Request, userID int64, body io.Reader, size int64) bool {
+	if size <= 0 {
+		middleware.WriteError(w, r, http.StatusBadRequest, models.ErrCodeInvalidRequest, "Пустой файл хранилища")
+		return false
+	}
+	contentModTime := time.Now().UTC()
+	if header := r.Header.Get(contentModifiedAtHeader); header != "" {
+		parsed, err := time.Parse(time.RFC3339, header)
+		if err != nil {
+			middleware.WriteErrorDetails(w, r, http.StatusBadRequest, models.ErrCodeInvalidRequest,
+				"Неверный формат заголовка X-Kdbx-Content-Modified-At (ожидается RFC3339)",
+				map[string]any{"header": contentModifiedAtHeader})
+			return false
+		}
+		contentModTime = parsed
+	}
+	origin := requestOrigin(r)
+	if origin.ClientName == "" {
+		origin.ClientName, origin.ClientVersion = webdavClientName, r.UserAgent()
+	}
+
+	// Клиенты WebDAV передают разные типы содержимого, формат файла проверяет сервис по заголовку KDBX
+	version, err := h.vaultService.UploadVault(
+		r.Context(), userID, body, size, "application/octet-stream", contentModTime, origin,
+	)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			h.writeReadError(w, r, userID, err)
+			return false
+		}
+		h.writeServiceError(w, r, userID, err)
+		return false
+	}
+
+	log.Printf("[WebDAVHandler] vault.kdbx пользователя %d записан (версия %d)", userID, version.ID)
+	if etag := vaultETag(version); etag != "" {
+		w.Header().Set("ETag", etag)
+		h.rememberBase(r, userID, etag)
+	}
+	h.restoreVault(userID)
+	w.WriteHeader(http.StatusNoContent)
+	return true
+}
+
+// delete удаляет временный файл. vault.kdbx удаляется только в рамках транзакционной записи KeePass
+// (удаление исходного файла перед переименованием временного) и только для клиента: история версий
+// на сервере сохраняется, а до переименования GET и PROPFIND отвечают, что файла нет. Без временного
+// файла удаление vault.kdbx отклоняется, чтобы клиент не считал хранилище удаленным.
+func (h *WebDAVHandler) delete(w http.ResponseWriter, r *http.Request, userID int64, name string) {
+	switch name {
+	case "":
+		middleware.WriteError(w, r, http.StatusForbidden, models.ErrCodeForbidden, "Каталог нельзя удалить")
+	case WebDAVVaultFile:
+		if !h.markVaultDeleted(userID) {
+			log.Printf("[WebDAVHandler] Удаление vault.kdbx пользователя %d отклонено: нет временного файла", userID)
+			middleware.WriteError(w, r, http.StatusForbidden, models.ErrCodeForbidden,
+				"Хранилище нельзя удалить по WebDAV, версии хранятся на сервере")
+			return
+		}
+		log.Printf("[WebDAVHandler] vault.kdbx пользователя %d скрыт до переименования временного файла", userID)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		if !h.removeTempFile(userID, name) {
+			middleware.WriteError(w, r, http.StatusNotFound, models.ErrCodeNotFound, "Файл не найден")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// move переименовывает временный файл. Переименование в vault.kdbx загружает его новой версией хранилища.
+func (h *WebDAVHandler) move(w http.ResponseWriter, r *http.Request, userID int64, name string) {
+	dest, ok := h.destination(r)
+	if !ok {
+		middleware.WriteError(w, r, http.StatusBadRequest, models.ErrCodeInvalidRequest,
+			"Неверный или отсутствующий заголовок Destination")
+		return
+	}
+	if name == "" || name == WebDAVVaultFile || dest == "" {
+		middleware.WriteError(w, r, http.StatusForbidden, models.ErrCodeForbidden,
+			"Переименовать можно только временный файл")
+		return
+	}
+	file, ok := h.tempFile(userID, name)
+	if !ok {
+		middleware.WriteError(w, r, http.StatusNotFound, models.ErrCodeNotFound, "Файл не найден")
+		return
+	}
+	overwrite := !strings.EqualFold(r.Header.Get("Overwrite"), "F")
+
+	if dest != WebDAVVaultFile {
+		if _, exists := h.tempFile(userID, dest); exists && !overwrite {
+			middleware.WriteError(w, r, http.StatusPreconditionFailed, models.ErrCodeInvalidRequest, "Файл уже существует")
+			return
+		}
+		h.removeTempFile(userID, name)
+		if !h.storeTempFile(userID, dest, file.data) {
+			middleware.WriteError(w, r, http.StatusInsufficientStorage, models.ErrCodeInvalidRequest,
+				"Превышен лимит временных файлов")
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		return
+	}
+
+	if !overwrite && !h.vaultDeleted(userID) {
+		current, err := h.currentVersion(r, userID)
+		if err != nil {
+			h.writeServiceError(w, r, userID, err)
+			return
+		}
+		if current != nil {
+			middleware.WriteError(w, r, http.StatusPreconditionFailed, models.ErrCodeInvalidRequest, "Файл уже существует")
+			return
+		}
+	} else if !h.checkBase(w, r, userID) {
+		return
+	}
+	if h.upload(w, r, userID, bytes.NewReader(file.data), int64(len(file.data))) {
+		h.removeTempFile(userID, name)
+	}
+}
+
+// destination возвращает имя файла из заголовка Destination (абсолютный URL или путь в каталоге WebDAV).
+func (h *WebDAVHandler) destination(r *http.Request) (string, bool) {
+	header := r.Header.Get("Destination")
+	if header == "" {
+		return "", false
+	}
+	u, err := url.Parse(header)
+	if err != nil {
+		return "", false
+	}
+	return h.resourceName(u.Path)
+}
+
+// lock выдает токен блокировки, не удерживая ее (см. WebDAVHandler).
+func (h *WebDAVHandler) lock(w http.ResponseWriter, r *http.Request, name string) {
+	_, _ = io.Copy(io.Discard, r.Body)
+	token := "opaquelocktoken:" + uuid.NewString()
+	w.Header().Set("Lock-Token", "<"+token+">")
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = fmt.Fprintf(w, xml.Header+`<D:prop xmlns:D="DAV:"><D:lockdiscovery><D:activelock>`+
+		`<D:locktype><D:write/></D:locktype><D:lockscope><D:exclusive/></D:lockscope>`+
+		`<D:depth>0</D:depth><D:timeout>%s</D:timeout>`+
+		`<D:locktoken><D:href>%s</D:href></D:locktoken><D:lockroot><D:href>%s</D:href></D:lockroot>`+
+		`</D:activelock></D:lockdiscovery></D:prop>`, webdavLockTTL, token, h.href(name))
+}
+
+// currentVersion возвращает метаданные текущей версии хранилища (nil - хранилища еще нет).
+func (h *WebDAVHandler) currentVersion(r *http.Request, userID int64) (*models.VaultVersion, error) {
+	current, err := h.vaultService.GetVaultMetadata(r.Context(), userID)
+	if errors.Is(err, services.ErrVaultNotFound) {
+		return nil, nil //nolint:nilnil // Отсутствие хранилища - не ошибка
+	}
+	return current, err
+}
+
+// writeServiceError отвечает об ошибке сервиса хранилища с теми же статусами, что и /api/vault/upload.
+func (h *WebDAVHandler) writeServiceError(w http.ResponseWriter, r *http.Request, userID int64, err error) {
+	switch {
+	case errors.Is(err, services.ErrConflictVersion):
+		log.Printf("[WebDAVHandler] Конфликт версии при записи vault.kdbx пользователя %d: %v", userID, err)
+		middleware.WriteError(w, r, http.StatusConflict, models.ErrCodeVersionConflict,
+			"Конфликт версий: на сервере уже есть более новая или идентичная версия с другим содержимым.")
+	case errors.Is(err, services.ErrInvalidVaultFile):
+		middleware.WriteErrorDetails(w, r, http.StatusUnsupportedMediaType, models.ErrCodeUnsupportedMedia,
+			"Файл не является базой KeePass поддерживаемой версии (KDBX 3.x или 4.x)",
+			map[string]any{"reason": err.Error()})
+	case errors.Is(err, services.ErrOperationTimeout):
+		writeTimeoutError(w, r)
+	default:
+		log.Printf("[WebDAVHandler] Ошибка сервиса хранилища для пользователя %d: %v", userID, err)
+		middleware.WriteError(w, r, http.StatusInternalServerError, models.ErrCodeInternal, "Внутренняя ошибка сервера")
+	}
+}
+
+// writeReadError отвечает об ошибке чтения тела запроса (413, если превышен лимит размера).
+func (h *WebDAVHandler) writeReadError(w http.ResponseWriter, r *http.Request, userID int64, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		middleware.WriteErrorDetails(w, r, http.StatusRequestEntityTooLarge, models.ErrCodePayloadTooLarge,
+			fmt.Sprintf("Размер запроса превышает допустимый (%d байт)", maxBytesErr.Limit),
+			map[string]any{"max_size": maxBytesErr.Limit})
+		return
+	}
+	log.Printf("[WebDAVHandler] Ошибка чтения тела запроса пользователя %d: %v", userID, err)
+	middleware.WriteError(w, r, http.StatusBadRequest, models.ErrCodeInvalidRequest, "Ошибка чтения тела запроса")
+}
+
+// --- Временные файлы --- //
+
+// putTempFile сохраняет временный файл (201 - создан, 204 - перезаписан).
+func (h *WebDAVHandler) putTempFile(w http.ResponseWriter, r *http.Request, userID int64, name string) {
+	data, err := readBufferedBody(r)
+	if err != nil {
+		h.writeReadError(w, r, userID, err)
+		return
+	}
+
+	_, exists := h.tempFile(userID, name)
+	if !h.storeTempFile(userID, name, data) {
+		log.Printf("[WebDAVHandler] Превышен лимит временных файлов пользователя %d", userID)
+		middleware.WriteError(w, r, http.StatusInsufficientStorage, models.ErrCodeInvalidRequest,
+			"Превышен лимит временных файлов")
+		return
+	}
+	if exists {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+// readBufferedBody читает тело запроса в память, но не больше WebDAVMaxBufferedSize байт.
+// Превышение лимита возвращается как *http.MaxBytesError, чтобы клиент получил 413.
+func readBufferedBody(r *http.Request) ([]byte, error) {
+	tooLarge := &http.MaxBytesError{Limit: WebDAVMaxBufferedSize}
+	if r.ContentLength > WebDAVMaxBufferedSize {
+		return nil, tooLarge
+	}
+	data, err := io.ReadAll(io.LimitReader(r.Body, WebDAVMaxBufferedSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > WebDAVMaxBufferedSize {
+		return nil, tooLarge
+	}
+	return data, nil
+}
+
+// tempFiles возвращает копию временных файлов пользователя, удаляя файлы с истекшим сроком хранения.
+func (h *WebDAVHandler) tempFiles(userID int64) map[string]webdavTempFile {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.expireTempFilesLocked(userID, time.Now())
+	files := make(map[string]webdavTempFile, len(h.temps[userID]))
+	for name, file := range h.temps[userID] {
+		files[name] = file
+	}
+	return files
+}
+
+// tempFile возвращает временный файл пользователя.
+func (h *WebDAVHandler) tempFile(userID int64, name string) (webdavTempFile, bool) {
+	file, ok := h.tempFiles(userID)[name]
+	return file, ok
+}
+
+// storeTempFile сохраняет временный файл пользователя. Возвращает false, если превышено число временных
+// файлов пользователя или их общий объем в памяти сервера.
+func (h *WebDAVHandler) storeTempFile(userID int64, name string, data []byte) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.expireTempFilesLocked(userID, time.Now())
+	old, exists := h.temps[userID][name]
+	if !exists && len(h.temps[userID]) >= webdavMaxTempFiles {
+		return false
+	}
+	if h.tempBytes-len(old.data)+len(data) > webdavMaxTempBytes {
+		return false
+	}
+	if h.temps[userID] == nil {
+		h.temps[userID] = make(map[string]webdavTempFile)
+	}
+	h.temps[userID][name] = webdavTempFile{data: data, modified: time.Now()}
+	h.tempBytes += len(data) - len(old.data)
+	return true
+}
+
+// removeTempFile удаляет временный файл пользователя и сообщает, был ли он.
+func (h *WebDAVHandler) removeTempFile(userID int64, name string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	file, ok := h.temps[userID][name]
+	if ok {
+		h.deleteTempFileLocked(userID, name, file)
+	}
+	return ok
+}
+
+// expireTempFilesLocked удаляет временные файлы пользователя с истекшим сроком и возвращает их число.
+// Вызывается с захваченным h.mu.
+func (h *WebDAVHandler) expireTempFilesLocked(userID int64, now time.Time) int {
+	expired := 0
+	for name, file := range h.temps[userID] {
+		if now.Sub(file.modified) > webdavTempTTL {
+			h.deleteTempFileLocked(userID, name, file)
+			expired++
+		}
+	}
+	return expired
+}
+
+// deleteTempFileLocked удаляет временный файл и учитывает освободившийся объем. Вызывается с захваченным h.mu.
+func (h *WebDAVHandler) deleteTempFileLocked(userID int64, name string, file webdavTempFile) {
+	delete(h.temps[userID], name)
+	if len(h.temps[userID]) == 0 {
+		delete(h.temps, userID)
+	}
+	h.tempBytes -= len(file.data)
+	if len(h.temps[userID]) == 0 {
+		// Переименовывать в vault.kdbx больше нечего, поэтому удаленный клиентом файл снова показывается
+		delete(h.deleted, userID)
+	}
+}
+
+// markVaultDeleted скрывает vault.kdbx пользователя до переименования в него временного файла.
+// Возвращает false, если временных файлов нет и удаление не относится к транзакционной записи.
+func (h *WebDAVHandler) markVaultDeleted(userID int64) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.temps[userID]) == 0 {
+		return false
+	}
+	h.deleted[userID] = true
+	return true
+}
+
+// vaultDeleted сообщает, что пользователь удалил vault.kdbx и еще не переименовал в него временный файл.
+func (h *WebDAVHandler) vaultDeleted(userID int64) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.deleted[userID]
+}
+
+// restoreVault снова показывает vault.kdbx пользователя после записи новой версии.
+func (h *WebDAVHandler) restoreVault(userID int64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.deleted, userID)
+}
+
+// baseETag возвращает ETag версии vault.kdbx, на которой основана копия клиента запроса r.
+func (h *WebDAVHandler) baseETag(r *http.Request, userID int64) (string, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	base, ok := h.bases[userID][r.UserAgent()]
+	if !ok || time.Since(base.seen) > webdavBaseTTL {
+		return "", false
+	}
+	return base.etag, true
+}
+
+// rememberBase запоминает, что копия клиента запроса r основана на версии с ETag etag.
+func (h *WebDAVHandler) rememberBase(r *http.Request, userID int64, etag string) {
+	if etag == "" {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	clients := h.bases[userID]
+	if clients == nil {
+		clients = make(map[string]webdavBase)
+		h.bases[userID] = clients
+	}
+	agent := r.UserAgent()
+	if _, exists := clients[agent]; !exists && len(clients) >= webdavMaxClients {
+		oldest := ""
+		for name, base := range clients {
+			if oldest == "" || base.seen.Before(clients[oldest].seen) {
+				oldest = name
+			}
+		}
+		delete(clients, oldest)
+	}
+	clients[agent] = webdavBase{etag: etag, seen: time.Now()}
+}
+
+// Run удаляет временные файлы и проверенные пароли с истекшим сроком раз в webdavSweepInterval
+// до отмены контекста. Без этого память освобождалась бы, только когда пользователь снова обращается к каталогу.
+func (h *WebDAVHandler) Run(ctx context.Context) {
+	ticker := time.NewTicker(webdavSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("[WebDAVHandler] Очистка временных файлов остановлена")
+			return
+		case <-ticker.C:
+			if expired := h.SweepExpired(time.Now()); expired > 0 {
+				log.Printf("[WebDAVHandler] Удалено временных файлов с истекшим сроком: %d", expired)
+			}
+		}
+	}
+}
+
+// SweepExpired удаляет временные файлы всех пользователей, срок хранения которых истек к моменту now,
+// устаревшие проверенные пароли и версии, скачанные клиентами. Возвращает число удаленных временных файлов.
+func (h *WebDAVHandler) SweepExpired(now time.Time) int {
+	h.mu.Lock()
+	expired := 0
+	for userID := range h.temps {
+		expired += h.expireTempFilesLocked(userID, now)
+	}
+	for userID, clients := range h.bases {
+		for agent, base := range clients {
+			if now.Sub(base.seen) > webdavBaseTTL {
+				delete(clients, agent)
+			}
+		}
+		if len(clients) == 0 {
+			delete(h.bases, userID)
+		}
+	}
+	h.mu.Unlock()
+
+	h.credMu.Lock()
+	for key, cred := range h.credentials {
+		if now.After(cred.expires) {
+			delete(h.credentials, key)
+		}
+	}
+	h.credMu.Unlock()
+	return expired
+}
+
+// --- PROPFIND --- //
+
+// davMultistatus - ответ 207 Multi-Status на PROPFIND.
+type davMultistatus struct {
+	XMLName   xml.Name      `xml:"D:multistatus"`
+	Namespace string        `xml:"xmlns:D,attr"`
+	Responses []davResponse `xml:"D:response"`
+}
+
+type davResponse struct {
+	Href     string      `xml:"D:href"`
+	Propstat davPropstat `xml:"D:propstat"`
+}
+
+type davPropstat struct {
+	Prop   davProp `xml:"D:prop"`
+	Status string  `xml:"D:status"`
+}
+
+type davProp struct {
+	DisplayName   string          `xml:"D:displayname"`
+	ResourceType  davResourceType `xml:"D:resourcetype"`
+	ContentLength string          `xml:"D:getcontentlength,omitempty"`
+	ContentType   string          `xml:"D:getcontenttype,omitempty"`
+	LastModified  string          `xml:"D:getlastmodified,omitempty"`
+	ETag          string          `xml:"D:getetag,omitempty"`
+}
+
+type davResourceType struct {
+	Collection *struct{} `xml:"D:collection"`
+}
+
+// propfind отвечает свойствами каталога и файлов (Depth: 0 - только запрошенный ресурс).
+// Запрошенные свойства не разбираются: всегда возвращается один и тот же набор, которого достаточно клиентам KeePass.
+func (h *WebDAVHandler) propfind(w http.ResponseWriter, r *http.Request, userID int64, name string) {
+	_, _ = io.Copy(io.Discard, r.Body)
+
+	var responses []davResponse
+	if name == "" || name == WebDAVVaultFile {
+		var current *models.VaultVersion
+		if !h.vaultDeleted(userID) { // Удаленный клиентом vault.kdbx не показывается до переименования
+			var err error
+			if current, err = h.currentVersion(r, userID); err != nil {
+				h.writeServiceError(w, r, userID, err)
+				return
+			}
+		}
+		if name == "" {
+			responses = append(responses, davResponse{Href: h.href(""), Propstat: davPropstat{Prop: davProp{
+				DisplayName:  strings.Trim(h.prefix, "/"),
+				ResourceType: davResourceType{Collection: &struct{}{}},
+			}}})
+		}
+		if current != nil && (name != "" || r.Header.Get("Depth") != "0") {
+			responses = append(responses, h.vaultResponse(current))
+		}
+	}
+	if name != WebDAVVaultFile && (name != "" || r.Header.Get("Depth") != "0") {
+		files := h.tempFiles(userID)
+		names := make([]string, 0, len(files))
+		for fileName := range files {
+			if name == "" || fileName == name {
+				names = append(names, fileName)
+			}
+		}
+		sort.Strings(names)
+		for _, fileName := range names {
+			responses = append(responses, h.fileResponse(fileName, int64(len(files[fileName].data)),
+				files[fileName].modified, ""))
+		}
+	}
+	if len(responses) == 0 {
+		middleware.WriteError(w, r, http.StatusNotFound, models.ErrCodeNotFound, "Файл не найден")
+		return
+	}
+
+	for i := range responses {
+		responses[i].Propstat.Status = "HTTP/1.1 200 OK"
+	}
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(http.StatusMultiStatus)
+	_, _ = io.WriteString(w, xml.Header)
+	if err := xml.NewEncoder(w).Encode(davMultistatus{Namespace: "DAV:", Responses: responses}); err != nil {
+		log.Printf("[WebDAVHandler] Ошибка кодирования ответа PROPFIND: %v", err)
+	}
+}
+
+// vaultResponse возвращает свойства vault.kdbx по метаданным текущей версии.
+func (h *WebDAVHandler) vaultResponse(version *models.VaultVersion) davResponse {
+	size := int64(0)
+	if version.SizeBytes != nil {
+		size = *version.SizeBytes
+	}
+	return h.fileResponse(WebDAVVaultFile, size, version.CreatedAt, vaultETag(version))
+}
+
+// fileResponse возвращает свойства файла.
+func (h *WebDAVHandler) fileResponse(name string, size int64, modified time.Time, etag string) davResponse {
+	return davResponse{Href: h.href(name), Propstat: davPropstat{Prop: davProp{
+		DisplayName:   name,
+		ContentLength: strconv.FormatInt(size, 10),
+		ContentType:   "application/octet-stream",
+		LastModified:  modified.UTC().Format(http.TimeFormat),
+		ETag:          etag,
+	}}}
+}
@@ -0,0 +1,441 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/maynagashev/gophkeeper/models"
+	"github.com/maynagashev/gophkeeper/server/internal/handlers"
+	"github.com/maynagashev/gophkeeper/server/internal/mocks"
+	"github.com/maynagashev/gophkeeper/server/internal/services"
+)
+
+const webdavTestSecret = "webdav-secret"
+
+// webdavToken возвращает JWT пользователя userID, подписанный тестовым ключом.
+func webdavToken(t *testing.T, userID int64) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": userID,
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte(webdavTestSecret))
+	require.NoError(t, err)
+	return token
+}
+
+// webdavDisabledUserID - пользователь, учетную запись которого отключили после выдачи токена.
+const webdavDisabledUserID = 2
+
+// webdavAccounts находит пользователей активными, кроме webdavDisabledUserID.
+func webdavAccounts(_ context.Context, userID int64) (*models.User, error) {
+	user := &models.User{ID: userID, Role: models.RoleUser}
+	if userID == webdavDisabledUserID {
+		disabledAt := time.Now()
+		user.DisabledAt = &disabledAt
+	}
+	return user, nil
+}
+
+// webdavRequest выполняет запрос к обработчику WebDAV с токеном Bearer пользователя 1.
+func webdavRequest(
+	t *testing.T,
+	h *handlers.WebDAVHandler,
+	method, target, body string,
+	headers map[string]string,
+) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+webdavToken(t, 1))
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestWebDAVHandler_Authentication(t *testing.T) {
+	t.Run("Без учетных данных запрашивается Basic", func(t *testing.T) {
+		h := handlers.NewWebDAVHandler(mocks.NewVaultService(t), mocks.NewAuthService(t), webdavTestSecret,
+			webdavAccounts, "/dav")
+		rr := httptest.NewRecorder()
+
+		h.ServeHTTP(rr, httptest.NewRequest("PROPFIND", "/dav/", nil))
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		assert.Contains(t, rr.Header().Get("WWW-Authenticate"), `Basic realm="GophKeeper"`)
+	})
+
+	t.Run("Неверный пароль", func(t *testing.T) {
+		auth := mocks.NewAuthService(t)
+		auth.EXPECT().VerifyCredentials(mock.Anything, "user", "wrong").Return(nil, services.ErrInvalidCredentials)
+		h := handlers.NewWebDAVHandler(mocks.NewVaultService(t), auth, webdavTestSecret, webdavAccounts, "/dav")
+		req := httptest.NewRequest(http.MethodOptions, "/dav/", nil)
+		req.SetBasicAuth("user", "wrong")
+		rr := httptest.NewRecorder()
+
+		h.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("Проверенный пароль запоминается", func(t *testing.T) {
+		// Вход не выполняется: ни токена, ни учета устройства, ни метрик входа на каждый запрос
+		auth := mocks.NewAuthService(t)
+		auth.EXPECT().VerifyCredentials(mock.Anything, "user", "secret").
+			Return(&models.User{ID: 1, Role: models.RoleUser}, nil).Once()
+		h := handlers.NewWebDAVHandler(mocks.NewVaultService(t), auth, webdavTestSecret, webdavAccounts, "/dav")
+
+		for range 3 {
+			req := httptest.NewRequest(http.MethodOptions, "/dav/", nil)
+			req.SetBasicAuth("user", "secret")
+			rr := httptest.NewRecorder()
+
+			h.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, "1, 2", rr.Header().Get("DAV"))
+		}
+	})
+
+	t.Run("Другой пароль проверяется заново", func(t *testing.T) {
+		auth := mocks.NewAuthService(t)
+		auth.EXPECT().VerifyCredentials(mock.Anything, "user", "secret").
+			Return(&models.User{ID: 1, Role: models.RoleUser}, nil).Once()
+		auth.EXPECT().VerifyCredentials(mock.Anything, "user", "guess").Return(nil, services.ErrInvalidCredentials).Once()
+		h := handlers.NewWebDAVHandler(mocks.NewVaultService(t), auth, webdavTestSecret, webdavAccounts, "/dav")
+
+		codes := make([]int, 0, 2)
+		for _, password := range []string{"secret", "guess"} {
+			req := httptest.NewRequest(http.MethodOptions, "/dav/", nil)
+			req.SetBasicAuth("user", password)
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, req)
+			codes = append(codes, rr.Code)
+		}
+
+		assert.Equal(t, []int{http.StatusOK, http.StatusUnauthorized}, codes)
+	})
+
+	t.Run("Отключение учетной записи действует несмотря на кэш", func(t *testing.T) {
+		auth := mocks.NewAuthService(t)
+		// Первая проверка прошла до отключения, при следующем запросе учетная запись уже отключена
+		auth.EXPECT().VerifyCredentials(mock.Anything, "user", "secret").
+			Return(&models.User{ID: webdavDisabledUserID, Role: models.RoleUser}, nil).Once()
+		auth.EXPECT().VerifyCredentials(mock.Anything, "user", "secret").
+			Return(nil, services.ErrAccountDisabled).Once()
+		h := handlers.NewWebDAVHandler(mocks.NewVaultService(t), auth, webdavTestSecret, webdavAccounts, "/dav")
+
+		codes := make([]int, 0, 2)
+		for range 2 {
+			req := httptest.NewRequest(http.MethodOptions, "/dav/", nil)
+			req.SetBasicAuth("user", "secret")
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, req)
+			codes = append(codes, rr.Code)
+		}
+
+		assert.Equal(t, []int{http.StatusOK, http.StatusForbidden}, codes)
+	})
+
+	t.Run("Токен отключенного пользователя", func(t *testing.T) {
+		h := handlers.NewWebDAVHandler(mocks.NewVaultService(t), mocks.NewAuthService(t), webdavTestSecret,
+			webdavAccounts, "/dav")
+		req := httptest.NewRequest(http.MethodOptions, "/dav/", nil)
+		req.Header.Set("Authorization", "Bearer "+webdavToken(t, webdavDisabledUserID))
+		rr := httptest.NewRecorder()
+
+		h.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+}
+
+func TestWebDAVHandler_Propfind(t *testing.T) {
+	checksum, size := "abc123", int64(42)
+	current := &models.VaultVersion{ID: 7, Checksum: &checksum, SizeBytes: &size, CreatedAt: time.Now()}
+
+	t.Run("Каталог с файлом хранилища", func(t *testing.T) {
+		vs := mocks.NewVaultService(t)
+		vs.EXPECT().GetVaultMetadata(mock.Anything, int64(1)).Return(current, nil)
+		h := handlers.NewWebDAVHandler(vs, mocks.NewAuthService(t), webdavTestSecret, webdavAccounts, "/dav")
+
+		rr := webdavRequest(t, h, "PROPFIND", "/dav/", "", map[string]string{"Depth": "1"})
+
+		assert.Equal(t, http.StatusMultiStatus, rr.Code)
+		body := rr.Body.String()
+		assert.Contains(t, body, "<D:href>/dav/</D:href>")
+		assert.Contains(t, body, "<D:collection></D:collection>")
+		assert.Contains(t, body, "<D:href>/dav/vault.kdbx</D:href>")
+		assert.Contains(t, body, "<D:getcontentlength>42</D:getcontentlength>")
+		assert.Contains(t, body, `<D:getetag>&#34;abc123&#34;</D:getetag>`)
+	})
+
+	t.Run("Хранилища еще нет", func(t *testing.T) {
+		vs := mocks.NewVaultService(t)
+		vs.EXPECT().GetVaultMetadata(mock.Anything, int64(1)).Return(nil, services.ErrVaultNotFound)
+		h := handlers.NewWebDAVHandler(vs, mocks.NewAuthService(t), webdavTestSecret, webdavAccounts, "/dav")
+
+		rr := webdavRequest(t, h, "PROPFIND", "/dav/vault.kdbx", "", map[string]string{"Depth": "0"})
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestWebDAVHandler_Put(t *testing.T) {
+	checksum := "abc123"
+	current := &models.VaultVersion{ID: 7, Checksum: &checksum}
+
+	t.Run("Запись создает версию через UploadVault", func(t *testing.T) {
+		vs := mocks.NewVaultService(t)
+		vs.EXPECT().UploadVault(mock.Anything, int64(1), mock.Anything, int64(4), "application/octet-stream",
+			mock.Anything, mock.MatchedBy(func(o models.UploadOrigin) bool {
+				return o.ClientName == "webdav" && o.ClientVersion == "KeePassDX/4.0"
+			})).
+			RunAndReturn(func(_ context.Context, _ int64, r io.Reader, _ int64, _ string, _ time.Time,
+				_ models.UploadOrigin) (*models.VaultVersion, error) {
+				data, err := io.ReadAll(r)
+				require.NoError(t, err)
+				assert.Equal(t, "kdbx", string(data))
+				return &models.VaultVersion{ID: 8, Checksum: &checksum}, nil
+			})
+		h := handlers.NewWebDAVHandler(vs, mocks.NewAuthService(t), webdavTestSecret, webdavAccounts, "/dav")
+
+		rr := webdavRequest(t, h, http.MethodPut, "/dav/vault.kdbx", "kdbx",
+			map[string]string{"User-Agent": "KeePassDX/4.0"})
+
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+		assert.Equal(t, `"abc123"`, rr.Header().Get("ETag"))
+	})
+
+	t.Run("Конфликт версий", func(t *testing.T) {
+		vs := mocks.NewVaultService(t)
+		vs.EXPECT().UploadVault(mock.Anything, int64(1), mock.Anything, int64(4), mock.Anything, mock.Anything,
+			mock.Anything).Return(nil, services.ErrConflictVersion)
+		h := handlers.NewWebDAVHandler(vs, mocks.NewAuthService(t), webdavTestSecret, webdavAccounts, "/dav")
+
+		rr := webdavRequest(t, h, http.MethodPut, "/dav/vault.kdbx", "kdbx", nil)
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+
+	t.Run("Файл не является базой KeePass", func(t *testing.T) {
+		vs := mocks.NewVaultService(t)
+		vs.EXPECT().UploadVault(mock.Anything, int64(1), mock.Anything, int64(4), mock.Anything, mock.Anything,
+			mock.Anything).Return(nil, errors.Join(services.ErrInvalidVaultFile, errors.New("нет сигнатуры")))
+		h := handlers.NewWebDAVHandler(vs, mocks.NewAuthService(t), webdavTestSecret, webdavAccounts, "/dav")
+
+		rr := webdavRequest(t, h, http.MethodPut, "/dav/vault.kdbx", "text", nil)
+
+		assert.Equal(t, http.StatusUnsupportedMediaType, rr.Code)
+	})
+
+	t.Run("Тело без Content-Length ограничено по размеру", func(t *testing.T) {
+		// Сервис хранилища не вызывается: тело отклоняется при чтении
+		h := handlers.NewWebDAVHandler(mocks.NewVaultService(t), mocks.NewAuthService(t), webdavTestSecret,
+			webdavAccounts, "/dav")
+		body := io.LimitReader(zeroReader{}, handlers.WebDAVMaxBufferedSize+1)
+		req := httptest.NewRequest(http.MethodPut, "/dav/vault.kdbx", body)
+		req.Header.Set("Authorization", "Bearer "+webdavToken(t, 1))
+		req.ContentLength = -1 // Передача chunked
+		rr := httptest.NewRecorder()
+
+		h.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+		assert.Contains(t, rr.Body.String(), strconv.Itoa(handlers.WebDAVMaxBufferedSize))
+	})
+
+	t.Run("If-Match с устаревшим ETag", func(t *testing.T) {
+		vs := mocks.NewVaultService(t)
+		vs.EXPECT().GetVaultMetadata(mock.Anything, int64(1)).Return(current, nil)
+		h := handlers.NewWebDAVHandler(vs, mocks.NewAuthService(t), webdavTestSecret, webdavAccounts, "/dav")
+
+		rr := webdavRequest(t, h, http.MethodPut, "/dav/vault.kdbx", "kdbx", map[string]string{"If-Match": `"old"`})
+
+		assert.Equal(t, http.StatusPreconditionFailed, rr.Code)
+	})
+
+	t.Run("Запись поверх версии, загруженной после скачивания", func(t *testing.T) {
+		newerChecksum := "def456"
+		newer := &models.VaultVersion{ID: 8, Checksum: &newerChecksum}
+		vs := mocks.NewVaultService(t)
+		// Клиент скачивает версию 7, затем через API загружают версию 8
+		vs.EXPECT().GetVaultMetadata(mock.Anything, int64(1)).Return(current, nil).Once()
+		vs.EXPECT().GetVaultMetadata(mock.Anything, int64(1)).Return(newer, nil)
+		h := handlers.NewWebDAVHandler(vs, mocks.NewAuthService(t), webdavTestSecret, webdavAccounts, "/dav")
+		keepass := map[string]string{"User-Agent": "KeePass/2.57"}
+
+		rr := webdavRequest(t, h, http.MethodGet, "/dav/vault.kdbx", "",
+			map[string]string{"User-Agent": "KeePass/2.57", "If-None-Match": `"abc123"`})
+		require.Equal(t, http.StatusNotModified, rr.Code)
+
+		rr = webdavRequest(t, h, http.MethodPut, "/dav/vault.kdbx", "kdbx", keepass)
+		assert.Equal(t, http.StatusPreconditionFailed, rr.Code, "Устаревшая копия не заменяет новую версию")
+
+		rr = webdavRequest(t, h, http.MethodPut, "/dav/vault.kdbx.tmp", "kdbx", keepass)
+		require.Equal(t, http.StatusCreated, rr.Code)
+		rr = webdavRequest(t, h, "MOVE", "/dav/vault.kdbx.tmp", "",
+			map[string]string{"User-Agent": "KeePass/2.57", "Destination": "/dav/vault.kdbx"})
+		assert.Equal(t, http.StatusPreconditionFailed, rr.Code, "Транзакционная запись проверяется так же")
+
+		// После повторного скачивания клиент записывает файл поверх версии 8
+		vs.EXPECT().UploadVault(mock.Anything, int64(1), mock.Anything, int64(4), mock.Anything, mock.Anything,
+			mock.Anything).Return(&models.VaultVersion{ID: 9, Checksum: &checksum}, nil).Once()
+		rr = webdavRequest(t, h, http.MethodGet, "/dav/vault.kdbx", "",
+			map[string]string{"User-Agent": "KeePass/2.57", "If-None-Match": `"def456"`})
+		require.Equal(t, http.StatusNotModified, rr.Code)
+		rr = webdavRequest(t, h, http.MethodPut, "/dav/vault.kdbx", "kdbx", keepass)
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+	})
+}
+
+// zeroReader - бесконечный поток нулевых байт.
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+// TestWebDAVHandler_TransactedWrite проверяет запись, которую выполняет KeePass 2: файл загружается
+// во временный, исходный удаляется, а временный переименовывается в исходный.
+func TestWebDAVHandler_TransactedWrite(t *testing.T) {
+	checksum := "abc123"
+	vs := mocks.NewVaultService(t)
+	vs.EXPECT().UploadVault(mock.Anything, int64(1), mock.Anything, int64(4), mock.Anything, mock.Anything,
+		mock.Anything).
+		RunAndReturn(func(_ context.Context, _ int64, r io.Reader, _ int64, _ string, _ time.Time,
+			_ models.UploadOrigin) (*models.VaultVersion, error) {
+			data, err := io.ReadAll(r)
+			require.NoError(t, err)
+			assert.Equal(t, "kdbx", string(data))
+			return &models.VaultVersion{ID: 8, Checksum: &checksum}, nil
+		}).Once()
+	h := handlers.NewWebDAVHandler(vs, mocks.NewAuthService(t), webdavTestSecret, webdavAccounts, "/dav")
+
+	rr := webdavRequest(t, h, "LOCK", "/dav/vault.kdbx", "", nil)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.True(t, strings.HasPrefix(rr.Header().Get("Lock-Token"), "<opaquelocktoken:"))
+
+	rr = webdavRequest(t, h, http.MethodPut, "/dav/vault.kdbx.tmp", "kdbx", nil)
+	assert.Equal(t, http.StatusCreated, rr.Code)
+
+	rr = webdavRequest(t, h, http.MethodGet, "/dav/vault.kdbx.tmp", "", nil)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "kdbx", rr.Body.String())
+
+	rr = webdavRequest(t, h, http.MethodDelete, "/dav/vault.kdbx", "", nil)
+	assert.Equal(t, http.StatusNoContent, rr.Code, "Удаление перед переименованием временного файла принимается")
+
+	rr = webdavRequest(t, h, http.MethodGet, "/dav/vault.kdbx", "", nil)
+	assert.Equal(t, http.StatusNotFound, rr.Code, "До переименования удаленный файл не показывается")
+	rr = webdavRequest(t, h, "PROPFIND", "/dav/vault.kdbx", "", map[string]string{"Depth": "0"})
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	rr = webdavRequest(t, h, "MOVE", "/dav/vault.kdbx.tmp", "",
+		map[string]string{"Destination": "https://example.com/dav/vault.kdbx", "Overwrite": "T"})
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+
+	rr = webdavRequest(t, h, http.MethodGet, "/dav/vault.kdbx.tmp", "", nil)
+	assert.Equal(t, http.StatusNotFound, rr.Code, "Временный файл удаляется после загрузки")
+
+	vs.EXPECT().GetVaultMetadata(mock.Anything, int64(1)).Return(&models.VaultVersion{ID: 8, Checksum: &checksum}, nil).
+		Once()
+	rr = webdavRequest(t, h, "PROPFIND", "/dav/vault.kdbx", "", map[string]string{"Depth": "0"})
+	assert.Equal(t, http.StatusMultiStatus, rr.Code, "После переименования файл снова показывается")
+
+	rr = webdavRequest(t, h, "MOVE", "/dav/vault.kdbx", "",
+		map[string]string{"Destination": "/dav/other.kdbx"})
+	assert.Equal(t, http.StatusForbidden, rr.Code, "Хранилище нельзя переименовать")
+}
+
+func TestWebDAVHandler_Delete(t *testing.T) {
+	checksum := "abc123"
+	current := &models.VaultVersion{ID: 7, Checksum: &checksum}
+
+	t.Run("Удаление хранилища без временного файла отклоняется", func(t *testing.T) {
+		vs := mocks.NewVaultService(t)
+		vs.EXPECT().GetVaultMetadata(mock.Anything, int64(1)).Return(current, nil)
+		h := handlers.NewWebDAVHandler(vs, mocks.NewAuthService(t), webdavTestSecret, webdavAccounts, "/dav")
+
+		rr := webdavRequest(t, h, http.MethodDelete, "/dav/vault.kdbx", "", nil)
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+
+		rr = webdavRequest(t, h, http.MethodGet, "/dav/vault.kdbx", "", map[string]string{"If-None-Match": `"abc123"`})
+		assert.Equal(t, http.StatusNotModified, rr.Code, "Файл остается на месте")
+	})
+
+	t.Run("Файл показывается снова, если временный файл удален", func(t *testing.T) {
+		vs := mocks.NewVaultService(t)
+		vs.EXPECT().GetVaultMetadata(mock.Anything, int64(1)).Return(current, nil).Once()
+		h := handlers.NewWebDAVHandler(vs, mocks.NewAuthService(t), webdavTestSecret, webdavAccounts, "/dav")
+
+		rr := webdavRequest(t, h, http.MethodPut, "/dav/vault.kdbx.tmp", "kdbx", nil)
+		require.Equal(t, http.StatusCreated, rr.Code)
+		rr = webdavRequest(t, h, http.MethodDelete, "/dav/vault.kdbx", "", nil)
+		require.Equal(t, http.StatusNoContent, rr.Code)
+		rr = webdavRequest(t, h, http.MethodDelete, "/dav/vault.kdbx.tmp", "", nil)
+		require.Equal(t, http.StatusNoContent, rr.Code)
+
+		rr = webdavRequest(t, h, http.MethodGet, "/dav/vault.kdbx", "", map[string]string{"If-None-Match": `"abc123"`})
+		assert.Equal(t, http.StatusNotModified, rr.Code)
+	})
+}
+
+func TestWebDAVHandler_TempFiles(t *testing.T) {
+	t.Run("Размер временного файла ограничен", func(t *testing.T) {
+		h := handlers.NewWebDAVHandler(mocks.NewVaultService(t), mocks.NewAuthService(t), webdavTestSecret,
+			webdavAccounts, "/dav")
+		req := httptest.NewRequest(http.MethodPut, "/dav/vault.kdbx.tmp", strings.NewReader("kdbx"))
+		req.Header.Set("Authorization", "Bearer "+webdavToken(t, 1))
+		req.ContentLength = handlers.WebDAVMaxBufferedSize + 1
+		rr := httptest.NewRecorder()
+
+		h.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+		assert.Contains(t, rr.Body.String(), strconv.Itoa(handlers.WebDAVMaxBufferedSize))
+	})
+
+	t.Run("Число временных файлов пользователя ограничено", func(t *testing.T) {
+		h := handlers.NewWebDAVHandler(mocks.NewVaultService(t), mocks.NewAuthService(t), webdavTestSecret,
+			webdavAccounts, "/dav")
+		for i := range 4 {
+			rr := webdavRequest(t, h, http.MethodPut, fmt.Sprintf("/dav/%d.tmp", i), "kdbx", nil)
+			require.Equal(t, http.StatusCreated, rr.Code)
+		}
+
+		rr := webdavRequest(t, h, http.MethodPut, "/dav/4.tmp", "kdbx", nil)
+		assert.Equal(t, http.StatusInsufficientStorage, rr.Code)
+
+		rr = webdavRequest(t, h, http.MethodPut, "/dav/0.tmp", "kdbx2", nil)
+		assert.Equal(t, http.StatusNoContent, rr.Code, "Существующий файл можно перезаписать")
+	})
+
+	t.Run("Файлы с истекшим сроком удаляются без обращений пользователя", func(t *testing.T) {
+		h := handlers.NewWebDAVHandler(mocks.NewVaultService(t), mocks.NewAuthService(t), webdavTestSecret,
+			webdavAccounts, "/dav")
+		rr := webdavRequest(t, h, http.MethodPut, "/dav/vault.kdbx.tmp", "kdbx", nil)
+		require.Equal(t, http.StatusCreated, rr.Code)
+
+		assert.Zero(t, h.SweepExpired(time.Now()), "Свежий файл не удаляется")
+		assert.Equal(t, 1, h.SweepExpired(time.Now().Add(11*time.Minute)))
+
+		rr = webdavRequest(t, h, http.MethodGet, "/dav/vault.kdbx.tmp", "", nil)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/maynagashev/gophkeeper/models"
+	"github.com/maynagashev/gophkeeper/server/internal/middleware"
+	"github.com/maynagashev/gophkeeper/server/internal/services"
+)
+
+// WebhookHandler обрабатывает HTTP-запросы управления вебхуками пользователя.
+type WebhookHandler struct {
+	webhookService services.WebhookService
+}
+
+// NewWebhookHandler создает новый экземпляр WebhookHandler.
+func NewWebhookHandler(s services.WebhookService) *WebhookHandler {
+	return &WebhookHandler{webhookService: s}
+}
+
+// Create обрабатывает POST запрос на регистрацию вебхука.
+// В ответе возвращается секрет для проверки подписи (X-Gophkeeper-Signature) - больше он не отдается.
+func (h *WebhookHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Printf("[WebhookHandler:Create] Не удалось получить userID из контекста")
+		middleware.WriteError(w, r, http.StatusInternalServerError, models.ErrCodeInternal, "Внутренняя ошибка сервера")
+		return
+	}
+
+	var req models.CreateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("[WebhookHandler:Create] Ошибка декодирования запроса: %v", err)
+		middleware.WriteError(w, r, http.StatusBadRequest, models.ErrCodeInvalidRequest, "Неверный формат запроса")
+		return
+	}
+
+	webhook, err := h.webhookService.CreateWebhook(r.Context(), userID, req.URL, req.Secret)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidWebhookURL) {
+			middleware.WriteError(w, r, http.StatusBadRequest, models.ErrCodeInvalidRequest, err.Error())
+			return
+		}
+		log.Printf("[WebhookHandler:Create] Ошибка регистрации вебхука пользователя %d: %v", userID, err)
+		middleware.WriteError(w, r, http.StatusInternalServerError, models.ErrCodeInternal, "Внутренняя ошибка сервера")
+		return
+	}
+	writeWebhookJSON(w, http.StatusCreated, webhook, "Create")
+}
+
+// List обрабатывает GET запрос на получение списка вебхуков пользователя.
+func (h *WebhookHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Printf("[WebhookHandler:List] Не удалось получить userID из контекста")
+		middleware.WriteError(w, r, http.StatusInternalServerError, models.ErrCodeInternal, "Внутренняя ошибка сервера")
+		return
+	}
+
+	webhooks, err := h.webhookService.ListWebhooks(r.Context(), userID)
+	if err != nil {
+		log.Printf("[WebhookHandler:List] Ошибка получения вебхуков пользователя %d: %v", userID, err)
+		middleware.WriteError(w, r, http.StatusInternalServerError, models.ErrCodeInternal, "Внутренняя ошибка сервера")
+		return
+	}
+	writeWebhookJSON(w, http.StatusOK, webhooks, "List")
+}
+
+// Delete обрабатывает DELETE запрос на удаление вебхука.
+func (h *WebhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Printf("[WebhookHandler:Delete] Не удалось получить userID из контекста")
+		middleware.WriteError(w, r, http.StatusInternalServerError, models.ErrCodeInternal, "Внутренняя ошибка сервера")
+		return
+	}
+	webhookID, err := strconv.ParseInt(chi.URLParam(r, "webhookID"), 10, 64)
+	if err != nil || webhookID <= 0 {
+		middleware.WriteError(w, r, http.StatusBadRequest, models.ErrCodeInvalidRequest, "Неверный ID вебхука")
+		return
+	}
+
+	if err = h.webhookService.DeleteWebhook(r.Context(), userID, webhookID); err != nil {
+		if errors.Is(err, services.ErrWebhookNotFound) {
+			middleware.WriteError(w, r, http.StatusNotFound, models.ErrCodeNotFound, err.Error())
+			return
+		}
+		log.Printf("[WebhookHandler:Delete] Ошибка удаления вебхука ID %d: %v", webhookID, err)
+		middleware.WriteError(w, r, http.StatusInternalServerError, models.ErrCodeInternal, "Внутренняя ошибка сервера")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListDeliveries обрабатывает GET запрос на получение истории доставок вебхука (с пагинацией).
+func (h *WebhookHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Printf("[WebhookHandler:ListDeliveries] Не удалось получить userID из контекста")
+		middleware.WriteError(w, r, http.StatusInternalServerError, models.ErrCodeInternal, "Внутренняя ошибка сервера")
+		return
+	}
+	webhookID, err := strconv.ParseInt(chi.URLParam(r, "webhookID"), 10, 64)
+	if err != nil || webhookID <= 0 {
+		middleware.WriteError(w, r, http.StatusBadRequest, models.ErrCodeInvalidRequest, "Неверный ID вебхука")
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	deliveries, err := h.webhookService.ListDeliveries(r.Context(), userID, webhookID, limit, offset)
+	if err != nil {
+		if errors.Is(err, services.ErrWebhookNotFound) {
+			middleware.WriteError(w, r, http.StatusNotFound, models.ErrCodeNotFound, err.Error())
+			return
+		}
+		log.Printf("[WebhookHandler:ListDeliveries] Ошибка получения истории доставок вебхука ID %d: %v", webhookID, err)
+		middleware.WriteError(w, r, http.StatusInternalServerError, models.ErrCodeInternal, "Внутренняя ошибка сервера")
+		return
+	}
+	writeWebhookJSON(w, http.StatusOK, deliveries, "ListDeliveries")
+}
+
+// writeWebhookJSON отправляет ответ в формате JSON.
+func writeWebhookJSON(w http.ResponseWriter, status int, response any, handlerName string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("[WebhookHandler:%s] Ошибка кодирования ответа: %v", handlerName, err)
+	}
+}
@@ -0,0 +1,210 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/maynagashev/gophkeeper/models"
+	"github.com/maynagashev/gophkeeper/server/internal/handlers"
+	"github.com/maynagashev/gophkeeper/server/internal/middleware"
+	"github.com/maynagashev/gophkeeper/server/internal/mocks"
+	"github.com/maynagashev/gophkeeper/server/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// setupWebhookRouter создает роутер с маршрутами вебхуков и userID в контексте.
+func setupWebhookRouter(h *handlers.WebhookHandler, userID int64) *chi.Mux {
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), middleware.UserIDKey, userID)))
+		})
+	})
+	r.Post("/webhooks", h.Create)
+	r.Get("/webhooks", h.List)
+	r.Delete("/webhooks/{webhookID}", h.Delete)
+	r.Get("/webhooks/{webhookID}/deliveries", h.ListDeliveries)
+	return r
+}
+
+func TestWebhookHandler_Create(t *testing.T) {
+	const userID = int64(1)
+
+	tests := []struct {
+		name           string
+		body           string
+		setupMock      func(m *mocks.WebhookService)
+		expectedStatus int
+	}{
+		{
+			name: "Успешная регистрация",
+			body: `{"url": "https://example.com/hook", "secret": "s3cr3t"}`,
+			setupMock: func(m *mocks.WebhookService) {
+				m.EXPECT().CreateWebhook(mock.Anything, userID, "https://example.com/hook", "s3cr3t").
+					Return(&models.Webhook{ID: 5, UserID: userID, URL: "https://example.com/hook", Secret: "s3cr3t"}, nil).
+					Once()
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "Невалидный JSON",
+			body:           `{"url":`,
+			setupMock:      func(_ *mocks.WebhookService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Некорректный URL",
+			body: `{"url": "ftp://example.com"}`,
+			setupMock: func(m *mocks.WebhookService) {
+				m.EXPECT().CreateWebhook(mock.Anything, userID, "ftp://example.com", "").
+					Return(nil, services.ErrInvalidWebhookURL).Once()
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Внутренняя ошибка",
+			body: `{"url": "https://example.com/hook"}`,
+			setupMock: func(m *mocks.WebhookService) {
+				m.EXPECT().CreateWebhook(mock.Anything, userID, "https://example.com/hook", "").
+					Return(nil, errors.New("db error")).Once()
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := mocks.NewWebhookService(t)
+			tt.setupMock(mockService)
+			r := setupWebhookRouter(handlers.NewWebhookHandler(mockService), userID)
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(tt.body)))
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedStatus == http.StatusCreated {
+				var webhook models.Webhook
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &webhook))
+				assert.Equal(t, int64(5), webhook.ID)
+				assert.Equal(t, "s3cr3t", webhook.Secret, "Секрет возвращается при создании")
+			}
+		})
+	}
+}
+
+func TestWebhookHandler_List(t *testing.T) {
+	mockService := mocks.NewWebhookService(t)
+	mockService.EXPECT().ListWebhooks(mock.Anything, int64(1)).
+		Return([]models.Webhook{{ID: 5, UserID: 1, URL: "https://example.com/hook", IsActive: true}}, nil).Once()
+	r := setupWebhookRouter(handlers.NewWebhookHandler(mockService), 1)
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/webhooks", nil))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	var webhooks []models.Webhook
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &webhooks))
+	require.Len(t, webhooks, 1)
+	assert.NotContains(t, rr.Body.String(), "secret")
+}
+
+func TestWebhookHandler_Delete(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		setupMock      func(m *mocks.WebhookService)
+		expectedStatus int
+	}{
+		{
+			name: "Успешное удаление",
+			path: "/webhooks/5",
+			setupMock: func(m *mocks.WebhookService) {
+				m.EXPECT().DeleteWebhook(mock.Anything, int64(1), int64(5)).Return(nil).Once()
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name: "Вебхук не найден",
+			path: "/webhooks/6",
+			setupMock: func(m *mocks.WebhookService) {
+				m.EXPECT().DeleteWebhook(mock.Anything, int64(1), int64(6)).Return(services.ErrWebhookNotFound).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Неверный ID",
+			path:           "/webhooks/abc",
+			setupMock:      func(_ *mocks.WebhookService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := mocks.NewWebhookService(t)
+			tt.setupMock(mockService)
+			r := setupWebhookRouter(handlers.NewWebhookHandler(mockService), 1)
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, tt.path, nil))
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestWebhookHandler_ListDeliveries(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		setupMock      func(m *mocks.WebhookService)
+		expectedStatus int
+	}{
+		{
+			name: "Успешное получение истории",
+			path: "/webhooks/5/deliveries?limit=10&offset=20",
+			setupMock: func(m *mocks.WebhookService) {
+				m.EXPECT().ListDeliveries(mock.Anything, int64(1), int64(5), 10, 20).
+					Return([]models.WebhookDelivery{{ID: 10, WebhookID: 5, Status: models.WebhookDeliveryDelivered,
+						Payload: json.RawMessage(`{"type":"version_created"}`)}}, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Чужой вебхук",
+			path: "/webhooks/6/deliveries",
+			setupMock: func(m *mocks.WebhookService) {
+				m.EXPECT().ListDeliveries(mock.Anything, int64(1), int64(6), 20, 0).
+					Return(nil, services.ErrWebhookNotFound).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := mocks.NewWebhookService(t)
+			tt.setupMock(mockService)
+			r := setupWebhookRouter(handlers.NewWebhookHandler(mockService), 1)
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var deliveries []models.WebhookDelivery
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &deliveries))
+				require.Len(t, deliveries, 1)
+				assert.JSONEq(t, `{"type":"version_created"}`, string(deliveries[0].Payload))
+			}
+		})
+	}
+}
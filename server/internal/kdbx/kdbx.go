@@ -0,0 +1,276 @@
+// Package kdbx разбирает внешний заголовок файлов баз KeePass (KDBX 3.x и 4.x).
+//
+// Внешний заголовок не зашифрован, поэтому сервер может без мастер-пароля убедиться, что загружаемый
+// файл действительно является базой KeePass поддерживаемой версии, и узнать алгоритмы шифрования и
+// формирования ключа (KDF). Содержимое базы при этом не расшифровывается.
+package kdbx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrInvalidFormat возвращается, если данные не являются базой KeePass поддерживаемой версии
+// или ее заголовок поврежден.
+var ErrInvalidFormat = errors.New("файл не является базой KeePass (KDBX 3.x или 4.x)")
+
+// Сигнатуры файла (первые 8 байт, little-endian).
+const (
+	signature1     = 0x9AA2D903 // Общая для всех форматов KeePass
+	signature2KDBX = 0xB54BFB67 // KDBX (KeePass 2.x)
+	signature2KDB  = 0xB54BFB65 // KDB (KeePass 1.x) - не поддерживается
+)
+
+// Поддерживаемые основные версии формата.
+const (
+	majorVersion3 = 3
+	majorVersion4 = 4
+)
+
+const (
+	// MinFileSize - минимальный размер файла базы: заголовок с обязательными полями и минимальное
+	// зашифрованное содержимое. Файлы меньшего размера заведомо обрезаны.
+	MinFileSize = 256
+	// maxHeaderSize ограничивает размер заголовка, который читается в память.
+	maxHeaderSize = 1 << 20
+	// prefixSize - размер сигнатур и версии формата.
+	prefixSize = 12
+)
+
+// Минимальный размер данных после заголовка:
+// KDBX 4 - SHA-256 и HMAC заголовка (64 байта) и завершающий блок (HMAC и размер, 36 байт);
+// KDBX 3 - зашифрованные StreamStartBytes (32 байта).
+const (
+	minPayloadSize3 = 32
+	minPayloadSize4 = 64 + 36
+)
+
+// Идентификаторы полей внешнего заголовка.
+const (
+	fieldEndOfHeader  = 0
+	fieldCipherID     = 2
+	fieldKdfParams    = 11 // Только KDBX 4
+	cipherIDSize      = 16
+	kdfUUIDKey        = "$UUID"
+	variantMapVersion = 0x01 // Старший байт версии VariantMap
+	variantTypeEnd    = 0x00
+	variantTypeBytes  = 0x42
+)
+
+// Известные алгоритмы шифрования (PwUuid в порядке байтов файла).
+var cipherNames = map[string]string{ //nolint:gochecknoglobals // Неизменяемая таблица алгоритмов
+	"31c1f2e6bf714350be5805216afc5aff": "AES-256",
+	"d6038a2b8b6f4cb5a524339a31dbb59a": "ChaCha20",
+	"ad68f29f576f4bb9a36ad47af965346c": "Twofish",
+}
+
+// Известные алгоритмы формирования ключа (KDBX 4).
+var kdfNames = map[string]string{ //nolint:gochecknoglobals // Неизменяемая таблица алгоритмов
+	"c9d9f39a628a4460bf740d08c18a4fea": KDFAES,
+	"ef636ddf8c29444b91f7a9a403e30a0c": "Argon2d",
+	"9e298b1956db4773b23dfc3ec6f0a1e6": "Argon2id",
+}
+
+// KDFAES - название KDF на основе AES (единственный KDF в KDBX 3).
+const KDFAES = "AES-KDF"
+
+// Info - сведения о базе из внешнего заголовка.
+type Info struct {
+	MajorVersion uint16
+	MinorVersion uint16
+	// Cipher - название алгоритма шифрования или UUID в hex, если алгоритм неизвестен.
+	Cipher string
+	// KDF - название алгоритма формирования ключа или UUID в hex, если алгоритм неизвестен.
+	KDF string
+	// HeaderSize - размер внешнего заголовка вместе с сигнатурами, байт.
+	HeaderSize int64
+}
+
+// Version возвращает версию формата в виде "4.1".
+func (i *Info) Version() string {
+	return fmt.Sprintf("%d.%d", i.MajorVersion, i.MinorVersion)
+}
+
+// MinFileSize возвращает минимальный размер файла с таким заголовком.
+func (i *Info) MinFileSize() int64 {
+	if i.MajorVersion == majorVersion3 {
+		return i.HeaderSize + minPayloadSize3
+	}
+	return i.HeaderSize + minPayloadSize4
+}
+
+// ReadHeader читает из r внешний заголовок базы и возвращает сведения о ней и прочитанные байты
+// (чтобы передать файл дальше целиком, их нужно прочитать перед остатком r).
+// Ошибки формата оборачивают ErrInvalidFormat, прочие - ошибки чтения r.
+func ReadHeader(r io.Reader) (*Info, []byte, error) {
+	hr := &headerReader{r: r}
+
+	prefix, err := hr.read(prefixSize)
+	if err != nil {
+		return nil, hr.buf, err
+	}
+	sig1 := binary.LittleEndian.Uint32(prefix[0:4])
+	sig2 := binary.LittleEndian.Uint32(prefix[4:8])
+	switch {
+	case sig1 != signature1:
+		return nil, hr.buf, fmt.Errorf("%w: неверная сигнатура", ErrInvalidFormat)
+	case sig2 == signature2KDB:
+		return nil, hr.buf, fmt.Errorf("%w: формат KeePass 1.x (KDB) не поддерживается", ErrInvalidFormat)
+	case sig2 != signature2KDBX:
+		return nil, hr.buf, fmt.Errorf("%w: неверная сигнатура", ErrInvalidFormat)
+	}
+
+	info := &Info{
+		MinorVersion: binary.LittleEndian.Uint16(prefix[8:10]),
+		MajorVersion: binary.LittleEndian.Uint16(prefix[10:12]),
+	}
+	if info.MajorVersion != majorVersion3 && info.MajorVersion != majorVersion4 {
+		return nil, hr.buf, fmt.Errorf("%w: неподдерживаемая версия формата %s", ErrInvalidFormat, info.Version())
+	}
+	if info.MajorVersion == majorVersion3 {
+		// В KDBX 3 ключ всегда формируется многократным шифрованием AES
+		info.KDF = KDFAES
+	}
+
+	if err = hr.readFields(info); err != nil {
+		return nil, hr.buf, err
+	}
+	if info.Cipher == "" {
+		return nil, hr.buf, fmt.Errorf("%w: в заголовке нет алгоритма шифрования", ErrInvalidFormat)
+	}
+	if info.KDF == "" {
+		return nil, hr.buf, fmt.Errorf("%w: в заголовке нет параметров KDF", ErrInvalidFormat)
+	}
+	info.HeaderSize = int64(len(hr.buf))
+	return info, hr.buf, nil
+}
+
+// headerReader читает заголовок, сохраняя прочитанные байты.
+type headerReader struct {
+	r   io.Reader
+	buf []byte
+}
+
+// read читает ровно n байт. Обрыв данных внутри заголовка считается ошибкой формата.
+func (hr *headerReader) read(n int) ([]byte, error) {
+	if len(hr.buf)+n > maxHeaderSize {
+		return nil, fmt.Errorf("%w: заголовок больше %d байт", ErrInvalidFormat, maxHeaderSize)
+	}
+	start := len(hr.buf)
+	hr.buf = append(hr.buf, make([]byte, n)...)
+	if _, err := io.ReadFull(hr.r, hr.buf[start:]); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, fmt.Errorf("%w: файл обрывается внутри заголовка", ErrInvalidFormat)
+		}
+		return nil, fmt.Errorf("ошибка чтения заголовка KDBX: %w", err)
+	}
+	return hr.buf[start:], nil
+}
+
+// readFields читает поля заголовка до поля EndOfHeader и заполняет info.
+// Поле состоит из идентификатора (1 байт), длины (2 байта в KDBX 3, 4 байта в KDBX 4) и данных.
+func (hr *headerReader) readFields(info *Info) error {
+	lengthSize := 4
+	if info.MajorVersion == majorVersion3 {
+		lengthSize = 2
+	}
+	for {
+		head, err := hr.read(1 + lengthSize)
+		if err != nil {
+			return err
+		}
+		id := head[0]
+		var length int
+		if lengthSize == 2 {
+			length = int(binary.LittleEndian.Uint16(head[1:]))
+		} else {
+			length = int(binary.LittleEndian.Uint32(head[1:]))
+		}
+		if length > maxHeaderSize {
+			return fmt.Errorf("%w: поле заголовка %d слишком велико", ErrInvalidFormat, id)
+		}
+		data, err := hr.read(length)
+		if err != nil {
+			return err
+		}
+
+		switch id {
+		case fieldEndOfHeader:
+			return nil
+		case fieldCipherID:
+			if len(data) != cipherIDSize {
+				return fmt.Errorf("%w: неверная длина идентификатора шифра", ErrInvalidFormat)
+			}
+			info.Cipher = algorithmName(cipherNames, data)
+		case fieldKdfParams:
+			if info.MajorVersion != majorVersion4 {
+				continue
+			}
+			kdf, kdfErr := kdfFromParams(data)
+			if kdfErr != nil {
+				return kdfErr
+			}
+			info.KDF = kdf
+		}
+	}
+}
+
+// kdfFromParams находит UUID алгоритма в параметрах KDF (VariantMap).
+// Элемент VariantMap: тип (1 байт), длина ключа (4 байта), ключ, длина значения (4 байта), значение.
+func kdfFromParams(data []byte) (string, error) {
+	errParams := fmt.Errorf("%w: поврежденные параметры KDF", ErrInvalidFormat)
+	if len(data) < 2 || data[1] != variantMapVersion {
+		return "", errParams
+	}
+	rest := data[2:]
+	for len(rest) > 0 {
+		typ := rest[0]
+		if typ == variantTypeEnd {
+			break
+		}
+		key, tail, ok := lengthPrefixed(rest[1:])
+		if !ok {
+			return "", errParams
+		}
+		value, tail, ok := lengthPrefixed(tail)
+		if !ok {
+			return "", errParams
+		}
+		if string(key) == kdfUUIDKey && typ == variantTypeBytes && len(value) == cipherIDSize {
+			return algorithmName(kdfNames, value), nil
+		}
+		rest = tail
+	}
+	return "", fmt.Errorf("%w: в параметрах KDF нет UUID алгоритма", ErrInvalidFormat)
+}
+
+// lengthPrefixed отделяет от data значение с 4-байтной длиной.
+func lengthPrefixed(data []byte) ([]byte, []byte, bool) {
+	if len(data) < 4 {
+		return nil, nil, false
+	}
+	length := binary.LittleEndian.Uint32(data)
+	data = data[4:]
+	if uint64(length) > uint64(len(data)) {
+		return nil, nil, false
+	}
+	return data[:length], data[length:], true
+}
+
+// algorithmName возвращает название алгоритма по UUID или сам UUID в hex.
+func algorithmName(names map[string]string, uuid []byte) string {
+	id := hex.EncodeToString(uuid)
+	if name, ok := names[id]; ok {
+		return name
+	}
+	return id
+}
+
+// Reader возвращает поток с содержимым всего файла по прочитанному ReadHeader заголовку и остатку r.
+func Reader(header []byte, r io.Reader) io.Reader {
+	return io.MultiReader(bytes.NewReader(header), r)
+}
@@ -0,0 +1,108 @@
+package kdbx_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/maynagashev/gophkeeper/server/internal/kdbx"
+	"github.com/maynagashev/gophkeeper/server/internal/kdbx/kdbxtest"
+)
+
+func TestReadHeader(t *testing.T) {
+	t.Run("KDBX 4", func(t *testing.T) {
+		file := kdbxtest.File(4, 1024)
+		header := kdbxtest.Header(4)
+
+		info, read, err := kdbx.ReadHeader(bytes.NewReader(file))
+		require.NoError(t, err)
+		assert.Equal(t, "4.1", info.Version())
+		assert.Equal(t, "AES-256", info.Cipher)
+		assert.Equal(t, "Argon2id", info.KDF)
+		assert.Equal(t, int64(len(header)), info.HeaderSize)
+		assert.Equal(t, info.HeaderSize+100, info.MinFileSize())
+		assert.Equal(t, header, read)
+	})
+
+	t.Run("KDBX 3", func(t *testing.T) {
+		info, _, err := kdbx.ReadHeader(bytes.NewReader(kdbxtest.File(3, 1024)))
+		require.NoError(t, err)
+		assert.Equal(t, "3.1", info.Version())
+		assert.Equal(t, "AES-256", info.Cipher)
+		assert.Equal(t, kdbx.KDFAES, info.KDF)
+		assert.Equal(t, info.HeaderSize+32, info.MinFileSize())
+	})
+
+	t.Run("Reader возвращает файл целиком", func(t *testing.T) {
+		file := kdbxtest.File(4, 1024)
+		r := bytes.NewReader(file)
+		_, header, err := kdbx.ReadHeader(r)
+		require.NoError(t, err)
+
+		got, err := io.ReadAll(kdbx.Reader(header, r))
+		require.NoError(t, err)
+		assert.Equal(t, file, got)
+	})
+
+	t.Run("Неизвестный шифр возвращается как UUID", func(t *testing.T) {
+		file := kdbxtest.File(4, 1024)
+		// Поле шифра следует сразу за сигнатурами: идентификатор (1 байт) и длина (4 байта)
+		copy(file[12+5:], bytes.Repeat([]byte{0xAB}, 16))
+
+		info, _, err := kdbx.ReadHeader(bytes.NewReader(file))
+		require.NoError(t, err)
+		assert.Equal(t, strings.Repeat("ab", 16), info.Cipher)
+	})
+
+	invalid := []struct {
+		name string
+		data func() []byte
+	}{
+		{name: "Пустой файл", data: func() []byte { return nil }},
+		{name: "Не KeePass", data: func() []byte { return bytes.Repeat([]byte("not a kdbx "), 50) }},
+		{name: "KeePass 1.x", data: func() []byte {
+			file := kdbxtest.File(4, 1024)
+			binary.LittleEndian.PutUint32(file[4:], 0xB54BFB65)
+			return file
+		}},
+		{name: "Неподдерживаемая версия", data: func() []byte {
+			file := kdbxtest.File(4, 1024)
+			binary.LittleEndian.PutUint16(file[10:], 5)
+			return file
+		}},
+		{name: "Обрезанный заголовок", data: func() []byte { return kdbxtest.Header(4)[:40] }},
+		{name: "Поврежденные параметры KDF", data: func() []byte {
+			header := kdbxtest.Header(4)
+			// Параметры KDF - предпоследнее поле; портим версию VariantMap
+			i := bytes.Index(header, []byte{0x00, 0x01, 0x42})
+			require.Positive(t, i)
+			header[i+1] = 0x02
+			return header
+		}},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := kdbx.ReadHeader(bytes.NewReader(tt.data()))
+			require.ErrorIs(t, err, kdbx.ErrInvalidFormat)
+		})
+	}
+
+	t.Run("Ошибка чтения не считается ошибкой формата", func(t *testing.T) {
+		readErr := errors.New("соединение разорвано")
+		r := io.MultiReader(bytes.NewReader(kdbxtest.Header(4)[:20]), &failingReader{err: readErr})
+
+		_, _, err := kdbx.ReadHeader(r)
+		require.ErrorIs(t, err, readErr)
+		assert.NotErrorIs(t, err, kdbx.ErrInvalidFormat)
+	})
+}
+
+type failingReader struct{ err error }
+
+func (r *failingReader) Read([]byte) (int, error) { return 0, r.err }
@@ -0,0 +1,87 @@
+// Package kdbxtest формирует файлы с корректным внешним заголовком KDBX для тестов.
+// Содержимое после заголовка не зашифровано и заполнено нулями: разбору заголовка оно не нужно.
+package kdbxtest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+)
+
+// UUID алгоритмов в порядке байтов файла.
+const (
+	CipherAES256 = "31c1f2e6bf714350be5805216afc5aff"
+	KDFArgon2id  = "9e298b1956db4773b23dfc3ec6f0a1e6"
+)
+
+// Header возвращает внешний заголовок KDBX версии major.1 (3 или 4) с шифром AES-256
+// и KDF Argon2id (для KDBX 3 - AES-KDF).
+func Header(major uint16) []byte {
+	var buf bytes.Buffer
+	write := func(v any) { _ = binary.Write(&buf, binary.LittleEndian, v) }
+	write(uint32(0x9AA2D903))
+	write(uint32(0xB54BFB67))
+	write(uint16(1)) // Младшая версия
+	write(major)
+
+	field := func(id byte, data []byte) {
+		buf.WriteByte(id)
+		if major == 3 {
+			write(uint16(len(data)))
+		} else {
+			write(uint32(len(data)))
+		}
+		buf.Write(data)
+	}
+	field(2, mustHex(CipherAES256))
+	field(3, []byte{1, 0, 0, 0}) // Сжатие GZip
+	field(4, make([]byte, 32))   // MasterSeed
+	if major == 3 {
+		field(5, make([]byte, 32))               // TransformSeed
+		field(6, []byte{0, 0, 1, 0, 0, 0, 0, 0}) // TransformRounds
+		field(7, make([]byte, 16))               // EncryptionIV
+		field(8, make([]byte, 32))               // ProtectedStreamKey
+		field(9, make([]byte, 32))               // StreamStartBytes
+		field(10, []byte{2, 0, 0, 0})            // InnerRandomStreamID
+	} else {
+		field(7, make([]byte, 16)) // EncryptionIV
+		field(11, kdfParams())
+	}
+	field(0, []byte("\r\n\r\n"))
+	return buf.Bytes()
+}
+
+// File возвращает файл размером size (не меньше заголовка) с заголовком Header(major).
+func File(major uint16, size int) []byte {
+	data := Header(major)
+	if size > len(data) {
+		data = append(data, make([]byte, size-len(data))...)
+	}
+	return data
+}
+
+// kdfParams формирует параметры KDF (VariantMap) с UUID Argon2id и числом итераций.
+func kdfParams() []byte {
+	var buf bytes.Buffer
+	write := func(v any) { _ = binary.Write(&buf, binary.LittleEndian, v) }
+	item := func(typ byte, key string, value []byte) {
+		buf.WriteByte(typ)
+		write(uint32(len(key)))
+		buf.WriteString(key)
+		write(uint32(len(value)))
+		buf.Write(value)
+	}
+	write(uint16(0x0100))
+	item(0x42, "$UUID", mustHex(KDFArgon2id))
+	item(0x05, "I", []byte{2, 0, 0, 0, 0, 0, 0, 0})
+	buf.WriteByte(0)
+	return buf.Bytes()
+}
+
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
@@ -0,0 +1,146 @@
+// Package metrics собирает показатели работы сервера в формате Prometheus.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace - префикс имен всех метрик сервера.
+const namespace = "gophkeeper"
+
+// Направления передачи файлов хранилищ.
+const (
+	directionUpload   = "upload"
+	directionDownload = "download"
+)
+
+// Metrics хранит метрики сервера и реестр, через который они отдаются на /metrics.
+// Реализует services.VaultMetrics, services.AuthMetrics и middleware.HTTPMetrics.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests     *prometheus.CounterVec
+	httpDuration     *prometheus.HistogramVec
+	transferBytes    *prometheus.CounterVec
+	transferDuration *prometheus.HistogramVec
+	versionsCreated  *prometheus.CounterVec
+	storageErrors    *prometheus.CounterVec
+	logins           *prometheus.CounterVec
+}
+
+// New создает метрики сервера в собственном реестре (вместе со стандартными метриками Go и процесса).
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "Количество HTTP-запросов по методу, маршруту и статусу ответа.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Время обработки HTTP-запросов по методу и маршруту.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		transferBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "vault",
+			Name:      "transfer_bytes_total",
+			Help:      "Объем загруженных и скачанных файлов хранилищ в байтах.",
+		}, []string{"direction"}),
+		transferDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "vault",
+			Name:      "transfer_duration_seconds",
+			Help:      "Время передачи файлов хранилищ в хранилище объектов и из него.",
+			Buckets:   prometheus.ExponentialBuckets(0.01, 2, 15), //nolint:mnd // От 10 мс до ~3 мин
+		}, []string{"direction"}),
+		versionsCreated: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "vault",
+			Name:      "versions_created_total",
+			Help:      "Количество созданных версий хранилищ по причине (загрузка или откат).",
+		}, []string{"reason"}),
+		storageErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "storage",
+			Name:      "errors_total",
+			Help:      "Количество ошибок хранилища объектов по операции и типу ошибки.",
+		}, []string{"operation", "kind"}),
+		logins: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "auth",
+			Name:      "logins_total",
+			Help:      "Количество попыток входа по результату.",
+		}, []string{"result"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.transferBytes,
+		m.transferDuration,
+		m.versionsCreated,
+		m.storageErrors,
+		m.logins,
+	)
+	return m
+}
+
+// RegisterDBStats добавляет метрики пула соединений с БД (sql.DB.Stats()).
+func (m *Metrics) RegisterDBStats(db *sql.DB) {
+	m.registry.MustRegister(collectors.NewDBStatsCollector(db, namespace))
+}
+
+// Handler возвращает обработчик, отдающий метрики в текстовом формате Prometheus.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// ObserveHTTPRequest учитывает обработанный HTTP-запрос.
+func (m *Metrics) ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	m.httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	m.httpDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+}
+
+// ObserveUpload учитывает файл, загруженный в хранилище объектов.
+func (m *Metrics) ObserveUpload(sizeBytes int64, duration time.Duration) {
+	m.observeTransfer(directionUpload, sizeBytes, duration)
+}
+
+// ObserveDownload учитывает файл (или его часть), отданный клиенту.
+func (m *Metrics) ObserveDownload(sizeBytes int64, duration time.Duration) {
+	m.observeTransfer(directionDownload, sizeBytes, duration)
+}
+
+func (m *Metrics) observeTransfer(direction string, sizeBytes int64, duration time.Duration) {
+	m.transferBytes.WithLabelValues(direction).Add(float64(sizeBytes))
+	m.transferDuration.WithLabelValues(direction).Observe(duration.Seconds())
+}
+
+// VersionCreated учитывает созданную версию хранилища.
+func (m *Metrics) VersionCreated(reason string) {
+	m.versionsCreated.WithLabelValues(reason).Inc()
+}
+
+// StorageError учитывает ошибку хранилища объектов.
+func (m *Metrics) StorageError(operation, kind string) {
+	m.storageErrors.WithLabelValues(operation, kind).Inc()
+}
+
+// LoginAttempt учитывает попытку входа.
+func (m *Metrics) LoginAttempt(result string) {
+	m.logins.WithLabelValues(result).Inc()
+}
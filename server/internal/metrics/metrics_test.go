@@ -0,0 +1,60 @@
+package metrics_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/maynagashev/gophkeeper/server/internal/metrics"
+)
+
+// scrape возвращает текст метрик, отдаваемый обработчиком /metrics.
+func scrape(t *testing.T, m *metrics.Metrics) string {
+	t.Helper()
+	rr := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	body, err := io.ReadAll(rr.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestMetrics_Handler(t *testing.T) {
+	m := metrics.New()
+	m.ObserveHTTPRequest(http.MethodGet, "/api/vault/", http.StatusOK, 20*time.Millisecond)
+	m.ObserveUpload(1024, time.Second)
+	m.ObserveDownload(512, time.Second)
+	m.VersionCreated("upload")
+	m.StorageError("download", "not_found")
+	m.LoginAttempt("success")
+	m.LoginAttempt("invalid_credentials")
+
+	body := scrape(t, m)
+	assert.Contains(t, body, `gophkeeper_http_requests_total{method="GET",route="/api/vault/",status="200"} 1`)
+	assert.Contains(t, body, `gophkeeper_http_request_duration_seconds_count{method="GET",route="/api/vault/"} 1`)
+	assert.Contains(t, body, `gophkeeper_vault_transfer_bytes_total{direction="upload"} 1024`)
+	assert.Contains(t, body, `gophkeeper_vault_transfer_bytes_total{direction="download"} 512`)
+	assert.Contains(t, body, `gophkeeper_vault_transfer_duration_seconds_count{direction="upload"} 1`)
+	assert.Contains(t, body, `gophkeeper_vault_versions_created_total{reason="upload"} 1`)
+	assert.Contains(t, body, `gophkeeper_storage_errors_total{kind="not_found",operation="download"} 1`)
+	assert.Contains(t, body, `gophkeeper_auth_logins_total{result="success"} 1`)
+	assert.Contains(t, body, `gophkeeper_auth_logins_total{result="invalid_credentials"} 1`)
+	assert.Contains(t, body, "go_goroutines")
+}
+
+func TestMetrics_RegisterDBStats(t *testing.T) {
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	m := metrics.New()
+	m.RegisterDBStats(db)
+
+	assert.Contains(t, scrape(t, m), `go_sql_open_connections{db_name="gophkeeper"}`)
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"log"
+	"net/http"
+
+	"github.com/maynagashev/gophkeeper/models"
+)
+
+// AdminTokenHeader - заголовок, в котором передается токен администратора.
+const AdminTokenHeader = "X-Admin-Token"
+
+// AdminTokenAuthenticator проверяет токен администратора для служебных маршрутов.
+// Токен сравнивается за постоянное время, чтобы не раскрывать его по времени ответа.
+func AdminTokenAuthenticator(adminToken string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			provided := r.Header.Get(AdminTokenHeader)
+			if adminToken == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(adminToken)) != 1 {
+				log.Printf("[AdminMiddleware] Отклонен запрос к %s: неверный токен администратора", r.URL.Path)
+				WriteError(w, r, http.StatusForbidden, models.ErrCodeForbidden, "Доступ запрещен")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireRole пропускает только запросы пользователей с ролью role (текущей, из БД).
+// Должен применяться после JWTAuthenticator; остальным отвечает 403 Forbidden.
+func RequireRole(role string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if GetUserRoleFromContext(r.Context()) != role {
+				userID, _ := GetUserIDFromContext(r.Context())
+				log.Printf("[AdminMiddleware] Отклонен запрос пользователя %d к %s: требуется роль %s",
+					userID, r.URL.Path, role)
+				WriteError(w, r, http.StatusForbidden, models.ErrCodeForbidden, "Доступ запрещен")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
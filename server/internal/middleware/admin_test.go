@@ -0,0 +1,82 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/maynagashev/gophkeeper/server/internal/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminTokenAuthenticator(t *testing.T) {
+	tests := []struct {
+		name           string
+		configured     string
+		provided       string
+		expectedStatus int
+	}{
+		{name: "Верный токен", configured: "secret", provided: "secret", expectedStatus: http.StatusOK},
+		{name: "Неверный токен", configured: "secret", provided: "wrong", expectedStatus: http.StatusForbidden},
+		{name: "Токен не передан", configured: "secret", provided: "", expectedStatus: http.StatusForbidden},
+		{name: "Токен не настроен", configured: "", provided: "", expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+			handler := middleware.AdminTokenAuthenticator(tt.configured)(next)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/admin/storage/integrity", nil)
+			if tt.provided != "" {
+				req.Header.Set(middleware.AdminTokenHeader, tt.provided)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	tests := []struct {
+		name           string
+		ctx            context.Context
+		expectedStatus int
+	}{
+		{
+			name:           "Администратор",
+			ctx:            context.WithValue(context.Background(), middleware.UserRoleKey, "admin"),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Обычный пользователь",
+			ctx:            context.WithValue(context.Background(), middleware.UserRoleKey, "user"),
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Роль не указана",
+			ctx:            context.Background(),
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+			handler := middleware.RequireRole("admin")(next)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/admin/users", nil).WithContext(tt.ctx)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/maynagashev/gophkeeper/models"
+)
+
+// apiVersionKey - ключ контекста с признаком запроса к API v2.
+const apiVersionKey contextKey = "apiV2"
+
+// APIv2 отмечает запросы к /api/v2: ответы об ошибках для них отправляются в формате
+// models.ErrorResponse вместо текста. Обработчики API v1 и v2 общие.
+func APIv2(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), apiVersionKey, true)))
+	})
+}
+
+// IsAPIv2 проверяет, относится ли запрос к API v2.
+func IsAPIv2(r *http.Request) bool {
+	v2, _ := r.Context().Value(apiVersionKey).(bool)
+	return v2
+}
+
+// WriteError отправляет ответ об ошибке: JSON models.ErrorResponse с кодом code для API v2
+// и текст message (как http.Error) для API v1.
+func WriteError(w http.ResponseWriter, r *http.Request, status int, code, message string) {
+	WriteErrorDetails(w, r, status, code, message, nil)
+}
+
+// WriteErrorDetails отправляет ответ об ошибке с дополнительными сведениями details (только для API v2).
+func WriteErrorDetails(
+	w http.ResponseWriter,
+	r *http.Request,
+	status int,
+	code, message string,
+	details map[string]any,
+) {
+	if !IsAPIv2(r) {
+		http.Error(w, message, status)
+		return
+	}
+
+	resp := models.ErrorResponse{
+		Code:      code,
+		Message:   message,
+		Details:   details,
+		RequestID: chimiddleware.GetReqID(r.Context()),
+	}
+	h := w.Header()
+	h.Del("Content-Length")
+	h.Set("Content-Type", "application/json; charset=utf-8")
+	h.Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("[APIError] Ошибка кодирования ответа об ошибке: %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/maynagashev/gophkeeper/models"
+	"github.com/maynagashev/gophkeeper/server/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteError(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		middleware.WriteErrorDetails(w, r, http.StatusBadRequest, models.ErrCodeInvalidRequest,
+			"Неверный формат запроса", map[string]any{"header": "Content-Length"})
+	})
+
+	t.Run("API v1 отвечает текстом", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/vault", nil))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, "Неверный формат запроса\n", rr.Body.String())
+		assert.Contains(t, rr.Header().Get("Content-Type"), "text/plain")
+	})
+
+	t.Run("API v2 отвечает JSON с кодом и ID запроса", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/v2/vault", nil)
+		req.Header.Set(chimiddleware.RequestIDHeader, "req-42")
+		chimiddleware.RequestID(middleware.APIv2(handler)).ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Contains(t, rr.Header().Get("Content-Type"), "application/json")
+		var resp models.ErrorResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, models.ErrorResponse{
+			Code:      models.ErrCodeInvalidRequest,
+			Message:   "Неверный формат запроса",
+			Details:   map[string]any{"header": "Content-Length"},
+			RequestID: "req-42",
+		}, resp)
+	})
+
+	t.Run("Ошибки middleware API v2 тоже в формате JSON", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		next := http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+			t.Error("обработчик не должен вызываться без токена")
+		})
+		middleware.APIv2(middleware.JWTAuthenticator("secret", nil)(next)).
+			ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v2/vault", nil))
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		var resp models.ErrorResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, models.ErrCodeUnauthorized, resp.Code)
+	})
+}
@@ -2,37 +2,52 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"strings"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/maynagashev/gophkeeper/models"
 )
 
 // Тип для ключа контекста.
 type contextKey string
 
-// Ключ для хранения ID пользователя в контексте.
-const UserIDKey contextKey = "userID"
-
-// TODO: Вынести секретный ключ в конфигурацию/переменные окружения! (Дублируется с services)
-const jwtSecretKey = "your-very-secret-key"
+// Ключи для хранения ID и роли пользователя в контексте.
+const (
+	UserIDKey   contextKey = "userID"
+	UserRoleKey contextKey = "userRole"
+)
 
 // Структура для пользовательских данных в JWT (claims) - должна совпадать с той, что в services.
 type jwtClaims struct {
-	UserID int64 `json:"user_id"`
+	UserID int64  `json:"user_id"`
+	Role   string `json:"role"`
 	jwt.RegisteredClaims
 }
 
-// Authenticator проверяет JWT токен аутентификации.
-func Authenticator(next http.Handler) http.Handler {
+// AccountLookup возвращает актуальное состояние учетной записи пользователя из БД.
+// Если пользователь удален, возвращает ошибку, содержащую ErrAccountNotFound.
+type AccountLookup func(ctx context.Context, userID int64) (*models.User, error)
+
+// JWTAuthenticator проверяет JWT токен аутентификации, подписанный ключом secret
+// (тем же, что использует сервис аутентификации при выдаче токенов), и учетную запись его владельца.
+func JWTAuthenticator(secret string, accounts AccountLookup) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return authenticate(next, []byte(secret), accounts)
+	}
+}
+
+// authenticate возвращает обработчик, проверяющий токен перед вызовом next.
+func authenticate(next http.Handler, secret []byte, accounts AccountLookup) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Получаем заголовок Authorization
 		authHeader := r.Header.Get("Authorization")
 		if authHeader == "" {
 			log.Println("[AuthMiddleware] Заголовок Authorization отсутствует")
-			http.Error(w, "Требуется аутентификация", http.StatusUnauthorized)
+			WriteError(w, r, http.StatusUnauthorized, models.ErrCodeUnauthorized, "Требуется аутентификация")
 			return
 		}
 
@@ -40,50 +55,110 @@ func Authenticator(next http.Handler) http.Handler {
 		headerParts := strings.Split(authHeader, " ")
 		if len(headerParts) != 2 || strings.ToLower(headerParts[0]) != "bearer" {
 			log.Printf("[AuthMiddleware] Неверный формат заголовка Authorization: %s", authHeader)
-			http.Error(w, "Неверный формат токена", http.StatusUnauthorized)
+			WriteError(w, r, http.StatusUnauthorized, models.ErrCodeUnauthorized, "Неверный формат токена")
 			return
 		}
 
-		tokenString := headerParts[1]
-
-		// Парсим и валидируем токен
-		claims := &jwtClaims{}
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			// Убеждаемся, что метод подписи - HS256
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("неожиданный метод подписи: %v", token.Header["alg"])
-			}
-			// Возвращаем секретный ключ
-			return []byte(jwtSecretKey), nil
-		})
-
-		if err != nil {
-			log.Printf("[AuthMiddleware] Ошибка парсинга/валидации токена: %v", err)
-			http.Error(w, "Невалидный токен", http.StatusUnauthorized)
+		ctx, err := AuthenticateToken(r.Context(), headerParts[1], secret, accounts)
+		switch {
+		case err == nil:
+		case errors.Is(err, ErrInvalidToken):
+			WriteError(w, r, http.StatusUnauthorized, models.ErrCodeUnauthorized, "Невалидный токен")
 			return
-		}
-
-		// Проверяем валидность токена (включая время жизни, issuer и т.д.)
-		if !token.Valid {
-			log.Println("[AuthMiddleware] Предоставлен невалидный токен (возможно, истек)")
-			http.Error(w, "Невалидный токен", http.StatusUnauthorized)
+		case errors.Is(err, ErrAccountDisabled):
+			WriteError(w, r, http.StatusForbidden, models.ErrCodeAccountDisabled, err.Error())
+			return
+		default:
+			log.Printf("[AuthMiddleware] Ошибка проверки учетной записи: %v", err)
+			WriteError(w, r, http.StatusInternalServerError, models.ErrCodeInternal, "Внутренняя ошибка сервера")
 			return
 		}
 
-		// Добавляем UserID в контекст запроса
-		ctx := context.WithValue(r.Context(), UserIDKey, claims.UserID)
-
 		// Логируем успешную аутентификацию
-		log.Printf("[AuthMiddleware] Пользователь %d успешно аутентифицирован", claims.UserID)
+		userID, _ := GetUserIDFromContext(ctx)
+		log.Printf("[AuthMiddleware] Пользователь %d успешно аутентифицирован", userID)
 
 		// Передаем управление следующему обработчику с обновленным контекстом
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
+// AuthenticateToken проверяет JWT, подписанный ключом secret, и возвращает контекст с ID и ролью пользователя.
+// Токен действует до истечения срока, поэтому учетная запись каждый раз загружается через accounts:
+// отключенный пользователь получает ErrAccountDisabled, удаленный - ErrInvalidToken, а роль берется из БД,
+// а не из токена, чтобы понижение роли действовало сразу. Используется HTTP middleware, WebDAV и перехватчиками gRPC.
+func AuthenticateToken(
+	ctx context.Context,
+	tokenString string,
+	secret []byte,
+	accounts AccountLookup,
+) (context.Context, error) {
+	// Парсим и валидируем токен
+	claims := &jwtClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		// Убеждаемся, что метод подписи - HS256
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("неожиданный метод подписи: %v", token.Header["alg"])
+		}
+		// Возвращаем секретный ключ
+		return secret, nil
+	})
+
+	if err != nil {
+		log.Printf("[AuthMiddleware] Ошибка парсинга/валидации токена: %v", err)
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	// Проверяем валидность токена (включая время жизни, issuer и т.д.)
+	if !token.Valid {
+		log.Println("[AuthMiddleware] Предоставлен невалидный токен (возможно, истек)")
+		return nil, ErrInvalidToken
+	}
+
+	user, err := accounts(ctx, claims.UserID)
+	if err != nil {
+		if errors.Is(err, ErrAccountNotFound) {
+			log.Printf("[AuthMiddleware] Пользователь %d из токена не найден", claims.UserID)
+			return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+		}
+		return nil, fmt.Errorf("ошибка загрузки пользователя %d: %w", claims.UserID, err)
+	}
+	if user.DisabledAt != nil {
+		log.Printf("[AuthMiddleware] Учетная запись пользователя %d отключена", claims.UserID)
+		return nil, ErrAccountDisabled
+	}
+
+	// Добавляем UserID и текущую роль в контекст запроса
+	return ContextWithUser(ctx, claims.UserID, user.Role), nil
+}
+
+// ContextWithUser возвращает контекст с ID и ролью аутентифицированного пользователя.
+// Нужен обработчикам, которые проверяют учетные данные без JWT (HTTP Basic в WebDAV).
+func ContextWithUser(ctx context.Context, userID int64, role string) context.Context {
+	ctx = context.WithValue(ctx, UserIDKey, userID)
+	return context.WithValue(ctx, UserRoleKey, role)
+}
+
 // GetUserIDFromContext извлекает UserID из контекста запроса.
 // Возвращает ID пользователя и true, если ID найден, иначе 0 и false.
 func GetUserIDFromContext(ctx context.Context) (int64, bool) {
 	userID, ok := ctx.Value(UserIDKey).(int64)
 	return userID, ok
 }
+
+// GetUserRoleFromContext извлекает роль пользователя из контекста запроса.
+// Возвращает пустую строку, если роль не найдена (например, в токене, выданном до появления ролей).
+func GetUserRoleFromContext(ctx context.Context) string {
+	role, _ := ctx.Value(UserRoleKey).(string)
+	return role
+}
+
+// Ошибки проверки токена.
+var (
+	// ErrInvalidToken - токен не прошел проверку подписи или срока действия либо его владелец удален.
+	ErrInvalidToken = errors.New("невалидный токен")
+	// ErrAccountDisabled - учетная запись владельца токена отключена администратором.
+	ErrAccountDisabled = errors.New("учетная запись отключена администратором")
+	// ErrAccountNotFound возвращается AccountLookup, если пользователя с ID из токена нет.
+	ErrAccountNotFound = errors.New("пользователь не найден")
+)
@@ -2,6 +2,7 @@ package middleware_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -10,19 +11,43 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/maynagashev/gophkeeper/models"
 	"github.com/maynagashev/gophkeeper/server/internal/middleware"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
-// TODO: Вынести секретный ключ в общее место или передавать в Authenticator.
-const jwtSecretKey = "your-very-secret-key"
+// Ключ подписи, с которым создается проверяемый middleware.
+const jwtSecretKey = "test-jwt-secret"
 
 type jwtClaims struct {
-	UserID int64 `json:"user_id"`
+	UserID int64  `json:"user_id"`
+	Role   string `json:"role"`
 	jwt.RegisteredClaims
 }
 
+// Пользователи, которых находит testAccounts.
+const (
+	disabledUserID = 13  // Учетная запись отключена
+	deletedUserID  = 404 // Пользователь удален
+	brokenUserID   = 500 // Ошибка БД при загрузке
+)
+
+// testAccounts находит любого пользователя активным с ролью user, кроме отдельных ID (см. константы выше).
+func testAccounts(_ context.Context, userID int64) (*models.User, error) {
+	switch userID {
+	case disabledUserID:
+		disabledAt := time.Now()
+		return &models.User{ID: userID, Role: models.RoleUser, DisabledAt: &disabledAt}, nil
+	case deletedUserID:
+		return nil, middleware.ErrAccountNotFound
+	case brokenUserID:
+		return nil, errors.New("connection refused")
+	default:
+		return &models.User{ID: userID, Role: models.RoleUser}, nil
+	}
+}
+
 func TestGetUserIDFromContext(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -76,6 +101,7 @@ func TestGetUserIDFromContext(t *testing.T) {
 func generateTestToken(userID int64, secretKey string, expiresAt time.Time) (string, error) {
 	claims := jwtClaims{
 		UserID: userID,
+		Role:   "user",
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -92,13 +118,14 @@ func TestAuthenticator(t *testing.T) {
 	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		userID, ok := middleware.GetUserIDFromContext(r.Context())
 		assert.True(t, ok, "UserID должен быть в контексте")
+		assert.Equal(t, "user", middleware.GetUserRoleFromContext(r.Context()), "Роль должна быть в контексте")
 		assert.NotEqual(t, int64(0), userID, "UserID не должен быть 0")
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write([]byte(fmt.Sprintf("OK for user %d", userID)))
 	})
 
 	// Оборачиваем обработчик в middleware
-	authMiddleware := middleware.Authenticator(nextHandler)
+	authMiddleware := middleware.JWTAuthenticator(jwtSecretKey, testAccounts)(nextHandler)
 
 	// Создаем тестовый сервер
 	server := httptest.NewServer(authMiddleware)
@@ -158,6 +185,24 @@ func TestAuthenticator(t *testing.T) {
 			expectedStatus: http.StatusUnauthorized,
 			expectedBody:   "Невалидный токен",
 		},
+		{
+			name:           "Учетная запись отключена после выдачи токена",
+			header:         generateAuthHeader(t, disabledUserID, jwtSecretKey, time.Now().Add(time.Hour)),
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   "учетная запись отключена",
+		},
+		{
+			name:           "Пользователь удален после выдачи токена",
+			header:         generateAuthHeader(t, deletedUserID, jwtSecretKey, time.Now().Add(time.Hour)),
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   "Невалидный токен",
+		},
+		{
+			name:           "Ошибка загрузки пользователя",
+			header:         generateAuthHeader(t, brokenUserID, jwtSecretKey, time.Now().Add(time.Hour)),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Внутренняя ошибка сервера",
+		},
 	}
 
 	for _, tt := range tests {
@@ -197,3 +242,54 @@ func generateTestTokenOnly(t *testing.T, userID int64, secretKey string, expires
 	require.NoError(t, err, "Ошибка генерации тестового токена")
 	return token
 }
+
+func TestAuthenticateToken(t *testing.T) {
+	t.Run("Валидный токен", func(t *testing.T) {
+		token := generateTestTokenOnly(t, 42, jwtSecretKey, time.Now().Add(time.Hour))
+
+		ctx, err := middleware.AuthenticateToken(context.Background(), token, []byte(jwtSecretKey), testAccounts)
+
+		require.NoError(t, err)
+		userID, ok := middleware.GetUserIDFromContext(ctx)
+		assert.True(t, ok)
+		assert.Equal(t, int64(42), userID)
+	})
+
+	t.Run("Истекший токен", func(t *testing.T) {
+		token := generateTestTokenOnly(t, 42, jwtSecretKey, time.Now().Add(-time.Hour))
+
+		_, err := middleware.AuthenticateToken(context.Background(), token, []byte(jwtSecretKey), testAccounts)
+
+		require.ErrorIs(t, err, middleware.ErrInvalidToken)
+	})
+
+	t.Run("Чужой ключ подписи", func(t *testing.T) {
+		token := generateTestTokenOnly(t, 42, "other-secret", time.Now().Add(time.Hour))
+
+		_, err := middleware.AuthenticateToken(context.Background(), token, []byte(jwtSecretKey), testAccounts)
+
+		require.ErrorIs(t, err, middleware.ErrInvalidToken)
+	})
+	t.Run("Роль берется из БД, а не из токена", func(t *testing.T) {
+		claims := jwtClaims{
+			UserID:           42,
+			Role:             models.RoleAdmin,
+			RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+		}
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(jwtSecretKey))
+		require.NoError(t, err)
+
+		ctx, err := middleware.AuthenticateToken(context.Background(), token, []byte(jwtSecretKey), testAccounts)
+
+		require.NoError(t, err)
+		assert.Equal(t, models.RoleUser, middleware.GetUserRoleFromContext(ctx), "Администратор понижен после входа")
+	})
+
+	t.Run("Отключенная учетная запись", func(t *testing.T) {
+		token := generateTestTokenOnly(t, disabledUserID, jwtSecretKey, time.Now().Add(time.Hour))
+
+		_, err := middleware.AuthenticateToken(context.Background(), token, []byte(jwtSecretKey), testAccounts)
+
+		require.ErrorIs(t, err, middleware.ErrAccountDisabled)
+	})
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/maynagashev/gophkeeper/models"
+)
+
+// MaxBodySize ограничивает размер тела запроса limit байтами (0 - без ограничения).
+// Запрос с заявленным Content-Length больше лимита отклоняется сразу, не дожидаясь передачи тела;
+// чтение сверх лимита (если длина не заявлена или неверна) прерывается http.MaxBytesReader.
+func MaxBodySize(limit int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if limit <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > limit {
+				log.Printf("[LimitMiddleware] Отклонен запрос к %s: размер тела %d превышает лимит %d",
+					r.URL.Path, r.ContentLength, limit)
+				WriteErrorDetails(w, r, http.StatusRequestEntityTooLarge, models.ErrCodePayloadTooLarge,
+					fmt.Sprintf("Размер запроса превышает допустимый (%d байт)", limit),
+					map[string]any{"max_size": limit})
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
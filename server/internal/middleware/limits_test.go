@@ -0,0 +1,63 @@
+package middleware_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/maynagashev/gophkeeper/server/internal/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaxBodySize(t *testing.T) {
+	// Обработчик читает тело целиком и сообщает, удалось ли уложиться в лимит
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.ReadAll(r.Body); err != nil {
+			var maxErr *http.MaxBytesError
+			if errors.As(err, &maxErr) {
+				w.WriteHeader(http.StatusRequestEntityTooLarge)
+				return
+			}
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name           string
+		limit          int64
+		body           string
+		hideLength     bool // Не заявлять Content-Length
+		expectedStatus int
+	}{
+		{name: "Тело в пределах лимита", limit: 10, body: "0123456789", expectedStatus: http.StatusOK},
+		{
+			name: "Заявленный размер больше лимита", limit: 10, body: "0123456789A",
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name: "Тело без заявленного размера больше лимита", limit: 10, body: "0123456789A", hideLength: true,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+		{name: "Без ограничения", limit: 0, body: strings.Repeat("x", 1024), expectedStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body io.Reader = strings.NewReader(tt.body)
+			if tt.hideLength {
+				body = io.MultiReader(body) // Длина тела неизвестна httptest.NewRequest
+			}
+			req := httptest.NewRequest(http.MethodPost, "/api/vault/upload", body)
+			rr := httptest.NewRecorder()
+
+			middleware.MaxBodySize(tt.limit)(next).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
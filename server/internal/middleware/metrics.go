@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+)
+
+// unmatchedRoute - метка маршрута для запросов, не совпавших ни с одним маршрутом.
+// Путь запроса в метку не попадает, чтобы произвольные URL не порождали новые временные ряды.
+const unmatchedRoute = "unmatched"
+
+// HTTPMetrics принимает показатели обработанных HTTP-запросов.
+type HTTPMetrics interface {
+	ObserveHTTPRequest(method, route string, status int, duration time.Duration)
+}
+
+// Metrics учитывает каждый запрос по методу, шаблону маршрута chi и статусу ответа.
+// Должен подключаться к корневому роутеру: шаблон маршрута известен только после обработки запроса.
+func Metrics(recorder HTTPMetrics) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			started := time.Now()
+			ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r)
+
+			route := unmatchedRoute
+			if rctx := chi.RouteContext(r.Context()); rctx != nil {
+				if pattern := rctx.RoutePattern(); pattern != "" {
+					route = pattern
+				}
+			}
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK // Обработчик ничего не записал - net/http ответит 200
+			}
+			recorder.ObserveHTTPRequest(r.Method, route, status, time.Since(started))
+		})
+	}
+}
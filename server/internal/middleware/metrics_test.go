@@ -0,0 +1,56 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/maynagashev/gophkeeper/server/internal/middleware"
+)
+
+// observedRequest - запрос, учтенный в метриках.
+type observedRequest struct {
+	method string
+	route  string
+	status int
+}
+
+// recordingHTTPMetrics запоминает учтенные запросы.
+type recordingHTTPMetrics struct {
+	requests []observedRequest
+}
+
+func (m *recordingHTTPMetrics) ObserveHTTPRequest(method, route string, status int, _ time.Duration) {
+	m.requests = append(m.requests, observedRequest{method: method, route: route, status: status})
+}
+
+func TestMetrics(t *testing.T) {
+	recorder := &recordingHTTPMetrics{}
+	r := chi.NewRouter()
+	r.Use(middleware.Metrics(recorder))
+	r.Route("/api", func(r chi.Router) {
+		r.Get("/webhooks/{webhookID}", func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		})
+		r.Get("/ping", func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte("pong"))
+		})
+	})
+
+	for _, path := range []string{"/api/webhooks/42", "/api/ping", "/unknown/7"} {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	require.Len(t, recorder.requests, 3)
+	// Метка маршрута - шаблон, а не путь запроса
+	assert.Equal(t, observedRequest{http.MethodGet, "/api/webhooks/{webhookID}", http.StatusNoContent},
+		recorder.requests[0])
+	assert.Equal(t, observedRequest{http.MethodGet, "/api/ping", http.StatusOK}, recorder.requests[1])
+	assert.Equal(t, observedRequest{http.MethodGet, "unmatched", http.StatusNotFound}, recorder.requests[2])
+}
@@ -2,7 +2,12 @@
 
 package mocks
 
-import mock "github.com/stretchr/testify/mock"
+import (
+	context "context"
+
+	models "github.com/maynagashev/gophkeeper/models"
+	mock "github.com/stretchr/testify/mock"
+)
 
 // AuthService is an autogenerated mock type for the AuthService type
 type AuthService struct {
@@ -17,9 +22,9 @@ func (_m *AuthService) EXPECT() *AuthService_Expecter {
 	return &AuthService_Expecter{mock: &_m.Mock}
 }
 
-// Login provides a mock function with given fields: username, password
-func (_m *AuthService) Login(username string, password string) (string, error) {
-	ret := _m.Called(username, password)
+// Login provides a mock function with given fields: ctx, username, password, device
+func (_m *AuthService) Login(ctx context.Context, username string, password string, device models.Device) (string, error) {
+	ret := _m.Called(ctx, username, password, device)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Login")
@@ -27,17 +32,17 @@ func (_m *AuthService) Login(username string, password string) (string, error) {
 
 	var r0 string
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, string) (string, error)); ok {
-		return rf(username, password)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, models.Device) (string, error)); ok {
+		return rf(ctx, username, password, device)
 	}
-	if rf, ok := ret.Get(0).(func(string, string) string); ok {
-		r0 = rf(username, password)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, models.Device) string); ok {
+		r0 = rf(ctx, username, password, device)
 	} else {
 		r0 = ret.Get(0).(string)
 	}
 
-	if rf, ok := ret.Get(1).(func(string, string) error); ok {
-		r1 = rf(username, password)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, models.Device) error); ok {
+		r1 = rf(ctx, username, password, device)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -51,15 +56,17 @@ type AuthService_Login_Call struct {
 }
 
 // Login is a helper method to define mock.On call
+//   - ctx context.Context
 //   - username string
 //   - password string
-func (_e *AuthService_Expecter) Login(username interface{}, password interface{}) *AuthService_Login_Call {
-	return &AuthService_Login_Call{Call: _e.mock.On("Login", username, password)}
+//   - device models.Device
+func (_e *AuthService_Expecter) Login(ctx interface{}, username interface{}, password interface{}, device interface{}) *AuthService_Login_Call {
+	return &AuthService_Login_Call{Call: _e.mock.On("Login", ctx, username, password, device)}
 }
 
-func (_c *AuthService_Login_Call) Run(run func(username string, password string)) *AuthService_Login_Call {
+func (_c *AuthService_Login_Call) Run(run func(ctx context.Context, username string, password string, device models.Device)) *AuthService_Login_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string), args[1].(string))
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(models.Device))
 	})
 	return _c
 }
@@ -69,22 +76,22 @@ func (_c *AuthService_Login_Call) Return(_a0 string, _a1 error) *AuthService_Log
 	return _c
 }
 
-func (_c *AuthService_Login_Call) RunAndReturn(run func(string, string) (string, error)) *AuthService_Login_Call {
+func (_c *AuthService_Login_Call) RunAndReturn(run func(context.Context, string, string, models.Device) (string, error)) *AuthService_Login_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// Register provides a mock function with given fields: username, password
-func (_m *AuthService) Register(username string, password string) error {
-	ret := _m.Called(username, password)
+// Register provides a mock function with given fields: ctx, username, password
+func (_m *AuthService) Register(ctx context.Context, username string, password string) error {
+	ret := _m.Called(ctx, username, password)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Register")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string, string) error); ok {
-		r0 = rf(username, password)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, username, password)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -98,15 +105,16 @@ type AuthService_Register_Call struct {
 }
 
 // Register is a helper method to define mock.On call
+//   - ctx context.Context
 //   - username string
 //   - password string
-func (_e *AuthService_Expecter) Register(username interface{}, password interface{}) *AuthService_Register_Call {
-	return &AuthService_Register_Call{Call: _e.mock.On("Register", username, password)}
+func (_e *AuthService_Expecter) Register(ctx interface{}, username interface{}, password interface{}) *AuthService_Register_Call {
+	return &AuthService_Register_Call{Call: _e.mock.On("Register", ctx, username, password)}
 }
 
-func (_c *AuthService_Register_Call) Run(run func(username string, password string)) *AuthService_Register_Call {
+func (_c *AuthService_Register_Call) Run(run func(ctx context.Context, username string, password string)) *AuthService_Register_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string), args[1].(string))
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
 	})
 	return _c
 }
@@ -116,7 +124,67 @@ func (_c *AuthService_Register_Call) Return(_a0 error) *AuthService_Register_Cal
 	return _c
 }
 
-func (_c *AuthService_Register_Call) RunAndReturn(run func(string, string) error) *AuthService_Register_Call {
+func (_c *AuthService_Register_Call) RunAndReturn(run func(context.Context, string, string) error) *AuthService_Register_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// VerifyCredentials provides a mock function with given fields: ctx, username, password
+func (_m *AuthService) VerifyCredentials(ctx context.Context, username string, password string) (*models.User, error) {
+	ret := _m.Called(ctx, username, password)
+
+	if len(ret) == 0 {
+		panic("no return value specified for VerifyCredentials")
+	}
+
+	var r0 *models.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*models.User, error)); ok {
+		return rf(ctx, username, password)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *models.User); ok {
+		r0 = rf(ctx, username, password)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, username, password)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AuthService_VerifyCredentials_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'VerifyCredentials'
+type AuthService_VerifyCredentials_Call struct {
+	*mock.Call
+}
+
+// VerifyCredentials is a helper method to define mock.On call
+//   - ctx context.Context
+//   - username string
+//   - password string
+func (_e *AuthService_Expecter) VerifyCredentials(ctx interface{}, username interface{}, password interface{}) *AuthService_VerifyCredentials_Call {
+	return &AuthService_VerifyCredentials_Call{Call: _e.mock.On("VerifyCredentials", ctx, username, password)}
+}
+
+func (_c *AuthService_VerifyCredentials_Call) Run(run func(ctx context.Context, username string, password string)) *AuthService_VerifyCredentials_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *AuthService_VerifyCredentials_Call) Return(_a0 *models.User, _a1 error) *AuthService_VerifyCredentials_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *AuthService_VerifyCredentials_Call) RunAndReturn(run func(context.Context, string, string) (*models.User, error)) *AuthService_VerifyCredentials_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -22,6 +22,53 @@ func (_m *FileStorage) EXPECT() *FileStorage_Expecter {
 	return &FileStorage_Expecter{mock: &_m.Mock}
 }
 
+// DeleteFile provides a mock function with given fields: ctx, objectKey
+func (_m *FileStorage) DeleteFile(ctx context.Context, objectKey string) error {
+	ret := _m.Called(ctx, objectKey)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteFile")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, objectKey)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// FileStorage_DeleteFile_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteFile'
+type FileStorage_DeleteFile_Call struct {
+	*mock.Call
+}
+
+// DeleteFile is a helper method to define mock.On call
+//   - ctx context.Context
+//   - objectKey string
+func (_e *FileStorage_Expecter) DeleteFile(ctx interface{}, objectKey interface{}) *FileStorage_DeleteFile_Call {
+	return &FileStorage_DeleteFile_Call{Call: _e.mock.On("DeleteFile", ctx, objectKey)}
+}
+
+func (_c *FileStorage_DeleteFile_Call) Run(run func(ctx context.Context, objectKey string)) *FileStorage_DeleteFile_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *FileStorage_DeleteFile_Call) Return(_a0 error) *FileStorage_DeleteFile_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *FileStorage_DeleteFile_Call) RunAndReturn(run func(context.Context, string) error) *FileStorage_DeleteFile_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // DownloadFile provides a mock function with given fields: ctx, objectKey
 func (_m *FileStorage) DownloadFile(ctx context.Context, objectKey string) (io.ReadCloser, error) {
 	ret := _m.Called(ctx, objectKey)
@@ -81,6 +128,113 @@ func (_c *FileStorage_DownloadFile_Call) RunAndReturn(run func(context.Context,
 	return _c
 }
 
+// DownloadFileRange provides a mock function with given fields: ctx, objectKey, offset, length
+func (_m *FileStorage) DownloadFileRange(ctx context.Context, objectKey string, offset int64, length int64) (io.ReadCloser, error) {
+	ret := _m.Called(ctx, objectKey, offset, length)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DownloadFileRange")
+	}
+
+	var r0 io.ReadCloser
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64, int64) (io.ReadCloser, error)); ok {
+		return rf(ctx, objectKey, offset, length)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64, int64) io.ReadCloser); ok {
+		r0 = rf(ctx, objectKey, offset, length)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(io.ReadCloser)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int64, int64) error); ok {
+		r1 = rf(ctx, objectKey, offset, length)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FileStorage_DownloadFileRange_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DownloadFileRange'
+type FileStorage_DownloadFileRange_Call struct {
+	*mock.Call
+}
+
+// DownloadFileRange is a helper method to define mock.On call
+//   - ctx context.Context
+//   - objectKey string
+//   - offset int64
+//   - length int64
+func (_e *FileStorage_Expecter) DownloadFileRange(ctx interface{}, objectKey interface{}, offset interface{}, length interface{}) *FileStorage_DownloadFileRange_Call {
+	return &FileStorage_DownloadFileRange_Call{Call: _e.mock.On("DownloadFileRange", ctx, objectKey, offset, length)}
+}
+
+func (_c *FileStorage_DownloadFileRange_Call) Run(run func(ctx context.Context, objectKey string, offset int64, length int64)) *FileStorage_DownloadFileRange_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int64), args[3].(int64))
+	})
+	return _c
+}
+
+func (_c *FileStorage_DownloadFileRange_Call) Return(_a0 io.ReadCloser, _a1 error) *FileStorage_DownloadFileRange_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *FileStorage_DownloadFileRange_Call) RunAndReturn(run func(context.Context, string, int64, int64) (io.ReadCloser, error)) *FileStorage_DownloadFileRange_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Health provides a mock function with given fields: ctx
+func (_m *FileStorage) Health(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Health")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// FileStorage_Health_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Health'
+type FileStorage_Health_Call struct {
+	*mock.Call
+}
+
+// Health is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *FileStorage_Expecter) Health(ctx interface{}) *FileStorage_Health_Call {
+	return &FileStorage_Health_Call{Call: _e.mock.On("Health", ctx)}
+}
+
+func (_c *FileStorage_Health_Call) Run(run func(ctx context.Context)) *FileStorage_Health_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *FileStorage_Health_Call) Return(_a0 error) *FileStorage_Health_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *FileStorage_Health_Call) RunAndReturn(run func(context.Context) error) *FileStorage_Health_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // UploadFile provides a mock function with given fields: ctx, objectKey, reader, size, contentType
 func (_m *FileStorage) UploadFile(ctx context.Context, objectKey string, reader io.Reader, size int64, contentType string) error {
 	ret := _m.Called(ctx, objectKey, reader, size, contentType)
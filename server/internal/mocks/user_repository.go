@@ -79,6 +79,170 @@ func (_c *UserRepository_CreateUser_Call) RunAndReturn(run func(context.Context,
 	return _c
 }
 
+// DeleteUser provides a mock function with given fields: ctx, userID
+func (_m *UserRepository) DeleteUser(ctx context.Context, userID int64) error {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteUser")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UserRepository_DeleteUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteUser'
+type UserRepository_DeleteUser_Call struct {
+	*mock.Call
+}
+
+// DeleteUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int64
+func (_e *UserRepository_Expecter) DeleteUser(ctx interface{}, userID interface{}) *UserRepository_DeleteUser_Call {
+	return &UserRepository_DeleteUser_Call{Call: _e.mock.On("DeleteUser", ctx, userID)}
+}
+
+func (_c *UserRepository_DeleteUser_Call) Run(run func(ctx context.Context, userID int64)) *UserRepository_DeleteUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *UserRepository_DeleteUser_Call) Return(_a0 error) *UserRepository_DeleteUser_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *UserRepository_DeleteUser_Call) RunAndReturn(run func(context.Context, int64) error) *UserRepository_DeleteUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetServerStats provides a mock function with given fields: ctx
+func (_m *UserRepository) GetServerStats(ctx context.Context) (*models.ServerStats, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetServerStats")
+	}
+
+	var r0 *models.ServerStats
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*models.ServerStats, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *models.ServerStats); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.ServerStats)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UserRepository_GetServerStats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetServerStats'
+type UserRepository_GetServerStats_Call struct {
+	*mock.Call
+}
+
+// GetServerStats is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *UserRepository_Expecter) GetServerStats(ctx interface{}) *UserRepository_GetServerStats_Call {
+	return &UserRepository_GetServerStats_Call{Call: _e.mock.On("GetServerStats", ctx)}
+}
+
+func (_c *UserRepository_GetServerStats_Call) Run(run func(ctx context.Context)) *UserRepository_GetServerStats_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *UserRepository_GetServerStats_Call) Return(_a0 *models.ServerStats, _a1 error) *UserRepository_GetServerStats_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *UserRepository_GetServerStats_Call) RunAndReturn(run func(context.Context) (*models.ServerStats, error)) *UserRepository_GetServerStats_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetUserByID provides a mock function with given fields: ctx, userID
+func (_m *UserRepository) GetUserByID(ctx context.Context, userID int64) (*models.User, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserByID")
+	}
+
+	var r0 *models.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (*models.User, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) *models.User); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UserRepository_GetUserByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUserByID'
+type UserRepository_GetUserByID_Call struct {
+	*mock.Call
+}
+
+// GetUserByID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int64
+func (_e *UserRepository_Expecter) GetUserByID(ctx interface{}, userID interface{}) *UserRepository_GetUserByID_Call {
+	return &UserRepository_GetUserByID_Call{Call: _e.mock.On("GetUserByID", ctx, userID)}
+}
+
+func (_c *UserRepository_GetUserByID_Call) Run(run func(ctx context.Context, userID int64)) *UserRepository_GetUserByID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *UserRepository_GetUserByID_Call) Return(_a0 *models.User, _a1 error) *UserRepository_GetUserByID_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *UserRepository_GetUserByID_Call) RunAndReturn(run func(context.Context, int64) (*models.User, error)) *UserRepository_GetUserByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetUserByUsername provides a mock function with given fields: ctx, username
 func (_m *UserRepository) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
 	ret := _m.Called(ctx, username)
@@ -138,6 +302,271 @@ func (_c *UserRepository_GetUserByUsername_Call) RunAndReturn(run func(context.C
 	return _c
 }
 
+// ListUsers provides a mock function with given fields: ctx, search, limit, offset
+func (_m *UserRepository) ListUsers(ctx context.Context, search string, limit int, offset int) ([]models.UserSummary, error) {
+	ret := _m.Called(ctx, search, limit, offset)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListUsers")
+	}
+
+	var r0 []models.UserSummary
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, int) ([]models.UserSummary, error)); ok {
+		return rf(ctx, search, limit, offset)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, int) []models.UserSummary); ok {
+		r0 = rf(ctx, search, limit, offset)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.UserSummary)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int, int) error); ok {
+		r1 = rf(ctx, search, limit, offset)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UserRepository_ListUsers_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListUsers'
+type UserRepository_ListUsers_Call struct {
+	*mock.Call
+}
+
+// ListUsers is a helper method to define mock.On call
+//   - ctx context.Context
+//   - search string
+//   - limit int
+//   - offset int
+func (_e *UserRepository_Expecter) ListUsers(ctx interface{}, search interface{}, limit interface{}, offset interface{}) *UserRepository_ListUsers_Call {
+	return &UserRepository_ListUsers_Call{Call: _e.mock.On("ListUsers", ctx, search, limit, offset)}
+}
+
+func (_c *UserRepository_ListUsers_Call) Run(run func(ctx context.Context, search string, limit int, offset int)) *UserRepository_ListUsers_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *UserRepository_ListUsers_Call) Return(_a0 []models.UserSummary, _a1 error) *UserRepository_ListUsers_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *UserRepository_ListUsers_Call) RunAndReturn(run func(context.Context, string, int, int) ([]models.UserSummary, error)) *UserRepository_ListUsers_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetUserDisabled provides a mock function with given fields: ctx, userID, disabled
+func (_m *UserRepository) SetUserDisabled(ctx context.Context, userID int64, disabled bool) error {
+	ret := _m.Called(ctx, userID, disabled)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetUserDisabled")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, bool) error); ok {
+		r0 = rf(ctx, userID, disabled)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UserRepository_SetUserDisabled_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetUserDisabled'
+type UserRepository_SetUserDisabled_Call struct {
+	*mock.Call
+}
+
+// SetUserDisabled is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int64
+//   - disabled bool
+func (_e *UserRepository_Expecter) SetUserDisabled(ctx interface{}, userID interface{}, disabled interface{}) *UserRepository_SetUserDisabled_Call {
+	return &UserRepository_SetUserDisabled_Call{Call: _e.mock.On("SetUserDisabled", ctx, userID, disabled)}
+}
+
+func (_c *UserRepository_SetUserDisabled_Call) Run(run func(ctx context.Context, userID int64, disabled bool)) *UserRepository_SetUserDisabled_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(bool))
+	})
+	return _c
+}
+
+func (_c *UserRepository_SetUserDisabled_Call) Return(_a0 error) *UserRepository_SetUserDisabled_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *UserRepository_SetUserDisabled_Call) RunAndReturn(run func(context.Context, int64, bool) error) *UserRepository_SetUserDisabled_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetUserRole provides a mock function with given fields: ctx, userID, role
+func (_m *UserRepository) SetUserRole(ctx context.Context, userID int64, role string) error {
+	ret := _m.Called(ctx, userID, role)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetUserRole")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) error); ok {
+		r0 = rf(ctx, userID, role)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UserRepository_SetUserRole_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetUserRole'
+type UserRepository_SetUserRole_Call struct {
+	*mock.Call
+}
+
+// SetUserRole is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int64
+//   - role string
+func (_e *UserRepository_Expecter) SetUserRole(ctx interface{}, userID interface{}, role interface{}) *UserRepository_SetUserRole_Call {
+	return &UserRepository_SetUserRole_Call{Call: _e.mock.On("SetUserRole", ctx, userID, role)}
+}
+
+func (_c *UserRepository_SetUserRole_Call) Run(run func(ctx context.Context, userID int64, role string)) *UserRepository_SetUserRole_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *UserRepository_SetUserRole_Call) Return(_a0 error) *UserRepository_SetUserRole_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *UserRepository_SetUserRole_Call) RunAndReturn(run func(context.Context, int64, string) error) *UserRepository_SetUserRole_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// TouchDevice provides a mock function with given fields: ctx, userID, fingerprint, userAgent, ipAddress
+func (_m *UserRepository) TouchDevice(ctx context.Context, userID int64, fingerprint string, userAgent string, ipAddress string) (bool, error) {
+	ret := _m.Called(ctx, userID, fingerprint, userAgent, ipAddress)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TouchDevice")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, string, string) (bool, error)); ok {
+		return rf(ctx, userID, fingerprint, userAgent, ipAddress)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, string, string) bool); ok {
+		r0 = rf(ctx, userID, fingerprint, userAgent, ipAddress)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string, string, string) error); ok {
+		r1 = rf(ctx, userID, fingerprint, userAgent, ipAddress)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UserRepository_TouchDevice_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'TouchDevice'
+type UserRepository_TouchDevice_Call struct {
+	*mock.Call
+}
+
+// TouchDevice is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int64
+//   - fingerprint string
+//   - userAgent string
+//   - ipAddress string
+func (_e *UserRepository_Expecter) TouchDevice(ctx interface{}, userID interface{}, fingerprint interface{}, userAgent interface{}, ipAddress interface{}) *UserRepository_TouchDevice_Call {
+	return &UserRepository_TouchDevice_Call{Call: _e.mock.On("TouchDevice", ctx, userID, fingerprint, userAgent, ipAddress)}
+}
+
+func (_c *UserRepository_TouchDevice_Call) Run(run func(ctx context.Context, userID int64, fingerprint string, userAgent string, ipAddress string)) *UserRepository_TouchDevice_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(string), args[3].(string), args[4].(string))
+	})
+	return _c
+}
+
+func (_c *UserRepository_TouchDevice_Call) Return(_a0 bool, _a1 error) *UserRepository_TouchDevice_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *UserRepository_TouchDevice_Call) RunAndReturn(run func(context.Context, int64, string, string, string) (bool, error)) *UserRepository_TouchDevice_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdatePasswordHash provides a mock function with given fields: ctx, userID, passwordHash
+func (_m *UserRepository) UpdatePasswordHash(ctx context.Context, userID int64, passwordHash string) error {
+	ret := _m.Called(ctx, userID, passwordHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdatePasswordHash")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) error); ok {
+		r0 = rf(ctx, userID, passwordHash)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UserRepository_UpdatePasswordHash_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdatePasswordHash'
+type UserRepository_UpdatePasswordHash_Call struct {
+	*mock.Call
+}
+
+// UpdatePasswordHash is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int64
+//   - passwordHash string
+func (_e *UserRepository_Expecter) UpdatePasswordHash(ctx interface{}, userID interface{}, passwordHash interface{}) *UserRepository_UpdatePasswordHash_Call {
+	return &UserRepository_UpdatePasswordHash_Call{Call: _e.mock.On("UpdatePasswordHash", ctx, userID, passwordHash)}
+}
+
+func (_c *UserRepository_UpdatePasswordHash_Call) Run(run func(ctx context.Context, userID int64, passwordHash string)) *UserRepository_UpdatePasswordHash_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *UserRepository_UpdatePasswordHash_Call) Return(_a0 error) *UserRepository_UpdatePasswordHash_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *UserRepository_UpdatePasswordHash_Call) RunAndReturn(run func(context.Context, int64, string) error) *UserRepository_UpdatePasswordHash_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewUserRepository creates a new instance of UserRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewUserRepository(t interface {
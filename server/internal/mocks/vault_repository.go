@@ -206,6 +206,74 @@ func (_c *VaultRepository_GetVaultWithCurrentVersionByUserID_Call) RunAndReturn(
 	return _c
 }
 
+// LockVaultWithCurrentVersionByUserID provides a mock function with given fields: ctx, userID
+func (_m *VaultRepository) LockVaultWithCurrentVersionByUserID(ctx context.Context, userID int64) (*models.Vault, *models.VaultVersion, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LockVaultWithCurrentVersionByUserID")
+	}
+
+	var r0 *models.Vault
+	var r1 *models.VaultVersion
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (*models.Vault, *models.VaultVersion, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) *models.Vault); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Vault)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) *models.VaultVersion); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*models.VaultVersion)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, int64) error); ok {
+		r2 = rf(ctx, userID)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// VaultRepository_LockVaultWithCurrentVersionByUserID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LockVaultWithCurrentVersionByUserID'
+type VaultRepository_LockVaultWithCurrentVersionByUserID_Call struct {
+	*mock.Call
+}
+
+// LockVaultWithCurrentVersionByUserID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int64
+func (_e *VaultRepository_Expecter) LockVaultWithCurrentVersionByUserID(ctx interface{}, userID interface{}) *VaultRepository_LockVaultWithCurrentVersionByUserID_Call {
+	return &VaultRepository_LockVaultWithCurrentVersionByUserID_Call{Call: _e.mock.On("LockVaultWithCurrentVersionByUserID", ctx, userID)}
+}
+
+func (_c *VaultRepository_LockVaultWithCurrentVersionByUserID_Call) Run(run func(ctx context.Context, userID int64)) *VaultRepository_LockVaultWithCurrentVersionByUserID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *VaultRepository_LockVaultWithCurrentVersionByUserID_Call) Return(_a0 *models.Vault, _a1 *models.VaultVersion, _a2 error) *VaultRepository_LockVaultWithCurrentVersionByUserID_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *VaultRepository_LockVaultWithCurrentVersionByUserID_Call) RunAndReturn(run func(context.Context, int64) (*models.Vault, *models.VaultVersion, error)) *VaultRepository_LockVaultWithCurrentVersionByUserID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // UpdateVaultCurrentVersion provides a mock function with given fields: ctx, vaultID, versionID
 func (_m *VaultRepository) UpdateVaultCurrentVersion(ctx context.Context, vaultID int64, versionID int64) error {
 	ret := _m.Called(ctx, vaultID, versionID)
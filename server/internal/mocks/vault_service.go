@@ -3,12 +3,12 @@
 package mocks
 
 import (
+	context "context"
 	io "io"
+	time "time"
 
 	models "github.com/maynagashev/gophkeeper/models"
 	mock "github.com/stretchr/testify/mock"
-
-	time "time"
 )
 
 // VaultService is an autogenerated mock type for the VaultService type
@@ -24,9 +24,9 @@ func (_m *VaultService) EXPECT() *VaultService_Expecter {
 	return &VaultService_Expecter{mock: &_m.Mock}
 }
 
-// DownloadVault provides a mock function with given fields: userID
-func (_m *VaultService) DownloadVault(userID int64) (io.ReadCloser, *models.VaultVersion, error) {
-	ret := _m.Called(userID)
+// DownloadVault provides a mock function with given fields: ctx, userID
+func (_m *VaultService) DownloadVault(ctx context.Context, userID int64) (io.ReadCloser, *models.VaultVersion, error) {
+	ret := _m.Called(ctx, userID)
 
 	if len(ret) == 0 {
 		panic("no return value specified for DownloadVault")
@@ -35,27 +35,27 @@ func (_m *VaultService) DownloadVault(userID int64) (io.ReadCloser, *models.Vaul
 	var r0 io.ReadCloser
 	var r1 *models.VaultVersion
 	var r2 error
-	if rf, ok := ret.Get(0).(func(int64) (io.ReadCloser, *models.VaultVersion, error)); ok {
-		return rf(userID)
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (io.ReadCloser, *models.VaultVersion, error)); ok {
+		return rf(ctx, userID)
 	}
-	if rf, ok := ret.Get(0).(func(int64) io.ReadCloser); ok {
-		r0 = rf(userID)
+	if rf, ok := ret.Get(0).(func(context.Context, int64) io.ReadCloser); ok {
+		r0 = rf(ctx, userID)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(io.ReadCloser)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(int64) *models.VaultVersion); ok {
-		r1 = rf(userID)
+	if rf, ok := ret.Get(1).(func(context.Context, int64) *models.VaultVersion); ok {
+		r1 = rf(ctx, userID)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(*models.VaultVersion)
 		}
 	}
 
-	if rf, ok := ret.Get(2).(func(int64) error); ok {
-		r2 = rf(userID)
+	if rf, ok := ret.Get(2).(func(context.Context, int64) error); ok {
+		r2 = rf(ctx, userID)
 	} else {
 		r2 = ret.Error(2)
 	}
@@ -69,14 +69,15 @@ type VaultService_DownloadVault_Call struct {
 }
 
 // DownloadVault is a helper method to define mock.On call
+//   - ctx context.Context
 //   - userID int64
-func (_e *VaultService_Expecter) DownloadVault(userID interface{}) *VaultService_DownloadVault_Call {
-	return &VaultService_DownloadVault_Call{Call: _e.mock.On("DownloadVault", userID)}
+func (_e *VaultService_Expecter) DownloadVault(ctx interface{}, userID interface{}) *VaultService_DownloadVault_Call {
+	return &VaultService_DownloadVault_Call{Call: _e.mock.On("DownloadVault", ctx, userID)}
 }
 
-func (_c *VaultService_DownloadVault_Call) Run(run func(userID int64)) *VaultService_DownloadVault_Call {
+func (_c *VaultService_DownloadVault_Call) Run(run func(ctx context.Context, userID int64)) *VaultService_DownloadVault_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(int64))
+		run(args[0].(context.Context), args[1].(int64))
 	})
 	return _c
 }
@@ -86,14 +87,75 @@ func (_c *VaultService_DownloadVault_Call) Return(_a0 io.ReadCloser, _a1 *models
 	return _c
 }
 
-func (_c *VaultService_DownloadVault_Call) RunAndReturn(run func(int64) (io.ReadCloser, *models.VaultVersion, error)) *VaultService_DownloadVault_Call {
+func (_c *VaultService_DownloadVault_Call) RunAndReturn(run func(context.Context, int64) (io.ReadCloser, *models.VaultVersion, error)) *VaultService_DownloadVault_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetVaultMetadata provides a mock function with given fields: userID
-func (_m *VaultService) GetVaultMetadata(userID int64) (*models.VaultVersion, error) {
-	ret := _m.Called(userID)
+// DownloadVersion provides a mock function with given fields: ctx, version, offset, length
+func (_m *VaultService) DownloadVersion(ctx context.Context, version *models.VaultVersion, offset int64, length int64) (io.ReadCloser, error) {
+	ret := _m.Called(ctx, version, offset, length)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DownloadVersion")
+	}
+
+	var r0 io.ReadCloser
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.VaultVersion, int64, int64) (io.ReadCloser, error)); ok {
+		return rf(ctx, version, offset, length)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.VaultVersion, int64, int64) io.ReadCloser); ok {
+		r0 = rf(ctx, version, offset, length)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(io.ReadCloser)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.VaultVersion, int64, int64) error); ok {
+		r1 = rf(ctx, version, offset, length)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// VaultService_DownloadVersion_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DownloadVersion'
+type VaultService_DownloadVersion_Call struct {
+	*mock.Call
+}
+
+// DownloadVersion is a helper method to define mock.On call
+//   - ctx context.Context
+//   - version *models.VaultVersion
+//   - offset int64
+//   - length int64
+func (_e *VaultService_Expecter) DownloadVersion(ctx interface{}, version interface{}, offset interface{}, length interface{}) *VaultService_DownloadVersion_Call {
+	return &VaultService_DownloadVersion_Call{Call: _e.mock.On("DownloadVersion", ctx, version, offset, length)}
+}
+
+func (_c *VaultService_DownloadVersion_Call) Run(run func(ctx context.Context, version *models.VaultVersion, offset int64, length int64)) *VaultService_DownloadVersion_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*models.VaultVersion), args[2].(int64), args[3].(int64))
+	})
+	return _c
+}
+
+func (_c *VaultService_DownloadVersion_Call) Return(_a0 io.ReadCloser, _a1 error) *VaultService_DownloadVersion_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *VaultService_DownloadVersion_Call) RunAndReturn(run func(context.Context, *models.VaultVersion, int64, int64) (io.ReadCloser, error)) *VaultService_DownloadVersion_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetVaultMetadata provides a mock function with given fields: ctx, userID
+func (_m *VaultService) GetVaultMetadata(ctx context.Context, userID int64) (*models.VaultVersion, error) {
+	ret := _m.Called(ctx, userID)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetVaultMetadata")
@@ -101,19 +163,19 @@ func (_m *VaultService) GetVaultMetadata(userID int64) (*models.VaultVersion, er
 
 	var r0 *models.VaultVersion
 	var r1 error
-	if rf, ok := ret.Get(0).(func(int64) (*models.VaultVersion, error)); ok {
-		return rf(userID)
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (*models.VaultVersion, error)); ok {
+		return rf(ctx, userID)
 	}
-	if rf, ok := ret.Get(0).(func(int64) *models.VaultVersion); ok {
-		r0 = rf(userID)
+	if rf, ok := ret.Get(0).(func(context.Context, int64) *models.VaultVersion); ok {
+		r0 = rf(ctx, userID)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.VaultVersion)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(int64) error); ok {
-		r1 = rf(userID)
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, userID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -127,14 +189,15 @@ type VaultService_GetVaultMetadata_Call struct {
 }
 
 // GetVaultMetadata is a helper method to define mock.On call
+//   - ctx context.Context
 //   - userID int64
-func (_e *VaultService_Expecter) GetVaultMetadata(userID interface{}) *VaultService_GetVaultMetadata_Call {
-	return &VaultService_GetVaultMetadata_Call{Call: _e.mock.On("GetVaultMetadata", userID)}
+func (_e *VaultService_Expecter) GetVaultMetadata(ctx interface{}, userID interface{}) *VaultService_GetVaultMetadata_Call {
+	return &VaultService_GetVaultMetadata_Call{Call: _e.mock.On("GetVaultMetadata", ctx, userID)}
 }
 
-func (_c *VaultService_GetVaultMetadata_Call) Run(run func(userID int64)) *VaultService_GetVaultMetadata_Call {
+func (_c *VaultService_GetVaultMetadata_Call) Run(run func(ctx context.Context, userID int64)) *VaultService_GetVaultMetadata_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(int64))
+		run(args[0].(context.Context), args[1].(int64))
 	})
 	return _c
 }
@@ -144,14 +207,14 @@ func (_c *VaultService_GetVaultMetadata_Call) Return(_a0 *models.VaultVersion, _
 	return _c
 }
 
-func (_c *VaultService_GetVaultMetadata_Call) RunAndReturn(run func(int64) (*models.VaultVersion, error)) *VaultService_GetVaultMetadata_Call {
+func (_c *VaultService_GetVaultMetadata_Call) RunAndReturn(run func(context.Context, int64) (*models.VaultVersion, error)) *VaultService_GetVaultMetadata_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// ListVersions provides a mock function with given fields: userID, limit, offset
-func (_m *VaultService) ListVersions(userID int64, limit int, offset int) ([]models.VaultVersion, error) {
-	ret := _m.Called(userID, limit, offset)
+// ListVersions provides a mock function with given fields: ctx, userID, limit, offset
+func (_m *VaultService) ListVersions(ctx context.Context, userID int64, limit int, offset int) ([]models.VaultVersion, error) {
+	ret := _m.Called(ctx, userID, limit, offset)
 
 	if len(ret) == 0 {
 		panic("no return value specified for ListVersions")
@@ -159,19 +222,19 @@ func (_m *VaultService) ListVersions(userID int64, limit int, offset int) ([]mod
 
 	var r0 []models.VaultVersion
 	var r1 error
-	if rf, ok := ret.Get(0).(func(int64, int, int) ([]models.VaultVersion, error)); ok {
-		return rf(userID, limit, offset)
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int, int) ([]models.VaultVersion, error)); ok {
+		return rf(ctx, userID, limit, offset)
 	}
-	if rf, ok := ret.Get(0).(func(int64, int, int) []models.VaultVersion); ok {
-		r0 = rf(userID, limit, offset)
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int, int) []models.VaultVersion); ok {
+		r0 = rf(ctx, userID, limit, offset)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]models.VaultVersion)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(int64, int, int) error); ok {
-		r1 = rf(userID, limit, offset)
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int, int) error); ok {
+		r1 = rf(ctx, userID, limit, offset)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -185,16 +248,17 @@ type VaultService_ListVersions_Call struct {
 }
 
 // ListVersions is a helper method to define mock.On call
+//   - ctx context.Context
 //   - userID int64
 //   - limit int
 //   - offset int
-func (_e *VaultService_Expecter) ListVersions(userID interface{}, limit interface{}, offset interface{}) *VaultService_ListVersions_Call {
-	return &VaultService_ListVersions_Call{Call: _e.mock.On("ListVersions", userID, limit, offset)}
+func (_e *VaultService_Expecter) ListVersions(ctx interface{}, userID interface{}, limit interface{}, offset interface{}) *VaultService_ListVersions_Call {
+	return &VaultService_ListVersions_Call{Call: _e.mock.On("ListVersions", ctx, userID, limit, offset)}
 }
 
-func (_c *VaultService_ListVersions_Call) Run(run func(userID int64, limit int, offset int)) *VaultService_ListVersions_Call {
+func (_c *VaultService_ListVersions_Call) Run(run func(ctx context.Context, userID int64, limit int, offset int)) *VaultService_ListVersions_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(int64), args[1].(int), args[2].(int))
+		run(args[0].(context.Context), args[1].(int64), args[2].(int), args[3].(int))
 	})
 	return _c
 }
@@ -204,22 +268,22 @@ func (_c *VaultService_ListVersions_Call) Return(_a0 []models.VaultVersion, _a1
 	return _c
 }
 
-func (_c *VaultService_ListVersions_Call) RunAndReturn(run func(int64, int, int) ([]models.VaultVersion, error)) *VaultService_ListVersions_Call {
+func (_c *VaultService_ListVersions_Call) RunAndReturn(run func(context.Context, int64, int, int) ([]models.VaultVersion, error)) *VaultService_ListVersions_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// RollbackToVersion provides a mock function with given fields: userID, versionID
-func (_m *VaultService) RollbackToVersion(userID int64, versionID int64) error {
-	ret := _m.Called(userID, versionID)
+// RollbackToVersion provides a mock function with given fields: ctx, userID, versionID
+func (_m *VaultService) RollbackToVersion(ctx context.Context, userID int64, versionID int64) error {
+	ret := _m.Called(ctx, userID, versionID)
 
 	if len(ret) == 0 {
 		panic("no return value specified for RollbackToVersion")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(int64, int64) error); ok {
-		r0 = rf(userID, versionID)
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) error); ok {
+		r0 = rf(ctx, userID, versionID)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -233,15 +297,16 @@ type VaultService_RollbackToVersion_Call struct {
 }
 
 // RollbackToVersion is a helper method to define mock.On call
+//   - ctx context.Context
 //   - userID int64
 //   - versionID int64
-func (_e *VaultService_Expecter) RollbackToVersion(userID interface{}, versionID interface{}) *VaultService_RollbackToVersion_Call {
-	return &VaultService_RollbackToVersion_Call{Call: _e.mock.On("RollbackToVersion", userID, versionID)}
+func (_e *VaultService_Expecter) RollbackToVersion(ctx interface{}, userID interface{}, versionID interface{}) *VaultService_RollbackToVersion_Call {
+	return &VaultService_RollbackToVersion_Call{Call: _e.mock.On("RollbackToVersion", ctx, userID, versionID)}
 }
 
-func (_c *VaultService_RollbackToVersion_Call) Run(run func(userID int64, versionID int64)) *VaultService_RollbackToVersion_Call {
+func (_c *VaultService_RollbackToVersion_Call) Run(run func(ctx context.Context, userID int64, versionID int64)) *VaultService_RollbackToVersion_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(int64), args[1].(int64))
+		run(args[0].(context.Context), args[1].(int64), args[2].(int64))
 	})
 	return _c
 }
@@ -251,27 +316,39 @@ func (_c *VaultService_RollbackToVersion_Call) Return(_a0 error) *VaultService_R
 	return _c
 }
 
-func (_c *VaultService_RollbackToVersion_Call) RunAndReturn(run func(int64, int64) error) *VaultService_RollbackToVersion_Call {
+func (_c *VaultService_RollbackToVersion_Call) RunAndReturn(run func(context.Context, int64, int64) error) *VaultService_RollbackToVersion_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// UploadVault provides a mock function with given fields: userID, reader, size, contentType, contentModifiedAt
-func (_m *VaultService) UploadVault(userID int64, reader io.Reader, size int64, contentType string, contentModifiedAt time.Time) error {
-	ret := _m.Called(userID, reader, size, contentType, contentModifiedAt)
+// UploadVault provides a mock function with given fields: ctx, userID, reader, size, contentType, contentModifiedAt, origin
+func (_m *VaultService) UploadVault(ctx context.Context, userID int64, reader io.Reader, size int64, contentType string, contentModifiedAt time.Time, origin models.UploadOrigin) (*models.VaultVersion, error) {
+	ret := _m.Called(ctx, userID, reader, size, contentType, contentModifiedAt, origin)
 
 	if len(ret) == 0 {
 		panic("no return value specified for UploadVault")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(int64, io.Reader, int64, string, time.Time) error); ok {
-		r0 = rf(userID, reader, size, contentType, contentModifiedAt)
+	var r0 *models.VaultVersion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, io.Reader, int64, string, time.Time, models.UploadOrigin) (*models.VaultVersion, error)); ok {
+		return rf(ctx, userID, reader, size, contentType, contentModifiedAt, origin)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, io.Reader, int64, string, time.Time, models.UploadOrigin) *models.VaultVersion); ok {
+		r0 = rf(ctx, userID, reader, size, contentType, contentModifiedAt, origin)
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.VaultVersion)
+		}
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(context.Context, int64, io.Reader, int64, string, time.Time, models.UploadOrigin) error); ok {
+		r1 = rf(ctx, userID, reader, size, contentType, contentModifiedAt, origin)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
 // VaultService_UploadVault_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UploadVault'
@@ -280,28 +357,30 @@ type VaultService_UploadVault_Call struct {
 }
 
 // UploadVault is a helper method to define mock.On call
+//   - ctx context.Context
 //   - userID int64
 //   - reader io.Reader
 //   - size int64
 //   - contentType string
 //   - contentModifiedAt time.Time
-func (_e *VaultService_Expecter) UploadVault(userID interface{}, reader interface{}, size interface{}, contentType interface{}, contentModifiedAt interface{}) *VaultService_UploadVault_Call {
-	return &VaultService_UploadVault_Call{Call: _e.mock.On("UploadVault", userID, reader, size, contentType, contentModifiedAt)}
+//   - origin models.UploadOrigin
+func (_e *VaultService_Expecter) UploadVault(ctx interface{}, userID interface{}, reader interface{}, size interface{}, contentType interface{}, contentModifiedAt interface{}, origin interface{}) *VaultService_UploadVault_Call {
+	return &VaultService_UploadVault_Call{Call: _e.mock.On("UploadVault", ctx, userID, reader, size, contentType, contentModifiedAt, origin)}
 }
 
-func (_c *VaultService_UploadVault_Call) Run(run func(userID int64, reader io.Reader, size int64, contentType string, contentModifiedAt time.Time)) *VaultService_UploadVault_Call {
+func (_c *VaultService_UploadVault_Call) Run(run func(ctx context.Context, userID int64, reader io.Reader, size int64, contentType string, contentModifiedAt time.Time, origin models.UploadOrigin)) *VaultService_UploadVault_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(int64), args[1].(io.Reader), args[2].(int64), args[3].(string), args[4].(time.Time))
+		run(args[0].(context.Context), args[1].(int64), args[2].(io.Reader), args[3].(int64), args[4].(string), args[5].(time.Time), args[6].(models.UploadOrigin))
 	})
 	return _c
 }
 
-func (_c *VaultService_UploadVault_Call) Return(_a0 error) *VaultService_UploadVault_Call {
-	_c.Call.Return(_a0)
+func (_c *VaultService_UploadVault_Call) Return(_a0 *models.VaultVersion, _a1 error) *VaultService_UploadVault_Call {
+	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *VaultService_UploadVault_Call) RunAndReturn(run func(int64, io.Reader, int64, string, time.Time) error) *VaultService_UploadVault_Call {
+func (_c *VaultService_UploadVault_Call) RunAndReturn(run func(context.Context, int64, io.Reader, int64, string, time.Time, models.UploadOrigin) (*models.VaultVersion, error)) *VaultService_UploadVault_Call {
 	_c.Call.Return(run)
 	return _c
 }
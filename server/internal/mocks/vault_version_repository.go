@@ -4,6 +4,7 @@ package mocks
 
 import (
 	context "context"
+	time "time"
 
 	models "github.com/maynagashev/gophkeeper/models"
 	mock "github.com/stretchr/testify/mock"
@@ -79,6 +80,65 @@ func (_c *VaultVersionRepository_CreateVersion_Call) RunAndReturn(run func(conte
 	return _c
 }
 
+// DeleteVersions provides a mock function with given fields: ctx, versionIDs
+func (_m *VaultVersionRepository) DeleteVersions(ctx context.Context, versionIDs []int64) ([]models.VaultVersion, error) {
+	ret := _m.Called(ctx, versionIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteVersions")
+	}
+
+	var r0 []models.VaultVersion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []int64) ([]models.VaultVersion, error)); ok {
+		return rf(ctx, versionIDs)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []int64) []models.VaultVersion); ok {
+		r0 = rf(ctx, versionIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.VaultVersion)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []int64) error); ok {
+		r1 = rf(ctx, versionIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// VaultVersionRepository_DeleteVersions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteVersions'
+type VaultVersionRepository_DeleteVersions_Call struct {
+	*mock.Call
+}
+
+// DeleteVersions is a helper method to define mock.On call
+//   - ctx context.Context
+//   - versionIDs []int64
+func (_e *VaultVersionRepository_Expecter) DeleteVersions(ctx interface{}, versionIDs interface{}) *VaultVersionRepository_DeleteVersions_Call {
+	return &VaultVersionRepository_DeleteVersions_Call{Call: _e.mock.On("DeleteVersions", ctx, versionIDs)}
+}
+
+func (_c *VaultVersionRepository_DeleteVersions_Call) Run(run func(ctx context.Context, versionIDs []int64)) *VaultVersionRepository_DeleteVersions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]int64))
+	})
+	return _c
+}
+
+func (_c *VaultVersionRepository_DeleteVersions_Call) Return(_a0 []models.VaultVersion, _a1 error) *VaultVersionRepository_DeleteVersions_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *VaultVersionRepository_DeleteVersions_Call) RunAndReturn(run func(context.Context, []int64) ([]models.VaultVersion, error)) *VaultVersionRepository_DeleteVersions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetVersionByID provides a mock function with given fields: ctx, versionID
 func (_m *VaultVersionRepository) GetVersionByID(ctx context.Context, versionID int64) (*models.VaultVersion, error) {
 	ret := _m.Called(ctx, versionID)
@@ -138,6 +198,123 @@ func (_c *VaultVersionRepository_GetVersionByID_Call) RunAndReturn(run func(cont
 	return _c
 }
 
+// ListAllObjectKeys provides a mock function with given fields: ctx
+func (_m *VaultVersionRepository) ListAllObjectKeys(ctx context.Context) ([]string, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListAllObjectKeys")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]string, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []string); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// VaultVersionRepository_ListAllObjectKeys_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListAllObjectKeys'
+type VaultVersionRepository_ListAllObjectKeys_Call struct {
+	*mock.Call
+}
+
+// ListAllObjectKeys is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *VaultVersionRepository_Expecter) ListAllObjectKeys(ctx interface{}) *VaultVersionRepository_ListAllObjectKeys_Call {
+	return &VaultVersionRepository_ListAllObjectKeys_Call{Call: _e.mock.On("ListAllObjectKeys", ctx)}
+}
+
+func (_c *VaultVersionRepository_ListAllObjectKeys_Call) Run(run func(ctx context.Context)) *VaultVersionRepository_ListAllObjectKeys_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *VaultVersionRepository_ListAllObjectKeys_Call) Return(_a0 []string, _a1 error) *VaultVersionRepository_ListAllObjectKeys_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *VaultVersionRepository_ListAllObjectKeys_Call) RunAndReturn(run func(context.Context) ([]string, error)) *VaultVersionRepository_ListAllObjectKeys_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListObjectKeysByUserID provides a mock function with given fields: ctx, userID
+func (_m *VaultVersionRepository) ListObjectKeysByUserID(ctx context.Context, userID int64) ([]string, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListObjectKeysByUserID")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]string, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []string); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// VaultVersionRepository_ListObjectKeysByUserID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListObjectKeysByUserID'
+type VaultVersionRepository_ListObjectKeysByUserID_Call struct {
+	*mock.Call
+}
+
+// ListObjectKeysByUserID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int64
+func (_e *VaultVersionRepository_Expecter) ListObjectKeysByUserID(ctx interface{}, userID interface{}) *VaultVersionRepository_ListObjectKeysByUserID_Call {
+	return &VaultVersionRepository_ListObjectKeysByUserID_Call{Call: _e.mock.On("ListObjectKeysByUserID", ctx, userID)}
+}
+
+func (_c *VaultVersionRepository_ListObjectKeysByUserID_Call) Run(run func(ctx context.Context, userID int64)) *VaultVersionRepository_ListObjectKeysByUserID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *VaultVersionRepository_ListObjectKeysByUserID_Call) Return(_a0 []string, _a1 error) *VaultVersionRepository_ListObjectKeysByUserID_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *VaultVersionRepository_ListObjectKeysByUserID_Call) RunAndReturn(run func(context.Context, int64) ([]string, error)) *VaultVersionRepository_ListObjectKeysByUserID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // ListVersionsByVaultID provides a mock function with given fields: ctx, vaultID, limit, offset
 func (_m *VaultVersionRepository) ListVersionsByVaultID(ctx context.Context, vaultID int64, limit int, offset int) ([]models.VaultVersion, error) {
 	ret := _m.Called(ctx, vaultID, limit, offset)
@@ -199,6 +376,175 @@ func (_c *VaultVersionRepository_ListVersionsByVaultID_Call) RunAndReturn(run fu
 	return _c
 }
 
+// ListVersionsForVerification provides a mock function with given fields: ctx, verifiedBefore, limit
+func (_m *VaultVersionRepository) ListVersionsForVerification(ctx context.Context, verifiedBefore time.Time, limit int) ([]models.VaultVersion, error) {
+	ret := _m.Called(ctx, verifiedBefore, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListVersionsForVerification")
+	}
+
+	var r0 []models.VaultVersion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int) ([]models.VaultVersion, error)); ok {
+		return rf(ctx, verifiedBefore, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int) []models.VaultVersion); ok {
+		r0 = rf(ctx, verifiedBefore, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.VaultVersion)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, int) error); ok {
+		r1 = rf(ctx, verifiedBefore, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// VaultVersionRepository_ListVersionsForVerification_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListVersionsForVerification'
+type VaultVersionRepository_ListVersionsForVerification_Call struct {
+	*mock.Call
+}
+
+// ListVersionsForVerification is a helper method to define mock.On call
+//   - ctx context.Context
+//   - verifiedBefore time.Time
+//   - limit int
+func (_e *VaultVersionRepository_Expecter) ListVersionsForVerification(ctx interface{}, verifiedBefore interface{}, limit interface{}) *VaultVersionRepository_ListVersionsForVerification_Call {
+	return &VaultVersionRepository_ListVersionsForVerification_Call{Call: _e.mock.On("ListVersionsForVerification", ctx, verifiedBefore, limit)}
+}
+
+func (_c *VaultVersionRepository_ListVersionsForVerification_Call) Run(run func(ctx context.Context, verifiedBefore time.Time, limit int)) *VaultVersionRepository_ListVersionsForVerification_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(time.Time), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *VaultVersionRepository_ListVersionsForVerification_Call) Return(_a0 []models.VaultVersion, _a1 error) *VaultVersionRepository_ListVersionsForVerification_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *VaultVersionRepository_ListVersionsForVerification_Call) RunAndReturn(run func(context.Context, time.Time, int) ([]models.VaultVersion, error)) *VaultVersionRepository_ListVersionsForVerification_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListVersionsWithIntegrityProblems provides a mock function with given fields: ctx, limit, offset
+func (_m *VaultVersionRepository) ListVersionsWithIntegrityProblems(ctx context.Context, limit int, offset int) ([]models.VaultVersion, error) {
+	ret := _m.Called(ctx, limit, offset)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListVersionsWithIntegrityProblems")
+	}
+
+	var r0 []models.VaultVersion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) ([]models.VaultVersion, error)); ok {
+		return rf(ctx, limit, offset)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) []models.VaultVersion); ok {
+		r0 = rf(ctx, limit, offset)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.VaultVersion)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, limit, offset)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// VaultVersionRepository_ListVersionsWithIntegrityProblems_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListVersionsWithIntegrityProblems'
+type VaultVersionRepository_ListVersionsWithIntegrityProblems_Call struct {
+	*mock.Call
+}
+
+// ListVersionsWithIntegrityProblems is a helper method to define mock.On call
+//   - ctx context.Context
+//   - limit int
+//   - offset int
+func (_e *VaultVersionRepository_Expecter) ListVersionsWithIntegrityProblems(ctx interface{}, limit interface{}, offset interface{}) *VaultVersionRepository_ListVersionsWithIntegrityProblems_Call {
+	return &VaultVersionRepository_ListVersionsWithIntegrityProblems_Call{Call: _e.mock.On("ListVersionsWithIntegrityProblems", ctx, limit, offset)}
+}
+
+func (_c *VaultVersionRepository_ListVersionsWithIntegrityProblems_Call) Run(run func(ctx context.Context, limit int, offset int)) *VaultVersionRepository_ListVersionsWithIntegrityProblems_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *VaultVersionRepository_ListVersionsWithIntegrityProblems_Call) Return(_a0 []models.VaultVersion, _a1 error) *VaultVersionRepository_ListVersionsWithIntegrityProblems_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *VaultVersionRepository_ListVersionsWithIntegrityProblems_Call) RunAndReturn(run func(context.Context, int, int) ([]models.VaultVersion, error)) *VaultVersionRepository_ListVersionsWithIntegrityProblems_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateVerificationStatus provides a mock function with given fields: ctx, versionID, status, verifiedAt
+func (_m *VaultVersionRepository) UpdateVerificationStatus(ctx context.Context, versionID int64, status string, verifiedAt time.Time) error {
+	ret := _m.Called(ctx, versionID, status, verifiedAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateVerificationStatus")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, time.Time) error); ok {
+		r0 = rf(ctx, versionID, status, verifiedAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// VaultVersionRepository_UpdateVerificationStatus_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateVerificationStatus'
+type VaultVersionRepository_UpdateVerificationStatus_Call struct {
+	*mock.Call
+}
+
+// UpdateVerificationStatus is a helper method to define mock.On call
+//   - ctx context.Context
+//   - versionID int64
+//   - status string
+//   - verifiedAt time.Time
+func (_e *VaultVersionRepository_Expecter) UpdateVerificationStatus(ctx interface{}, versionID interface{}, status interface{}, verifiedAt interface{}) *VaultVersionRepository_UpdateVerificationStatus_Call {
+	return &VaultVersionRepository_UpdateVerificationStatus_Call{Call: _e.mock.On("UpdateVerificationStatus", ctx, versionID, status, verifiedAt)}
+}
+
+func (_c *VaultVersionRepository_UpdateVerificationStatus_Call) Run(run func(ctx context.Context, versionID int64, status string, verifiedAt time.Time)) *VaultVersionRepository_UpdateVerificationStatus_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(string), args[3].(time.Time))
+	})
+	return _c
+}
+
+func (_c *VaultVersionRepository_UpdateVerificationStatus_Call) Return(_a0 error) *VaultVersionRepository_UpdateVerificationStatus_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *VaultVersionRepository_UpdateVerificationStatus_Call) RunAndReturn(run func(context.Context, int64, string, time.Time) error) *VaultVersionRepository_UpdateVerificationStatus_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewVaultVersionRepository creates a new instance of VaultVersionRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewVaultVersionRepository(t interface {
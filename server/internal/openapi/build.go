@@ -0,0 +1,248 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3gen"
+
+	"github.com/maynagashev/gophkeeper/models"
+)
+
+// Префиксы версий API (см. setupRouter в cmd/server).
+const (
+	apiV1Prefix = "/api"
+	apiV2Prefix = "/api/v2"
+)
+
+// Имена схем аутентификации в спецификации.
+const (
+	bearerAuthScheme = "bearerAuth"
+	adminTokenScheme = "adminToken"
+)
+
+// errorDescriptions - описания ответов об ошибках по статусам.
+var errorDescriptions = map[int]string{ //nolint:gochecknoglobals // Неизменяемая таблица описаний
+	http.StatusBadRequest:                   "Некорректные данные запроса",
+	http.StatusUnauthorized:                 "Токен отсутствует, неверен или истек (для входа - неверные учетные данные)",
+	http.StatusForbidden:                    "Доступ запрещен (в том числе учетная запись отключена администратором)",
+	http.StatusNotFound:                     "Ресурс не найден",
+	http.StatusConflict:                     "Конфликт с данными на сервере",
+	http.StatusRequestEntityTooLarge:        "Размер тела запроса превышает лимит",
+	http.StatusUnsupportedMediaType:         "Файл не является базой KeePass поддерживаемой версии (KDBX 3.x/4.x)",
+	http.StatusRequestedRangeNotSatisfiable: "Запрошенный диапазон недостижим",
+	http.StatusInternalServerError:          "Внутренняя ошибка сервера",
+	http.StatusServiceUnavailable:           "Превышено время выполнения операции",
+}
+
+// builder собирает спецификацию: схемы JSON-тел генерируются по типам Go и выносятся в components.
+type builder struct {
+	doc *openapi3.T
+	gen *openapi3gen.Generator
+}
+
+// Build формирует спецификацию OpenAPI по описанию операций.
+// Операции API описываются дважды: для /api (ошибки текстом) и для /api/v2 (ошибки в формате models.ErrorResponse).
+func Build() (*openapi3.T, error) {
+	b := &builder{
+		doc: &openapi3.T{
+			OpenAPI: "3.0.3",
+			Info: &openapi3.Info{
+				Title:   "GophKeeper API",
+				Version: "2.0.0",
+				Description: "API сервера GophKeeper. Маршруты /api/v2 и /api совпадают; " +
+					"/api/v2 отвечает об ошибках в формате ErrorResponse, /api (v1) - текстом.",
+			},
+			Paths: openapi3.NewPaths(),
+			Components: &openapi3.Components{
+				Schemas: openapi3.Schemas{},
+				SecuritySchemes: openapi3.SecuritySchemes{
+					bearerAuthScheme: {Value: openapi3.NewJWTSecurityScheme()},
+					adminTokenScheme: {Value: openapi3.NewSecurityScheme().WithType("apiKey").
+						WithIn("header").WithName("X-Admin-Token")},
+				},
+			},
+		},
+		gen: openapi3gen.NewGenerator(
+			openapi3gen.CreateComponentSchemas(openapi3gen.ExportComponentSchemasOptions{
+				ExportComponentSchemas: true,
+				ExportTopLevelSchema:   true,
+			}),
+			openapi3gen.SchemaCustomizer(nullableSlices),
+		),
+	}
+
+	// Схемы, на которые ссылаются описания, но не тела операций
+	for _, value := range []any{models.ErrorResponse{}, models.VaultEvent{}} {
+		if _, err := b.schema(value); err != nil {
+			return nil, err
+		}
+	}
+	b.doc.Components.Schemas["ErrorResponse"].Value.Properties["code"].Value.WithEnum(errorCodes...)
+
+	for _, op := range operations() {
+		if !op.api {
+			if err := b.addOperation(op, op.path, 0); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err := b.addOperation(op, apiV2Prefix+op.path, apiV2); err != nil {
+			return nil, err
+		}
+		if err := b.addOperation(op, apiV1Prefix+op.path, apiV1); err != nil {
+			return nil, err
+		}
+	}
+	return b.doc, nil
+}
+
+// nullableSlices отмечает срезы как nullable: nil-срез кодируется в JSON как null.
+func nullableSlices(_ string, t reflect.Type, _ reflect.StructTag, schema *openapi3.Schema) error {
+	if t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8 {
+		schema.Nullable = true
+	}
+	return nil
+}
+
+// schema возвращает схему тела: готовую *openapi3.Schema или сгенерированную по типу значения.
+func (b *builder) schema(value any) (*openapi3.SchemaRef, error) {
+	if s, ok := value.(*openapi3.Schema); ok {
+		return s.NewRef(), nil
+	}
+	ref, err := b.gen.NewSchemaRefForValue(value, b.doc.Components.Schemas)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка генерации схемы %T: %w", value, err)
+	}
+	return ref, nil
+}
+
+func (b *builder) content(bd *body) (openapi3.Content, error) {
+	schema, err := b.schema(bd.value)
+	if err != nil {
+		return nil, err
+	}
+	return openapi3.Content{bd.contentType: openapi3.NewMediaType().WithSchemaRef(schema)}, nil
+}
+
+// addOperation добавляет операцию op по пути path в формате версии version (0 - вне API).
+func (b *builder) addOperation(op operation, path string, version apiVersion) error {
+	o := openapi3.NewOperation()
+	o.OperationID = op.id
+	if version == apiV1 {
+		o.OperationID += "V1"
+	}
+	o.Summary = op.summary
+	o.Tags = []string{op.tag}
+	for _, p := range op.params {
+		o.AddParameter(p)
+	}
+
+	switch op.auth {
+	case authBearer, authAdmin:
+		o.Security = openapi3.NewSecurityRequirements().With(openapi3.NewSecurityRequirement().Authenticate(bearerAuthScheme))
+	case authAdminToken:
+		o.Security = openapi3.NewSecurityRequirements().With(openapi3.NewSecurityRequirement().Authenticate(adminTokenScheme))
+	case authNone:
+	}
+
+	if op.request != nil {
+		content, err := b.content(op.request)
+		if err != nil {
+			return err
+		}
+		o.RequestBody = &openapi3.RequestBodyRef{Value: openapi3.NewRequestBody().WithRequired(true).WithContent(content)}
+	}
+
+	o.Responses = openapi3.NewResponsesWithCapacity(len(op.responses) + len(op.errors))
+	for _, resp := range op.responses {
+		if resp.version != 0 && resp.version != version {
+			continue
+		}
+		r := openapi3.NewResponse().WithDescription(resp.description)
+		if resp.body != nil {
+			content, err := b.content(resp.body)
+			if err != nil {
+				return err
+			}
+			r.WithContent(content)
+		}
+		if len(resp.headers) > 0 {
+			r.Headers = openapi3.Headers{}
+			for name, description := range resp.headers {
+				r.Headers[name] = &openapi3.HeaderRef{Value: &openapi3.Header{Parameter: openapi3.Parameter{
+					Description: description,
+					Schema:      openapi3.NewStringSchema().NewRef(),
+				}}}
+			}
+		}
+		o.Responses.Set(strconv.Itoa(resp.status), &openapi3.ResponseRef{Value: r})
+	}
+	if version != 0 {
+		if err := b.addErrorResponses(o, op, version); err != nil {
+			return err
+		}
+	}
+
+	b.doc.AddOperation(path, op.method, o)
+	return nil
+}
+
+// addErrorResponses добавляет ответы об ошибках операции, в том числе ошибки middleware аутентификации
+// и ограничения размера тела.
+func (b *builder) addErrorResponses(o *openapi3.Operation, op operation, version apiVersion) error {
+	statuses := append([]int(nil), op.errors...)
+	switch op.auth {
+	case authBearer, authAdmin:
+		// Учетная запись владельца токена загружается при каждом запросе: отключенной отвечает 403
+		statuses = append(statuses, http.StatusUnauthorized, http.StatusForbidden, http.StatusInternalServerError)
+	case authAdminToken:
+		statuses = append(statuses, http.StatusForbidden)
+	case authNone:
+	}
+
+	errBody := textBody()
+	if version == apiV2 {
+		errBody = jsonBody(models.ErrorResponse{})
+	}
+	for _, status := range statuses {
+		if o.Responses.Status(status) != nil {
+			continue
+		}
+		description, ok := errorDescriptions[status]
+		if !ok {
+			return fmt.Errorf("нет описания ответа об ошибке %d (%s %s)", status, op.method, op.path)
+		}
+		content, err := b.content(errBody)
+		if err != nil {
+			return err
+		}
+		o.Responses.Set(strconv.Itoa(status),
+			&openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription(description).WithContent(content)})
+	}
+	return nil
+}
+
+// errorCodes - все коды ошибок API v2 (models.ErrCode*).
+var errorCodes = []any{ //nolint:gochecknoglobals // Неизменяемый список кодов
+	models.ErrCodeInvalidRequest,
+	models.ErrCodeUnauthorized,
+	models.ErrCodeInvalidCredentials,
+	models.ErrCodeAccountDisabled,
+	models.ErrCodeForbidden,
+	models.ErrCodeUsernameTaken,
+	models.ErrCodeVaultNotFound,
+	models.ErrCodeVersionNotFound,
+	models.ErrCodeVersionConflict,
+	models.ErrCodeNoAccessRequest,
+	models.ErrCodeNotFound,
+	models.ErrCodePayloadTooLarge,
+	models.ErrCodeUnsupportedMedia,
+	models.ErrCodeRangeNotSatisfiable,
+	models.ErrCodeVaultCorrupted,
+	models.ErrCodeTimeout,
+	models.ErrCodeInternal,
+}
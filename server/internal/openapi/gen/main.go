@@ -0,0 +1,20 @@
+// Команда gen записывает спецификацию OpenAPI в openapi.json (запускается через go generate в каталоге пакета openapi).
+package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/maynagashev/gophkeeper/server/internal/openapi"
+)
+
+func main() {
+	data, err := openapi.Generate()
+	if err != nil {
+		log.Fatalf("Ошибка генерации спецификации OpenAPI: %v", err)
+	}
+	if err = os.WriteFile(openapi.SpecFile, data, 0o600); err != nil {
+		log.Fatalf("Ошибка записи %s: %v", openapi.SpecFile, err)
+	}
+	log.Printf("Спецификация OpenAPI записана в %s", openapi.SpecFile)
+}
@@ -0,0 +1,70 @@
+// Package openapi описывает HTTP API сервера в формате OpenAPI 3.
+//
+// Спецификация генерируется функцией Build по таблице операций (operations.go) и схемам типов
+// пакета models и хранится в openapi.json (обновляется командой go generate). Сервер отдает ее
+// на /api/openapi.json, а тесты проверяют по ней запросы и ответы настоящих обработчиков (см. Validator).
+package openapi
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+//go:generate go run ./gen
+
+// SpecFile - имя файла спецификации в каталоге пакета.
+const SpecFile = "openapi.json"
+
+//go:embed openapi.json
+var specJSON []byte
+
+// JSON возвращает сгенерированную спецификацию в формате JSON.
+func JSON() []byte {
+	return specJSON
+}
+
+// Generate формирует спецификацию (см. Build) и возвращает ее в том виде, в каком она хранится в openapi.json.
+func Generate() ([]byte, error) {
+	doc, err := Build()
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка кодирования спецификации OpenAPI: %w", err)
+	}
+	var buf bytes.Buffer
+	if err = json.Indent(&buf, data, "", "  "); err != nil {
+		return nil, fmt.Errorf("ошибка форматирования спецификации OpenAPI: %w", err)
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// Load разбирает встроенную спецификацию и проверяет ее корректность.
+func Load() (*openapi3.T, error) {
+	doc, err := openapi3.NewLoader().LoadFromData(specJSON)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка разбора спецификации OpenAPI: %w", err)
+	}
+	if err = doc.Validate(context.Background()); err != nil {
+		return nil, fmt.Errorf("спецификация OpenAPI некорректна: %w", err)
+	}
+	return doc, nil
+}
+
+// Handler отдает спецификацию OpenAPI (GET /api/openapi.json).
+func Handler(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(specJSON); err != nil {
+		log.Printf("[OpenAPI] Ошибка отправки спецификации: %v", err)
+	}
+}
@@ -0,0 +1,120 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/maynagashev/gophkeeper/models"
+	"github.com/maynagashev/gophkeeper/server/internal/openapi"
+)
+
+func TestSpec(t *testing.T) {
+	t.Run("openapi.json соответствует описанию операций", func(t *testing.T) {
+		generated, err := openapi.Generate()
+		require.NoError(t, err)
+		assert.JSONEq(t, string(generated), string(openapi.JSON()),
+			"Спецификация устарела, выполните go generate ./internal/openapi")
+	})
+
+	t.Run("Спецификация корректна", func(t *testing.T) {
+		doc, err := openapi.Load()
+		require.NoError(t, err)
+		assert.NotNil(t, doc.Paths.Value("/api/v2/vault/upload"))
+		assert.NotNil(t, doc.Paths.Value("/api/vault/upload"))
+		assert.NotNil(t, doc.Paths.Value("/healthz"))
+	})
+
+	t.Run("Ошибки описаны по-разному для API v1 и v2", func(t *testing.T) {
+		doc, err := openapi.Load()
+		require.NoError(t, err)
+
+		v2 := doc.Paths.Value("/api/v2/vault").Get.Responses.Status(http.StatusNotFound).Value
+		assert.Equal(t, "#/components/schemas/ErrorResponse", v2.Content.Get("application/json").Schema.Ref)
+		v1 := doc.Paths.Value("/api/vault").Get.Responses.Status(http.StatusNotFound).Value
+		assert.NotNil(t, v1.Content.Get("text/plain"))
+		assert.Nil(t, v1.Content.Get("application/json"))
+	})
+
+	t.Run("Отдача спецификации", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		openapi.Handler(rr, httptest.NewRequest(http.MethodGet, "/api/openapi.json", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+		var doc map[string]any
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &doc))
+		assert.Equal(t, "3.0.3", doc["openapi"])
+	})
+}
+
+func TestValidator(t *testing.T) {
+	doc, err := openapi.Load()
+	require.NoError(t, err)
+
+	// serve выполняет запрос через Validator и возвращает ошибки проверки
+	serve := func(t *testing.T, handler http.HandlerFunc, req *http.Request) []error {
+		t.Helper()
+		var errs []error
+		v, err := openapi.NewValidator(doc, func(_ *http.Request, err error) { errs = append(errs, err) })
+		require.NoError(t, err)
+		v.Middleware(handler).ServeHTTP(httptest.NewRecorder(), req)
+		return errs
+	}
+	loginReq := func(body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/api/v2/login", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		return req
+	}
+	loginOK := func(w http.ResponseWriter, r *http.Request) {
+		var req models.LoginRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req), "Тело запроса должно остаться доступным обработчику")
+		assert.Equal(t, "user", req.Username)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(models.LoginResponse{Token: "token"})
+	}
+
+	t.Run("Запрос и ответ соответствуют спецификации", func(t *testing.T) {
+		assert.Empty(t, serve(t, loginOK, loginReq(`{"username":"user","password":"secret"}`)))
+	})
+
+	t.Run("Неверный запрос", func(t *testing.T) {
+		errs := serve(t, func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(models.ErrorResponse{Code: models.ErrCodeInvalidRequest, Message: "Неверный формат"})
+		}, loginReq(`{"username":"user","password":1}`))
+		require.Len(t, errs, 1)
+		assert.ErrorIs(t, errs[0], openapi.ErrInvalidRequest)
+	})
+
+	t.Run("Неверный ответ", func(t *testing.T) {
+		errs := serve(t, func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"token":42}`))
+		}, loginReq(`{"username":"user","password":"secret"}`))
+		require.Len(t, errs, 1)
+		assert.ErrorIs(t, errs[0], openapi.ErrInvalidResponse)
+	})
+
+	t.Run("Статус ответа не описан", func(t *testing.T) {
+		errs := serve(t, func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+		}, loginReq(`{"username":"user","password":"secret"}`))
+		require.Len(t, errs, 1)
+		assert.ErrorIs(t, errs[0], openapi.ErrInvalidResponse)
+	})
+
+	t.Run("Маршрут не описан", func(t *testing.T) {
+		errs := serve(t, func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}, httptest.NewRequest(http.MethodGet, "/api/v2/unknown", nil))
+		require.Len(t, errs, 1)
+		assert.ErrorIs(t, errs[0], openapi.ErrUndocumentedRoute)
+	})
+}
@@ -0,0 +1,455 @@
+package openapi
+
+import (
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/maynagashev/gophkeeper/models"
+	"github.com/maynagashev/gophkeeper/server/internal/handlers"
+)
+
+// authScheme - способ аутентификации операции.
+type authScheme int
+
+const (
+	authNone       authScheme = iota // Публичная операция
+	authBearer                       // JWT пользователя (Authorization: Bearer)
+	authAdmin                        // JWT пользователя с ролью администратора
+	authAdminToken                   // Служебный токен администратора (X-Admin-Token)
+)
+
+// apiVersion - версия API, для которой описывается ответ (0 - для обеих).
+type apiVersion int
+
+const (
+	apiV1 apiVersion = 1 // /api: ошибки текстом
+	apiV2 apiVersion = 2 // /api/v2: ошибки в формате models.ErrorResponse
+)
+
+// operation описывает одну операцию HTTP API.
+// Для операций API (api=true) путь задается относительно /api и описывается для /api и /api/v2.
+type operation struct {
+	method    string
+	path      string
+	api       bool
+	id        string // operationId для /api/v2 (для /api добавляется суффикс V1)
+	summary   string
+	tag       string
+	auth      authScheme
+	params    []*openapi3.Parameter
+	request   *body
+	responses []response
+	errors    []int // Статусы ответов об ошибках (формат зависит от версии API)
+}
+
+// body описывает тело запроса или ответа: value - значение Go-типа, по которому генерируется
+// JSON-схема, или готовая *openapi3.Schema для других форматов.
+type body struct {
+	contentType string
+	value       any
+}
+
+// response описывает успешный ответ операции (body nil - ответ без тела).
+type response struct {
+	status      int
+	description string
+	body        *body
+	headers     map[string]string // Заголовки ответа и их описания
+	version     apiVersion
+}
+
+func jsonBody(value any) *body {
+	return &body{contentType: "application/json", value: value}
+}
+
+func textBody() *body {
+	return &body{contentType: "text/plain", value: openapi3.NewStringSchema()}
+}
+
+func binaryBody() *body {
+	return &body{contentType: "application/octet-stream", value: openapi3.NewStringSchema().WithFormat("binary")}
+}
+
+func paginationParams() []*openapi3.Parameter {
+	return []*openapi3.Parameter{
+		openapi3.NewQueryParameter("limit").WithSchema(openapi3.NewIntegerSchema()).
+			WithDescription("Количество записей (по умолчанию 20, не больше 100)"),
+		openapi3.NewQueryParameter("offset").WithSchema(openapi3.NewIntegerSchema()).
+			WithDescription("Смещение от начала списка"),
+	}
+}
+
+func usernameParam() *openapi3.Parameter {
+	return openapi3.NewPathParameter("username").WithSchema(openapi3.NewStringSchema()).
+		WithDescription("Имя пользователя")
+}
+
+func webhookIDParam() *openapi3.Parameter {
+	return openapi3.NewPathParameter("webhookID").WithSchema(openapi3.NewInt64Schema()).
+		WithDescription("ID вебхука")
+}
+
+func contactIDParam() *openapi3.Parameter {
+	return openapi3.NewPathParameter("contactID").WithSchema(openapi3.NewInt64Schema()).
+		WithDescription("ID пользователя - доверенного контакта")
+}
+
+func ownerIDParam() *openapi3.Parameter {
+	return openapi3.NewPathParameter("ownerID").WithSchema(openapi3.NewInt64Schema()).
+		WithDescription("ID владельца хранилища, назначившего пользователя доверенным контактом")
+}
+
+// ownerVaultParam - параметр чтения хранилища владельца доверенным контактом (см. EmergencyAccessHandler.OwnerVault).
+func ownerVaultParam() *openapi3.Parameter {
+	return openapi3.NewQueryParameter("owner_id").WithSchema(openapi3.NewInt64Schema()).
+		WithDescription("ID владельца хранилища: доверенный контакт читает его хранилище после открытия доступа")
+}
+
+// downloadHeaders - заголовки ответа на скачивание хранилища.
+func downloadHeaders() map[string]string {
+	return map[string]string{
+		"ETag":                    "Контрольная сумма (SHA256) версии",
+		"Last-Modified":           "Время создания версии на сервере",
+		"Accept-Ranges":           "Поддерживаются запросы части файла (bytes)",
+		handlers.IntegrityTrailer: "Результат проверки контрольной суммы: ok или corrupted (трейлер при потоковой отдаче)",
+	}
+}
+
+// operations возвращает описание всех маршрутов сервера (см. setupRouter в cmd/server).
+//
+//nolint:funlen // Таблица операций
+func operations() []operation {
+	downloadParams := []*openapi3.Parameter{
+		openapi3.NewHeaderParameter("If-None-Match").WithSchema(openapi3.NewStringSchema()).
+			WithDescription("ETag имеющейся у клиента версии: при совпадении ответ 304"),
+		openapi3.NewHeaderParameter("If-Modified-Since").WithSchema(openapi3.NewStringSchema()).
+			WithDescription("Учитывается только без If-None-Match"),
+		openapi3.NewHeaderParameter("Range").WithSchema(openapi3.NewStringSchema()).
+			WithDescription("Диапазон байтов для продолжения прерванного скачивания (bytes=N-)"),
+		openapi3.NewHeaderParameter("If-Range").WithSchema(openapi3.NewStringSchema()).
+			WithDescription("ETag версии, для которой запрошен диапазон"),
+		ownerVaultParam(),
+	}
+	downloadResponses := []response{
+		{status: http.StatusOK, description: "Файл текущей версии", body: binaryBody(), headers: downloadHeaders()},
+		{status: http.StatusPartialContent, description: "Часть файла текущей версии", body: binaryBody(),
+			headers: map[string]string{"Content-Range": "Отданный диапазон байтов"}},
+		{status: http.StatusNotModified, description: "Версия не изменилась"},
+	}
+
+	return []operation{
+		// --- Служебные маршруты --- //
+		{
+			method: http.MethodGet, path: "/ping", id: "ping", tag: "service",
+			summary:   "Проверка доступности сервера",
+			responses: []response{{status: http.StatusOK, description: "pong", body: textBody()}},
+		},
+		{
+			method: http.MethodGet, path: "/healthz", id: "liveness", tag: "service",
+			summary: "Проверка того, что процесс сервера жив",
+			responses: []response{
+				{status: http.StatusOK, description: "Сервер работает", body: jsonBody(models.HealthReport{})},
+			},
+		},
+		{
+			method: http.MethodGet, path: "/readyz", id: "readiness", tag: "service",
+			summary: "Проверка доступности БД и хранилища файлов",
+			responses: []response{
+				{status: http.StatusOK, description: "Все зависимости доступны", body: jsonBody(models.HealthReport{})},
+				{status: http.StatusServiceUnavailable, description: "Часть зависимостей недоступна",
+					body: jsonBody(models.HealthReport{})},
+			},
+		},
+		{
+			method: http.MethodGet, path: "/metrics", id: "metrics", tag: "service",
+			summary: "Метрики Prometheus (если включены на основном порту)",
+			responses: []response{
+				{status: http.StatusOK, description: "Метрики в текстовом формате Prometheus", body: textBody()},
+			},
+		},
+		{
+			method: http.MethodGet, path: "/api/openapi.json", id: "openapi", tag: "service",
+			summary: "Спецификация OpenAPI",
+			responses: []response{
+				{status: http.StatusOK, description: "Этот документ", body: jsonBody(openapi3.NewObjectSchema())},
+			},
+		},
+
+		// --- Авторизация --- //
+		{
+			method: http.MethodPost, path: "/register", api: true, id: "register", tag: "auth",
+			summary:   "Регистрация пользователя",
+			request:   jsonBody(models.RegisterRequest{}),
+			responses: []response{{status: http.StatusCreated, description: "Пользователь зарегистрирован", body: textBody()}},
+			errors: []int{
+				http.StatusBadRequest, http.StatusConflict, http.StatusInternalServerError, http.StatusServiceUnavailable,
+			},
+		},
+		{
+			method: http.MethodPost, path: "/login", api: true, id: "login", tag: "auth",
+			summary:   "Вход и получение JWT",
+			request:   jsonBody(models.LoginRequest{}),
+			responses: []response{{status: http.StatusOK, description: "Токен доступа", body: jsonBody(models.LoginResponse{})}},
+			errors: []int{
+				http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden,
+				http.StatusInternalServerError, http.StatusServiceUnavailable,
+			},
+		},
+
+		// --- Хранилище --- //
+		{
+			method: http.MethodGet, path: "/vault", api: true, id: "getVaultMetadata", tag: "vault", auth: authBearer,
+			summary: "Метаданные текущей версии хранилища",
+			params:  []*openapi3.Parameter{ownerVaultParam()},
+			responses: []response{
+				{status: http.StatusOK, description: "Текущая версия", body: jsonBody(models.VaultVersion{})},
+			},
+			errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusInternalServerError},
+		},
+		{
+			method: http.MethodPost, path: "/vault/upload", api: true, id: "uploadVault", tag: "vault", auth: authBearer,
+			summary: "Загрузка новой версии хранилища",
+			params: []*openapi3.Parameter{
+				openapi3.NewHeaderParameter("X-Kdbx-Content-Modified-At").WithRequired(true).
+					WithSchema(openapi3.NewDateTimeSchema()).
+					WithDescription("Время изменения содержимого KDBX (Root.LastModificationTime), RFC3339 UTC"),
+				openapi3.NewHeaderParameter(models.HeaderClientName).WithSchema(openapi3.NewStringSchema()).
+					WithDescription("Имя клиентского приложения, сохраняется с версией"),
+				openapi3.NewHeaderParameter(models.HeaderClientVersion).WithSchema(openapi3.NewStringSchema()).
+					WithDescription("Версия клиентского приложения, сохраняется с версией"),
+				openapi3.NewHeaderParameter(models.HeaderDeviceID).WithSchema(openapi3.NewStringSchema()).
+					WithDescription("Идентификатор установки клиента на устройстве, сохраняется с версией"),
+			},
+			request: binaryBody(),
+			responses: []response{
+				{status: http.StatusOK, description: "Файл загружен", body: textBody(), version: apiV1},
+				{status: http.StatusOK, description: "Метаданные созданной (или совпавшей текущей) версии",
+					body: jsonBody(models.UploadResponse{}), version: apiV2},
+			},
+			errors: []int{
+				http.StatusBadRequest, http.StatusConflict, http.StatusRequestEntityTooLarge,
+				http.StatusUnsupportedMediaType, http.StatusInternalServerError, http.StatusServiceUnavailable,
+			},
+		},
+		{
+			method: http.MethodGet, path: "/vault/download", api: true, id: "downloadVault", tag: "vault",
+			auth: authBearer, summary: "Скачивание текущей версии хранилища",
+			params: downloadParams, responses: downloadResponses,
+			errors: []int{
+				http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusRequestedRangeNotSatisfiable,
+				http.StatusInternalServerError, http.StatusServiceUnavailable,
+			},
+		},
+		{
+			method: http.MethodHead, path: "/vault/download", api: true, id: "headVault", tag: "vault",
+			auth: authBearer, summary: "Заголовки скачивания текущей версии без тела",
+			params: downloadParams,
+			responses: []response{
+				{status: http.StatusOK, description: "Версия доступна для скачивания", headers: downloadHeaders()},
+				{status: http.StatusNotModified, description: "Версия не изменилась"},
+			},
+			errors: []int{
+				http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound,
+				http.StatusInternalServerError, http.StatusServiceUnavailable,
+			},
+		},
+		{
+			method: http.MethodGet, path: "/vault/versions", api: true, id: "listVersions", tag: "vault", auth: authBearer,
+			summary: "Список версий хранилища (сначала новые)",
+			params:  append(paginationParams(), ownerVaultParam()),
+			responses: []response{
+				{status: http.StatusOK, description: "Версии и ID текущей версии", body: jsonBody(models.UserVersions{})},
+			},
+			errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusInternalServerError},
+		},
+		{
+			method: http.MethodPost, path: "/vault/rollback", api: true, id: "rollbackVault", tag: "vault", auth: authBearer,
+			summary:   "Откат хранилища к одной из прежних версий",
+			request:   jsonBody(handlers.RollbackRequest{}),
+			responses: []response{{status: http.StatusNoContent, description: "Откат выполнен"}},
+			errors: []int{
+				http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound,
+				http.StatusInternalServerError, http.StatusServiceUnavailable,
+			},
+		},
+		{
+			method: http.MethodGet, path: "/vault/events", api: true, id: "streamVaultEvents", tag: "vault",
+			auth: authBearer, summary: "Поток событий хранилища (Server-Sent Events)",
+			responses: []response{{
+				status: http.StatusOK,
+				description: "Поток событий: строка event с типом события и строка data с JSON VaultEvent; " +
+					"комментарии ': ping' поддерживают соединение",
+				body: &body{contentType: "text/event-stream", value: openapi3.NewStringSchema()},
+			}},
+			errors: []int{http.StatusInternalServerError},
+		},
+
+		// --- Вебхуки --- //
+		{
+			method: http.MethodGet, path: "/webhooks", api: true, id: "listWebhooks", tag: "webhooks", auth: authBearer,
+			summary:   "Список вебхуков пользователя",
+			responses: []response{{status: http.StatusOK, description: "Вебхуки", body: jsonBody([]models.Webhook{})}},
+			errors:    []int{http.StatusInternalServerError},
+		},
+		{
+			method: http.MethodPost, path: "/webhooks", api: true, id: "createWebhook", tag: "webhooks", auth: authBearer,
+			summary: "Регистрация вебхука",
+			request: jsonBody(models.CreateWebhookRequest{}),
+			responses: []response{
+				{status: http.StatusCreated, description: "Вебхук с секретом подписи", body: jsonBody(models.Webhook{})},
+			},
+			errors: []int{http.StatusBadRequest, http.StatusInternalServerError},
+		},
+		{
+			method: http.MethodDelete, path: "/webhooks/{webhookID}", api: true, id: "deleteWebhook", tag: "webhooks",
+			auth: authBearer, summary: "Удаление вебхука",
+			params:    []*openapi3.Parameter{webhookIDParam()},
+			responses: []response{{status: http.StatusNoContent, description: "Вебхук удален"}},
+			errors:    []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError},
+		},
+		{
+			method: http.MethodGet, path: "/webhooks/{webhookID}/deliveries", api: true, id: "listWebhookDeliveries",
+			tag: "webhooks", auth: authBearer, summary: "История доставок вебхука",
+			params: append([]*openapi3.Parameter{webhookIDParam()}, paginationParams()...),
+			responses: []response{
+				{status: http.StatusOK, description: "Доставки (сначала новые)", body: jsonBody([]models.WebhookDelivery{})},
+			},
+			errors: []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError},
+		},
+
+		// --- Экстренный доступ --- //
+		{
+			method: http.MethodGet, path: "/emergency/contacts", api: true, id: "listEmergencyContacts",
+			tag: "emergency", auth: authBearer, summary: "Доверенные контакты владельца",
+			responses: []response{
+				{status: http.StatusOK, description: "Контакты и статусы их доступа", body: jsonBody([]models.EmergencyAccess{})},
+			},
+			errors: []int{http.StatusInternalServerError},
+		},
+		{
+			method: http.MethodPost, path: "/emergency/contacts", api: true, id: "setEmergencyContact",
+			tag: "emergency", auth: authBearer, summary: "Назначение доверенного контакта или изменение периода ожидания",
+			request: jsonBody(models.SetEmergencyContactRequest{}),
+			responses: []response{
+				{status: http.StatusOK, description: "Доверенный контакт", body: jsonBody(models.EmergencyAccess{})},
+			},
+			errors: []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError},
+		},
+		{
+			method: http.MethodDelete, path: "/emergency/contacts/{contactID}", api: true, id: "removeEmergencyContact",
+			tag: "emergency", auth: authBearer, summary: "Отмена назначения доверенного контакта",
+			params:    []*openapi3.Parameter{contactIDParam()},
+			responses: []response{{status: http.StatusNoContent, description: "Контакт и оставленный для него ключ удалены"}},
+			errors:    []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError},
+		},
+		{
+			method: http.MethodPut, path: "/emergency/contacts/{contactID}/envelope", api: true,
+			id: "setEmergencyKeyEnvelope", tag: "emergency", auth: authBearer,
+			summary:   "Сохранение ключа к хранилищу, зашифрованного на клиенте для контакта",
+			params:    []*openapi3.Parameter{contactIDParam()},
+			request:   jsonBody(models.KeyEnvelope{}),
+			responses: []response{{status: http.StatusNoContent, description: "Ключ сохранен"}},
+			errors: []int{
+				http.StatusBadRequest, http.StatusNotFound, http.StatusRequestEntityTooLarge, http.StatusInternalServerError,
+			},
+		},
+		{
+			method: http.MethodPost, path: "/emergency/contacts/{contactID}/deny", api: true, id: "denyEmergencyAccess",
+			tag: "emergency", auth: authBearer, summary: "Отклонение запроса экстренного доступа",
+			params: []*openapi3.Parameter{contactIDParam()},
+			responses: []response{
+				{status: http.StatusOK, description: "Доступ закрыт", body: jsonBody(models.EmergencyAccess{})},
+			},
+			errors: []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusInternalServerError},
+		},
+		{
+			method: http.MethodGet, path: "/emergency/grants", api: true, id: "listEmergencyGrants",
+			tag: "emergency", auth: authBearer, summary: "Владельцы, назначившие пользователя доверенным контактом",
+			responses: []response{
+				{status: http.StatusOK, description: "Доступы к хранилищам владельцев",
+					body: jsonBody([]models.EmergencyAccess{})},
+			},
+			errors: []int{http.StatusInternalServerError},
+		},
+		{
+			method: http.MethodPost, path: "/emergency/grants/{ownerID}/request", api: true, id: "requestEmergencyAccess",
+			tag: "emergency", auth: authBearer, summary: "Запрос экстренного доступа к хранилищу владельца",
+			params: []*openapi3.Parameter{ownerIDParam()},
+			responses: []response{
+				{status: http.StatusOK, description: "Доступ со временем его открытия (access_available_at)",
+					body: jsonBody(models.EmergencyAccess{})},
+			},
+			errors: []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError},
+		},
+		{
+			method: http.MethodGet, path: "/emergency/grants/{ownerID}/envelope", api: true, id: "getEmergencyKeyEnvelope",
+			tag: "emergency", auth: authBearer, summary: "Ключ к хранилищу владельца после открытия доступа",
+			params: []*openapi3.Parameter{ownerIDParam()},
+			responses: []response{
+				{status: http.StatusOK, description: "Ключ, зашифрованный для контакта", body: jsonBody(models.KeyEnvelope{})},
+			},
+			errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusInternalServerError},
+		},
+
+		// --- Администрирование --- //
+		{
+			method: http.MethodGet, path: "/admin/storage/integrity", api: true, id: "storageIntegrity", tag: "admin",
+			auth: authAdminToken, summary: "Итоги проверки целостности и проблемные версии",
+			params: paginationParams(),
+			responses: []response{
+				{status: http.StatusOK, description: "Отчет", body: jsonBody(models.StorageIntegrityResponse{})},
+			},
+			errors: []int{http.StatusInternalServerError},
+		},
+		{
+			method: http.MethodPost, path: "/admin/storage/scrub", api: true, id: "storageScrub", tag: "admin",
+			auth: authAdminToken, summary: "Внеочередная проверка целостности объектов",
+			responses: []response{
+				{status: http.StatusOK, description: "Итоги проверки", body: jsonBody(models.StorageScrubReport{})},
+			},
+			errors: []int{http.StatusInternalServerError},
+		},
+		{
+			method: http.MethodGet, path: "/admin/users", api: true, id: "listUsers", tag: "admin", auth: authAdmin,
+			summary: "Пользователи со статистикой хранилищ",
+			params: append([]*openapi3.Parameter{
+				openapi3.NewQueryParameter("search").WithSchema(openapi3.NewStringSchema()).
+					WithDescription("Подстрока имени пользователя"),
+			}, paginationParams()...),
+			responses: []response{
+				{status: http.StatusOK, description: "Пользователи", body: jsonBody([]models.UserSummary{})},
+			},
+			errors: []int{http.StatusInternalServerError},
+		},
+		{
+			method: http.MethodPost, path: "/admin/users/{username}/disable", api: true, id: "disableUser", tag: "admin",
+			auth: authAdmin, summary: "Отключение учетной записи",
+			params:    []*openapi3.Parameter{usernameParam()},
+			responses: []response{{status: http.StatusNoContent, description: "Учетная запись отключена"}},
+			errors:    []int{http.StatusNotFound, http.StatusInternalServerError},
+		},
+		{
+			method: http.MethodPost, path: "/admin/users/{username}/enable", api: true, id: "enableUser", tag: "admin",
+			auth: authAdmin, summary: "Включение учетной записи",
+			params:    []*openapi3.Parameter{usernameParam()},
+			responses: []response{{status: http.StatusNoContent, description: "Учетная запись включена"}},
+			errors:    []int{http.StatusNotFound, http.StatusInternalServerError},
+		},
+		{
+			method: http.MethodGet, path: "/admin/users/{username}/storage", api: true, id: "userStorage", tag: "admin",
+			auth: authAdmin, summary: "Использование хранилища пользователем",
+			params: []*openapi3.Parameter{usernameParam()},
+			responses: []response{
+				{status: http.StatusOK, description: "Объем хранилища", body: jsonBody(models.UserStorageUsage{})},
+			},
+			errors: []int{http.StatusNotFound, http.StatusInternalServerError},
+		},
+		{
+			method: http.MethodGet, path: "/admin/stats", api: true, id: "serverStats", tag: "admin", auth: authAdmin,
+			summary:   "Сводная статистика сервера",
+			responses: []response{{status: http.StatusOK, description: "Статистика", body: jsonBody(models.ServerStats{})}},
+			errors:    []int{http.StatusInternalServerError},
+		},
+	}
+}
@@ -0,0 +1,128 @@
+package openapi
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/legacy"
+)
+
+// Виды несоответствия спецификации, о которых сообщает Validator (проверяются через errors.Is).
+var (
+	ErrUndocumentedRoute = errors.New("маршрут не описан в спецификации")
+	ErrInvalidRequest    = errors.New("запрос не соответствует спецификации")
+	ErrInvalidResponse   = errors.New("ответ не соответствует спецификации")
+)
+
+func init() { //nolint:gochecknoinits // Регистрация формата тела в общем реестре openapi3filter
+	// Поток событий проверяется как текст: схема описывает его как строку
+	openapi3filter.RegisterBodyDecoder("text/event-stream", openapi3filter.PlainBodyDecoder)
+}
+
+// Validator проверяет запросы и ответы HTTP API на соответствие спецификации.
+// Предназначен для тестов: запросы и ответы не изменяются, о несоответствиях сообщается через report.
+type Validator struct {
+	router  routers.Router
+	options *openapi3filter.Options
+	report  func(r *http.Request, err error)
+}
+
+// NewValidator создает Validator для спецификации doc.
+// Аутентификация не проверяется (ее выполняет middleware сервера), а статусы ответов,
+// не описанные в спецификации, считаются несоответствием.
+func NewValidator(doc *openapi3.T, report func(r *http.Request, err error)) (*Validator, error) {
+	router, err := legacy.NewRouter(doc)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка построения маршрутов по спецификации: %w", err)
+	}
+	return &Validator{
+		router: router,
+		options: &openapi3filter.Options{
+			AuthenticationFunc:    openapi3filter.NoopAuthenticationFunc,
+			IncludeResponseStatus: true,
+			MultiError:            true,
+		},
+		report: report,
+	}, nil
+}
+
+// Middleware проверяет запрос перед вызовом next и ответ после него.
+func (v *Validator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route, pathParams, err := v.router.FindRoute(r)
+		if err != nil {
+			v.report(r, fmt.Errorf("%w: %s %s: %w", ErrUndocumentedRoute, r.Method, r.URL.Path, err))
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// ValidateRequest читает тело запроса и подставляет вместо него прочитанную копию
+		input := &openapi3filter.RequestValidationInput{
+			Request:    r,
+			PathParams: pathParams,
+			Route:      route,
+			Options:    v.options,
+		}
+		if err = openapi3filter.ValidateRequest(r.Context(), input); err != nil {
+			v.report(r, fmt.Errorf("%w: %s %s: %w", ErrInvalidRequest, r.Method, r.URL.Path, err))
+		}
+
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		// Без явного Content-Type net/http определяет его по содержимому тела - так же поступаем и здесь
+		header := rec.Header().Clone()
+		if header.Get("Content-Type") == "" && rec.body.Len() > 0 {
+			header.Set("Content-Type", http.DetectContentType(rec.body.Bytes()))
+		}
+		output := &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: input,
+			Status:                 rec.status,
+			Header:                 header,
+			Options:                v.options,
+		}
+		output.SetBodyBytes(rec.body.Bytes())
+		if err = openapi3filter.ValidateResponse(r.Context(), output); err != nil {
+			v.report(r, fmt.Errorf("%w: %s %s (статус %d): %w", ErrInvalidResponse, r.Method, r.URL.Path, rec.status, err))
+		}
+	})
+}
+
+// responseRecorder передает ответ дальше, сохраняя статус и копию тела для проверки.
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	if !rec.wroteHeader {
+		rec.status = status
+		rec.wroteHeader = true
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *responseRecorder) Write(p []byte) (int, error) {
+	rec.wroteHeader = true
+	rec.body.Write(p)
+	return rec.ResponseWriter.Write(p)
+}
+
+// Flush поддерживает потоковые ответы (поток событий).
+func (rec *responseRecorder) Flush() {
+	if f, ok := rec.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap открывает исходный ResponseWriter для http.ResponseController.
+func (rec *responseRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
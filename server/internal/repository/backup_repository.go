@@ -0,0 +1,273 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// importBatchSize - количество строк, вставляемых одним запросом при восстановлении.
+const importBatchSize = 500
+
+// backupTable - таблица резервной копии и версия схемы (номер миграции), в которой она создана.
+type backupTable struct {
+	name  string
+	since uint
+}
+
+// backupTables - таблицы, входящие в резервную копию, в порядке восстановления:
+// каждая таблица ссылается только на таблицы, расположенные выше.
+// Ссылка vaults.current_version_id проверяется в конце транзакции (миграция 000010).
+var backupTables = []backupTable{ //nolint:gochecknoglobals // Неизменяемый список таблиц
+	{name: "users", since: 1},
+	{name: "user_devices", since: 5},
+	{name: "emergency_access", since: 13},
+	{name: "vaults", since: 1},
+	{name: "vault_versions", since: 2},
+	{name: "webhooks", since: 5},
+	{name: "webhook_deliveries", since: 5},
+}
+
+// BackupTables возвращает таблицы резервной копии схемы версии schemaVersion в порядке восстановления.
+// Состав зависит от версии, поэтому копии, созданные до появления новых таблиц, остаются совместимыми.
+func BackupTables(schemaVersion uint) []string {
+	tables := make([]string, 0, len(backupTables))
+	for _, table := range backupTables {
+		if table.since <= schemaVersion {
+			tables = append(tables, table.name)
+		}
+	}
+	return tables
+}
+
+// ErrUnknownBackupTable возвращается при обращении к таблице, не входящей в резервную копию.
+var ErrUnknownBackupTable = errors.New("таблица не входит в резервную копию")
+
+// BackupObject - объект файлового хранилища, на который ссылаются метаданные.
+type BackupObject struct {
+	Key string `db:"object_key"`
+	// Checksum - ожидаемая контрольная сумма (SHA256) объекта, если она известна.
+	Checksum *string `db:"checksum"`
+}
+
+// BackupTx - операции с БД, выполняемые в транзакции резервного копирования или восстановления.
+type BackupTx interface {
+	// SchemaVersion возвращает примененную версию схемы и признак незавершенной миграции.
+	SchemaVersion(ctx context.Context) (uint, bool, error)
+	// ExportTable передает в fn строки таблицы в формате JSON в порядке id и возвращает их количество.
+	ExportTable(ctx context.Context, table string, fn func(row []byte) error) (int64, error)
+	// ListObjects возвращает объекты, на которые ссылаются версии хранилищ, по возрастанию ключа.
+	ListObjects(ctx context.Context) ([]BackupObject, error)
+	// IsEmpty сообщает, что в таблицах tables нет строк.
+	IsEmpty(ctx context.Context, tables []string) (bool, error)
+	// ImportRows вставляет строки в формате JSON, полученные от ExportTable.
+	ImportRows(ctx context.Context, table string, rows [][]byte) error
+	// ResetSequences продолжает последовательности id таблиц tables после максимальных восстановленных значений.
+	ResetSequences(ctx context.Context, tables []string) error
+}
+
+// BackupRepository выгружает и загружает содержимое таблиц сервера целиком.
+type BackupRepository interface {
+	// Export выполняет fn в транзакции только для чтения со снимком данных (REPEATABLE READ),
+	// поэтому все таблицы выгружаются согласованно, даже если сервер продолжает работу.
+	Export(ctx context.Context, fn func(tx BackupTx) error) error
+	// Import выполняет fn в транзакции с отложенной проверкой внешних ключей.
+	// Если fn вернула ошибку, ни одна строка не сохраняется.
+	Import(ctx context.Context, fn func(tx BackupTx) error) error
+}
+
+// postgresBackupRepository реализует BackupRepository для PostgreSQL.
+type postgresBackupRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresBackupRepository создает новый экземпляр репозитория резервного копирования.
+func NewPostgresBackupRepository(db *sqlx.DB) BackupRepository {
+	return &postgresBackupRepository{db: db}
+}
+
+// Export выполняет fn в согласованном снимке БД.
+func (r *postgresBackupRepository) Export(ctx context.Context, fn func(tx BackupTx) error) error {
+	return r.withTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}, nil, fn)
+}
+
+// Import выполняет fn в транзакции с отложенной проверкой ссылок на текущие версии.
+func (r *postgresBackupRepository) Import(ctx context.Context, fn func(tx BackupTx) error) error {
+	deferConstraints := func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, `SET CONSTRAINTS fk_current_version DEFERRED`); err != nil {
+			return fmt.Errorf("ошибка откладывания проверки внешних ключей: %w", err)
+		}
+		return nil
+	}
+	return r.withTx(ctx, nil, deferConstraints, fn)
+}
+
+// withTx выполняет fn в транзакции с указанными параметрами. prepare (если задана) настраивает транзакцию до fn.
+func (r *postgresBackupRepository) withTx(
+	ctx context.Context,
+	opts *sql.TxOptions,
+	prepare func(tx *sqlx.Tx) error,
+	fn func(tx BackupTx) error,
+) error {
+	tx, err := r.db.BeginTxx(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if prepare != nil {
+		err = prepare(tx)
+	}
+	if err == nil {
+		err = fn(&postgresBackupTx{db: tx})
+	}
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			log.Printf("[Repo] Ошибка отката транзакции резервного копирования: %v", rollbackErr)
+		}
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("ошибка коммита транзакции: %w", err)
+	}
+	return nil
+}
+
+// postgresBackupTx реализует BackupTx поверх открытой транзакции.
+type postgresBackupTx struct {
+	db *sqlx.Tx
+}
+
+// SchemaVersion возвращает версию схемы из таблицы golang-migrate.
+func (t *postgresBackupTx) SchemaVersion(ctx context.Context) (uint, bool, error) {
+	var version int64
+	var dirty bool
+	err := t.db.QueryRowxContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, false, nil
+		}
+		return 0, false, fmt.Errorf("ошибка получения версии схемы БД: %w", err)
+	}
+	return uint(version), dirty, nil //nolint:gosec // Версии миграций неотрицательны
+}
+
+// ExportTable построчно выгружает таблицу в JSON.
+func (t *postgresBackupTx) ExportTable(ctx context.Context, table string, fn func(row []byte) error) (int64, error) {
+	if err := checkBackupTable(table); err != nil {
+		return 0, err
+	}
+	// Имя таблицы взято из фиксированного списка, подстановка безопасна
+	query := `SELECT row_to_json(t)::text FROM ` + table + ` t ORDER BY t.id`
+	rows, err := t.db.QueryContext(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка выгрузки таблицы %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	var count int64
+	for rows.Next() {
+		var row []byte
+		if err = rows.Scan(&row); err != nil {
+			return count, fmt.Errorf("ошибка чтения строки таблицы %s: %w", table, err)
+		}
+		if err = fn(row); err != nil {
+			return count, err
+		}
+		count++
+	}
+	if err = rows.Err(); err != nil {
+		return count, fmt.Errorf("ошибка выгрузки таблицы %s: %w", table, err)
+	}
+	return count, nil
+}
+
+// ListObjects возвращает ключи объектов версий вместе с ожидаемыми контрольными суммами.
+// Объекты хранятся только в vault_versions: колонки object_key и checksum удалены из vaults миграцией 2.
+// Несколько версий могут ссылаться на один объект (откат), поэтому ключи группируются.
+func (t *postgresBackupTx) ListObjects(ctx context.Context) ([]BackupObject, error) {
+	query := `
+		SELECT object_key, MAX(checksum) AS checksum
+		FROM vault_versions
+		GROUP BY object_key
+		ORDER BY object_key`
+	var objects []BackupObject
+	if err := t.db.SelectContext(ctx, &objects, query); err != nil {
+		return nil, fmt.Errorf("ошибка получения списка объектов: %w", err)
+	}
+	return objects, nil
+}
+
+// IsEmpty проверяет, что таблицы резервной копии не содержат строк.
+func (t *postgresBackupTx) IsEmpty(ctx context.Context, tables []string) (bool, error) {
+	for _, table := range tables {
+		if err := checkBackupTable(table); err != nil {
+			return false, err
+		}
+		var exists bool
+		if err := t.db.GetContext(ctx, &exists, `SELECT EXISTS (SELECT 1 FROM `+table+`)`); err != nil {
+			return false, fmt.Errorf("ошибка проверки таблицы %s: %w", table, err)
+		}
+		if exists {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// ImportRows вставляет строки пакетами через json_populate_recordset: значения столбцов
+// приводятся к типам таблицы самим PostgreSQL, поэтому формат не зависит от состава столбцов.
+func (t *postgresBackupTx) ImportRows(ctx context.Context, table string, rows [][]byte) error {
+	if err := checkBackupTable(table); err != nil {
+		return err
+	}
+	query := `INSERT INTO ` + table + ` SELECT * FROM json_populate_recordset(NULL::` + table + `, $1::json)`
+	for batch := range slices.Chunk(rows, importBatchSize) {
+		payload := make([]byte, 0, len(batch)*2)
+		payload = append(payload, '[')
+		for i, row := range batch {
+			if i > 0 {
+				payload = append(payload, ',')
+			}
+			payload = append(payload, row...)
+		}
+		payload = append(payload, ']')
+		if _, err := t.db.ExecContext(ctx, query, string(payload)); err != nil {
+			return fmt.Errorf("ошибка загрузки таблицы %s: %w", table, err)
+		}
+	}
+	return nil
+}
+
+// ResetSequences выставляет последовательности id так, чтобы новые строки не конфликтовали с восстановленными.
+func (t *postgresBackupTx) ResetSequences(ctx context.Context, tables []string) error {
+	for _, table := range tables {
+		if err := checkBackupTable(table); err != nil {
+			return err
+		}
+		query := `SELECT setval(pg_get_serial_sequence('` + table + `', 'id'), COALESCE(MAX(id), 0) + 1, false) FROM ` +
+			table
+		if _, err := t.db.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("ошибка обновления последовательности таблицы %s: %w", table, err)
+		}
+	}
+	return nil
+}
+
+// checkBackupTable проверяет, что таблица входит в резервную копию.
+func checkBackupTable(table string) error {
+	if !slices.ContainsFunc(backupTables, func(t backupTable) bool { return t.name == table }) {
+		return fmt.Errorf("%w: %s", ErrUnknownBackupTable, table)
+	}
+	return nil
+}
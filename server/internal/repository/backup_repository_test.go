@@ -0,0 +1,254 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/maynagashev/gophkeeper/server/internal/repository"
+	"github.com/maynagashev/gophkeeper/server/migrations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupBackupRepoMock создает BackupRepository поверх мока БД.
+func setupBackupRepoMock(t *testing.T) (repository.BackupRepository, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	return repository.NewPostgresBackupRepository(sqlx.NewDb(db, "sqlmock")), mock
+}
+
+func TestBackupTables(t *testing.T) {
+	t.Run("Состав зависит от версии схемы", func(t *testing.T) {
+		assert.Equal(t, []string{"users", "user_devices", "vaults", "vault_versions", "webhooks", "webhook_deliveries"},
+			repository.BackupTables(12))
+		assert.Equal(t, []string{
+			"users", "user_devices", "emergency_access", "vaults", "vault_versions", "webhooks", "webhook_deliveries",
+		}, repository.BackupTables(13))
+	})
+}
+
+func TestBackupRepository_Export(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Выгрузка таблицы и списка объектов в одной транзакции", func(t *testing.T) {
+		repo, mock := setupBackupRepoMock(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT version, dirty FROM schema_migrations LIMIT 1`)).
+			WillReturnRows(sqlmock.NewRows([]string{"version", "dirty"}).AddRow(int64(10), false))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT row_to_json(t)::text FROM users t ORDER BY t.id`)).
+			WillReturnRows(sqlmock.NewRows([]string{"row_to_json"}).
+				AddRow(`{"id":1,"username":"alice"}`).AddRow(`{"id":2,"username":"bob"}`))
+		checksum := "abc"
+		mock.ExpectQuery(`SELECT object_key, MAX\(checksum\) AS checksum FROM vault_versions GROUP BY object_key`).
+			WillReturnRows(sqlmock.NewRows([]string{"object_key", "checksum"}).
+				AddRow("user_1/v1.kdbx", checksum).AddRow("user_1/v2.kdbx", nil))
+		mock.ExpectCommit()
+
+		var rows []string
+		err := repo.Export(ctx, func(tx repository.BackupTx) error {
+			version, dirty, err := tx.SchemaVersion(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, uint(10), version)
+			assert.False(t, dirty)
+
+			count, err := tx.ExportTable(ctx, "users", func(row []byte) error {
+				rows = append(rows, string(row))
+				return nil
+			})
+			require.NoError(t, err)
+			assert.Equal(t, int64(2), count)
+
+			objects, err := tx.ListObjects(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, []repository.BackupObject{
+				{Key: "user_1/v1.kdbx", Checksum: &checksum},
+				{Key: "user_1/v2.kdbx"},
+			}, objects)
+			return nil
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{`{"id":1,"username":"alice"}`, `{"id":2,"username":"bob"}`}, rows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Неизвестная таблица не выгружается", func(t *testing.T) {
+		repo, mock := setupBackupRepoMock(t)
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+
+		err := repo.Export(ctx, func(tx repository.BackupTx) error {
+			_, exportErr := tx.ExportTable(ctx, "pg_authid", func([]byte) error { return nil })
+			return exportErr
+		})
+
+		require.ErrorIs(t, err, repository.ErrUnknownBackupTable)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestBackupRepository_Import(t *testing.T) {
+	ctx := context.Background()
+	deferQuery := regexp.QuoteMeta(`SET CONSTRAINTS fk_current_version DEFERRED`)
+	tables := repository.BackupTables(uint(repository.ExpectedSchemaVersion()))
+
+	t.Run("Загрузка строк и обновление последовательностей", func(t *testing.T) {
+		repo, mock := setupBackupRepoMock(t)
+		mock.ExpectBegin()
+		mock.ExpectExec(deferQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+		for _, table := range tables {
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS (SELECT 1 FROM ` + table + `)`)).
+				WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		}
+		mock.ExpectExec(regexp.QuoteMeta(
+			`INSERT INTO users SELECT * FROM json_populate_recordset(NULL::users, $1::json)`)).
+			WithArgs(`[{"id":1},{"id":2}]`).WillReturnResult(sqlmock.NewResult(0, 2))
+		for _, table := range tables {
+			mock.ExpectExec(regexp.QuoteMeta(`SELECT setval(pg_get_serial_sequence('` + table + `', 'id')`)).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+		}
+		mock.ExpectCommit()
+
+		err := repo.Import(ctx, func(tx repository.BackupTx) error {
+			empty, err := tx.IsEmpty(ctx, tables)
+			require.NoError(t, err)
+			assert.True(t, empty)
+			if err = tx.ImportRows(ctx, "users", [][]byte{[]byte(`{"id":1}`), []byte(`{"id":2}`)}); err != nil {
+				return err
+			}
+			return tx.ResetSequences(ctx, tables)
+		})
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Непустая БД", func(t *testing.T) {
+		repo, mock := setupBackupRepoMock(t)
+		mock.ExpectBegin()
+		mock.ExpectExec(deferQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS (SELECT 1 FROM users)`)).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectCommit()
+
+		err := repo.Import(ctx, func(tx repository.BackupTx) error {
+			empty, err := tx.IsEmpty(ctx, tables)
+			require.NoError(t, err)
+			assert.False(t, empty)
+			return nil
+		})
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Ошибка загрузки откатывает транзакцию", func(t *testing.T) {
+		repo, mock := setupBackupRepoMock(t)
+		dbErr := errors.New("violates foreign key constraint")
+		mock.ExpectBegin()
+		mock.ExpectExec(deferQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`INSERT INTO vaults`).WillReturnError(dbErr)
+		mock.ExpectRollback()
+
+		err := repo.Import(ctx, func(tx repository.BackupTx) error {
+			return tx.ImportRows(ctx, "vaults", [][]byte{[]byte(`{"id":1}`)})
+		})
+
+		require.ErrorIs(t, err, dbErr)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+// migratedSchema восстанавливает колонки таблиц после применения всех миграций из migrations.FS.
+// Разбираются только используемые в миграциях конструкции: CREATE TABLE и ADD/DROP COLUMN в ALTER TABLE.
+func migratedSchema(t *testing.T) map[string]map[string]bool {
+	t.Helper()
+	files, err := fs.Glob(migrations.FS, "*.up.sql")
+	require.NoError(t, err)
+	sort.Strings(files)
+
+	createTable := regexp.MustCompile(`(?is)CREATE TABLE (?:IF NOT EXISTS )?(\w+) \((.*?)\n\);`)
+	alterTable := regexp.MustCompile(`(?is)ALTER TABLE (\w+)\s(.*?);`)
+	addColumn := regexp.MustCompile(`(?i)ADD COLUMN (?:IF NOT EXISTS )?(\w+)`)
+	dropColumn := regexp.MustCompile(`(?i)DROP COLUMN (?:IF EXISTS )?(\w+)`)
+	columnName := regexp.MustCompile(`^(\w+)\s`)
+	notColumns := map[string]bool{"constraint": true, "primary": true, "unique": true, "foreign": true,
+		"references": true, "on": true, "check": true}
+	comment := regexp.MustCompile(`--[^\n]*`)
+
+	schema := make(map[string]map[string]bool)
+	for _, file := range files {
+		data, readErr := fs.ReadFile(migrations.FS, file)
+		require.NoError(t, readErr)
+		sql := comment.ReplaceAllString(string(data), "")
+
+		for _, m := range createTable.FindAllStringSubmatch(sql, -1) {
+			columns := make(map[string]bool)
+			for _, line := range strings.Split(m[2], "\n") {
+				if name := columnName.FindStringSubmatch(strings.TrimSpace(line)); name != nil &&
+					!notColumns[strings.ToLower(name[1])] {
+					columns[name[1]] = true
+				}
+			}
+			schema[m[1]] = columns
+		}
+		for _, m := range alterTable.FindAllStringSubmatch(sql, -1) {
+			for _, c := range addColumn.FindAllStringSubmatch(m[2], -1) {
+				schema[m[1]][c[1]] = true
+			}
+			for _, c := range dropColumn.FindAllStringSubmatch(m[2], -1) {
+				delete(schema[m[1]], c[1])
+			}
+		}
+	}
+	return schema
+}
+
+// TestBackupTx_ListObjectsMatchesSchema проверяет, что запрос списка объектов обращается только
+// к колонкам, которые существуют после всех миграций (мок БД сам по себе схему не проверяет).
+func TestBackupTx_ListObjectsMatchesSchema(t *testing.T) {
+	schema := migratedSchema(t)
+	require.True(t, schema["vault_versions"]["object_key"], "Схема должна разбираться из миграций")
+	require.False(t, schema["vaults"]["object_key"], "Колонка удалена миграцией 2")
+
+	var query string
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherFunc(func(_, actual string) error {
+		query = actual
+		return nil
+	})))
+	require.NoError(t, err)
+	mock.ExpectBegin()
+	mock.ExpectQuery("").WillReturnRows(sqlmock.NewRows([]string{"object_key", "checksum"}))
+	mock.ExpectCommit()
+
+	err = repository.NewPostgresBackupRepository(sqlx.NewDb(db, "sqlmock")).
+		Export(context.Background(), func(tx repository.BackupTx) error {
+			_, listErr := tx.ListObjects(context.Background())
+			return listErr
+		})
+	require.NoError(t, err)
+
+	selects := regexp.MustCompile(`(?is)SELECT\s+(.*?)\s+FROM\s+(\w+)`).FindAllStringSubmatch(query, -1)
+	require.NotEmpty(t, selects)
+	identifier := regexp.MustCompile(`\w+`)
+	sqlWords := map[string]bool{"MAX": true, "AS": true}
+	for _, m := range selects {
+		table := m[2]
+		require.Contains(t, schema, table, "Таблица %s отсутствует в схеме", table)
+		// Псевдоним после AS - не колонка таблицы
+		columns := regexp.MustCompile(`(?i)\s+AS\s+\w+`).ReplaceAllString(m[1], "")
+		for _, column := range identifier.FindAllString(columns, -1) {
+			if sqlWords[strings.ToUpper(column)] {
+				continue
+			}
+			assert.True(t, schema[table][column], "Колонки %s.%s нет в схеме после миграций", table, column)
+		}
+	}
+}
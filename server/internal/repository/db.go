@@ -9,15 +9,26 @@ import (
 	_ "github.com/lib/pq" // Драйвер PostgreSQL, импортируем для регистрации
 )
 
-const (
-	maxOpenConns    = 25              // Максимальное количество открытых соединений
-	maxIdleConns    = 25              // Максимальное количество простаивающих соединений
-	connMaxLifetime = 5 * time.Minute // Максимальное время жизни соединения
-	connMaxIdleTime = 5 * time.Minute // Максимальное время простоя соединения
-)
+// PoolConfig задает параметры пула соединений с БД.
+type PoolConfig struct {
+	MaxOpenConns    int           // Максимальное количество открытых соединений
+	MaxIdleConns    int           // Максимальное количество простаивающих соединений
+	ConnMaxLifetime time.Duration // Максимальное время жизни соединения
+	ConnMaxIdleTime time.Duration // Максимальное время простоя соединения
+}
+
+// DefaultPoolConfig возвращает параметры пула соединений по умолчанию.
+func DefaultPoolConfig() PoolConfig {
+	return PoolConfig{
+		MaxOpenConns:    25,
+		MaxIdleConns:    25,
+		ConnMaxLifetime: 5 * time.Minute,
+		ConnMaxIdleTime: 5 * time.Minute,
+	}
+}
 
-// NewPostgresDB создает и возвращает новое подключение к PostgreSQL.
-func NewPostgresDB(dsn string) (*sqlx.DB, error) {
+// NewPostgresDB создает и возвращает новое подключение к PostgreSQL с заданными параметрами пула.
+func NewPostgresDB(dsn string, pool PoolConfig) (*sqlx.DB, error) {
 	log.Printf("Подключение к PostgreSQL...")
 
 	db, err := sqlx.Connect("postgres", dsn)
@@ -36,10 +47,10 @@ func NewPostgresDB(dsn string) (*sqlx.DB, error) {
 	}
 
 	// Настройка пула соединений
-	db.SetMaxOpenConns(maxOpenConns)
-	db.SetMaxIdleConns(maxIdleConns)
-	db.SetConnMaxLifetime(connMaxLifetime)
-	db.SetConnMaxIdleTime(connMaxIdleTime)
+	db.SetMaxOpenConns(pool.MaxOpenConns)
+	db.SetMaxIdleConns(pool.MaxIdleConns)
+	db.SetConnMaxLifetime(pool.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(pool.ConnMaxIdleTime)
 
 	log.Println("Подключение к PostgreSQL успешно установлено.")
 	return db, nil
@@ -0,0 +1,220 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/maynagashev/gophkeeper/models"
+)
+
+// EmergencyAccessRepository определяет методы для работы с доверенными контактами (экстренным доступом).
+type EmergencyAccessRepository interface {
+	// UpsertContact назначает контакта владельцу или меняет период ожидания уже назначенного контакта
+	// (статус запроса и ключ при этом сохраняются).
+	UpsertContact(ctx context.Context, ownerID, contactID int64, waitPeriodHours int) error
+	GetAccess(ctx context.Context, ownerID, contactID int64) (*models.EmergencyAccess, error)
+	ListByOwner(ctx context.Context, ownerID int64) ([]models.EmergencyAccess, error)
+	ListByContact(ctx context.Context, contactID int64) ([]models.EmergencyAccess, error)
+	DeleteContact(ctx context.Context, ownerID, contactID int64) error
+	// RequestAccess переводит доступ в статус requested с временем запроса requestedAt.
+	// Возвращает false, если запрос уже идет (время прежнего запроса не сбрасывается).
+	RequestAccess(ctx context.Context, ownerID, contactID int64, requestedAt time.Time) (bool, error)
+	// DenyAccess отклоняет идущий запрос. Возвращает false, если запроса нет.
+	DenyAccess(ctx context.Context, ownerID, contactID int64) (bool, error)
+	SetKeyEnvelope(ctx context.Context, ownerID, contactID int64, envelope []byte) error
+	GetKeyEnvelope(ctx context.Context, ownerID, contactID int64) ([]byte, error)
+}
+
+// emergencyAccessSelect выбирает доступ вместе с именами владельца и контакта (без самого ключа).
+const emergencyAccessSelect = `SELECT ea.id, ea.owner_id, o.username AS owner_username,
+	       ea.contact_id, c.username AS contact_username, ea.wait_period_hours, ea.status, ea.requested_at,
+	       ea.key_envelope IS NOT NULL AS has_key_envelope, ea.created_at, ea.updated_at
+	FROM emergency_access ea
+	JOIN users o ON o.id = ea.owner_id
+	JOIN users c ON c.id = ea.contact_id`
+
+// postgresEmergencyAccessRepository реализует EmergencyAccessRepository для PostgreSQL.
+type postgresEmergencyAccessRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresEmergencyAccessRepository создает новый экземпляр репозитория доверенных контактов.
+func NewPostgresEmergencyAccessRepository(db *sqlx.DB) EmergencyAccessRepository {
+	return &postgresEmergencyAccessRepository{db: db}
+}
+
+// UpsertContact назначает доверенного контакта или обновляет период ожидания.
+func (r *postgresEmergencyAccessRepository) UpsertContact(
+	ctx context.Context,
+	ownerID, contactID int64,
+	waitPeriodHours int,
+) error {
+	query := `INSERT INTO emergency_access (owner_id, contact_id, wait_period_hours) VALUES ($1, $2, $3)
+	          ON CONFLICT (owner_id, contact_id)
+	          DO UPDATE SET wait_period_hours = EXCLUDED.wait_period_hours, updated_at = NOW()`
+
+	if _, err := r.db.ExecContext(ctx, query, ownerID, contactID, waitPeriodHours); err != nil {
+		log.Printf("[EmergencyAccessRepo] Ошибка назначения контакта ID %d пользователю ID %d: %v",
+			contactID, ownerID, err)
+		return fmt.Errorf("ошибка выполнения запроса на назначение доверенного контакта: %w", err)
+	}
+
+	log.Printf("[EmergencyAccessRepo] Пользователь ID %d назначил контакта ID %d (ожидание %d ч)",
+		ownerID, contactID, waitPeriodHours)
+	return nil
+}
+
+// GetAccess находит доступ контакта к хранилищу владельца.
+func (r *postgresEmergencyAccessRepository) GetAccess(
+	ctx context.Context,
+	ownerID, contactID int64,
+) (*models.EmergencyAccess, error) {
+	query := emergencyAccessSelect + ` WHERE ea.owner_id=$1 AND ea.contact_id=$2`
+	var access models.EmergencyAccess
+
+	err := r.db.GetContext(ctx, &access, query, ownerID, contactID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrEmergencyAccessNotFound
+		}
+		log.Printf("[EmergencyAccessRepo] Ошибка при поиске доступа контакта ID %d к хранилищу ID %d: %v",
+			contactID, ownerID, err)
+		return nil, fmt.Errorf("ошибка выполнения запроса на получение доверенного контакта: %w", err)
+	}
+	return &access, nil
+}
+
+// ListByOwner возвращает доверенных контактов владельца.
+func (r *postgresEmergencyAccessRepository) ListByOwner(
+	ctx context.Context,
+	ownerID int64,
+) ([]models.EmergencyAccess, error) {
+	return r.list(ctx, emergencyAccessSelect+` WHERE ea.owner_id=$1 ORDER BY ea.id`, ownerID)
+}
+
+// ListByContact возвращает владельцев, назначивших пользователя доверенным контактом.
+func (r *postgresEmergencyAccessRepository) ListByContact(
+	ctx context.Context,
+	contactID int64,
+) ([]models.EmergencyAccess, error) {
+	return r.list(ctx, emergencyAccessSelect+` WHERE ea.contact_id=$1 ORDER BY ea.id`, contactID)
+}
+
+// list выполняет запрос списка доступов с одним параметром.
+func (r *postgresEmergencyAccessRepository) list(
+	ctx context.Context,
+	query string,
+	userID int64,
+) ([]models.EmergencyAccess, error) {
+	accesses := make([]models.EmergencyAccess, 0)
+	if err := r.db.SelectContext(ctx, &accesses, query, userID); err != nil {
+		log.Printf("[EmergencyAccessRepo] Ошибка при получении доверенных контактов пользователя ID %d: %v", userID, err)
+		return nil, fmt.Errorf("ошибка выполнения запроса на получение доверенных контактов: %w", err)
+	}
+	return accesses, nil
+}
+
+// DeleteContact отменяет назначение доверенного контакта вместе с оставленным для него ключом.
+func (r *postgresEmergencyAccessRepository) DeleteContact(ctx context.Context, ownerID, contactID int64) error {
+	query := `DELETE FROM emergency_access WHERE owner_id=$1 AND contact_id=$2`
+	return r.execOne(ctx, "удаление доверенного контакта", query, ownerID, contactID)
+}
+
+// RequestAccess отмечает запрос доступа, если он еще не идет.
+func (r *postgresEmergencyAccessRepository) RequestAccess(
+	ctx context.Context,
+	ownerID, contactID int64,
+	requestedAt time.Time,
+) (bool, error) {
+	query := `UPDATE emergency_access SET status=$3, requested_at=$4, updated_at=NOW()
+	          WHERE owner_id=$1 AND contact_id=$2 AND status <> $3`
+	return r.execChanged(ctx, "запрос доступа", query, ownerID, contactID, models.EmergencyAccessRequested, requestedAt)
+}
+
+// DenyAccess отклоняет идущий запрос доступа.
+func (r *postgresEmergencyAccessRepository) DenyAccess(ctx context.Context, ownerID, contactID int64) (bool, error) {
+	query := `UPDATE emergency_access SET status=$3, updated_at=NOW()
+	          WHERE owner_id=$1 AND contact_id=$2 AND status=$4`
+	return r.execChanged(ctx, "отклонение доступа", query, ownerID, contactID,
+		models.EmergencyAccessDenied, models.EmergencyAccessRequested)
+}
+
+// SetKeyEnvelope сохраняет ключ к хранилищу, зашифрованный владельцем для контакта.
+func (r *postgresEmergencyAccessRepository) SetKeyEnvelope(
+	ctx context.Context,
+	ownerID, contactID int64,
+	envelope []byte,
+) error {
+	query := `UPDATE emergency_access SET key_envelope=$3, updated_at=NOW() WHERE owner_id=$1 AND contact_id=$2`
+	return r.execOne(ctx, "сохранение ключа для контакта", query, ownerID, contactID, envelope)
+}
+
+// GetKeyEnvelope возвращает ключ, оставленный владельцем для контакта (nil, если ключа нет).
+func (r *postgresEmergencyAccessRepository) GetKeyEnvelope(
+	ctx context.Context,
+	ownerID, contactID int64,
+) ([]byte, error) {
+	query := `SELECT key_envelope FROM emergency_access WHERE owner_id=$1 AND contact_id=$2`
+	var envelope []byte
+
+	err := r.db.QueryRowxContext(ctx, query, ownerID, contactID).Scan(&envelope)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrEmergencyAccessNotFound
+		}
+		log.Printf("[EmergencyAccessRepo] Ошибка при получении ключа контакта ID %d: %v", contactID, err)
+		return nil, fmt.Errorf("ошибка выполнения запроса на получение ключа для контакта: %w", err)
+	}
+	return envelope, nil
+}
+
+// execOne выполняет изменение одной записи доступа и возвращает ErrEmergencyAccessNotFound, если ее нет.
+func (r *postgresEmergencyAccessRepository) execOne(
+	ctx context.Context,
+	operation, query string,
+	ownerID, contactID int64,
+	args ...any,
+) error {
+	changed, err := r.execChanged(ctx, operation, query, ownerID, contactID, args...)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		return ErrEmergencyAccessNotFound
+	}
+	return nil
+}
+
+// execChanged выполняет изменение записи доступа и сообщает, была ли она изменена.
+func (r *postgresEmergencyAccessRepository) execChanged(
+	ctx context.Context,
+	operation, query string,
+	ownerID, contactID int64,
+	args ...any,
+) (bool, error) {
+	result, err := r.db.ExecContext(ctx, query, append([]any{ownerID, contactID}, args...)...)
+	if err != nil {
+		log.Printf("[EmergencyAccessRepo] Ошибка (%s) для контакта ID %d пользователя ID %d: %v",
+			operation, contactID, ownerID, err)
+		return false, fmt.Errorf("ошибка выполнения запроса (%s): %w", operation, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("ошибка проверки результата запроса (%s): %w", operation, err)
+	}
+	if rowsAffected > 0 {
+		log.Printf("[EmergencyAccessRepo] Выполнено (%s) для контакта ID %d пользователя ID %d",
+			operation, contactID, ownerID)
+	}
+	return rowsAffected > 0, nil
+}
+
+// Ошибки репозитория доверенных контактов.
+var (
+	ErrEmergencyAccessNotFound = errors.New("доверенный контакт не найден")
+)
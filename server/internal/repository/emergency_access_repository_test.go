@@ -0,0 +1,222 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/maynagashev/gophkeeper/models"
+	"github.com/maynagashev/gophkeeper/server/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Вспомогательная функция для создания мока БД и репозитория доверенных контактов.
+func setupEmergencyRepoMock(t *testing.T) (repository.EmergencyAccessRepository, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	return repository.NewPostgresEmergencyAccessRepository(sqlxDB), mock
+}
+
+var emergencyAccessColumns = []string{ //nolint:gochecknoglobals // Тестовые данные
+	"id", "owner_id", "owner_username", "contact_id", "contact_username", "wait_period_hours", "status",
+	"requested_at", "has_key_envelope", "created_at", "updated_at",
+}
+
+func TestUpsertContact(t *testing.T) {
+	query := regexp.QuoteMeta(`INSERT INTO emergency_access (owner_id, contact_id, wait_period_hours)`)
+
+	t.Run("Успешное назначение", func(t *testing.T) {
+		repo, mock := setupEmergencyRepoMock(t)
+		mock.ExpectExec(query).WithArgs(int64(1), int64(2), 48).WillReturnResult(sqlmock.NewResult(1, 1))
+
+		err := repo.UpsertContact(context.Background(), 1, 2, 48)
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Ошибка базы данных", func(t *testing.T) {
+		repo, mock := setupEmergencyRepoMock(t)
+		mock.ExpectExec(query).WillReturnError(errors.New("insert error"))
+
+		err := repo.UpsertContact(context.Background(), 1, 2, 48)
+
+		require.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetAccess(t *testing.T) {
+	query := regexp.QuoteMeta(`WHERE ea.owner_id=$1 AND ea.contact_id=$2`)
+
+	t.Run("Успешный поиск", func(t *testing.T) {
+		repo, mock := setupEmergencyRepoMock(t)
+		requestedAt := time.Now()
+		mock.ExpectQuery(query).WithArgs(int64(1), int64(2)).WillReturnRows(sqlmock.NewRows(emergencyAccessColumns).
+			AddRow(3, 1, "owner", 2, "contact", 48, models.EmergencyAccessRequested, requestedAt, true,
+				time.Now(), time.Now()))
+
+		access, err := repo.GetAccess(context.Background(), 1, 2)
+
+		require.NoError(t, err)
+		assert.Equal(t, "contact", access.ContactUsername)
+		assert.Equal(t, models.EmergencyAccessRequested, access.Status)
+		assert.True(t, access.HasKeyEnvelope)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Контакт не найден", func(t *testing.T) {
+		repo, mock := setupEmergencyRepoMock(t)
+		mock.ExpectQuery(query).WillReturnError(sql.ErrNoRows)
+
+		access, err := repo.GetAccess(context.Background(), 1, 2)
+
+		require.ErrorIs(t, err, repository.ErrEmergencyAccessNotFound)
+		assert.Nil(t, access)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestListEmergencyAccess(t *testing.T) {
+	t.Run("Контакты владельца", func(t *testing.T) {
+		repo, mock := setupEmergencyRepoMock(t)
+		mock.ExpectQuery(regexp.QuoteMeta(`WHERE ea.owner_id=$1 ORDER BY ea.id`)).WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows(emergencyAccessColumns).
+				AddRow(3, 1, "owner", 2, "contact", 48, models.EmergencyAccessIdle, nil, false, time.Now(), time.Now()))
+
+		accesses, err := repo.ListByOwner(context.Background(), 1)
+
+		require.NoError(t, err)
+		require.Len(t, accesses, 1)
+		assert.Nil(t, accesses[0].RequestedAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Ошибка базы данных", func(t *testing.T) {
+		repo, mock := setupEmergencyRepoMock(t)
+		mock.ExpectQuery(regexp.QuoteMeta(`WHERE ea.contact_id=$1 ORDER BY ea.id`)).
+			WillReturnError(errors.New("select error"))
+
+		accesses, err := repo.ListByContact(context.Background(), 2)
+
+		require.Error(t, err)
+		assert.Nil(t, accesses)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDeleteContact(t *testing.T) {
+	query := regexp.QuoteMeta(`DELETE FROM emergency_access WHERE owner_id=$1 AND contact_id=$2`)
+
+	t.Run("Успешное удаление", func(t *testing.T) {
+		repo, mock := setupEmergencyRepoMock(t)
+		mock.ExpectExec(query).WithArgs(int64(1), int64(2)).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		require.NoError(t, repo.DeleteContact(context.Background(), 1, 2))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Контакт не найден", func(t *testing.T) {
+		repo, mock := setupEmergencyRepoMock(t)
+		mock.ExpectExec(query).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		require.ErrorIs(t, repo.DeleteContact(context.Background(), 1, 2), repository.ErrEmergencyAccessNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRequestAndDenyAccess(t *testing.T) {
+	requestQuery := regexp.QuoteMeta(`UPDATE emergency_access SET status=$3, requested_at=$4`)
+	denyQuery := regexp.QuoteMeta(`WHERE owner_id=$1 AND contact_id=$2 AND status=$4`)
+	requestedAt := time.Now()
+
+	t.Run("Запрос доступа", func(t *testing.T) {
+		repo, mock := setupEmergencyRepoMock(t)
+		mock.ExpectExec(requestQuery).WithArgs(int64(1), int64(2), models.EmergencyAccessRequested, requestedAt).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		requested, err := repo.RequestAccess(context.Background(), 1, 2, requestedAt)
+
+		require.NoError(t, err)
+		assert.True(t, requested)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Запрос уже идет", func(t *testing.T) {
+		repo, mock := setupEmergencyRepoMock(t)
+		mock.ExpectExec(requestQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		requested, err := repo.RequestAccess(context.Background(), 1, 2, requestedAt)
+
+		require.NoError(t, err)
+		assert.False(t, requested)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Отклонение запроса", func(t *testing.T) {
+		repo, mock := setupEmergencyRepoMock(t)
+		mock.ExpectExec(denyQuery).
+			WithArgs(int64(1), int64(2), models.EmergencyAccessDenied, models.EmergencyAccessRequested).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		denied, err := repo.DenyAccess(context.Background(), 1, 2)
+
+		require.NoError(t, err)
+		assert.True(t, denied)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Ошибка базы данных", func(t *testing.T) {
+		repo, mock := setupEmergencyRepoMock(t)
+		mock.ExpectExec(denyQuery).WillReturnError(errors.New("update error"))
+
+		denied, err := repo.DenyAccess(context.Background(), 1, 2)
+
+		require.Error(t, err)
+		assert.False(t, denied)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestKeyEnvelope(t *testing.T) {
+	t.Run("Сохранение ключа", func(t *testing.T) {
+		repo, mock := setupEmergencyRepoMock(t)
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE emergency_access SET key_envelope=$3`)).
+			WithArgs(int64(1), int64(2), []byte("envelope")).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		require.NoError(t, repo.SetKeyEnvelope(context.Background(), 1, 2, []byte("envelope")))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	query := regexp.QuoteMeta(`SELECT key_envelope FROM emergency_access WHERE owner_id=$1 AND contact_id=$2`)
+
+	t.Run("Получение ключа", func(t *testing.T) {
+		repo, mock := setupEmergencyRepoMock(t)
+		mock.ExpectQuery(query).WithArgs(int64(1), int64(2)).
+			WillReturnRows(sqlmock.NewRows([]string{"key_envelope"}).AddRow([]byte("envelope")))
+
+		envelope, err := repo.GetKeyEnvelope(context.Background(), 1, 2)
+
+		require.NoError(t, err)
+		assert.Equal(t, []byte("envelope"), envelope)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Ключ не оставлен", func(t *testing.T) {
+		repo, mock := setupEmergencyRepoMock(t)
+		mock.ExpectQuery(query).WillReturnRows(sqlmock.NewRows([]string{"key_envelope"}).AddRow(nil))
+
+		envelope, err := repo.GetKeyEnvelope(context.Background(), 1, 2)
+
+		require.NoError(t, err)
+		assert.Nil(t, envelope)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/maynagashev/gophkeeper/server/migrations"
+)
+
+// ExpectedSchemaVersion возвращает версию схемы БД, с которой работает код, - номер последней
+// встроенной миграции (migrations.FS). Вычисляется один раз; ошибка чтения встроенных миграций
+// означает поврежденную сборку, поэтому приводит к панике.
+//
+//nolint:gochecknoglobals // Неизменяемое значение, вычисляемое при первом обращении
+var ExpectedSchemaVersion = sync.OnceValue(func() int64 {
+	latest, err := LatestMigrationVersion(migrations.FS)
+	if err != nil {
+		panic(fmt.Sprintf("встроенные миграции недоступны: %v", err))
+	}
+	return int64(latest)
+})
+
+// HealthRepository проверяет состояние базы данных.
+type HealthRepository interface {
+	// Ping проверяет соединение с БД.
+	Ping(ctx context.Context) error
+	// GetSchemaVersion возвращает примененную версию схемы из таблицы golang-migrate
+	// и признак незавершенной (dirty) миграции. Если миграции не применялись, версия равна 0.
+	GetSchemaVersion(ctx context.Context) (int64, bool, error)
+}
+
+// postgresHealthRepository реализует HealthRepository для PostgreSQL.
+type postgresHealthRepository struct {
+	db *sqlx.DB
+}
+
+// NewPostgresHealthRepository создает новый экземпляр репозитория проверки состояния БД.
+func NewPostgresHealthRepository(db *sqlx.DB) HealthRepository {
+	return &postgresHealthRepository{db: db}
+}
+
+// Ping проверяет соединение с БД.
+func (r *postgresHealthRepository) Ping(ctx context.Context) error {
+	if err := r.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("ошибка проверки соединения с БД: %w", err)
+	}
+	return nil
+}
+
+// GetSchemaVersion возвращает примененную версию схемы БД.
+func (r *postgresHealthRepository) GetSchemaVersion(ctx context.Context) (int64, bool, error) {
+	query := `SELECT version, dirty FROM schema_migrations LIMIT 1`
+	var version int64
+	var dirty bool
+
+	err := r.db.QueryRowxContext(ctx, query).Scan(&version, &dirty)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, false, nil
+		}
+		return 0, false, fmt.Errorf("ошибка получения версии схемы БД: %w", err)
+	}
+	return version, dirty, nil
+}
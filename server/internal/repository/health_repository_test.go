@@ -0,0 +1,76 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/maynagashev/gophkeeper/server/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Вспомогательная функция для создания мока БД и репозитория проверки состояния.
+func setupHealthRepoMock(t *testing.T) (repository.HealthRepository, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	require.NoError(t, err)
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	return repository.NewPostgresHealthRepository(sqlxDB), mock
+}
+
+func TestHealthRepository_Ping(t *testing.T) {
+	t.Run("БД доступна", func(t *testing.T) {
+		repo, mock := setupHealthRepoMock(t)
+		mock.ExpectPing()
+
+		require.NoError(t, repo.Ping(context.Background()))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("БД недоступна", func(t *testing.T) {
+		repo, mock := setupHealthRepoMock(t)
+		mock.ExpectPing().WillReturnError(errors.New("connection refused"))
+
+		require.Error(t, repo.Ping(context.Background()))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestHealthRepository_GetSchemaVersion(t *testing.T) {
+	query := regexp.QuoteMeta(`SELECT version, dirty FROM schema_migrations LIMIT 1`)
+
+	t.Run("Версия схемы получена", func(t *testing.T) {
+		repo, mock := setupHealthRepoMock(t)
+		mock.ExpectQuery(query).WillReturnRows(sqlmock.NewRows([]string{"version", "dirty"}).AddRow(int64(7), true))
+
+		version, dirty, err := repo.GetSchemaVersion(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), version)
+		assert.True(t, dirty)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Миграции не применялись", func(t *testing.T) {
+		repo, mock := setupHealthRepoMock(t)
+		mock.ExpectQuery(query).WillReturnRows(sqlmock.NewRows([]string{"version", "dirty"}))
+
+		version, dirty, err := repo.GetSchemaVersion(context.Background())
+
+		require.NoError(t, err)
+		assert.Zero(t, version)
+		assert.False(t, dirty)
+	})
+
+	t.Run("Ошибка базы данных", func(t *testing.T) {
+		repo, mock := setupHealthRepoMock(t)
+		mock.ExpectQuery(query).WillReturnError(errors.New(`relation "schema_migrations" does not exist`))
+
+		_, _, err := repo.GetSchemaVersion(context.Background())
+
+		require.Error(t, err)
+	})
+}
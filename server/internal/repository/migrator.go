@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/maynagashev/gophkeeper/server/migrations"
+)
+
+// ErrSchemaAhead возвращается, если к БД применены миграции новее встроенных в сервер.
+var ErrSchemaAhead = errors.New("схема БД новее, чем поддерживает сервер")
+
+// MigrationStatus описывает состояние схемы БД относительно встроенных миграций.
+type MigrationStatus struct {
+	// Version - примененная версия схемы (0 - миграции не применялись).
+	Version uint
+	// Dirty - последняя миграция прервана, схему нужно исправить вручную.
+	Dirty bool
+	// Latest - последняя версия среди встроенных миграций.
+	Latest uint
+}
+
+// Pending сообщает, что есть непримененные встроенные миграции.
+func (s MigrationStatus) Pending() bool {
+	return s.Version < s.Latest
+}
+
+// Ahead сообщает, что схема БД новее встроенных миграций (БД обновлена более новой версией сервера).
+func (s MigrationStatus) Ahead() bool {
+	return s.Version > s.Latest
+}
+
+// Migrator применяет встроенные миграции. Примененная версия хранится в таблице schema_migrations
+// (формат golang-migrate), поэтому он совместим с базами, размеченными утилитой migrate.
+type Migrator struct {
+	m      *migrate.Migrate
+	latest uint
+}
+
+// NewMigrator создает Migrator с отдельным соединением с БД, которое закрывается в Close.
+func NewMigrator(dsn string) (*Migrator, error) {
+	src, err := iofs.New(migrations.FS, ".")
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения встроенных миграций: %w", err)
+	}
+	latest, err := latestVersion(src)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка подключения к БД: %w", err)
+	}
+	driver, err := postgres.WithInstance(db, &postgres.Config{})
+	if err != nil {
+		if closeErr := db.Close(); closeErr != nil {
+			log.Printf("[Migrator] Ошибка закрытия соединения с БД: %v", closeErr)
+		}
+		return nil, fmt.Errorf("ошибка подключения к БД для миграций: %w", err)
+	}
+	m, err := migrate.NewWithInstance("iofs", src, "postgres", driver)
+	if err != nil {
+		// Драйвер закрывает и соединение с БД, открытое выше
+		if closeErr := driver.Close(); closeErr != nil {
+			log.Printf("[Migrator] Ошибка закрытия соединения с БД: %v", closeErr)
+		}
+		return nil, fmt.Errorf("ошибка инициализации миграций: %w", err)
+	}
+	m.Log = migrateLogger{}
+	return &Migrator{m: m, latest: latest}, nil
+}
+
+// Status возвращает примененную и последнюю встроенную версии схемы.
+func (mg *Migrator) Status() (MigrationStatus, error) {
+	version, dirty, err := mg.m.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return MigrationStatus{}, fmt.Errorf("ошибка получения версии схемы БД: %w", err)
+	}
+	return MigrationStatus{Version: version, Dirty: dirty, Latest: mg.latest}, nil
+}
+
+// Up применяет все непримененные миграции.
+func (mg *Migrator) Up() error {
+	status, err := mg.Status()
+	if err != nil {
+		return err
+	}
+	if status.Ahead() {
+		return fmt.Errorf("%w: версия %d, последняя известная %d", ErrSchemaAhead, status.Version, status.Latest)
+	}
+	if err = mg.m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("ошибка применения миграций: %w", err)
+	}
+	return nil
+}
+
+// Down откатывает одну последнюю примененную миграцию.
+func (mg *Migrator) Down() error {
+	status, err := mg.Status()
+	if err != nil {
+		return err
+	}
+	if status.Version == 0 {
+		return errors.New("нет примененных миграций")
+	}
+	if status.Ahead() {
+		return fmt.Errorf("%w: откат версии %d возможен только более новым сервером", ErrSchemaAhead, status.Version)
+	}
+	if err = mg.m.Steps(-1); err != nil {
+		return fmt.Errorf("ошибка отката миграции: %w", err)
+	}
+	return nil
+}
+
+// MigrateTo применяет миграции до версии version. Откат не выполняется: если схема уже новее version,
+// возвращается ошибка.
+func (mg *Migrator) MigrateTo(version uint) error {
+	status, err := mg.Status()
+	if err != nil {
+		return err
+	}
+	if version > status.Latest {
+		return fmt.Errorf("%w: запрошена версия %d, последняя известная %d", ErrSchemaAhead, version, status.Latest)
+	}
+	if status.Version > version {
+		return fmt.Errorf("схема БД (версия %d) новее запрошенной версии %d", status.Version, version)
+	}
+	if status.Version == version && !status.Dirty {
+		return nil
+	}
+	if err = mg.m.Migrate(version); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("ошибка применения миграций: %w", err)
+	}
+	return nil
+}
+
+// Close закрывает соединение с БД, открытое для миграций.
+func (mg *Migrator) Close() error {
+	srcErr, dbErr := mg.m.Close()
+	return errors.Join(srcErr, dbErr)
+}
+
+// LatestMigrationVersion возвращает номер последней миграции в fsys.
+func LatestMigrationVersion(fsys fs.FS) (uint, error) {
+	src, err := iofs.New(fsys, ".")
+	if err != nil {
+		return 0, fmt.Errorf("ошибка чтения миграций: %w", err)
+	}
+	defer src.Close()
+	return latestVersion(src)
+}
+
+// latestVersion перебирает миграции источника и возвращает номер последней.
+func latestVersion(src source.Driver) (uint, error) {
+	version, err := src.First()
+	if err != nil {
+		return 0, fmt.Errorf("ошибка чтения миграций: %w", err)
+	}
+	for {
+		next, nextErr := src.Next(version)
+		if errors.Is(nextErr, fs.ErrNotExist) {
+			return version, nil
+		}
+		if nextErr != nil {
+			return 0, fmt.Errorf("ошибка чтения миграций: %w", nextErr)
+		}
+		version = next
+	}
+}
+
+// migrateLogger выводит ход применения миграций в общий лог.
+type migrateLogger struct{}
+
+// Printf выводит сообщение golang-migrate.
+func (migrateLogger) Printf(format string, v ...any) {
+	log.Printf("[Migrator] "+format, v...)
+}
+
+// Verbose отключает подробный вывод.
+func (migrateLogger) Verbose() bool {
+	return false
+}
@@ -0,0 +1,70 @@
+package repository_test
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/maynagashev/gophkeeper/server/internal/repository"
+	"github.com/maynagashev/gophkeeper/server/migrations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbeddedMigrations(t *testing.T) {
+	t.Run("Код ожидает версию схемы последней встроенной миграции", func(t *testing.T) {
+		latest, err := repository.LatestMigrationVersion(migrations.FS)
+		require.NoError(t, err)
+		assert.Positive(t, latest)
+		assert.Equal(t, int64(latest), repository.ExpectedSchemaVersion())
+	})
+
+	t.Run("У каждой миграции есть откат", func(t *testing.T) {
+		ups, err := fs.Glob(migrations.FS, "*.up.sql")
+		require.NoError(t, err)
+		require.NotEmpty(t, ups)
+		for _, up := range ups {
+			down := strings.TrimSuffix(up, ".up.sql") + ".down.sql"
+			_, statErr := fs.Stat(migrations.FS, down)
+			assert.NoError(t, statErr, "нет файла отката %s", down)
+		}
+	})
+}
+
+func TestLatestMigrationVersion(t *testing.T) {
+	t.Run("Миграции не по порядку", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"000010_c.up.sql": {Data: []byte("SELECT 1;")},
+			"000002_b.up.sql": {Data: []byte("SELECT 1;")},
+			"000001_a.up.sql": {Data: []byte("SELECT 1;")},
+		}
+		latest, err := repository.LatestMigrationVersion(fsys)
+		require.NoError(t, err)
+		assert.Equal(t, uint(10), latest)
+	})
+
+	t.Run("Нет миграций", func(t *testing.T) {
+		_, err := repository.LatestMigrationVersion(fstest.MapFS{})
+		require.Error(t, err)
+	})
+}
+
+func TestMigrationStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  repository.MigrationStatus
+		pending bool
+		ahead   bool
+	}{
+		{name: "Схема актуальна", status: repository.MigrationStatus{Version: 7, Latest: 7}},
+		{name: "Миграции не применялись", status: repository.MigrationStatus{Latest: 7}, pending: true},
+		{name: "Схема новее сервера", status: repository.MigrationStatus{Version: 8, Latest: 7}, ahead: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.pending, tt.status.Pending())
+			assert.Equal(t, tt.ahead, tt.status.Ahead())
+		})
+	}
+}
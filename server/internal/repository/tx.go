@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// DBTX - общий набор методов *sqlx.DB и *sqlx.Tx, через который работают репозитории.
+// Благодаря ему один и тот же репозиторий выполняет запросы как напрямую, так и в транзакции.
+type DBTX interface {
+	sqlx.ExtContext
+	GetContext(ctx context.Context, dest any, query string, args ...any) error
+	SelectContext(ctx context.Context, dest any, query string, args ...any) error
+}
+
+// TxRepositories - репозитории хранилищ, выполняющие запросы в рамках одной транзакции.
+// Webhooks нужен, чтобы доставки вебхуков ставились в очередь вместе с изменением хранилища
+// (transactional outbox): событие не теряется после коммита и не появляется при откате.
+type TxRepositories struct {
+	Vaults   VaultRepository
+	Versions VaultVersionRepository
+	Webhooks WebhookRepository
+}
+
+// Transactor выполняет группу операций с хранилищами как единое целое (unit of work).
+type Transactor interface {
+	// WithTx выполняет fn в транзакции. Если fn вернула ошибку или запаниковала, транзакция
+	// откатывается, иначе фиксируется. Ошибка fn возвращается без изменений.
+	WithTx(ctx context.Context, fn func(repos TxRepositories) error) error
+}
+
+// sqlxTransactor реализует Transactor поверх sqlx.
+type sqlxTransactor struct {
+	db *sqlx.DB
+}
+
+// NewTransactor создает новый экземпляр Transactor.
+func NewTransactor(db *sqlx.DB) Transactor {
+	return &sqlxTransactor{db: db}
+}
+
+// WithTx выполняет fn в транзакции с репозиториями, привязанными к этой транзакции.
+func (t *sqlxTransactor) WithTx(ctx context.Context, fn func(repos TxRepositories) error) error {
+	tx, err := t.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Printf("[Tx] Ошибка начала транзакции: %v", err)
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+
+	// Гарантируем откат транзакции в случае паники
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p) // Передаем панику дальше
+		}
+	}()
+
+	err = fn(TxRepositories{
+		Vaults:   &postgresVaultRepository{db: tx},
+		Versions: &postgresVaultVersionRepository{db: tx},
+		Webhooks: &postgresWebhookRepository{db: tx},
+	})
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			log.Printf("[Tx] Ошибка отката транзакции: %v", rollbackErr)
+		}
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("[Tx] Ошибка коммита транзакции: %v", err)
+		return fmt.Errorf("ошибка коммита транзакции: %w", err)
+	}
+	return nil
+}
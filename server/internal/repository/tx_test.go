@@ -0,0 +1,117 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/maynagashev/gophkeeper/server/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupTransactorMock создает Transactor поверх мока БД.
+func setupTransactorMock(t *testing.T) (repository.Transactor, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	return repository.NewTransactor(sqlx.NewDb(db, "sqlmock")), mock
+}
+
+func TestTransactor_WithTx(t *testing.T) {
+	ctx := context.Background()
+	updateQuery := regexp.QuoteMeta(`UPDATE vaults SET current_version_id=$1, updated_at=NOW() WHERE id=$2`)
+
+	t.Run("Запросы репозиториев выполняются в транзакции и фиксируются", func(t *testing.T) {
+		transactor, mock := setupTransactorMock(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT v.id AS vault_id.*FOR UPDATE OF v`).WithArgs(int64(101)).
+			WillReturnRows(sqlmock.NewRows([]string{"vault_id", "user_id", "vault_created_at", "vault_updated_at",
+				"version_id"}).AddRow(int64(501), int64(101), time.Now(), time.Now(), nil))
+		mock.ExpectExec(updateQuery).WithArgs(int64(601), int64(501)).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := transactor.WithTx(ctx, func(repos repository.TxRepositories) error {
+			vault, version, err := repos.Vaults.LockVaultWithCurrentVersionByUserID(ctx, 101)
+			require.NoError(t, err)
+			assert.Equal(t, int64(501), vault.ID)
+			assert.Nil(t, version)
+			return repos.Vaults.UpdateVaultCurrentVersion(ctx, vault.ID, 601)
+		})
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Доставки вебхуков ставятся в очередь в той же транзакции", func(t *testing.T) {
+		transactor, mock := setupTransactorMock(t)
+		enqueueErr := errors.New("connection reset")
+		mock.ExpectBegin()
+		mock.ExpectExec(updateQuery).WithArgs(int64(601), int64(501)).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`INSERT INTO webhook_deliveries`).WillReturnError(enqueueErr)
+		mock.ExpectRollback()
+
+		err := transactor.WithTx(ctx, func(repos repository.TxRepositories) error {
+			require.NoError(t, repos.Vaults.UpdateVaultCurrentVersion(ctx, 501, 601))
+			_, err := repos.Webhooks.EnqueueDeliveries(ctx, 101, "version_created", []byte(`{}`))
+			return err
+		})
+
+		// Изменение хранилища откатывается вместе с неудачной постановкой в очередь
+		require.ErrorIs(t, err, enqueueErr)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Ошибка функции откатывает транзакцию и возвращается без изменений", func(t *testing.T) {
+		transactor, mock := setupTransactorMock(t)
+		fnErr := errors.New("конфликт версий")
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+
+		err := transactor.WithTx(ctx, func(_ repository.TxRepositories) error { return fnErr })
+
+		require.ErrorIs(t, err, fnErr)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Паника откатывает транзакцию", func(t *testing.T) {
+		transactor, mock := setupTransactorMock(t)
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+
+		assert.Panics(t, func() {
+			_ = transactor.WithTx(ctx, func(_ repository.TxRepositories) error { panic("boom") })
+		})
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Ошибка начала транзакции", func(t *testing.T) {
+		transactor, mock := setupTransactorMock(t)
+		mock.ExpectBegin().WillReturnError(errors.New("connection refused"))
+
+		called := false
+		err := transactor.WithTx(ctx, func(_ repository.TxRepositories) error {
+			called = true
+			return nil
+		})
+
+		require.Error(t, err)
+		assert.False(t, called, "Функция не должна выполняться без транзакции")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Ошибка коммита", func(t *testing.T) {
+		transactor, mock := setupTransactorMock(t)
+		mock.ExpectBegin()
+		mock.ExpectCommit().WillReturnError(errors.New("serialization failure"))
+
+		err := transactor.WithTx(ctx, func(_ repository.TxRepositories) error { return nil })
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "ошибка коммита транзакции")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
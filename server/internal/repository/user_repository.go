@@ -21,6 +21,18 @@ const (
 type UserRepository interface {
 	CreateUser(ctx context.Context, user *models.User) (int64, error)
 	GetUserByUsername(ctx context.Context, username string) (*models.User, error)
+	// GetUserByID находит пользователя по ID (вызывается при проверке каждого токена).
+	GetUserByID(ctx context.Context, userID int64) (*models.User, error)
+	TouchDevice(ctx context.Context, userID int64, fingerprint, userAgent, ipAddress string) (bool, error)
+	// ListUsers возвращает пользователей, имя которых содержит search (пустая строка - все), по алфавиту.
+	ListUsers(ctx context.Context, search string, limit, offset int) ([]models.UserSummary, error)
+	SetUserDisabled(ctx context.Context, userID int64, disabled bool) error
+	SetUserRole(ctx context.Context, userID int64, role string) error
+	UpdatePasswordHash(ctx context.Context, userID int64, passwordHash string) error
+	// DeleteUser удаляет пользователя вместе с хранилищем, версиями, вебхуками и устройствами (ON DELETE CASCADE).
+	DeleteUser(ctx context.Context, userID int64) error
+	// GetServerStats возвращает количество пользователей, хранилищ, версий и общий объем объектов.
+	GetServerStats(ctx context.Context) (*models.ServerStats, error)
 }
 
 // postgresUserRepository реализует UserRepository для PostgreSQL.
@@ -58,7 +70,8 @@ func (r *postgresUserRepository) CreateUser(ctx context.Context, user *models.Us
 // GetUserByUsername находит пользователя по его имени.
 // Возвращает пользователя или ошибку, если пользователь не найден или произошла другая ошибка.
 func (r *postgresUserRepository) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
-	query := `SELECT id, username, password_hash, created_at, updated_at FROM users WHERE username=$1`
+	query := `SELECT id, username, password_hash, created_at, updated_at, disabled_at, role
+	          FROM users WHERE username=$1`
 	var user models.User
 
 	err := r.db.GetContext(ctx, &user, query, username)
@@ -75,6 +88,152 @@ func (r *postgresUserRepository) GetUserByUsername(ctx context.Context, username
 	return &user, nil
 }
 
+// GetUserByID находит пользователя по ID.
+// Вызывается на каждый аутентифицированный запрос, поэтому успешный поиск не логируется.
+func (r *postgresUserRepository) GetUserByID(ctx context.Context, userID int64) (*models.User, error) {
+	query := `SELECT id, username, password_hash, created_at, updated_at, disabled_at, role
+	          FROM users WHERE id=$1`
+	var user models.User
+
+	err := r.db.GetContext(ctx, &user, query, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		log.Printf("[Repo] Ошибка при поиске пользователя ID %d: %v", userID, err)
+		return nil, fmt.Errorf("ошибка выполнения запроса на получение пользователя: %w", err)
+	}
+	return &user, nil
+}
+
+// TouchDevice запоминает устройство, с которого вошел пользователь, и обновляет время последнего входа.
+// Возвращает true, если устройство встретилось впервые.
+func (r *postgresUserRepository) TouchDevice(
+	ctx context.Context,
+	userID int64,
+	fingerprint, userAgent, ipAddress string,
+) (bool, error) {
+	// xmax = 0 только у только что вставленной строки, у обновленной при конфликте он ненулевой
+	query := `INSERT INTO user_devices (user_id, fingerprint, user_agent, last_ip) VALUES ($1, $2, $3, $4)
+	          ON CONFLICT (user_id, fingerprint)
+	          DO UPDATE SET last_seen_at = CURRENT_TIMESTAMP, last_ip = EXCLUDED.last_ip
+	          RETURNING (xmax = 0) AS inserted`
+	var inserted bool
+
+	err := r.db.QueryRowxContext(ctx, query, userID, fingerprint, userAgent, ipAddress).Scan(&inserted)
+	if err != nil {
+		log.Printf("[Repo] Ошибка при сохранении устройства пользователя ID %d: %v", userID, err)
+		return false, fmt.Errorf("ошибка выполнения запроса на сохранение устройства: %w", err)
+	}
+
+	if inserted {
+		log.Printf("[Repo] Новое устройство пользователя ID %d: %s", userID, userAgent)
+	}
+	return inserted, nil
+}
+
+// ListUsers возвращает пользователей со статистикой их хранилищ.
+func (r *postgresUserRepository) ListUsers(
+	ctx context.Context,
+	search string,
+	limit,
+	offset int,
+) ([]models.UserSummary, error) {
+	// Объект, на который ссылаются несколько версий (после отката), учитывается в размере один раз
+	query := `SELECT u.id, u.username, u.created_at, u.disabled_at, u.role,
+	                 COUNT(vv.id) AS version_count,
+	                 COALESCE((SELECT SUM(o.size_bytes) FROM (
+	                     SELECT DISTINCT ON (ov.object_key) ov.size_bytes
+	                     FROM vault_versions ov JOIN vaults ovt ON ovt.id = ov.vault_id
+	                     WHERE ovt.user_id = u.id
+	                 ) o), 0) AS storage_bytes,
+	                 MAX(vv.created_at) AS last_upload_at
+	          FROM users u
+	          LEFT JOIN vaults v ON v.user_id = u.id
+	          LEFT JOIN vault_versions vv ON vv.vault_id = v.id
+	          WHERE $1 = '' OR u.username ILIKE '%' || $1 || '%'
+	          GROUP BY u.id
+	          ORDER BY u.username
+	          LIMIT $2 OFFSET $3`
+
+	users := make([]models.UserSummary, 0, limit)
+	if err := r.db.SelectContext(ctx, &users, query, search, limit, offset); err != nil {
+		log.Printf("[Repo] Ошибка при получении списка пользователей (search='%s'): %v", search, err)
+		return nil, fmt.Errorf("ошибка выполнения запроса на получение списка пользователей: %w", err)
+	}
+	return users, nil
+}
+
+// SetUserDisabled отключает или включает учетную запись. Время отключения сохраняется при повторном отключении.
+func (r *postgresUserRepository) SetUserDisabled(ctx context.Context, userID int64, disabled bool) error {
+	query := `UPDATE users
+	          SET disabled_at = CASE WHEN $1 THEN COALESCE(disabled_at, CURRENT_TIMESTAMP) END
+	          WHERE id = $2`
+	return r.execForUser(ctx, userID, "изменения статуса учетной записи", query, disabled, userID)
+}
+
+// SetUserRole задает роль пользователя (models.RoleUser или models.RoleAdmin).
+func (r *postgresUserRepository) SetUserRole(ctx context.Context, userID int64, role string) error {
+	query := `UPDATE users SET role = $1 WHERE id = $2`
+	return r.execForUser(ctx, userID, "изменения роли", query, role, userID)
+}
+
+// UpdatePasswordHash заменяет хеш пароля пользователя.
+func (r *postgresUserRepository) UpdatePasswordHash(ctx context.Context, userID int64, passwordHash string) error {
+	query := `UPDATE users SET password_hash = $1 WHERE id = $2`
+	return r.execForUser(ctx, userID, "смены пароля", query, passwordHash, userID)
+}
+
+// DeleteUser удаляет пользователя и все связанные с ним записи.
+func (r *postgresUserRepository) DeleteUser(ctx context.Context, userID int64) error {
+	query := `DELETE FROM users WHERE id = $1`
+	return r.execForUser(ctx, userID, "удаления пользователя", query, userID)
+}
+
+// GetServerStats возвращает сводную статистику по пользователям и хранилищам.
+func (r *postgresUserRepository) GetServerStats(ctx context.Context) (*models.ServerStats, error) {
+	query := `SELECT (SELECT COUNT(*) FROM users) AS users,
+	                 (SELECT COUNT(*) FROM users WHERE disabled_at IS NOT NULL) AS disabled_users,
+	                 (SELECT COUNT(*) FROM users WHERE role = 'admin') AS admins,
+	                 (SELECT COUNT(*) FROM vaults) AS vaults,
+	                 (SELECT COUNT(*) FROM vault_versions) AS versions,
+	                 COALESCE((SELECT SUM(o.size_bytes) FROM (
+	                     SELECT DISTINCT ON (object_key) size_bytes FROM vault_versions
+	                 ) o), 0) AS storage_bytes`
+
+	var stats models.ServerStats
+	if err := r.db.GetContext(ctx, &stats, query); err != nil {
+		log.Printf("[Repo] Ошибка при получении статистики сервера: %v", err)
+		return nil, fmt.Errorf("ошибка выполнения запроса на получение статистики сервера: %w", err)
+	}
+	return &stats, nil
+}
+
+// execForUser выполняет изменяющий запрос и возвращает ErrUserNotFound, если пользователь не найден.
+func (r *postgresUserRepository) execForUser(
+	ctx context.Context,
+	userID int64,
+	operation string,
+	query string,
+	args ...any,
+) error {
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("[Repo] Ошибка %s для пользователя ID %d: %v", operation, userID, err)
+		return fmt.Errorf("ошибка выполнения запроса %s: %w", operation, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка получения результата запроса %s: %w", operation, err)
+	}
+	if rowsAffected == 0 {
+		log.Printf("[Repo] Пользователь ID %d не найден при выполнении %s", userID, operation)
+		return ErrUserNotFound
+	}
+	log.Printf("[Repo] Выполнено %s для пользователя ID %d", operation, userID)
+	return nil
+}
+
 // Кастомные ошибки репозитория.
 var (
 	ErrUserNotFound  = errors.New("пользователь не найден")
@@ -3,6 +3,7 @@ package repository_test
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"errors"
 	"regexp"
 	"testing"
@@ -108,6 +109,8 @@ func TestCreateUser(t *testing.T) {
 }
 
 func TestGetUserByUsername(t *testing.T) {
+	getUserQuery := regexp.QuoteMeta(
+		`SELECT id, username, password_hash, created_at, updated_at, disabled_at, role FROM users WHERE username=$1`)
 	// Определяем тестового пользователя заранее
 	now := time.Now()
 	testUser := &models.User{
@@ -116,6 +119,7 @@ func TestGetUserByUsername(t *testing.T) {
 		PasswordHash: "hash123",
 		CreatedAt:    now,
 		UpdatedAt:    now,
+		Role:         models.RoleAdmin,
 	}
 
 	tests := []struct {
@@ -129,10 +133,11 @@ func TestGetUserByUsername(t *testing.T) {
 			name:     "Успешный поиск",
 			username: "testuser",
 			mockSetup: func(mock sqlmock.Sqlmock, username string) {
-				rows := sqlmock.NewRows([]string{"id", "username", "password_hash", "created_at", "updated_at"}).
-					AddRow(testUser.ID, testUser.Username, testUser.PasswordHash, testUser.CreatedAt, testUser.UpdatedAt)
-				query := regexp.QuoteMeta(`SELECT id, username, password_hash, created_at, updated_at FROM users WHERE username=$1`)
-				mock.ExpectQuery(query).WithArgs(username).WillReturnRows(rows)
+				rows := sqlmock.NewRows([]string{
+					"id", "username", "password_hash", "created_at", "updated_at", "disabled_at", "role",
+				}).AddRow(testUser.ID, testUser.Username, testUser.PasswordHash, testUser.CreatedAt, testUser.UpdatedAt,
+					nil, models.RoleAdmin)
+				mock.ExpectQuery(getUserQuery).WithArgs(username).WillReturnRows(rows)
 			},
 			expectedUser: testUser,
 			expectedErr:  nil,
@@ -141,8 +146,7 @@ func TestGetUserByUsername(t *testing.T) {
 			name:     "Пользователь не найден",
 			username: "notfounduser",
 			mockSetup: func(mock sqlmock.Sqlmock, username string) {
-				query := regexp.QuoteMeta(`SELECT id, username, password_hash, created_at, updated_at FROM users WHERE username=$1`)
-				mock.ExpectQuery(query).WithArgs(username).WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery(getUserQuery).WithArgs(username).WillReturnError(sql.ErrNoRows)
 			},
 			expectedUser: nil,
 			expectedErr:  repository.ErrUserNotFound,
@@ -151,9 +155,8 @@ func TestGetUserByUsername(t *testing.T) {
 			name:     "Ошибка базы данных",
 			username: "erroruser",
 			mockSetup: func(mock sqlmock.Sqlmock, username string) {
-				query := regexp.QuoteMeta(`SELECT id, username, password_hash, created_at, updated_at FROM users WHERE username=$1`)
 				dbErr := errors.New("database error")
-				mock.ExpectQuery(query).WithArgs(username).WillReturnError(dbErr)
+				mock.ExpectQuery(getUserQuery).WithArgs(username).WillReturnError(dbErr)
 			},
 			expectedUser: nil,
 			expectedErr:  errors.New("ошибка выполнения запроса"), // Ожидаем обернутую ошибку
@@ -184,3 +187,226 @@ func TestGetUserByUsername(t *testing.T) {
 		})
 	}
 }
+
+func TestGetUserByID(t *testing.T) {
+	query := regexp.QuoteMeta(
+		`SELECT id, username, password_hash, created_at, updated_at, disabled_at, role FROM users WHERE id=$1`)
+
+	t.Run("Отключенный пользователь", func(t *testing.T) {
+		repo, mock := setupUserRepoMock(t)
+		disabledAt := time.Now()
+		mock.ExpectQuery(query).WithArgs(int64(1)).WillReturnRows(sqlmock.NewRows([]string{
+			"id", "username", "password_hash", "created_at", "updated_at", "disabled_at", "role",
+		}).AddRow(1, "testuser", "hash", time.Now(), time.Now(), disabledAt, models.RoleUser))
+
+		user, err := repo.GetUserByID(context.Background(), 1)
+
+		require.NoError(t, err)
+		require.NotNil(t, user.DisabledAt)
+		assert.Equal(t, models.RoleUser, user.Role)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Пользователь не найден", func(t *testing.T) {
+		repo, mock := setupUserRepoMock(t)
+		mock.ExpectQuery(query).WithArgs(int64(2)).WillReturnError(sql.ErrNoRows)
+
+		user, err := repo.GetUserByID(context.Background(), 2)
+
+		require.ErrorIs(t, err, repository.ErrUserNotFound)
+		assert.Nil(t, user)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestTouchDevice(t *testing.T) {
+	query := `INSERT INTO user_devices .* ON CONFLICT \(user_id, fingerprint\) .* RETURNING \(xmax = 0\) AS inserted`
+
+	tests := []struct {
+		name        string
+		mockSetup   func(mock sqlmock.Sqlmock)
+		expectedNew bool
+		expectedErr bool
+	}{
+		{
+			name: "Новое устройство",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(query).WithArgs(int64(1), "fp", "agent", "10.0.0.1").
+					WillReturnRows(sqlmock.NewRows([]string{"inserted"}).AddRow(true))
+			},
+			expectedNew: true,
+		},
+		{
+			name: "Известное устройство",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(query).WithArgs(int64(1), "fp", "agent", "10.0.0.1").
+					WillReturnRows(sqlmock.NewRows([]string{"inserted"}).AddRow(false))
+			},
+			expectedNew: false,
+		},
+		{
+			name: "Ошибка базы данных",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(query).WillReturnError(errors.New("database error"))
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock := setupUserRepoMock(t)
+			tt.mockSetup(mock)
+
+			isNew, err := repo.TouchDevice(context.Background(), 1, "fp", "agent", "10.0.0.1")
+
+			if tt.expectedErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "ошибка выполнения запроса")
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedNew, isNew)
+			assert.NoError(t, mock.ExpectationsWereMet(), "Не все ожидания мока были выполнены")
+		})
+	}
+}
+
+func TestListUsers(t *testing.T) {
+	query := `SELECT u.id, u.username, u.created_at, u.disabled_at, u.role, COUNT\(vv.id\) AS version_count, .*` +
+		`WHERE \$1 = '' OR u.username ILIKE .* LIMIT \$2 OFFSET \$3`
+
+	t.Run("Успешный поиск", func(t *testing.T) {
+		repo, mock := setupUserRepoMock(t)
+		now := time.Now()
+		rows := sqlmock.NewRows([]string{
+			"id", "username", "created_at", "disabled_at", "role", "version_count", "storage_bytes", "last_upload_at",
+		}).
+			AddRow(int64(1), "alice", now, nil, models.RoleAdmin, int64(3), int64(4096), now).
+			AddRow(int64(2), "alina", now, now, models.RoleUser, int64(0), int64(0), nil)
+		mock.ExpectQuery(query).WithArgs("ali", 50, 0).WillReturnRows(rows)
+
+		users, err := repo.ListUsers(context.Background(), "ali", 50, 0)
+
+		require.NoError(t, err)
+		require.Len(t, users, 2)
+		assert.Equal(t, "alice", users[0].Username)
+		assert.Equal(t, int64(3), users[0].VersionCount)
+		assert.Equal(t, int64(4096), users[0].StorageBytes)
+		assert.Equal(t, models.RoleAdmin, users[0].Role)
+		assert.Nil(t, users[0].DisabledAt)
+		assert.NotNil(t, users[1].DisabledAt)
+		assert.Nil(t, users[1].LastUploadAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Ошибка базы данных", func(t *testing.T) {
+		repo, mock := setupUserRepoMock(t)
+		mock.ExpectQuery(query).WillReturnError(errors.New("database error"))
+
+		_, err := repo.ListUsers(context.Background(), "", 50, 0)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "ошибка выполнения запроса")
+	})
+}
+
+func TestUserAdminUpdates(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		args  []driver.Value
+		call  func(repo repository.UserRepository) error
+	}{
+		{
+			name:  "Отключение учетной записи",
+			query: `UPDATE users SET disabled_at = CASE WHEN $1 THEN COALESCE(disabled_at, CURRENT_TIMESTAMP) END`,
+			args:  []driver.Value{true, int64(5)},
+			call: func(repo repository.UserRepository) error {
+				return repo.SetUserDisabled(context.Background(), 5, true)
+			},
+		},
+		{
+			name:  "Назначение роли",
+			query: `UPDATE users SET role = $1 WHERE id = $2`,
+			args:  []driver.Value{models.RoleAdmin, int64(5)},
+			call: func(repo repository.UserRepository) error {
+				return repo.SetUserRole(context.Background(), 5, models.RoleAdmin)
+			},
+		},
+		{
+			name:  "Смена пароля",
+			query: `UPDATE users SET password_hash = $1 WHERE id = $2`,
+			args:  []driver.Value{"new-hash", int64(5)},
+			call: func(repo repository.UserRepository) error {
+				return repo.UpdatePasswordHash(context.Background(), 5, "new-hash")
+			},
+		},
+		{
+			name:  "Удаление пользователя",
+			query: `DELETE FROM users WHERE id = $1`,
+			args:  []driver.Value{int64(5)},
+			call: func(repo repository.UserRepository) error {
+				return repo.DeleteUser(context.Background(), 5)
+			},
+		},
+	}
+
+	// Запросы сравниваются без учета переносов строк и отступов
+	spaces := regexp.MustCompile(`\s+`)
+	for _, tt := range tests {
+		queryPattern := spaces.ReplaceAllString(regexp.QuoteMeta(tt.query), `\s+`)
+
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock := setupUserRepoMock(t)
+			mock.ExpectExec(queryPattern).WithArgs(tt.args...).WillReturnResult(sqlmock.NewResult(0, 1))
+
+			require.NoError(t, tt.call(repo))
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+
+		t.Run(tt.name+": пользователь не найден", func(t *testing.T) {
+			repo, mock := setupUserRepoMock(t)
+			mock.ExpectExec(queryPattern).WithArgs(tt.args...).WillReturnResult(sqlmock.NewResult(0, 0))
+
+			require.ErrorIs(t, tt.call(repo), repository.ErrUserNotFound)
+		})
+
+		t.Run(tt.name+": ошибка базы данных", func(t *testing.T) {
+			repo, mock := setupUserRepoMock(t)
+			mock.ExpectExec(queryPattern).WillReturnError(errors.New("database error"))
+
+			err := tt.call(repo)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "ошибка выполнения запроса")
+		})
+	}
+}
+
+func TestGetServerStats(t *testing.T) {
+	query := `SELECT \(SELECT COUNT\(\*\) FROM users\) AS users, .* AS storage_bytes`
+
+	t.Run("Успешное получение", func(t *testing.T) {
+		repo, mock := setupUserRepoMock(t)
+		rows := sqlmock.NewRows([]string{"users", "disabled_users", "admins", "vaults", "versions", "storage_bytes"}).
+			AddRow(int64(10), int64(1), int64(2), int64(8), int64(40), int64(1<<20))
+		mock.ExpectQuery(query).WillReturnRows(rows)
+
+		stats, err := repo.GetServerStats(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, &models.ServerStats{
+			Users: 10, DisabledUsers: 1, Admins: 2, Vaults: 8, Versions: 40, StorageBytes: 1 << 20,
+		}, stats)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Ошибка базы данных", func(t *testing.T) {
+		repo, mock := setupUserRepoMock(t)
+		mock.ExpectQuery(query).WillReturnError(errors.New("database error"))
+
+		_, err := repo.GetServerStats(context.Background())
+
+		require.Error(t, err)
+	})
+}
@@ -9,8 +9,8 @@ import (
 	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/maynagashev/gophkeeper/models"
-	// "github.com/lib/pq" // Больше не нужен здесь, т.к. убрали CreateVault/UpdateVaultMetadata с проверкой UNIQUE.
 )
 
 // VaultRepository определяет методы для работы с основными записями хранилищ.
@@ -19,11 +19,12 @@ type VaultRepository interface {
 	CreateVault(ctx context.Context, vault *models.Vault) (int64, error)
 	UpdateVaultCurrentVersion(ctx context.Context, vaultID int64, versionID int64) error
 	GetVaultWithCurrentVersionByUserID(ctx context.Context, userID int64) (*models.Vault, *models.VaultVersion, error)
+	LockVaultWithCurrentVersionByUserID(ctx context.Context, userID int64) (*models.Vault, *models.VaultVersion, error)
 }
 
 // postgresVaultRepository реализует VaultRepository для PostgreSQL.
 type postgresVaultRepository struct {
-	db *sqlx.DB
+	db DBTX
 }
 
 // NewPostgresVaultRepository создает новый экземпляр репозитория хранилищ.
@@ -59,7 +60,12 @@ func (r *postgresVaultRepository) CreateVault(ctx context.Context, vault *models
 
 	err := r.db.QueryRowxContext(ctx, query, vault.UserID).Scan(&vaultID)
 	if err != nil {
-		// Здесь может быть ошибка уникальности user_id, если мы добавим такой constraint
+		// Хранилище пользователя уже создано параллельной транзакцией
+		var pgErr *pq.Error
+		if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolationCode {
+			log.Printf("[VaultRepo] Хранилище для пользователя ID %d уже существует", vault.UserID)
+			return 0, ErrVaultAlreadyExists
+		}
 		log.Printf("[VaultRepo] Непредвиденная ошибка при создании хранилища для пользователя ID %d: %v", vault.UserID, err)
 		return 0, fmt.Errorf("ошибка выполнения запроса на создание хранилища: %w", err)
 	}
@@ -98,17 +104,40 @@ func (r *postgresVaultRepository) UpdateVaultCurrentVersion(ctx context.Context,
 func (r *postgresVaultRepository) GetVaultWithCurrentVersionByUserID(
 	ctx context.Context,
 	userID int64,
+) (*models.Vault, *models.VaultVersion, error) {
+	return r.getVaultWithCurrentVersion(ctx, userID, "")
+}
+
+// LockVaultWithCurrentVersionByUserID работает как GetVaultWithCurrentVersionByUserID, но блокирует
+// запись хранилища (SELECT ... FOR UPDATE) до конца транзакции. Так параллельные изменения хранилища
+// одного пользователя (например, загрузки с разных устройств) выполняются по очереди.
+// Имеет смысл только для репозитория, полученного через Transactor.WithTx.
+func (r *postgresVaultRepository) LockVaultWithCurrentVersionByUserID(
+	ctx context.Context,
+	userID int64,
+) (*models.Vault, *models.VaultVersion, error) {
+	// Блокируем только запись vaults: версию по LEFT JOIN заблокировать нельзя, да и не нужно
+	return r.getVaultWithCurrentVersion(ctx, userID, " FOR UPDATE OF v")
+}
+
+// getVaultWithCurrentVersion выполняет запрос хранилища с текущей версией с необязательным суффиксом блокировки.
+func (r *postgresVaultRepository) getVaultWithCurrentVersion(
+	ctx context.Context,
+	userID int64,
+	lockClause string,
 ) (*models.Vault, *models.VaultVersion, error) {
 	// Добавили выборку vv.content_modified_at
 	query := `
 		SELECT
 		    v.id AS vault_id, v.user_id, v.created_at AS vault_created_at, v.updated_at AS vault_updated_at,
 		    vv.id AS version_id, vv.object_key, vv.checksum, vv.size_bytes,
-		    vv.created_at AS version_created_at, vv.content_modified_at AS version_content_modified_at
+		    vv.created_at AS version_created_at, vv.content_modified_at AS version_content_modified_at,
+		    vv.restored_from_version_id, vv.kdbx_version, vv.kdbx_cipher, vv.kdbx_kdf,
+		    vv.client_name, vv.client_version, vv.device_id, vv.uploader_ip
 		FROM vaults v
 		LEFT JOIN vault_versions vv ON v.current_version_id = vv.id
 		WHERE v.user_id = $1
-		LIMIT 1`
+		LIMIT 1` + lockClause
 
 	// Используем временную структуру для сканирования результата JOIN
 	// Добавили поле для content_modified_at
@@ -123,6 +152,14 @@ func (r *postgresVaultRepository) GetVaultWithCurrentVersionByUserID(
 		SizeBytes                *int64     `db:"size_bytes"`
 		VersionCreatedAt         *time.Time `db:"version_created_at"`
 		VersionContentModifiedAt *time.Time `db:"version_content_modified_at"` // Указатель, т.к. LEFT JOIN может дать NULL
+		RestoredFromVersionID    *int64     `db:"restored_from_version_id"`
+		KdbxVersion              *string    `db:"kdbx_version"`
+		KdbxCipher               *string    `db:"kdbx_cipher"`
+		KdbxKDF                  *string    `db:"kdbx_kdf"`
+		ClientName               *string    `db:"client_name"`
+		ClientVersion            *string    `db:"client_version"`
+		DeviceID                 *string    `db:"device_id"`
+		UploaderIP               *string    `db:"uploader_ip"`
 	}
 
 	var res result
@@ -149,13 +186,21 @@ func (r *postgresVaultRepository) GetVaultWithCurrentVersionByUserID(
 	if res.VersionID != nil { // Если есть текущая версия
 		// Добавили заполнение ContentModifiedAt
 		currentVersion = &models.VaultVersion{
-			ID:                *res.VersionID,
-			VaultID:           res.VaultID,
-			ObjectKey:         *res.ObjectKey,
-			Checksum:          res.Checksum,
-			SizeBytes:         res.SizeBytes,
-			CreatedAt:         *res.VersionCreatedAt,
-			ContentModifiedAt: res.VersionContentModifiedAt, // Указатель на время или nil
+			ID:                    *res.VersionID,
+			VaultID:               res.VaultID,
+			ObjectKey:             *res.ObjectKey,
+			Checksum:              res.Checksum,
+			SizeBytes:             res.SizeBytes,
+			CreatedAt:             *res.VersionCreatedAt,
+			ContentModifiedAt:     res.VersionContentModifiedAt, // Указатель на время или nil
+			RestoredFromVersionID: res.RestoredFromVersionID,
+			KdbxVersion:           res.KdbxVersion,
+			KdbxCipher:            res.KdbxCipher,
+			KdbxKDF:               res.KdbxKDF,
+			ClientName:            res.ClientName,
+			ClientVersion:         res.ClientVersion,
+			DeviceID:              res.DeviceID,
+			UploaderIP:            res.UploaderIP,
 		}
 		log.Printf("[VaultRepo] Найдено хранилище ID %d с текущей версией ID %d"+
 			" для пользователя %d", vault.ID, currentVersion.ID, userID)
@@ -166,7 +211,8 @@ func (r *postgresVaultRepository) GetVaultWithCurrentVersionByUserID(
 	return vault, currentVersion, nil
 }
 
-// Кастомная ошибка репозитория.
+// Кастомные ошибки репозитория.
 var (
-	ErrVaultNotFound = errors.New("метаданные хранилища не найдены")
+	ErrVaultNotFound      = errors.New("метаданные хранилища не найдены")
+	ErrVaultAlreadyExists = errors.New("хранилище пользователя уже существует")
 )
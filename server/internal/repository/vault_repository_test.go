@@ -11,6 +11,7 @@ import (
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/maynagashev/gophkeeper/models"
 	"github.com/maynagashev/gophkeeper/server/internal/repository"
 	"github.com/stretchr/testify/assert"
@@ -68,6 +69,16 @@ func TestCreateVault(t *testing.T) {
 			expectedID:  0,
 			expectedErr: errors.New("ошибка выполнения запроса"),
 		},
+		{
+			name:  "Хранилище пользователя уже существует",
+			vault: &models.Vault{UserID: 103},
+			mockSetup: func(mock sqlmock.Sqlmock, vault *models.Vault) {
+				query := regexp.QuoteMeta(`INSERT INTO vaults (user_id) VALUES ($1) RETURNING id`)
+				mock.ExpectQuery(query).WithArgs(vault.UserID).WillReturnError(&pq.Error{Code: "23505"})
+			},
+			expectedID:  0,
+			expectedErr: repository.ErrVaultAlreadyExists,
+		},
 	}
 
 	for _, tt := range tests {
@@ -78,9 +89,12 @@ func TestCreateVault(t *testing.T) {
 			vaultID, err := repo.CreateVault(context.Background(), tt.vault)
 
 			assert.Equal(t, tt.expectedID, vaultID)
-			if tt.expectedErr == nil {
+			switch {
+			case tt.expectedErr == nil:
 				require.NoError(t, err)
-			} else {
+			case errors.Is(tt.expectedErr, repository.ErrVaultAlreadyExists):
+				require.ErrorIs(t, err, repository.ErrVaultAlreadyExists)
+			default:
 				require.Error(t, err)
 				assert.Contains(t, err.Error(), "ошибка выполнения запроса")
 			}
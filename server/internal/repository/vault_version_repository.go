@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/lib/pq"
@@ -17,11 +18,20 @@ type VaultVersionRepository interface {
 	CreateVersion(ctx context.Context, version *models.VaultVersion) (int64, error)
 	ListVersionsByVaultID(ctx context.Context, vaultID int64, limit, offset int) ([]models.VaultVersion, error)
 	GetVersionByID(ctx context.Context, versionID int64) (*models.VaultVersion, error)
+	ListAllObjectKeys(ctx context.Context) ([]string, error)
+	ListVersionsForVerification(ctx context.Context, verifiedBefore time.Time, limit int) ([]models.VaultVersion, error)
+	UpdateVerificationStatus(ctx context.Context, versionID int64, status string, verifiedAt time.Time) error
+	ListVersionsWithIntegrityProblems(ctx context.Context, limit, offset int) ([]models.VaultVersion, error)
+	// ListObjectKeysByUserID возвращает ключи объектов всех версий хранилища пользователя (без повторов).
+	ListObjectKeysByUserID(ctx context.Context, userID int64) ([]string, error)
+	// DeleteVersions удаляет версии и возвращает объекты (ключ и размер), на которые больше не ссылается
+	// ни одна версия: их можно удалить из хранилища файлов.
+	DeleteVersions(ctx context.Context, versionIDs []int64) ([]models.VaultVersion, error)
 }
 
 // postgresVaultVersionRepository реализует VaultVersionRepository для PostgreSQL.
 type postgresVaultVersionRepository struct {
-	db *sqlx.DB
+	db DBTX
 }
 
 // NewPostgresVaultVersionRepository создает новый экземпляр репозитория версий.
@@ -29,18 +39,23 @@ func NewPostgresVaultVersionRepository(db *sqlx.DB) VaultVersionRepository {
 	return &postgresVaultVersionRepository{db: db}
 }
 
-// CreateVersion создает новую запись о версии хранилища.
+// CreateVersion создает новую запись о версии хранилища и заполняет ее ID и время создания.
 func (r *postgresVaultVersionRepository) CreateVersion(
 	ctx context.Context,
 	version *models.VaultVersion,
 ) (int64, error) {
-	query := `INSERT INTO vault_versions (vault_id, object_key, checksum, size_bytes, content_modified_at)
-	          VALUES ($1, $2, $3, $4, $5) RETURNING id`
+	query := `INSERT INTO vault_versions
+	              (vault_id, object_key, checksum, size_bytes, content_modified_at, restored_from_version_id,
+	               kdbx_version, kdbx_cipher, kdbx_kdf, client_name, client_version, device_id, uploader_ip)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) RETURNING id, created_at`
 	var versionID int64
+	var createdAt time.Time
 
 	err := r.db.QueryRowxContext(ctx, query,
 		version.VaultID, version.ObjectKey, version.Checksum, version.SizeBytes, version.ContentModifiedAt,
-	).Scan(&versionID)
+		version.RestoredFromVersionID, version.KdbxVersion, version.KdbxCipher, version.KdbxKDF,
+		version.ClientName, version.ClientVersion, version.DeviceID, version.UploaderIP,
+	).Scan(&versionID, &createdAt)
 
 	if err != nil {
 		// Проверяем на ошибку уникальности object_key
@@ -53,6 +68,10 @@ func (r *postgresVaultVersionRepository) CreateVersion(
 		return 0, fmt.Errorf("ошибка выполнения запроса на создание версии: %w", err)
 	}
 
+	// Заполняем присвоенные БД поля, чтобы вызывающая сторона могла вернуть метаданные версии клиенту
+	version.ID = versionID
+	version.CreatedAt = createdAt
+
 	log.Printf("[VaultVerRepo] Версия (ID: %d) успешно создана для хранилища ID %d", versionID, version.VaultID)
 	return versionID, nil
 }
@@ -65,10 +84,12 @@ func (r *postgresVaultVersionRepository) ListVersionsByVaultID(
 	offset int,
 ) ([]models.VaultVersion, error) {
 	// Запрос с сортировкой по убыванию времени создания (сначала новые)
-	query := `SELECT id, vault_id, object_key, checksum, size_bytes, created_at, content_modified_at
+	query := `SELECT id, vault_id, object_key, checksum, size_bytes, created_at, content_modified_at,
+	                 restored_from_version_id, kdbx_version, kdbx_cipher, kdbx_kdf,
+	                 client_name, client_version, device_id, uploader_ip
 	          FROM vault_versions
 	          WHERE vault_id=$1
-	          ORDER BY created_at DESC
+	          ORDER BY created_at DESC, id DESC
 	          LIMIT $2 OFFSET $3`
 
 	versions := make([]models.VaultVersion, 0, limit)
@@ -88,8 +109,9 @@ func (r *postgresVaultVersionRepository) GetVersionByID(
 	ctx context.Context,
 	versionID int64,
 ) (*models.VaultVersion, error) {
-	query := `SELECT id, vault_id, object_key, checksum, size_bytes, created_at, content_modified_at` +
-		` FROM vault_versions WHERE id=$1`
+	query := `SELECT id, vault_id, object_key, checksum, size_bytes, created_at, content_modified_at,` +
+		` restored_from_version_id, kdbx_version, kdbx_cipher, kdbx_kdf,` +
+		` client_name, client_version, device_id, uploader_ip FROM vault_versions WHERE id=$1`
 	var version models.VaultVersion
 
 	err := r.db.GetContext(ctx, &version, query, versionID)
@@ -106,6 +128,148 @@ func (r *postgresVaultVersionRepository) GetVersionByID(
 	return &version, nil
 }
 
+// ListAllObjectKeys возвращает ключи объектов всех версий всех хранилищ (без повторов:
+// версии, созданные откатом, ссылаются на объект восстановленной версии).
+// Используется служебными задачами, которые обходят все объекты в хранилище файлов.
+func (r *postgresVaultVersionRepository) ListAllObjectKeys(ctx context.Context) ([]string, error) {
+	query := `SELECT object_key FROM vault_versions GROUP BY object_key ORDER BY MIN(id)`
+
+	var objectKeys []string
+	err := r.db.SelectContext(ctx, &objectKeys, query)
+	if err != nil {
+		log.Printf("[VaultVerRepo] Ошибка при получении списка ключей объектов: %v", err)
+		return nil, fmt.Errorf("ошибка выполнения запроса на получение ключей объектов: %w", err)
+	}
+
+	log.Printf("[VaultVerRepo] Получено %d ключей объектов", len(objectKeys))
+	return objectKeys, nil
+}
+
+// verificationColumns - список колонок версии вместе с результатами проверки целостности.
+const verificationColumns = `id, vault_id, object_key, checksum, size_bytes, created_at, content_modified_at,` +
+	` restored_from_version_id, last_verified_at, verification_status`
+
+// ListVersionsForVerification возвращает версии, которые не проверялись с момента verifiedBefore.
+// Непроверенные ранее версии возвращаются первыми.
+func (r *postgresVaultVersionRepository) ListVersionsForVerification(
+	ctx context.Context,
+	verifiedBefore time.Time,
+	limit int,
+) ([]models.VaultVersion, error) {
+	query := `SELECT ` + verificationColumns + `
+	          FROM vault_versions
+	          WHERE last_verified_at IS NULL OR last_verified_at < $1
+	          ORDER BY last_verified_at NULLS FIRST, id
+	          LIMIT $2`
+
+	versions := make([]models.VaultVersion, 0, limit)
+	err := r.db.SelectContext(ctx, &versions, query, verifiedBefore, limit)
+	if err != nil {
+		log.Printf("[VaultVerRepo] Ошибка при получении версий для проверки целостности: %v", err)
+		return nil, fmt.Errorf("ошибка выполнения запроса на получение версий для проверки: %w", err)
+	}
+
+	return versions, nil
+}
+
+// UpdateVerificationStatus сохраняет результат проверки целостности объекта версии.
+func (r *postgresVaultVersionRepository) UpdateVerificationStatus(
+	ctx context.Context,
+	versionID int64,
+	status string,
+	verifiedAt time.Time,
+) error {
+	query := `UPDATE vault_versions SET verification_status = $1, last_verified_at = $2 WHERE id = $3`
+
+	result, err := r.db.ExecContext(ctx, query, status, verifiedAt, versionID)
+	if err != nil {
+		log.Printf("[VaultVerRepo] Ошибка при сохранении статуса проверки версии ID %d: %v", versionID, err)
+		return fmt.Errorf("ошибка выполнения запроса на обновление статуса проверки: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("[VaultVerRepo] Ошибка получения количества обновленных строк для версии ID %d: %v", versionID, err)
+		return fmt.Errorf("ошибка получения результата обновления статуса проверки: %w", err)
+	}
+	if rowsAffected == 0 {
+		log.Printf("[VaultVerRepo] Версия с ID %d не найдена при сохранении статуса проверки", versionID)
+		return ErrVersionNotFound
+	}
+
+	return nil
+}
+
+// ListVersionsWithIntegrityProblems возвращает версии, последняя проверка которых завершилась неуспешно.
+func (r *postgresVaultVersionRepository) ListVersionsWithIntegrityProblems(
+	ctx context.Context,
+	limit,
+	offset int,
+) ([]models.VaultVersion, error) {
+	query := `SELECT ` + verificationColumns + `
+	          FROM vault_versions
+	          WHERE verification_status IS NOT NULL AND verification_status <> $1
+	          ORDER BY last_verified_at DESC, id
+	          LIMIT $2 OFFSET $3`
+
+	versions := make([]models.VaultVersion, 0, limit)
+	err := r.db.SelectContext(ctx, &versions, query, models.VerificationStatusOK, limit, offset)
+	if err != nil {
+		log.Printf("[VaultVerRepo] Ошибка при получении версий с нарушением целостности: %v", err)
+		return nil, fmt.Errorf("ошибка выполнения запроса на получение версий с нарушением целостности: %w", err)
+	}
+
+	log.Printf("[VaultVerRepo] Найдено %d версий с нарушением целостности (limit=%d, offset=%d)",
+		len(versions), limit, offset)
+	return versions, nil
+}
+
+// ListObjectKeysByUserID возвращает ключи объектов всех версий хранилища пользователя.
+func (r *postgresVaultVersionRepository) ListObjectKeysByUserID(ctx context.Context, userID int64) ([]string, error) {
+	query := `SELECT vv.object_key
+	          FROM vault_versions vv JOIN vaults v ON v.id = vv.vault_id
+	          WHERE v.user_id = $1
+	          GROUP BY vv.object_key
+	          ORDER BY MIN(vv.id)`
+
+	var objectKeys []string
+	if err := r.db.SelectContext(ctx, &objectKeys, query, userID); err != nil {
+		log.Printf("[VaultVerRepo] Ошибка при получении ключей объектов пользователя ID %d: %v", userID, err)
+		return nil, fmt.Errorf("ошибка выполнения запроса на получение ключей объектов пользователя: %w", err)
+	}
+	return objectKeys, nil
+}
+
+// DeleteVersions удаляет версии по ID. Объекты, на которые ссылаются оставшиеся версии
+// (например, созданные откатом), в результат не попадают.
+func (r *postgresVaultVersionRepository) DeleteVersions(
+	ctx context.Context,
+	versionIDs []int64,
+) ([]models.VaultVersion, error) {
+	if len(versionIDs) == 0 {
+		return []models.VaultVersion{}, nil
+	}
+	// Основной запрос видит таблицу до удаления, поэтому удаляемые версии исключаются явно
+	query := `WITH deleted AS (
+	              DELETE FROM vault_versions WHERE id = ANY($1) RETURNING object_key, size_bytes
+	          )
+	          SELECT DISTINCT ON (d.object_key) d.object_key, d.size_bytes
+	          FROM deleted d
+	          WHERE NOT EXISTS (
+	              SELECT 1 FROM vault_versions vv WHERE vv.object_key = d.object_key AND vv.id <> ALL($1)
+	          )
+	          ORDER BY d.object_key`
+
+	orphaned := make([]models.VaultVersion, 0, len(versionIDs))
+	if err := r.db.SelectContext(ctx, &orphaned, query, pq.Array(versionIDs)); err != nil {
+		log.Printf("[VaultVerRepo] Ошибка при удалении версий %v: %v", versionIDs, err)
+		return nil, fmt.Errorf("ошибка выполнения запроса на удаление версий: %w", err)
+	}
+
+	log.Printf("[VaultVerRepo] Удалено версий: %d, объектов без ссылок: %d", len(versionIDs), len(orphaned))
+	return orphaned, nil
+}
+
 // Кастомные ошибки репозитория версий.
 var (
 	ErrVersionNotFound = errors.New("версия хранилища не найдена") // Возвращаем определение
@@ -43,6 +43,9 @@ func TestCreateVersion(t *testing.T) {
 	checksum := "abc"
 	sizeBytes := int64(1024)
 	versionContentModifiedAt := now.Add(-time.Hour)
+	kdbxVersion, kdbxCipher, kdbxKDF := "4.1", "AES-256", "Argon2id"
+	clientName, clientVersion := "gophkeeper-client", "v1.2.0"
+	deviceID, uploaderIP := "5f2c9a0e7b3d41e8", "192.0.2.10"
 
 	tests := []struct {
 		name        string
@@ -59,15 +62,27 @@ func TestCreateVersion(t *testing.T) {
 				Checksum:          &checksum,
 				SizeBytes:         &sizeBytes,
 				ContentModifiedAt: &versionContentModifiedAt,
+				KdbxVersion:       &kdbxVersion,
+				KdbxCipher:        &kdbxCipher,
+				KdbxKDF:           &kdbxKDF,
+				ClientName:        &clientName,
+				ClientVersion:     &clientVersion,
+				DeviceID:          &deviceID,
+				UploaderIP:        &uploaderIP,
 			},
 			mockSetup: func(mock sqlmock.Sqlmock, version *models.VaultVersion) {
-				rows := sqlmock.NewRows([]string{"id"}).AddRow(int64(601))
+				rows := sqlmock.NewRows([]string{"id", "created_at"}).AddRow(int64(601), now)
 				query := regexp.QuoteMeta(
-					`INSERT INTO vault_versions (vault_id, object_key, checksum, size_bytes, content_modified_at)` +
-						` VALUES ($1, $2, $3, $4, $5) RETURNING id`,
+					`INSERT INTO vault_versions` +
+						` (vault_id, object_key, checksum, size_bytes, content_modified_at, restored_from_version_id,` +
+						` kdbx_version, kdbx_cipher, kdbx_kdf, client_name, client_version, device_id, uploader_ip)` +
+						` VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) RETURNING id, created_at`,
 				)
 				mock.ExpectQuery(query).
-					WithArgs(version.VaultID, version.ObjectKey, version.Checksum, version.SizeBytes, version.ContentModifiedAt).
+					WithArgs(version.VaultID, version.ObjectKey, version.Checksum, version.SizeBytes,
+						version.ContentModifiedAt, version.RestoredFromVersionID,
+						version.KdbxVersion, version.KdbxCipher, version.KdbxKDF,
+						version.ClientName, version.ClientVersion, version.DeviceID, version.UploaderIP).
 					WillReturnRows(rows)
 			},
 			expectedID:  601,
@@ -84,12 +99,17 @@ func TestCreateVersion(t *testing.T) {
 			},
 			mockSetup: func(mock sqlmock.Sqlmock, version *models.VaultVersion) {
 				query := regexp.QuoteMeta(
-					`INSERT INTO vault_versions (vault_id, object_key, checksum, size_bytes, content_modified_at)` +
-						` VALUES ($1, $2, $3, $4, $5) RETURNING id`,
+					`INSERT INTO vault_versions` +
+						` (vault_id, object_key, checksum, size_bytes, content_modified_at, restored_from_version_id,` +
+						` kdbx_version, kdbx_cipher, kdbx_kdf, client_name, client_version, device_id, uploader_ip)` +
+						` VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) RETURNING id, created_at`,
 				)
 				pqErr := &pq.Error{Code: "23505"} // unique_violation
 				mock.ExpectQuery(query).
-					WithArgs(version.VaultID, version.ObjectKey, version.Checksum, version.SizeBytes, version.ContentModifiedAt).
+					WithArgs(version.VaultID, version.ObjectKey, version.Checksum, version.SizeBytes,
+						version.ContentModifiedAt, version.RestoredFromVersionID,
+						version.KdbxVersion, version.KdbxCipher, version.KdbxKDF,
+						version.ClientName, version.ClientVersion, version.DeviceID, version.UploaderIP).
 					WillReturnError(pqErr)
 			},
 			expectedID:  0,
@@ -106,12 +126,17 @@ func TestCreateVersion(t *testing.T) {
 			},
 			mockSetup: func(mock sqlmock.Sqlmock, version *models.VaultVersion) {
 				query := regexp.QuoteMeta(
-					`INSERT INTO vault_versions (vault_id, object_key, checksum, size_bytes, content_modified_at)` +
-						` VALUES ($1, $2, $3, $4, $5) RETURNING id`,
+					`INSERT INTO vault_versions` +
+						` (vault_id, object_key, checksum, size_bytes, content_modified_at, restored_from_version_id,` +
+						` kdbx_version, kdbx_cipher, kdbx_kdf, client_name, client_version, device_id, uploader_ip)` +
+						` VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) RETURNING id, created_at`,
 				)
 				dbErr := errors.New("connection error")
 				mock.ExpectQuery(query).
-					WithArgs(version.VaultID, version.ObjectKey, version.Checksum, version.SizeBytes, version.ContentModifiedAt).
+					WithArgs(version.VaultID, version.ObjectKey, version.Checksum, version.SizeBytes,
+						version.ContentModifiedAt, version.RestoredFromVersionID,
+						version.KdbxVersion, version.KdbxCipher, version.KdbxKDF,
+						version.ClientName, version.ClientVersion, version.DeviceID, version.UploaderIP).
 					WillReturnError(dbErr)
 			},
 			expectedID:  0,
@@ -129,6 +154,8 @@ func TestCreateVersion(t *testing.T) {
 			assert.Equal(t, tt.expectedID, versionID)
 			if tt.expectedErr == nil {
 				require.NoError(t, err)
+				assert.Equal(t, tt.expectedID, tt.version.ID)
+				assert.Equal(t, now, tt.version.CreatedAt)
 			} else {
 				require.Error(t, err)
 				assert.Contains(t, err.Error(), tt.expectedErr.Error())
@@ -182,8 +209,10 @@ func TestListVersionsByVaultID(t *testing.T) {
 					rows.AddRow(v.ID, v.VaultID, v.ObjectKey, v.Checksum, v.SizeBytes, v.CreatedAt, v.ContentModifiedAt)
 				}
 				query := regexp.QuoteMeta(
-					`SELECT id, vault_id, object_key, checksum, size_bytes, created_at, content_modified_at` +
-						` FROM vault_versions WHERE vault_id=$1 ORDER BY created_at DESC LIMIT $2 OFFSET $3`,
+					`SELECT id, vault_id, object_key, checksum, size_bytes, created_at, content_modified_at,` +
+						` restored_from_version_id, kdbx_version, kdbx_cipher, kdbx_kdf,` +
+						` client_name, client_version, device_id, uploader_ip FROM vault_versions WHERE vault_id=$1` +
+						` ORDER BY created_at DESC, id DESC LIMIT $2 OFFSET $3`,
 				)
 				mock.ExpectQuery(query).WithArgs(vaultID, limit, offset).WillReturnRows(rows)
 			},
@@ -201,8 +230,10 @@ func TestListVersionsByVaultID(t *testing.T) {
 					"created_at", "content_modified_at",
 				})
 				query := regexp.QuoteMeta(
-					`SELECT id, vault_id, object_key, checksum, size_bytes, created_at, content_modified_at` +
-						` FROM vault_versions WHERE vault_id=$1 ORDER BY created_at DESC LIMIT $2 OFFSET $3`,
+					`SELECT id, vault_id, object_key, checksum, size_bytes, created_at, content_modified_at,` +
+						` restored_from_version_id, kdbx_version, kdbx_cipher, kdbx_kdf,` +
+						` client_name, client_version, device_id, uploader_ip FROM vault_versions WHERE vault_id=$1` +
+						` ORDER BY created_at DESC, id DESC LIMIT $2 OFFSET $3`,
 				)
 				mock.ExpectQuery(query).WithArgs(vaultID, limit, offset).WillReturnRows(rows)
 			},
@@ -216,8 +247,10 @@ func TestListVersionsByVaultID(t *testing.T) {
 			offset:  0,
 			mockSetup: func(mock sqlmock.Sqlmock, vaultID int64, limit, offset int) {
 				query := regexp.QuoteMeta(
-					`SELECT id, vault_id, object_key, checksum, size_bytes, created_at, content_modified_at` +
-						` FROM vault_versions WHERE vault_id=$1 ORDER BY created_at DESC LIMIT $2 OFFSET $3`,
+					`SELECT id, vault_id, object_key, checksum, size_bytes, created_at, content_modified_at,` +
+						` restored_from_version_id, kdbx_version, kdbx_cipher, kdbx_kdf,` +
+						` client_name, client_version, device_id, uploader_ip FROM vault_versions WHERE vault_id=$1` +
+						` ORDER BY created_at DESC, id DESC LIMIT $2 OFFSET $3`,
 				)
 				dbErr := errors.New("select error")
 				mock.ExpectQuery(query).WithArgs(vaultID, limit, offset).WillReturnError(dbErr)
@@ -254,6 +287,9 @@ func TestGetVersionByID(t *testing.T) {
 	checksum := "abc"
 	sizeBytes := int64(1024)
 	modTime := now.Add(-time.Hour)
+	restoredFromID := int64(600)
+	kdbxVersion, kdbxCipher, kdbxKDF := "3.1", "ChaCha20", "AES-KDF"
+	clientName, deviceID, uploaderIP := "gophkeeper-client", "5f2c9a0e7b3d41e8", "2001:db8::1"
 	testVersion := &models.VaultVersion{
 		ID:                601,
 		VaultID:           501,
@@ -262,6 +298,14 @@ func TestGetVersionByID(t *testing.T) {
 		SizeBytes:         &sizeBytes,
 		CreatedAt:         now,
 		ContentModifiedAt: &modTime,
+		// Версия создана откатом к версии 600
+		RestoredFromVersionID: &restoredFromID,
+		KdbxVersion:           &kdbxVersion,
+		KdbxCipher:            &kdbxCipher,
+		KdbxKDF:               &kdbxKDF,
+		ClientName:            &clientName,
+		DeviceID:              &deviceID,
+		UploaderIP:            &uploaderIP,
 	}
 
 	tests := []struct {
@@ -277,14 +321,19 @@ func TestGetVersionByID(t *testing.T) {
 			mockSetup: func(mock sqlmock.Sqlmock, versionID int64) {
 				rows := sqlmock.NewRows([]string{
 					"id", "vault_id", "object_key", "checksum", "size_bytes",
-					"created_at", "content_modified_at",
+					"created_at", "content_modified_at", "restored_from_version_id",
+					"kdbx_version", "kdbx_cipher", "kdbx_kdf",
+					"client_name", "client_version", "device_id", "uploader_ip",
 				}).AddRow(
 					testVersion.ID, testVersion.VaultID, testVersion.ObjectKey, testVersion.Checksum,
 					testVersion.SizeBytes, testVersion.CreatedAt, testVersion.ContentModifiedAt,
+					testVersion.RestoredFromVersionID, kdbxVersion, kdbxCipher, kdbxKDF,
+					clientName, nil, deviceID, uploaderIP,
 				)
 				query := regexp.QuoteMeta(
-					`SELECT id, vault_id, object_key, checksum, size_bytes, created_at, content_modified_at` +
-						` FROM vault_versions WHERE id=$1`,
+					`SELECT id, vault_id, object_key, checksum, size_bytes, created_at, content_modified_at,` +
+						` restored_from_version_id, kdbx_version, kdbx_cipher, kdbx_kdf,` +
+						` client_name, client_version, device_id, uploader_ip FROM vault_versions WHERE id=$1`,
 				)
 				mock.ExpectQuery(query).WithArgs(versionID).WillReturnRows(rows)
 			},
@@ -296,8 +345,9 @@ func TestGetVersionByID(t *testing.T) {
 			versionID: 602,
 			mockSetup: func(mock sqlmock.Sqlmock, versionID int64) {
 				query := regexp.QuoteMeta(
-					`SELECT id, vault_id, object_key, checksum, size_bytes, created_at, content_modified_at` +
-						` FROM vault_versions WHERE id=$1`,
+					`SELECT id, vault_id, object_key, checksum, size_bytes, created_at, content_modified_at,` +
+						` restored_from_version_id, kdbx_version, kdbx_cipher, kdbx_kdf,` +
+						` client_name, client_version, device_id, uploader_ip FROM vault_versions WHERE id=$1`,
 				)
 				mock.ExpectQuery(query).WithArgs(versionID).WillReturnError(sql.ErrNoRows)
 			},
@@ -309,8 +359,9 @@ func TestGetVersionByID(t *testing.T) {
 			versionID: 603,
 			mockSetup: func(mock sqlmock.Sqlmock, versionID int64) {
 				query := regexp.QuoteMeta(
-					`SELECT id, vault_id, object_key, checksum, size_bytes, created_at, content_modified_at` +
-						` FROM vault_versions WHERE id=$1`,
+					`SELECT id, vault_id, object_key, checksum, size_bytes, created_at, content_modified_at,` +
+						` restored_from_version_id, kdbx_version, kdbx_cipher, kdbx_kdf,` +
+						` client_name, client_version, device_id, uploader_ip FROM vault_versions WHERE id=$1`,
 				)
 				dbErr := errors.New("get error")
 				mock.ExpectQuery(query).WithArgs(versionID).WillReturnError(dbErr)
@@ -344,3 +395,211 @@ func TestGetVersionByID(t *testing.T) {
 		})
 	}
 }
+
+func TestListAllObjectKeys(t *testing.T) {
+	query := regexp.QuoteMeta(`SELECT object_key FROM vault_versions GROUP BY object_key ORDER BY MIN(id)`)
+
+	t.Run("Успешное получение", func(t *testing.T) {
+		repo, mock := setupVaultVersionRepoMock(t)
+		rows := sqlmock.NewRows([]string{"object_key"}).AddRow("key1").AddRow("key2")
+		mock.ExpectQuery(query).WillReturnRows(rows)
+
+		keys, err := repo.ListAllObjectKeys(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"key1", "key2"}, keys)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Ошибка базы данных", func(t *testing.T) {
+		repo, mock := setupVaultVersionRepoMock(t)
+		mock.ExpectQuery(query).WillReturnError(errors.New("select error"))
+
+		keys, err := repo.ListAllObjectKeys(context.Background())
+
+		require.Error(t, err)
+		assert.Nil(t, keys)
+		assert.Contains(t, err.Error(), "ошибка выполнения запроса")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestListVersionsForVerification(t *testing.T) {
+	query := `SELECT id, vault_id, object_key, .* FROM vault_versions\s+WHERE last_verified_at IS NULL OR last_verified_at < \$1`
+	columns := []string{
+		"id", "vault_id", "object_key", "checksum", "size_bytes", "created_at",
+		"content_modified_at", "last_verified_at", "verification_status",
+	}
+	verifiedBefore := time.Now().Add(-time.Hour)
+
+	t.Run("Успешное получение", func(t *testing.T) {
+		repo, mock := setupVaultVersionRepoMock(t)
+		checksum := "abc"
+		rows := sqlmock.NewRows(columns).
+			AddRow(1, 10, "key1", checksum, 5, time.Now(), nil, nil, nil).
+			AddRow(2, 10, "key2", checksum, 5, time.Now(), nil, verifiedBefore.Add(-time.Hour), "ok")
+		mock.ExpectQuery(query).WithArgs(verifiedBefore, 50).WillReturnRows(rows)
+
+		versions, err := repo.ListVersionsForVerification(context.Background(), verifiedBefore, 50)
+
+		require.NoError(t, err)
+		require.Len(t, versions, 2)
+		assert.Nil(t, versions[0].VerificationStatus)
+		require.NotNil(t, versions[1].VerificationStatus)
+		assert.Equal(t, models.VerificationStatusOK, *versions[1].VerificationStatus)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Ошибка базы данных", func(t *testing.T) {
+		repo, mock := setupVaultVersionRepoMock(t)
+		mock.ExpectQuery(query).WillReturnError(errors.New("select error"))
+
+		versions, err := repo.ListVersionsForVerification(context.Background(), verifiedBefore, 50)
+
+		require.Error(t, err)
+		assert.Nil(t, versions)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUpdateVerificationStatus(t *testing.T) {
+	query := regexp.QuoteMeta(
+		`UPDATE vault_versions SET verification_status = $1, last_verified_at = $2 WHERE id = $3`)
+	verifiedAt := time.Now()
+
+	tests := []struct {
+		name      string
+		mockSetup func(mock sqlmock.Sqlmock)
+		wantErr   error
+	}{
+		{
+			name: "Успешное обновление",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(query).WithArgs(models.VerificationStatusCorrupted, verifiedAt, int64(7)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "Версия не найдена",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(query).WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantErr: repository.ErrVersionNotFound,
+		},
+		{
+			name: "Ошибка базы данных",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(query).WillReturnError(errors.New("update error"))
+			},
+			wantErr: errors.New("ошибка выполнения запроса"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock := setupVaultVersionRepoMock(t)
+			tt.mockSetup(mock)
+
+			err := repo.UpdateVerificationStatus(
+				context.Background(), 7, models.VerificationStatusCorrupted, verifiedAt)
+
+			switch {
+			case tt.wantErr == nil:
+				require.NoError(t, err)
+			case errors.Is(tt.wantErr, repository.ErrVersionNotFound):
+				require.ErrorIs(t, err, repository.ErrVersionNotFound)
+			default:
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr.Error())
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestListVersionsWithIntegrityProblems(t *testing.T) {
+	query := `SELECT id, vault_id, object_key, .* FROM vault_versions\s+WHERE verification_status IS NOT NULL`
+	columns := []string{
+		"id", "vault_id", "object_key", "checksum", "size_bytes", "created_at",
+		"content_modified_at", "last_verified_at", "verification_status",
+	}
+
+	t.Run("Успешное получение", func(t *testing.T) {
+		repo, mock := setupVaultVersionRepoMock(t)
+		rows := sqlmock.NewRows(columns).
+			AddRow(3, 10, "key3", "abc", 5, time.Now(), nil, time.Now(), models.VerificationStatusMissing)
+		mock.ExpectQuery(query).WithArgs(models.VerificationStatusOK, 20, 0).WillReturnRows(rows)
+
+		versions, err := repo.ListVersionsWithIntegrityProblems(context.Background(), 20, 0)
+
+		require.NoError(t, err)
+		require.Len(t, versions, 1)
+		assert.Equal(t, "key3", versions[0].ObjectKey)
+		assert.Equal(t, models.VerificationStatusMissing, *versions[0].VerificationStatus)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Ошибка базы данных", func(t *testing.T) {
+		repo, mock := setupVaultVersionRepoMock(t)
+		mock.ExpectQuery(query).WillReturnError(errors.New("select error"))
+
+		versions, err := repo.ListVersionsWithIntegrityProblems(context.Background(), 20, 0)
+
+		require.Error(t, err)
+		assert.Nil(t, versions)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestListObjectKeysByUserID(t *testing.T) {
+	query := `SELECT vv.object_key FROM vault_versions vv JOIN vaults v ON v.id = vv.vault_id WHERE v.user_id = \$1`
+
+	repo, mock := setupVaultVersionRepoMock(t)
+	rows := sqlmock.NewRows([]string{"object_key"}).AddRow("user_1/a.kdbx").AddRow("user_1/b.kdbx")
+	mock.ExpectQuery(query).WithArgs(int64(1)).WillReturnRows(rows)
+
+	keys, err := repo.ListObjectKeysByUserID(context.Background(), 1)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user_1/a.kdbx", "user_1/b.kdbx"}, keys)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteVersions(t *testing.T) {
+	query := `WITH deleted AS \(\s*DELETE FROM vault_versions WHERE id = ANY\(\$1\) RETURNING object_key, size_bytes` +
+		`.*vv.id <> ALL\(\$1\)`
+
+	t.Run("Возвращаются объекты без ссылок", func(t *testing.T) {
+		repo, mock := setupVaultVersionRepoMock(t)
+		rows := sqlmock.NewRows([]string{"object_key", "size_bytes"}).AddRow("user_1/old.kdbx", int64(100))
+		mock.ExpectQuery(query).WithArgs(pq.Array([]int64{3, 4})).WillReturnRows(rows)
+
+		orphaned, err := repo.DeleteVersions(context.Background(), []int64{3, 4})
+
+		require.NoError(t, err)
+		require.Len(t, orphaned, 1)
+		assert.Equal(t, "user_1/old.kdbx", orphaned[0].ObjectKey)
+		assert.Equal(t, int64(100), *orphaned[0].SizeBytes)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Пустой список не выполняет запрос", func(t *testing.T) {
+		repo, mock := setupVaultVersionRepoMock(t)
+
+		orphaned, err := repo.DeleteVersions(context.Background(), nil)
+
+		require.NoError(t, err)
+		assert.Empty(t, orphaned)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Ошибка базы данных", func(t *testing.T) {
+		repo, mock := setupVaultVersionRepoMock(t)
+		mock.ExpectQuery(query).WillReturnError(errors.New("database error"))
+
+		_, err := repo.DeleteVersions(context.Background(), []int64{3})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "ошибка выполнения запроса на удаление версий")
+	})
+}
@@ -0,0 +1,231 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/maynagashev/gophkeeper/models"
+)
+
+// WebhookRepository определяет методы для работы с вебхуками и очередью их доставок.
+type WebhookRepository interface {
+	CreateWebhook(ctx context.Context, webhook *models.Webhook) (int64, error)
+	GetWebhookByID(ctx context.Context, userID, webhookID int64) (*models.Webhook, error)
+	ListWebhooksByUserID(ctx context.Context, userID int64) ([]models.Webhook, error)
+	DeleteWebhook(ctx context.Context, userID, webhookID int64) error
+	EnqueueDeliveries(ctx context.Context, userID int64, eventType string, payload []byte) (int64, error)
+	ClaimDueDeliveries(
+		ctx context.Context,
+		now time.Time,
+		limit int,
+		leaseUntil time.Time,
+	) ([]models.WebhookDelivery, error)
+	UpdateDeliveryResult(ctx context.Context, delivery *models.WebhookDelivery) error
+	ListDeliveries(ctx context.Context, webhookID int64, limit, offset int) ([]models.WebhookDelivery, error)
+}
+
+// postgresWebhookRepository реализует WebhookRepository для PostgreSQL.
+// Через DBTX доставки ставятся в очередь и в транзакции изменения хранилища (см. TxRepositories).
+type postgresWebhookRepository struct {
+	db DBTX
+}
+
+// NewPostgresWebhookRepository создает новый экземпляр репозитория вебхуков.
+func NewPostgresWebhookRepository(db *sqlx.DB) WebhookRepository {
+	return &postgresWebhookRepository{db: db}
+}
+
+// CreateWebhook сохраняет новый вебхук пользователя.
+func (r *postgresWebhookRepository) CreateWebhook(ctx context.Context, webhook *models.Webhook) (int64, error) {
+	query := `INSERT INTO webhooks (user_id, url, secret) VALUES ($1, $2, $3) RETURNING id`
+	var webhookID int64
+
+	err := r.db.QueryRowxContext(ctx, query, webhook.UserID, webhook.URL, webhook.Secret).Scan(&webhookID)
+	if err != nil {
+		log.Printf("[WebhookRepo] Ошибка при создании вебхука для пользователя ID %d: %v", webhook.UserID, err)
+		return 0, fmt.Errorf("ошибка выполнения запроса на создание вебхука: %w", err)
+	}
+
+	log.Printf("[WebhookRepo] Вебхук (ID: %d) создан для пользователя ID %d", webhookID, webhook.UserID)
+	return webhookID, nil
+}
+
+// GetWebhookByID находит вебхук пользователя по ID.
+func (r *postgresWebhookRepository) GetWebhookByID(
+	ctx context.Context,
+	userID, webhookID int64,
+) (*models.Webhook, error) {
+	query := `SELECT id, user_id, url, secret, is_active, created_at FROM webhooks WHERE id=$1 AND user_id=$2`
+	var webhook models.Webhook
+
+	err := r.db.GetContext(ctx, &webhook, query, webhookID, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Printf("[WebhookRepo] Вебхук ID %d пользователя ID %d не найден", webhookID, userID)
+			return nil, ErrWebhookNotFound
+		}
+		log.Printf("[WebhookRepo] Ошибка при поиске вебхука ID %d: %v", webhookID, err)
+		return nil, fmt.Errorf("ошибка выполнения запроса на получение вебхука: %w", err)
+	}
+	return &webhook, nil
+}
+
+// ListWebhooksByUserID возвращает все вебхуки пользователя.
+func (r *postgresWebhookRepository) ListWebhooksByUserID(ctx context.Context, userID int64) ([]models.Webhook, error) {
+	query := `SELECT id, user_id, url, secret, is_active, created_at FROM webhooks WHERE user_id=$1 ORDER BY id`
+
+	webhooks := make([]models.Webhook, 0)
+	err := r.db.SelectContext(ctx, &webhooks, query, userID)
+	if err != nil {
+		log.Printf("[WebhookRepo] Ошибка при получении вебхуков пользователя ID %d: %v", userID, err)
+		return nil, fmt.Errorf("ошибка выполнения запроса на получение вебхуков: %w", err)
+	}
+
+	log.Printf("[WebhookRepo] Получено %d вебхуков пользователя ID %d", len(webhooks), userID)
+	return webhooks, nil
+}
+
+// DeleteWebhook удаляет вебхук пользователя вместе с историей его доставок.
+func (r *postgresWebhookRepository) DeleteWebhook(ctx context.Context, userID, webhookID int64) error {
+	query := `DELETE FROM webhooks WHERE id=$1 AND user_id=$2`
+
+	result, err := r.db.ExecContext(ctx, query, webhookID, userID)
+	if err != nil {
+		log.Printf("[WebhookRepo] Ошибка при удалении вебхука ID %d: %v", webhookID, err)
+		return fmt.Errorf("ошибка выполнения запроса на удаление вебхука: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("[WebhookRepo] Ошибка при получении количества удаленных строк (вебхук ID %d): %v", webhookID, err)
+		return fmt.Errorf("ошибка проверки результата удаления вебхука: %w", err)
+	}
+	if rowsAffected == 0 {
+		log.Printf("[WebhookRepo] Вебхук ID %d пользователя ID %d не найден при удалении", webhookID, userID)
+		return ErrWebhookNotFound
+	}
+
+	log.Printf("[WebhookRepo] Вебхук ID %d пользователя ID %d удален", webhookID, userID)
+	return nil
+}
+
+// EnqueueDeliveries ставит событие в очередь доставки на все активные вебхуки пользователя.
+// Возвращает количество созданных доставок.
+func (r *postgresWebhookRepository) EnqueueDeliveries(
+	ctx context.Context,
+	userID int64,
+	eventType string,
+	payload []byte,
+) (int64, error) {
+	query := `INSERT INTO webhook_deliveries (webhook_id, event_type, payload, status)
+	          SELECT id, $2, $3, $4 FROM webhooks WHERE user_id=$1 AND is_active`
+
+	result, err := r.db.ExecContext(ctx, query, userID, eventType, string(payload), models.WebhookDeliveryPending)
+	if err != nil {
+		log.Printf("[WebhookRepo] Ошибка постановки события %s в очередь для пользователя ID %d: %v",
+			eventType, userID, err)
+		return 0, fmt.Errorf("ошибка выполнения запроса на постановку доставок в очередь: %w", err)
+	}
+	enqueued, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("ошибка проверки результата постановки доставок в очередь: %w", err)
+	}
+
+	log.Printf("[WebhookRepo] Событие %s пользователя ID %d поставлено в очередь (%d доставок)",
+		eventType, userID, enqueued)
+	return enqueued, nil
+}
+
+// ClaimDueDeliveries выбирает доставки, которые пора отправить, и откладывает их следующую попытку
+// до leaseUntil. Так другие реплики сервера не возьмут эти доставки, пока текущая их отправляет,
+// а доставки, не завершенные из-за падения реплики, будут повторены после истечения аренды.
+func (r *postgresWebhookRepository) ClaimDueDeliveries(
+	ctx context.Context,
+	now time.Time,
+	limit int,
+	leaseUntil time.Time,
+) ([]models.WebhookDelivery, error) {
+	query := `UPDATE webhook_deliveries d SET next_attempt_at = $3
+	          FROM webhooks w
+	          WHERE d.webhook_id = w.id AND d.id IN (
+	              SELECT id FROM webhook_deliveries
+	              WHERE status = $4 AND next_attempt_at <= $1
+	              ORDER BY next_attempt_at
+	              LIMIT $2
+	              FOR UPDATE SKIP LOCKED
+	          )
+	          RETURNING d.id, d.webhook_id, d.event_type, d.payload, d.status, d.attempts, d.next_attempt_at,
+	                    d.last_attempt_at, d.response_status, d.last_error, d.created_at, d.delivered_at,
+	                    w.url, w.secret`
+
+	deliveries := make([]models.WebhookDelivery, 0, limit)
+	err := r.db.SelectContext(ctx, &deliveries, query, now, limit, leaseUntil, models.WebhookDeliveryPending)
+	if err != nil {
+		log.Printf("[WebhookRepo] Ошибка при выборке доставок для отправки: %v", err)
+		return nil, fmt.Errorf("ошибка выполнения запроса на выборку доставок: %w", err)
+	}
+	return deliveries, nil
+}
+
+// UpdateDeliveryResult сохраняет результат попытки доставки.
+func (r *postgresWebhookRepository) UpdateDeliveryResult(ctx context.Context, delivery *models.WebhookDelivery) error {
+	query := `UPDATE webhook_deliveries
+	          SET status = $2, attempts = $3, next_attempt_at = $4, last_attempt_at = $5,
+	              response_status = $6, last_error = $7, delivered_at = $8
+	          WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, query,
+		delivery.ID, delivery.Status, delivery.Attempts, delivery.NextAttemptAt, delivery.LastAttemptAt,
+		delivery.ResponseStatus, delivery.LastError, delivery.DeliveredAt,
+	)
+	if err != nil {
+		log.Printf("[WebhookRepo] Ошибка при сохранении результата доставки ID %d: %v", delivery.ID, err)
+		return fmt.Errorf("ошибка выполнения запроса на обновление доставки: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка проверки результата обновления доставки: %w", err)
+	}
+	if rowsAffected == 0 {
+		// Вебхук мог быть удален вместе с историей доставок во время отправки
+		log.Printf("[WebhookRepo] Доставка ID %d не найдена при сохранении результата", delivery.ID)
+		return ErrWebhookDeliveryNotFound
+	}
+	return nil
+}
+
+// ListDeliveries возвращает историю доставок вебхука (сначала новые).
+func (r *postgresWebhookRepository) ListDeliveries(
+	ctx context.Context,
+	webhookID int64,
+	limit,
+	offset int,
+) ([]models.WebhookDelivery, error) {
+	query := `SELECT id, webhook_id, event_type, payload, status, attempts, next_attempt_at, last_attempt_at,
+	                 response_status, last_error, created_at, delivered_at
+	          FROM webhook_deliveries
+	          WHERE webhook_id=$1
+	          ORDER BY created_at DESC, id DESC
+	          LIMIT $2 OFFSET $3`
+
+	deliveries := make([]models.WebhookDelivery, 0, limit)
+	err := r.db.SelectContext(ctx, &deliveries, query, webhookID, limit, offset)
+	if err != nil {
+		log.Printf("[WebhookRepo] Ошибка при получении истории доставок вебхука ID %d: %v", webhookID, err)
+		return nil, fmt.Errorf("ошибка выполнения запроса на получение истории доставок: %w", err)
+	}
+
+	log.Printf("[WebhookRepo] Получено %d доставок вебхука ID %d (limit=%d, offset=%d)",
+		len(deliveries), webhookID, limit, offset)
+	return deliveries, nil
+}
+
+// Кастомные ошибки репозитория вебхуков.
+var (
+	ErrWebhookNotFound         = errors.New("вебхук не найден")
+	ErrWebhookDeliveryNotFound = errors.New("доставка вебхука не найдена")
+)
@@ -0,0 +1,330 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/maynagashev/gophkeeper/models"
+	"github.com/maynagashev/gophkeeper/server/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Вспомогательная функция для создания мока БД и репозитория вебхуков.
+func setupWebhookRepoMock(t *testing.T) (repository.WebhookRepository, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	return repository.NewPostgresWebhookRepository(sqlxDB), mock
+}
+
+var webhookColumns = []string{"id", "user_id", "url", "secret", "is_active", "created_at"} //nolint:gochecknoglobals // Тестовые данные
+
+var deliveryColumns = []string{ //nolint:gochecknoglobals // Тестовые данные
+	"id", "webhook_id", "event_type", "payload", "status", "attempts", "next_attempt_at",
+	"last_attempt_at", "response_status", "last_error", "created_at", "delivered_at",
+}
+
+func TestCreateWebhook(t *testing.T) {
+	query := regexp.QuoteMeta(`INSERT INTO webhooks (user_id, url, secret) VALUES ($1, $2, $3) RETURNING id`)
+	webhook := &models.Webhook{UserID: 1, URL: "https://example.com/hook", Secret: "s3cr3t"}
+
+	t.Run("Успешное создание", func(t *testing.T) {
+		repo, mock := setupWebhookRepoMock(t)
+		mock.ExpectQuery(query).WithArgs(webhook.UserID, webhook.URL, webhook.Secret).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(5)))
+
+		id, err := repo.CreateWebhook(context.Background(), webhook)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), id)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Ошибка базы данных", func(t *testing.T) {
+		repo, mock := setupWebhookRepoMock(t)
+		mock.ExpectQuery(query).WillReturnError(errors.New("insert error"))
+
+		id, err := repo.CreateWebhook(context.Background(), webhook)
+
+		require.Error(t, err)
+		assert.Zero(t, id)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetWebhookByID(t *testing.T) {
+	query := regexp.QuoteMeta(
+		`SELECT id, user_id, url, secret, is_active, created_at FROM webhooks WHERE id=$1 AND user_id=$2`)
+
+	t.Run("Успешный поиск", func(t *testing.T) {
+		repo, mock := setupWebhookRepoMock(t)
+		mock.ExpectQuery(query).WithArgs(int64(5), int64(1)).WillReturnRows(
+			sqlmock.NewRows(webhookColumns).AddRow(5, 1, "https://example.com/hook", "s3cr3t", true, time.Now()))
+
+		webhook, err := repo.GetWebhookByID(context.Background(), 1, 5)
+
+		require.NoError(t, err)
+		assert.Equal(t, "https://example.com/hook", webhook.URL)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Вебхук не найден", func(t *testing.T) {
+		repo, mock := setupWebhookRepoMock(t)
+		mock.ExpectQuery(query).WillReturnError(sql.ErrNoRows)
+
+		webhook, err := repo.GetWebhookByID(context.Background(), 1, 5)
+
+		require.ErrorIs(t, err, repository.ErrWebhookNotFound)
+		assert.Nil(t, webhook)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestListWebhooksByUserID(t *testing.T) {
+	query := regexp.QuoteMeta(
+		`SELECT id, user_id, url, secret, is_active, created_at FROM webhooks WHERE user_id=$1 ORDER BY id`)
+
+	t.Run("Успешное получение", func(t *testing.T) {
+		repo, mock := setupWebhookRepoMock(t)
+		mock.ExpectQuery(query).WithArgs(int64(1)).WillReturnRows(sqlmock.NewRows(webhookColumns).
+			AddRow(5, 1, "https://a.example.com", "s1", true, time.Now()).
+			AddRow(6, 1, "https://b.example.com", "s2", false, time.Now()))
+
+		webhooks, err := repo.ListWebhooksByUserID(context.Background(), 1)
+
+		require.NoError(t, err)
+		require.Len(t, webhooks, 2)
+		assert.False(t, webhooks[1].IsActive)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Ошибка базы данных", func(t *testing.T) {
+		repo, mock := setupWebhookRepoMock(t)
+		mock.ExpectQuery(query).WillReturnError(errors.New("select error"))
+
+		webhooks, err := repo.ListWebhooksByUserID(context.Background(), 1)
+
+		require.Error(t, err)
+		assert.Nil(t, webhooks)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDeleteWebhook(t *testing.T) {
+	query := regexp.QuoteMeta(`DELETE FROM webhooks WHERE id=$1 AND user_id=$2`)
+
+	tests := []struct {
+		name      string
+		mockSetup func(mock sqlmock.Sqlmock)
+		wantErr   error
+	}{
+		{
+			name: "Успешное удаление",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(query).WithArgs(int64(5), int64(1)).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "Вебхук не найден",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(query).WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantErr: repository.ErrWebhookNotFound,
+		},
+		{
+			name: "Ошибка базы данных",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(query).WillReturnError(errors.New("delete error"))
+			},
+			wantErr: errors.New("ошибка выполнения запроса"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock := setupWebhookRepoMock(t)
+			tt.mockSetup(mock)
+
+			err := repo.DeleteWebhook(context.Background(), 1, 5)
+
+			switch {
+			case tt.wantErr == nil:
+				require.NoError(t, err)
+			case errors.Is(tt.wantErr, repository.ErrWebhookNotFound):
+				require.ErrorIs(t, err, repository.ErrWebhookNotFound)
+			default:
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr.Error())
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestEnqueueDeliveries(t *testing.T) {
+	query := `INSERT INTO webhook_deliveries \(webhook_id, event_type, payload, status\)\s+` +
+		`SELECT id, \$2, \$3, \$4 FROM webhooks WHERE user_id=\$1 AND is_active`
+	payload := []byte(`{"type":"version_created"}`)
+
+	t.Run("Успешная постановка в очередь", func(t *testing.T) {
+		repo, mock := setupWebhookRepoMock(t)
+		mock.ExpectExec(query).
+			WithArgs(int64(1), models.VaultEventVersionCreated, string(payload), models.WebhookDeliveryPending).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+
+		enqueued, err := repo.EnqueueDeliveries(context.Background(), 1, models.VaultEventVersionCreated, payload)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), enqueued)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Ошибка базы данных", func(t *testing.T) {
+		repo, mock := setupWebhookRepoMock(t)
+		mock.ExpectExec(query).WillReturnError(errors.New("insert error"))
+
+		_, err := repo.EnqueueDeliveries(context.Background(), 1, models.VaultEventVersionCreated, payload)
+
+		require.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestClaimDueDeliveries(t *testing.T) {
+	query := `UPDATE webhook_deliveries d SET next_attempt_at = \$3 FROM webhooks w .* FOR UPDATE SKIP LOCKED .* RETURNING`
+	now := time.Now()
+	leaseUntil := now.Add(time.Minute)
+
+	t.Run("Успешная выборка", func(t *testing.T) {
+		repo, mock := setupWebhookRepoMock(t)
+		columns := append(append([]string{}, deliveryColumns...), "url", "secret")
+		rows := sqlmock.NewRows(columns).AddRow(
+			10, 5, models.VaultEventRolledBack, []byte(`{"type":"rolled_back"}`), models.WebhookDeliveryPending,
+			1, leaseUntil, now, 500, "status 500", now, nil, "https://example.com/hook", "s3cr3t")
+		mock.ExpectQuery(query).WithArgs(now, 20, leaseUntil, models.WebhookDeliveryPending).WillReturnRows(rows)
+
+		deliveries, err := repo.ClaimDueDeliveries(context.Background(), now, 20, leaseUntil)
+
+		require.NoError(t, err)
+		require.Len(t, deliveries, 1)
+		assert.Equal(t, "https://example.com/hook", deliveries[0].URL)
+		assert.Equal(t, "s3cr3t", deliveries[0].Secret)
+		assert.JSONEq(t, `{"type":"rolled_back"}`, string(deliveries[0].Payload))
+		require.NotNil(t, deliveries[0].ResponseStatus)
+		assert.Equal(t, 500, *deliveries[0].ResponseStatus)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Ошибка базы данных", func(t *testing.T) {
+		repo, mock := setupWebhookRepoMock(t)
+		mock.ExpectQuery(query).WillReturnError(errors.New("update error"))
+
+		deliveries, err := repo.ClaimDueDeliveries(context.Background(), now, 20, leaseUntil)
+
+		require.Error(t, err)
+		assert.Nil(t, deliveries)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUpdateDeliveryResult(t *testing.T) {
+	query := `UPDATE webhook_deliveries\s+SET status = \$2, attempts = \$3, .* WHERE id = \$1`
+	now := time.Now()
+	responseStatus := 200
+	delivery := &models.WebhookDelivery{
+		ID: 10, Status: models.WebhookDeliveryDelivered, Attempts: 1, NextAttemptAt: now,
+		LastAttemptAt: &now, ResponseStatus: &responseStatus, DeliveredAt: &now,
+	}
+
+	tests := []struct {
+		name      string
+		mockSetup func(mock sqlmock.Sqlmock)
+		wantErr   error
+	}{
+		{
+			name: "Успешное обновление",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(query).
+					WithArgs(delivery.ID, delivery.Status, delivery.Attempts, delivery.NextAttemptAt,
+						delivery.LastAttemptAt, delivery.ResponseStatus, delivery.LastError, delivery.DeliveredAt).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "Доставка не найдена",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(query).WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantErr: repository.ErrWebhookDeliveryNotFound,
+		},
+		{
+			name: "Ошибка базы данных",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(query).WillReturnError(errors.New("update error"))
+			},
+			wantErr: errors.New("ошибка выполнения запроса"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock := setupWebhookRepoMock(t)
+			tt.mockSetup(mock)
+
+			err := repo.UpdateDeliveryResult(context.Background(), delivery)
+
+			switch {
+			case tt.wantErr == nil:
+				require.NoError(t, err)
+			case errors.Is(tt.wantErr, repository.ErrWebhookDeliveryNotFound):
+				require.ErrorIs(t, err, repository.ErrWebhookDeliveryNotFound)
+			default:
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr.Error())
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestListDeliveries(t *testing.T) {
+	query := `SELECT id, webhook_id, event_type, .* FROM webhook_deliveries\s+WHERE webhook_id=\$1`
+
+	t.Run("Успешное получение", func(t *testing.T) {
+		repo, mock := setupWebhookRepoMock(t)
+		now := time.Now()
+		rows := sqlmock.NewRows(deliveryColumns).
+			AddRow(11, 5, models.VaultEventVersionCreated, []byte(`{}`), models.WebhookDeliveryDelivered,
+				1, now, now, 204, nil, now, now).
+			AddRow(10, 5, models.VaultEventRolledBack, []byte(`{}`), models.WebhookDeliveryFailed,
+				8, now, now, nil, "connection refused", now, nil)
+		mock.ExpectQuery(query).WithArgs(int64(5), 20, 0).WillReturnRows(rows)
+
+		deliveries, err := repo.ListDeliveries(context.Background(), 5, 20, 0)
+
+		require.NoError(t, err)
+		require.Len(t, deliveries, 2)
+		assert.Equal(t, models.WebhookDeliveryFailed, deliveries[1].Status)
+		require.NotNil(t, deliveries[1].LastError)
+		assert.Equal(t, "connection refused", *deliveries[1].LastError)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Ошибка базы данных", func(t *testing.T) {
+		repo, mock := setupWebhookRepoMock(t)
+		mock.ExpectQuery(query).WillReturnError(errors.New("select error"))
+
+		deliveries, err := repo.ListDeliveries(context.Background(), 5, 20, 0)
+
+		require.Error(t, err)
+		assert.Nil(t, deliveries)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
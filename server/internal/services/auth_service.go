@@ -2,6 +2,8 @@ package services
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"log"
@@ -9,26 +11,46 @@ import (
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/maynagashev/gophkeeper/models"
+	"github.com/maynagashev/gophkeeper/server/internal/events"
 	"github.com/maynagashev/gophkeeper/server/internal/repository"
 	"golang.org/x/crypto/bcrypt"
 )
 
 // AuthService определяет интерфейс для сервиса аутентификации.
 type AuthService interface {
-	Register(username, password string) error
-	Login(username, password string) (string, error) // Возвращает JWT токен или ошибку
+	Register(ctx context.Context, username, password string) error
+	// Login возвращает JWT токен или ошибку. device описывает устройство, с которого выполнен вход.
+	Login(ctx context.Context, username, password string, device models.Device) (string, error)
+	// VerifyCredentials проверяет имя и пароль по тем же правилам, что и Login, и возвращает пользователя.
+	// В отличие от Login токен не выпускается, а попытка не учитывается в метриках входа и в устройствах:
+	// так проверяются пароли, которые клиент передает с каждым запросом (HTTP Basic в WebDAV).
+	VerifyCredentials(ctx context.Context, username, password string) (*models.User, error)
 }
 
-// Константы для JWT.
+// Параметры JWT по умолчанию.
 const (
-	// TODO: Вынести секретный ключ в конфигурацию/переменные окружения!
-	jwtSecretKey = "your-very-secret-key"
-	tokenTTL     = time.Hour * 24 // Время жизни токена - 24 часа
+	// DefaultJWTSecret - ключ подписи токенов, если он не задан в конфигурации.
+	// Подходит только для локальной разработки: в рабочей среде ключ нужно задать явно.
+	DefaultJWTSecret = "your-very-secret-key"
+	// DefaultTokenTTL - время жизни токена по умолчанию.
+	DefaultTokenTTL = time.Hour * 24
 )
 
+// TokenConfig задает параметры выдаваемых JWT. Пустые поля заменяются значениями по умолчанию.
+type TokenConfig struct {
+	// Secret - ключ подписи HS256; должен совпадать с ключом middleware аутентификации.
+	Secret string
+	// TTL - время жизни токена.
+	TTL time.Duration
+}
+
+// loginEventPublishTimeout - таймаут публикации события о входе с нового устройства.
+const loginEventPublishTimeout = 5 * time.Second
+
 // Структура для пользовательских данных в JWT (claims).
 type jwtClaims struct {
-	UserID int64 `json:"user_id"`
+	UserID int64  `json:"user_id"`
+	Role   string `json:"role"` // Роль пользователя на момент выдачи токена
 	jwt.RegisteredClaims
 }
 
@@ -36,17 +58,39 @@ type jwtClaims struct {
 var _ AuthService = (*authService)(nil)
 
 type authService struct {
-	userRepo repository.UserRepository // Зависимость от репозитория пользователей
+	userRepo  repository.UserRepository // Зависимость от репозитория пользователей
+	publisher events.Publisher          // Получатель событий о входе с нового устройства (может быть nil)
+	tokens    TokenConfig
+	timeouts  Timeouts
+	metrics   AuthMetrics
 }
 
 // NewAuthService создает новый экземпляр сервиса аутентификации.
-func NewAuthService(userRepo repository.UserRepository) AuthService { // Возвращаем интерфейс
-	return &authService{userRepo: userRepo}
+// publisher может быть nil, тогда события о входе с нового устройства не публикуются.
+// Из timeouts используется только таймаут запросов к БД. metrics может быть nil.
+func NewAuthService(
+	userRepo repository.UserRepository,
+	publisher events.Publisher,
+	tokens TokenConfig,
+	timeouts Timeouts,
+	metrics AuthMetrics,
+) AuthService {
+	if metrics == nil {
+		metrics = noopMetrics{}
+	}
+	if tokens.Secret == "" {
+		tokens.Secret = DefaultJWTSecret
+	}
+	if tokens.TTL <= 0 {
+		tokens.TTL = DefaultTokenTTL
+	}
+	return &authService{userRepo: userRepo, publisher: publisher, tokens: tokens, timeouts: timeouts, metrics: metrics}
 }
 
 // Register регистрирует нового пользователя.
-func (s *authService) Register(username, password string) error {
-	ctx := context.Background() // Используем фоновый контекст для операций сервиса
+func (s *authService) Register(ctx context.Context, username, password string) error {
+	ctx, cancel := withTimeout(ctx, s.timeouts.DB)
+	defer cancel()
 
 	// Хешируем пароль
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
@@ -68,7 +112,7 @@ func (s *authService) Register(username, password string) error {
 			return ErrUsernameTaken // Возвращаем ошибку слоя сервиса
 		}
 		log.Printf("[AuthService] Непредвиденная ошибка репозитория при регистрации '%s': %v", username, err)
-		return errors.New("внутренняя ошибка сервера при создании пользователя")
+		return timeoutOr(ctx, errors.New("внутренняя ошибка сервера при создании пользователя"))
 	}
 
 	log.Printf("[AuthService] Пользователь '%s' успешно зарегистрирован", username)
@@ -76,18 +120,62 @@ func (s *authService) Register(username, password string) error {
 }
 
 // Login аутентифицирует пользователя и возвращает JWT токен.
-func (s *authService) Login(username, password string) (string, error) {
-	ctx := context.Background()
+func (s *authService) Login(ctx context.Context, username, password string, device models.Device) (string, error) {
+	token, err := s.login(ctx, username, password, device)
+	switch {
+	case err == nil:
+		s.metrics.LoginAttempt(LoginResultSuccess)
+	case errors.Is(err, ErrInvalidCredentials):
+		s.metrics.LoginAttempt(LoginResultInvalidCredentials)
+	case errors.Is(err, ErrAccountDisabled):
+		s.metrics.LoginAttempt(LoginResultAccountDisabled)
+	default:
+		s.metrics.LoginAttempt(LoginResultError)
+	}
+	return token, err
+}
+
+// login выполняет проверку учетных данных и выпуск токена для Login.
+func (s *authService) login(ctx context.Context, username, password string, device models.Device) (string, error) {
+	ctx, cancel := withTimeout(ctx, s.timeouts.DB)
+	defer cancel()
+
+	user, err := s.checkCredentials(ctx, username, password)
+	if err != nil {
+		return "", err
+	}
+
+	// Генерируем JWT токен
+	token, err := s.generateJWT(user)
+	if err != nil {
+		log.Printf("[AuthService] Ошибка генерации JWT для '%s': %v", username, err)
+		return "", errors.New("внутренняя ошибка сервера при генерации токена")
+	}
+
+	log.Printf("[AuthService] Пользователь '%s' успешно аутентифицирован", username)
+	s.trackDevice(ctx, user.ID, device)
+	return token, nil
+}
 
+// VerifyCredentials проверяет имя и пароль пользователя без выпуска токена и учета входа.
+func (s *authService) VerifyCredentials(ctx context.Context, username, password string) (*models.User, error) {
+	ctx, cancel := withTimeout(ctx, s.timeouts.DB)
+	defer cancel()
+	return s.checkCredentials(ctx, username, password)
+}
+
+// checkCredentials находит пользователя и сверяет пароль с хешем. Отключенная учетная запись
+// дает ErrAccountDisabled, но только после проверки пароля.
+func (s *authService) checkCredentials(ctx context.Context, username, password string) (*models.User, error) {
 	// Получаем пользователя по имени пользователя
 	user, err := s.userRepo.GetUserByUsername(ctx, username)
 	if err != nil {
 		if errors.Is(err, repository.ErrUserNotFound) {
 			log.Printf("[AuthService] Попытка входа несуществующего пользователя: %s", username)
-			return "", ErrInvalidCredentials // Общая ошибка для несуществующего пользователя и неверного пароля
+			return nil, ErrInvalidCredentials // Общая ошибка для несуществующего пользователя и неверного пароля
 		}
 		log.Printf("[AuthService] Ошибка репозитория при поиске '%s': %v", username, err)
-		return "", errors.New("внутренняя ошибка сервера при поиске пользователя")
+		return nil, timeoutOr(ctx, errors.New("внутренняя ошибка сервера при поиске пользователя"))
 	}
 
 	// Сравниваем предоставленный пароль с хешем из базы данных
@@ -95,30 +183,73 @@ func (s *authService) Login(username, password string) (string, error) {
 	if err != nil {
 		// Ошибка сравнения означает неверный пароль (или другую проблему bcrypt)
 		log.Printf("[AuthService] Неверный пароль для пользователя: %s", username)
-		return "", ErrInvalidCredentials // Общая ошибка
+		return nil, ErrInvalidCredentials // Общая ошибка
 	}
 
-	// Генерируем JWT токен
-	token, err := s.generateJWT(user.ID)
+	// Статус учетной записи сообщаем только после проверки пароля
+	if user.DisabledAt != nil {
+		log.Printf("[AuthService] Попытка входа в отключенную учетную запись: %s", username)
+		return nil, ErrAccountDisabled
+	}
+
+	return user, nil
+}
+
+// deviceFingerprint возвращает отпечаток устройства для учета входов.
+// Клиент GophKeeper передает постоянный идентификатор установки, который не меняется при смене сети
+// или обновлении клиента. Для сторонних клиентов отпечаток строится по User-Agent и IP-адресу:
+// одного User-Agent недостаточно, он совпадает у всех установок одной версии клиента.
+func deviceFingerprint(device models.Device) string {
+	source := "ua:" + device.UserAgent + "\x00" + device.IPAddress
+	if device.DeviceID != "" {
+		source = "id:" + device.DeviceID
+	}
+	sum := sha256.Sum256([]byte(source))
+	return hex.EncodeToString(sum[:])
+}
+
+// trackDevice запоминает устройство пользователя и публикует событие, если вход выполнен с нового устройства.
+// Ошибки не влияют на результат входа.
+func (s *authService) trackDevice(ctx context.Context, userID int64, device models.Device) {
+	isNew, err := s.userRepo.TouchDevice(ctx, userID, deviceFingerprint(device), device.UserAgent, device.IPAddress)
 	if err != nil {
-		log.Printf("[AuthService] Ошибка генерации JWT для '%s': %v", username, err)
-		return "", errors.New("внутренняя ошибка сервера при генерации токена")
+		log.Printf("[AuthService] Ошибка сохранения устройства пользователя %d: %v", userID, err)
+		return
+	}
+	if !isNew || s.publisher == nil {
+		return
 	}
 
-	log.Printf("[AuthService] Пользователь '%s' успешно аутентифицирован", username)
-	return token, nil
+	// Вход уже состоялся, поэтому событие публикуется и при отключении клиента
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), loginEventPublishTimeout)
+	defer cancel()
+	event := models.VaultEvent{
+		Type:      models.VaultEventLoginNewDevice,
+		UserID:    userID,
+		CreatedAt: time.Now().UTC(),
+		Device:    &device,
+	}
+	if err = s.publisher.Publish(ctx, event); err != nil {
+		log.Printf("[AuthService] Ошибка публикации события о входе пользователя %d с нового устройства: %v", userID, err)
+	}
 }
 
 // generateJWT создает и подписывает JWT токен для пользователя.
-func (s *authService) generateJWT(userID int64) (string, error) {
+// Роль записывается в токен, поэтому ее изменение вступает в силу при следующем входе.
+func (s *authService) generateJWT(user *models.User) (string, error) {
+	role := user.Role
+	if role == "" {
+		role = models.RoleUser
+	}
 	// Создаем claims (полезную нагрузку)
 	claims := jwtClaims{
-		UserID: userID,
+		UserID: user.ID,
+		Role:   role,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(tokenTTL)), // Время истечения
-			IssuedAt:  jwt.NewNumericDate(time.Now()),               // Время выдачи
-			NotBefore: jwt.NewNumericDate(time.Now()),               // Время, с которого токен валиден
-			Issuer:    "gophkeeper-server",                          // Источник токена
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.tokens.TTL)), // Время истечения
+			IssuedAt:  jwt.NewNumericDate(time.Now()),                   // Время выдачи
+			NotBefore: jwt.NewNumericDate(time.Now()),                   // Время, с которого токен валиден
+			Issuer:    "gophkeeper-server",                              // Источник токена
 		},
 	}
 
@@ -126,7 +257,7 @@ func (s *authService) generateJWT(userID int64) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
 	// Подписываем токен секретным ключом
-	signedToken, err := token.SignedString([]byte(jwtSecretKey))
+	signedToken, err := token.SignedString([]byte(s.tokens.Secret))
 	if err != nil {
 		return "", fmt.Errorf("ошибка подписи JWT: %w", err)
 	}
@@ -138,4 +269,5 @@ func (s *authService) generateJWT(userID int64) (string, error) {
 var (
 	ErrInvalidCredentials = errors.New("неверное имя пользователя или пароль")
 	ErrUsernameTaken      = errors.New("имя пользователя уже занято")
+	ErrAccountDisabled    = errors.New("учетная запись отключена администратором")
 )
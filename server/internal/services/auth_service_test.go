@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/maynagashev/gophkeeper/models"
 	"github.com/maynagashev/gophkeeper/server/internal/mocks"
 	"github.com/maynagashev/gophkeeper/server/internal/repository"
@@ -18,13 +20,12 @@ import (
 func TestNewAuthService(t *testing.T) {
 	mockUserRepo := new(mocks.UserRepository)
 
-	authService := services.NewAuthService(mockUserRepo)
+	authService := services.NewAuthService(mockUserRepo, nil, services.TokenConfig{}, services.Timeouts{}, nil)
 
 	require.NotNil(t, authService)
 }
 
 func TestAuthService_Register(t *testing.T) {
-	ctx := context.Background()
 	username := "testuser"
 	password := "password123"
 
@@ -37,7 +38,7 @@ func TestAuthService_Register(t *testing.T) {
 			name: "Успешная регистрация",
 			mockSetup: func(mockUserRepo *mocks.UserRepository) {
 				mockUserRepo.EXPECT().
-					CreateUser(ctx, mock.AnythingOfType("*models.User")).
+					CreateUser(mock.Anything, mock.AnythingOfType("*models.User")).
 					Return(int64(1), nil).Once()
 			},
 			expectedError: nil,
@@ -46,7 +47,7 @@ func TestAuthService_Register(t *testing.T) {
 			name: "Имя пользователя занято",
 			mockSetup: func(mockUserRepo *mocks.UserRepository) {
 				mockUserRepo.EXPECT().
-					CreateUser(ctx, mock.AnythingOfType("*models.User")).
+					CreateUser(mock.Anything, mock.AnythingOfType("*models.User")).
 					Return(int64(0), repository.ErrUsernameTaken).Once()
 			},
 			expectedError: services.ErrUsernameTaken,
@@ -55,7 +56,7 @@ func TestAuthService_Register(t *testing.T) {
 			name: "Ошибка репозитория при создании",
 			mockSetup: func(mockUserRepo *mocks.UserRepository) {
 				mockUserRepo.EXPECT().
-					CreateUser(ctx, mock.AnythingOfType("*models.User")).
+					CreateUser(mock.Anything, mock.AnythingOfType("*models.User")).
 					Return(int64(0), errors.New("some db error")).Once()
 			},
 			expectedError: errors.New("внутренняя ошибка сервера при создании пользователя"),
@@ -67,8 +68,8 @@ func TestAuthService_Register(t *testing.T) {
 			mockUserRepo := new(mocks.UserRepository)
 			tt.mockSetup(mockUserRepo)
 
-			authService := services.NewAuthService(mockUserRepo)
-			err := authService.Register(username, password)
+			authService := services.NewAuthService(mockUserRepo, nil, services.TokenConfig{}, services.Timeouts{}, nil)
+			err := authService.Register(context.Background(), username, password)
 
 			if tt.expectedError != nil {
 				require.Error(t, err)
@@ -83,11 +84,11 @@ func TestAuthService_Register(t *testing.T) {
 }
 
 func TestAuthService_Login(t *testing.T) {
-	ctx := context.Background()
 	username := "testuser"
 	password := "password123"
 	wrongPassword := "wrongpassword"
 	userID := int64(1)
+	device := models.Device{UserAgent: "gophkeeper-test", IPAddress: "192.0.2.1"}
 	hashedPasswordBytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	require.NoError(t, err, "Не удалось сгенерировать хеш пароля для тестов")
 	hashedPassword := string(hashedPasswordBytes)
@@ -110,8 +111,11 @@ func TestAuthService_Login(t *testing.T) {
 			passwordToUse: password,
 			mockSetup: func(mockUserRepo *mocks.UserRepository) {
 				mockUserRepo.EXPECT().
-					GetUserByUsername(ctx, username).
+					GetUserByUsername(mock.Anything, username).
 					Return(correctUser, nil).Once()
+				mockUserRepo.EXPECT().
+					TouchDevice(mock.Anything, userID, mock.Anything, device.UserAgent, device.IPAddress).
+					Return(false, nil).Once()
 			},
 			expectedToken: true,
 			expectedError: nil,
@@ -121,7 +125,7 @@ func TestAuthService_Login(t *testing.T) {
 			passwordToUse: password,
 			mockSetup: func(mockUserRepo *mocks.UserRepository) {
 				mockUserRepo.EXPECT().
-					GetUserByUsername(ctx, username).
+					GetUserByUsername(mock.Anything, username).
 					Return(nil, repository.ErrUserNotFound).Once()
 			},
 			expectedToken: false,
@@ -132,7 +136,7 @@ func TestAuthService_Login(t *testing.T) {
 			passwordToUse: wrongPassword,
 			mockSetup: func(mockUserRepo *mocks.UserRepository) {
 				mockUserRepo.EXPECT().
-					GetUserByUsername(ctx, username).
+					GetUserByUsername(mock.Anything, username).
 					Return(correctUser, nil).Once()
 			},
 			expectedToken: false,
@@ -143,7 +147,7 @@ func TestAuthService_Login(t *testing.T) {
 			passwordToUse: password,
 			mockSetup: func(mockUserRepo *mocks.UserRepository) {
 				mockUserRepo.EXPECT().
-					GetUserByUsername(ctx, username).
+					GetUserByUsername(mock.Anything, username).
 					Return(nil, errors.New("some db error")).Once()
 			},
 			expectedToken: false,
@@ -156,8 +160,8 @@ func TestAuthService_Login(t *testing.T) {
 			mockUserRepo := new(mocks.UserRepository)
 			tt.mockSetup(mockUserRepo)
 
-			authService := services.NewAuthService(mockUserRepo)
-			token, loginErr := authService.Login(username, tt.passwordToUse)
+			authService := services.NewAuthService(mockUserRepo, nil, services.TokenConfig{}, services.Timeouts{}, nil)
+			token, loginErr := authService.Login(context.Background(), username, tt.passwordToUse, device)
 
 			if tt.expectedError != nil {
 				require.Error(t, loginErr)
@@ -172,3 +176,222 @@ func TestAuthService_Login(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthService_LoginFromNewDevice(t *testing.T) {
+	username := "testuser"
+	password := "password123"
+	userID := int64(1)
+	device := models.Device{UserAgent: "gophkeeper-test", IPAddress: "192.0.2.1"}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	require.NoError(t, err)
+	user := &models.User{ID: userID, Username: username, PasswordHash: string(hashedPassword)}
+
+	t.Run("Вход с нового устройства публикует событие", func(t *testing.T) {
+		mockUserRepo := mocks.NewUserRepository(t)
+		mockPublisher := mocks.NewPublisher(t)
+		mockUserRepo.EXPECT().GetUserByUsername(mock.Anything, username).Return(user, nil).Once()
+		mockUserRepo.EXPECT().TouchDevice(mock.Anything, userID, mock.Anything, device.UserAgent, device.IPAddress).
+			Return(true, nil).Once()
+		mockPublisher.EXPECT().Publish(mock.Anything, mock.MatchedBy(func(e models.VaultEvent) bool {
+			return e.Type == models.VaultEventLoginNewDevice && e.UserID == userID &&
+				e.Device != nil && *e.Device == device
+		})).Return(nil).Once()
+
+		authService := services.NewAuthService(mockUserRepo, mockPublisher, services.TokenConfig{}, services.Timeouts{}, nil)
+		token, loginErr := authService.Login(context.Background(), username, password, device)
+
+		require.NoError(t, loginErr)
+		assert.NotEmpty(t, token)
+	})
+
+	t.Run("Ошибки учета устройства не мешают входу", func(t *testing.T) {
+		mockUserRepo := mocks.NewUserRepository(t)
+		mockPublisher := mocks.NewPublisher(t)
+		mockUserRepo.EXPECT().GetUserByUsername(mock.Anything, username).Return(user, nil).Once()
+		mockUserRepo.EXPECT().TouchDevice(mock.Anything, userID, mock.Anything, device.UserAgent, device.IPAddress).
+			Return(false, errors.New("db error")).Once()
+
+		authService := services.NewAuthService(mockUserRepo, mockPublisher, services.TokenConfig{}, services.Timeouts{}, nil)
+		token, loginErr := authService.Login(context.Background(), username, password, device)
+
+		require.NoError(t, loginErr)
+		assert.NotEmpty(t, token)
+	})
+}
+
+func TestAuthService_DeviceFingerprint(t *testing.T) {
+	password := "password123"
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	require.NoError(t, err)
+	user := &models.User{ID: 1, Username: "testuser", PasswordHash: string(hashedPassword)}
+
+	// fingerprint выполняет вход с устройства device и возвращает отпечаток, переданный в TouchDevice.
+	fingerprint := func(t *testing.T, device models.Device) string {
+		t.Helper()
+		mockUserRepo := mocks.NewUserRepository(t)
+		mockUserRepo.EXPECT().GetUserByUsername(mock.Anything, user.Username).Return(user, nil).Once()
+		var got string
+		mockUserRepo.EXPECT().TouchDevice(mock.Anything, user.ID, mock.Anything, device.UserAgent, device.IPAddress).
+			RunAndReturn(func(_ context.Context, _ int64, fp, _, _ string) (bool, error) {
+				got = fp
+				return false, nil
+			}).Once()
+		authService := services.NewAuthService(mockUserRepo, nil, services.TokenConfig{}, services.Timeouts{}, nil)
+		_, loginErr := authService.Login(context.Background(), user.Username, password, device)
+		require.NoError(t, loginErr)
+		require.NotEmpty(t, got)
+		return got
+	}
+
+	t.Run("Идентификатор установки не зависит от сети и версии клиента", func(t *testing.T) {
+		home := fingerprint(t, models.Device{DeviceID: "device-a", UserAgent: "gophkeeper/1.0", IPAddress: "192.0.2.1"})
+		office := fingerprint(t, models.Device{DeviceID: "device-a", UserAgent: "gophkeeper/1.1", IPAddress: "192.0.2.2"})
+		assert.Equal(t, home, office)
+	})
+
+	t.Run("Установки одной версии клиента различаются", func(t *testing.T) {
+		first := fingerprint(t, models.Device{DeviceID: "device-a", UserAgent: "gophkeeper/1.0"})
+		second := fingerprint(t, models.Device{DeviceID: "device-b", UserAgent: "gophkeeper/1.0"})
+		assert.NotEqual(t, first, second)
+	})
+
+	t.Run("Без идентификатора учитывается IP-адрес", func(t *testing.T) {
+		first := fingerprint(t, models.Device{UserAgent: "KeePassDX", IPAddress: "192.0.2.1"})
+		second := fingerprint(t, models.Device{UserAgent: "KeePassDX", IPAddress: "198.51.100.7"})
+		assert.NotEqual(t, first, second)
+	})
+}
+
+func TestAuthService_LoginDisabledAccount(t *testing.T) {
+	username := "disabled"
+	password := "password123"
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	require.NoError(t, err)
+	disabledAt := time.Now()
+	user := &models.User{ID: 3, Username: username, PasswordHash: string(hashedPassword), DisabledAt: &disabledAt}
+
+	t.Run("Верный пароль: сообщаем об отключении", func(t *testing.T) {
+		mockUserRepo := mocks.NewUserRepository(t)
+		mockUserRepo.EXPECT().GetUserByUsername(mock.Anything, username).Return(user, nil).Once()
+
+		authService := services.NewAuthService(mockUserRepo, nil, services.TokenConfig{}, services.Timeouts{}, nil)
+		token, loginErr := authService.Login(context.Background(), username, password, models.Device{})
+
+		require.ErrorIs(t, loginErr, services.ErrAccountDisabled)
+		assert.Empty(t, token)
+	})
+
+	t.Run("Неверный пароль: статус учетной записи не раскрывается", func(t *testing.T) {
+		mockUserRepo := mocks.NewUserRepository(t)
+		mockUserRepo.EXPECT().GetUserByUsername(mock.Anything, username).Return(user, nil).Once()
+
+		authService := services.NewAuthService(mockUserRepo, nil, services.TokenConfig{}, services.Timeouts{}, nil)
+		_, loginErr := authService.Login(context.Background(), username, "wrong", models.Device{})
+
+		require.ErrorIs(t, loginErr, services.ErrInvalidCredentials)
+	})
+}
+
+func TestAuthService_VerifyCredentials(t *testing.T) {
+	username := "webdav"
+	password := "password123"
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	require.NoError(t, err)
+	user := &models.User{ID: 4, Username: username, PasswordHash: string(hashedPassword), Role: models.RoleUser}
+	disabledAt := time.Now()
+	disabled := &models.User{ID: 5, Username: "off", PasswordHash: string(hashedPassword), DisabledAt: &disabledAt}
+
+	t.Run("Верный пароль: без токена, метрик и учета устройства", func(t *testing.T) {
+		// Моки без ожиданий TouchDevice и LoginAttempt: любой такой вызов провалит тест
+		mockUserRepo := mocks.NewUserRepository(t)
+		mockUserRepo.EXPECT().GetUserByUsername(mock.Anything, username).Return(user, nil).Once()
+		mockMetrics := mocks.NewAuthMetrics(t)
+		mockPublisher := mocks.NewPublisher(t)
+
+		authService := services.NewAuthService(mockUserRepo, mockPublisher, services.TokenConfig{},
+			services.Timeouts{}, mockMetrics)
+		verified, verifyErr := authService.VerifyCredentials(context.Background(), username, password)
+
+		require.NoError(t, verifyErr)
+		assert.Equal(t, int64(4), verified.ID)
+	})
+
+	t.Run("Ошибки", func(t *testing.T) {
+		mockUserRepo := mocks.NewUserRepository(t)
+		mockUserRepo.EXPECT().GetUserByUsername(mock.Anything, username).Return(user, nil).Once()
+		mockUserRepo.EXPECT().GetUserByUsername(mock.Anything, "ghost").Return(nil, repository.ErrUserNotFound).Once()
+		mockUserRepo.EXPECT().GetUserByUsername(mock.Anything, "off").Return(disabled, nil).Once()
+		authService := services.NewAuthService(mockUserRepo, nil, services.TokenConfig{}, services.Timeouts{}, nil)
+
+		_, verifyErr := authService.VerifyCredentials(context.Background(), username, "wrong")
+		require.ErrorIs(t, verifyErr, services.ErrInvalidCredentials)
+		_, verifyErr = authService.VerifyCredentials(context.Background(), "ghost", password)
+		require.ErrorIs(t, verifyErr, services.ErrInvalidCredentials)
+		_, verifyErr = authService.VerifyCredentials(context.Background(), "off", password)
+		require.ErrorIs(t, verifyErr, services.ErrAccountDisabled)
+	})
+}
+
+func TestAuthService_TokenConfig(t *testing.T) {
+	username := "testuser"
+	password := "password123"
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	require.NoError(t, err)
+	user := &models.User{ID: 7, Username: username, PasswordHash: string(hashedPassword)}
+
+	mockUserRepo := mocks.NewUserRepository(t)
+	mockUserRepo.EXPECT().GetUserByUsername(mock.Anything, username).Return(user, nil).Once()
+	mockUserRepo.EXPECT().TouchDevice(mock.Anything, user.ID, mock.Anything, "", "").Return(false, nil).Once()
+
+	tokens := services.TokenConfig{Secret: "configured-secret", TTL: 15 * time.Minute}
+	authService := services.NewAuthService(mockUserRepo, nil, tokens, services.Timeouts{}, nil)
+	signed, err := authService.Login(context.Background(), username, password, models.Device{})
+	require.NoError(t, err)
+
+	claims := &jwt.RegisteredClaims{}
+	_, err = jwt.ParseWithClaims(signed, claims, func(_ *jwt.Token) (any, error) {
+		return []byte(tokens.Secret), nil
+	})
+	require.NoError(t, err, "токен должен быть подписан ключом из конфигурации")
+	assert.WithinDuration(t, time.Now().Add(tokens.TTL), claims.ExpiresAt.Time, 5*time.Second)
+
+	_, err = jwt.ParseWithClaims(signed, &jwt.RegisteredClaims{}, func(_ *jwt.Token) (any, error) {
+		return []byte(services.DefaultJWTSecret), nil
+	})
+	require.Error(t, err, "ключ по умолчанию не должен подходить")
+}
+
+func TestAuthService_TokenRoleClaim(t *testing.T) {
+	password := "password123"
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		role         string
+		expectedRole string
+	}{
+		{name: "Администратор", role: models.RoleAdmin, expectedRole: models.RoleAdmin},
+		{name: "Обычный пользователь", role: models.RoleUser, expectedRole: models.RoleUser},
+		{name: "Роль не задана", role: "", expectedRole: models.RoleUser},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := &models.User{ID: 7, Username: "testuser", PasswordHash: string(hashedPassword), Role: tt.role}
+			mockUserRepo := mocks.NewUserRepository(t)
+			mockUserRepo.EXPECT().GetUserByUsername(mock.Anything, user.Username).Return(user, nil).Once()
+			mockUserRepo.EXPECT().TouchDevice(mock.Anything, user.ID, mock.Anything, "", "").Return(false, nil).Once()
+
+			authService := services.NewAuthService(mockUserRepo, nil, services.TokenConfig{}, services.Timeouts{}, nil)
+			signed, loginErr := authService.Login(context.Background(), user.Username, password, models.Device{})
+			require.NoError(t, loginErr)
+
+			claims := jwt.MapClaims{}
+			_, err = jwt.ParseWithClaims(signed, claims, func(_ *jwt.Token) (any, error) {
+				return []byte(services.DefaultJWTSecret), nil
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedRole, claims["role"])
+		})
+	}
+}
@@ -0,0 +1,273 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/maynagashev/gophkeeper/models"
+	"github.com/maynagashev/gophkeeper/server/internal/events"
+	"github.com/maynagashev/gophkeeper/server/internal/repository"
+)
+
+const (
+	// MaxEmergencyWaitPeriodHours - максимальный период ожидания экстренного доступа (один год).
+	MaxEmergencyWaitPeriodHours = 365 * 24
+	// MaxKeyEnvelopeSize - максимальный размер ключа, оставляемого для доверенного контакта.
+	MaxKeyEnvelopeSize = 64 << 10
+	// emergencyEventPublishTimeout - таймаут публикации событий экстренного доступа.
+	emergencyEventPublishTimeout = 5 * time.Second
+)
+
+// EmergencyAccessService определяет интерфейс экстренного доступа доверенных контактов к хранилищу.
+//
+// Владелец назначает доверенного контакта с периодом ожидания и может оставить для него ключ к хранилищу,
+// зашифрованный на стороне клиента. Контакт запрашивает доступ, владелец получает событие
+// emergency_access_requested и может отклонить запрос. Если за период ожидания запрос не отклонен,
+// контакт получает доступ на чтение хранилища владельца и оставленный ключ.
+type EmergencyAccessService interface {
+	// SetContact назначает пользователя contactUsername доверенным контактом владельца
+	// или меняет период ожидания уже назначенного контакта.
+	SetContact(ctx context.Context, ownerID int64, contactUsername string, waitPeriodHours int) (
+		*models.EmergencyAccess, error)
+	ListContacts(ctx context.Context, ownerID int64) ([]models.EmergencyAccess, error)
+	RemoveContact(ctx context.Context, ownerID, contactID int64) error
+	SetKeyEnvelope(ctx context.Context, ownerID, contactID int64, envelope []byte) error
+	// DenyAccess отклоняет запрос контакта, в том числе после истечения периода ожидания (доступ закрывается).
+	DenyAccess(ctx context.Context, ownerID, contactID int64) (*models.EmergencyAccess, error)
+
+	// ListGrants возвращает владельцев, назначивших пользователя доверенным контактом.
+	ListGrants(ctx context.Context, contactID int64) ([]models.EmergencyAccess, error)
+	// RequestAccess запрашивает доступ к хранилищу владельца. Повторный запрос не сбрасывает период ожидания.
+	RequestAccess(ctx context.Context, contactID, ownerID int64) (*models.EmergencyAccess, error)
+	// GetKeyEnvelope возвращает ключ, оставленный владельцем, после открытия доступа.
+	GetKeyEnvelope(ctx context.Context, contactID, ownerID int64) ([]byte, error)
+	// CheckAccess возвращает nil, если контакт может читать хранилище владельца.
+	CheckAccess(ctx context.Context, contactID, ownerID int64) error
+}
+
+var _ EmergencyAccessService = (*emergencyAccessService)(nil)
+
+// emergencyAccessService реализует EmergencyAccessService.
+type emergencyAccessService struct {
+	repo      repository.EmergencyAccessRepository
+	userRepo  repository.UserRepository
+	publisher events.Publisher // Получатель событий экстренного доступа (может быть nil)
+	now       func() time.Time
+}
+
+// NewEmergencyAccessService создает сервис экстренного доступа.
+// publisher может быть nil, тогда владелец и контакт не уведомляются о запросах.
+func NewEmergencyAccessService(
+	repo repository.EmergencyAccessRepository,
+	userRepo repository.UserRepository,
+	publisher events.Publisher,
+) EmergencyAccessService {
+	return &emergencyAccessService{repo: repo, userRepo: userRepo, publisher: publisher, now: time.Now}
+}
+
+// SetContact назначает доверенного контакта.
+func (s *emergencyAccessService) SetContact(
+	ctx context.Context,
+	ownerID int64,
+	contactUsername string,
+	waitPeriodHours int,
+) (*models.EmergencyAccess, error) {
+	if waitPeriodHours < 1 || waitPeriodHours > MaxEmergencyWaitPeriodHours {
+		return nil, ErrInvalidWaitPeriod
+	}
+	contact, err := s.userRepo.GetUserByUsername(ctx, contactUsername)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		log.Printf("[EmergencyAccessService] Ошибка поиска пользователя '%s': %v", contactUsername, err)
+		return nil, errors.New("внутренняя ошибка сервера")
+	}
+	if contact.ID == ownerID {
+		return nil, ErrEmergencyContactSelf
+	}
+
+	if err = s.repo.UpsertContact(ctx, ownerID, contact.ID, waitPeriodHours); err != nil {
+		log.Printf("[EmergencyAccessService] Ошибка назначения контакта %d пользователю %d: %v", contact.ID, ownerID, err)
+		return nil, errors.New("внутренняя ошибка сервера при назначении контакта")
+	}
+	log.Printf("[EmergencyAccessService] Пользователь %d назначил доверенным контактом пользователя %d (%d ч)",
+		ownerID, contact.ID, waitPeriodHours)
+	return s.getAccess(ctx, ownerID, contact.ID)
+}
+
+// ListContacts возвращает доверенных контактов владельца.
+func (s *emergencyAccessService) ListContacts(ctx context.Context, ownerID int64) ([]models.EmergencyAccess, error) {
+	accesses, err := s.repo.ListByOwner(ctx, ownerID)
+	if err != nil {
+		log.Printf("[EmergencyAccessService] Ошибка получения контактов пользователя %d: %v", ownerID, err)
+		return nil, errors.New("внутренняя ошибка сервера")
+	}
+	return s.resolveAll(accesses), nil
+}
+
+// RemoveContact отменяет назначение доверенного контакта.
+func (s *emergencyAccessService) RemoveContact(ctx context.Context, ownerID, contactID int64) error {
+	if err := s.repo.DeleteContact(ctx, ownerID, contactID); err != nil {
+		return s.repoError(err, "удаления контакта", ownerID, contactID)
+	}
+	log.Printf("[EmergencyAccessService] Пользователь %d удалил доверенного контакта %d", ownerID, contactID)
+	return nil
+}
+
+// SetKeyEnvelope сохраняет ключ для контакта. Содержимое ключа сервер не проверяет: оно зашифровано клиентом.
+func (s *emergencyAccessService) SetKeyEnvelope(ctx context.Context, ownerID, contactID int64, envelope []byte) error {
+	if len(envelope) == 0 || len(envelope) > MaxKeyEnvelopeSize {
+		return ErrInvalidKeyEnvelope
+	}
+	if err := s.repo.SetKeyEnvelope(ctx, ownerID, contactID, envelope); err != nil {
+		return s.repoError(err, "сохранения ключа", ownerID, contactID)
+	}
+	log.Printf("[EmergencyAccessService] Пользователь %d оставил ключ для контакта %d", ownerID, contactID)
+	return nil
+}
+
+// DenyAccess отклоняет запрос контакта и уведомляет его событием emergency_access_denied.
+func (s *emergencyAccessService) DenyAccess(
+	ctx context.Context,
+	ownerID, contactID int64,
+) (*models.EmergencyAccess, error) {
+	denied, err := s.repo.DenyAccess(ctx, ownerID, contactID)
+	if err != nil {
+		return nil, s.repoError(err, "отклонения доступа", ownerID, contactID)
+	}
+	access, err := s.getAccess(ctx, ownerID, contactID)
+	if err != nil {
+		return nil, err
+	}
+	if !denied {
+		return nil, ErrNoAccessRequest
+	}
+
+	log.Printf("[EmergencyAccessService] Пользователь %d отклонил запрос доступа контакта %d", ownerID, contactID)
+	s.publish(ctx, contactID, models.VaultEventEmergencyAccessDenied, access)
+	return access, nil
+}
+
+// ListGrants возвращает доступы пользователя к хранилищам других владельцев.
+func (s *emergencyAccessService) ListGrants(ctx context.Context, contactID int64) ([]models.EmergencyAccess, error) {
+	accesses, err := s.repo.ListByContact(ctx, contactID)
+	if err != nil {
+		log.Printf("[EmergencyAccessService] Ошибка получения доступов контакта %d: %v", contactID, err)
+		return nil, errors.New("внутренняя ошибка сервера")
+	}
+	return s.resolveAll(accesses), nil
+}
+
+// RequestAccess запрашивает доступ и уведомляет владельца событием emergency_access_requested.
+func (s *emergencyAccessService) RequestAccess(
+	ctx context.Context,
+	contactID, ownerID int64,
+) (*models.EmergencyAccess, error) {
+	requested, err := s.repo.RequestAccess(ctx, ownerID, contactID, s.now().UTC())
+	if err != nil {
+		return nil, s.repoError(err, "запроса доступа", ownerID, contactID)
+	}
+	access, err := s.getAccess(ctx, ownerID, contactID)
+	if err != nil {
+		return nil, err
+	}
+	if requested {
+		log.Printf("[EmergencyAccessService] Контакт %d запросил доступ к хранилищу пользователя %d", contactID, ownerID)
+		s.publish(ctx, ownerID, models.VaultEventEmergencyAccessRequested, access)
+	}
+	return access, nil
+}
+
+// GetKeyEnvelope возвращает ключ для контакта после открытия доступа.
+func (s *emergencyAccessService) GetKeyEnvelope(ctx context.Context, contactID, ownerID int64) ([]byte, error) {
+	if err := s.CheckAccess(ctx, contactID, ownerID); err != nil {
+		return nil, err
+	}
+	envelope, err := s.repo.GetKeyEnvelope(ctx, ownerID, contactID)
+	if err != nil {
+		return nil, s.repoError(err, "получения ключа", ownerID, contactID)
+	}
+	if envelope == nil {
+		return nil, ErrKeyEnvelopeNotFound
+	}
+	return envelope, nil
+}
+
+// CheckAccess проверяет, что контакт запросил доступ и период ожидания истек.
+func (s *emergencyAccessService) CheckAccess(ctx context.Context, contactID, ownerID int64) error {
+	access, err := s.getAccess(ctx, ownerID, contactID)
+	if err != nil {
+		return err
+	}
+	if access.Status != models.EmergencyAccessGranted {
+		return ErrEmergencyAccessNotGranted
+	}
+	return nil
+}
+
+// getAccess возвращает доступ с вычисленным статусом.
+func (s *emergencyAccessService) getAccess(ctx context.Context, ownerID, contactID int64) (
+	*models.EmergencyAccess, error) {
+	access, err := s.repo.GetAccess(ctx, ownerID, contactID)
+	if err != nil {
+		return nil, s.repoError(err, "получения доступа", ownerID, contactID)
+	}
+	access.Resolve(s.now())
+	return access, nil
+}
+
+// resolveAll вычисляет статусы доступов на текущий момент.
+func (s *emergencyAccessService) resolveAll(accesses []models.EmergencyAccess) []models.EmergencyAccess {
+	now := s.now()
+	for i := range accesses {
+		accesses[i].Resolve(now)
+	}
+	return accesses
+}
+
+// repoError преобразует ошибку репозитория в ошибку сервиса.
+func (s *emergencyAccessService) repoError(err error, operation string, ownerID, contactID int64) error {
+	if errors.Is(err, repository.ErrEmergencyAccessNotFound) {
+		return ErrEmergencyAccessNotFound
+	}
+	log.Printf("[EmergencyAccessService] Ошибка %s (владелец %d, контакт %d): %v", operation, ownerID, contactID, err)
+	return errors.New("внутренняя ошибка сервера")
+}
+
+// publish отправляет событие экстренного доступа пользователю userID. Ошибки не влияют на результат операции.
+func (s *emergencyAccessService) publish(
+	ctx context.Context,
+	userID int64,
+	eventType string,
+	access *models.EmergencyAccess,
+) {
+	if s.publisher == nil {
+		return
+	}
+	// Изменение уже сохранено, поэтому событие публикуется и при отключении клиента
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), emergencyEventPublishTimeout)
+	defer cancel()
+	event := models.VaultEvent{
+		Type:            eventType,
+		UserID:          userID,
+		CreatedAt:       s.now().UTC(),
+		EmergencyAccess: access,
+	}
+	if err := s.publisher.Publish(ctx, event); err != nil {
+		log.Printf("[EmergencyAccessService] Ошибка публикации события %s пользователю %d: %v", eventType, userID, err)
+	}
+}
+
+// Ошибки сервиса экстренного доступа.
+var (
+	ErrEmergencyAccessNotFound   = errors.New("доверенный контакт не найден")
+	ErrEmergencyAccessNotGranted = errors.New("экстренный доступ не открыт: нет запроса или не истек период ожидания")
+	ErrEmergencyContactSelf      = errors.New("нельзя назначить доверенным контактом самого себя")
+	ErrInvalidWaitPeriod         = errors.New("период ожидания должен быть от 1 часа до 1 года")
+	ErrInvalidKeyEnvelope        = errors.New("ключ для контакта пуст или превышает 64 КиБ")
+	ErrKeyEnvelopeNotFound       = errors.New("владелец не оставил ключ для контакта")
+	ErrNoAccessRequest           = errors.New("контакт не запрашивал доступ")
+)
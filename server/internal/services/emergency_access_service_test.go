@@ -0,0 +1,226 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/maynagashev/gophkeeper/models"
+	"github.com/maynagashev/gophkeeper/server/internal/mocks"
+	"github.com/maynagashev/gophkeeper/server/internal/repository"
+	"github.com/maynagashev/gophkeeper/server/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// requestedAccess возвращает доступ контакта 2 к хранилищу владельца 1, запрошенный ago назад (ожидание 24 часа).
+func requestedAccess(ago time.Duration) *models.EmergencyAccess {
+	requestedAt := time.Now().Add(-ago)
+	return &models.EmergencyAccess{
+		ID: 3, OwnerID: 1, ContactID: 2, WaitPeriodHours: 24,
+		Status: models.EmergencyAccessRequested, RequestedAt: &requestedAt,
+	}
+}
+
+func TestEmergencyAccessService_SetContact(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Некорректный период ожидания", func(t *testing.T) {
+		service := services.NewEmergencyAccessService(mocks.NewEmergencyAccessRepository(t), mocks.NewUserRepository(t), nil)
+
+		for _, hours := range []int{0, -1, services.MaxEmergencyWaitPeriodHours + 1} {
+			_, err := service.SetContact(ctx, 1, "contact", hours)
+			require.ErrorIs(t, err, services.ErrInvalidWaitPeriod, hours)
+		}
+	})
+
+	t.Run("Пользователь не найден", func(t *testing.T) {
+		users := mocks.NewUserRepository(t)
+		users.EXPECT().GetUserByUsername(ctx, "ghost").Return(nil, repository.ErrUserNotFound).Once()
+		service := services.NewEmergencyAccessService(mocks.NewEmergencyAccessRepository(t), users, nil)
+
+		_, err := service.SetContact(ctx, 1, "ghost", 24)
+
+		require.ErrorIs(t, err, services.ErrUserNotFound)
+	})
+
+	t.Run("Нельзя назначить себя", func(t *testing.T) {
+		users := mocks.NewUserRepository(t)
+		users.EXPECT().GetUserByUsername(ctx, "owner").Return(&models.User{ID: 1}, nil).Once()
+		service := services.NewEmergencyAccessService(mocks.NewEmergencyAccessRepository(t), users, nil)
+
+		_, err := service.SetContact(ctx, 1, "owner", 24)
+
+		require.ErrorIs(t, err, services.ErrEmergencyContactSelf)
+	})
+
+	t.Run("Успешное назначение", func(t *testing.T) {
+		users := mocks.NewUserRepository(t)
+		users.EXPECT().GetUserByUsername(ctx, "contact").Return(&models.User{ID: 2}, nil).Once()
+		repo := mocks.NewEmergencyAccessRepository(t)
+		repo.EXPECT().UpsertContact(ctx, int64(1), int64(2), 24).Return(nil).Once()
+		repo.EXPECT().GetAccess(ctx, int64(1), int64(2)).
+			Return(&models.EmergencyAccess{OwnerID: 1, ContactID: 2, Status: models.EmergencyAccessIdle}, nil).Once()
+
+		access, err := services.NewEmergencyAccessService(repo, users, nil).SetContact(ctx, 1, "contact", 24)
+
+		require.NoError(t, err)
+		assert.Equal(t, models.EmergencyAccessIdle, access.Status)
+		assert.Nil(t, access.AvailableAt)
+	})
+}
+
+func TestEmergencyAccessService_RequestAccess(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Владелец получает событие о запросе", func(t *testing.T) {
+		repo := mocks.NewEmergencyAccessRepository(t)
+		repo.EXPECT().RequestAccess(ctx, int64(1), int64(2), mock.Anything).Return(true, nil).Once()
+		repo.EXPECT().GetAccess(ctx, int64(1), int64(2)).Return(requestedAccess(0), nil).Once()
+		publisher := mocks.NewPublisher(t)
+		publisher.EXPECT().Publish(mock.Anything, mock.MatchedBy(func(e models.VaultEvent) bool {
+			return e.Type == models.VaultEventEmergencyAccessRequested && e.UserID == 1 && e.EmergencyAccess != nil
+		})).Return(nil).Once()
+
+		access, err := services.NewEmergencyAccessService(repo, mocks.NewUserRepository(t), publisher).
+			RequestAccess(ctx, 2, 1)
+
+		require.NoError(t, err)
+		assert.Equal(t, models.EmergencyAccessRequested, access.Status)
+		require.NotNil(t, access.AvailableAt)
+		assert.WithinDuration(t, time.Now().Add(24*time.Hour), *access.AvailableAt, time.Minute)
+	})
+
+	t.Run("Повторный запрос не уведомляет владельца", func(t *testing.T) {
+		repo := mocks.NewEmergencyAccessRepository(t)
+		repo.EXPECT().RequestAccess(ctx, int64(1), int64(2), mock.Anything).Return(false, nil).Once()
+		repo.EXPECT().GetAccess(ctx, int64(1), int64(2)).Return(requestedAccess(time.Hour), nil).Once()
+
+		access, err := services.NewEmergencyAccessService(repo, mocks.NewUserRepository(t), mocks.NewPublisher(t)).
+			RequestAccess(ctx, 2, 1)
+
+		require.NoError(t, err)
+		assert.Equal(t, models.EmergencyAccessRequested, access.Status)
+	})
+
+	t.Run("Пользователь не назначил контакта", func(t *testing.T) {
+		repo := mocks.NewEmergencyAccessRepository(t)
+		repo.EXPECT().RequestAccess(ctx, int64(1), int64(2), mock.Anything).
+			Return(false, repository.ErrEmergencyAccessNotFound).Once()
+
+		_, err := services.NewEmergencyAccessService(repo, mocks.NewUserRepository(t), nil).RequestAccess(ctx, 2, 1)
+
+		require.ErrorIs(t, err, services.ErrEmergencyAccessNotFound)
+	})
+}
+
+func TestEmergencyAccessService_DenyAccess(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Контакт получает событие об отказе", func(t *testing.T) {
+		repo := mocks.NewEmergencyAccessRepository(t)
+		repo.EXPECT().DenyAccess(ctx, int64(1), int64(2)).Return(true, nil).Once()
+		repo.EXPECT().GetAccess(ctx, int64(1), int64(2)).
+			Return(&models.EmergencyAccess{OwnerID: 1, ContactID: 2, Status: models.EmergencyAccessDenied}, nil).Once()
+		publisher := mocks.NewPublisher(t)
+		publisher.EXPECT().Publish(mock.Anything, mock.MatchedBy(func(e models.VaultEvent) bool {
+			return e.Type == models.VaultEventEmergencyAccessDenied && e.UserID == 2
+		})).Return(errors.New("publish error")).Once()
+
+		access, err := services.NewEmergencyAccessService(repo, mocks.NewUserRepository(t), publisher).
+			DenyAccess(ctx, 1, 2)
+
+		require.NoError(t, err, "Ошибка публикации не влияет на результат")
+		assert.Equal(t, models.EmergencyAccessDenied, access.Status)
+	})
+
+	t.Run("Запроса нет", func(t *testing.T) {
+		repo := mocks.NewEmergencyAccessRepository(t)
+		repo.EXPECT().DenyAccess(ctx, int64(1), int64(2)).Return(false, nil).Once()
+		repo.EXPECT().GetAccess(ctx, int64(1), int64(2)).
+			Return(&models.EmergencyAccess{OwnerID: 1, ContactID: 2, Status: models.EmergencyAccessIdle}, nil).Once()
+
+		_, err := services.NewEmergencyAccessService(repo, mocks.NewUserRepository(t), nil).DenyAccess(ctx, 1, 2)
+
+		require.ErrorIs(t, err, services.ErrNoAccessRequest)
+	})
+}
+
+func TestEmergencyAccessService_CheckAccess(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		access  *models.EmergencyAccess
+		repoErr error
+		wantErr error
+	}{
+		{name: "Период ожидания истек", access: requestedAccess(25 * time.Hour)},
+		{name: "Период ожидания не истек", access: requestedAccess(time.Hour),
+			wantErr: services.ErrEmergencyAccessNotGranted},
+		{name: "Запрос отклонен", access: &models.EmergencyAccess{Status: models.EmergencyAccessDenied},
+			wantErr: services.ErrEmergencyAccessNotGranted},
+		{name: "Контакт не назначен", repoErr: repository.ErrEmergencyAccessNotFound,
+			wantErr: services.ErrEmergencyAccessNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewEmergencyAccessRepository(t)
+			repo.EXPECT().GetAccess(ctx, int64(1), int64(2)).Return(tt.access, tt.repoErr).Once()
+
+			err := services.NewEmergencyAccessService(repo, mocks.NewUserRepository(t), nil).CheckAccess(ctx, 2, 1)
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestEmergencyAccessService_KeyEnvelope(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Пустой или слишком большой ключ", func(t *testing.T) {
+		service := services.NewEmergencyAccessService(mocks.NewEmergencyAccessRepository(t), mocks.NewUserRepository(t), nil)
+
+		for _, envelope := range [][]byte{nil, make([]byte, services.MaxKeyEnvelopeSize+1)} {
+			require.ErrorIs(t, service.SetKeyEnvelope(ctx, 1, 2, envelope), services.ErrInvalidKeyEnvelope)
+		}
+	})
+
+	t.Run("Ключ отдается после открытия доступа", func(t *testing.T) {
+		repo := mocks.NewEmergencyAccessRepository(t)
+		repo.EXPECT().GetAccess(ctx, int64(1), int64(2)).Return(requestedAccess(25*time.Hour), nil).Once()
+		repo.EXPECT().GetKeyEnvelope(ctx, int64(1), int64(2)).Return([]byte("envelope"), nil).Once()
+
+		envelope, err := services.NewEmergencyAccessService(repo, mocks.NewUserRepository(t), nil).
+			GetKeyEnvelope(ctx, 2, 1)
+
+		require.NoError(t, err)
+		assert.Equal(t, []byte("envelope"), envelope)
+	})
+
+	t.Run("Ключ не отдается до открытия доступа", func(t *testing.T) {
+		repo := mocks.NewEmergencyAccessRepository(t)
+		repo.EXPECT().GetAccess(ctx, int64(1), int64(2)).Return(requestedAccess(time.Hour), nil).Once()
+
+		_, err := services.NewEmergencyAccessService(repo, mocks.NewUserRepository(t), nil).GetKeyEnvelope(ctx, 2, 1)
+
+		require.ErrorIs(t, err, services.ErrEmergencyAccessNotGranted)
+	})
+
+	t.Run("Владелец не оставил ключ", func(t *testing.T) {
+		repo := mocks.NewEmergencyAccessRepository(t)
+		repo.EXPECT().GetAccess(ctx, int64(1), int64(2)).Return(requestedAccess(25*time.Hour), nil).Once()
+		repo.EXPECT().GetKeyEnvelope(ctx, int64(1), int64(2)).Return(nil, nil).Once()
+
+		_, err := services.NewEmergencyAccessService(repo, mocks.NewUserRepository(t), nil).GetKeyEnvelope(ctx, 2, 1)
+
+		require.ErrorIs(t, err, services.ErrKeyEnvelopeNotFound)
+	})
+}
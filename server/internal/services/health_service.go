@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/maynagashev/gophkeeper/models"
+	"github.com/maynagashev/gophkeeper/server/internal/repository"
+	"github.com/maynagashev/gophkeeper/server/internal/storage"
+)
+
+const (
+	// HealthCheckDatabase, HealthCheckStorage и HealthCheckMigrations - имена проверок готовности.
+	HealthCheckDatabase   = "database"
+	HealthCheckStorage    = "storage"
+	HealthCheckMigrations = "migrations"
+
+	// defaultHealthCacheTTL - время, в течение которого повторные запросы получают сохраненный результат.
+	defaultHealthCacheTTL = 5 * time.Second
+	// healthCheckTimeout - предельное время одной проверки зависимости.
+	healthCheckTimeout = 3 * time.Second
+)
+
+// HealthService определяет интерфейс проверки готовности сервера обслуживать запросы.
+type HealthService interface {
+	// Ready проверяет БД, хранилище объектов и версию схемы БД.
+	// Результат кэшируется, чтобы частые опросы оркестратора не нагружали зависимости.
+	Ready(ctx context.Context) models.HealthReport
+}
+
+var _ HealthService = (*healthService)(nil)
+
+// healthService реализует HealthService.
+type healthService struct {
+	healthRepo  repository.HealthRepository
+	fileStorage storage.FileStorage
+	cacheTTL    time.Duration
+	now         func() time.Time
+
+	mu     sync.Mutex
+	cached *models.HealthReport
+}
+
+// NewHealthService создает сервис проверки готовности.
+// cacheTTL задает время жизни сохраненного результата (0 - значение по умолчанию).
+func NewHealthService(
+	healthRepo repository.HealthRepository,
+	fileStorage storage.FileStorage,
+	cacheTTL time.Duration,
+) HealthService {
+	if cacheTTL <= 0 {
+		cacheTTL = defaultHealthCacheTTL
+	}
+	return &healthService{
+		healthRepo:  healthRepo,
+		fileStorage: fileStorage,
+		cacheTTL:    cacheTTL,
+		now:         time.Now,
+	}
+}
+
+// Ready возвращает результат проверки зависимостей, при необходимости выполняя ее заново.
+// Мьютекс удерживается на время проверки: параллельные запросы дожидаются одного общего результата.
+func (s *healthService) Ready(ctx context.Context) models.HealthReport {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cached != nil && s.now().Sub(*s.cached.CheckedAt) < s.cacheTTL {
+		return *s.cached
+	}
+
+	report := s.check(ctx)
+	s.cached = &report
+	return report
+}
+
+// check выполняет все проверки параллельно. Проверки не зависят от отмены запроса (ограничены своим таймаутом),
+// чтобы отключившийся клиент не закэшировал ложный отказ для остальных.
+func (s *healthService) check(ctx context.Context) models.HealthReport {
+	ctx = context.WithoutCancel(ctx)
+	checks := map[string]func(ctx context.Context) error{
+		HealthCheckDatabase:   s.healthRepo.Ping,
+		HealthCheckStorage:    s.fileStorage.Health,
+		HealthCheckMigrations: s.checkSchemaVersion,
+	}
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		results = make(map[string]models.HealthCheck, len(checks))
+	)
+	for name, fn := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := runHealthCheck(ctx, fn)
+			mu.Lock()
+			results[name] = result
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	checkedAt := s.now()
+	report := models.HealthReport{Status: models.HealthStatusOK, Checks: results, CheckedAt: &checkedAt}
+	for _, result := range results {
+		if result.Status != models.HealthStatusOK {
+			report.Status = models.HealthStatusFail
+		}
+	}
+	return report
+}
+
+// checkSchemaVersion проверяет, что к БД применены все миграции, требуемые кодом, и ни одна не прервана.
+func (s *healthService) checkSchemaVersion(ctx context.Context) error {
+	version, dirty, err := s.healthRepo.GetSchemaVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("миграция %d применена не полностью (dirty)", version)
+	}
+	if version != repository.ExpectedSchemaVersion() {
+		return fmt.Errorf("версия схемы БД %d, ожидается %d", version, repository.ExpectedSchemaVersion())
+	}
+	return nil
+}
+
+// runHealthCheck выполняет одну проверку с таймаутом и замеряет ее длительность.
+func runHealthCheck(ctx context.Context, fn func(ctx context.Context) error) models.HealthCheck {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	started := time.Now()
+	err := fn(ctx)
+	result := models.HealthCheck{Status: models.HealthStatusOK, DurationMS: time.Since(started).Milliseconds()}
+	if err != nil {
+		result.Status = models.HealthStatusFail
+		result.Error = timeoutOr(ctx, err).Error()
+	}
+	return result
+}
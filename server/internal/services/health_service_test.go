@@ -0,0 +1,107 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/maynagashev/gophkeeper/models"
+	"github.com/maynagashev/gophkeeper/server/internal/mocks"
+	"github.com/maynagashev/gophkeeper/server/internal/repository"
+	"github.com/maynagashev/gophkeeper/server/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthService_Ready(t *testing.T) {
+	t.Run("Все зависимости доступны", func(t *testing.T) {
+		repo := mocks.NewHealthRepository(t)
+		fs := mocks.NewFileStorage(t)
+		repo.EXPECT().Ping(mock.Anything).Return(nil).Once()
+		repo.EXPECT().GetSchemaVersion(mock.Anything).Return(repository.ExpectedSchemaVersion(), false, nil).Once()
+		fs.EXPECT().Health(mock.Anything).Return(nil).Once()
+
+		report := services.NewHealthService(repo, fs, time.Minute).Ready(context.Background())
+
+		assert.Equal(t, models.HealthStatusOK, report.Status)
+		require.Len(t, report.Checks, 3)
+		for name, check := range report.Checks {
+			assert.Equal(t, models.HealthStatusOK, check.Status, name)
+			assert.Empty(t, check.Error, name)
+		}
+		assert.NotNil(t, report.CheckedAt)
+	})
+
+	t.Run("Хранилище недоступно", func(t *testing.T) {
+		repo := mocks.NewHealthRepository(t)
+		fs := mocks.NewFileStorage(t)
+		repo.EXPECT().Ping(mock.Anything).Return(nil)
+		repo.EXPECT().GetSchemaVersion(mock.Anything).Return(repository.ExpectedSchemaVersion(), false, nil)
+		fs.EXPECT().Health(mock.Anything).Return(errors.New("connection refused"))
+
+		report := services.NewHealthService(repo, fs, time.Minute).Ready(context.Background())
+
+		assert.Equal(t, models.HealthStatusFail, report.Status)
+		assert.Equal(t, models.HealthStatusOK, report.Checks[services.HealthCheckDatabase].Status)
+		assert.Equal(t, models.HealthStatusFail, report.Checks[services.HealthCheckStorage].Status)
+		assert.Equal(t, "connection refused", report.Checks[services.HealthCheckStorage].Error)
+	})
+
+	t.Run("Версия схемы не совпадает", func(t *testing.T) {
+		repo := mocks.NewHealthRepository(t)
+		fs := mocks.NewFileStorage(t)
+		repo.EXPECT().Ping(mock.Anything).Return(nil)
+		repo.EXPECT().GetSchemaVersion(mock.Anything).Return(repository.ExpectedSchemaVersion()-1, false, nil)
+		fs.EXPECT().Health(mock.Anything).Return(nil)
+
+		report := services.NewHealthService(repo, fs, time.Minute).Ready(context.Background())
+
+		assert.Equal(t, models.HealthStatusFail, report.Status)
+		assert.Contains(t, report.Checks[services.HealthCheckMigrations].Error, "ожидается")
+	})
+
+	t.Run("Миграция применена не полностью", func(t *testing.T) {
+		repo := mocks.NewHealthRepository(t)
+		fs := mocks.NewFileStorage(t)
+		repo.EXPECT().Ping(mock.Anything).Return(nil)
+		repo.EXPECT().GetSchemaVersion(mock.Anything).Return(repository.ExpectedSchemaVersion(), true, nil)
+		fs.EXPECT().Health(mock.Anything).Return(nil)
+
+		report := services.NewHealthService(repo, fs, time.Minute).Ready(context.Background())
+
+		assert.Equal(t, models.HealthStatusFail, report.Status)
+		assert.Contains(t, report.Checks[services.HealthCheckMigrations].Error, "dirty")
+	})
+
+	t.Run("Результат кэшируется", func(t *testing.T) {
+		repo := mocks.NewHealthRepository(t)
+		fs := mocks.NewFileStorage(t)
+		// Once: повторный вызов зависимостей провалит тест
+		repo.EXPECT().Ping(mock.Anything).Return(errors.New("db down")).Once()
+		repo.EXPECT().GetSchemaVersion(mock.Anything).Return(repository.ExpectedSchemaVersion(), false, nil).Once()
+		fs.EXPECT().Health(mock.Anything).Return(nil).Once()
+		svc := services.NewHealthService(repo, fs, time.Minute)
+
+		first := svc.Ready(context.Background())
+		second := svc.Ready(context.Background())
+
+		assert.Equal(t, models.HealthStatusFail, second.Status)
+		assert.Equal(t, first.CheckedAt, second.CheckedAt)
+	})
+
+	t.Run("Отмена запроса не прерывает проверку", func(t *testing.T) {
+		repo := mocks.NewHealthRepository(t)
+		fs := mocks.NewFileStorage(t)
+		repo.EXPECT().Ping(mock.Anything).RunAndReturn(func(ctx context.Context) error { return ctx.Err() })
+		repo.EXPECT().GetSchemaVersion(mock.Anything).Return(repository.ExpectedSchemaVersion(), false, nil)
+		fs.EXPECT().Health(mock.Anything).Return(nil)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		report := services.NewHealthService(repo, fs, time.Minute).Ready(ctx)
+
+		assert.Equal(t, models.HealthStatusOK, report.Status)
+	})
+}
@@ -0,0 +1,287 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/maynagashev/gophkeeper/models"
+	"github.com/maynagashev/gophkeeper/server/internal/repository"
+	"github.com/maynagashev/gophkeeper/server/internal/storage"
+)
+
+const (
+	// scrubBatchSize - количество версий, выбираемых из БД за один запрос при проверке.
+	scrubBatchSize = 100
+	// verificationStatusTimeout - таймаут сохранения статуса проверки вне контекста запроса.
+	verificationStatusTimeout = 5 * time.Second
+)
+
+// IntegrityService определяет интерфейс фоновой проверки целостности объектов хранилища.
+type IntegrityService interface {
+	// ScrubOnce перепроверяет контрольные суммы всех версий, не проверявшихся дольше интервала.
+	ScrubOnce(ctx context.Context) (*models.StorageScrubReport, error)
+	// Run периодически запускает ScrubOnce до отмены контекста.
+	Run(ctx context.Context)
+	// LastReport возвращает итоги последнего завершенного прохода (nil, если проходов не было).
+	LastReport() *models.StorageScrubReport
+	// ListProblems возвращает версии с поврежденными или отсутствующими объектами.
+	ListProblems(ctx context.Context, limit, offset int) ([]models.VaultVersion, error)
+	// VerifyVersions проверяет указанные версии независимо от времени последней проверки
+	// и возвращает итоги вместе с версиями, проверка которых завершилась неуспешно.
+	VerifyVersions(ctx context.Context, versions []models.VaultVersion) (*models.UserStorageReport, error)
+}
+
+var _ IntegrityService = (*integrityService)(nil)
+
+// integrityService реализует IntegrityService.
+type integrityService struct {
+	vaultVersionRepo repository.VaultVersionRepository
+	fileStorage      storage.FileStorage
+	interval         time.Duration
+	now              func() time.Time
+
+	mu         sync.RWMutex
+	lastReport *models.StorageScrubReport
+}
+
+// NewIntegrityService создает сервис проверки целостности.
+// interval задает, как часто перепроверяется каждая версия и как часто запускается проход в Run.
+func NewIntegrityService(
+	vaultVersionRepo repository.VaultVersionRepository,
+	fileStorage storage.FileStorage,
+	interval time.Duration,
+) IntegrityService {
+	return &integrityService{
+		vaultVersionRepo: vaultVersionRepo,
+		fileStorage:      fileStorage,
+		interval:         interval,
+		now:              time.Now,
+	}
+}
+
+// Run запускает проход проверки сразу и затем раз в interval.
+func (s *integrityService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.ScrubOnce(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("[IntegrityService] Ошибка прохода проверки целостности: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			log.Printf("[IntegrityService] Фоновая проверка целостности остановлена")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ScrubOnce перехеширует объекты всех версий, которые не проверялись с начала текущего интервала,
+// и сохраняет результат проверки для каждой версии.
+func (s *integrityService) ScrubOnce(ctx context.Context) (*models.StorageScrubReport, error) {
+	report := &models.StorageScrubReport{StartedAt: s.now()}
+	verifiedBefore := report.StartedAt.Add(-s.interval)
+	log.Printf("[IntegrityService] Начат проход проверки целостности (версии, не проверявшиеся с %v)", verifiedBefore)
+
+	for {
+		versions, err := s.vaultVersionRepo.ListVersionsForVerification(ctx, verifiedBefore, scrubBatchSize)
+		if err != nil {
+			return nil, errors.New("ошибка получения версий для проверки целостности")
+		}
+		if len(versions) == 0 {
+			break
+		}
+
+		for i := range versions {
+			// Без сохраненного статуса версия снова попадет в выборку, поэтому при ошибке прерываем проход
+			if err = s.checkVersion(ctx, &versions[i], report); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	report.FinishedAt = s.now()
+	s.mu.Lock()
+	s.lastReport = report
+	s.mu.Unlock()
+
+	log.Printf("[IntegrityService] Проход проверки завершен: проверено %d, ok %d, повреждено %d,"+
+		" отсутствует %d, ошибок %d", report.Checked, report.OK, report.Corrupted, report.Missing, report.Errors)
+	return report, nil
+}
+
+// VerifyVersions проверяет версии (например, все версии одного пользователя) и сохраняет результат
+// проверки для каждой из них. Итоги последнего прохода фоновой проверки не меняются.
+func (s *integrityService) VerifyVersions(
+	ctx context.Context,
+	versions []models.VaultVersion,
+) (*models.UserStorageReport, error) {
+	result := &models.UserStorageReport{
+		Report:   models.StorageScrubReport{StartedAt: s.now()},
+		Problems: []models.VaultVersion{},
+	}
+	for i := range versions {
+		version := versions[i]
+		if err := s.checkVersion(ctx, &version, &result.Report); err != nil {
+			return nil, err
+		}
+		if *version.VerificationStatus != models.VerificationStatusOK {
+			result.Problems = append(result.Problems, version)
+		}
+	}
+	result.Report.FinishedAt = s.now()
+	return result, nil
+}
+
+// checkVersion проверяет объект версии, сохраняет результат в БД и в version и учитывает его в report.
+func (s *integrityService) checkVersion(
+	ctx context.Context,
+	version *models.VaultVersion,
+	report *models.StorageScrubReport,
+) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	status := s.verifyVersion(ctx, version)
+	verifiedAt := s.now()
+	if err := s.vaultVersionRepo.UpdateVerificationStatus(ctx, version.ID, status, verifiedAt); err != nil {
+		log.Printf("[IntegrityService] Ошибка сохранения статуса проверки версии %d: %v", version.ID, err)
+		return errors.New("ошибка сохранения результата проверки целостности")
+	}
+	version.VerificationStatus = &status
+	version.LastVerifiedAt = &verifiedAt
+
+	report.Checked++
+	switch status {
+	case models.VerificationStatusOK:
+		report.OK++
+	case models.VerificationStatusCorrupted:
+		report.Corrupted++
+	case models.VerificationStatusMissing:
+		report.Missing++
+	default:
+		report.Errors++
+	}
+	return nil
+}
+
+// verifyVersion скачивает объект версии, пересчитывает SHA256 и возвращает статус проверки.
+func (s *integrityService) verifyVersion(ctx context.Context, version *models.VaultVersion) string {
+	reader, err := s.fileStorage.DownloadFile(ctx, version.ObjectKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			log.Printf("[IntegrityService] Объект '%s' версии %d отсутствует в хранилище", version.ObjectKey, version.ID)
+			return models.VerificationStatusMissing
+		}
+		if errors.Is(err, storage.ErrDecryptionFailed) {
+			log.Printf("[IntegrityService] Объект '%s' версии %d не расшифровывается: %v", version.ObjectKey, version.ID, err)
+			return models.VerificationStatusCorrupted
+		}
+		log.Printf("[IntegrityService] Ошибка скачивания объекта '%s' версии %d: %v", version.ObjectKey, version.ID, err)
+		return models.VerificationStatusError
+	}
+	defer reader.Close()
+
+	hasher := sha256.New()
+	if _, err = io.Copy(hasher, reader); err != nil {
+		if errors.Is(err, storage.ErrDecryptionFailed) {
+			log.Printf("[IntegrityService] Объект '%s' версии %d не расшифровывается: %v", version.ObjectKey, version.ID, err)
+			return models.VerificationStatusCorrupted
+		}
+		log.Printf("[IntegrityService] Ошибка чтения объекта '%s' версии %d: %v", version.ObjectKey, version.ID, err)
+		return models.VerificationStatusError
+	}
+
+	if version.Checksum == nil {
+		// Сравнивать не с чем: считаем объект целым, раз он полностью читается
+		return models.VerificationStatusOK
+	}
+	actual := hex.EncodeToString(hasher.Sum(nil))
+	if actual != *version.Checksum {
+		log.Printf("[IntegrityService] Контрольная сумма объекта '%s' версии %d не совпадает: ожидалась %s, получена %s",
+			version.ObjectKey, version.ID, *version.Checksum, actual)
+		return models.VerificationStatusCorrupted
+	}
+	return models.VerificationStatusOK
+}
+
+// LastReport возвращает итоги последнего завершенного прохода проверки.
+func (s *integrityService) LastReport() *models.StorageScrubReport {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.lastReport == nil {
+		return nil
+	}
+	report := *s.lastReport
+	return &report
+}
+
+// ListProblems возвращает версии, последняя проверка которых завершилась неуспешно.
+func (s *integrityService) ListProblems(ctx context.Context, limit, offset int) ([]models.VaultVersion, error) {
+	versions, err := s.vaultVersionRepo.ListVersionsWithIntegrityProblems(ctx, limit, offset)
+	if err != nil {
+		log.Printf("[IntegrityService] Ошибка получения списка версий с нарушением целостности: %v", err)
+		return nil, errors.New("внутренняя ошибка сервера")
+	}
+	return versions, nil
+}
+
+// checksumReader считает SHA256 прочитанных данных и сверяет его с ожидаемым значением в конце потока.
+// При несовпадении вместо io.EOF возвращается ErrChecksumMismatch, поэтому получатель
+// не может принять поврежденные данные за успешно прочитанный файл.
+type checksumReader struct {
+	inner      io.ReadCloser
+	hasher     hash.Hash
+	expected   string
+	onMismatch func()
+	done       bool
+	mismatch   bool
+}
+
+// newChecksumReader оборачивает поток проверкой контрольной суммы.
+func newChecksumReader(inner io.ReadCloser, expected string, onMismatch func()) *checksumReader {
+	return &checksumReader{
+		inner:      inner,
+		hasher:     sha256.New(),
+		expected:   expected,
+		onMismatch: onMismatch,
+	}
+}
+
+// Read читает данные из исходного потока и при достижении конца сверяет контрольную сумму.
+func (r *checksumReader) Read(p []byte) (int, error) {
+	n, err := r.inner.Read(p)
+	r.hasher.Write(p[:n])
+	if !errors.Is(err, io.EOF) {
+		return n, err
+	}
+	if !r.done {
+		r.done = true
+		r.mismatch = hex.EncodeToString(r.hasher.Sum(nil)) != r.expected
+		if r.mismatch && r.onMismatch != nil {
+			r.onMismatch()
+		}
+	}
+	if r.mismatch {
+		return n, ErrChecksumMismatch
+	}
+	return n, err
+}
+
+// Close закрывает исходный поток.
+func (r *checksumReader) Close() error {
+	return r.inner.Close()
+}
+
+// Ошибки проверки целостности.
+var (
+	ErrChecksumMismatch = errors.New("контрольная сумма файла хранилища не совпадает с сохраненной")
+)
@@ -0,0 +1,204 @@
+package services_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maynagashev/gophkeeper/models"
+	"github.com/maynagashev/gophkeeper/server/internal/mocks"
+	"github.com/maynagashev/gophkeeper/server/internal/services"
+	"github.com/maynagashev/gophkeeper/server/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func sha256Hex(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestIntegrityService_ScrubOnce(t *testing.T) {
+	okChecksum := sha256Hex("good")
+	badChecksum := sha256Hex("expected")
+	versions := []models.VaultVersion{
+		{ID: 1, ObjectKey: "user_1/ok.kdbx", Checksum: &okChecksum},
+		{ID: 2, ObjectKey: "user_1/bad.kdbx", Checksum: &badChecksum},
+		{ID: 3, ObjectKey: "user_1/missing.kdbx", Checksum: &okChecksum},
+		{ID: 4, ObjectKey: "user_1/undecryptable.kdbx", Checksum: &okChecksum},
+		{ID: 5, ObjectKey: "user_1/unavailable.kdbx", Checksum: &okChecksum},
+	}
+
+	versionRepo := mocks.NewVaultVersionRepository(t)
+	fileStorage := mocks.NewFileStorage(t)
+
+	versionRepo.EXPECT().ListVersionsForVerification(mock.Anything, mock.Anything, mock.Anything).
+		Return(versions, nil).Once()
+	versionRepo.EXPECT().ListVersionsForVerification(mock.Anything, mock.Anything, mock.Anything).
+		Return([]models.VaultVersion{}, nil).Once()
+
+	fileStorage.EXPECT().DownloadFile(mock.Anything, "user_1/ok.kdbx").
+		Return(io.NopCloser(strings.NewReader("good")), nil).Once()
+	fileStorage.EXPECT().DownloadFile(mock.Anything, "user_1/bad.kdbx").
+		Return(io.NopCloser(strings.NewReader("tampered")), nil).Once()
+	fileStorage.EXPECT().DownloadFile(mock.Anything, "user_1/missing.kdbx").
+		Return(nil, storage.ErrObjectNotFound).Once()
+	fileStorage.EXPECT().DownloadFile(mock.Anything, "user_1/undecryptable.kdbx").
+		Return(nil, storage.ErrDecryptionFailed).Once()
+	fileStorage.EXPECT().DownloadFile(mock.Anything, "user_1/unavailable.kdbx").
+		Return(nil, errors.New("connection refused")).Once()
+
+	expectedStatuses := map[int64]string{
+		1: models.VerificationStatusOK,
+		2: models.VerificationStatusCorrupted,
+		3: models.VerificationStatusMissing,
+		4: models.VerificationStatusCorrupted,
+		5: models.VerificationStatusError,
+	}
+	for id, status := range expectedStatuses {
+		versionRepo.EXPECT().UpdateVerificationStatus(mock.Anything, id, status, mock.Anything).Return(nil).Once()
+	}
+
+	service := services.NewIntegrityService(versionRepo, fileStorage, time.Hour)
+	assert.Nil(t, service.LastReport(), "до первого прохода отчета быть не должно")
+
+	report, err := service.ScrubOnce(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 5, report.Checked)
+	assert.Equal(t, 1, report.OK)
+	assert.Equal(t, 2, report.Corrupted)
+	assert.Equal(t, 1, report.Missing)
+	assert.Equal(t, 1, report.Errors)
+	assert.Equal(t, report, service.LastReport())
+}
+
+func TestIntegrityService_ScrubOnceErrors(t *testing.T) {
+	t.Run("Ошибка получения версий", func(t *testing.T) {
+		versionRepo := mocks.NewVaultVersionRepository(t)
+		versionRepo.EXPECT().ListVersionsForVerification(mock.Anything, mock.Anything, mock.Anything).
+			Return(nil, errors.New("db error")).Once()
+		service := services.NewIntegrityService(versionRepo, mocks.NewFileStorage(t), time.Hour)
+
+		report, err := service.ScrubOnce(context.Background())
+
+		require.Error(t, err)
+		assert.Nil(t, report)
+		assert.Nil(t, service.LastReport())
+	})
+
+	t.Run("Ошибка сохранения статуса прерывает проход", func(t *testing.T) {
+		checksum := sha256Hex("good")
+		versionRepo := mocks.NewVaultVersionRepository(t)
+		fileStorage := mocks.NewFileStorage(t)
+		versionRepo.EXPECT().ListVersionsForVerification(mock.Anything, mock.Anything, mock.Anything).
+			Return([]models.VaultVersion{{ID: 1, ObjectKey: "k", Checksum: &checksum}}, nil).Once()
+		fileStorage.EXPECT().DownloadFile(mock.Anything, "k").
+			Return(io.NopCloser(strings.NewReader("good")), nil).Once()
+		versionRepo.EXPECT().UpdateVerificationStatus(mock.Anything, int64(1), models.VerificationStatusOK, mock.Anything).
+			Return(errors.New("db error")).Once()
+		service := services.NewIntegrityService(versionRepo, fileStorage, time.Hour)
+
+		_, err := service.ScrubOnce(context.Background())
+
+		require.Error(t, err)
+	})
+}
+
+func TestIntegrityService_ListProblems(t *testing.T) {
+	versionRepo := mocks.NewVaultVersionRepository(t)
+	status := models.VerificationStatusMissing
+	problems := []models.VaultVersion{{ID: 3, VerificationStatus: &status}}
+	versionRepo.EXPECT().ListVersionsWithIntegrityProblems(mock.Anything, 20, 0).Return(problems, nil).Once()
+	versionRepo.EXPECT().ListVersionsWithIntegrityProblems(mock.Anything, 20, 20).
+		Return(nil, errors.New("db error")).Once()
+	service := services.NewIntegrityService(versionRepo, mocks.NewFileStorage(t), time.Hour)
+
+	result, err := service.ListProblems(context.Background(), 20, 0)
+	require.NoError(t, err)
+	assert.Equal(t, problems, result)
+
+	_, err = service.ListProblems(context.Background(), 20, 20)
+	require.Error(t, err)
+}
+
+func TestIntegrityService_VerifyVersions(t *testing.T) {
+	okChecksum := sha256Hex("good")
+	versions := []models.VaultVersion{
+		{ID: 1, ObjectKey: "user_1/ok.kdbx", Checksum: &okChecksum},
+		{ID: 2, ObjectKey: "user_1/missing.kdbx", Checksum: &okChecksum},
+	}
+	versionRepo := mocks.NewVaultVersionRepository(t)
+	fileStorage := mocks.NewFileStorage(t)
+	fileStorage.EXPECT().DownloadFile(mock.Anything, "user_1/ok.kdbx").
+		Return(io.NopCloser(strings.NewReader("good")), nil).Once()
+	fileStorage.EXPECT().DownloadFile(mock.Anything, "user_1/missing.kdbx").
+		Return(nil, storage.ErrObjectNotFound).Once()
+	versionRepo.EXPECT().UpdateVerificationStatus(mock.Anything, int64(1), models.VerificationStatusOK, mock.Anything).
+		Return(nil).Once()
+	versionRepo.EXPECT().
+		UpdateVerificationStatus(mock.Anything, int64(2), models.VerificationStatusMissing, mock.Anything).
+		Return(nil).Once()
+	service := services.NewIntegrityService(versionRepo, fileStorage, time.Hour)
+
+	result, err := service.VerifyVersions(context.Background(), versions)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Report.Checked)
+	assert.Equal(t, 1, result.Report.OK)
+	assert.Equal(t, 1, result.Report.Missing)
+	require.Len(t, result.Problems, 1)
+	assert.Equal(t, int64(2), result.Problems[0].ID)
+	assert.Nil(t, versions[1].VerificationStatus, "исходные версии не должны изменяться")
+	assert.Nil(t, service.LastReport(), "итоги фоновой проверки не должны меняться")
+}
+
+func TestVaultService_DownloadVaultVerifiesChecksum(t *testing.T) {
+	testUserID := int64(1)
+	content := "vault content"
+
+	tests := []struct {
+		name         string
+		stored       string
+		wantErr      error
+		expectRecord bool
+	}{
+		{name: "Контрольная сумма совпадает", stored: content},
+		{name: "Контрольная сумма не совпадает", stored: "tampered", wantErr: services.ErrChecksumMismatch, expectRecord: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mockVaultRepo, mockVersionRepo, mockFileStorage, _ := setupVaultServiceWithMocks()
+			checksum := sha256Hex(content)
+			version := &models.VaultVersion{ID: 9, VaultID: 1, ObjectKey: "user_1/v.kdbx", Checksum: &checksum}
+			mockVaultRepo.EXPECT().GetVaultWithCurrentVersionByUserID(mock.Anything, testUserID).
+				Return(&models.Vault{ID: 1, UserID: testUserID}, version, nil).Once()
+			mockFileStorage.EXPECT().DownloadFile(mock.Anything, "user_1/v.kdbx").
+				Return(io.NopCloser(strings.NewReader(tt.stored)), nil).Once()
+			if tt.expectRecord {
+				mockVersionRepo.EXPECT().
+					UpdateVerificationStatus(mock.Anything, int64(9), models.VerificationStatusCorrupted, mock.Anything).
+					Return(nil).Once()
+			}
+
+			reader, _, err := service.DownloadVault(context.Background(), testUserID)
+			require.NoError(t, err)
+			data, err := io.ReadAll(reader)
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, content, string(data))
+			}
+			mockVersionRepo.AssertExpectations(t)
+		})
+	}
+}
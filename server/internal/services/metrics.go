@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/maynagashev/gophkeeper/server/internal/storage"
+)
+
+// VaultMetrics принимает показатели операций с хранилищами.
+type VaultMetrics interface {
+	// ObserveUpload учитывает файл, загруженный в хранилище объектов.
+	ObserveUpload(sizeBytes int64, duration time.Duration)
+	// ObserveDownload учитывает файл (или его часть), отданный клиенту.
+	ObserveDownload(sizeBytes int64, duration time.Duration)
+	// VersionCreated учитывает созданную версию (reason - VersionReason*).
+	VersionCreated(reason string)
+	// StorageError учитывает ошибку хранилища объектов (operation - StorageOp*, kind - StorageError*).
+	StorageError(operation, kind string)
+}
+
+// AuthMetrics принимает показатели аутентификации.
+type AuthMetrics interface {
+	// LoginAttempt учитывает попытку входа (result - LoginResult*).
+	LoginAttempt(result string)
+}
+
+// Причины создания версии хранилища.
+const (
+	VersionReasonUpload   = "upload"
+	VersionReasonRollback = "rollback"
+)
+
+// Операции с хранилищем объектов и типы их ошибок.
+const (
+	StorageOpUpload   = "upload"
+	StorageOpDownload = "download"
+
+	StorageErrorNotFound         = "not_found"
+	StorageErrorTimeout          = "timeout"
+	StorageErrorCanceled         = "canceled"
+	StorageErrorChecksumMismatch = "checksum_mismatch"
+	StorageErrorOther            = "other"
+)
+
+// Результаты попытки входа.
+const (
+	LoginResultSuccess            = "success"
+	LoginResultInvalidCredentials = "invalid_credentials"
+	LoginResultAccountDisabled    = "account_disabled"
+	LoginResultError              = "error"
+)
+
+// noopMetrics используется, если метрики не переданы в конструктор сервиса.
+type noopMetrics struct{}
+
+func (noopMetrics) ObserveUpload(int64, time.Duration)   {}
+func (noopMetrics) ObserveDownload(int64, time.Duration) {}
+func (noopMetrics) VersionCreated(string)                {}
+func (noopMetrics) StorageError(string, string)          {}
+func (noopMetrics) LoginAttempt(string)                  {}
+
+// storageErrorKind определяет тип ошибки хранилища объектов для метрик.
+func storageErrorKind(ctx context.Context, err error) string {
+	switch {
+	case errors.Is(err, storage.ErrObjectNotFound):
+		return StorageErrorNotFound
+	case errors.Is(err, ErrChecksumMismatch):
+		return StorageErrorChecksumMismatch
+	case errors.Is(ctx.Err(), context.DeadlineExceeded) || errors.Is(err, context.DeadlineExceeded):
+		return StorageErrorTimeout
+	case errors.Is(ctx.Err(), context.Canceled) || errors.Is(err, context.Canceled):
+		return StorageErrorCanceled
+	default:
+		return StorageErrorOther
+	}
+}
+
+// meteredReader считает отданные клиенту байты и учитывает скачивание при закрытии потока.
+// Ошибка чтения (кроме io.EOF) учитывается как ошибка хранилища.
+type meteredReader struct {
+	io.ReadCloser
+	metrics   VaultMetrics
+	errorKind func(err error) string // Тип ошибки чтения для метрик (с учетом контекста потока)
+	started   time.Time
+	read      int64
+	failed    bool
+}
+
+// Read читает данные и учитывает их объем.
+func (r *meteredReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.read += int64(n)
+	if err != nil && !errors.Is(err, io.EOF) && !r.failed {
+		r.failed = true
+		r.metrics.StorageError(StorageOpDownload, r.errorKind(err))
+	}
+	return n, err
+}
+
+// Close закрывает поток и учитывает скачивание.
+func (r *meteredReader) Close() error {
+	err := r.ReadCloser.Close()
+	r.metrics.ObserveDownload(r.read, time.Since(r.started))
+	return err
+}
@@ -0,0 +1,136 @@
+package services_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/maynagashev/gophkeeper/models"
+	"github.com/maynagashev/gophkeeper/server/internal/kdbx/kdbxtest"
+	"github.com/maynagashev/gophkeeper/server/internal/mocks"
+	"github.com/maynagashev/gophkeeper/server/internal/repository"
+	"github.com/maynagashev/gophkeeper/server/internal/services"
+	"github.com/maynagashev/gophkeeper/server/internal/storage"
+)
+
+// setupVaultServiceWithMetrics создает сервис с моками и моком метрик.
+func setupVaultServiceWithMetrics(t *testing.T) (
+	services.VaultService,
+	*mocks.VaultRepository,
+	*mocks.VaultVersionRepository,
+	*mocks.FileStorage,
+	*mocks.VaultMetrics,
+	*mocks.Transactor,
+) {
+	mockVaultRepo := mocks.NewVaultRepository(t)
+	mockVersionRepo := mocks.NewVaultVersionRepository(t)
+	mockFileStorage := mocks.NewFileStorage(t)
+	mockMetrics := mocks.NewVaultMetrics(t)
+	mockTx := mocks.NewTransactor(t)
+
+	service := services.NewVaultService(mockTx, mockVaultRepo, mockVersionRepo, mockFileStorage, nil,
+		services.Timeouts{}, mockMetrics)
+	return service, mockVaultRepo, mockVersionRepo, mockFileStorage, mockMetrics, mockTx
+}
+
+func TestVaultService_UploadMetrics(t *testing.T) {
+	const (
+		userID    = int64(1)
+		vaultID   = int64(10)
+		versionID = int64(101)
+	)
+	data := string(kdbxtest.File(4, 512))
+	modTime := time.Now().UTC().Truncate(time.Second)
+
+	t.Run("Учитываются объем загрузки и новая версия", func(t *testing.T) {
+		service, vaultRepo, versionRepo, fileStorage, metrics, mockTx := setupVaultServiceWithMetrics(t)
+		fileStorage.EXPECT().UploadFile(mock.Anything, mock.Anything, mock.Anything, int64(len(data)), mock.Anything).
+			Return(nil).Once()
+		expectTx(mockTx, vaultRepo, versionRepo)
+		vaultRepo.EXPECT().LockVaultWithCurrentVersionByUserID(mock.Anything, userID).
+			Return(&models.Vault{ID: vaultID, UserID: userID}, nil, nil).Once()
+		versionRepo.EXPECT().CreateVersion(mock.Anything, mock.Anything).Return(versionID, nil).Once()
+		vaultRepo.EXPECT().UpdateVaultCurrentVersion(mock.Anything, vaultID, versionID).Return(nil).Once()
+		metrics.EXPECT().ObserveUpload(int64(len(data)), mock.Anything).Once()
+		metrics.EXPECT().VersionCreated(services.VersionReasonUpload).Once()
+
+		_, err := service.UploadVault(context.Background(), userID, strings.NewReader(data), int64(len(data)),
+			"application/octet-stream", modTime, models.UploadOrigin{})
+		require.NoError(t, err)
+	})
+
+	t.Run("Ошибка хранилища учитывается по типу", func(t *testing.T) {
+		service, _, _, fileStorage, metrics, _ := setupVaultServiceWithMetrics(t)
+		fileStorage.EXPECT().UploadFile(mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return(context.DeadlineExceeded).Once()
+		metrics.EXPECT().StorageError(services.StorageOpUpload, services.StorageErrorTimeout).Once()
+
+		_, err := service.UploadVault(context.Background(), userID, strings.NewReader(data), int64(len(data)),
+			"application/octet-stream", modTime, models.UploadOrigin{})
+		require.Error(t, err)
+	})
+}
+
+func TestVaultService_DownloadMetrics(t *testing.T) {
+	checksum := sha256Hex("content")
+	version := &models.VaultVersion{ID: 5, VaultID: 1, ObjectKey: "user_1/vault.kdbx", Checksum: &checksum}
+
+	t.Run("Объем скачивания учитывается при закрытии потока", func(t *testing.T) {
+		service, _, _, fileStorage, metrics, _ := setupVaultServiceWithMetrics(t)
+		fileStorage.EXPECT().DownloadFile(mock.Anything, version.ObjectKey).
+			Return(io.NopCloser(strings.NewReader("content")), nil).Once()
+
+		reader, err := service.DownloadVersion(context.Background(), version, 0, -1)
+		require.NoError(t, err)
+		_, err = io.ReadAll(reader)
+		require.NoError(t, err)
+
+		metrics.EXPECT().ObserveDownload(int64(len("content")), mock.Anything).Once()
+		require.NoError(t, reader.Close())
+	})
+
+	t.Run("Несовпадение контрольной суммы учитывается как ошибка хранилища", func(t *testing.T) {
+		service, _, versionRepo, fileStorage, metrics, _ := setupVaultServiceWithMetrics(t)
+		fileStorage.EXPECT().DownloadFile(mock.Anything, version.ObjectKey).
+			Return(io.NopCloser(strings.NewReader("tampered")), nil).Once()
+		versionRepo.EXPECT().UpdateVerificationStatus(mock.Anything, version.ID,
+			models.VerificationStatusCorrupted, mock.Anything).Return(nil).Once()
+		metrics.EXPECT().StorageError(services.StorageOpDownload, services.StorageErrorChecksumMismatch).Once()
+		metrics.EXPECT().ObserveDownload(mock.Anything, mock.Anything).Once()
+
+		reader, err := service.DownloadVersion(context.Background(), version, 0, -1)
+		require.NoError(t, err)
+		_, err = io.ReadAll(reader)
+		require.ErrorIs(t, err, services.ErrChecksumMismatch)
+		require.NoError(t, reader.Close())
+	})
+
+	t.Run("Отсутствующий объект учитывается как ошибка хранилища", func(t *testing.T) {
+		service, _, _, fileStorage, metrics, _ := setupVaultServiceWithMetrics(t)
+		fileStorage.EXPECT().DownloadFileRange(mock.Anything, version.ObjectKey, int64(3), int64(-1)).
+			Return(nil, storage.ErrObjectNotFound).Once()
+		metrics.EXPECT().StorageError(services.StorageOpDownload, services.StorageErrorNotFound).Once()
+
+		_, err := service.DownloadVersion(context.Background(), version, 3, -1)
+		require.ErrorIs(t, err, services.ErrVaultNotFound)
+	})
+}
+
+func TestAuthService_LoginMetrics(t *testing.T) {
+	mockUserRepo := mocks.NewUserRepository(t)
+	mockMetrics := mocks.NewAuthMetrics(t)
+	mockUserRepo.EXPECT().GetUserByUsername(mock.Anything, "unknown").Return(nil, repository.ErrUserNotFound).Once()
+	mockMetrics.EXPECT().LoginAttempt(services.LoginResultInvalidCredentials).Once()
+
+	authService := services.NewAuthService(mockUserRepo, nil, services.TokenConfig{}, services.Timeouts{}, mockMetrics)
+	token, err := authService.Login(context.Background(), "unknown", "password", models.Device{})
+
+	require.ErrorIs(t, err, services.ErrInvalidCredentials)
+	assert.Empty(t, token)
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// Timeouts задает предельное время операций сервисов. Нулевое значение - без ограничения:
+// операция выполняется, пока не отменен контекст запроса (например, при отключении клиента).
+type Timeouts struct {
+	// DB - таймаут запросов и транзакций БД в рамках одной операции.
+	DB time.Duration
+	// Storage - таймаут передачи файла в хранилище объектов или из него.
+	Storage time.Duration
+}
+
+// ErrOperationTimeout возвращается, если операция не уложилась в настроенный таймаут.
+var ErrOperationTimeout = errors.New("превышено время выполнения операции")
+
+// withTimeout возвращает контекст с таймаутом d (или просто отменяемый контекст, если d не задан).
+func withTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, d)
+}
+
+// timeoutOr возвращает ErrOperationTimeout, если у ctx истек таймаут, иначе err.
+func timeoutOr(ctx context.Context, err error) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return ErrOperationTimeout
+	}
+	return err
+}
+
+// cancelOnClose освобождает контекст потока при закрытии читателя: поток живет дольше вызова
+// сервиса, поэтому отменить контекст сразу нельзя.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close закрывает поток и отменяет его контекст.
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
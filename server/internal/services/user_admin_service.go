@@ -0,0 +1,350 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/maynagashev/gophkeeper/models"
+	"github.com/maynagashev/gophkeeper/server/internal/repository"
+	"github.com/maynagashev/gophkeeper/server/internal/storage"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// adminVersionsPageSize - количество версий, выбираемых из БД за один запрос при обходе всех версий.
+const adminVersionsPageSize = 100
+
+// UserAdminService определяет операции администрирования пользователей и их хранилищ.
+// Пользователь задается именем, как его знает оператор.
+type UserAdminService interface {
+	// ListUsers возвращает пользователей, имя которых содержит search (пустая строка - все).
+	ListUsers(ctx context.Context, search string, limit, offset int) ([]models.UserSummary, error)
+	// SetUserDisabled отключает (запрещает вход) или включает учетную запись.
+	SetUserDisabled(ctx context.Context, username string, disabled bool) error
+	// SetUserRole задает роль пользователя (models.RoleUser или models.RoleAdmin).
+	SetUserRole(ctx context.Context, username, role string) error
+	// ResetPassword задает пользователю новый пароль.
+	ResetPassword(ctx context.Context, username, password string) error
+	// ListVersions возвращает все версии хранилища пользователя.
+	ListVersions(ctx context.Context, username string) (*models.UserVersions, error)
+	// GetStorageUsage возвращает объем хранилища пользователя.
+	GetStorageUsage(ctx context.Context, username string) (*models.UserStorageUsage, error)
+	// PruneVersions удаляет все версии, кроме keep последних и текущей, вместе с объектами,
+	// на которые больше не ссылается ни одна версия.
+	PruneVersions(ctx context.Context, username string, keep int) (*models.PruneReport, error)
+	// VerifyStorage проверяет контрольные суммы объектов всех версий пользователя.
+	VerifyStorage(ctx context.Context, username string) (*models.UserStorageReport, error)
+	// DeleteUser удаляет пользователя со всеми данными и объектами его версий.
+	DeleteUser(ctx context.Context, username string) (*models.UserDeleteReport, error)
+	// ServerStats возвращает сводную статистику сервера.
+	ServerStats(ctx context.Context) (*models.ServerStats, error)
+}
+
+var _ UserAdminService = (*userAdminService)(nil)
+
+// userAdminService реализует UserAdminService поверх репозиториев и хранилища файлов.
+type userAdminService struct {
+	userRepo         repository.UserRepository
+	vaultRepo        repository.VaultRepository
+	vaultVersionRepo repository.VaultVersionRepository
+	transactor       repository.Transactor
+	fileStorage      storage.FileStorage
+	integrity        IntegrityService
+}
+
+// NewUserAdminService создает сервис администрирования пользователей.
+// Проверка целостности выполняется через integrity, поэтому ее результаты сохраняются так же,
+// как результаты фоновой проверки.
+func NewUserAdminService(
+	userRepo repository.UserRepository,
+	vaultRepo repository.VaultRepository,
+	vaultVersionRepo repository.VaultVersionRepository,
+	transactor repository.Transactor,
+	fileStorage storage.FileStorage,
+	integrity IntegrityService,
+) UserAdminService {
+	return &userAdminService{
+		userRepo:         userRepo,
+		vaultRepo:        vaultRepo,
+		vaultVersionRepo: vaultVersionRepo,
+		transactor:       transactor,
+		fileStorage:      fileStorage,
+		integrity:        integrity,
+	}
+}
+
+// ListUsers возвращает пользователей со статистикой их хранилищ.
+func (s *userAdminService) ListUsers(
+	ctx context.Context,
+	search string,
+	limit,
+	offset int,
+) ([]models.UserSummary, error) {
+	users, err := s.userRepo.ListUsers(ctx, search, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения списка пользователей: %w", err)
+	}
+	return users, nil
+}
+
+// SetUserDisabled отключает или включает учетную запись.
+// Отключение действует сразу: учетная запись проверяется при каждом запросе, поэтому уже выданные токены
+// отклоняются с ErrCodeAccountDisabled.
+func (s *userAdminService) SetUserDisabled(ctx context.Context, username string, disabled bool) error {
+	user, err := s.getUser(ctx, username)
+	if err != nil {
+		return err
+	}
+	if err = s.userRepo.SetUserDisabled(ctx, user.ID, disabled); err != nil {
+		return s.userError(err, "изменения статуса учетной записи")
+	}
+	log.Printf("[UserAdminService] Учетная запись '%s' (ID %d): отключена=%t", username, user.ID, disabled)
+	return nil
+}
+
+// SetUserRole изменяет роль пользователя. Роль загружается из БД при каждом запросе,
+// поэтому новая роль действует сразу, в том числе для уже выданных токенов.
+func (s *userAdminService) SetUserRole(ctx context.Context, username, role string) error {
+	if role != models.RoleUser && role != models.RoleAdmin {
+		return ErrInvalidRole
+	}
+	user, err := s.getUser(ctx, username)
+	if err != nil {
+		return err
+	}
+	if err = s.userRepo.SetUserRole(ctx, user.ID, role); err != nil {
+		return s.userError(err, "изменения роли")
+	}
+	log.Printf("[UserAdminService] Роль пользователя '%s' (ID %d): %s", username, user.ID, role)
+	return nil
+}
+
+// ResetPassword хеширует и сохраняет новый пароль пользователя.
+func (s *userAdminService) ResetPassword(ctx context.Context, username, password string) error {
+	if password == "" {
+		return ErrEmptyPassword
+	}
+	user, err := s.getUser(ctx, username)
+	if err != nil {
+		return err
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("ошибка хеширования пароля: %w", err)
+	}
+	if err = s.userRepo.UpdatePasswordHash(ctx, user.ID, string(hashedPassword)); err != nil {
+		return s.userError(err, "смены пароля")
+	}
+	log.Printf("[UserAdminService] Пароль пользователя '%s' (ID %d) изменен", username, user.ID)
+	return nil
+}
+
+// ListVersions возвращает все версии хранилища пользователя (сначала новые).
+func (s *userAdminService) ListVersions(ctx context.Context, username string) (*models.UserVersions, error) {
+	user, err := s.getUser(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	vault, err := s.vaultRepo.GetVaultByUserID(ctx, user.ID)
+	if err != nil {
+		if errors.Is(err, repository.ErrVaultNotFound) {
+			return &models.UserVersions{Versions: []models.VaultVersion{}}, nil
+		}
+		return nil, fmt.Errorf("ошибка получения хранилища пользователя: %w", err)
+	}
+	versions, err := listAllVersions(ctx, s.vaultVersionRepo, vault.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.UserVersions{CurrentVersionID: vault.CurrentVersionID, Versions: versions}, nil
+}
+
+// GetStorageUsage подсчитывает версии и объем объектов хранилища пользователя.
+func (s *userAdminService) GetStorageUsage(ctx context.Context, username string) (*models.UserStorageUsage, error) {
+	userVersions, err := s.ListVersions(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := &models.UserStorageUsage{
+		Username:         username,
+		VersionCount:     len(userVersions.Versions),
+		CurrentVersionID: userVersions.CurrentVersionID,
+	}
+	// Объект, на который ссылаются несколько версий (после отката), учитывается один раз
+	seen := make(map[string]bool, len(userVersions.Versions))
+	for i, version := range userVersions.Versions {
+		if i == 0 {
+			usage.LastUploadAt = &userVersions.Versions[i].CreatedAt
+		}
+		if userVersions.CurrentVersionID != nil && *userVersions.CurrentVersionID == version.ID {
+			usage.CurrentSizeBytes = version.SizeBytes
+		}
+		if seen[version.ObjectKey] || version.SizeBytes == nil {
+			continue
+		}
+		seen[version.ObjectKey] = true
+		usage.StorageBytes += *version.SizeBytes
+	}
+	return usage, nil
+}
+
+// PruneVersions удаляет старые версии в транзакции с блокировкой хранилища (параллельная загрузка
+// или откат дождутся ее завершения), а затем удаляет из хранилища файлов объекты без ссылок.
+func (s *userAdminService) PruneVersions(
+	ctx context.Context,
+	username string,
+	keep int,
+) (*models.PruneReport, error) {
+	if keep < 1 {
+		return nil, ErrInvalidKeepCount
+	}
+	user, err := s.getUser(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.PruneReport{}
+	var orphaned []models.VaultVersion
+	err = s.transactor.WithTx(ctx, func(repos repository.TxRepositories) error {
+		vault, _, lockErr := repos.Vaults.LockVaultWithCurrentVersionByUserID(ctx, user.ID)
+		if lockErr != nil {
+			return lockErr
+		}
+		versions, listErr := listAllVersions(ctx, repos.Versions, vault.ID)
+		if listErr != nil {
+			return listErr
+		}
+
+		var toDelete []int64
+		for i, version := range versions {
+			isCurrent := vault.CurrentVersionID != nil && *vault.CurrentVersionID == version.ID
+			if i < keep || isCurrent {
+				report.KeptVersions++
+				continue
+			}
+			toDelete = append(toDelete, version.ID)
+		}
+
+		var deleteErr error
+		orphaned, deleteErr = repos.Versions.DeleteVersions(ctx, toDelete)
+		report.DeletedVersions = len(toDelete)
+		return deleteErr
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrVaultNotFound) {
+			return report, nil // Хранилища нет - удалять нечего
+		}
+		return nil, fmt.Errorf("ошибка удаления версий: %w", err)
+	}
+
+	for _, object := range orphaned {
+		if deleteErr := s.fileStorage.DeleteFile(ctx, object.ObjectKey); deleteErr != nil {
+			log.Printf("[UserAdminService] Ошибка удаления объекта '%s': %v", object.ObjectKey, deleteErr)
+			report.FailedObjects = append(report.FailedObjects, object.ObjectKey)
+			continue
+		}
+		report.DeletedObjects++
+		if object.SizeBytes != nil {
+			report.FreedBytes += *object.SizeBytes
+		}
+	}
+
+	log.Printf("[UserAdminService] Очистка версий пользователя '%s': оставлено %d, удалено %d версий и %d объектов",
+		username, report.KeptVersions, report.DeletedVersions, report.DeletedObjects)
+	return report, nil
+}
+
+// VerifyStorage проверяет объекты всех версий пользователя.
+func (s *userAdminService) VerifyStorage(ctx context.Context, username string) (*models.UserStorageReport, error) {
+	userVersions, err := s.ListVersions(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	return s.integrity.VerifyVersions(ctx, userVersions.Versions)
+}
+
+// DeleteUser удаляет пользователя, а затем объекты его версий. Записи в БД удаляются первыми:
+// если удаление объекта не удастся, останется недоступный никому объект, а не версия без объекта.
+func (s *userAdminService) DeleteUser(ctx context.Context, username string) (*models.UserDeleteReport, error) {
+	user, err := s.getUser(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	objectKeys, err := s.vaultVersionRepo.ListObjectKeysByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения объектов пользователя: %w", err)
+	}
+	if err = s.userRepo.DeleteUser(ctx, user.ID); err != nil {
+		return nil, s.userError(err, "удаления пользователя")
+	}
+
+	report := &models.UserDeleteReport{}
+	for _, objectKey := range objectKeys {
+		if deleteErr := s.fileStorage.DeleteFile(ctx, objectKey); deleteErr != nil {
+			log.Printf("[UserAdminService] Ошибка удаления объекта '%s': %v", objectKey, deleteErr)
+			report.FailedObjects = append(report.FailedObjects, objectKey)
+			continue
+		}
+		report.DeletedObjects++
+	}
+
+	log.Printf("[UserAdminService] Пользователь '%s' (ID %d) удален, удалено объектов: %d",
+		username, user.ID, report.DeletedObjects)
+	return report, nil
+}
+
+// ServerStats возвращает статистику по пользователям и хранилищам вместе с итогами последней проверки целостности.
+func (s *userAdminService) ServerStats(ctx context.Context) (*models.ServerStats, error) {
+	stats, err := s.userRepo.GetServerStats(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения статистики сервера: %w", err)
+	}
+	stats.LastScrub = s.integrity.LastReport()
+	return stats, nil
+}
+
+// getUser находит пользователя по имени.
+func (s *userAdminService) getUser(ctx context.Context, username string) (*models.User, error) {
+	user, err := s.userRepo.GetUserByUsername(ctx, username)
+	if err != nil {
+		return nil, s.userError(err, "поиска пользователя")
+	}
+	return user, nil
+}
+
+// userError переводит ошибку репозитория пользователей в ошибку сервиса.
+func (s *userAdminService) userError(err error, operation string) error {
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return ErrUserNotFound
+	}
+	return fmt.Errorf("ошибка %s: %w", operation, err)
+}
+
+// listAllVersions возвращает все версии хранилища постранично (сначала новые).
+func listAllVersions(
+	ctx context.Context,
+	repo repository.VaultVersionRepository,
+	vaultID int64,
+) ([]models.VaultVersion, error) {
+	versions := []models.VaultVersion{}
+	for offset := 0; ; offset += adminVersionsPageSize {
+		page, err := repo.ListVersionsByVaultID(ctx, vaultID, adminVersionsPageSize, offset)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка получения версий хранилища: %w", err)
+		}
+		versions = append(versions, page...)
+		if len(page) < adminVersionsPageSize {
+			return versions, nil
+		}
+	}
+}
+
+// Ошибки сервиса администрирования.
+var (
+	ErrUserNotFound     = errors.New("пользователь не найден")
+	ErrEmptyPassword    = errors.New("пароль не может быть пустым")
+	ErrInvalidKeepCount = errors.New("количество сохраняемых версий должно быть не меньше 1")
+	ErrInvalidRole      = errors.New("неизвестная роль пользователя")
+)
@@ -0,0 +1,284 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/maynagashev/gophkeeper/models"
+	"github.com/maynagashev/gophkeeper/server/internal/mocks"
+	"github.com/maynagashev/gophkeeper/server/internal/repository"
+	"github.com/maynagashev/gophkeeper/server/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type userAdminMocks struct {
+	userRepo    *mocks.UserRepository
+	vaultRepo   *mocks.VaultRepository
+	versionRepo *mocks.VaultVersionRepository
+	tx          *mocks.Transactor
+	fileStorage *mocks.FileStorage
+	integrity   *mocks.IntegrityService
+}
+
+func setupUserAdminService(t *testing.T) (services.UserAdminService, *userAdminMocks) {
+	m := &userAdminMocks{
+		userRepo:    mocks.NewUserRepository(t),
+		vaultRepo:   mocks.NewVaultRepository(t),
+		versionRepo: mocks.NewVaultVersionRepository(t),
+		tx:          mocks.NewTransactor(t),
+		fileStorage: mocks.NewFileStorage(t),
+		integrity:   mocks.NewIntegrityService(t),
+	}
+	service := services.NewUserAdminService(m.userRepo, m.vaultRepo, m.versionRepo, m.tx, m.fileStorage, m.integrity)
+	return service, m
+}
+
+func int64Ptr(v int64) *int64 { return &v }
+
+func TestUserAdminService_SetUserDisabled(t *testing.T) {
+	t.Run("Успешное отключение", func(t *testing.T) {
+		service, m := setupUserAdminService(t)
+		m.userRepo.EXPECT().GetUserByUsername(mock.Anything, "alice").Return(&models.User{ID: 7}, nil).Once()
+		m.userRepo.EXPECT().SetUserDisabled(mock.Anything, int64(7), true).Return(nil).Once()
+
+		require.NoError(t, service.SetUserDisabled(context.Background(), "alice", true))
+	})
+
+	t.Run("Пользователь не найден", func(t *testing.T) {
+		service, m := setupUserAdminService(t)
+		m.userRepo.EXPECT().GetUserByUsername(mock.Anything, "bob").Return(nil, repository.ErrUserNotFound).Once()
+
+		err := service.SetUserDisabled(context.Background(), "bob", true)
+
+		require.ErrorIs(t, err, services.ErrUserNotFound)
+	})
+}
+
+func TestUserAdminService_SetUserRole(t *testing.T) {
+	t.Run("Назначение администратора", func(t *testing.T) {
+		service, m := setupUserAdminService(t)
+		m.userRepo.EXPECT().GetUserByUsername(mock.Anything, "alice").Return(&models.User{ID: 7}, nil).Once()
+		m.userRepo.EXPECT().SetUserRole(mock.Anything, int64(7), models.RoleAdmin).Return(nil).Once()
+
+		require.NoError(t, service.SetUserRole(context.Background(), "alice", models.RoleAdmin))
+	})
+
+	t.Run("Неизвестная роль", func(t *testing.T) {
+		service, _ := setupUserAdminService(t)
+
+		err := service.SetUserRole(context.Background(), "alice", "root")
+
+		require.ErrorIs(t, err, services.ErrInvalidRole)
+	})
+}
+
+func TestUserAdminService_ResetPassword(t *testing.T) {
+	t.Run("Пароль хешируется", func(t *testing.T) {
+		service, m := setupUserAdminService(t)
+		m.userRepo.EXPECT().GetUserByUsername(mock.Anything, "alice").Return(&models.User{ID: 7}, nil).Once()
+		m.userRepo.EXPECT().UpdatePasswordHash(mock.Anything, int64(7), mock.Anything).
+			RunAndReturn(func(_ context.Context, _ int64, hash string) error {
+				return bcrypt.CompareHashAndPassword([]byte(hash), []byte("new-password"))
+			}).Once()
+
+		require.NoError(t, service.ResetPassword(context.Background(), "alice", "new-password"))
+	})
+
+	t.Run("Пустой пароль", func(t *testing.T) {
+		service, _ := setupUserAdminService(t)
+
+		err := service.ResetPassword(context.Background(), "alice", "")
+
+		require.ErrorIs(t, err, services.ErrEmptyPassword)
+	})
+}
+
+func TestUserAdminService_ListVersions(t *testing.T) {
+	t.Run("Хранилища нет", func(t *testing.T) {
+		service, m := setupUserAdminService(t)
+		m.userRepo.EXPECT().GetUserByUsername(mock.Anything, "alice").Return(&models.User{ID: 7}, nil).Once()
+		m.vaultRepo.EXPECT().GetVaultByUserID(mock.Anything, int64(7)).Return(nil, repository.ErrVaultNotFound).Once()
+
+		result, err := service.ListVersions(context.Background(), "alice")
+
+		require.NoError(t, err)
+		assert.Nil(t, result.CurrentVersionID)
+		assert.Empty(t, result.Versions)
+	})
+
+	t.Run("Версии выбираются постранично", func(t *testing.T) {
+		service, m := setupUserAdminService(t)
+		firstPage := make([]models.VaultVersion, 100)
+		m.userRepo.EXPECT().GetUserByUsername(mock.Anything, "alice").Return(&models.User{ID: 7}, nil).Once()
+		m.vaultRepo.EXPECT().GetVaultByUserID(mock.Anything, int64(7)).
+			Return(&models.Vault{ID: 3, CurrentVersionID: int64Ptr(42)}, nil).Once()
+		m.versionRepo.EXPECT().ListVersionsByVaultID(mock.Anything, int64(3), 100, 0).Return(firstPage, nil).Once()
+		m.versionRepo.EXPECT().ListVersionsByVaultID(mock.Anything, int64(3), 100, 100).
+			Return([]models.VaultVersion{{ID: 1}}, nil).Once()
+
+		result, err := service.ListVersions(context.Background(), "alice")
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(42), *result.CurrentVersionID)
+		assert.Len(t, result.Versions, 101)
+	})
+}
+
+func TestUserAdminService_GetStorageUsage(t *testing.T) {
+	service, m := setupUserAdminService(t)
+	lastUpload := time.Date(2025, 5, 1, 10, 0, 0, 0, time.UTC)
+	// Версия 3 создана откатом к версии 1 и ссылается на тот же объект
+	versions := []models.VaultVersion{
+		{ID: 3, ObjectKey: "user_7/v1.kdbx", SizeBytes: int64Ptr(100), CreatedAt: lastUpload},
+		{ID: 2, ObjectKey: "user_7/v2.kdbx", SizeBytes: int64Ptr(200)},
+		{ID: 1, ObjectKey: "user_7/v1.kdbx", SizeBytes: int64Ptr(100)},
+	}
+	m.userRepo.EXPECT().GetUserByUsername(mock.Anything, "alice").Return(&models.User{ID: 7}, nil).Once()
+	m.vaultRepo.EXPECT().GetVaultByUserID(mock.Anything, int64(7)).
+		Return(&models.Vault{ID: 3, CurrentVersionID: int64Ptr(3)}, nil).Once()
+	m.versionRepo.EXPECT().ListVersionsByVaultID(mock.Anything, int64(3), 100, 0).Return(versions, nil).Once()
+
+	usage, err := service.GetStorageUsage(context.Background(), "alice")
+
+	require.NoError(t, err)
+	assert.Equal(t, "alice", usage.Username)
+	assert.Equal(t, 3, usage.VersionCount)
+	assert.Equal(t, int64(300), usage.StorageBytes)
+	assert.Equal(t, int64(3), *usage.CurrentVersionID)
+	assert.Equal(t, int64(100), *usage.CurrentSizeBytes)
+	assert.Equal(t, lastUpload, *usage.LastUploadAt)
+}
+
+func TestUserAdminService_ServerStats(t *testing.T) {
+	service, m := setupUserAdminService(t)
+	report := &models.StorageScrubReport{Checked: 5, OK: 5}
+	m.userRepo.EXPECT().GetServerStats(mock.Anything).Return(&models.ServerStats{Users: 3}, nil).Once()
+	m.integrity.EXPECT().LastReport().Return(report).Once()
+
+	stats, err := service.ServerStats(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), stats.Users)
+	assert.Equal(t, report, stats.LastScrub)
+}
+
+func TestUserAdminService_PruneVersions(t *testing.T) {
+	expectPruneTx := func(m *userAdminMocks) {
+		m.tx.EXPECT().WithTx(mock.Anything, mock.Anything).
+			RunAndReturn(func(_ context.Context, fn func(repository.TxRepositories) error) error {
+				return fn(repository.TxRepositories{Vaults: m.vaultRepo, Versions: m.versionRepo})
+			}).Once()
+	}
+
+	t.Run("Удаляются старые версии, текущая сохраняется", func(t *testing.T) {
+		service, m := setupUserAdminService(t)
+		m.userRepo.EXPECT().GetUserByUsername(mock.Anything, "alice").Return(&models.User{ID: 7}, nil).Once()
+		expectPruneTx(m)
+		// Текущая версия (2) не входит в две последние: после отката пользователь загрузил еще две.
+		m.vaultRepo.EXPECT().LockVaultWithCurrentVersionByUserID(mock.Anything, int64(7)).
+			Return(&models.Vault{ID: 3, CurrentVersionID: int64Ptr(2)}, nil, nil).Once()
+		m.versionRepo.EXPECT().ListVersionsByVaultID(mock.Anything, int64(3), 100, 0).
+			Return([]models.VaultVersion{{ID: 5}, {ID: 4}, {ID: 3}, {ID: 2}, {ID: 1}}, nil).Once()
+		m.versionRepo.EXPECT().DeleteVersions(mock.Anything, []int64{3, 1}).
+			Return([]models.VaultVersion{
+				{ObjectKey: "user_7/v3.kdbx", SizeBytes: int64Ptr(100)},
+				{ObjectKey: "user_7/v1.kdbx", SizeBytes: int64Ptr(50)},
+			}, nil).Once()
+		m.fileStorage.EXPECT().DeleteFile(mock.Anything, "user_7/v3.kdbx").Return(nil).Once()
+		m.fileStorage.EXPECT().DeleteFile(mock.Anything, "user_7/v1.kdbx").Return(errors.New("storage error")).Once()
+
+		report, err := service.PruneVersions(context.Background(), "alice", 2)
+
+		require.NoError(t, err)
+		assert.Equal(t, 3, report.KeptVersions)
+		assert.Equal(t, 2, report.DeletedVersions)
+		assert.Equal(t, 1, report.DeletedObjects)
+		assert.Equal(t, int64(100), report.FreedBytes)
+		assert.Equal(t, []string{"user_7/v1.kdbx"}, report.FailedObjects)
+	})
+
+	t.Run("Хранилища нет", func(t *testing.T) {
+		service, m := setupUserAdminService(t)
+		m.userRepo.EXPECT().GetUserByUsername(mock.Anything, "alice").Return(&models.User{ID: 7}, nil).Once()
+		expectPruneTx(m)
+		m.vaultRepo.EXPECT().LockVaultWithCurrentVersionByUserID(mock.Anything, int64(7)).
+			Return(nil, nil, repository.ErrVaultNotFound).Once()
+
+		report, err := service.PruneVersions(context.Background(), "alice", 2)
+
+		require.NoError(t, err)
+		assert.Equal(t, &models.PruneReport{}, report)
+	})
+
+	t.Run("Ошибка удаления версий", func(t *testing.T) {
+		service, m := setupUserAdminService(t)
+		m.userRepo.EXPECT().GetUserByUsername(mock.Anything, "alice").Return(&models.User{ID: 7}, nil).Once()
+		expectPruneTx(m)
+		m.vaultRepo.EXPECT().LockVaultWithCurrentVersionByUserID(mock.Anything, int64(7)).
+			Return(&models.Vault{ID: 3}, nil, nil).Once()
+		m.versionRepo.EXPECT().ListVersionsByVaultID(mock.Anything, int64(3), 100, 0).
+			Return([]models.VaultVersion{{ID: 2}, {ID: 1}}, nil).Once()
+		m.versionRepo.EXPECT().DeleteVersions(mock.Anything, []int64{1}).Return(nil, errors.New("db error")).Once()
+
+		_, err := service.PruneVersions(context.Background(), "alice", 1)
+
+		require.Error(t, err)
+	})
+
+	t.Run("Неверное количество сохраняемых версий", func(t *testing.T) {
+		service, _ := setupUserAdminService(t)
+
+		_, err := service.PruneVersions(context.Background(), "alice", 0)
+
+		require.ErrorIs(t, err, services.ErrInvalidKeepCount)
+	})
+}
+
+func TestUserAdminService_VerifyStorage(t *testing.T) {
+	service, m := setupUserAdminService(t)
+	versions := []models.VaultVersion{{ID: 1}, {ID: 2}}
+	expected := &models.UserStorageReport{Report: models.StorageScrubReport{Checked: 2, OK: 2}}
+	m.userRepo.EXPECT().GetUserByUsername(mock.Anything, "alice").Return(&models.User{ID: 7}, nil).Once()
+	m.vaultRepo.EXPECT().GetVaultByUserID(mock.Anything, int64(7)).Return(&models.Vault{ID: 3}, nil).Once()
+	m.versionRepo.EXPECT().ListVersionsByVaultID(mock.Anything, int64(3), 100, 0).Return(versions, nil).Once()
+	m.integrity.EXPECT().VerifyVersions(mock.Anything, versions).Return(expected, nil).Once()
+
+	report, err := service.VerifyStorage(context.Background(), "alice")
+
+	require.NoError(t, err)
+	assert.Equal(t, expected, report)
+}
+
+func TestUserAdminService_DeleteUser(t *testing.T) {
+	t.Run("Удаляются пользователь и объекты", func(t *testing.T) {
+		service, m := setupUserAdminService(t)
+		m.userRepo.EXPECT().GetUserByUsername(mock.Anything, "alice").Return(&models.User{ID: 7}, nil).Once()
+		m.versionRepo.EXPECT().ListObjectKeysByUserID(mock.Anything, int64(7)).
+			Return([]string{"user_7/a.kdbx", "user_7/b.kdbx"}, nil).Once()
+		m.userRepo.EXPECT().DeleteUser(mock.Anything, int64(7)).Return(nil).Once()
+		m.fileStorage.EXPECT().DeleteFile(mock.Anything, "user_7/a.kdbx").Return(nil).Once()
+		m.fileStorage.EXPECT().DeleteFile(mock.Anything, "user_7/b.kdbx").Return(errors.New("storage error")).Once()
+
+		report, err := service.DeleteUser(context.Background(), "alice")
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, report.DeletedObjects)
+		assert.Equal(t, []string{"user_7/b.kdbx"}, report.FailedObjects)
+	})
+
+	t.Run("Ошибка удаления пользователя - объекты не удаляются", func(t *testing.T) {
+		service, m := setupUserAdminService(t)
+		m.userRepo.EXPECT().GetUserByUsername(mock.Anything, "alice").Return(&models.User{ID: 7}, nil).Once()
+		m.versionRepo.EXPECT().ListObjectKeysByUserID(mock.Anything, int64(7)).Return([]string{"k"}, nil).Once()
+		m.userRepo.EXPECT().DeleteUser(mock.Anything, int64(7)).Return(errors.New("db error")).Once()
+
+		_, err := service.DeleteUser(context.Background(), "alice")
+
+		require.Error(t, err)
+	})
+}
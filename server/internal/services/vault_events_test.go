@@ -0,0 +1,188 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maynagashev/gophkeeper/models"
+	"github.com/maynagashev/gophkeeper/server/internal/kdbx/kdbxtest"
+	"github.com/maynagashev/gophkeeper/server/internal/mocks"
+	"github.com/maynagashev/gophkeeper/server/internal/repository"
+	"github.com/maynagashev/gophkeeper/server/internal/services"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// setupVaultServiceWithPublisher создает сервис с моками и моком публикатора событий.
+func setupVaultServiceWithPublisher(t *testing.T) (
+	services.VaultService,
+	*mocks.VaultRepository,
+	*mocks.VaultVersionRepository,
+	*mocks.FileStorage,
+	*mocks.Publisher,
+	*mocks.Transactor,
+) {
+	mockVaultRepo := mocks.NewVaultRepository(t)
+	mockVersionRepo := mocks.NewVaultVersionRepository(t)
+	mockFileStorage := mocks.NewFileStorage(t)
+	mockPublisher := mocks.NewPublisher(t)
+	mockTx := mocks.NewTransactor(t)
+
+	service := services.NewVaultService(mockTx, mockVaultRepo, mockVersionRepo, mockFileStorage, mockPublisher,
+		services.Timeouts{}, nil)
+	return service, mockVaultRepo, mockVersionRepo, mockFileStorage, mockPublisher, mockTx
+}
+
+func TestVaultService_UploadPublishesEvent(t *testing.T) {
+	const (
+		userID    = int64(1)
+		vaultID   = int64(10)
+		versionID = int64(101)
+	)
+	data := string(kdbxtest.File(4, 512))
+	modTime := time.Now().UTC().Truncate(time.Second)
+
+	t.Run("Событие публикуется после коммита", func(t *testing.T) {
+		service, vaultRepo, versionRepo, fileStorage, publisher, mockTx := setupVaultServiceWithPublisher(t)
+		fileStorage.EXPECT().UploadFile(mock.Anything, mock.Anything, mock.Anything, int64(len(data)), mock.Anything).
+			RunAndReturn(func(_ context.Context, _ string, r io.Reader, _ int64, _ string) error {
+				// Вычитываем поток, чтобы сервис посчитал контрольную сумму
+				_, err := io.Copy(io.Discard, r)
+				return err
+			}).Once()
+		expectTx(mockTx, vaultRepo, versionRepo)
+		vaultRepo.EXPECT().LockVaultWithCurrentVersionByUserID(mock.Anything, userID).
+			Return(&models.Vault{ID: vaultID, UserID: userID}, nil, nil).Once()
+		versionRepo.EXPECT().CreateVersion(mock.Anything, mock.Anything).Return(versionID, nil).Once()
+		vaultRepo.EXPECT().UpdateVaultCurrentVersion(mock.Anything, vaultID, versionID).Return(nil).Once()
+		publisher.EXPECT().Publish(mock.Anything, mock.MatchedBy(func(e models.VaultEvent) bool {
+			return e.Type == models.VaultEventVersionCreated && e.UserID == userID &&
+				e.VersionID == versionID && e.Checksum != nil && *e.Checksum == sha256Hex(data)
+		})).Return(errors.New("ошибка публикации не влияет на загрузку")).Once()
+
+		_, err := service.UploadVault(context.Background(), userID, strings.NewReader(data), int64(len(data)),
+			"application/octet-stream", modTime, models.UploadOrigin{})
+
+		require.NoError(t, err)
+	})
+
+	t.Run("Доставки вебхуков ставятся в очередь в транзакции загрузки", func(t *testing.T) {
+		service, vaultRepo, versionRepo, fileStorage, publisher, mockTx := setupVaultServiceWithPublisher(t)
+		webhookRepo := mocks.NewWebhookRepository(t)
+		fileStorage.EXPECT().UploadFile(mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return(nil).Once()
+		expectTxWithWebhooks(mockTx, vaultRepo, versionRepo, webhookRepo)
+		vaultRepo.EXPECT().LockVaultWithCurrentVersionByUserID(mock.Anything, userID).
+			Return(&models.Vault{ID: vaultID, UserID: userID}, nil, nil).Once()
+		versionRepo.EXPECT().CreateVersion(mock.Anything, mock.Anything).Return(versionID, nil).Once()
+		vaultRepo.EXPECT().UpdateVaultCurrentVersion(mock.Anything, vaultID, versionID).Return(nil).Once()
+		webhookRepo.EXPECT().EnqueueDeliveries(mock.Anything, userID, models.VaultEventVersionCreated,
+			mock.MatchedBy(func(payload []byte) bool {
+				var event models.VaultEvent
+				return json.Unmarshal(payload, &event) == nil && event.VersionID == versionID
+			})).Return(int64(1), nil).Once()
+		publisher.EXPECT().Publish(mock.Anything, mock.Anything).Return(nil).Once()
+
+		_, err := service.UploadVault(context.Background(), userID, strings.NewReader(data), int64(len(data)),
+			"application/octet-stream", modTime, models.UploadOrigin{})
+
+		require.NoError(t, err)
+	})
+
+	t.Run("Ошибка постановки в очередь вебхуков отменяет загрузку", func(t *testing.T) {
+		// Публикатор не ожидает вызовов: без очереди доставок версия не фиксируется
+		service, vaultRepo, versionRepo, fileStorage, _, mockTx := setupVaultServiceWithPublisher(t)
+		webhookRepo := mocks.NewWebhookRepository(t)
+		fileStorage.EXPECT().UploadFile(mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return(nil).Once()
+		expectTxWithWebhooks(mockTx, vaultRepo, versionRepo, webhookRepo)
+		vaultRepo.EXPECT().LockVaultWithCurrentVersionByUserID(mock.Anything, userID).
+			Return(&models.Vault{ID: vaultID, UserID: userID}, nil, nil).Once()
+		versionRepo.EXPECT().CreateVersion(mock.Anything, mock.Anything).Return(versionID, nil).Once()
+		vaultRepo.EXPECT().UpdateVaultCurrentVersion(mock.Anything, vaultID, versionID).Return(nil).Once()
+		webhookRepo.EXPECT().EnqueueDeliveries(mock.Anything, userID, models.VaultEventVersionCreated, mock.Anything).
+			Return(int64(0), errors.New("connection reset")).Once()
+
+		version, err := service.UploadVault(context.Background(), userID, strings.NewReader(data),
+			int64(len(data)), "application/octet-stream", modTime, models.UploadOrigin{})
+
+		require.Error(t, err)
+		require.Nil(t, version)
+	})
+
+	t.Run("При конфликте событие не публикуется", func(t *testing.T) {
+		service, vaultRepo, versionRepo, fileStorage, _, mockTx := setupVaultServiceWithPublisher(t)
+		newer := modTime.Add(time.Hour)
+		fileStorage.EXPECT().UploadFile(mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return(nil).Once()
+		expectTx(mockTx, vaultRepo, versionRepo)
+		vaultRepo.EXPECT().LockVaultWithCurrentVersionByUserID(mock.Anything, userID).
+			Return(&models.Vault{ID: vaultID, UserID: userID},
+				&models.VaultVersion{ID: 100, VaultID: vaultID, ContentModifiedAt: &newer}, nil).Once()
+
+		_, err := service.UploadVault(context.Background(), userID, strings.NewReader(data), int64(len(data)),
+			"application/octet-stream", modTime, models.UploadOrigin{})
+
+		require.ErrorIs(t, err, services.ErrConflictVersion)
+	})
+}
+
+func TestVaultService_RollbackPublishesEvent(t *testing.T) {
+	const (
+		userID     = int64(1)
+		vaultID    = int64(10)
+		versionID  = int64(99)
+		restoredID = int64(100)
+	)
+	checksum := "abc"
+
+	t.Run("Событие публикуется после отката", func(t *testing.T) {
+		service, vaultRepo, versionRepo, _, publisher, mockTx := setupVaultServiceWithPublisher(t)
+		expectTx(mockTx, vaultRepo, versionRepo)
+		vaultRepo.EXPECT().LockVaultWithCurrentVersionByUserID(mock.Anything, userID).
+			Return(&models.Vault{ID: vaultID, UserID: userID}, nil, nil).Once()
+		versionRepo.EXPECT().GetVersionByID(mock.Anything, versionID).
+			Return(&models.VaultVersion{ID: versionID, VaultID: vaultID, Checksum: &checksum}, nil).Once()
+		versionRepo.EXPECT().CreateVersion(mock.Anything, mock.Anything).Return(restoredID, nil).Once()
+		vaultRepo.EXPECT().UpdateVaultCurrentVersion(mock.Anything, vaultID, restoredID).Return(nil).Once()
+		publisher.EXPECT().Publish(mock.Anything, mock.MatchedBy(func(e models.VaultEvent) bool {
+			return e.Type == models.VaultEventRolledBack && e.UserID == userID && e.VersionID == restoredID &&
+				e.Checksum != nil && *e.Checksum == checksum && !e.CreatedAt.IsZero()
+		})).Return(nil).Once()
+
+		require.NoError(t, service.RollbackToVersion(context.Background(), userID, versionID))
+	})
+
+	t.Run("Ошибка постановки в очередь вебхуков отменяет откат", func(t *testing.T) {
+		service, vaultRepo, versionRepo, _, _, mockTx := setupVaultServiceWithPublisher(t)
+		webhookRepo := mocks.NewWebhookRepository(t)
+		expectTxWithWebhooks(mockTx, vaultRepo, versionRepo, webhookRepo)
+		vaultRepo.EXPECT().LockVaultWithCurrentVersionByUserID(mock.Anything, userID).
+			Return(&models.Vault{ID: vaultID, UserID: userID}, nil, nil).Once()
+		versionRepo.EXPECT().GetVersionByID(mock.Anything, versionID).
+			Return(&models.VaultVersion{ID: versionID, VaultID: vaultID, Checksum: &checksum}, nil).Once()
+		versionRepo.EXPECT().CreateVersion(mock.Anything, mock.Anything).Return(restoredID, nil).Once()
+		vaultRepo.EXPECT().UpdateVaultCurrentVersion(mock.Anything, vaultID, restoredID).Return(nil).Once()
+		webhookRepo.EXPECT().EnqueueDeliveries(mock.Anything, userID, models.VaultEventRolledBack, mock.Anything).
+			Return(int64(0), errors.New("connection reset")).Once()
+
+		err := service.RollbackToVersion(context.Background(), userID, versionID)
+
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "внутренняя ошибка сервера при откате")
+	})
+
+	t.Run("При ошибке отката событие не публикуется", func(t *testing.T) {
+		service, vaultRepo, versionRepo, _, _, mockTx := setupVaultServiceWithPublisher(t)
+		expectTx(mockTx, vaultRepo, versionRepo)
+		vaultRepo.EXPECT().LockVaultWithCurrentVersionByUserID(mock.Anything, userID).
+			Return(nil, nil, repository.ErrVaultNotFound).Once()
+
+		require.ErrorIs(t, service.RollbackToVersion(context.Background(), userID, versionID), services.ErrVaultNotFound)
+	})
+}
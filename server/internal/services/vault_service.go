@@ -3,7 +3,6 @@ package services
 import (
 	"context"
 	"crypto/sha256"
-	"database/sql"
 	"encoding/hex"
 	"errors"
 	"fmt"
@@ -13,47 +12,76 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/maynagashev/gophkeeper/models"
+	"github.com/maynagashev/gophkeeper/server/internal/events"
+	"github.com/maynagashev/gophkeeper/server/internal/kdbx"
 	"github.com/maynagashev/gophkeeper/server/internal/repository"
 	"github.com/maynagashev/gophkeeper/server/internal/storage"
 )
 
 // VaultService определяет интерфейс для сервиса работы с хранилищами.
 type VaultService interface {
-	GetVaultMetadata(userID int64) (*models.VaultVersion, error)
-	UploadVault(userID int64, reader io.Reader, size int64, contentType string, contentModifiedAt time.Time) error
-	DownloadVault(userID int64) (io.ReadCloser, *models.VaultVersion, error)
-	ListVersions(userID int64, limit, offset int) ([]models.VaultVersion, error)
-	RollbackToVersion(userID int64, versionID int64) error
+	GetVaultMetadata(ctx context.Context, userID int64) (*models.VaultVersion, error)
+	UploadVault(
+		ctx context.Context,
+		userID int64,
+		reader io.Reader,
+		size int64,
+		contentType string,
+		contentModifiedAt time.Time,
+		origin models.UploadOrigin,
+	) (*models.VaultVersion, error)
+	DownloadVault(ctx context.Context, userID int64) (io.ReadCloser, *models.VaultVersion, error)
+	DownloadVersion(ctx context.Context, version *models.VaultVersion, offset, length int64) (io.ReadCloser, error)
+	ListVersions(ctx context.Context, userID int64, limit, offset int) ([]models.VaultVersion, error)
+	RollbackToVersion(ctx context.Context, userID int64, versionID int64) error
 }
 
 // vaultService реализует логику работы с хранилищами.
 var _ VaultService = (*vaultService)(nil)
 
 type vaultService struct {
-	db               *sql.DB
+	transactor       repository.Transactor
 	vaultRepo        repository.VaultRepository
 	vaultVersionRepo repository.VaultVersionRepository
 	fileStorage      storage.FileStorage
+	publisher        events.Publisher
+	timeouts         Timeouts
+	metrics          VaultMetrics
 }
 
 // NewVaultService создает новый экземпляр сервиса хранилищ.
+// Изменения хранилища (загрузка, откат) выполняются в транзакциях transactor.
+// publisher получает события об изменениях хранилища после коммита (подписки SSE и gRPC), он может быть nil.
+// Доставки вебхуков ставятся в очередь в той же транзакции, что и изменение (см. TxRepositories).
+// timeouts ограничивают время запросов к БД и передачи файлов.
+// metrics может быть nil - тогда показатели операций не собираются.
 func NewVaultService(
-	db *sql.DB,
+	transactor repository.Transactor,
 	vaultRepo repository.VaultRepository,
 	vaultVersionRepo repository.VaultVersionRepository,
 	fileStorage storage.FileStorage,
+	publisher events.Publisher,
+	timeouts Timeouts,
+	metrics VaultMetrics,
 ) VaultService {
+	if metrics == nil {
+		metrics = noopMetrics{}
+	}
 	return &vaultService{
-		db:               db,
+		transactor:       transactor,
 		vaultRepo:        vaultRepo,
 		vaultVersionRepo: vaultVersionRepo,
 		fileStorage:      fileStorage,
+		publisher:        publisher,
+		timeouts:         timeouts,
+		metrics:          metrics,
 	}
 }
 
 // GetVaultMetadata получает метаданные ТЕКУЩЕЙ версии хранилища для пользователя.
-func (s *vaultService) GetVaultMetadata(userID int64) (*models.VaultVersion, error) {
-	ctx := context.Background()
+func (s *vaultService) GetVaultMetadata(ctx context.Context, userID int64) (*models.VaultVersion, error) {
+	ctx, cancel := withTimeout(ctx, s.timeouts.DB)
+	defer cancel()
 
 	_, currentVersion, err := s.vaultRepo.GetVaultWithCurrentVersionByUserID(ctx, userID)
 	if err != nil {
@@ -62,7 +90,7 @@ func (s *vaultService) GetVaultMetadata(userID int64) (*models.VaultVersion, err
 			return nil, ErrVaultNotFound
 		}
 		log.Printf("[VaultService] Ошибка репозитория при получении хранилища с версией для пользователя %d: %v", userID, err)
-		return nil, errors.New("внутренняя ошибка сервера при получении метаданных")
+		return nil, timeoutOr(ctx, errors.New("внутренняя ошибка сервера при получении метаданных"))
 	}
 
 	if currentVersion == nil {
@@ -77,70 +105,124 @@ func (s *vaultService) GetVaultMetadata(userID int64) (*models.VaultVersion, err
 }
 
 // Добавили contentModifiedAt в параметры.
+// origin - сведения о клиенте и устройстве, они сохраняются вместе с созданной версией.
+// Возвращает метаданные созданной версии, а если загружена идентичная версия - метаданные текущей.
+// Отмена ctx (например, отключение клиента) прерывает загрузку файла и откатывает транзакцию.
 func (s *vaultService) UploadVault(
+	ctx context.Context,
 	userID int64,
 	reader io.Reader,
 	size int64,
 	contentType string,
 	contentModifiedAt time.Time,
-) error {
-	ctx := context.Background()
+	origin models.UploadOrigin,
+) (*models.VaultVersion, error) {
+	// Заголовок проверяется до загрузки в хранилище, поэтому файл другого формата не сохраняется
+	format, reader, err := checkVaultFile(reader, size)
+	if err != nil {
+		log.Printf("[VaultService] Файл пользователя %d отклонен: %v", userID, err)
+		return nil, err
+	}
 
 	// Загружаем файл и получаем его чек-сумму
-	objectKey, checksumClient, err := s.uploadFileToStorage(ctx, userID, reader, size, contentType)
+	storageCtx, cancelStorage := withTimeout(ctx, s.timeouts.Storage)
+	objectKey, checksumClient, err := s.uploadFileToStorage(storageCtx, userID, reader, size, contentType)
 	if err != nil {
-		return err
+		err = timeoutOr(storageCtx, err)
 	}
-
-	// --- Транзакция БД --- //
-	tx, err := s.db.BeginTx(ctx, nil)
+	cancelStorage()
 	if err != nil {
-		log.Printf("[VaultService] Ошибка начала транзакции для пользователя %d: %v", userID, err)
-		// TODO: Попытаться удалить загруженный файл из MinIO?
-		return errors.New("внутренняя ошибка сервера")
+		return nil, err
 	}
-	// Гарантируем откат транзакции в случае паники или ошибки
+
+	// Событие о созданной версии: вебхукам оно ставится в очередь в транзакции,
+	// подписчикам публикуется только после успешного коммита
+	var (
+		event  *models.VaultEvent
+		result *models.VaultVersion
+	)
 	defer func() {
-		if p := recover(); p != nil {
-			_ = tx.Rollback()
-			panic(p) // Передаем панику дальше
-		} else if err != nil {
-			log.Printf("[VaultService] Ошибка во время транзакции, откат... Error: %v", err)
-			_ = tx.Rollback()
-			// TODO: Попытаться удалить загруженный файл из MinIO?
-		} else {
-			err = tx.Commit()
-			if err != nil {
-				log.Printf("[VaultService] Ошибка коммита транзакции: %v", err)
-				// TODO: Попытаться удалить загруженный файл из MinIO?
-			}
+		if err == nil && event != nil {
+			s.metrics.VersionCreated(VersionReasonUpload)
+			s.publishEvent(*event)
 		}
 	}()
 
-	// Получаем текущее хранилище и его ВЕРСИЮ
-	vault, currentVersion, err := s.vaultRepo.GetVaultWithCurrentVersionByUserID(ctx, userID) // TODO: Передать tx
-	if err != nil && !errors.Is(err, repository.ErrVaultNotFound) {
-		// Неожиданная ошибка при поиске
-		log.Printf("[VaultService] Ошибка поиска хранилища/версии для пользователя %d: %v", userID, err)
-		return errors.New("внутренняя ошибка сервера") // defer откатит транзакцию
-	}
+	// --- Транзакция БД --- //
+	// Запись хранилища блокируется до конца транзакции, поэтому одновременные загрузки
+	// одного пользователя выполняются по очереди и каждая сравнивается с актуальной версией.
+	ctx, cancel := withTimeout(ctx, s.timeouts.DB)
+	defer cancel()
+	err = s.transactor.WithTx(ctx, func(repos repository.TxRepositories) error {
+		// Получаем текущее хранилище и его ВЕРСИЮ
+		vault, currentVersion, lockErr := repos.Vaults.LockVaultWithCurrentVersionByUserID(ctx, userID)
+		if lockErr != nil && !errors.Is(lockErr, repository.ErrVaultNotFound) {
+			// Неожиданная ошибка при поиске
+			log.Printf("[VaultService] Ошибка поиска хранилища/версии для пользователя %d: %v", userID, lockErr)
+			return errors.New("внутренняя ошибка сервера") // Транзакция будет откачена
+		}
 
-	// Сравниваем версии и решаем, нужно ли создавать новую
-	shouldCreateNewVersion, err := s.shouldCreateNewVersion(currentVersion, contentModifiedAt, checksumClient)
+		// Сравниваем версии и решаем, нужно ли создавать новую
+		shouldCreate, checkErr := s.shouldCreateNewVersion(currentVersion, contentModifiedAt, checksumClient)
+		if checkErr != nil {
+			return checkErr // Ошибка конфликта
+		}
+		if !shouldCreate {
+			result = currentVersion // Идентичная версия
+			return nil
+		}
+
+		created, createErr := s.createNewVersion(
+			ctx, repos, vault, userID, objectKey, checksumClient, size, contentModifiedAt, format, origin,
+		)
+		if createErr != nil {
+			return createErr
+		}
+		result = created
+		event = newVaultEvent(models.VaultEventVersionCreated, userID, created.ID, &checksumClient)
+		// Без очереди доставок версия не фиксируется, иначе вебхуки о ней не узнают
+		return enqueueWebhookEvent(ctx, repos.Webhooks, *event)
+	})
 	if err != nil {
-		return err // Возвращаем ошибку конфликта, если она возникла
+		// TODO: Попытаться удалить загруженный файл из MinIO?
+		if errors.Is(err, ErrConflictVersion) {
+			return nil, err
+		}
+		log.Printf("[VaultService] Ошибка во время транзакции загрузки (пользователь %d): %v", userID, err)
+		return nil, timeoutOr(ctx, errors.New("внутренняя ошибка сервера"))
 	}
 
-	// Если нужно создать новую версию
-	if shouldCreateNewVersion {
-		err = s.createNewVersion(ctx, vault, userID, objectKey, checksumClient, size, contentModifiedAt)
-		if err != nil {
-			return err
-		}
+	// Ошибки нет (либо была идентичная версия), транзакция зафиксирована
+	return result, nil
+}
+
+// checkVaultFile проверяет по внешнему заголовку, что файл является базой KeePass поддерживаемой
+// версии и не обрезан. Возвращает сведения о формате и поток с файлом целиком (заголовок уже прочитан).
+func checkVaultFile(reader io.Reader, size int64) (*kdbx.Info, io.Reader, error) {
+	if size < kdbx.MinFileSize {
+		return nil, nil, fmt.Errorf("%w: размер файла %d байт меньше минимального (%d байт)",
+			ErrInvalidVaultFile, size, kdbx.MinFileSize)
 	}
+	info, header, err := kdbx.ReadHeader(reader)
+	if errors.Is(err, kdbx.ErrInvalidFormat) {
+		return nil, nil, fmt.Errorf("%w: %w", ErrInvalidVaultFile, err)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if size < info.MinFileSize() {
+		return nil, nil, fmt.Errorf("%w: файл обрезан (%d байт при заголовке %d байт)",
+			ErrInvalidVaultFile, size, info.HeaderSize)
+	}
+	return info, kdbx.Reader(header, reader), nil
+}
 
-	// Ошибки нет (либо была идентичная версия), defer выполнит Commit
-	return nil
+// optionalString возвращает nil для пустой строки, чтобы в БД сохранялся NULL.
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
 }
 
 // uploadFileToStorage загружает файл в хранилище и возвращает ключ объекта и чек-сумму.
@@ -159,12 +241,16 @@ func (s *vaultService) uploadFileToStorage(
 	objectKey := fmt.Sprintf("user_%d/vault_%s.kdbx", userID, uuid.New().String())
 
 	// Загружаем файл в MinIO
+	started := time.Now()
 	err := s.fileStorage.UploadFile(ctx, objectKey, teeReader, size, contentType)
 	if err != nil {
+		s.metrics.StorageError(StorageOpUpload, storageErrorKind(ctx, err))
 		log.Printf("[VaultService] Ошибка загрузки файла в хранилище для пользователя %d: %v", userID, err)
 		return "", "", errors.New("внутренняя ошибка сервера при загрузке файла")
 	}
 
+	s.metrics.ObserveUpload(size, time.Since(started))
+
 	// Получаем вычисленную чек-сумму клиента
 	checksumClient := hex.EncodeToString(hash.Sum(nil))
 	log.Printf("[VaultService] Файл для пользователя %d загружен в '%s', SHA256: %s",
@@ -215,15 +301,19 @@ func (s *vaultService) shouldCreateNewVersion(
 }
 
 // createNewVersion создает новую версию хранилища или новое хранилище, если оно не существует.
+// Возвращает метаданные созданной версии.
 func (s *vaultService) createNewVersion(
 	ctx context.Context,
+	repos repository.TxRepositories,
 	vault *models.Vault,
 	userID int64,
 	objectKey string,
 	checksumClient string,
 	size int64,
 	contentModifiedAt time.Time,
-) error {
+	format *kdbx.Info,
+	origin models.UploadOrigin,
+) (*models.VaultVersion, error) {
 	// Найдем или создадим Vault
 	var vaultID int64
 	if vault == nil {
@@ -231,10 +321,15 @@ func (s *vaultService) createNewVersion(
 		log.Printf("[VaultService] Хранилище для пользователя %d не найдено, создаем новое.", userID)
 		newVault := &models.Vault{UserID: userID}
 		var err error
-		vaultID, err = s.vaultRepo.CreateVault(ctx, newVault) // TODO: Передать tx
+		vaultID, err = repos.Vaults.CreateVault(ctx, newVault)
+		if errors.Is(err, repository.ErrVaultAlreadyExists) {
+			// Первую версию только что загрузило другое устройство - клиенту нужно синхронизироваться
+			log.Printf("[VaultService] Хранилище пользователя %d создано параллельной загрузкой. Конфликт.", userID)
+			return nil, ErrConflictVersion
+		}
 		if err != nil {
 			log.Printf("[VaultService] Ошибка создания хранилища в транзакции для пользователя %d: %v", userID, err)
-			return errors.New("внутренняя ошибка сервера")
+			return nil, errors.New("внутренняя ошибка сервера")
 		}
 		log.Printf("[VaultService] Новое хранилище создано (ID: %d) для пользователя %d", vaultID, userID)
 	} else {
@@ -243,45 +338,55 @@ func (s *vaultService) createNewVersion(
 	}
 
 	// Создаем запись о новой версии
+	formatVersion := format.Version()
+	origin = origin.Normalize()
 	newVersion := &models.VaultVersion{
 		VaultID:           vaultID,
 		ObjectKey:         objectKey,
 		Checksum:          &checksumClient,
 		SizeBytes:         &size,
 		ContentModifiedAt: &contentModifiedAt,
+		KdbxVersion:       &formatVersion,
+		KdbxCipher:        &format.Cipher,
+		KdbxKDF:           &format.KDF,
+		ClientName:        optionalString(origin.ClientName),
+		ClientVersion:     optionalString(origin.ClientVersion),
+		DeviceID:          optionalString(origin.DeviceID),
+		UploaderIP:        optionalString(origin.IPAddress),
 	}
-	versionID, err := s.vaultVersionRepo.CreateVersion(ctx, newVersion) // TODO: Передать tx
+	versionID, err := repos.Versions.CreateVersion(ctx, newVersion)
 	if err != nil {
 		log.Printf("[VaultService] Ошибка создания версии в транзакции для хранилища %d: %v", vaultID, err)
-		return errors.New("внутренняя ошибка сервера")
+		return nil, errors.New("внутренняя ошибка сервера")
 	}
 	log.Printf("[VaultService] Новая версия создана (ID: %d) для хранилища %d", versionID, vaultID)
 
 	// Обновляем current_version_id в Vault
-	err = s.vaultRepo.UpdateVaultCurrentVersion(ctx, vaultID, versionID) // TODO: Передать tx
+	err = repos.Vaults.UpdateVaultCurrentVersion(ctx, vaultID, versionID)
 	if err != nil {
 		log.Printf("[VaultService] Ошибка обновления current_version_id в транзакции для хранилища %d: %v", vaultID, err)
-		return errors.New("внутренняя ошибка сервера")
+		return nil, errors.New("внутренняя ошибка сервера")
 	}
 	log.Printf("[VaultService] current_version_id для хранилища %d обновлен на %d", vaultID, versionID)
 
 	log.Printf("[VaultService] Загрузка и обновление метаданных для пользователя %d завершены успешно", userID)
-	return nil
+	newVersion.ID = versionID
+	return newVersion, nil
 }
 
 // DownloadVault скачивает ТЕКУЩУЮ версию файла хранилища.
-func (s *vaultService) DownloadVault(userID int64) (io.ReadCloser, *models.VaultVersion, error) {
-	ctx := context.Background()
-
+func (s *vaultService) DownloadVault(ctx context.Context, userID int64) (io.ReadCloser, *models.VaultVersion, error) {
 	// Получаем хранилище и текущую версию одним запросом
-	_, currentVersion, err := s.vaultRepo.GetVaultWithCurrentVersionByUserID(ctx, userID)
+	dbCtx, cancel := withTimeout(ctx, s.timeouts.DB)
+	defer cancel()
+	_, currentVersion, err := s.vaultRepo.GetVaultWithCurrentVersionByUserID(dbCtx, userID)
 	if err != nil {
 		if errors.Is(err, repository.ErrVaultNotFound) {
 			log.Printf("[VaultService] Запрос на скачивание: хранилище или версия для пользователя %d не найдены", userID)
 			return nil, nil, ErrVaultNotFound
 		}
 		log.Printf("[VaultService] Ошибка получения хранилища/версии для скачивания (пользователь %d): %v", userID, err)
-		return nil, nil, errors.New("внутренняя ошибка сервера при получении метаданных")
+		return nil, nil, timeoutOr(dbCtx, errors.New("внутренняя ошибка сервера при получении метаданных"))
 	}
 
 	if currentVersion == nil {
@@ -289,17 +394,9 @@ func (s *vaultService) DownloadVault(userID int64) (io.ReadCloser, *models.Vault
 		return nil, nil, ErrVaultNotFound
 	}
 
-	// Скачиваем файл из MinIO по ключу текущей версии
-	fileReader, err := s.fileStorage.DownloadFile(ctx, currentVersion.ObjectKey)
+	fileReader, err := s.DownloadVersion(ctx, currentVersion, 0, -1)
 	if err != nil {
-		if errors.Is(err, storage.ErrObjectNotFound) {
-			log.Printf("[VaultService] Файл '%s' не найден в хранилище"+
-				" (пользователь %d, версия %d)", currentVersion.ObjectKey, userID, currentVersion.ID)
-			return nil, nil, ErrVaultNotFound
-		}
-		log.Printf("[VaultService] Ошибка скачивания файла '%s' из хранилища"+
-			" (пользователь %d, версия %d): %v", currentVersion.ObjectKey, userID, currentVersion.ID, err)
-		return nil, nil, errors.New("внутренняя ошибка сервера при скачивании файла")
+		return nil, nil, err
 	}
 
 	log.Printf("[VaultService] Файл '%s' (версия %d) для пользователя %d"+
@@ -307,9 +404,74 @@ func (s *vaultService) DownloadVault(userID int64) (io.ReadCloser, *models.Vault
 	return fileReader, currentVersion, nil
 }
 
+// DownloadVersion открывает файл указанной версии начиная с offset длиной length байт
+// (length < 0 - до конца файла). Контрольная сумма сверяется только при чтении файла целиком,
+// так как для части файла сохраненная сумма не применима.
+// Таймаут передачи отсчитывается с момента открытия файла и действует, пока поток не закрыт.
+func (s *vaultService) DownloadVersion(
+	ctx context.Context,
+	version *models.VaultVersion,
+	offset, length int64,
+) (io.ReadCloser, error) {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Storage)
+	wholeFile := offset == 0 && length < 0
+
+	var fileReader io.ReadCloser
+	var err error
+	if wholeFile {
+		fileReader, err = s.fileStorage.DownloadFile(ctx, version.ObjectKey)
+	} else {
+		fileReader, err = s.fileStorage.DownloadFileRange(ctx, version.ObjectKey, offset, length)
+	}
+	if err != nil {
+		defer cancel()
+		s.metrics.StorageError(StorageOpDownload, storageErrorKind(ctx, err))
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			log.Printf("[VaultService] Файл '%s' (версия %d) не найден в хранилище", version.ObjectKey, version.ID)
+			return nil, ErrVaultNotFound
+		}
+		log.Printf("[VaultService] Ошибка скачивания файла '%s' (версия %d, offset=%d, length=%d): %v",
+			version.ObjectKey, version.ID, offset, length, err)
+		return nil, timeoutOr(ctx, errors.New("внутренняя ошибка сервера при скачивании файла"))
+	}
+	fileReader = &cancelOnClose{ReadCloser: fileReader, cancel: cancel}
+
+	// Сверяем контрольную сумму по мере чтения: поврежденный объект не должен уйти клиенту как валидный
+	if wholeFile && version.Checksum != nil {
+		fileReader = newChecksumReader(fileReader, *version.Checksum, func() {
+			s.recordCorruptedVersion(version)
+		})
+	}
+	fileReader = &meteredReader{
+		ReadCloser: fileReader,
+		metrics:    s.metrics,
+		errorKind:  func(err error) string { return storageErrorKind(ctx, err) },
+		started:    time.Now(),
+	}
+	return fileReader, nil
+}
+
+// recordCorruptedVersion сохраняет статус поврежденной версии, обнаруженной при скачивании.
+func (s *vaultService) recordCorruptedVersion(version *models.VaultVersion) {
+	log.Printf("[VaultService] Контрольная сумма объекта '%s' (версия %d) не совпала при скачивании",
+		version.ObjectKey, version.ID)
+	// Запрос клиента к этому моменту может быть уже завершен, поэтому используем отдельный контекст
+	ctx, cancel := context.WithTimeout(context.Background(), verificationStatusTimeout)
+	defer cancel()
+	err := s.vaultVersionRepo.UpdateVerificationStatus(ctx, version.ID, models.VerificationStatusCorrupted, time.Now())
+	if err != nil {
+		log.Printf("[VaultService] Ошибка сохранения статуса поврежденной версии %d: %v", version.ID, err)
+	}
+}
+
 // ListVersions возвращает список версий хранилища пользователя.
-func (s *vaultService) ListVersions(userID int64, limit, offset int) ([]models.VaultVersion, error) {
-	ctx := context.Background()
+func (s *vaultService) ListVersions(
+	ctx context.Context,
+	userID int64,
+	limit, offset int,
+) ([]models.VaultVersion, error) {
+	ctx, cancel := withTimeout(ctx, s.timeouts.DB)
+	defer cancel()
 
 	// Сначала находим ID хранилища пользователя
 	vault, err := s.vaultRepo.GetVaultByUserID(ctx, userID)
@@ -319,7 +481,7 @@ func (s *vaultService) ListVersions(userID int64, limit, offset int) ([]models.V
 			return []models.VaultVersion{}, nil // Возвращаем пустой слайс, а не ошибку
 		}
 		log.Printf("[VaultService] Ошибка поиска хранилища для списка версий (пользователь %d): %v", userID, err)
-		return nil, errors.New("внутренняя ошибка сервера")
+		return nil, timeoutOr(ctx, errors.New("внутренняя ошибка сервера"))
 	}
 
 	// Получаем список версий для найденного vaultID
@@ -327,7 +489,7 @@ func (s *vaultService) ListVersions(userID int64, limit, offset int) ([]models.V
 	if err != nil {
 		log.Printf("[VaultService] Ошибка получения списка версий для хранилища %d"+
 			" (пользователь %d): %v", vault.ID, userID, err)
-		return nil, errors.New("внутренняя ошибка сервера")
+		return nil, timeoutOr(ctx, errors.New("внутренняя ошибка сервера"))
 	}
 
 	log.Printf("[VaultService] Возвращено %d версий для пользователя %d", len(versions), userID)
@@ -335,58 +497,158 @@ func (s *vaultService) ListVersions(userID int64, limit, offset int) ([]models.V
 }
 
 // RollbackToVersion откатывает хранилище пользователя к указанной версии.
-func (s *vaultService) RollbackToVersion(userID int64, versionID int64) error {
-	ctx := context.Background()
+// Откат выполняется в транзакции с блокировкой записи хранилища, как и загрузка новой версии.
+func (s *vaultService) RollbackToVersion(ctx context.Context, userID int64, versionID int64) error {
+	ctx, cancel := withTimeout(ctx, s.timeouts.DB)
+	defer cancel()
+
+	var (
+		event       *models.VaultEvent
+		restored    *models.VaultVersion
+		rollbackErr error
+	)
+	err := s.transactor.WithTx(ctx, func(repos repository.TxRepositories) error {
+		event, restored, rollbackErr = s.rollbackInTx(ctx, repos, userID, versionID)
+		return rollbackErr
+	})
+	if rollbackErr != nil {
+		return timeoutOr(ctx, rollbackErr)
+	}
+	if err != nil {
+		log.Printf("[VaultService] Ошибка транзакции отката к версии %d (пользователь %d): %v", versionID, userID, err)
+		return timeoutOr(ctx, errors.New("внутренняя ошибка сервера при откате"))
+	}
 
-	// 1. Найти хранилище пользователя
-	vault, err := s.vaultRepo.GetVaultByUserID(ctx, userID)
+	// Откат к уже текущей версии ничего не меняет
+	if event == nil {
+		return nil
+	}
+
+	log.Printf("[VaultService] Пользователь %d успешно откатил хранилище %d к версии %d (новая версия %d)",
+		userID, restored.VaultID, versionID, event.VersionID)
+	s.metrics.VersionCreated(VersionReasonRollback)
+	s.publishEvent(*event)
+	return nil
+}
+
+// rollbackInTx выполняет откат в транзакции и возвращает событие о созданной версии (nil, если откат
+// не требуется) и восстановленную версию. Доставки вебхуков ставятся в очередь в этой же транзакции.
+func (s *vaultService) rollbackInTx(
+	ctx context.Context,
+	repos repository.TxRepositories,
+	userID int64,
+	versionID int64,
+) (*models.VaultEvent, *models.VaultVersion, error) {
+	// 1. Найти и заблокировать хранилище пользователя
+	vault, _, err := repos.Vaults.LockVaultWithCurrentVersionByUserID(ctx, userID)
 	if err != nil {
 		if errors.Is(err, repository.ErrVaultNotFound) {
 			log.Printf("[VaultService] Попытка отката: хранилище для пользователя %d не найдено", userID)
-			return ErrVaultNotFound
+			return nil, nil, ErrVaultNotFound
 		}
 		log.Printf("[VaultService] Ошибка поиска хранилища для отката (пользователь %d): %v", userID, err)
-		return errors.New("внутренняя ошибка сервера")
+		return nil, nil, errors.New("внутренняя ошибка сервера")
 	}
 
 	// 2. Проверить, что указанная версия принадлежит этому хранилищу
-	version, err := s.vaultVersionRepo.GetVersionByID(ctx, versionID)
+	version, err := repos.Versions.GetVersionByID(ctx, versionID)
 	if err != nil {
 		if errors.Is(err, repository.ErrVersionNotFound) {
 			log.Printf("[VaultService] Попытка отката: версия %d не найдена (пользователь %d)", versionID, userID)
-			return ErrVersionNotFound // А возвращаем ошибку сервиса
+			return nil, nil, ErrVersionNotFound // А возвращаем ошибку сервиса
 		}
 		log.Printf("[VaultService] Ошибка поиска версии %d для отката (пользователь %d): %v", versionID, userID, err)
-		return errors.New("внутренняя ошибка сервера")
+		return nil, nil, errors.New("внутренняя ошибка сервера")
 	}
 	if version.VaultID != vault.ID {
 		log.Printf("[VaultService] Попытка отката: версия %d не принадлежит хранилищу %d"+
 			" (пользователь %d)", versionID, vault.ID, userID)
-		return ErrForbidden // Другая ошибка: попытка доступа к чужой версии
+		return nil, nil, ErrForbidden // Другая ошибка: попытка доступа к чужой версии
+	}
+
+	// Откат к уже текущей версии ничего не меняет
+	if vault.CurrentVersionID != nil && *vault.CurrentVersionID == versionID {
+		log.Printf("[VaultService] Откат не требуется: версия %d уже текущая (пользователь %d)", versionID, userID)
+		return nil, version, nil
+	}
+
+	// 3. Создать новую версию, ссылающуюся на объект восстанавливаемой версии.
+	// История остается линейной, а время изменения контента - время отката, поэтому загрузка
+	// копии, измененной до отката, будет отклонена как конфликт.
+	restoredAt := time.Now().UTC()
+	newVersion := &models.VaultVersion{
+		VaultID:               vault.ID,
+		ObjectKey:             version.ObjectKey,
+		Checksum:              version.Checksum,
+		SizeBytes:             version.SizeBytes,
+		ContentModifiedAt:     &restoredAt,
+		RestoredFromVersionID: &version.ID,
+		KdbxVersion:           version.KdbxVersion,
+		KdbxCipher:            version.KdbxCipher,
+		KdbxKDF:               version.KdbxKDF,
+	}
+	newVersionID, err := repos.Versions.CreateVersion(ctx, newVersion)
+	if err != nil {
+		log.Printf("[VaultService] Ошибка создания версии при откате к версии %d"+
+			" для хранилища %d (пользователь %d): %v", versionID, vault.ID, userID, err)
+		return nil, nil, errors.New("внутренняя ошибка сервера при откате")
 	}
 
-	// 3. Обновить current_version_id в хранилище
-	err = s.vaultRepo.UpdateVaultCurrentVersion(ctx, vault.ID, versionID)
+	// 4. Сделать новую версию текущей
+	err = repos.Vaults.UpdateVaultCurrentVersion(ctx, vault.ID, newVersionID)
 	if err != nil {
 		// Обрабатываем случай, если хранилище вдруг не нашлось (хотя мы его только что нашли)
 		if errors.Is(err, repository.ErrVaultNotFound) {
 			log.Printf("[VaultService] Ошибка отката: хранилище %d исчезло во время обновления?"+
 				" (пользователь %d)", vault.ID, userID)
-			return ErrVaultNotFound
+			return nil, nil, ErrVaultNotFound
 		}
 		log.Printf("[VaultService] Ошибка обновления current_version_id при откате"+
 			" для хранилища %d (пользователь %d): %v", vault.ID, userID, err)
-		return errors.New("внутренняя ошибка сервера при откате")
+		return nil, nil, errors.New("внутренняя ошибка сервера при откате")
 	}
 
-	log.Printf("[VaultService] Пользователь %d успешно откатил хранилище %d к версии %d", userID, vault.ID, versionID)
-	return nil
+	event := newVaultEvent(models.VaultEventRolledBack, userID, newVersionID, version.Checksum)
+	if err = enqueueWebhookEvent(ctx, repos.Webhooks, *event); err != nil {
+		return nil, nil, errors.New("внутренняя ошибка сервера при откате")
+	}
+	return event, version, nil
+}
+
+// newVaultEvent создает событие об изменении хранилища с текущим временем.
+func newVaultEvent(eventType string, userID, versionID int64, checksum *string) *models.VaultEvent {
+	return &models.VaultEvent{
+		Type:      eventType,
+		UserID:    userID,
+		VersionID: versionID,
+		Checksum:  checksum,
+		CreatedAt: time.Now().UTC(),
+	}
 }
 
+// publishEvent публикует событие об изменении хранилища подписчикам после коммита.
+// Ошибка публикации не влияет на результат операции: клиенты все равно увидят
+// новую версию при следующей синхронизации.
+func (s *vaultService) publishEvent(event models.VaultEvent) {
+	if s.publisher == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), eventPublishTimeout)
+	defer cancel()
+	if err := s.publisher.Publish(ctx, event); err != nil {
+		log.Printf("[VaultService] Ошибка публикации события %s для пользователя %d: %v", event.Type, event.UserID, err)
+	}
+}
+
+// eventPublishTimeout - таймаут публикации события об изменении хранилища.
+const eventPublishTimeout = 5 * time.Second
+
 // Кастомные ошибки сервиса.
 var (
 	ErrVaultNotFound   = errors.New("хранилище или его версия не найдены")
 	ErrVersionNotFound = errors.New("указанная версия хранилища не найдена")
 	ErrForbidden       = errors.New("доступ запрещен") // Общая ошибка доступа
 	ErrConflictVersion = errors.New("конфликт версий")
+	// ErrInvalidVaultFile - загружаемый файл не является базой KeePass поддерживаемой версии или обрезан.
+	ErrInvalidVaultFile = errors.New("некорректный файл хранилища")
 )
@@ -1,6 +1,7 @@
 package services_test
 
 import (
+	"bytes"
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
@@ -11,8 +12,9 @@ import (
 	"testing"
 	"time"
 
-	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/maynagashev/gophkeeper/models"
+	"github.com/maynagashev/gophkeeper/server/internal/kdbx"
+	"github.com/maynagashev/gophkeeper/server/internal/kdbx/kdbxtest"
 	"github.com/maynagashev/gophkeeper/server/internal/mocks"
 	"github.com/maynagashev/gophkeeper/server/internal/repository"
 	"github.com/maynagashev/gophkeeper/server/internal/services"
@@ -139,26 +141,78 @@ func (m *MockFileStorage) DownloadFile(ctx context.Context, objectKey string) (i
 	return ret.(io.ReadCloser), args.Error(1)
 }
 
+func (m *MockFileStorage) DownloadFileRange(
+	ctx context.Context,
+	objectKey string,
+	offset, length int64,
+) (io.ReadCloser, error) {
+	args := m.Called(ctx, objectKey, offset, length)
+	ret := args.Get(0)
+	if ret == nil {
+		return nil, args.Error(1)
+	}
+	//nolint:errcheck // Ошибки кастования в моках приемлемы
+	return ret.(io.ReadCloser), args.Error(1)
+}
+
+func (m *MockFileStorage) Health(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func (m *MockFileStorage) DeleteFile(ctx context.Context, objectKey string) error {
+	args := m.Called(ctx, objectKey)
+	return args.Error(0)
+}
+
 // --- Helper to setup service with mocks ---.
 func setupVaultServiceWithMocks() (
 	services.VaultService,
 	*mocks.VaultRepository,
 	*mocks.VaultVersionRepository,
 	*mocks.FileStorage,
-	sqlmock.Sqlmock,
+	*mocks.Transactor,
 ) {
 	mockVaultRepo := new(mocks.VaultRepository)
 	mockVersionRepo := new(mocks.VaultVersionRepository)
 	mockFileStorage := new(mocks.FileStorage)
+	mockTx := new(mocks.Transactor)
 
-	mockDB, mockSQL, err := sqlmock.New()
-	if err != nil {
-		panic(fmt.Sprintf("Не удалось создать sqlmock: %s", err))
-	}
+	vaultService := services.NewVaultService(mockTx, mockVaultRepo, mockVersionRepo, mockFileStorage, nil,
+		services.Timeouts{}, nil)
 
-	vaultService := services.NewVaultService(mockDB, mockVaultRepo, mockVersionRepo, mockFileStorage)
+	return vaultService, mockVaultRepo, mockVersionRepo, mockFileStorage, mockTx
+}
 
-	return vaultService, mockVaultRepo, mockVersionRepo, mockFileStorage, mockSQL
+// expectTx ожидает одну транзакцию: fn выполняется с репозиториями-моками, а ее ошибка
+// возвращается из WithTx, как и в настоящей реализации (после отката транзакции).
+// Постановка доставок вебхуков в очередь в такой транзакции всегда успешна.
+func expectTx(
+	mockTx *mocks.Transactor,
+	mockVaultRepo *mocks.VaultRepository,
+	mockVersionRepo *mocks.VaultVersionRepository,
+) {
+	mockWebhookRepo := new(mocks.WebhookRepository)
+	mockWebhookRepo.EXPECT().EnqueueDeliveries(mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(int64(1), nil).Maybe()
+	expectTxWithWebhooks(mockTx, mockVaultRepo, mockVersionRepo, mockWebhookRepo)
+}
+
+// expectTxWithWebhooks ожидает одну транзакцию с заданным репозиторием вебхуков.
+func expectTxWithWebhooks(
+	mockTx *mocks.Transactor,
+	mockVaultRepo *mocks.VaultRepository,
+	mockVersionRepo *mocks.VaultVersionRepository,
+	mockWebhookRepo *mocks.WebhookRepository,
+) {
+	mockTx.EXPECT().WithTx(mock.Anything, mock.Anything).
+		RunAndReturn(func(_ context.Context, fn func(repository.TxRepositories) error) error {
+			return fn(repository.TxRepositories{
+				Vaults:   mockVaultRepo,
+				Versions: mockVersionRepo,
+				Webhooks: mockWebhookRepo,
+			})
+		}).Once()
 }
 
 // --- Tests ---
@@ -241,10 +295,10 @@ func TestVaultService_GetVaultMetadata(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			vaultService, mockVaultRepo, mockVersionRepo, _, mockSQL := setupVaultServiceWithMocks()
+			vaultService, mockVaultRepo, mockVersionRepo, _, mockTx := setupVaultServiceWithMocks()
 			tt.mockSetup(mockVaultRepo, mockVersionRepo)
 
-			metadata, err := vaultService.GetVaultMetadata(testUserID)
+			metadata, err := vaultService.GetVaultMetadata(context.Background(), testUserID)
 
 			// Проверяем ошибку, если она ожидается
 			if tt.expectedErr != nil {
@@ -255,7 +309,7 @@ func TestVaultService_GetVaultMetadata(t *testing.T) {
 					require.EqualError(err, tt.expectedErr.Error())
 				}
 				assert.Nil(metadata)
-				require.NoError(mockSQL.ExpectationsWereMet(), "Ожидания sqlmock не выполнены")
+				mockTx.AssertExpectations(t)
 				return
 			}
 
@@ -281,8 +335,8 @@ func TestVaultService_GetVaultMetadata(t *testing.T) {
 			}
 			assert.Equal(tt.expectedMetadata, metadata)
 
-			// Проверяем, что все ожидания моков были выполнены
-			require.NoError(mockSQL.ExpectationsWereMet(), "Ожидания sqlmock не выполнены")
+			// Проверяем, что все ожидания моков были выполнены (чтение метаданных идет без транзакции)
+			mockTx.AssertExpectations(t)
 			mockVaultRepo.AssertExpectations(t)
 			mockVersionRepo.AssertExpectations(t)
 		})
@@ -300,7 +354,7 @@ func TestVaultService_UploadVault(t *testing.T) {
 	testModTimeOlder := testModTime.Add(-time.Hour) // Время старой версии на сервере
 	testSize := int64(1234)
 	testContentType := "application/octet-stream"
-	testData := "test data"
+	testData := string(kdbxtest.File(4, int(testSize)))
 
 	// Вычисляем чексумму для тестовых данных
 	h := sha256.New()
@@ -313,6 +367,7 @@ func TestVaultService_UploadVault(t *testing.T) {
 			v.Checksum != nil && // Проверяем, что чексумма не nil
 			v.SizeBytes != nil && *v.SizeBytes == testSize &&
 			v.ContentModifiedAt != nil && v.ContentModifiedAt.Equal(testModTime) &&
+			v.KdbxVersion != nil && *v.KdbxVersion == "4.1" && v.KdbxKDF != nil && *v.KdbxKDF == "Argon2id" &&
 			strings.HasPrefix(v.ObjectKey, fmt.Sprintf("user_%d/vault_", testUserID))
 	})
 
@@ -323,7 +378,7 @@ func TestVaultService_UploadVault(t *testing.T) {
 			mockVaultRepo *mocks.VaultRepository,
 			mockVersionRepo *mocks.VaultVersionRepository,
 			mockFileStorage *mocks.FileStorage,
-			mockSQL sqlmock.Sqlmock,
+			mockTx *mocks.Transactor,
 		)
 		expectedErr      error
 		checkErrorIs     bool
@@ -336,19 +391,19 @@ func TestVaultService_UploadVault(t *testing.T) {
 				mockVaultRepo *mocks.VaultRepository,
 				mockVersionRepo *mocks.VaultVersionRepository,
 				mockFileStorage *mocks.FileStorage,
-				mockSQL sqlmock.Sqlmock,
+				mockTx *mocks.Transactor,
 			) {
 				// 1. Загрузка файла
 				mockFileStorage.EXPECT().
 					UploadFile(mock.Anything, mock.AnythingOfType("string"), mock.Anything, testSize, testContentType).
 					Return(nil).Once()
 
-				// 2. Начало транзакции
-				mockSQL.ExpectBegin()
+				// 2. Транзакция с блокировкой хранилища
+				expectTx(mockTx, mockVaultRepo, mockVersionRepo)
 
 				// 3. Проверка существования хранилища (для проверки конфликта)
 				mockVaultRepo.EXPECT().
-					GetVaultWithCurrentVersionByUserID(mock.Anything, testUserID).
+					LockVaultWithCurrentVersionByUserID(mock.Anything, testUserID).
 					Return(nil, nil, repository.ErrVaultNotFound).Once()
 
 				// 4. Создание нового хранилища
@@ -365,9 +420,6 @@ func TestVaultService_UploadVault(t *testing.T) {
 				mockVaultRepo.EXPECT().
 					UpdateVaultCurrentVersion(mock.Anything, testVaultID, testVersionID).
 					Return(nil).Once()
-
-				// 7. Завершение транзакции
-				mockSQL.ExpectCommit()
 			},
 			expectedErr: nil,
 		},
@@ -378,15 +430,15 @@ func TestVaultService_UploadVault(t *testing.T) {
 				mockVaultRepo *mocks.VaultRepository,
 				mockVersionRepo *mocks.VaultVersionRepository,
 				mockFileStorage *mocks.FileStorage,
-				mockSQL sqlmock.Sqlmock,
+				mockTx *mocks.Transactor,
 			) {
 				// 1. Загрузка файла
 				mockFileStorage.EXPECT().
 					UploadFile(mock.Anything, mock.AnythingOfType("string"), mock.Anything, testSize, testContentType).
 					Return(nil).Once()
 
-				// 2. Начало транзакции
-				mockSQL.ExpectBegin()
+				// 2. Транзакция с блокировкой хранилища
+				expectTx(mockTx, mockVaultRepo, mockVersionRepo)
 
 				// 3. Получение существующего хранилища и текущей версии
 				mockExistingVault := &models.Vault{ID: testVaultID, UserID: testUserID}
@@ -398,7 +450,7 @@ func TestVaultService_UploadVault(t *testing.T) {
 				}
 
 				mockVaultRepo.EXPECT().
-					GetVaultWithCurrentVersionByUserID(mock.Anything, testUserID).
+					LockVaultWithCurrentVersionByUserID(mock.Anything, testUserID).
 					Return(mockExistingVault, mockExistingVersion, nil).Once()
 
 				// 4. Создание новой версии
@@ -410,9 +462,6 @@ func TestVaultService_UploadVault(t *testing.T) {
 				mockVaultRepo.EXPECT().
 					UpdateVaultCurrentVersion(mock.Anything, testVaultID, testVersionID).
 					Return(nil).Once()
-
-				// 6. Завершение транзакции
-				mockSQL.ExpectCommit()
 			},
 			expectedErr: nil,
 		},
@@ -421,17 +470,17 @@ func TestVaultService_UploadVault(t *testing.T) {
 			clientModTime: testModTimeOlder, // Время клиента СТАРШЕ версии на сервере
 			mockSetup: func(
 				mockVaultRepo *mocks.VaultRepository,
-				_ *mocks.VaultVersionRepository,
+				mockVersionRepo *mocks.VaultVersionRepository,
 				mockFileStorage *mocks.FileStorage,
-				mockSQL sqlmock.Sqlmock,
+				mockTx *mocks.Transactor,
 			) {
 				// 1. Загрузка файла (всегда происходит сначала)
 				mockFileStorage.EXPECT().
 					UploadFile(mock.Anything, mock.AnythingOfType("string"), mock.Anything, testSize, testContentType).
 					Return(nil).Once()
 
-				// 2. Начало транзакции
-				mockSQL.ExpectBegin()
+				// 2. Транзакция с блокировкой хранилища
+				expectTx(mockTx, mockVaultRepo, mockVersionRepo)
 
 				// 3. Проверка метаданных для обнаружения конфликта
 				mockExistingVault := &models.Vault{ID: testVaultID, UserID: testUserID}
@@ -442,11 +491,8 @@ func TestVaultService_UploadVault(t *testing.T) {
 					Checksum:          &serverChecksum,
 				}
 				mockVaultRepo.EXPECT().
-					GetVaultWithCurrentVersionByUserID(mock.Anything, testUserID).
+					LockVaultWithCurrentVersionByUserID(mock.Anything, testUserID).
 					Return(mockExistingVault, mockExistingVersion, nil).Once()
-
-				// 4. Откат транзакции из-за ошибки
-				mockSQL.ExpectRollback()
 			},
 			expectedErr:      services.ErrConflictVersion,
 			checkErrorIs:     true,
@@ -457,17 +503,17 @@ func TestVaultService_UploadVault(t *testing.T) {
 			clientModTime: testModTime, // Время клиента совпадает
 			mockSetup: func(
 				mockVaultRepo *mocks.VaultRepository,
-				_ *mocks.VaultVersionRepository,
+				mockVersionRepo *mocks.VaultVersionRepository,
 				mockFileStorage *mocks.FileStorage,
-				mockSQL sqlmock.Sqlmock,
+				mockTx *mocks.Transactor,
 			) {
 				// 1. Загрузка файла
 				mockFileStorage.EXPECT().
 					UploadFile(mock.Anything, mock.AnythingOfType("string"), mock.Anything, testSize, testContentType).
 					Return(nil).Once()
 
-				// 2. Начало транзакции
-				mockSQL.ExpectBegin()
+				// 2. Транзакция с блокировкой хранилища
+				expectTx(mockTx, mockVaultRepo, mockVersionRepo)
 
 				// 3. Проверка метаданных для обнаружения конфликта
 				mockExistingVault := &models.Vault{ID: testVaultID, UserID: testUserID}
@@ -478,11 +524,8 @@ func TestVaultService_UploadVault(t *testing.T) {
 					Checksum:          &serverChecksum,
 				}
 				mockVaultRepo.EXPECT().
-					GetVaultWithCurrentVersionByUserID(mock.Anything, testUserID).
+					LockVaultWithCurrentVersionByUserID(mock.Anything, testUserID).
 					Return(mockExistingVault, mockExistingVersion, nil).Once()
-
-				// 4. Транзакция откатывается из-за ошибки конфликта
-				mockSQL.ExpectRollback()
 			},
 			expectedErr:      services.ErrConflictVersion,
 			checkErrorIs:     true,
@@ -493,9 +536,9 @@ func TestVaultService_UploadVault(t *testing.T) {
 			clientModTime: testModTime, // Время клиента совпадает
 			mockSetup: func(
 				mockVaultRepo *mocks.VaultRepository,
-				_ *mocks.VaultVersionRepository,
+				mockVersionRepo *mocks.VaultVersionRepository,
 				mockFileStorage *mocks.FileStorage,
-				mockSQL sqlmock.Sqlmock,
+				mockTx *mocks.Transactor,
 			) {
 				// Мы должны эмулировать поведение uploadFileToStorage, где сначала загружается файл,
 				// а потом используется полученная чексумма.
@@ -509,8 +552,8 @@ func TestVaultService_UploadVault(t *testing.T) {
 						_, _ = io.ReadAll(reader) // Это важно! Мы должны прочитать, чтобы хеш вычислился
 					}).Once()
 
-				// 2. Начало транзакции
-				mockSQL.ExpectBegin()
+				// 2. Транзакция с блокировкой хранилища
+				expectTx(mockTx, mockVaultRepo, mockVersionRepo)
 
 				// 3. Проверка метаданных - версии идентичны, поэтому мы должны
 				// вернуть существующую версию с такой же контрольной суммой
@@ -518,7 +561,7 @@ func TestVaultService_UploadVault(t *testing.T) {
 				modTimeServer := testModTime
 
 				// Задаем ту же контрольную сумму, что будет вычислена при пустых данных
-				// В нашем случае в тесте строка будет "test data"
+				// В нашем случае в тесте это файл testData
 				mockExistingVersion := &models.VaultVersion{
 					ID:                testVersionID,
 					VaultID:           testVaultID,
@@ -527,14 +570,53 @@ func TestVaultService_UploadVault(t *testing.T) {
 				}
 
 				mockVaultRepo.EXPECT().
-					GetVaultWithCurrentVersionByUserID(mock.Anything, testUserID).
+					LockVaultWithCurrentVersionByUserID(mock.Anything, testUserID).
 					Return(mockExistingVault, mockExistingVersion, nil).Once()
-
-				// 4. Транзакция должна закоммититься
-				mockSQL.ExpectCommit()
 			},
 			expectedErr: nil, // Ошибки нет, просто пропускаем
 		},
+		{
+			name:          "Конфликт - Хранилище создано параллельной загрузкой",
+			clientModTime: testModTime,
+			mockSetup: func(
+				mockVaultRepo *mocks.VaultRepository,
+				mockVersionRepo *mocks.VaultVersionRepository,
+				mockFileStorage *mocks.FileStorage,
+				mockTx *mocks.Transactor,
+			) {
+				mockFileStorage.EXPECT().
+					UploadFile(mock.Anything, mock.AnythingOfType("string"), mock.Anything, testSize, testContentType).
+					Return(nil).Once()
+				expectTx(mockTx, mockVaultRepo, mockVersionRepo)
+				mockVaultRepo.EXPECT().
+					LockVaultWithCurrentVersionByUserID(mock.Anything, testUserID).
+					Return(nil, nil, repository.ErrVaultNotFound).Once()
+				// Другое устройство создало хранилище, пока эта транзакция ждала блокировки
+				mockVaultRepo.EXPECT().
+					CreateVault(mock.Anything, mock.AnythingOfType("*models.Vault")).
+					Return(int64(0), repository.ErrVaultAlreadyExists).Once()
+			},
+			expectedErr:      services.ErrConflictVersion,
+			checkErrorIs:     true,
+			expectedConflict: true,
+		},
+		{
+			name:          "Ошибка - Транзакция не зафиксирована",
+			clientModTime: testModTime,
+			mockSetup: func(
+				_ *mocks.VaultRepository,
+				_ *mocks.VaultVersionRepository,
+				mockFileStorage *mocks.FileStorage,
+				mockTx *mocks.Transactor,
+			) {
+				mockFileStorage.EXPECT().
+					UploadFile(mock.Anything, mock.AnythingOfType("string"), mock.Anything, testSize, testContentType).
+					Return(nil).Once()
+				mockTx.EXPECT().WithTx(mock.Anything, mock.Anything).
+					Return(errors.New("ошибка коммита транзакции")).Once()
+			},
+			expectedErr: errors.New("внутренняя ошибка сервера"),
+		},
 		{
 			name:          "Ошибка - Загрузка в FileStorage",
 			clientModTime: testModTime,
@@ -542,7 +624,7 @@ func TestVaultService_UploadVault(t *testing.T) {
 				_ *mocks.VaultRepository,
 				_ *mocks.VaultVersionRepository,
 				mockFileStorage *mocks.FileStorage,
-				_ sqlmock.Sqlmock,
+				_ *mocks.Transactor,
 			) {
 				// 1. Ошибка при загрузке файла - файл не загружается, транзакция не начинается
 				mockFileStorage.EXPECT().
@@ -559,19 +641,23 @@ func TestVaultService_UploadVault(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Получаем моки
-			service, mockVaultRepo, mockVersionRepo, mockFileStorage, mockSQL := setupVaultServiceWithMocks()
+			service, mockVaultRepo, mockVersionRepo, mockFileStorage, mockTx := setupVaultServiceWithMocks()
 
-			tt.mockSetup(mockVaultRepo, mockVersionRepo, mockFileStorage, mockSQL)
+			tt.mockSetup(mockVaultRepo, mockVersionRepo, mockFileStorage, mockTx)
 
 			// Строка данных для тестирования
 			currentReader := strings.NewReader(testData)
 
 			// Вызываем метод сервиса
-			err := service.UploadVault(testUserID, currentReader, testSize, testContentType, tt.clientModTime)
+			version, err := service.UploadVault(
+				context.Background(), testUserID, currentReader, testSize, testContentType, tt.clientModTime,
+				models.UploadOrigin{},
+			)
 
 			// Проверяем результат
 			if tt.expectedErr != nil {
 				require.Error(err)
+				assert.Nil(version)
 				if tt.checkErrorIs {
 					require.ErrorIs(err, tt.expectedErr)
 				} else {
@@ -579,21 +665,100 @@ func TestVaultService_UploadVault(t *testing.T) {
 				}
 			} else {
 				require.NoError(err)
+				// Возвращается созданная версия, а при идентичной загрузке - текущая
+				require.NotNil(version)
+				assert.Equal(testVersionID, version.ID)
 			}
 
 			// Проверяем, что все ожидания моков были выполнены
 			mockVaultRepo.AssertExpectations(t)
 			mockVersionRepo.AssertExpectations(t)
 			mockFileStorage.AssertExpectations(t)
-			// Проверяем ожидания sqlmock только если не было ошибки загрузки файла
-			// или если ошибка nil (успешный случай или пропуск)
-			if !tt.expectedConflict && (tt.expectedErr == nil || !strings.Contains(tt.expectedErr.Error(), "загрузке файла")) {
-				require.NoError(mockSQL.ExpectationsWereMet(), "Ожидания sqlmock не выполнены")
-			}
+			// При ошибке загрузки файла транзакция не начинается
+			mockTx.AssertExpectations(t)
+		})
+	}
+}
+
+// TestVaultService_UploadVaultInvalidFile проверяет, что файл, не являющийся базой KeePass
+// поддерживаемой версии, отклоняется до загрузки в хранилище.
+func TestVaultService_UploadVaultInvalidFile(t *testing.T) {
+	header := kdbxtest.Header(4)
+	tests := []struct {
+		name string
+		data []byte
+		size int64
+	}{
+		{name: "Не KDBX", data: bytes.Repeat([]byte("plain text "), 100), size: 1100},
+		{name: "Меньше минимального размера", data: header, size: int64(len(header))},
+		{name: "Обрезан после заголовка", data: kdbxtest.File(4, kdbx.MinFileSize), size: kdbx.MinFileSize},
+		{name: "KDBX 2.x", data: func() []byte {
+			file := kdbxtest.File(4, 1024)
+			file[10] = 2 // Старшая версия формата
+			return file
+		}(), size: 1024},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _, _, mockFileStorage, mockTx := setupVaultServiceWithMocks()
+
+			version, err := service.UploadVault(context.Background(), 1, bytes.NewReader(tt.data), tt.size,
+				"application/octet-stream", time.Now(), models.UploadOrigin{})
+
+			require.ErrorIs(t, err, services.ErrInvalidVaultFile)
+			assert.Nil(t, version)
+			mockFileStorage.AssertNotCalled(t, "UploadFile", mock.Anything, mock.Anything, mock.Anything,
+				mock.Anything, mock.Anything)
+			mockTx.AssertNotCalled(t, "WithTx", mock.Anything, mock.Anything)
 		})
 	}
 }
 
+// TestVaultService_UploadVaultOrigin проверяет, что сведения о клиенте и устройстве сохраняются
+// вместе с версией: пустые поля - как NULL, длинные значения обрезаются.
+func TestVaultService_UploadVaultOrigin(t *testing.T) {
+	const (
+		userID    = int64(1)
+		vaultID   = int64(10)
+		versionID = int64(101)
+	)
+	data := string(kdbxtest.File(4, 512))
+	origin := models.UploadOrigin{
+		ClientName: " gophkeeper-client ",
+		DeviceID:   strings.Repeat("d", models.MaxOriginFieldLength+10),
+		IPAddress:  "192.0.2.10",
+	}
+
+	service, vaultRepo, versionRepo, fileStorage, publisher, mockTx := setupVaultServiceWithPublisher(t)
+	fileStorage.EXPECT().UploadFile(mock.Anything, mock.Anything, mock.Anything, int64(len(data)), mock.Anything).
+		Return(nil).Once()
+	expectTx(mockTx, vaultRepo, versionRepo)
+	vaultRepo.EXPECT().LockVaultWithCurrentVersionByUserID(mock.Anything, userID).
+		Return(&models.Vault{ID: vaultID, UserID: userID}, nil, nil).Once()
+	var saved *models.VaultVersion
+	versionRepo.EXPECT().CreateVersion(mock.Anything, mock.Anything).
+		RunAndReturn(func(_ context.Context, v *models.VaultVersion) (int64, error) {
+			saved = v
+			return versionID, nil
+		}).Once()
+	vaultRepo.EXPECT().UpdateVaultCurrentVersion(mock.Anything, vaultID, versionID).Return(nil).Once()
+	publisher.EXPECT().Publish(mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	_, err := service.UploadVault(context.Background(), userID, strings.NewReader(data), int64(len(data)),
+		"application/octet-stream", time.Now(), origin)
+
+	require.NoError(t, err)
+	require.NotNil(t, saved)
+	require.NotNil(t, saved.ClientName)
+	assert.Equal(t, "gophkeeper-client", *saved.ClientName)
+	assert.Nil(t, saved.ClientVersion)
+	require.NotNil(t, saved.DeviceID)
+	assert.Equal(t, strings.Repeat("d", models.MaxOriginFieldLength), *saved.DeviceID)
+	require.NotNil(t, saved.UploaderIP)
+	assert.Equal(t, "192.0.2.10", *saved.UploaderIP)
+}
+
 // TestVaultService_DownloadVault проверяет функциональность скачивания текущей версии хранилища.
 func TestVaultService_DownloadVault(t *testing.T) {
 	assert := assert.New(t)
@@ -753,7 +918,7 @@ func TestVaultService_DownloadVault(t *testing.T) {
 			tt.mockSetup(mockVaultRepo, mockVersionRepo, mockFileStorage)
 
 			// Вызываем тестируемый метод
-			reader, metadata, err := service.DownloadVault(testUserID)
+			reader, metadata, err := service.DownloadVault(context.Background(), testUserID)
 
 			// Проверяем результат
 			if tt.expectedErr != nil {
@@ -792,6 +957,45 @@ func TestVaultService_DownloadVault(t *testing.T) {
 }
 
 // TestVaultService_ListVersions проверяет функциональность получения списка версий хранилища.
+func TestVaultService_DownloadVersion(t *testing.T) {
+	checksum := "0000000000000000000000000000000000000000000000000000000000000000"
+	version := &models.VaultVersion{ID: 5, VaultID: 1, ObjectKey: "user_1/vault.kdbx", Checksum: &checksum}
+
+	t.Run("Диапазон запрашивается у хранилища без проверки контрольной суммы", func(t *testing.T) {
+		service, _, _, mockFileStorage, _ := setupVaultServiceWithMocks()
+		mockFileStorage.EXPECT().DownloadFileRange(mock.Anything, version.ObjectKey, int64(3), int64(4)).
+			Return(io.NopCloser(strings.NewReader("part")), nil).Once()
+
+		reader, err := service.DownloadVersion(context.Background(), version, 3, 4)
+		require.NoError(t, err)
+		data, err := io.ReadAll(reader)
+		require.NoError(t, err, "Сумма части файла не сверяется с суммой всего файла")
+		assert.Equal(t, "part", string(data))
+	})
+
+	t.Run("Файл целиком проверяется по контрольной сумме", func(t *testing.T) {
+		service, _, mockVersionRepo, mockFileStorage, _ := setupVaultServiceWithMocks()
+		mockFileStorage.EXPECT().DownloadFile(mock.Anything, version.ObjectKey).
+			Return(io.NopCloser(strings.NewReader("content")), nil).Once()
+		mockVersionRepo.EXPECT().UpdateVerificationStatus(mock.Anything, version.ID,
+			models.VerificationStatusCorrupted, mock.Anything).Return(nil).Once()
+
+		reader, err := service.DownloadVersion(context.Background(), version, 0, -1)
+		require.NoError(t, err)
+		_, err = io.ReadAll(reader)
+		require.ErrorIs(t, err, services.ErrChecksumMismatch)
+	})
+
+	t.Run("Объект не найден", func(t *testing.T) {
+		service, _, _, mockFileStorage, _ := setupVaultServiceWithMocks()
+		mockFileStorage.EXPECT().DownloadFileRange(mock.Anything, version.ObjectKey, int64(10), int64(-1)).
+			Return(nil, storage.ErrObjectNotFound).Once()
+
+		_, err := service.DownloadVersion(context.Background(), version, 10, -1)
+		require.ErrorIs(t, err, services.ErrVaultNotFound)
+	})
+}
+
 func TestVaultService_ListVersions(t *testing.T) {
 	assert := assert.New(t)
 	require := require.New(t)
@@ -883,7 +1087,7 @@ func TestVaultService_ListVersions(t *testing.T) {
 			tt.mockSetup(mockVaultRepo, mockVersionRepo)
 
 			// Вызываем тестируемый метод
-			versions, err := service.ListVersions(testUserID, testLimit, testOffset)
+			versions, err := service.ListVersions(context.Background(), testUserID, testLimit, testOffset)
 
 			// Проверяем результат
 			if tt.expectedErr != nil {
@@ -920,6 +1124,7 @@ func TestVaultService_RollbackToVersion(t *testing.T) {
 	testUserID := int64(1)
 	testVaultID := int64(101)
 	testVersionID := int64(201)
+	testRestoredVersionID := int64(202)
 
 	tests := []struct {
 		name         string
@@ -935,21 +1140,67 @@ func TestVaultService_RollbackToVersion(t *testing.T) {
 			) {
 				// Настраиваем мок репозитория хранилища
 				mockVaultRepo.EXPECT().
-					GetVaultByUserID(mock.Anything, testUserID).
-					Return(&models.Vault{ID: testVaultID, UserID: testUserID}, nil).Once()
+					LockVaultWithCurrentVersionByUserID(mock.Anything, testUserID).
+					Return(&models.Vault{ID: testVaultID, UserID: testUserID}, nil, nil).Once()
 
 				// Настраиваем мок репозитория версий
 				mockVersionRepo.EXPECT().
 					GetVersionByID(mock.Anything, testVersionID).
 					Return(&models.VaultVersion{ID: testVersionID, VaultID: testVaultID}, nil).Once()
 
-				// Ожидаем обновление текущей версии
+				// Ожидаем создание новой версии со ссылкой на восстановленную
+				mockVersionRepo.EXPECT().
+					CreateVersion(mock.Anything, mock.MatchedBy(func(v *models.VaultVersion) bool {
+						return v.VaultID == testVaultID && v.RestoredFromVersionID != nil &&
+							*v.RestoredFromVersionID == testVersionID && v.ContentModifiedAt != nil
+					})).
+					Return(testRestoredVersionID, nil).Once()
+
+				// Ожидаем обновление текущей версии на новую
 				mockVaultRepo.EXPECT().
-					UpdateVaultCurrentVersion(mock.Anything, testVaultID, testVersionID).
+					UpdateVaultCurrentVersion(mock.Anything, testVaultID, testRestoredVersionID).
 					Return(nil).Once()
 			},
 			expectedErr: nil,
 		},
+		{
+			name: "Версия уже текущая",
+			mockSetup: func(
+				mockVaultRepo *mocks.VaultRepository,
+				mockVersionRepo *mocks.VaultVersionRepository,
+			) {
+				currentVersionID := testVersionID
+				mockVaultRepo.EXPECT().
+					LockVaultWithCurrentVersionByUserID(mock.Anything, testUserID).
+					Return(&models.Vault{ID: testVaultID, UserID: testUserID, CurrentVersionID: &currentVersionID}, nil, nil).
+					Once()
+
+				mockVersionRepo.EXPECT().
+					GetVersionByID(mock.Anything, testVersionID).
+					Return(&models.VaultVersion{ID: testVersionID, VaultID: testVaultID}, nil).Once()
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "Ошибка при создании версии",
+			mockSetup: func(
+				mockVaultRepo *mocks.VaultRepository,
+				mockVersionRepo *mocks.VaultVersionRepository,
+			) {
+				mockVaultRepo.EXPECT().
+					LockVaultWithCurrentVersionByUserID(mock.Anything, testUserID).
+					Return(&models.Vault{ID: testVaultID, UserID: testUserID}, nil, nil).Once()
+
+				mockVersionRepo.EXPECT().
+					GetVersionByID(mock.Anything, testVersionID).
+					Return(&models.VaultVersion{ID: testVersionID, VaultID: testVaultID}, nil).Once()
+
+				mockVersionRepo.EXPECT().
+					CreateVersion(mock.Anything, mock.Anything).
+					Return(int64(0), errors.New("db error")).Once()
+			},
+			expectedErr: errors.New("внутренняя ошибка сервера при откате"),
+		},
 		{
 			name: "Хранилище не найдено",
 			mockSetup: func(
@@ -957,8 +1208,8 @@ func TestVaultService_RollbackToVersion(t *testing.T) {
 				_ *mocks.VaultVersionRepository,
 			) {
 				mockVaultRepo.EXPECT().
-					GetVaultByUserID(mock.Anything, testUserID).
-					Return(nil, repository.ErrVaultNotFound).Once()
+					LockVaultWithCurrentVersionByUserID(mock.Anything, testUserID).
+					Return(nil, nil, repository.ErrVaultNotFound).Once()
 			},
 			expectedErr:  services.ErrVaultNotFound,
 			checkErrorIs: true,
@@ -970,8 +1221,8 @@ func TestVaultService_RollbackToVersion(t *testing.T) {
 				_ *mocks.VaultVersionRepository,
 			) {
 				mockVaultRepo.EXPECT().
-					GetVaultByUserID(mock.Anything, testUserID).
-					Return(nil, errors.New("db error")).Once()
+					LockVaultWithCurrentVersionByUserID(mock.Anything, testUserID).
+					Return(nil, nil, errors.New("db error")).Once()
 			},
 			expectedErr: errors.New("внутренняя ошибка сервера"),
 		},
@@ -982,8 +1233,8 @@ func TestVaultService_RollbackToVersion(t *testing.T) {
 				mockVersionRepo *mocks.VaultVersionRepository,
 			) {
 				mockVaultRepo.EXPECT().
-					GetVaultByUserID(mock.Anything, testUserID).
-					Return(&models.Vault{ID: testVaultID, UserID: testUserID}, nil).Once()
+					LockVaultWithCurrentVersionByUserID(mock.Anything, testUserID).
+					Return(&models.Vault{ID: testVaultID, UserID: testUserID}, nil, nil).Once()
 
 				mockVersionRepo.EXPECT().
 					GetVersionByID(mock.Anything, testVersionID).
@@ -999,8 +1250,8 @@ func TestVaultService_RollbackToVersion(t *testing.T) {
 				mockVersionRepo *mocks.VaultVersionRepository,
 			) {
 				mockVaultRepo.EXPECT().
-					GetVaultByUserID(mock.Anything, testUserID).
-					Return(&models.Vault{ID: testVaultID, UserID: testUserID}, nil).Once()
+					LockVaultWithCurrentVersionByUserID(mock.Anything, testUserID).
+					Return(&models.Vault{ID: testVaultID, UserID: testUserID}, nil, nil).Once()
 
 				mockVersionRepo.EXPECT().
 					GetVersionByID(mock.Anything, testVersionID).
@@ -1015,8 +1266,8 @@ func TestVaultService_RollbackToVersion(t *testing.T) {
 				mockVersionRepo *mocks.VaultVersionRepository,
 			) {
 				mockVaultRepo.EXPECT().
-					GetVaultByUserID(mock.Anything, testUserID).
-					Return(&models.Vault{ID: testVaultID, UserID: testUserID}, nil).Once()
+					LockVaultWithCurrentVersionByUserID(mock.Anything, testUserID).
+					Return(&models.Vault{ID: testVaultID, UserID: testUserID}, nil, nil).Once()
 
 				mockVersionRepo.EXPECT().
 					GetVersionByID(mock.Anything, testVersionID).
@@ -1032,15 +1283,19 @@ func TestVaultService_RollbackToVersion(t *testing.T) {
 				mockVersionRepo *mocks.VaultVersionRepository,
 			) {
 				mockVaultRepo.EXPECT().
-					GetVaultByUserID(mock.Anything, testUserID).
-					Return(&models.Vault{ID: testVaultID, UserID: testUserID}, nil).Once()
+					LockVaultWithCurrentVersionByUserID(mock.Anything, testUserID).
+					Return(&models.Vault{ID: testVaultID, UserID: testUserID}, nil, nil).Once()
 
 				mockVersionRepo.EXPECT().
 					GetVersionByID(mock.Anything, testVersionID).
 					Return(&models.VaultVersion{ID: testVersionID, VaultID: testVaultID}, nil).Once()
 
+				mockVersionRepo.EXPECT().
+					CreateVersion(mock.Anything, mock.Anything).
+					Return(testRestoredVersionID, nil).Once()
+
 				mockVaultRepo.EXPECT().
-					UpdateVaultCurrentVersion(mock.Anything, testVaultID, testVersionID).
+					UpdateVaultCurrentVersion(mock.Anything, testVaultID, testRestoredVersionID).
 					Return(errors.New("db error")).Once()
 			},
 			expectedErr: errors.New("внутренняя ошибка сервера при откате"),
@@ -1050,11 +1305,13 @@ func TestVaultService_RollbackToVersion(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Настраиваем сервис с моками
-			service, mockVaultRepo, mockVersionRepo, _, _ := setupVaultServiceWithMocks()
+			service, mockVaultRepo, mockVersionRepo, _, mockTx := setupVaultServiceWithMocks()
+			// Откат всегда выполняется в одной транзакции
+			expectTx(mockTx, mockVaultRepo, mockVersionRepo)
 			tt.mockSetup(mockVaultRepo, mockVersionRepo)
 
 			// Вызываем тестируемый метод
-			err := service.RollbackToVersion(testUserID, testVersionID)
+			err := service.RollbackToVersion(context.Background(), testUserID, testVersionID)
 
 			// Проверяем результат
 			if tt.expectedErr != nil {
@@ -1071,8 +1328,65 @@ func TestVaultService_RollbackToVersion(t *testing.T) {
 			// Проверяем, что все ожидания моков были выполнены
 			mockVaultRepo.AssertExpectations(t)
 			mockVersionRepo.AssertExpectations(t)
+			mockTx.AssertExpectations(t)
 		})
 	}
 }
 
 // Add tests for ListVersions, DownloadVault, RollbackToVersion as needed
+
+// TestVaultService_Timeouts проверяет, что операции ограничены настроенными таймаутами и контекстом запроса.
+func TestVaultService_Timeouts(t *testing.T) {
+	// blockUntilDone имитирует зависшее хранилище, которое отвечает только после отмены контекста.
+	blockUntilDone := func(ctx context.Context, _ string, _ io.Reader, _ int64, _ string) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	data := string(kdbxtest.File(4, 512))
+
+	t.Run("Загрузка в хранилище не уложилась в таймаут", func(t *testing.T) {
+		mockFileStorage := new(mocks.FileStorage)
+		mockTx := new(mocks.Transactor)
+		service := services.NewVaultService(mockTx, new(mocks.VaultRepository), new(mocks.VaultVersionRepository),
+			mockFileStorage, nil, services.Timeouts{Storage: 10 * time.Millisecond}, nil)
+		mockFileStorage.EXPECT().
+			UploadFile(mock.Anything, mock.Anything, mock.Anything, int64(len(data)), "application/octet-stream").
+			RunAndReturn(blockUntilDone).Once()
+
+		_, err := service.UploadVault(context.Background(), 1, strings.NewReader(data), int64(len(data)),
+			"application/octet-stream", time.Now(), models.UploadOrigin{})
+		require.ErrorIs(t, err, services.ErrOperationTimeout)
+		mockTx.AssertNotCalled(t, "WithTx", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Отмена запроса прерывает загрузку без ошибки таймаута", func(t *testing.T) {
+		service, _, _, mockFileStorage, mockTx := setupVaultServiceWithMocks()
+		mockFileStorage.EXPECT().
+			UploadFile(mock.Anything, mock.Anything, mock.Anything, int64(len(data)), "application/octet-stream").
+			RunAndReturn(blockUntilDone).Once()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := service.UploadVault(ctx, 1, strings.NewReader(data), int64(len(data)), "application/octet-stream",
+			time.Now(), models.UploadOrigin{})
+		require.Error(t, err)
+		require.NotErrorIs(t, err, services.ErrOperationTimeout)
+		mockTx.AssertNotCalled(t, "WithTx", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Запрос к БД получает контекст с таймаутом", func(t *testing.T) {
+		mockVaultRepo := new(mocks.VaultRepository)
+		service := services.NewVaultService(new(mocks.Transactor), mockVaultRepo, new(mocks.VaultVersionRepository),
+			new(mocks.FileStorage), nil, services.Timeouts{DB: time.Millisecond}, nil)
+		mockVaultRepo.EXPECT().GetVaultWithCurrentVersionByUserID(mock.Anything, int64(1)).
+			RunAndReturn(func(ctx context.Context, _ int64) (*models.Vault, *models.VaultVersion, error) {
+				_, hasDeadline := ctx.Deadline()
+				assert.True(t, hasDeadline, "Контекст запроса к БД должен иметь дедлайн")
+				<-ctx.Done()
+				return nil, nil, ctx.Err()
+			}).Once()
+
+		_, err := service.GetVaultMetadata(context.Background(), 1)
+		require.ErrorIs(t, err, services.ErrOperationTimeout)
+	})
+}
@@ -0,0 +1,358 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/maynagashev/gophkeeper/models"
+	"github.com/maynagashev/gophkeeper/server/internal/events"
+	"github.com/maynagashev/gophkeeper/server/internal/repository"
+)
+
+// Заголовки исходящих запросов вебхуков.
+const (
+	WebhookSignatureHeader = "X-Gophkeeper-Signature" // "sha256=<hex HMAC-SHA256 тела запроса>"
+	WebhookEventHeader     = "X-Gophkeeper-Event"     // Тип события
+	WebhookDeliveryHeader  = "X-Gophkeeper-Delivery"  // ID доставки (одинаков для всех повторов)
+)
+
+const (
+	// webhookPollInterval - как часто проверять очередь доставок.
+	webhookPollInterval = 5 * time.Second
+	// webhookBatchSize - сколько доставок выбирается из очереди за один проход.
+	webhookBatchSize = 20
+	// webhookDeliveryTimeout - таймаут одного HTTP-запроса к получателю.
+	webhookDeliveryTimeout = 10 * time.Second
+	// webhookClaimLease - на сколько откладывается повтор выбранной доставки, пока она отправляется.
+	webhookClaimLease = time.Minute
+	// webhookMaxAttempts - после стольких неудачных попыток доставка помечается как failed.
+	webhookMaxAttempts = 8
+	// Параметры экспоненциальной задержки между попытками: 10s, 20s, 40s, ... но не более часа.
+	webhookBaseBackoff = 10 * time.Second
+	webhookMaxBackoff  = time.Hour
+	// webhookSecretBytes - длина генерируемого секрета в байтах.
+	webhookSecretBytes = 32
+	// webhookMaxErrorLength - сколько символов ошибки или тела ответа сохранять в истории доставок.
+	webhookMaxErrorLength = 512
+)
+
+// WebhookService определяет интерфейс управления вебхуками и доставки событий на них.
+type WebhookService interface {
+	// Publish ставит событие в очередь доставки на все активные вебхуки пользователя (см. events.Publisher),
+	// а Run отправляет накопившиеся доставки с повторами.
+	Publish(ctx context.Context, event models.VaultEvent) error
+	CreateWebhook(ctx context.Context, userID int64, rawURL, secret string) (*models.Webhook, error)
+	ListWebhooks(ctx context.Context, userID int64) ([]models.Webhook, error)
+	DeleteWebhook(ctx context.Context, userID, webhookID int64) error
+	ListDeliveries(ctx context.Context, userID, webhookID int64, limit, offset int) ([]models.WebhookDelivery, error)
+	// DeliverDue отправляет доставки, которым подошел срок, и возвращает их количество.
+	DeliverDue(ctx context.Context) (int, error)
+	// Run периодически вызывает DeliverDue до отмены контекста.
+	Run(ctx context.Context)
+}
+
+var (
+	_ WebhookService   = (*webhookService)(nil)
+	_ events.Publisher = (*webhookService)(nil)
+)
+
+// webhookService реализует WebhookService.
+type webhookService struct {
+	repo       repository.WebhookRepository
+	httpClient *http.Client
+	targets    webhookTargets
+	now        func() time.Time
+}
+
+// NewWebhookService создает сервис вебхуков.
+// Вебхуки во внутреннюю сеть (loopback, link-local, частные сети) запрещены, кроме сетей из allowedNetworks.
+// Если httpClient равен nil, используется клиент с таймаутом webhookDeliveryTimeout, который проверяет
+// адрес получателя при каждом подключении.
+func NewWebhookService(
+	repo repository.WebhookRepository,
+	httpClient *http.Client,
+	allowedNetworks []netip.Prefix,
+) WebhookService {
+	targets := webhookTargets{allowed: allowedNetworks}
+	if httpClient == nil {
+		httpClient = newWebhookHTTPClient(targets)
+	}
+	return &webhookService{
+		repo:       repo,
+		httpClient: httpClient,
+		targets:    targets,
+		now:        time.Now,
+	}
+}
+
+// CreateWebhook регистрирует вебхук пользователя. Если секрет не передан, он генерируется.
+// Секрет возвращается в ответе, чтобы получатель мог проверять подпись.
+func (s *webhookService) CreateWebhook(
+	ctx context.Context,
+	userID int64,
+	rawURL, secret string,
+) (*models.Webhook, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		log.Printf("[WebhookService] Некорректный URL вебхука от пользователя %d: '%s'", userID, rawURL)
+		return nil, fmt.Errorf("%w: требуется абсолютный http(s) адрес", ErrInvalidWebhookURL)
+	}
+	if err = s.targets.check(ctx, parsed.Hostname()); err != nil {
+		log.Printf("[WebhookService] Пользователь %d указал вебхук во внутреннюю сеть '%s': %v", userID, rawURL, err)
+		return nil, err
+	}
+
+	if secret == "" {
+		buf := make([]byte, webhookSecretBytes)
+		if _, err = rand.Read(buf); err != nil {
+			log.Printf("[WebhookService] Ошибка генерации секрета вебхука: %v", err)
+			return nil, errors.New("внутренняя ошибка сервера при генерации секрета")
+		}
+		secret = hex.EncodeToString(buf)
+	}
+
+	webhook := &models.Webhook{
+		UserID:    userID,
+		URL:       parsed.String(),
+		Secret:    secret,
+		IsActive:  true,
+		CreatedAt: s.now(),
+	}
+	webhook.ID, err = s.repo.CreateWebhook(ctx, webhook)
+	if err != nil {
+		log.Printf("[WebhookService] Ошибка сохранения вебхука пользователя %d: %v", userID, err)
+		return nil, errors.New("внутренняя ошибка сервера при сохранении вебхука")
+	}
+
+	log.Printf("[WebhookService] Пользователь %d зарегистрировал вебхук ID %d (%s)", userID, webhook.ID, webhook.URL)
+	return webhook, nil
+}
+
+// ListWebhooks возвращает вебхуки пользователя без секретов.
+func (s *webhookService) ListWebhooks(ctx context.Context, userID int64) ([]models.Webhook, error) {
+	webhooks, err := s.repo.ListWebhooksByUserID(ctx, userID)
+	if err != nil {
+		log.Printf("[WebhookService] Ошибка получения вебхуков пользователя %d: %v", userID, err)
+		return nil, errors.New("внутренняя ошибка сервера")
+	}
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
+	return webhooks, nil
+}
+
+// DeleteWebhook удаляет вебхук пользователя.
+func (s *webhookService) DeleteWebhook(ctx context.Context, userID, webhookID int64) error {
+	err := s.repo.DeleteWebhook(ctx, userID, webhookID)
+	if err != nil {
+		if errors.Is(err, repository.ErrWebhookNotFound) {
+			return ErrWebhookNotFound
+		}
+		log.Printf("[WebhookService] Ошибка удаления вебхука ID %d: %v", webhookID, err)
+		return errors.New("внутренняя ошибка сервера при удалении вебхука")
+	}
+	return nil
+}
+
+// ListDeliveries возвращает историю доставок вебхука, принадлежащего пользователю.
+func (s *webhookService) ListDeliveries(
+	ctx context.Context,
+	userID, webhookID int64,
+	limit, offset int,
+) ([]models.WebhookDelivery, error) {
+	if _, err := s.repo.GetWebhookByID(ctx, userID, webhookID); err != nil {
+		if errors.Is(err, repository.ErrWebhookNotFound) {
+			return nil, ErrWebhookNotFound
+		}
+		log.Printf("[WebhookService] Ошибка поиска вебхука ID %d: %v", webhookID, err)
+		return nil, errors.New("внутренняя ошибка сервера")
+	}
+
+	deliveries, err := s.repo.ListDeliveries(ctx, webhookID, limit, offset)
+	if err != nil {
+		log.Printf("[WebhookService] Ошибка получения истории доставок вебхука ID %d: %v", webhookID, err)
+		return nil, errors.New("внутренняя ошибка сервера")
+	}
+	return deliveries, nil
+}
+
+// Publish ставит событие в очередь доставки на все активные вебхуки пользователя.
+// Событие сохраняется в БД, поэтому доставка переживает перезапуск сервера.
+func (s *webhookService) Publish(ctx context.Context, event models.VaultEvent) error {
+	return enqueueWebhookEvent(ctx, s.repo, event)
+}
+
+// enqueueWebhookEvent ставит событие в очередь доставки через repo. Сервис хранилища передает
+// сюда репозиторий своей транзакции, чтобы доставки фиксировались вместе с изменением хранилища.
+func enqueueWebhookEvent(ctx context.Context, repo repository.WebhookRepository, event models.VaultEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("ошибка кодирования события: %w", err)
+	}
+
+	if _, err = repo.EnqueueDeliveries(ctx, event.UserID, event.Type, payload); err != nil {
+		log.Printf("[WebhookService] Ошибка постановки события %s пользователя %d в очередь: %v",
+			event.Type, event.UserID, err)
+		return fmt.Errorf("ошибка постановки события в очередь вебхуков: %w", err)
+	}
+	return nil
+}
+
+// Run запускает отправку доставок сразу и затем раз в webhookPollInterval.
+func (s *webhookService) Run(ctx context.Context) {
+	ticker := time.NewTicker(webhookPollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.DeliverDue(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("[WebhookService] Ошибка отправки вебхуков: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			log.Printf("[WebhookService] Отправка вебхуков остановлена")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeliverDue выбирает из очереди доставки, которым подошел срок, и отправляет их.
+// Выборка повторяется, пока очередь не опустеет.
+func (s *webhookService) DeliverDue(ctx context.Context) (int, error) {
+	delivered := 0
+	for {
+		now := s.now()
+		deliveries, err := s.repo.ClaimDueDeliveries(ctx, now, webhookBatchSize, now.Add(webhookClaimLease))
+		if err != nil {
+			return delivered, errors.New("ошибка выборки доставок вебхуков")
+		}
+		if len(deliveries) == 0 {
+			return delivered, nil
+		}
+
+		for i := range deliveries {
+			if ctx.Err() != nil {
+				return delivered, ctx.Err()
+			}
+			s.attemptDelivery(ctx, &deliveries[i])
+			if err = s.repo.UpdateDeliveryResult(ctx, &deliveries[i]); err != nil &&
+				!errors.Is(err, repository.ErrWebhookDeliveryNotFound) {
+				return delivered, errors.New("ошибка сохранения результата доставки вебхука")
+			}
+			delivered++
+		}
+	}
+}
+
+// attemptDelivery отправляет одну доставку и записывает результат попытки в delivery.
+func (s *webhookService) attemptDelivery(ctx context.Context, delivery *models.WebhookDelivery) {
+	attemptedAt := s.now()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &attemptedAt
+
+	statusCode, err := s.send(ctx, delivery)
+	if statusCode != 0 {
+		delivery.ResponseStatus = &statusCode
+	} else {
+		delivery.ResponseStatus = nil
+	}
+
+	if err == nil {
+		delivery.Status = models.WebhookDeliveryDelivered
+		delivery.DeliveredAt = &attemptedAt
+		delivery.LastError = nil
+		log.Printf("[WebhookService] Доставка ID %d (%s) на вебхук ID %d выполнена, статус %d",
+			delivery.ID, delivery.EventType, delivery.WebhookID, statusCode)
+		return
+	}
+
+	lastError := truncate(err.Error(), webhookMaxErrorLength)
+	delivery.LastError = &lastError
+	if delivery.Attempts >= webhookMaxAttempts {
+		delivery.Status = models.WebhookDeliveryFailed
+		log.Printf("[WebhookService] Доставка ID %d на вебхук ID %d не выполнена за %d попыток: %v",
+			delivery.ID, delivery.WebhookID, delivery.Attempts, err)
+		return
+	}
+	delivery.Status = models.WebhookDeliveryPending
+	delivery.NextAttemptAt = attemptedAt.Add(webhookBackoff(delivery.Attempts))
+	log.Printf("[WebhookService] Попытка %d доставки ID %d на вебхук ID %d неуспешна (%v), повтор в %v",
+		delivery.Attempts, delivery.ID, delivery.WebhookID, err, delivery.NextAttemptAt)
+}
+
+// send выполняет HTTP-запрос к получателю и возвращает код ответа.
+// Ошибкой считается любой ответ с кодом вне диапазона 2xx.
+func (s *webhookService) send(ctx context.Context, delivery *models.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("ошибка создания запроса: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "GophKeeper-Webhook/1.0")
+	req.Header.Set(WebhookEventHeader, delivery.EventType)
+	req.Header.Set(WebhookDeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(delivery.Secret, delivery.Payload))
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка отправки запроса: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, webhookMaxErrorLength))
+		return resp.StatusCode, fmt.Errorf("получатель ответил статусом %d: %s", resp.StatusCode, body)
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return resp.StatusCode, nil
+}
+
+// SignWebhookPayload вычисляет значение заголовка подписи для тела запроса вебхука.
+func SignWebhookPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookBackoff возвращает задержку перед следующей попыткой после attempts неудачных.
+func webhookBackoff(attempts int) time.Duration {
+	backoff := webhookBaseBackoff
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= webhookMaxBackoff {
+			return webhookMaxBackoff
+		}
+	}
+	return backoff
+}
+
+// truncate обрезает строку до maxLen байт, не разрывая многобайтовые символы.
+func truncate(s string, maxLen int) string {
+	if len(s) <= maxLen {
+		return s
+	}
+	return strings.ToValidUTF8(s[:maxLen], "")
+}
+
+// Ошибки сервиса вебхуков.
+var (
+	ErrInvalidWebhookURL = errors.New("некорректный URL вебхука")
+	ErrWebhookNotFound   = errors.New("вебхук не найден")
+	// ErrForbiddenWebhookTarget - подключение к получателю отклонено: адрес относится к внутренней сети.
+	ErrForbiddenWebhookTarget = errors.New("отправка вебхука во внутреннюю сеть запрещена")
+)
@@ -0,0 +1,319 @@
+package services_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/maynagashev/gophkeeper/models"
+	"github.com/maynagashev/gophkeeper/server/internal/mocks"
+	"github.com/maynagashev/gophkeeper/server/internal/repository"
+	"github.com/maynagashev/gophkeeper/server/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookService_CreateWebhook(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Некорректный URL", func(t *testing.T) {
+		service := services.NewWebhookService(mocks.NewWebhookRepository(t), nil, nil)
+
+		for _, rawURL := range []string{"", "not a url", "ftp://example.com/hook", "/relative/path"} {
+			_, err := service.CreateWebhook(ctx, 1, rawURL, "")
+			require.ErrorIs(t, err, services.ErrInvalidWebhookURL, rawURL)
+		}
+	})
+
+	t.Run("Адрес во внутренней сети", func(t *testing.T) {
+		service := services.NewWebhookService(mocks.NewWebhookRepository(t), nil, nil)
+
+		for _, rawURL := range []string{
+			"http://127.0.0.1:8080/hook",
+			"http://localhost/hook",
+			"http://[::1]/hook",
+			"http://169.254.169.254/latest/meta-data/",
+			"http://10.0.0.5/hook",
+			"http://172.16.3.4/hook",
+			"https://192.168.1.1/hook",
+			"http://[::ffff:192.168.1.1]/hook",
+			"http://0.0.0.0/hook",
+		} {
+			_, err := service.CreateWebhook(ctx, 1, rawURL, "")
+			require.ErrorIs(t, err, services.ErrInvalidWebhookURL, rawURL)
+			assert.Contains(t, err.Error(), "внутренн", rawURL)
+		}
+	})
+
+	t.Run("Разрешенная внутренняя сеть", func(t *testing.T) {
+		repo := mocks.NewWebhookRepository(t)
+		repo.EXPECT().CreateWebhook(ctx, mock.Anything).Return(int64(6), nil).Once()
+		allowed := []netip.Prefix{netip.MustParsePrefix("10.20.0.0/16")}
+
+		webhook, err := services.NewWebhookService(repo, nil, allowed).
+			CreateWebhook(ctx, 1, "http://10.20.1.2:8080/hook", "secret")
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(6), webhook.ID)
+	})
+
+	t.Run("Секрет генерируется, если не задан", func(t *testing.T) {
+		repo := mocks.NewWebhookRepository(t)
+		repo.EXPECT().CreateWebhook(ctx, mock.MatchedBy(func(w *models.Webhook) bool {
+			return w.UserID == 1 && w.URL == "https://example.com/hook" && len(w.Secret) == 64
+		})).Return(int64(5), nil).Once()
+
+		webhook, err := services.NewWebhookService(repo, nil, nil).CreateWebhook(ctx, 1, "https://example.com/hook", "")
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), webhook.ID)
+		assert.Len(t, webhook.Secret, 64, "Сгенерированный секрет возвращается при создании")
+		assert.True(t, webhook.IsActive)
+	})
+
+	t.Run("Ошибка репозитория", func(t *testing.T) {
+		repo := mocks.NewWebhookRepository(t)
+		repo.EXPECT().CreateWebhook(ctx, mock.Anything).Return(int64(0), errors.New("db error")).Once()
+
+		webhook, err := services.NewWebhookService(repo, nil, nil).CreateWebhook(ctx, 1, "http://example.com", "secret")
+
+		require.Error(t, err)
+		assert.Nil(t, webhook)
+	})
+}
+
+func TestWebhookService_ListWebhooks(t *testing.T) {
+	ctx := context.Background()
+	repo := mocks.NewWebhookRepository(t)
+	repo.EXPECT().ListWebhooksByUserID(ctx, int64(1)).Return([]models.Webhook{
+		{ID: 5, UserID: 1, URL: "https://example.com/hook", Secret: "secret", IsActive: true},
+	}, nil).Once()
+
+	webhooks, err := services.NewWebhookService(repo, nil, nil).ListWebhooks(ctx, 1)
+
+	require.NoError(t, err)
+	require.Len(t, webhooks, 1)
+	assert.Empty(t, webhooks[0].Secret, "Секрет не должен возвращаться в списке")
+}
+
+func TestWebhookService_DeleteWebhook(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Вебхук не найден", func(t *testing.T) {
+		repo := mocks.NewWebhookRepository(t)
+		repo.EXPECT().DeleteWebhook(ctx, int64(1), int64(5)).Return(repository.ErrWebhookNotFound).Once()
+
+		err := services.NewWebhookService(repo, nil, nil).DeleteWebhook(ctx, 1, 5)
+
+		require.ErrorIs(t, err, services.ErrWebhookNotFound)
+	})
+
+	t.Run("Успешное удаление", func(t *testing.T) {
+		repo := mocks.NewWebhookRepository(t)
+		repo.EXPECT().DeleteWebhook(ctx, int64(1), int64(5)).Return(nil).Once()
+
+		require.NoError(t, services.NewWebhookService(repo, nil, nil).DeleteWebhook(ctx, 1, 5))
+	})
+}
+
+func TestWebhookService_ListDeliveries(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Чужой или несуществующий вебхук", func(t *testing.T) {
+		repo := mocks.NewWebhookRepository(t)
+		repo.EXPECT().GetWebhookByID(ctx, int64(1), int64(5)).Return(nil, repository.ErrWebhookNotFound).Once()
+
+		deliveries, err := services.NewWebhookService(repo, nil, nil).ListDeliveries(ctx, 1, 5, 20, 0)
+
+		require.ErrorIs(t, err, services.ErrWebhookNotFound)
+		assert.Nil(t, deliveries)
+	})
+
+	t.Run("Успешное получение", func(t *testing.T) {
+		repo := mocks.NewWebhookRepository(t)
+		repo.EXPECT().GetWebhookByID(ctx, int64(1), int64(5)).Return(&models.Webhook{ID: 5, UserID: 1}, nil).Once()
+		repo.EXPECT().ListDeliveries(ctx, int64(5), 20, 0).
+			Return([]models.WebhookDelivery{{ID: 10, WebhookID: 5}}, nil).Once()
+
+		deliveries, err := services.NewWebhookService(repo, nil, nil).ListDeliveries(ctx, 1, 5, 20, 0)
+
+		require.NoError(t, err)
+		assert.Len(t, deliveries, 1)
+	})
+}
+
+func TestWebhookService_Publish(t *testing.T) {
+	ctx := context.Background()
+	checksum := "abc"
+	event := models.VaultEvent{Type: models.VaultEventVersionCreated, UserID: 1, VersionID: 7, Checksum: &checksum}
+	payload, err := json.Marshal(event)
+	require.NoError(t, err)
+
+	repo := mocks.NewWebhookRepository(t)
+	repo.EXPECT().EnqueueDeliveries(ctx, int64(1), models.VaultEventVersionCreated, payload).Return(int64(2), nil).Once()
+
+	require.NoError(t, services.NewWebhookService(repo, nil, nil).Publish(ctx, event))
+}
+
+// webhookReceiver - локальный получатель вебхуков для тестов.
+type webhookReceiver struct {
+	server   *httptest.Server
+	status   atomic.Int32
+	requests atomic.Int32
+	lastBody atomic.Value
+	lastSig  atomic.Value
+	lastType atomic.Value
+}
+
+func newWebhookReceiver(t *testing.T, status int) *webhookReceiver {
+	t.Helper()
+	rcv := &webhookReceiver{}
+	rcv.status.Store(int32(status))
+	rcv.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		rcv.requests.Add(1)
+		rcv.lastBody.Store(body)
+		rcv.lastSig.Store(r.Header.Get(services.WebhookSignatureHeader))
+		rcv.lastType.Store(r.Header.Get(services.WebhookEventHeader))
+		w.WriteHeader(int(rcv.status.Load()))
+	}))
+	t.Cleanup(rcv.server.Close)
+	return rcv
+}
+
+// loopbackNetworks разрешает отправку на тестовые получатели httptest, которые слушают loopback.
+var loopbackNetworks = []netip.Prefix{netip.MustParsePrefix("127.0.0.0/8"), netip.MustParsePrefix("::1/128")}
+
+func TestWebhookService_DeliverDue(t *testing.T) {
+	ctx := context.Background()
+	secret := "s3cr3t"
+	payload := json.RawMessage(`{"type":"rolled_back","user_id":1,"version_id":3}`)
+
+	newDelivery := func(url string, attempts int) models.WebhookDelivery {
+		return models.WebhookDelivery{
+			ID: 10, WebhookID: 5, EventType: models.VaultEventRolledBack, Payload: payload,
+			Status: models.WebhookDeliveryPending, Attempts: attempts, URL: url, Secret: secret,
+		}
+	}
+	// expectClaim настраивает выдачу одной доставки и затем пустой очереди.
+	expectClaim := func(repo *mocks.WebhookRepository, delivery models.WebhookDelivery) {
+		repo.EXPECT().ClaimDueDeliveries(ctx, mock.Anything, mock.Anything, mock.Anything).
+			Return([]models.WebhookDelivery{delivery}, nil).Once()
+		repo.EXPECT().ClaimDueDeliveries(ctx, mock.Anything, mock.Anything, mock.Anything).
+			Return(nil, nil).Once()
+	}
+
+	t.Run("Успешная доставка с подписью", func(t *testing.T) {
+		rcv := newWebhookReceiver(t, http.StatusNoContent)
+		repo := mocks.NewWebhookRepository(t)
+		expectClaim(repo, newDelivery(rcv.server.URL, 0))
+		var saved models.WebhookDelivery
+		repo.EXPECT().UpdateDeliveryResult(ctx, mock.Anything).
+			Run(func(_ context.Context, d *models.WebhookDelivery) { saved = *d }).Return(nil).Once()
+
+		count, err := services.NewWebhookService(repo, nil, loopbackNetworks).DeliverDue(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+		assert.Equal(t, int32(1), rcv.requests.Load())
+		assert.JSONEq(t, string(payload), string(rcv.lastBody.Load().([]byte)))
+		assert.Equal(t, models.VaultEventRolledBack, rcv.lastType.Load())
+
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(payload)
+		assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), rcv.lastSig.Load(),
+			"Подпись должна быть HMAC-SHA256 тела запроса")
+
+		assert.Equal(t, models.WebhookDeliveryDelivered, saved.Status)
+		assert.Equal(t, 1, saved.Attempts)
+		require.NotNil(t, saved.ResponseStatus)
+		assert.Equal(t, http.StatusNoContent, *saved.ResponseStatus)
+		assert.NotNil(t, saved.DeliveredAt)
+		assert.Nil(t, saved.LastError)
+	})
+
+	t.Run("Ошибка получателя откладывает повтор с экспоненциальной задержкой", func(t *testing.T) {
+		rcv := newWebhookReceiver(t, http.StatusInternalServerError)
+		repo := mocks.NewWebhookRepository(t)
+		expectClaim(repo, newDelivery(rcv.server.URL, 2))
+		var saved models.WebhookDelivery
+		repo.EXPECT().UpdateDeliveryResult(ctx, mock.Anything).
+			Run(func(_ context.Context, d *models.WebhookDelivery) { saved = *d }).Return(nil).Once()
+
+		before := time.Now()
+		_, err := services.NewWebhookService(repo, nil, loopbackNetworks).DeliverDue(ctx)
+		after := time.Now()
+
+		require.NoError(t, err)
+		assert.Equal(t, models.WebhookDeliveryPending, saved.Status)
+		assert.Equal(t, 3, saved.Attempts)
+		require.NotNil(t, saved.ResponseStatus)
+		assert.Equal(t, http.StatusInternalServerError, *saved.ResponseStatus)
+		require.NotNil(t, saved.LastError)
+		assert.Contains(t, *saved.LastError, "500")
+		// Третья неудачная попытка: 10s * 2^2 = 40s
+		assert.WithinRange(t, saved.NextAttemptAt, before.Add(40*time.Second), after.Add(40*time.Second))
+	})
+
+	t.Run("Недоступный получатель после последней попытки помечается как failed", func(t *testing.T) {
+		rcv := newWebhookReceiver(t, http.StatusOK)
+		url := rcv.server.URL
+		rcv.server.Close() // Получатель недоступен
+
+		repo := mocks.NewWebhookRepository(t)
+		expectClaim(repo, newDelivery(url, 7))
+		var saved models.WebhookDelivery
+		repo.EXPECT().UpdateDeliveryResult(ctx, mock.Anything).
+			Run(func(_ context.Context, d *models.WebhookDelivery) { saved = *d }).Return(nil).Once()
+
+		_, err := services.NewWebhookService(repo, nil, loopbackNetworks).DeliverDue(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, models.WebhookDeliveryFailed, saved.Status)
+		assert.Equal(t, 8, saved.Attempts)
+		assert.Nil(t, saved.ResponseStatus)
+		require.NotNil(t, saved.LastError)
+	})
+
+	t.Run("Подключение к внутреннему адресу отклоняется при отправке", func(t *testing.T) {
+		rcv := newWebhookReceiver(t, http.StatusOK)
+		// Имя разрешается в loopback уже после регистрации вебхука (как при DNS rebinding)
+		url := strings.Replace(rcv.server.URL, "127.0.0.1", "localhost", 1)
+
+		repo := mocks.NewWebhookRepository(t)
+		expectClaim(repo, newDelivery(url, 0))
+		var saved models.WebhookDelivery
+		repo.EXPECT().UpdateDeliveryResult(ctx, mock.Anything).
+			Run(func(_ context.Context, d *models.WebhookDelivery) { saved = *d }).Return(nil).Once()
+
+		_, err := services.NewWebhookService(repo, nil, nil).DeliverDue(ctx)
+
+		require.NoError(t, err)
+		assert.Zero(t, rcv.requests.Load(), "Запрос во внутреннюю сеть не должен отправляться")
+		assert.Equal(t, models.WebhookDeliveryPending, saved.Status)
+		require.NotNil(t, saved.LastError)
+		assert.Contains(t, *saved.LastError, services.ErrForbiddenWebhookTarget.Error())
+	})
+
+	t.Run("Ошибка выборки из очереди", func(t *testing.T) {
+		repo := mocks.NewWebhookRepository(t)
+		repo.EXPECT().ClaimDueDeliveries(ctx, mock.Anything, mock.Anything, mock.Anything).
+			Return(nil, errors.New("db error")).Once()
+
+		_, err := services.NewWebhookService(repo, nil, loopbackNetworks).DeliverDue(ctx)
+
+		require.Error(t, err)
+	})
+}
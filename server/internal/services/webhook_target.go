@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+)
+
+// internalNetworks - сети, которые не покрываются методами netip.Addr, но тоже недоступны извне.
+var internalNetworks = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),     // "Этот" хост
+	netip.MustParsePrefix("100.64.0.0/10"), // Shared Address Space (CGNAT, служебные адреса облаков)
+}
+
+// webhookTargets решает, на какие адреса разрешено отправлять вебхуки. Адреса loopback,
+// link-local (в том числе 169.254.169.254 - метаданные облака), частных сетей RFC 1918 и ULA
+// запрещены, чтобы вебхук нельзя было направить во внутреннюю сеть сервера (SSRF).
+// Сети из allowed разрешены, даже если они внутренние.
+type webhookTargets struct {
+	allowed []netip.Prefix
+}
+
+// permits сообщает, разрешена ли отправка вебхука на адрес addr.
+func (t webhookTargets) permits(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, network := range t.allowed {
+		if network.Contains(addr) {
+			return true
+		}
+	}
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() || addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return false
+	}
+	for _, network := range internalNetworks {
+		if network.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// check проверяет хост URL вебхука при регистрации: IP-адрес проверяется сразу, а имя -
+// по адресам, в которые оно разрешается сейчас. Если имя не разрешается, регистрация не
+// отклоняется: адрес все равно проверяется при каждом подключении (см. dialContext).
+func (t webhookTargets) check(ctx context.Context, host string) error {
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if !t.permits(addr) {
+			return fmt.Errorf("%w: адрес %s относится к внутренней сети", ErrInvalidWebhookURL, addr)
+		}
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		log.Printf("[WebhookService] Не удалось разрешить имя %s при регистрации вебхука: %v", host, err)
+		return nil
+	}
+	for _, addr := range addrs {
+		if !t.permits(addr) {
+			return fmt.Errorf("%w: имя %s разрешается во внутренний адрес %s", ErrInvalidWebhookURL, host, addr.Unmap())
+		}
+	}
+	return nil
+}
+
+// control проверяет адрес непосредственно перед подключением. Так запрет действует и для имен,
+// которые после регистрации вебхука стали разрешаться во внутренний адрес (DNS rebinding), и для
+// перенаправлений.
+func (t webhookTargets) control(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("некорректный адрес получателя вебхука %s: %w", address, err)
+	}
+	if !t.permits(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrForbiddenWebhookTarget, addrPort.Addr().Unmap())
+	}
+	return nil
+}
+
+// newWebhookHTTPClient создает клиент для отправки вебхуков, который не подключается к запрещенным адресам.
+// Прокси из окружения не используется: иначе проверялся бы адрес прокси, а не получателя.
+func newWebhookHTTPClient(targets webhookTargets) *http.Client {
+	dialer := &net.Dialer{Timeout: webhookDeliveryTimeout, Control: targets.control}
+	transport := http.DefaultTransport.(*http.Transport).Clone() //nolint:errcheck // Тип DefaultTransport известен
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: webhookDeliveryTimeout, Transport: transport}
+}
@@ -0,0 +1,507 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+)
+
+// Параметры формата зашифрованного объекта.
+//
+// Объект состоит из заголовка (магия, размер блока, префикс nonce) и последовательности
+// блоков AES-GCM. Nonce блока = префикс (7 байт) + номер блока (4 байта) + флаг последнего блока (1 байт),
+// поэтому перестановка, удаление или усечение блоков обнаруживаются при расшифровке.
+const (
+	encMagic           = "GKENC1"
+	encChunkSize       = 64 * 1024
+	encNoncePrefixSize = 7
+	encHeaderSize      = len(encMagic) + 4 + encNoncePrefixSize
+	encTagSize         = 16
+	encNonceSize       = 12
+	dataKeySize        = 32
+
+	// keyObjectSuffix - суффикс объекта-спутника с обернутым ключом данных.
+	keyObjectSuffix = ".dek"
+	// keyObjectContentType - тип содержимого объекта-спутника.
+	keyObjectContentType = "application/json"
+	// envelopeAlgorithm - идентификатор алгоритма, записываемый в объект-спутник.
+	envelopeAlgorithm = "AES-256-GCM"
+)
+
+// wrappedKey - содержимое объекта-спутника: ключ данных, обернутый мастер-ключом.
+// Хранится отдельно от данных, чтобы ротация мастер-ключа не требовала перезаписи хранилищ.
+type wrappedKey struct {
+	KeyVersion int    `json:"key_version"`
+	Algorithm  string `json:"alg"`
+	WrappedKey []byte `json:"wrapped_key"` // nonce + шифротекст ключа данных
+}
+
+// Убедимся, что EncryptedStorage удовлетворяет интерфейсу FileStorage.
+var _ FileStorage = (*EncryptedStorage)(nil)
+
+// EncryptedStorage - декоратор FileStorage, шифрующий объекты на стороне сервера.
+// Каждый объект шифруется собственным ключом данных, который оборачивается мастер-ключом.
+type EncryptedStorage struct {
+	inner   FileStorage
+	keyring *MasterKeyring
+}
+
+// NewEncryptedStorage создает шифрующий декоратор над указанным хранилищем.
+func NewEncryptedStorage(inner FileStorage, keyring *MasterKeyring) *EncryptedStorage {
+	return &EncryptedStorage{inner: inner, keyring: keyring}
+}
+
+// UploadFile шифрует поток и загружает его вместе с обернутым ключом данных.
+// Исходный reader читается до конца, поэтому вызывающая сторона может хешировать открытый текст.
+func (s *EncryptedStorage) UploadFile(
+	ctx context.Context,
+	objectKey string,
+	reader io.Reader,
+	size int64,
+	contentType string,
+) error {
+	dataKey := make([]byte, dataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return fmt.Errorf("ошибка генерации ключа данных: %w", err)
+	}
+
+	wrapped, err := s.wrapKey(objectKey, dataKey)
+	if err != nil {
+		return err
+	}
+
+	encReader, err := newEncryptingReader(reader, dataKey, []byte(objectKey))
+	if err != nil {
+		return err
+	}
+
+	// Сначала сохраняем ключ: объект без ключа расшифровать невозможно,
+	// а лишний ключ без объекта безвреден.
+	if err = s.putWrappedKey(ctx, objectKey, wrapped); err != nil {
+		return err
+	}
+
+	if err = s.inner.UploadFile(ctx, objectKey, encReader, encryptedSize(size), contentType); err != nil {
+		return err
+	}
+
+	log.Printf("[EncryptedStorage] Объект '%s' зашифрован (версия мастер-ключа %d)", objectKey, wrapped.KeyVersion)
+	return nil
+}
+
+// DownloadFile скачивает и расшифровывает объект.
+// Объекты, загруженные до включения шифрования (без объекта-спутника), отдаются как есть.
+func (s *EncryptedStorage) DownloadFile(ctx context.Context, objectKey string) (io.ReadCloser, error) {
+	wrapped, err := s.getWrappedKey(ctx, objectKey)
+	if err != nil {
+		if errors.Is(err, ErrObjectNotFound) {
+			log.Printf("[EncryptedStorage] Для объекта '%s' нет ключа данных, отдаем без расшифровки", objectKey)
+			return s.inner.DownloadFile(ctx, objectKey)
+		}
+		return nil, err
+	}
+	return s.openDecrypted(ctx, objectKey, wrapped)
+}
+
+// Health проверяет доступность нижележащего хранилища.
+func (s *EncryptedStorage) Health(ctx context.Context) error {
+	return s.inner.Health(ctx)
+}
+
+// DeleteFile удаляет объект вместе с обернутым ключом данных.
+// Объект удаляется первым: ключ без объекта безвреден, а объект без ключа расшифровать невозможно.
+func (s *EncryptedStorage) DeleteFile(ctx context.Context, objectKey string) error {
+	if err := s.inner.DeleteFile(ctx, objectKey); err != nil {
+		return err
+	}
+	return s.inner.DeleteFile(ctx, objectKey+keyObjectSuffix)
+}
+
+// DownloadFileRange скачивает и расшифровывает часть объекта.
+// Блоки AES-GCM аутентифицируются последовательно от заголовка, поэтому зашифрованный объект
+// расшифровывается с начала, а данные до offset отбрасываются. Незашифрованные объекты
+// запрашиваются у нижележащего хранилища диапазоном.
+func (s *EncryptedStorage) DownloadFileRange(
+	ctx context.Context,
+	objectKey string,
+	offset,
+	length int64,
+) (io.ReadCloser, error) {
+	if offset < 0 || length == 0 {
+		return nil, fmt.Errorf("некорректный диапазон: offset=%d, length=%d", offset, length)
+	}
+	wrapped, err := s.getWrappedKey(ctx, objectKey)
+	if err != nil {
+		if errors.Is(err, ErrObjectNotFound) {
+			return s.inner.DownloadFileRange(ctx, objectKey, offset, length)
+		}
+		return nil, err
+	}
+
+	body, err := s.openDecrypted(ctx, objectKey, wrapped)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = io.CopyN(io.Discard, body, offset); err != nil {
+		_ = body.Close()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("смещение %d за пределами объекта '%s': %w", offset, objectKey, err)
+		}
+		return nil, err
+	}
+	if length < 0 {
+		return body, nil
+	}
+	return &limitedReadCloser{Reader: io.LimitReader(body, length), Closer: body}, nil
+}
+
+// openDecrypted скачивает объект и возвращает поток расшифровки ключом данных из объекта-спутника.
+func (s *EncryptedStorage) openDecrypted(
+	ctx context.Context,
+	objectKey string,
+	wrapped *wrappedKey,
+) (io.ReadCloser, error) {
+	dataKey, err := s.unwrapKey(objectKey, wrapped)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := s.inner.DownloadFile(ctx, objectKey)
+	if err != nil {
+		return nil, err
+	}
+
+	decReader, err := newDecryptingReader(body, dataKey, []byte(objectKey))
+	if err != nil {
+		_ = body.Close()
+		return nil, err
+	}
+	return decReader, nil
+}
+
+// RewrapKey переоборачивает ключ данных объекта активной версией мастер-ключа.
+// Сами данные объекта не перезаписываются. Возвращает true, если ключ был обновлен.
+func (s *EncryptedStorage) RewrapKey(ctx context.Context, objectKey string) (bool, error) {
+	wrapped, err := s.getWrappedKey(ctx, objectKey)
+	if err != nil {
+		return false, err
+	}
+	if wrapped.KeyVersion == s.keyring.ActiveVersion() {
+		return false, nil
+	}
+
+	dataKey, err := s.unwrapKey(objectKey, wrapped)
+	if err != nil {
+		return false, err
+	}
+	rewrapped, err := s.wrapKey(objectKey, dataKey)
+	if err != nil {
+		return false, err
+	}
+	if err = s.putWrappedKey(ctx, objectKey, rewrapped); err != nil {
+		return false, err
+	}
+
+	log.Printf("[EncryptedStorage] Ключ объекта '%s' переобернут: версия %d -> %d",
+		objectKey, wrapped.KeyVersion, rewrapped.KeyVersion)
+	return true, nil
+}
+
+// RewrapKeys переоборачивает ключи всех переданных объектов.
+// Объекты без ключа данных (загруженные до включения шифрования) пропускаются.
+// Возвращает количество обновленных ключей.
+func (s *EncryptedStorage) RewrapKeys(ctx context.Context, objectKeys []string) (int, error) {
+	rewrapped := 0
+	for _, objectKey := range objectKeys {
+		if err := ctx.Err(); err != nil {
+			return rewrapped, err
+		}
+		updated, err := s.RewrapKey(ctx, objectKey)
+		if err != nil {
+			if errors.Is(err, ErrObjectNotFound) {
+				continue
+			}
+			return rewrapped, fmt.Errorf("ошибка переоборачивания ключа объекта '%s': %w", objectKey, err)
+		}
+		if updated {
+			rewrapped++
+		}
+	}
+	return rewrapped, nil
+}
+
+// wrapKey оборачивает ключ данных активной версией мастер-ключа.
+func (s *EncryptedStorage) wrapKey(objectKey string, dataKey []byte) (*wrappedKey, error) {
+	version := s.keyring.ActiveVersion()
+	masterKey, err := s.keyring.key(version)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newGCM(masterKey)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("ошибка генерации nonce: %w", err)
+	}
+	sealed := aead.Seal(nonce, nonce, dataKey, []byte(objectKey))
+	return &wrappedKey{KeyVersion: version, Algorithm: envelopeAlgorithm, WrappedKey: sealed}, nil
+}
+
+// unwrapKey разворачивает ключ данных мастер-ключом указанной в нем версии.
+func (s *EncryptedStorage) unwrapKey(objectKey string, wrapped *wrappedKey) ([]byte, error) {
+	if wrapped.Algorithm != envelopeAlgorithm {
+		return nil, fmt.Errorf("неподдерживаемый алгоритм обертки ключа '%s'", wrapped.Algorithm)
+	}
+	masterKey, err := s.keyring.key(wrapped.KeyVersion)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newGCM(masterKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(wrapped.WrappedKey) < aead.NonceSize() {
+		return nil, ErrDecryptionFailed
+	}
+	nonce, sealed := wrapped.WrappedKey[:aead.NonceSize()], wrapped.WrappedKey[aead.NonceSize():]
+	dataKey, err := aead.Open(nil, nonce, sealed, []byte(objectKey))
+	if err != nil {
+		return nil, fmt.Errorf("%w: ключ данных объекта '%s'", ErrDecryptionFailed, objectKey)
+	}
+	return dataKey, nil
+}
+
+// putWrappedKey сохраняет объект-спутник с обернутым ключом.
+func (s *EncryptedStorage) putWrappedKey(ctx context.Context, objectKey string, wrapped *wrappedKey) error {
+	data, err := json.Marshal(wrapped)
+	if err != nil {
+		return fmt.Errorf("ошибка кодирования обернутого ключа: %w", err)
+	}
+	return s.inner.UploadFile(ctx, objectKey+keyObjectSuffix, bytes.NewReader(data), int64(len(data)),
+		keyObjectContentType)
+}
+
+// getWrappedKey читает объект-спутник с обернутым ключом.
+func (s *EncryptedStorage) getWrappedKey(ctx context.Context, objectKey string) (*wrappedKey, error) {
+	body, err := s.inner.DownloadFile(ctx, objectKey+keyObjectSuffix)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	var wrapped wrappedKey
+	if err = json.NewDecoder(body).Decode(&wrapped); err != nil {
+		return nil, fmt.Errorf("ошибка чтения обернутого ключа объекта '%s': %w", objectKey, err)
+	}
+	return &wrapped, nil
+}
+
+// encryptedSize вычисляет размер зашифрованного объекта по размеру открытого текста.
+func encryptedSize(plainSize int64) int64 {
+	chunks := plainSize / encChunkSize
+	if plainSize%encChunkSize != 0 || plainSize == 0 {
+		chunks++
+	}
+	return int64(encHeaderSize) + plainSize + chunks*encTagSize
+}
+
+// newGCM создает AEAD AES-GCM для ключа.
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка инициализации AES: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка инициализации GCM: %w", err)
+	}
+	return aead, nil
+}
+
+// chunkNonce формирует nonce для блока.
+func chunkNonce(prefix []byte, counter uint32, last bool) []byte {
+	nonce := make([]byte, encNonceSize)
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint32(nonce[encNoncePrefixSize:], counter)
+	if last {
+		nonce[encNonceSize-1] = 1
+	}
+	return nonce
+}
+
+// encryptingReader шифрует поток блоками по мере чтения.
+type encryptingReader struct {
+	src     *bufio.Reader
+	aead    cipher.AEAD
+	prefix  []byte
+	aad     []byte
+	counter uint32
+	buf     bytes.Buffer
+	plain   []byte
+	done    bool
+}
+
+func newEncryptingReader(src io.Reader, dataKey, aad []byte) (*encryptingReader, error) {
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	prefix := make([]byte, encNoncePrefixSize)
+	if _, err = rand.Read(prefix); err != nil {
+		return nil, fmt.Errorf("ошибка генерации префикса nonce: %w", err)
+	}
+	r := &encryptingReader{
+		src:    bufio.NewReaderSize(src, encChunkSize),
+		aead:   aead,
+		prefix: prefix,
+		aad:    aad,
+		plain:  make([]byte, encChunkSize),
+	}
+	r.buf.WriteString(encMagic)
+	var sizeBuf [4]byte
+	binary.BigEndian.PutUint32(sizeBuf[:], encChunkSize)
+	r.buf.Write(sizeBuf[:])
+	r.buf.Write(prefix)
+	return r, nil
+}
+
+func (r *encryptingReader) Read(p []byte) (int, error) {
+	for r.buf.Len() == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.sealNext(); err != nil {
+			return 0, err
+		}
+	}
+	return r.buf.Read(p)
+}
+
+// sealNext читает очередной блок открытого текста и шифрует его.
+func (r *encryptingReader) sealNext() error {
+	n, err := io.ReadFull(r.src, r.plain)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return err
+	}
+	last := n < encChunkSize
+	if !last {
+		// Полный блок: последний ли он, узнаем, заглянув вперед
+		if _, peekErr := r.src.Peek(1); peekErr != nil {
+			if !errors.Is(peekErr, io.EOF) {
+				return peekErr
+			}
+			last = true
+		}
+	}
+	nonce := chunkNonce(r.prefix, r.counter, last)
+	r.buf.Write(r.aead.Seal(nil, nonce, r.plain[:n], r.aad))
+	r.counter++
+	r.done = last
+	return nil
+}
+
+// decryptingReader расшифровывает поток блоками по мере чтения.
+type decryptingReader struct {
+	body      io.ReadCloser
+	src       *bufio.Reader
+	aead      cipher.AEAD
+	prefix    []byte
+	aad       []byte
+	chunkSize int
+	counter   uint32
+	buf       bytes.Buffer
+	sealed    []byte
+	done      bool
+}
+
+func newDecryptingReader(body io.ReadCloser, dataKey, aad []byte) (*decryptingReader, error) {
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	src := bufio.NewReaderSize(body, encChunkSize+encTagSize)
+	header := make([]byte, encHeaderSize)
+	if _, err = io.ReadFull(src, header); err != nil {
+		return nil, fmt.Errorf("%w: не удалось прочитать заголовок", ErrDecryptionFailed)
+	}
+	if string(header[:len(encMagic)]) != encMagic {
+		return nil, fmt.Errorf("%w: неизвестный формат объекта", ErrDecryptionFailed)
+	}
+	chunkSize := int(binary.BigEndian.Uint32(header[len(encMagic):]))
+	if chunkSize <= 0 || chunkSize > encChunkSize*16 {
+		return nil, fmt.Errorf("%w: неверный размер блока %d", ErrDecryptionFailed, chunkSize)
+	}
+	return &decryptingReader{
+		body:      body,
+		src:       src,
+		aead:      aead,
+		prefix:    append([]byte(nil), header[len(encMagic)+4:]...),
+		aad:       aad,
+		chunkSize: chunkSize,
+		sealed:    make([]byte, chunkSize+encTagSize),
+	}, nil
+}
+
+func (r *decryptingReader) Read(p []byte) (int, error) {
+	for r.buf.Len() == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.openNext(); err != nil {
+			return 0, err
+		}
+	}
+	return r.buf.Read(p)
+}
+
+// openNext читает и расшифровывает очередной блок.
+func (r *decryptingReader) openNext() error {
+	n, err := io.ReadFull(r.src, r.sealed)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return err
+	}
+	last := n < len(r.sealed)
+	if !last {
+		if _, peekErr := r.src.Peek(1); peekErr != nil {
+			if !errors.Is(peekErr, io.EOF) {
+				return peekErr
+			}
+			last = true
+		}
+	}
+	plain, openErr := r.aead.Open(nil, chunkNonce(r.prefix, r.counter, last), r.sealed[:n], r.aad)
+	if openErr != nil {
+		return fmt.Errorf("%w: блок %d поврежден или усечен", ErrDecryptionFailed, r.counter)
+	}
+	r.buf.Write(plain)
+	r.counter++
+	r.done = last
+	return nil
+}
+
+func (r *decryptingReader) Close() error {
+	return r.body.Close()
+}
+
+// Ошибки шифрующего хранилища.
+var (
+	ErrDecryptionFailed  = errors.New("ошибка расшифровки объекта")
+	ErrUnknownKeyVersion = errors.New("неизвестная версия мастер-ключа")
+)
+
+// limitedReadCloser ограничивает чтение, сохраняя закрытие исходного потока.
+type limitedReadCloser struct {
+	io.Reader
+	io.Closer
+}
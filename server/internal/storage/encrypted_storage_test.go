@@ -0,0 +1,294 @@
+package storage_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/maynagashev/gophkeeper/server/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStorage - простая реализация FileStorage в памяти для тестов.
+type memoryStorage struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func newMemoryStorage() *memoryStorage {
+	return &memoryStorage{objects: make(map[string][]byte)}
+}
+
+func (m *memoryStorage) UploadFile(_ context.Context, objectKey string, reader io.Reader, size int64, _ string) error {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	if int64(len(data)) != size {
+		return io.ErrShortWrite
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.objects[objectKey] = data
+	return nil
+}
+
+func (m *memoryStorage) DownloadFile(_ context.Context, objectKey string) (io.ReadCloser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.objects[objectKey]
+	if !ok {
+		return nil, storage.ErrObjectNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (m *memoryStorage) Health(_ context.Context) error {
+	return nil
+}
+
+func (m *memoryStorage) DeleteFile(_ context.Context, objectKey string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.objects, objectKey)
+	return nil
+}
+
+func (m *memoryStorage) DownloadFileRange(
+	_ context.Context,
+	objectKey string,
+	offset, length int64,
+) (io.ReadCloser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.objects[objectKey]
+	if !ok {
+		return nil, storage.ErrObjectNotFound
+	}
+	data = data[min(offset, int64(len(data))):]
+	if length >= 0 && length < int64(len(data)) {
+		data = data[:length]
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func randomKey(t *testing.T) []byte {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	return key
+}
+
+func newTestKeyring(t *testing.T, keys map[int][]byte) *storage.MasterKeyring {
+	keyring, err := storage.NewMasterKeyring(keys)
+	require.NoError(t, err)
+	return keyring
+}
+
+func TestEncryptedStorage_RoundTrip(t *testing.T) {
+	sizes := []int{0, 1, 1000, 64 * 1024, 64*1024 + 1, 3*64*1024 - 7}
+	keyring := newTestKeyring(t, map[int][]byte{1: randomKey(t)})
+
+	for _, size := range sizes {
+		inner := newMemoryStorage()
+		enc := storage.NewEncryptedStorage(inner, keyring)
+		plain := make([]byte, size)
+		_, err := rand.Read(plain)
+		require.NoError(t, err)
+
+		objectKey := "user_1/vault.kdbx"
+		reader := bytes.NewReader(plain)
+		err = enc.UploadFile(context.Background(), objectKey, reader, int64(size), "application/octet-stream")
+		require.NoError(t, err, "размер %d", size)
+		assert.Equal(t, 0, reader.Len(), "исходный поток должен быть прочитан полностью")
+
+		// В хранилище не должно быть открытого текста
+		stored := inner.objects[objectKey]
+		if size > 0 {
+			assert.False(t, bytes.Contains(stored, plain), "объект хранится в открытом виде")
+		}
+		assert.Contains(t, inner.objects, objectKey+".dek")
+
+		body, err := enc.DownloadFile(context.Background(), objectKey)
+		require.NoError(t, err)
+		got, err := io.ReadAll(body)
+		require.NoError(t, err)
+		require.NoError(t, body.Close())
+		assert.Equal(t, plain, got, "размер %d", size)
+	}
+}
+
+func TestEncryptedStorage_DeleteFile(t *testing.T) {
+	keyring := newTestKeyring(t, map[int][]byte{1: randomKey(t)})
+	inner := newMemoryStorage()
+	enc := storage.NewEncryptedStorage(inner, keyring)
+	objectKey := "user_1/vault.kdbx"
+	require.NoError(t, enc.UploadFile(context.Background(), objectKey, strings.NewReader("data"), 4, "text/plain"))
+
+	require.NoError(t, enc.DeleteFile(context.Background(), objectKey))
+
+	// Удаляются и объект, и объект-спутник с ключом данных
+	assert.Empty(t, inner.objects)
+	_, err := enc.DownloadFile(context.Background(), objectKey)
+	require.ErrorIs(t, err, storage.ErrObjectNotFound)
+}
+
+func TestEncryptedStorage_DetectsTampering(t *testing.T) {
+	keyring := newTestKeyring(t, map[int][]byte{1: randomKey(t)})
+	plain := bytes.Repeat([]byte("kdbx"), 40000)
+
+	tests := []struct {
+		name   string
+		mutate func(data []byte) []byte
+	}{
+		{
+			name: "Изменен байт",
+			mutate: func(data []byte) []byte {
+				data[len(data)/2] ^= 0xFF
+				return data
+			},
+		},
+		{
+			name: "Усечен последний блок",
+			mutate: func(data []byte) []byte {
+				return data[:len(data)-10]
+			},
+		},
+		{
+			name: "Удален последний блок целиком",
+			mutate: func(data []byte) []byte {
+				return data[:17+64*1024+16]
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inner := newMemoryStorage()
+			enc := storage.NewEncryptedStorage(inner, keyring)
+			objectKey := "user_1/vault.kdbx"
+			require.NoError(t, enc.UploadFile(context.Background(), objectKey,
+				bytes.NewReader(plain), int64(len(plain)), "application/octet-stream"))
+
+			inner.objects[objectKey] = tt.mutate(inner.objects[objectKey])
+
+			body, err := enc.DownloadFile(context.Background(), objectKey)
+			require.NoError(t, err)
+			_, err = io.ReadAll(body)
+			require.ErrorIs(t, err, storage.ErrDecryptionFailed)
+		})
+	}
+
+	t.Run("Объект подменен объектом другого пользователя", func(t *testing.T) {
+		inner := newMemoryStorage()
+		enc := storage.NewEncryptedStorage(inner, keyring)
+		require.NoError(t, enc.UploadFile(context.Background(), "user_1/a.kdbx",
+			bytes.NewReader(plain), int64(len(plain)), "application/octet-stream"))
+		require.NoError(t, enc.UploadFile(context.Background(), "user_2/b.kdbx",
+			strings.NewReader("other"), 5, "application/octet-stream"))
+
+		inner.objects["user_2/b.kdbx"] = inner.objects["user_1/a.kdbx"]
+		inner.objects["user_2/b.kdbx.dek"] = inner.objects["user_1/a.kdbx.dek"]
+
+		_, err := enc.DownloadFile(context.Background(), "user_2/b.kdbx")
+		require.ErrorIs(t, err, storage.ErrDecryptionFailed)
+	})
+}
+
+func TestEncryptedStorage_LegacyPlaintextObject(t *testing.T) {
+	inner := newMemoryStorage()
+	inner.objects["user_1/old.kdbx"] = []byte("plain data")
+	enc := storage.NewEncryptedStorage(inner, newTestKeyring(t, map[int][]byte{1: randomKey(t)}))
+
+	body, err := enc.DownloadFile(context.Background(), "user_1/old.kdbx")
+	require.NoError(t, err)
+	got, err := io.ReadAll(body)
+	require.NoError(t, err)
+	assert.Equal(t, "plain data", string(got))
+
+	_, err = enc.DownloadFile(context.Background(), "user_1/missing.kdbx")
+	require.ErrorIs(t, err, storage.ErrObjectNotFound)
+}
+
+func TestEncryptedStorage_DownloadFileRange(t *testing.T) {
+	ctx := context.Background()
+	inner := newMemoryStorage()
+	enc := storage.NewEncryptedStorage(inner, newTestKeyring(t, map[int][]byte{1: randomKey(t)}))
+
+	plain := make([]byte, 2*64*1024+100)
+	_, err := rand.Read(plain)
+	require.NoError(t, err)
+	require.NoError(t, enc.UploadFile(ctx, "user_1/vault.kdbx", bytes.NewReader(plain), int64(len(plain)), ""))
+	inner.objects["user_1/old.kdbx"] = []byte("plain data")
+
+	tests := []struct {
+		name      string
+		objectKey string
+		offset    int64
+		length    int64
+		expected  []byte
+	}{
+		{"Диапазон внутри блока", "user_1/vault.kdbx", 10, 20, plain[10:30]},
+		{"Диапазон через границу блоков", "user_1/vault.kdbx", 64*1024 - 5, 10, plain[64*1024-5 : 64*1024+5]},
+		{"До конца объекта", "user_1/vault.kdbx", 2 * 64 * 1024, -1, plain[2*64*1024:]},
+		{"Незашифрованный объект", "user_1/old.kdbx", 6, -1, []byte("data")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, rangeErr := enc.DownloadFileRange(ctx, tt.objectKey, tt.offset, tt.length)
+			require.NoError(t, rangeErr)
+			defer body.Close()
+			got, readErr := io.ReadAll(body)
+			require.NoError(t, readErr)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+
+	_, err = enc.DownloadFileRange(ctx, "user_1/vault.kdbx", int64(len(plain))+1, -1)
+	require.Error(t, err, "Смещение за пределами объекта")
+}
+
+func TestEncryptedStorage_KeyRotation(t *testing.T) {
+	keyV1 := randomKey(t)
+	keyV2 := randomKey(t)
+	inner := newMemoryStorage()
+	plain := []byte("содержимое хранилища")
+
+	// Загружаем объект с ключом версии 1
+	encV1 := storage.NewEncryptedStorage(inner, newTestKeyring(t, map[int][]byte{1: keyV1}))
+	require.NoError(t, encV1.UploadFile(context.Background(), "user_1/v.kdbx",
+		bytes.NewReader(plain), int64(len(plain)), "application/octet-stream"))
+	inner.objects["user_1/legacy.kdbx"] = []byte("legacy")
+	dataBefore := append([]byte(nil), inner.objects["user_1/v.kdbx"]...)
+
+	// Добавляем версию 2 и переоборачиваем ключи
+	encV2 := storage.NewEncryptedStorage(inner, newTestKeyring(t, map[int][]byte{1: keyV1, 2: keyV2}))
+	rewrapped, err := encV2.RewrapKeys(context.Background(), []string{"user_1/v.kdbx", "user_1/legacy.kdbx"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, rewrapped)
+	assert.Contains(t, string(inner.objects["user_1/v.kdbx.dek"]), `"key_version":2`)
+	assert.Equal(t, dataBefore, inner.objects["user_1/v.kdbx"], "данные не должны перезаписываться")
+
+	// Повторный запуск ничего не меняет
+	rewrapped, err = encV2.RewrapKeys(context.Background(), []string{"user_1/v.kdbx"})
+	require.NoError(t, err)
+	assert.Equal(t, 0, rewrapped)
+
+	// После удаления старой версии объект по-прежнему читается
+	encOnlyV2 := storage.NewEncryptedStorage(inner, newTestKeyring(t, map[int][]byte{2: keyV2}))
+	body, err := encOnlyV2.DownloadFile(context.Background(), "user_1/v.kdbx")
+	require.NoError(t, err)
+	got, err := io.ReadAll(body)
+	require.NoError(t, err)
+	assert.Equal(t, plain, got)
+
+	// Без нужной версии ключа объект не читается
+	encOnlyV1 := storage.NewEncryptedStorage(inner, newTestKeyring(t, map[int][]byte{1: keyV1}))
+	_, err = encOnlyV1.DownloadFile(context.Background(), "user_1/v.kdbx")
+	require.ErrorIs(t, err, storage.ErrUnknownKeyVersion)
+}
@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// masterKeySize - размер мастер-ключа (AES-256).
+const masterKeySize = 32
+
+// MasterKeyring хранит версии мастер-ключа сервера.
+// Активной считается версия с наибольшим номером: ей оборачиваются новые ключи данных,
+// а старые версии используются только для разворачивания и перешифрования.
+type MasterKeyring struct {
+	keys          map[int][]byte
+	activeVersion int
+}
+
+// LoadMasterKeyring загружает мастер-ключи из файла.
+//
+// Формат файла: по одной версии на строку в виде "<версия>:<base64 ключа 32 байта>".
+// Пустые строки и строки, начинающиеся с '#', игнорируются.
+// Для ротации достаточно дописать строку с новой версией и перезапустить сервер.
+func LoadMasterKeyring(path string) (*MasterKeyring, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка открытия файла мастер-ключей '%s': %w", path, err)
+	}
+	defer file.Close()
+
+	keyring := &MasterKeyring{keys: make(map[int][]byte)}
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if err = keyring.parseLine(line); err != nil {
+			return nil, fmt.Errorf("файл мастер-ключей '%s', строка %d: %w", path, lineNum, err)
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения файла мастер-ключей '%s': %w", path, err)
+	}
+	if len(keyring.keys) == 0 {
+		return nil, fmt.Errorf("файл мастер-ключей '%s' не содержит ни одного ключа", path)
+	}
+
+	return keyring, nil
+}
+
+// NewMasterKeyring создает связку ключей из готового набора версий (используется в тестах и утилитах).
+func NewMasterKeyring(keys map[int][]byte) (*MasterKeyring, error) {
+	keyring := &MasterKeyring{keys: make(map[int][]byte, len(keys))}
+	for version, key := range keys {
+		if err := keyring.add(version, key); err != nil {
+			return nil, err
+		}
+	}
+	if len(keyring.keys) == 0 {
+		return nil, errors.New("связка мастер-ключей пуста")
+	}
+	return keyring, nil
+}
+
+// parseLine разбирает строку вида "<версия>:<base64>".
+func (k *MasterKeyring) parseLine(line string) error {
+	versionStr, encoded, found := strings.Cut(line, ":")
+	if !found {
+		return errors.New("ожидается формат '<версия>:<base64 ключа>'")
+	}
+	version, err := strconv.Atoi(strings.TrimSpace(versionStr))
+	if err != nil {
+		return fmt.Errorf("неверный номер версии '%s': %w", versionStr, err)
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return fmt.Errorf("ошибка декодирования ключа версии %d: %w", version, err)
+	}
+	return k.add(version, key)
+}
+
+// add добавляет версию ключа в связку.
+func (k *MasterKeyring) add(version int, key []byte) error {
+	if version <= 0 {
+		return fmt.Errorf("версия ключа должна быть положительной, получено %d", version)
+	}
+	if len(key) != masterKeySize {
+		return fmt.Errorf("ключ версии %d должен быть длиной %d байт, получено %d", version, masterKeySize, len(key))
+	}
+	if _, exists := k.keys[version]; exists {
+		return fmt.Errorf("версия ключа %d указана повторно", version)
+	}
+	k.keys[version] = append([]byte(nil), key...)
+	if version > k.activeVersion {
+		k.activeVersion = version
+	}
+	return nil
+}
+
+// ActiveVersion возвращает номер активной версии мастер-ключа.
+func (k *MasterKeyring) ActiveVersion() int {
+	return k.activeVersion
+}
+
+// key возвращает ключ указанной версии.
+func (k *MasterKeyring) key(version int) ([]byte, error) {
+	key, ok := k.keys[version]
+	if !ok {
+		return nil, fmt.Errorf("%w: версия %d", ErrUnknownKeyVersion, version)
+	}
+	return key, nil
+}
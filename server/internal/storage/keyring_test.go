@@ -0,0 +1,86 @@
+package storage_test
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/maynagashev/gophkeeper/server/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeKeyFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "master.keys")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadMasterKeyring(t *testing.T) {
+	key1 := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("a", 32)))
+	key2 := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("b", 32)))
+	short := base64.StdEncoding.EncodeToString([]byte("short"))
+
+	tests := []struct {
+		name          string
+		content       string
+		wantErr       string
+		wantActiveVer int
+	}{
+		{
+			name:          "Одна версия",
+			content:       "1:" + key1 + "\n",
+			wantActiveVer: 1,
+		},
+		{
+			name:          "Несколько версий с комментариями",
+			content:       "# ключи хранилища\n1:" + key1 + "\n\n3:" + key2 + "\n",
+			wantActiveVer: 3,
+		},
+		{
+			name:    "Пустой файл",
+			content: "# только комментарий\n",
+			wantErr: "не содержит ни одного ключа",
+		},
+		{
+			name:    "Неверный формат строки",
+			content: key1 + "\n",
+			wantErr: "строка 1",
+		},
+		{
+			name:    "Короткий ключ",
+			content: "1:" + short + "\n",
+			wantErr: "должен быть длиной 32 байт",
+		},
+		{
+			name:    "Повтор версии",
+			content: "1:" + key1 + "\n1:" + key2 + "\n",
+			wantErr: "указана повторно",
+		},
+		{
+			name:    "Нулевая версия",
+			content: "0:" + key1 + "\n",
+			wantErr: "должна быть положительной",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyring, err := storage.LoadMasterKeyring(writeKeyFile(t, tt.content))
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantActiveVer, keyring.ActiveVersion())
+		})
+	}
+
+	t.Run("Файл не существует", func(t *testing.T) {
+		_, err := storage.LoadMasterKeyring(filepath.Join(t.TempDir(), "missing"))
+		require.Error(t, err)
+	})
+}
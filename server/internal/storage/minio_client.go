@@ -15,7 +15,13 @@ import (
 type FileStorage interface {
 	UploadFile(ctx context.Context, objectKey string, reader io.Reader, size int64, contentType string) error
 	DownloadFile(ctx context.Context, objectKey string) (io.ReadCloser, error)
-	// TODO: Добавить другие методы, если понадобятся (например, DeleteFile, GetFileInfo)
+	// DownloadFileRange скачивает часть объекта начиная с offset длиной length байт
+	// (length < 0 - до конца объекта).
+	DownloadFileRange(ctx context.Context, objectKey string, offset, length int64) (io.ReadCloser, error)
+	// Health проверяет, что хранилище доступно и бакет существует.
+	Health(ctx context.Context) error
+	// DeleteFile удаляет объект. Удаление отсутствующего объекта не считается ошибкой.
+	DeleteFile(ctx context.Context, objectKey string) error
 }
 
 // MinioClient реализует FileStorage для MinIO.
@@ -42,6 +48,7 @@ func NewMinioClient(cfg MinioConfig) (*MinioClient, error) {
 	minioClient, err := minio.New(cfg.Endpoint, &minio.Options{
 		Creds:  credentials.NewStaticV4(cfg.AccessKeyID, cfg.SecretAccessKey, ""),
 		Secure: cfg.UseSSL,
+		Region: cfg.Region,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("ошибка инициализации клиента MinIO: %w", err)
@@ -107,13 +114,70 @@ func (c *MinioClient) UploadFile(
 	return nil
 }
 
+// Health проверяет доступность MinIO и наличие бакета.
+func (c *MinioClient) Health(ctx context.Context) error {
+	exists, err := c.client.BucketExists(ctx, c.bucketName)
+	if err != nil {
+		return fmt.Errorf("ошибка проверки бакета '%s': %w", c.bucketName, err)
+	}
+	if !exists {
+		return fmt.Errorf("бакет '%s' не найден", c.bucketName)
+	}
+	return nil
+}
+
+// DeleteFile удаляет файл из MinIO.
+func (c *MinioClient) DeleteFile(ctx context.Context, objectKey string) error {
+	log.Printf("[Minio] Удаление файла '%s' из бакета '%s'...", objectKey, c.bucketName)
+	// RemoveObject не возвращает ошибку для отсутствующего объекта (семантика S3)
+	if err := c.client.RemoveObject(ctx, c.bucketName, objectKey, minio.RemoveObjectOptions{}); err != nil {
+		log.Printf("[Minio] Ошибка удаления файла '%s': %v", objectKey, err)
+		return fmt.Errorf("ошибка удаления файла из MinIO: %w", err)
+	}
+	return nil
+}
+
 // DownloadFile скачивает файл из MinIO.
 // Возвращает io.ReadCloser, который нужно закрыть после использования.
 func (c *MinioClient) DownloadFile(ctx context.Context, objectKey string) (io.ReadCloser, error) {
 	log.Printf("[Minio] Скачивание файла '%s' из бакета '%s'...", objectKey, c.bucketName)
+	return c.getObject(ctx, objectKey, minio.GetObjectOptions{})
+}
 
+// DownloadFileRange скачивает часть файла из MinIO, запрашивая у сервера только нужный диапазон байт.
+func (c *MinioClient) DownloadFileRange(
+	ctx context.Context,
+	objectKey string,
+	offset,
+	length int64,
+) (io.ReadCloser, error) {
+	if offset < 0 || length == 0 {
+		return nil, fmt.Errorf("некорректный диапазон: offset=%d, length=%d", offset, length)
+	}
+	log.Printf("[Minio] Скачивание диапазона файла '%s' (offset=%d, length=%d) из бакета '%s'...",
+		objectKey, offset, length, c.bucketName)
+
+	opts := minio.GetObjectOptions{}
+	end := int64(0) // 0 означает "до конца объекта"
+	if length > 0 {
+		end = offset + length - 1
+	}
+	if offset > 0 || end > 0 {
+		if err := opts.SetRange(offset, end); err != nil {
+			return nil, fmt.Errorf("ошибка установки диапазона для MinIO: %w", err)
+		}
+	}
+	return c.getObject(ctx, objectKey, opts)
+}
+
+// getObject получает объект из MinIO с указанными опциями.
+func (c *MinioClient) getObject(
+	ctx context.Context,
+	objectKey string,
+	opts minio.GetObjectOptions,
+) (io.ReadCloser, error) {
 	// Получаем объект
-	object, err := c.client.GetObject(ctx, c.bucketName, objectKey, minio.GetObjectOptions{})
+	object, err := c.client.GetObject(ctx, c.bucketName, objectKey, opts)
 	if err != nil {
 		// Проверяем, является ли ошибка "NoSuchKey"
 		var minioErr minio.ErrorResponse
@@ -125,17 +189,20 @@ func (c *MinioClient) DownloadFile(ctx context.Context, objectKey string) (io.Re
 		return nil, fmt.Errorf("ошибка получения файла из MinIO: %w", err)
 	}
 
-	// Проверяем метаданные объекта (необязательно, но может быть полезно)
-	/*
-		stat, err := object.Stat()
-		if err != nil {
-			// Важно закрыть тело объекта, даже если Stat() вернул ошибку
-			_ = object.Close()
-			log.Printf("[Minio] Ошибка получения метаданных для файла '%s': %v", objectKey, err)
-			return nil, fmt.Errorf("ошибка получения метаданных из MinIO: %w", err)
+	// GetObject ленивый: отсутствие объекта обнаруживается только при первом обращении,
+	// поэтому запрашиваем метаданные сразу, чтобы вернуть ErrObjectNotFound до начала чтения.
+	stat, err := object.Stat()
+	if err != nil {
+		// Важно закрыть тело объекта, даже если Stat() вернул ошибку
+		_ = object.Close()
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			log.Printf("[Minio] Файл '%s' не найден в бакете '%s'", objectKey, c.bucketName)
+			return nil, ErrObjectNotFound
 		}
-		log.Printf("[Minio] Файл '%s' найден, размер: %d, ContentType: %s", objectKey, stat.Size, stat.ContentType)
-	*/
+		log.Printf("[Minio] Ошибка получения метаданных для файла '%s': %v", objectKey, err)
+		return nil, fmt.Errorf("ошибка получения метаданных из MinIO: %w", err)
+	}
+	log.Printf("[Minio] Файл '%s' найден, размер: %d, ContentType: %s", objectKey, stat.Size, stat.ContentType)
 
 	log.Printf("[Minio] Файл '%s' успешно получен для скачивания", objectKey)
 	return object, nil // Возвращаем тело объекта (io.ReadCloser)
@@ -0,0 +1,206 @@
+// Package tlsutil содержит TLS-настройки сервера: создание самоподписанного сертификата при первом запуске,
+// перезагрузку сертификата без перезапуска и разбор минимальной версии TLS и наборов шифров.
+package tlsutil
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// CAFileName - имя файла сертификата CA, который создается рядом с сертификатом сервера.
+	CAFileName = "ca.crt"
+	// Сроки действия создаваемых сертификатов.
+	caValidity     = 10 * 365 * 24 * time.Hour
+	serverValidity = 825 * 24 * time.Hour
+	// Права на каталог и файлы: ключ доступен только владельцу.
+	dirPermissions  = 0o700
+	certPermissions = 0o644
+	keyPermissions  = 0o600
+	serialBits      = 128
+)
+
+// Bootstrap - сведения о созданных сертификатах, которые нужно сообщить клиентам.
+type Bootstrap struct {
+	CAFile string
+	// CAFingerprint - SHA-256 сертификата CA (hex через двоеточие), для сверки при установке CA на клиенте.
+	CAFingerprint string
+	// SPKIPin - SHA-256 открытого ключа (SPKI) сертификата сервера в base64, для закрепления на клиенте.
+	SPKIPin string
+}
+
+// EnsureSelfSigned создает самоподписанный CA и подписанный им сертификат сервера для hosts
+// (имен и IP-адресов), если файлов certFile и keyFile еще нет. Сертификат CA сохраняется в CAFileName
+// рядом с certFile, его закрытый ключ не сохраняется: для выпуска нового сертификата удалите файлы,
+// и при следующем запуске будет создан новый CA. Возвращает nil, если оба файла уже существуют.
+func EnsureSelfSigned(certFile, keyFile string, hosts []string) (*Bootstrap, error) {
+	certExists, err := fileExists(certFile)
+	if err != nil {
+		return nil, err
+	}
+	keyExists, err := fileExists(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case certExists && keyExists:
+		return nil, nil
+	case certExists || keyExists:
+		// Не перезаписываем файлы: второй файл мог быть удален по ошибке
+		return nil, fmt.Errorf("найден только один из файлов %s и %s: удалите его или восстановите второй",
+			certFile, keyFile)
+	}
+	if len(hosts) == 0 {
+		return nil, errors.New("не указаны имена хоста для сертификата")
+	}
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания ключа CA: %w", err)
+	}
+	now := time.Now()
+	caTemplate := &x509.Certificate{
+		Subject:               pkix.Name{Organization: []string{"GophKeeper"}, CommonName: "GophKeeper self-signed CA"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(caValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+	caDER, caCert, err := createCertificate(caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания сертификата CA: %w", err)
+	}
+
+	serverKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания ключа сервера: %w", err)
+	}
+	serverTemplate := &x509.Certificate{
+		Subject:     pkix.Name{Organization: []string{"GophKeeper"}, CommonName: hosts[0]},
+		NotBefore:   now.Add(-time.Hour),
+		NotAfter:    now.Add(serverValidity),
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			serverTemplate.IPAddresses = append(serverTemplate.IPAddresses, ip)
+		} else {
+			serverTemplate.DNSNames = append(serverTemplate.DNSNames, host)
+		}
+	}
+	serverDER, serverCert, err := createCertificate(serverTemplate, caCert, &serverKey.PublicKey, caKey)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания сертификата сервера: %w", err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(serverKey)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка кодирования ключа сервера: %w", err)
+	}
+
+	caFile := filepath.Join(filepath.Dir(certFile), CAFileName)
+	// Сертификат сервера сохраняется вместе с CA, чтобы клиенты получали всю цепочку
+	files := []struct {
+		path   string
+		blocks []*pem.Block
+		perm   os.FileMode
+	}{
+		{caFile, []*pem.Block{{Type: "CERTIFICATE", Bytes: caDER}}, certPermissions},
+		{keyFile, []*pem.Block{{Type: "PRIVATE KEY", Bytes: keyDER}}, keyPermissions},
+		{certFile, []*pem.Block{{Type: "CERTIFICATE", Bytes: serverDER}, {Type: "CERTIFICATE", Bytes: caDER}},
+			certPermissions},
+	}
+	for _, f := range files {
+		if err = writePEM(f.path, f.blocks, f.perm); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Bootstrap{
+		CAFile:        caFile,
+		CAFingerprint: CertificateFingerprint(caCert),
+		SPKIPin:       SPKIPin(serverCert),
+	}, nil
+}
+
+// CertificateFingerprint возвращает SHA-256 сертификата в виде "AB:CD:...".
+func CertificateFingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	parts := make([]string, len(sum))
+	for i, b := range sum {
+		parts[i] = fmt.Sprintf("%02X", b)
+	}
+	return strings.Join(parts, ":")
+}
+
+// SPKIPin возвращает SHA-256 открытого ключа сертификата (SubjectPublicKeyInfo) в base64.
+// Закрепление по ключу переживает перевыпуск сертификата с тем же ключом.
+func SPKIPin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// createCertificate выпускает сертификат по шаблону со случайным серийным номером.
+func createCertificate(
+	template, parent *x509.Certificate,
+	pub *ecdsa.PublicKey,
+	signer *ecdsa.PrivateKey,
+) ([]byte, *x509.Certificate, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), serialBits))
+	if err != nil {
+		return nil, nil, err
+	}
+	template.SerialNumber = serial
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, pub, signer)
+	if err != nil {
+		return nil, nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, err
+	}
+	return der, cert, nil
+}
+
+// writePEM записывает блоки PEM в новый файл, создавая каталог при необходимости.
+func writePEM(path string, blocks []*pem.Block, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), dirPermissions); err != nil {
+		return fmt.Errorf("ошибка создания каталога для %s: %w", path, err)
+	}
+	var data []byte
+	for _, block := range blocks {
+		data = append(data, pem.EncodeToMemory(block)...)
+	}
+	if err := os.WriteFile(path, data, perm); err != nil {
+		return fmt.Errorf("ошибка записи %s: %w", path, err)
+	}
+	return nil
+}
+
+// fileExists сообщает, существует ли файл.
+func fileExists(path string) (bool, error) {
+	_, err := os.Stat(path)
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	return false, fmt.Errorf("ошибка проверки файла %s: %w", path, err)
+}
@@ -0,0 +1,67 @@
+package tlsutil
+
+import (
+	"crypto/tls"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Поддерживаемые значения минимальной версии TLS.
+const (
+	Version12 = "1.2"
+	Version13 = "1.3"
+)
+
+// ParseVersion возвращает константу версии TLS для "1.2" или "1.3".
+// Более старые версии не поддерживаются.
+func ParseVersion(version string) (uint16, error) {
+	switch version {
+	case Version12:
+		return tls.VersionTLS12, nil
+	case Version13:
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("неподдерживаемая версия TLS '%s' (допустимо: %s, %s)", version, Version12, Version13)
+	}
+}
+
+// ParseCipherSuites возвращает идентификаторы наборов шифров по именам из crypto/tls
+// (например, TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256). Небезопасные наборы не принимаются.
+// Наборы шифров TLS 1.3 не настраиваются и всегда включены.
+func ParseCipherSuites(names []string) ([]uint16, error) {
+	known := make(map[string]*tls.CipherSuite)
+	for _, suite := range tls.CipherSuites() {
+		known[suite.Name] = suite
+	}
+	insecure := make(map[string]bool)
+	for _, suite := range tls.InsecureCipherSuites() {
+		insecure[suite.Name] = true
+	}
+
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		suite, ok := known[name]
+		switch {
+		case insecure[name]:
+			return nil, fmt.Errorf("небезопасный набор шифров %s", name)
+		case !ok:
+			return nil, fmt.Errorf("неизвестный набор шифров %s", name)
+		case !slices.Contains(suite.SupportedVersions, tls.VersionTLS12):
+			return nil, fmt.Errorf("набор шифров %s относится к TLS 1.3 и не настраивается", name)
+		}
+		ids = append(ids, suite.ID)
+	}
+	return ids, nil
+}
+
+// ServerConfig возвращает TLS-конфигурацию сервера, берущую сертификат из reloader.
+// cipherSuites применяются только к TLS 1.2 (пусто - наборы Go по умолчанию).
+func ServerConfig(reloader *Reloader, minVersion uint16, cipherSuites []uint16) *tls.Config {
+	return &tls.Config{
+		MinVersion:     minVersion,
+		CipherSuites:   cipherSuites,
+		GetCertificate: reloader.GetCertificate,
+	}
+}
@@ -0,0 +1,91 @@
+package tlsutil
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Reloader отдает TLS-сертификат сервера из файлов и перечитывает их при изменении,
+// чтобы обновленный сертификат применялся к новым соединениям без перезапуска сервера.
+type Reloader struct {
+	certFile string
+	keyFile  string
+	cert     atomic.Pointer[tls.Certificate]
+
+	mu    sync.Mutex
+	stamp [2]fileStamp // Состояние файлов сертификата и ключа при последней успешной загрузке
+}
+
+// fileStamp - признаки изменения файла.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// NewReloader загружает сертификат и ключ из файлов.
+func NewReloader(certFile, keyFile string) (*Reloader, error) {
+	r := &Reloader{certFile: certFile, keyFile: keyFile}
+	if _, err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetCertificate возвращает текущий сертификат (для tls.Config.GetCertificate).
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.cert.Load(), nil
+}
+
+// Reload перечитывает сертификат и ключ, если файлы изменились с последней загрузки.
+// Возвращает true, если сертификат заменен. При ошибке продолжает использоваться прежний сертификат,
+// а следующая попытка повторит загрузку (например, если ключ еще не успели заменить вслед за сертификатом).
+func (r *Reloader) Reload() (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var stamp [2]fileStamp
+	for i, path := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(path)
+		if err != nil {
+			return false, fmt.Errorf("ошибка чтения TLS-файла: %w", err)
+		}
+		stamp[i] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+	}
+	if r.cert.Load() != nil && stamp == r.stamp {
+		return false, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return false, fmt.Errorf("ошибка загрузки TLS-сертификата: %w", err)
+	}
+	r.cert.Store(&cert)
+	r.stamp = stamp
+	return true, nil
+}
+
+// Watch проверяет файлы раз в interval до отмены ctx и перезагружает сертификат при их изменении.
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		reloaded, err := r.Reload()
+		switch {
+		case err != nil:
+			log.Printf("[TLS] Сертификат не перезагружен, используется прежний: %v", err)
+		case reloaded:
+			log.Printf("[TLS] Сертификат %s перезагружен", r.certFile)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package tlsutil_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/maynagashev/gophkeeper/server/internal/tlsutil"
+)
+
+// bootstrapFiles создает самоподписанный сертификат во временном каталоге.
+func bootstrapFiles(t *testing.T) (string, string, *tlsutil.Bootstrap) {
+	t.Helper()
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "certs", "server.crt"), filepath.Join(dir, "certs", "server.key")
+	b, err := tlsutil.EnsureSelfSigned(certFile, keyFile, []string{"gophkeeper.local", "127.0.0.1"})
+	require.NoError(t, err)
+	require.NotNil(t, b)
+	return certFile, keyFile, b
+}
+
+func TestEnsureSelfSigned(t *testing.T) {
+	t.Run("Сертификат сервера подписан созданным CA", func(t *testing.T) {
+		certFile, keyFile, b := bootstrapFiles(t)
+
+		pair, err := tls.LoadX509KeyPair(certFile, keyFile)
+		require.NoError(t, err)
+		leaf, err := x509.ParseCertificate(pair.Certificate[0])
+		require.NoError(t, err)
+
+		caPEM, err := os.ReadFile(b.CAFile)
+		require.NoError(t, err)
+		roots := x509.NewCertPool()
+		require.True(t, roots.AppendCertsFromPEM(caPEM))
+		for _, host := range []string{"gophkeeper.local", "127.0.0.1"} {
+			_, err = leaf.Verify(x509.VerifyOptions{DNSName: host, Roots: roots})
+			assert.NoError(t, err, host)
+		}
+
+		assert.Equal(t, tlsutil.SPKIPin(leaf), b.SPKIPin)
+		assert.Len(t, b.CAFingerprint, 32*3-1)
+
+		info, err := os.Stat(keyFile)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	})
+
+	t.Run("Существующие файлы не перезаписываются", func(t *testing.T) {
+		certFile, keyFile, _ := bootstrapFiles(t)
+		before, err := os.ReadFile(certFile)
+		require.NoError(t, err)
+
+		b, err := tlsutil.EnsureSelfSigned(certFile, keyFile, []string{"localhost"})
+
+		require.NoError(t, err)
+		assert.Nil(t, b)
+		after, err := os.ReadFile(certFile)
+		require.NoError(t, err)
+		assert.Equal(t, before, after)
+	})
+
+	t.Run("Только один из файлов", func(t *testing.T) {
+		certFile, keyFile, _ := bootstrapFiles(t)
+		require.NoError(t, os.Remove(keyFile))
+
+		_, err := tlsutil.EnsureSelfSigned(certFile, keyFile, []string{"localhost"})
+
+		require.Error(t, err)
+		_, statErr := os.Stat(keyFile)
+		assert.ErrorIs(t, statErr, os.ErrNotExist)
+	})
+}
+
+func TestReloader(t *testing.T) {
+	certFile, keyFile, _ := bootstrapFiles(t)
+	reloader, err := tlsutil.NewReloader(certFile, keyFile)
+	require.NoError(t, err)
+	first, err := reloader.GetCertificate(nil)
+	require.NoError(t, err)
+
+	t.Run("Без изменений файлы не перечитываются", func(t *testing.T) {
+		reloaded, reloadErr := reloader.Reload()
+		require.NoError(t, reloadErr)
+		assert.False(t, reloaded)
+	})
+
+	t.Run("Поврежденный файл не заменяет текущий сертификат", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(keyFile, []byte("не ключ"), 0o600))
+
+		_, reloadErr := reloader.Reload()
+
+		require.Error(t, reloadErr)
+		current, _ := reloader.GetCertificate(nil)
+		assert.Same(t, first, current)
+	})
+
+	t.Run("Новый сертификат подхватывается", func(t *testing.T) {
+		newCert, newKey, _ := bootstrapFiles(t)
+		for src, dst := range map[string]string{newCert: certFile, newKey: keyFile} {
+			data, readErr := os.ReadFile(src)
+			require.NoError(t, readErr)
+			require.NoError(t, os.WriteFile(dst, data, 0o600))
+			// Время изменения может совпасть с прежним на файловых системах с грубым разрешением
+			require.NoError(t, os.Chtimes(dst, time.Now(), time.Now().Add(time.Minute)))
+		}
+
+		reloaded, reloadErr := reloader.Reload()
+
+		require.NoError(t, reloadErr)
+		assert.True(t, reloaded)
+		current, _ := reloader.GetCertificate(nil)
+		assert.NotEqual(t, first.Certificate[0], current.Certificate[0])
+	})
+}
+
+func TestParseVersion(t *testing.T) {
+	v, err := tlsutil.ParseVersion("1.3")
+	require.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS13), v)
+
+	_, err = tlsutil.ParseVersion("1.1")
+	assert.Error(t, err)
+}
+
+func TestParseCipherSuites(t *testing.T) {
+	ids, err := tlsutil.ParseCipherSuites([]string{
+		"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256", " TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []uint16{
+		tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256, tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+	}, ids)
+
+	for _, name := range []string{"TLS_RSA_WITH_RC4_128_SHA", "TLS_AES_128_GCM_SHA256", "TLS_UNKNOWN"} {
+		_, err = tlsutil.ParseCipherSuites([]string{name})
+		assert.Error(t, err, name)
+	}
+}
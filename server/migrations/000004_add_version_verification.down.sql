@@ -0,0 +1,12 @@
+-- 000004_add_version_verification.down.sql
+-- Удаление колонок с результатами проверки целостности объектов версий
+
+BEGIN;
+
+DROP INDEX IF EXISTS idx_vault_versions_last_verified_at;
+
+ALTER TABLE vault_versions
+DROP COLUMN IF EXISTS verification_status,
+DROP COLUMN IF EXISTS last_verified_at;
+
+COMMIT;
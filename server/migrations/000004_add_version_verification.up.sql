@@ -0,0 +1,15 @@
+-- 000004_add_version_verification.up.sql
+-- Добавление колонок для результатов проверки целостности объектов версий
+
+BEGIN;
+
+ALTER TABLE vault_versions
+ADD COLUMN last_verified_at TIMESTAMPTZ NULL,
+ADD COLUMN verification_status VARCHAR(16) NULL;
+
+COMMENT ON COLUMN vault_versions.last_verified_at IS 'Время последней проверки контрольной суммы объекта в хранилище файлов';
+COMMENT ON COLUMN vault_versions.verification_status IS 'Результат последней проверки: ok, corrupted, missing, error';
+
+CREATE INDEX idx_vault_versions_last_verified_at ON vault_versions(last_verified_at NULLS FIRST);
+
+COMMIT;
@@ -0,0 +1,10 @@
+-- 000005_add_webhooks.down.sql
+-- Удаление вебхуков, очереди их доставок и известных устройств пользователей
+
+BEGIN;
+
+DROP TABLE IF EXISTS user_devices;
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhooks;
+
+COMMIT;
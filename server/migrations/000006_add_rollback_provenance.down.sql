@@ -0,0 +1,22 @@
+-- 000006_add_rollback_provenance.down.sql
+-- Возврат к откату через перенос указателя на текущую версию
+
+BEGIN;
+
+-- Текущей становится исходная версия, а созданные откатом версии удаляются,
+-- чтобы снова стало возможным ограничение уникальности object_key
+UPDATE vaults v
+SET current_version_id = vv.restored_from_version_id
+FROM vault_versions vv
+WHERE v.current_version_id = vv.id AND vv.restored_from_version_id IS NOT NULL;
+
+DELETE FROM vault_versions WHERE restored_from_version_id IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_vault_versions_object_key;
+ALTER TABLE vault_versions ADD CONSTRAINT vault_versions_object_key_key UNIQUE (object_key);
+
+ALTER TABLE vault_versions
+DROP CONSTRAINT IF EXISTS fk_restored_from_version,
+DROP COLUMN IF EXISTS restored_from_version_id;
+
+COMMIT;
@@ -0,0 +1,19 @@
+-- 000006_add_rollback_provenance.up.sql
+-- Откат создает новую версию, ссылающуюся на объект восстановленной версии
+
+BEGIN;
+
+ALTER TABLE vault_versions
+ADD COLUMN restored_from_version_id INTEGER NULL,
+ADD CONSTRAINT fk_restored_from_version
+    FOREIGN KEY(restored_from_version_id)
+    REFERENCES vault_versions(id)
+    ON DELETE SET NULL;
+
+COMMENT ON COLUMN vault_versions.restored_from_version_id IS 'Версия, восстановленная откатом (NULL для загруженных версий)';
+
+-- Версии, созданные откатом, ссылаются на тот же объект, что и восстановленная версия
+ALTER TABLE vault_versions DROP CONSTRAINT IF EXISTS vault_versions_object_key_key;
+CREATE INDEX IF NOT EXISTS idx_vault_versions_object_key ON vault_versions(object_key);
+
+COMMIT;
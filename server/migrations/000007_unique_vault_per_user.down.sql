@@ -0,0 +1,8 @@
+-- 000007_unique_vault_per_user.down.sql
+
+BEGIN;
+
+ALTER TABLE vaults DROP CONSTRAINT IF EXISTS uq_vaults_user_id;
+CREATE INDEX IF NOT EXISTS idx_vaults_user_id ON vaults(user_id);
+
+COMMIT;
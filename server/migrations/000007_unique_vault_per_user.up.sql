@@ -0,0 +1,12 @@
+-- 000007_unique_vault_per_user.up.sql
+-- У пользователя может быть только одно хранилище. Ограничение нужно, чтобы две одновременные
+-- первые загрузки с разных устройств не создали два хранилища: вторая транзакция дождется
+-- фиксации первой и получит ошибку уникальности.
+-- Если в БД уже есть дубликаты, миграция завершится ошибкой - их нужно разобрать вручную.
+
+BEGIN;
+
+DROP INDEX IF EXISTS idx_vaults_user_id;
+ALTER TABLE vaults ADD CONSTRAINT uq_vaults_user_id UNIQUE (user_id);
+
+COMMIT;
@@ -0,0 +1,7 @@
+-- 000008_add_user_disabled.down.sql
+
+BEGIN;
+
+ALTER TABLE users DROP COLUMN IF EXISTS disabled_at;
+
+COMMIT;
@@ -0,0 +1,11 @@
+-- 000008_add_user_disabled.up.sql
+-- Отключение учетных записей администратором: отключенный пользователь не может войти
+
+BEGIN;
+
+ALTER TABLE users
+ADD COLUMN disabled_at TIMESTAMPTZ NULL;
+
+COMMENT ON COLUMN users.disabled_at IS 'Время отключения учетной записи администратором (NULL - учетная запись активна)';
+
+COMMIT;
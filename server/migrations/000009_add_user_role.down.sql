@@ -0,0 +1,7 @@
+-- 000009_add_user_role.down.sql
+
+BEGIN;
+
+ALTER TABLE users DROP COLUMN IF EXISTS role;
+
+COMMIT;
@@ -0,0 +1,11 @@
+-- 000009_add_user_role.up.sql
+-- Роль пользователя: администраторам доступен API /api/admin
+
+BEGIN;
+
+ALTER TABLE users
+ADD COLUMN role VARCHAR(16) NOT NULL DEFAULT 'user' CHECK (role IN ('user', 'admin'));
+
+COMMENT ON COLUMN users.role IS 'Роль пользователя: user или admin';
+
+COMMIT;
@@ -0,0 +1,7 @@
+-- 000010_deferrable_current_version.down.sql
+
+BEGIN;
+
+ALTER TABLE vaults ALTER CONSTRAINT fk_current_version NOT DEFERRABLE;
+
+COMMIT;
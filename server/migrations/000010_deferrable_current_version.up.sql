@@ -0,0 +1,10 @@
+-- 000010_deferrable_current_version.up.sql
+-- Ссылка хранилища на текущую версию проверяется в конце транзакции, если это запрошено
+-- (SET CONSTRAINTS ... DEFERRED). Это нужно восстановлению из резервной копии: хранилища и версии
+-- ссылаются друг на друга, поэтому вставить их по очереди с немедленной проверкой нельзя.
+
+BEGIN;
+
+ALTER TABLE vaults ALTER CONSTRAINT fk_current_version DEFERRABLE INITIALLY IMMEDIATE;
+
+COMMIT;
@@ -0,0 +1,11 @@
+-- 000011_add_kdbx_format.down.sql
+-- Удаление сведений о формате KDBX
+
+BEGIN;
+
+ALTER TABLE vault_versions
+DROP COLUMN IF EXISTS kdbx_version,
+DROP COLUMN IF EXISTS kdbx_cipher,
+DROP COLUMN IF EXISTS kdbx_kdf;
+
+COMMIT;
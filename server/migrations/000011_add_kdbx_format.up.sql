@@ -0,0 +1,15 @@
+-- 000011_add_kdbx_format.up.sql
+-- Сведения из внешнего заголовка KDBX, определенные сервером при проверке загружаемого файла
+
+BEGIN;
+
+ALTER TABLE vault_versions
+ADD COLUMN kdbx_version VARCHAR(16) NULL,
+ADD COLUMN kdbx_cipher VARCHAR(64) NULL,
+ADD COLUMN kdbx_kdf VARCHAR(64) NULL;
+
+COMMENT ON COLUMN vault_versions.kdbx_version IS 'Версия формата KDBX, например 4.1 (NULL для версий, загруженных до проверки формата)';
+COMMENT ON COLUMN vault_versions.kdbx_cipher IS 'Алгоритм шифрования базы (название или UUID в hex)';
+COMMENT ON COLUMN vault_versions.kdbx_kdf IS 'Алгоритм формирования ключа (название или UUID в hex)';
+
+COMMIT;
@@ -0,0 +1,12 @@
+-- 000012_add_upload_origin.down.sql
+-- Удаление сведений о клиенте и устройстве загрузки
+
+BEGIN;
+
+ALTER TABLE vault_versions
+DROP COLUMN IF EXISTS client_name,
+DROP COLUMN IF EXISTS client_version,
+DROP COLUMN IF EXISTS device_id,
+DROP COLUMN IF EXISTS uploader_ip;
+
+COMMIT;
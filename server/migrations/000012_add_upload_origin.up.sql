@@ -0,0 +1,17 @@
+-- 000012_add_upload_origin.up.sql
+-- Клиент и устройство, с которых загружена версия
+
+BEGIN;
+
+ALTER TABLE vault_versions
+ADD COLUMN client_name VARCHAR(128) NULL,
+ADD COLUMN client_version VARCHAR(128) NULL,
+ADD COLUMN device_id VARCHAR(128) NULL,
+ADD COLUMN uploader_ip VARCHAR(45) NULL;
+
+COMMENT ON COLUMN vault_versions.client_name IS 'Имя клиентского приложения из заголовка X-Client-Name';
+COMMENT ON COLUMN vault_versions.client_version IS 'Версия клиентского приложения из заголовка X-Client-Version';
+COMMENT ON COLUMN vault_versions.device_id IS 'Идентификатор установки клиента из заголовка X-Device-ID';
+COMMENT ON COLUMN vault_versions.uploader_ip IS 'IP-адрес, с которого загружена версия';
+
+COMMIT;
@@ -0,0 +1,8 @@
+-- 000013_add_emergency_access.down.sql
+-- Удаление доверенных контактов
+
+BEGIN;
+
+DROP TABLE IF EXISTS emergency_access;
+
+COMMIT;
@@ -0,0 +1,26 @@
+-- 000013_add_emergency_access.up.sql
+-- Доверенные контакты с экстренным доступом к хранилищу владельца
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS emergency_access (
+    id BIGSERIAL PRIMARY KEY,
+    owner_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    contact_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    wait_period_hours INTEGER NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'idle',
+    requested_at TIMESTAMPTZ NULL,
+    key_envelope BYTEA NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_emergency_access_owner_contact UNIQUE (owner_id, contact_id),
+    CONSTRAINT chk_emergency_access_not_self CHECK (owner_id <> contact_id),
+    CONSTRAINT chk_emergency_access_wait_period CHECK (wait_period_hours > 0)
+);
+
+CREATE INDEX idx_emergency_access_contact_id ON emergency_access(contact_id);
+
+COMMENT ON COLUMN emergency_access.status IS 'Статус: idle, requested, denied (granted вычисляется по requested_at)';
+COMMENT ON COLUMN emergency_access.key_envelope IS 'Ключ хранилища, зашифрованный клиентом владельца для контакта';
+
+COMMIT;